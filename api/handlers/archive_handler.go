@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ArchiveHandler handles archive HTTP requests
+type ArchiveHandler struct {
+	archiver *app.Archiver
+	queueMgr *app.QueueManager
+	logger   *zap.Logger
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(archiver *app.Archiver, queueMgr *app.QueueManager, logger *zap.Logger) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiver: archiver,
+		queueMgr: queueMgr,
+		logger:   logger,
+	}
+}
+
+// ArchiveRequest represents a request to archive completed downloads
+type ArchiveRequest struct {
+	Dest      string `json:"dest" binding:"required"` // Directory on the archive volume
+	OlderThan string `json:"older_than,omitempty"`    // e.g. 30d, 2w, 36h; empty archives every completed download
+	DryRun    bool   `json:"dry_run,omitempty"`       // Only list the downloads that would be archived
+}
+
+// Archive handles POST /api/v1/archive
+// Moves the files of downloads completed before older_than to dest.
+func (h *ArchiveHandler) Archive(c *gin.Context) {
+	var req ArchiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var olderThan time.Duration
+	if req.OlderThan != "" {
+		var err error
+		if olderThan, err = domain.ParseAge(req.OlderThan); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.archiver.ValidateDest(req.Dest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.archiver.Archive(req.Dest, olderThan, time.Now(), req.DryRun)
+	if err != nil {
+		h.logger.Error("Failed to archive downloads", zap.String("dest", req.Dest), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ArchiveDownloadRequest represents a request to archive one download
+type ArchiveDownloadRequest struct {
+	Dest   string `json:"dest" binding:"required"` // Directory on the archive volume
+	DryRun bool   `json:"dry_run,omitempty"`       // Only show where the files would go
+}
+
+// ArchiveDownload handles POST /api/v1/downloads/:id/archive
+// Moves the files of a completed download to dest.
+func (h *ArchiveHandler) ArchiveDownload(c *gin.Context) {
+	var req ArchiveDownloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	download, err := h.queueMgr.GetDownload(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "download not found"})
+		return
+	}
+	if download.Status != domain.StatusCompleted || download.ArchiveDir() != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "only completed downloads that are not archived yet can be archived"})
+		return
+	}
+	if err := h.archiver.ValidateDest(req.Dest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	archived, err := h.archiver.ArchiveDownload(download, req.Dest, req.DryRun)
+	if err != nil {
+		h.logger.Error("Failed to archive download", zap.String("id", download.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, archived)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// maxCookieFileSize bounds an uploaded cookie file; browser exports are a few KB
+const maxCookieFileSize = 1 << 20
+
+// CookieHandler handles cookie file HTTP requests
+type CookieHandler struct {
+	cookieMonitors map[domain.Platform]*app.CookieMonitor
+}
+
+// NewCookieHandler creates a new cookie handler
+func NewCookieHandler(cookieMonitors map[domain.Platform]*app.CookieMonitor) *CookieHandler {
+	return &CookieHandler{
+		cookieMonitors: cookieMonitors,
+	}
+}
+
+// ListCookies handles GET /api/v1/cookies
+// Pass ?refresh=true to check the cookies now instead of returning the last results.
+func (h *CookieHandler) ListCookies(c *gin.Context) {
+	platforms := make([]domain.Platform, 0, len(h.cookieMonitors))
+	for platform := range h.cookieMonitors {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i] < platforms[j] })
+
+	statuses := make([]domain.CookieStatus, 0, len(platforms))
+	for _, platform := range platforms {
+		statuses = append(statuses, h.status(c, h.cookieMonitors[platform]))
+	}
+	c.JSON(http.StatusOK, gin.H{"cookies": statuses})
+}
+
+// GetCookies handles GET /api/v1/cookies/:platform
+// Pass ?refresh=true to check the cookies now instead of returning the last result.
+func (h *CookieHandler) GetCookies(c *gin.Context) {
+	platform := domain.Platform(c.Param("platform"))
+	monitor, ok := h.cookieMonitors[platform]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no cookies managed for platform " + string(platform)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"platform": platform,
+		"cookie":   h.status(c, monitor),
+	})
+}
+
+// ImportCookies handles PUT /api/v1/cookies/:platform
+// The Netscape cookie file is the request body, or the "file" field of a
+// multipart form. It replaces the platform's cookie file only if it is valid.
+func (h *CookieHandler) ImportCookies(c *gin.Context) {
+	platform := domain.Platform(c.Param("platform"))
+	monitor, ok := h.cookieMonitors[platform]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no cookies managed for platform " + string(platform)})
+		return
+	}
+
+	data, err := readCookieUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := monitor.Import(c.Request.Context(), data)
+	if err != nil {
+		var invalid *domain.InvalidCookieError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "cookie": status})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"platform": platform,
+		"cookie":   status,
+	})
+}
+
+// status returns the last check result of monitor, checking now if asked to
+// or if it was never checked
+func (h *CookieHandler) status(c *gin.Context, monitor *app.CookieMonitor) domain.CookieStatus {
+	status := monitor.Status()
+	if c.Query("refresh") == "true" || status.State == domain.CookieUnknown {
+		status = monitor.Check(c.Request.Context())
+	}
+	return status
+}
+
+// readCookieUpload reads the uploaded cookie file of an import request
+func readCookieUpload(c *gin.Context) ([]byte, error) {
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("missing cookie file (form field \"file\")")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body = file
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxCookieFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty cookie file")
+	}
+	if len(data) > maxCookieFileSize {
+		return nil, errors.New("cookie file too large")
+	}
+	return data, nil
+}
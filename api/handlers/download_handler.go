@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/x-extract-go/internal/app"
@@ -27,10 +31,23 @@ func NewDownloadHandler(queueMgr *app.QueueManager, downloadMgr *app.DownloadMan
 
 // AddDownloadRequest represents a request to add a download
 type AddDownloadRequest struct {
-	URL      string `json:"url" binding:"required"`
-	Platform string `json:"platform,omitempty"`
-	Mode     string `json:"mode,omitempty"`
-	Filters  string `json:"filters,omitempty"`
+	URL         string `json:"url" binding:"required"`
+	Platform    string `json:"platform,omitempty"`
+	Mode        string `json:"mode,omitempty"`
+	Filters     string `json:"filters,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+	RangeEnd    int    `json:"range_end,omitempty"`    // Telegram: last message ID of a range starting at the URL's message
+	AllVariants bool   `json:"all_variants,omitempty"` // X: every image at original resolution and every video rendition
+	Force       bool   `json:"force,omitempty"`        // Download again even if the URL is already downloaded
+	CallbackURL string `json:"callback_url,omitempty"` // POSTed when the download reaches a terminal state
+	Source      string `json:"source,omitempty"`       // How the download was added (cli, dashboard, ...); defaults to api
+	Account     string `json:"account,omitempty"`      // X: twitter.accounts account to download with
+	Profile     string `json:"tdl_profile,omitempty"`  // Telegram: telegram.profiles tdl profile to download with
+}
+
+// UpdateDownloadRequest represents a request to update a queued download
+type UpdateDownloadRequest struct {
+	Priority *int `json:"priority" binding:"required"`
 }
 
 // AddDownload handles POST /api/downloads
@@ -51,6 +68,20 @@ func (h *DownloadHandler) AddDownload(c *gin.Context) {
 		}
 	}
 
+	if err := domain.ValidateCallbackURL(req.CallbackURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	source := domain.DownloadSource(req.Source)
+	if source == "" {
+		source = domain.SourceAPI
+	}
+	if !domain.ValidateSource(source) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid source: " + req.Source})
+		return
+	}
+
 	// Default mode
 	mode := domain.DownloadMode(req.Mode)
 	if mode == "" {
@@ -58,7 +89,22 @@ func (h *DownloadHandler) AddDownload(c *gin.Context) {
 	}
 
 	// Add to queue
-	download, err := h.queueMgr.AddDownload(req.URL, platform, mode, req.Filters)
+	download, err := h.queueMgr.AddDownloadWithOptions(req.URL, platform, mode, app.AddDownloadOptions{
+		Filters:     req.Filters,
+		Priority:    req.Priority,
+		RangeEnd:    req.RangeEnd,
+		AllVariants: req.AllVariants,
+		Force:       req.Force,
+		CallbackURL: req.CallbackURL,
+		Source:      source,
+		Account:     req.Account,
+		Profile:     req.Profile,
+	})
+	var duplicate *domain.DuplicateDownloadError
+	if errors.As(err, &duplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": duplicate.Error(), "duplicate": true, "download": duplicate.Existing})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to add download", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -68,6 +114,90 @@ func (h *DownloadHandler) AddDownload(c *gin.Context) {
 	c.JSON(http.StatusCreated, download)
 }
 
+// parseListQuery parses the filter, sort, paging and creation time query
+// parameters of GET /api/downloads
+func parseListQuery(c *gin.Context) (map[string]interface{}, domain.ListOptions, error) {
+	filters := make(map[string]interface{})
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+	if platform := c.Query("platform"); platform != "" {
+		filters["platform"] = platform
+	}
+	if uploader := c.Query("uploader"); uploader != "" {
+		filters["uploader"] = uploader
+	}
+	if uploaderID := c.Query("uploader_id"); uploaderID != "" {
+		filters["uploader_id"] = uploaderID
+	}
+	if source := c.Query("source"); source != "" {
+		filters["source"] = source
+	}
+	if language := c.Query("language"); language != "" {
+		filters["language"] = language
+	}
+	if errorCode := c.Query("error_code"); errorCode != "" {
+		code, err := domain.ParseErrorCode(errorCode)
+		if err != nil {
+			return nil, domain.ListOptions{}, err
+		}
+		filters["error_code"] = code
+	}
+
+	opts := domain.ListOptions{
+		SortBy:  c.Query("sort_by"),
+		SortDir: c.Query("sort_dir"),
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, domain.ListOptions{}, errors.New("invalid limit")
+		}
+		opts.Limit = parsed
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return nil, domain.ListOptions{}, errors.New("invalid offset")
+		}
+		opts.Offset = parsed
+	}
+	if after := c.Query("created_after"); after != "" {
+		parsed, err := parseTimeQuery(after)
+		if err != nil {
+			return nil, domain.ListOptions{}, errors.New("invalid created_after (want RFC 3339 or YYYY-MM-DD)")
+		}
+		opts.CreatedAfter = &parsed
+	}
+	if before := c.Query("created_before"); before != "" {
+		parsed, err := parseTimeQuery(before)
+		if err != nil {
+			return nil, domain.ListOptions{}, errors.New("invalid created_before (want RFC 3339 or YYYY-MM-DD)")
+		}
+		opts.CreatedBefore = &parsed
+	}
+	if minSize := c.Query("min_size"); minSize != "" {
+		parsed, err := domain.ParseByteSize(minSize)
+		if err != nil {
+			return nil, domain.ListOptions{}, fmt.Errorf("invalid min_size: %w", err)
+		}
+		opts.MinSize = parsed
+	}
+	if maxSize := c.Query("max_size"); maxSize != "" {
+		parsed, err := domain.ParseByteSize(maxSize)
+		if err != nil {
+			return nil, domain.ListOptions{}, fmt.Errorf("invalid max_size: %w", err)
+		}
+		opts.MaxSize = parsed
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, domain.ListOptions{}, err
+	}
+
+	return filters, opts, nil
+}
+
 // GetDownload handles GET /api/downloads/:id
 func (h *DownloadHandler) GetDownload(c *gin.Context) {
 	id := c.Param("id")
@@ -83,26 +213,32 @@ func (h *DownloadHandler) GetDownload(c *gin.Context) {
 
 // ListDownloads handles GET /api/downloads
 func (h *DownloadHandler) ListDownloads(c *gin.Context) {
-	// Parse query parameters for filtering
-	filters := make(map[string]interface{})
-
-	if status := c.Query("status"); status != "" {
-		filters["status"] = status
-	}
-	if platform := c.Query("platform"); platform != "" {
-		filters["platform"] = platform
+	filters, opts, err := parseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	downloads, err := h.queueMgr.ListDownloads(filters)
+	downloads, total, err := h.queueMgr.ListDownloads(filters, opts)
 	if err != nil {
 		h.logger.Error("Failed to list downloads", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
 	c.JSON(http.StatusOK, downloads)
 }
 
+// parseTimeQuery parses an RFC 3339 timestamp or a YYYY-MM-DD date (local
+// midnight)
+func parseTimeQuery(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
 // GetStats handles GET /api/downloads/stats
 func (h *DownloadHandler) GetStats(c *gin.Context) {
 	stats, err := h.queueMgr.GetStats()
@@ -115,6 +251,87 @@ func (h *DownloadHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetStatsHistory handles GET /api/v1/downloads/stats/history
+// Returns the downloads added and completed per day, by platform, for the
+// last ?days days (default 30).
+func (h *DownloadHandler) GetStatsHistory(c *gin.Context) {
+	days := domain.DefaultStatsHistoryDays
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || domain.ValidateStatsHistoryDays(n) != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid days: %s (1-%d)", value, domain.MaxStatsHistoryDays)})
+			return
+		}
+		days = n
+	}
+
+	history, err := h.queueMgr.GetStatsHistory(days)
+	if err != nil {
+		h.logger.Error("Failed to get stats history", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// UpdateDownload handles PATCH /api/downloads/:id
+func (h *DownloadHandler) UpdateDownload(c *gin.Context) {
+	id := c.Param("id")
+
+	var req UpdateDownloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.queueMgr.GetDownload(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "download not found"})
+		return
+	}
+
+	download, err := h.queueMgr.SetPriority(id, *req.Priority)
+	var notQueued *domain.NotQueuedError
+	if errors.As(err, &notQueued) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to update download", zap.String("id", id), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, download)
+}
+
+// ReorderQueue handles POST /api/v1/queue/reorder
+func (h *DownloadHandler) ReorderQueue(c *gin.Context) {
+	var req domain.QueueReorder
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queue, err := h.queueMgr.ReorderQueue(req)
+	var notQueued *domain.NotQueuedError
+	if errors.As(err, &notQueued) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to reorder queue", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
 // CancelDownload handles POST /api/downloads/:id/cancel
 func (h *DownloadHandler) CancelDownload(c *gin.Context) {
 	id := c.Param("id")
@@ -141,6 +358,182 @@ func (h *DownloadHandler) RetryDownload(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "download queued for retry"})
 }
 
+// RetryAllRequest selects the failed downloads to retry; empty fields match
+// every failed download
+type RetryAllRequest struct {
+	Platform  string `json:"platform,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	OlderThan string `json:"older_than,omitempty"` // e.g. 30d, 2w, 36h; only downloads that failed longer ago
+	NewerThan string `json:"newer_than,omitempty"` // Only downloads that failed more recently
+}
+
+// parseFailedFilter builds the filter of failed downloads from request values
+func parseFailedFilter(platform, errorCode, olderThan, newerThan string) (domain.FailedFilter, error) {
+	filter := domain.FailedFilter{Platform: domain.Platform(platform)}
+	var err error
+	if errorCode != "" {
+		if filter.ErrorCode, err = domain.ParseErrorCode(errorCode); err != nil {
+			return filter, err
+		}
+	}
+	if olderThan != "" {
+		if filter.OlderThan, err = domain.ParseAge(olderThan); err != nil {
+			return filter, err
+		}
+	}
+	if newerThan != "" {
+		if filter.NewerThan, err = domain.ParseAge(newerThan); err != nil {
+			return filter, err
+		}
+	}
+	return filter, filter.Validate()
+}
+
+// ListFailed handles GET /api/v1/downloads/failed
+// Lists failed downloads grouped by error code.
+func (h *DownloadHandler) ListFailed(c *gin.Context) {
+	filter, err := parseFailedFilter(c.Query("platform"), c.Query("error_code"), c.Query("older_than"), c.Query("newer_than"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groups, err := h.downloadMgr.FailedDownloads(filter)
+	if err != nil {
+		h.logger.Error("Failed to list failed downloads", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	total := 0
+	for _, group := range groups {
+		total += group.Count
+	}
+	c.JSON(http.StatusOK, gin.H{"total": total, "groups": groups})
+}
+
+// RetryAll handles POST /api/v1/downloads/retry-all
+// Queues every failed download matching the request again.
+func (h *DownloadHandler) RetryAll(c *gin.Context) {
+	var req RetryAllRequest
+	// The body is optional: without one every failed download is retried
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	filter, err := parseFailedFilter(req.Platform, req.ErrorCode, req.OlderThan, req.NewerThan)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids, err := h.downloadMgr.RetryFailed(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to retry failed downloads", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "retried": len(ids), "ids": ids})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"retried": len(ids), "ids": ids})
+}
+
+// GetDuplicates handles GET /api/v1/downloads/duplicates
+// Reports the completed files that share their content (download.dedupe).
+func (h *DownloadHandler) GetDuplicates(c *gin.Context) {
+	report, err := h.downloadMgr.DedupeReport()
+	if err != nil {
+		h.logger.Error("Failed to build dedupe report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportDownloads handles GET /api/downloads/export: every download matching
+// the filters of ListDownloads, streamed as CSV or JSON lines (format)
+func (h *DownloadHandler) ExportDownloads(c *gin.Context) {
+	format := c.DefaultQuery("format", domain.ExportCSV)
+	if err := domain.ValidateExportFormat(format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filters, opts, err := parseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if opts.SortDir == "" {
+		// Oldest first: downloads added during the export don't shift its pages
+		opts.SortDir = domain.SortAsc
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == domain.ExportJSONL {
+		contentType = "application/x-ndjson"
+	}
+	filename := fmt.Sprintf("x-extract-downloads-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	writer, err := domain.NewExportWriter(c.Writer, format)
+	if err == nil {
+		_, err = h.queueMgr.ExportDownloads(c.Request.Context(), writer, filters, opts)
+	}
+	if err != nil {
+		// The status is sent; the client sees a truncated export
+		h.logger.Error("Failed to export downloads", zap.Error(err))
+	}
+}
+
+// SimulateRequest represents a request to simulate a download
+type SimulateRequest struct {
+	URL         string `json:"url" binding:"required"`
+	Platform    string `json:"platform,omitempty"`
+	Mode        string `json:"mode,omitempty"`
+	RangeEnd    int    `json:"range_end,omitempty"`
+	AllVariants bool   `json:"all_variants,omitempty"`
+	Account     string `json:"account,omitempty"`
+	Profile     string `json:"tdl_profile,omitempty"`
+}
+
+// SimulateDownload handles POST /api/downloads/simulate: it reports what a
+// download would fetch, with its command line and settings, without queueing it
+func (h *DownloadHandler) SimulateDownload(c *gin.Context) {
+	var req SimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	platform := domain.Platform(req.Platform)
+	if platform == "" {
+		platform = domain.DetectPlatform(req.URL)
+		if platform == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported URL or platform"})
+			return
+		}
+	}
+	mode := domain.DownloadMode(req.Mode)
+	if mode == "" {
+		mode = domain.ModeDefault
+	}
+
+	sim, err := h.downloadMgr.Simulate(c.Request.Context(), req.URL, platform, mode, app.AddDownloadOptions{
+		RangeEnd:    req.RangeEnd,
+		AllVariants: req.AllVariants,
+		Account:     req.Account,
+		Profile:     req.Profile,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sim)
+}
+
 // DeleteDownload handles DELETE /api/downloads/:id
 func (h *DownloadHandler) DeleteDownload(c *gin.Context) {
 	id := c.Param("id")
@@ -5,40 +5,62 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
 )
 
 // HealthHandler handles health check requests
 type HealthHandler struct {
-	queueMgr *app.QueueManager
+	queueMgr       *app.QueueManager
+	platformHealth *app.PlatformHealthChecker
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(queueMgr *app.QueueManager) *HealthHandler {
+func NewHealthHandler(queueMgr *app.QueueManager, platformHealth *app.PlatformHealthChecker) *HealthHandler {
 	return &HealthHandler{
-		queueMgr: queueMgr,
+		queueMgr:       queueMgr,
+		platformHealth: platformHealth,
 	}
 }
 
 // HealthResponse represents a health check response
 type HealthResponse struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
-	Queue   struct {
+	Status    string `json:"status"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	Queue     struct {
 		Running bool `json:"running"`
 	} `json:"queue"`
+	Platforms []domain.PlatformHealth `json:"platforms,omitempty"`
 }
 
 // Health handles GET /health
 func (h *HealthHandler) Health(c *gin.Context) {
+	build := domain.CurrentBuild()
 	response := HealthResponse{
-		Status:  "ok",
-		Version: "1.0.0",
+		Status:    "ok",
+		Version:   build.Version,
+		Commit:    build.Commit,
+		BuildDate: build.BuildDate,
 	}
 	response.Queue.Running = h.queueMgr.IsRunning()
+	if h.platformHealth != nil {
+		response.Platforms = h.platformHealth.Check()
+		for _, platform := range response.Platforms {
+			if platform.Degraded {
+				response.Status = "degraded"
+			}
+		}
+	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// Version handles GET /api/v1/version
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, domain.CurrentBuild())
+}
+
 // Ready handles GET /ready
 func (h *HealthHandler) Ready(c *gin.Context) {
 	if !h.queueMgr.IsRunning() {
@@ -193,3 +193,40 @@ func (h *LogHandler) ExportLogs(c *gin.Context) {
 
 	c.File(logPath)
 }
+
+// GetQueueEvents handles GET /api/v1/queue/events?since=RFC3339
+// Returns the queue events logged after since, oldest first. When more is
+// true, request again with since set to the time of the last event.
+func (h *LogHandler) GetQueueEvents(c *gin.Context) {
+	sinceStr := c.Query("since")
+	if sinceStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'since' is required"})
+		return
+	}
+	since, err := time.Parse(time.RFC3339Nano, sinceStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, use RFC 3339 (e.g. 2026-01-27T10:30:00Z)"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "500"))
+	if err != nil || limit <= 0 {
+		limit = 500
+	}
+	if limit > 5000 {
+		limit = 5000 // Max limit
+	}
+
+	events, more, err := h.logReader.ReadQueueEvents(since, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read queue events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":  since,
+		"count":  len(events),
+		"more":   more,
+		"events": events,
+	})
+}
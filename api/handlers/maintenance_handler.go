@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+)
+
+// MaintenanceHandler handles maintenance job HTTP requests
+type MaintenanceHandler struct {
+	regenerator *app.MetadataRegenerator
+	window      *app.MaintenanceWindow
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(regenerator *app.MetadataRegenerator, window *app.MaintenanceWindow) *MaintenanceHandler {
+	return &MaintenanceHandler{regenerator: regenerator, window: window}
+}
+
+// RegenerateMetadataRequest represents a request to regenerate metadata
+type RegenerateMetadataRequest struct {
+	DryRun bool `json:"dry_run,omitempty"` // Only count what would be updated
+}
+
+// RegenerateMetadata handles POST /api/v1/maintenance/regenerate-metadata
+// Starts filling in empty Telegram descriptions from the message cache and
+// returns its status; poll GET for progress.
+func (h *MaintenanceHandler) RegenerateMetadata(c *gin.Context) {
+	var req RegenerateMetadataRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	status, started := h.regenerator.Start(req.DryRun)
+	if !started {
+		c.JSON(http.StatusConflict, gin.H{"error": "metadata regeneration is already running", "status": status})
+		return
+	}
+	c.JSON(http.StatusAccepted, status)
+}
+
+// GetRegenerateMetadata handles GET /api/v1/maintenance/regenerate-metadata
+// Returns the progress of the current or last regeneration.
+func (h *MaintenanceHandler) GetRegenerateMetadata(c *gin.Context) {
+	c.JSON(http.StatusOK, h.regenerator.Status())
+}
+
+// GetWindow handles GET /api/v1/maintenance/window
+// Returns the maintenance window, the jobs it holds and the downloads whose
+// transcodes or uploads wait for it.
+func (h *MaintenanceHandler) GetWindow(c *gin.Context) {
+	status, err := h.window.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
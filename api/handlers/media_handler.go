@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// MediaHandler serves the downloaded files of a download and their thumbnails
+type MediaHandler struct {
+	queueMgr    *app.QueueManager
+	thumbnailer domain.Thumbnailer
+	files       domain.FileStore
+	logger      *zap.Logger
+}
+
+// NewMediaHandler creates a new media handler. thumbnailer is nil when
+// thumbnails are disabled; files fetches files whose local copy was removed
+// after upload back from the storage backend.
+func NewMediaHandler(queueMgr *app.QueueManager, thumbnailer domain.Thumbnailer, files domain.FileStore, logger *zap.Logger) *MediaHandler {
+	return &MediaHandler{
+		queueMgr:    queueMgr,
+		thumbnailer: thumbnailer,
+		files:       files,
+		logger:      logger,
+	}
+}
+
+// ListFiles handles GET /api/v1/downloads/:id/files
+func (h *MediaHandler) ListFiles(c *gin.Context) {
+	download, err := h.queueMgr.GetDownload(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "download not found"})
+		return
+	}
+
+	files := []domain.DownloadFile{}
+	for i, path := range download.Files() {
+		file := domain.DownloadFile{
+			Index:     i,
+			Name:      filepath.Base(path),
+			Path:      path,
+			MediaType: domain.MediaTypeOf(path),
+			RemoteURL: download.RemoteURLOf(path),
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			file.Size = info.Size()
+			file.Exists = true
+		} else if file.RemoteURL != "" {
+			// Only the uploaded copy is left; it is served through the cache
+			file.Size = itemSize(download, path)
+			file.Exists = true
+		}
+		files = append(files, file)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"files": files})
+}
+
+// GetFileContent handles GET /api/v1/downloads/:id/files/:index/content.
+// Range requests are supported, so videos can be seeked in the browser. A
+// file whose local copy was removed after upload (storage.delete_local) is
+// served from the storage cache, or redirected to its remote URL when it
+// cannot be fetched.
+func (h *MediaHandler) GetFileContent(c *gin.Context) {
+	download, path, ok := h.filePath(c)
+	if !ok {
+		return
+	}
+
+	localPath, err := h.localPath(c, download, path)
+	if err != nil {
+		if remoteURL := download.RemoteURLOf(path); remoteURL != "" {
+			c.Redirect(http.StatusFound, remoteURL)
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename="+strconv.Quote(info.Name()))
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+}
+
+// GetFileThumbnail handles GET /api/v1/downloads/:id/files/:index/thumbnail
+func (h *MediaHandler) GetFileThumbnail(c *gin.Context) {
+	if h.thumbnailer == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "thumbnails are disabled"})
+		return
+	}
+	download, path, ok := h.filePath(c)
+	if !ok {
+		return
+	}
+	path, err := h.localPath(c, download, path)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return
+	}
+
+	thumbPath, err := h.thumbnailer.Thumbnail(c.Request.Context(), path)
+	if err != nil {
+		var unsupported *domain.UnsupportedThumbnailError
+		if errors.As(err, &unsupported) {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to make thumbnail", zap.String("path", path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Thumbnails are keyed by the file's size and modification time, so a
+	// cached copy only goes stale when the file is replaced
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.File(thumbPath)
+}
+
+// localPath returns a local path with the content of a file of download,
+// fetching it from the storage backend when only its uploaded copy is left
+func (h *MediaHandler) localPath(c *gin.Context, download *domain.Download, path string) (string, error) {
+	if h.files == nil || download.RemoteURLOf(path) == "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	localPath, err := h.files.LocalPath(c.Request.Context(), path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		h.logger.Warn("Failed to fetch stored file", zap.String("path", path), zap.Error(err))
+	}
+	return localPath, err
+}
+
+// itemSize returns the recorded size of a file of download
+func itemSize(download *domain.Download, path string) int64 {
+	for _, item := range download.Items {
+		if item.FilePath == path {
+			return item.FileSize
+		}
+	}
+	if path == download.FilePath {
+		return download.FileSize
+	}
+	return 0
+}
+
+// filePath returns the download and the path of the file selected by the
+// :id and :index parameters, writing a 404 response when there is none
+func (h *MediaHandler) filePath(c *gin.Context) (*domain.Download, string, bool) {
+	download, err := h.queueMgr.GetDownload(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "download not found"})
+		return nil, "", false
+	}
+	files := download.Files()
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 || index >= len(files) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return nil, "", false
+	}
+	return download, files[index], true
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+)
+
+// prometheusContentType is the content type of the Prometheus text format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler serves download metrics for Prometheus
+type MetricsHandler struct {
+	metrics *app.Metrics
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(metrics *app.Metrics) *MetricsHandler {
+	return &MetricsHandler{
+		metrics: metrics,
+	}
+}
+
+// GetMetrics handles GET /metrics
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	var buf bytes.Buffer
+	if _, err := h.metrics.WriteTo(&buf); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, prometheusContentType, buf.Bytes())
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// PlatformHandler handles platform status HTTP requests
+type PlatformHandler struct {
+	cookieMonitors map[domain.Platform]*app.CookieMonitor
+}
+
+// NewPlatformHandler creates a new platform handler
+func NewPlatformHandler(cookieMonitors map[domain.Platform]*app.CookieMonitor) *PlatformHandler {
+	return &PlatformHandler{
+		cookieMonitors: cookieMonitors,
+	}
+}
+
+// GetStatus handles GET /api/v1/platforms/:platform/status
+// Pass ?refresh=true to check the cookies now instead of returning the last result.
+func (h *PlatformHandler) GetStatus(c *gin.Context) {
+	platform := domain.Platform(c.Param("platform"))
+	monitor, ok := h.cookieMonitors[platform]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no status available for platform " + string(platform)})
+		return
+	}
+
+	status := monitor.Status()
+	if c.Query("refresh") == "true" || status.State == domain.CookieUnknown {
+		status = monitor.Check(c.Request.Context())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"platform": platform,
+		"cookie":   status,
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"go.uber.org/zap"
+)
+
+// ReportHandler handles archive report HTTP requests
+type ReportHandler struct {
+	generator *app.ReportGenerator
+	logger    *zap.Logger
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(generator *app.ReportGenerator, logger *zap.Logger) *ReportHandler {
+	return &ReportHandler{
+		generator: generator,
+		logger:    logger,
+	}
+}
+
+// GenerateReportRequest represents a request to write a report now
+type GenerateReportRequest struct {
+	Period string `json:"period,omitempty"` // daily or weekly (default: report.period)
+	Format string `json:"format,omitempty"` // markdown or html (default: report.format)
+}
+
+// GenerateReport handles POST /api/v1/reports
+// Writes the report of the last full period and returns it with its file name.
+func (h *ReportHandler) GenerateReport(c *gin.Context) {
+	var req GenerateReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	report, path, err := h.generator.Generate(req.Period, req.Format, time.Now())
+	if err != nil {
+		h.logger.Error("Failed to generate report", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"path":   path,
+		"report": report,
+	})
+}
+
+// ListReports handles GET /api/v1/reports
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	reports, err := h.generator.ListReports()
+	if err != nil {
+		h.logger.Error("Failed to list reports", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// GetReport handles GET /api/v1/reports/:name
+// Serves the report file itself (Markdown or HTML).
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	path, err := h.generator.ReportPath(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.File(path)
+}
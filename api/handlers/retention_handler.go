@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"go.uber.org/zap"
+)
+
+// RetentionHandler handles retention policy HTTP requests
+type RetentionHandler struct {
+	manager *app.RetentionManager
+	logger  *zap.Logger
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(manager *app.RetentionManager, logger *zap.Logger) *RetentionHandler {
+	return &RetentionHandler{
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// RunRetentionRequest represents a request to apply the retention policy now
+type RunRetentionRequest struct {
+	DryRun bool `json:"dry_run,omitempty"` // Only list the downloads that would expire
+}
+
+// RunRetention handles POST /api/v1/retention/run
+// Applies the configured retention policy and returns the expired downloads.
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	var req RunRetentionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.manager.RunOnce(time.Now(), req.DryRun)
+	if err != nil {
+		h.logger.Error("Failed to apply retention policy", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ScheduleHandler handles schedule-related HTTP requests
+type ScheduleHandler struct {
+	scheduler *app.Scheduler
+	logger    *zap.Logger
+}
+
+// NewScheduleHandler creates a new schedule handler
+func NewScheduleHandler(scheduler *app.Scheduler, logger *zap.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// CreateScheduleRequest represents a request to create a schedule
+type CreateScheduleRequest struct {
+	Name      string `json:"name,omitempty"`
+	SourceURL string `json:"source_url" binding:"required"`
+	Platform  string `json:"platform,omitempty"`
+	Cron      string `json:"cron" binding:"required"`
+}
+
+// UpdateScheduleRequest represents a request to update a schedule
+type UpdateScheduleRequest struct {
+	Name    *string `json:"name,omitempty"`
+	Cron    *string `json:"cron,omitempty"`
+	Enabled *bool   `json:"enabled,omitempty"`
+}
+
+// CreateSchedule handles POST /api/schedules
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.scheduler.CreateSchedule(req.Name, req.SourceURL, domain.Platform(req.Platform), req.Cron)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// ListSchedules handles GET /api/schedules
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.scheduler.ListSchedules()
+	if err != nil {
+		h.logger.Error("Failed to list schedules", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetSchedule handles GET /api/schedules/:id
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	schedule, err := h.scheduler.GetSchedule(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule handles PATCH /api/schedules/:id
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id := c.Param("id")
+
+	var req UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.scheduler.GetSchedule(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	schedule, err := h.scheduler.UpdateSchedule(id, app.ScheduleUpdate{
+		Name:     req.Name,
+		CronExpr: req.Cron,
+		Enabled:  req.Enabled,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule handles DELETE /api/schedules/:id
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.scheduler.GetSchedule(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	if err := h.scheduler.DeleteSchedule(id); err != nil {
+		h.logger.Error("Failed to delete schedule", zap.String("id", id), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "schedule deleted"})
+}
+
+// RunSchedule handles POST /api/schedules/:id/run
+func (h *ScheduleHandler) RunSchedule(c *gin.Context) {
+	id := c.Param("id")
+
+	schedule, err := h.scheduler.RunNow(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// SearchHandler handles saved search requests
+type SearchHandler struct {
+	repo   domain.SavedSearchRepository
+	logger *zap.Logger
+}
+
+// NewSearchHandler creates a new saved search handler
+func NewSearchHandler(repo domain.SavedSearchRepository, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// SavedSearchRequest represents a request to create or replace a saved search
+type SavedSearchRequest struct {
+	Name   string                `json:"name" binding:"required"`
+	Filter domain.DownloadFilter `json:"filter"`
+}
+
+// ListSearches handles GET /api/searches
+func (h *SearchHandler) ListSearches(c *gin.Context) {
+	searches, err := h.repo.FindAllSavedSearches()
+	if err != nil {
+		h.logger.Error("Failed to list saved searches", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// CreateSearch handles POST /api/searches
+func (h *SearchHandler) CreateSearch(c *gin.Context) {
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := domain.NewSavedSearch(req.Name, req.Filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if existing, _ := h.repo.FindSavedSearch(search.Name); existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "a saved search with this name already exists"})
+		return
+	}
+
+	if err := h.repo.CreateSavedSearch(search); err != nil {
+		h.logger.Error("Failed to create saved search", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// GetSearch handles GET /api/searches/:id
+func (h *SearchHandler) GetSearch(c *gin.Context) {
+	search, ok := h.findSearch(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// UpdateSearch handles PUT /api/searches/:id
+func (h *SearchHandler) UpdateSearch(c *gin.Context) {
+	search, ok := h.findSearch(c)
+	if !ok {
+		return
+	}
+
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := domain.NewSavedSearch(req.Name, req.Filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if existing, _ := h.repo.FindSavedSearch(updated.Name); existing != nil && existing.ID != search.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "a saved search with this name already exists"})
+		return
+	}
+
+	search.Name = updated.Name
+	search.Filter = updated.Filter
+	if err := h.repo.UpdateSavedSearch(search); err != nil {
+		h.logger.Error("Failed to update saved search", zap.String("id", search.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSearch handles DELETE /api/searches/:id
+func (h *SearchHandler) DeleteSearch(c *gin.Context) {
+	search, ok := h.findSearch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteSavedSearch(search.ID); err != nil {
+		h.logger.Error("Failed to delete saved search", zap.String("id", search.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "saved search deleted"})
+}
+
+// GetSearchResults handles GET /api/searches/:id/results
+func (h *SearchHandler) GetSearchResults(c *gin.Context) {
+	search, ok := h.findSearch(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	downloads, err := h.repo.SearchDownloads(search.Filter, limit)
+	if err != nil {
+		h.logger.Error("Failed to run saved search", zap.String("id", search.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, downloads)
+}
+
+// SearchDownloads handles GET /api/v1/downloads/search?q=
+// Matches every term of q against URL, title, description, uploader and tags.
+// Optional status, platform, language and limit narrow the results.
+func (h *SearchHandler) SearchDownloads(c *gin.Context) {
+	filter := domain.DownloadFilter{
+		Text:     c.Query("q"),
+		Status:   domain.DownloadStatus(c.Query("status")),
+		Platform: domain.Platform(c.Query("platform")),
+		Language: c.Query("language"),
+	}
+	if len(domain.SearchTerms(filter.Text)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	downloads, err := h.repo.SearchDownloads(filter, limit)
+	if err != nil {
+		h.logger.Error("Failed to search downloads", zap.String("q", filter.Text), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, downloads)
+}
+
+// findSearch looks up the saved search named by the :id path parameter (ID or
+// name) and writes a 404 response if it does not exist
+func (h *SearchHandler) findSearch(c *gin.Context) (*domain.SavedSearch, bool) {
+	search, err := h.repo.FindSavedSearch(c.Param("id"))
+	if err != nil {
+		h.logger.Error("Failed to find saved search", zap.String("id", c.Param("id")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if search == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "saved search not found"})
+		return nil, false
+	}
+	return search, true
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// SelfTestHandler handles self-test HTTP requests
+type SelfTestHandler struct {
+	tester *app.SelfTester
+	logger *zap.Logger
+}
+
+// NewSelfTestHandler creates a new self-test handler
+func NewSelfTestHandler(tester *app.SelfTester, logger *zap.Logger) *SelfTestHandler {
+	return &SelfTestHandler{
+		tester: tester,
+		logger: logger,
+	}
+}
+
+// SelfTestRequest represents a request to run the self-test
+type SelfTestRequest struct {
+	Platforms []domain.Platform `json:"platforms,omitempty"` // Platforms to test (default: all)
+	URLs      map[string]string `json:"urls,omitempty"`      // URLs replacing selftest.urls, by platform
+}
+
+// RunSelfTest handles POST /api/v1/admin/selftest
+// Checks the download pipeline stage by stage and returns the results; a
+// failed stage is reported in the result, not as an error.
+func (h *SelfTestHandler) RunSelfTest(c *gin.Context) {
+	var req SelfTestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.tester.Run(c.Request.Context(), app.SelfTestOptions{Platforms: req.Platforms, URLs: req.URLs})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !result.Passed {
+		h.logger.Warn("Self-test failed", zap.Any("result", result))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
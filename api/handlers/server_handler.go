@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+)
+
+// ServerHandler handles requests about the server process itself
+type ServerHandler struct {
+	queueMgr *app.QueueManager
+}
+
+// NewServerHandler creates a new server handler
+func NewServerHandler(queueMgr *app.QueueManager) *ServerHandler {
+	return &ServerHandler{queueMgr: queueMgr}
+}
+
+// KeepAliveRequest represents a request to keep the server from auto-exiting
+type KeepAliveRequest struct {
+	TTL  string `json:"ttl,omitempty"`  // How long, e.g. "30m" (default: queue.keepalive_ttl)
+	Hold bool   `json:"hold,omitempty"` // Keep alive until DELETE /api/v1/server/keepalive
+}
+
+// GetKeepAlive handles GET /api/v1/server/keepalive
+func (h *ServerHandler) GetKeepAlive(c *gin.Context) {
+	c.JSON(http.StatusOK, h.queueMgr.KeepAliveStatus())
+}
+
+// KeepAlive handles POST /api/v1/server/keepalive
+// Keeps the server running on an empty queue for the ttl, or until released.
+func (h *ServerHandler) KeepAlive(c *gin.Context) {
+	var req KeepAliveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ttl, use a positive duration such as 30m"})
+			return
+		}
+		ttl = d
+	}
+
+	c.JSON(http.StatusOK, h.queueMgr.KeepAlive(ttl, req.Hold))
+}
+
+// ReleaseKeepAlive handles DELETE /api/v1/server/keepalive
+func (h *ServerHandler) ReleaseKeepAlive(c *gin.Context) {
+	c.JSON(http.StatusOK, h.queueMgr.ReleaseKeepAlive())
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// SettingsHandler handles runtime settings HTTP requests
+type SettingsHandler struct {
+	manager *app.SettingsManager
+	logger  *zap.Logger
+}
+
+// NewSettingsHandler creates a new settings handler
+func NewSettingsHandler(manager *app.SettingsManager, logger *zap.Logger) *SettingsHandler {
+	return &SettingsHandler{
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// GetSettings handles GET /api/v1/settings
+func (h *SettingsHandler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.Get())
+}
+
+// UpdateSettings handles PATCH /api/v1/settings
+// Applies the given settings to the running server, saves them to the config
+// file and returns all runtime settings.
+func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
+	var patch domain.RuntimeSettingsPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := patch.ConfigValues(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.manager.Update(patch)
+	if err != nil {
+		h.logger.Error("Failed to update settings", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetEffectiveConfig handles GET /api/v1/admin/config and GET /api/v1/config
+// Returns every config key with its default, effective value, source
+// (default, system config, user override or environment) and whether it
+// applies without a restart, secrets redacted.
+func (h *SettingsHandler) GetEffectiveConfig(c *gin.Context) {
+	config, err := h.manager.EffectiveConfig()
+	if err != nil {
+		h.logger.Error("Failed to describe config", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, config)
+}
+
+// ReloadConfig handles POST /api/v1/config/reload
+// Reads the config files again and applies the changed runtime settings and
+// log level; returns the applied keys and the changed keys that need a
+// restart. A config that does not load or validate is rejected with 400.
+func (h *SettingsHandler) ReloadConfig(c *gin.Context) {
+	result, err := h.manager.Reload()
+	if err != nil {
+		h.logger.Warn("Config reload rejected", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateConfigRequest sets config keys, e.g. {"values": {"queue.check_interval": "5s"}}
+type UpdateConfigRequest struct {
+	Values map[string]interface{} `json:"values" binding:"required"`
+}
+
+// UpdateConfig handles PUT /api/v1/config
+// Saves the given keys to the user override config file, validated as at
+// startup, and applies what takes effect without a restart; returns the
+// applied keys and the changed keys that need a restart.
+func (h *SettingsHandler) UpdateConfig(c *gin.Context) {
+	var req UpdateConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.manager.UpdateConfig(req.Values)
+	if err != nil {
+		var invalid *app.InvalidConfigError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to update config", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// SubscriptionHandler handles channel subscription HTTP requests
+type SubscriptionHandler struct {
+	subscriber *app.Subscriber
+	logger     *zap.Logger
+}
+
+// NewSubscriptionHandler creates a new subscription handler
+func NewSubscriptionHandler(subscriber *app.Subscriber, logger *zap.Logger) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriber: subscriber,
+		logger:     logger,
+	}
+}
+
+// CreateSubscriptionRequest represents a request to subscribe to a channel
+type CreateSubscriptionRequest struct {
+	URL      string `json:"url" binding:"required"`
+	Name     string `json:"name,omitempty"`
+	Profile  string `json:"profile,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	Backfill bool   `json:"backfill,omitempty"`
+}
+
+// UpdateSubscriptionRequest represents a request to update a subscription
+type UpdateSubscriptionRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Interval *string `json:"interval,omitempty"`
+	Enabled  *bool   `json:"enabled,omitempty"`
+}
+
+// CreateSubscription handles POST /api/v1/subscriptions
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.subscriber.Subscribe(req.URL, app.SubscribeOptions{
+		Name:     req.Name,
+		Profile:  req.Profile,
+		Interval: req.Interval,
+		Backfill: req.Backfill,
+	})
+	var duplicate *domain.DuplicateSubscriptionError
+	if errors.As(err, &duplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": duplicate.Error(), "subscription": duplicate.Existing})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// ListSubscriptions handles GET /api/v1/subscriptions
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.subscriber.ListSubscriptions()
+	if err != nil {
+		h.logger.Error("Failed to list subscriptions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscription handles GET /api/v1/subscriptions/:id
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	subscription, err := h.subscriber.GetSubscription(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateSubscription handles PATCH /api/v1/subscriptions/:id
+func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	var req UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.subscriber.GetSubscription(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+
+	subscription, err := h.subscriber.UpdateSubscription(id, app.SubscriptionUpdate{
+		Name:     req.Name,
+		Interval: req.Interval,
+		Enabled:  req.Enabled,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription handles DELETE /api/v1/subscriptions/:id
+func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.subscriber.GetSubscription(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+
+	if err := h.subscriber.Unsubscribe(id); err != nil {
+		h.logger.Error("Failed to delete subscription", zap.String("id", id), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "subscription deleted"})
+}
+
+// CheckSubscription handles POST /api/v1/subscriptions/:id/check
+func (h *SubscriptionHandler) CheckSubscription(c *gin.Context) {
+	subscription, err := h.subscriber.CheckNow(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
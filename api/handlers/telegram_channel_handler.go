@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TelegramChannelHandler handles Telegram channel list HTTP requests
+type TelegramChannelHandler struct {
+	syncer    *app.ChannelSyncer
+	aliasRepo domain.TelegramChannelAliasRepository
+	logger    *zap.Logger
+}
+
+// NewTelegramChannelHandler creates a new Telegram channel handler
+func NewTelegramChannelHandler(syncer *app.ChannelSyncer, aliasRepo domain.TelegramChannelAliasRepository, logger *zap.Logger) *TelegramChannelHandler {
+	return &TelegramChannelHandler{
+		syncer:    syncer,
+		aliasRepo: aliasRepo,
+		logger:    logger,
+	}
+}
+
+// ListChannels handles GET /api/v1/telegram/channels
+// Returns the stored channels and the result of the last sync.
+func (h *TelegramChannelHandler) ListChannels(c *gin.Context) {
+	channels, err := h.syncer.Channels()
+	if err != nil {
+		h.logger.Error("Failed to list Telegram channels", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"channels": channels, "last_sync": h.syncer.LastResult()})
+}
+
+// SyncChannels handles POST /api/v1/telegram/channels/sync
+// Refreshes the channel list with tdl now and returns the renamed channels.
+func (h *TelegramChannelHandler) SyncChannels(c *gin.Context) {
+	result, err := h.syncer.Sync(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to sync Telegram channels", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RerenderChannelRequest represents a request to re-render a channel's downloads
+type RerenderChannelRequest struct {
+	DryRun bool `json:"dry_run,omitempty"` // Only count what would change
+}
+
+// RerenderChannel handles POST /api/v1/telegram/channels/:id/rerender
+// Updates the metadata, sidecars and file names of the channel's downloads
+// saved under an older name to its current name.
+func (h *TelegramChannelHandler) RerenderChannel(c *gin.Context) {
+	var req RerenderChannelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.syncer.Rerender(c.Param("id"), req.DryRun)
+	if err != nil {
+		h.logger.Error("Failed to re-render Telegram channel", zap.String("channel_id", c.Param("id")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "channel not found"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListAliases handles GET /api/v1/telegram/channels/aliases
+func (h *TelegramChannelHandler) ListAliases(c *gin.Context) {
+	aliases, err := h.aliasRepo.ListChannelAliases()
+	if err != nil {
+		h.logger.Error("Failed to list Telegram channel aliases", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+}
+
+// SetAliasRequest represents a request to record the successor of a channel
+type SetAliasRequest struct {
+	SuccessorID string `json:"successor_id" binding:"required"` // Numeric ID, username or t.me link
+}
+
+// SetAlias handles PUT /api/v1/telegram/channels/:id/alias
+// Records that the channel continues as another one, so both count as one source.
+func (h *TelegramChannelHandler) SetAlias(c *gin.Context) {
+	var req SetAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias := &domain.TelegramChannelAlias{
+		ChannelID:   domain.NormalizeTelegramChannelKey(c.Param("id")),
+		SuccessorID: domain.NormalizeTelegramChannelKey(req.SuccessorID),
+	}
+	stored, err := h.aliasRepo.ListChannelAliases()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := domain.ValidateTelegramChannelAlias(domain.NewTelegramChannelAliases(stored), alias.ChannelID, alias.SuccessorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.aliasRepo.SaveChannelAlias(alias); err != nil {
+		h.logger.Error("Failed to save Telegram channel alias", zap.String("channel_id", alias.ChannelID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, alias)
+}
+
+// DeleteAlias handles DELETE /api/v1/telegram/channels/:id/alias
+func (h *TelegramChannelHandler) DeleteAlias(c *gin.Context) {
+	deleted, err := h.aliasRepo.DeleteChannelAlias(domain.NormalizeTelegramChannelKey(c.Param("id")))
+	if err != nil {
+		h.logger.Error("Failed to delete Telegram channel alias", zap.String("channel_id", c.Param("id")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "channel has no alias"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "channel alias deleted"})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// UploaderHandler handles uploader/channel browse requests
+type UploaderHandler struct {
+	repo   domain.UploaderRepository
+	logger *zap.Logger
+}
+
+// NewUploaderHandler creates a new uploader handler
+func NewUploaderHandler(repo domain.UploaderRepository, logger *zap.Logger) *UploaderHandler {
+	return &UploaderHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListUploaders handles GET /api/uploaders
+func (h *UploaderHandler) ListUploaders(c *gin.Context) {
+	filter := domain.UploaderFilter{
+		Platform: domain.Platform(c.Query("platform")),
+		Sort:     c.Query("sort"),
+	}
+	if filter.Sort != "" && !domain.ValidUploaderSorts[filter.Sort] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort: must be one of latest, count, size, name"})
+		return
+	}
+
+	uploaders, err := h.repo.ListUploaders(filter)
+	if err != nil {
+		h.logger.Error("Failed to list uploaders", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, uploaders)
+}
+
+// ListUploaderDownloads handles GET /api/uploaders/:key/downloads
+func (h *UploaderHandler) ListUploaderDownloads(c *gin.Context) {
+	key := c.Param("key")
+
+	downloads, err := h.repo.FindByUploader(domain.Platform(c.Query("platform")), key)
+	if err != nil {
+		h.logger.Error("Failed to list uploader downloads", zap.String("key", key), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, downloads)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// AuthTokenNameKey is the gin context key of the name of the API token a
+// request was authenticated with
+const AuthTokenNameKey = "auth_token_name"
+
+// AuthCookie is the cookie holding the API token of a signed-in dashboard
+const AuthCookie = "x_extract_token"
+
+// authCookieMaxAge is how long a signed-in dashboard stays signed in, in
+// seconds
+const authCookieMaxAge = 365 * 24 * 60 * 60
+
+// Auth returns a gin middleware that requires one of the API tokens of
+// config (server.auth_token, server.auth_tokens), sent as
+// "Authorization: Bearer <token>", as the token query parameter (WebSocket
+// clients can't set headers) or in the dashboard's cookie. Without tokens
+// every request passes.
+func Auth(config *domain.ServerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.AuthEnabled() {
+			c.Next()
+			return
+		}
+
+		name, ok := config.MatchAuthToken(requestToken(c))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API token"})
+			return
+		}
+		c.Set(AuthTokenNameKey, name)
+		c.Next()
+	}
+}
+
+// requestToken returns the API token a request carries, if any
+func requestToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+	token, _ := c.Cookie(AuthCookie)
+	return token
+}
+
+// DashboardSignIn returns a gin middleware for the dashboard's pages that
+// signs the browser in: a valid token query parameter is stored in the
+// dashboard's cookie (HttpOnly, SameSite=Strict) and the page is reloaded
+// without it, so the token doesn't stay in the address bar or history.
+func DashboardSignIn(config *domain.ServerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" || !config.AuthEnabled() {
+			c.Next()
+			return
+		}
+		if _, ok := config.MatchAuthToken(token); !ok {
+			c.Next()
+			return
+		}
+
+		c.SetSameSite(http.SameSiteStrictMode)
+		c.SetCookie(AuthCookie, token, authCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+		query := c.Request.URL.Query()
+		query.Del("token")
+		location := *c.Request.URL
+		location.RawQuery = query.Encode()
+		c.Redirect(http.StatusFound, location.RequestURI())
+		c.Abort()
+	}
+}
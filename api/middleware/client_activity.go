@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/x-extract-go/internal/app"
+)
+
+// ClientActivity returns a gin middleware that records requests in clients so
+// auto-exit waits for the dashboard. WebSocket requests count as a session
+// until the connection closes. Health checks (the CLI's server probe) and
+// metrics scrapes are not counted.
+func ClientActivity(clients *app.ClientTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path == "/health" || path == "/ready" || path == "/metrics" {
+			c.Next()
+			return
+		}
+
+		if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			clients.SessionStarted(time.Now())
+			defer func() { clients.SessionEnded(time.Now()) }()
+		} else {
+			clients.Touch(time.Now())
+		}
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +13,7 @@ func Logger(log *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redactToken(c.Request.URL.RawQuery)
 
 		c.Next()
 
@@ -21,13 +22,27 @@ func Logger(log *zap.Logger) gin.HandlerFunc {
 		clientIP := c.ClientIP()
 		method := c.Request.Method
 
-		log.Info("HTTP request",
+		fields := []zap.Field{
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.String("query", query),
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
 			zap.String("client_ip", clientIP),
-		)
+		}
+		if name := c.GetString(AuthTokenNameKey); name != "" {
+			fields = append(fields, zap.String("token", name))
+		}
+		log.Info("HTTP request", fields...)
 	}
 }
+
+// redactToken replaces the value of an API token query parameter
+func redactToken(rawQuery string) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil || !query.Has("token") {
+		return rawQuery
+	}
+	query.Set("token", "REDACTED")
+	return query.Encode()
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/yourusername/x-extract-go/api/handlers"
 	"github.com/yourusername/x-extract-go/api/middleware"
 	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
 	"github.com/yourusername/x-extract-go/pkg/logger"
 	dashboard "github.com/yourusername/x-extract-go/web-dashboard"
 )
@@ -36,6 +37,26 @@ func SetupRouterWithMultiLogger(
 	downloadMgr *app.DownloadManager,
 	logAdapter *logger.LoggerAdapter,
 	logsDir string,
+	uploaderRepo domain.UploaderRepository,
+	searchRepo domain.SavedSearchRepository,
+	scheduler *app.Scheduler,
+	subscriber *app.Subscriber,
+	cookieMonitors map[domain.Platform]*app.CookieMonitor,
+	reportGenerator *app.ReportGenerator,
+	retentionMgr *app.RetentionManager,
+	settingsMgr *app.SettingsManager,
+	clientTracker *app.ClientTracker,
+	metrics *app.Metrics,
+	platformHealth *app.PlatformHealthChecker,
+	thumbnailer domain.Thumbnailer,
+	files domain.FileStore,
+	metadataRegenerator *app.MetadataRegenerator,
+	maintenanceWindow *app.MaintenanceWindow,
+	channelSyncer *app.ChannelSyncer,
+	channelAliasRepo domain.TelegramChannelAliasRepository,
+	archiver *app.Archiver,
+	selfTester *app.SelfTester,
+	serverConfig *domain.ServerConfig,
 ) *gin.Engine {
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
@@ -46,30 +67,175 @@ func SetupRouterWithMultiLogger(
 	router.Use(middleware.Logger(logAdapter.GetSingleLogger()))
 	router.Use(middleware.Recovery(logAdapter.GetSingleLogger()))
 	router.Use(middleware.CORS())
+	router.Use(middleware.ClientActivity(clientTracker))
 
 	// Health endpoints
-	healthHandler := handlers.NewHealthHandler(queueMgr)
+	healthHandler := handlers.NewHealthHandler(queueMgr, platformHealth)
 	router.GET("/health", healthHandler.Health)
 	router.GET("/ready", healthHandler.Ready)
 
-	// API v1 routes
-	v1 := router.Group("/api/v1")
+	// Prometheus metrics
+	metricsHandler := handlers.NewMetricsHandler(metrics)
+	router.GET("/metrics", metricsHandler.GetMetrics)
+
+	// API v1 routes, behind the API tokens when set
+	v1 := router.Group("/api/v1", middleware.Auth(serverConfig))
 	{
+		v1.GET("/version", healthHandler.Version)
+
 		// Download endpoints
 		downloadHandler := handlers.NewDownloadHandler(queueMgr, downloadMgr, logAdapter.GetSingleLogger())
 		logHandler := handlers.NewLogHandler(logsDir)
+		searchHandler := handlers.NewSearchHandler(searchRepo, logAdapter.GetSingleLogger())
+		mediaHandler := handlers.NewMediaHandler(queueMgr, thumbnailer, files, logAdapter.GetSingleLogger())
+		archiveHandler := handlers.NewArchiveHandler(archiver, queueMgr, logAdapter.GetSingleLogger())
 		downloads := v1.Group("/downloads")
 		{
 			downloads.POST("", downloadHandler.AddDownload)
 			downloads.GET("", downloadHandler.ListDownloads)
 			downloads.GET("/stats", downloadHandler.GetStats)
+			downloads.GET("/stats/history", downloadHandler.GetStatsHistory)
+			downloads.GET("/search", searchHandler.SearchDownloads)
+			downloads.GET("/failed", downloadHandler.ListFailed)
+			downloads.POST("/retry-all", downloadHandler.RetryAll)
+			downloads.GET("/duplicates", downloadHandler.GetDuplicates)
+			downloads.POST("/simulate", downloadHandler.SimulateDownload)
+			downloads.GET("/export", downloadHandler.ExportDownloads)
 			downloads.GET("/:id", downloadHandler.GetDownload)
+			downloads.PATCH("/:id", downloadHandler.UpdateDownload)
 			downloads.GET("/:id/progress", logHandler.GetDownloadProgress)
+			downloads.GET("/:id/files", mediaHandler.ListFiles)
+			downloads.GET("/:id/files/:index/content", mediaHandler.GetFileContent)
+			downloads.GET("/:id/files/:index/thumbnail", mediaHandler.GetFileThumbnail)
 			downloads.POST("/:id/cancel", downloadHandler.CancelDownload)
 			downloads.POST("/:id/retry", downloadHandler.RetryDownload)
+			downloads.POST("/:id/archive", archiveHandler.ArchiveDownload)
 			downloads.DELETE("/:id", downloadHandler.DeleteDownload)
 		}
 
+		// Uploader endpoints
+		uploaderHandler := handlers.NewUploaderHandler(uploaderRepo, logAdapter.GetSingleLogger())
+		uploaders := v1.Group("/uploaders")
+		{
+			uploaders.GET("", uploaderHandler.ListUploaders)
+			uploaders.GET("/:key/downloads", uploaderHandler.ListUploaderDownloads)
+		}
+
+		// Saved search endpoints
+		searches := v1.Group("/searches")
+		{
+			searches.GET("", searchHandler.ListSearches)
+			searches.POST("", searchHandler.CreateSearch)
+			searches.GET("/:id", searchHandler.GetSearch)
+			searches.PUT("/:id", searchHandler.UpdateSearch)
+			searches.DELETE("/:id", searchHandler.DeleteSearch)
+			searches.GET("/:id/results", searchHandler.GetSearchResults)
+		}
+
+		// Schedule endpoints (only when the scheduler is enabled)
+		if scheduler != nil {
+			scheduleHandler := handlers.NewScheduleHandler(scheduler, logAdapter.GetSingleLogger())
+			schedules := v1.Group("/schedules")
+			{
+				schedules.POST("", scheduleHandler.CreateSchedule)
+				schedules.GET("", scheduleHandler.ListSchedules)
+				schedules.GET("/:id", scheduleHandler.GetSchedule)
+				schedules.PATCH("/:id", scheduleHandler.UpdateSchedule)
+				schedules.DELETE("/:id", scheduleHandler.DeleteSchedule)
+				schedules.POST("/:id/run", scheduleHandler.RunSchedule)
+			}
+		}
+
+		// Subscription endpoints (only when subscriptions are enabled)
+		if subscriber != nil {
+			subscriptionHandler := handlers.NewSubscriptionHandler(subscriber, logAdapter.GetSingleLogger())
+			subscriptions := v1.Group("/subscriptions")
+			{
+				subscriptions.POST("", subscriptionHandler.CreateSubscription)
+				subscriptions.GET("", subscriptionHandler.ListSubscriptions)
+				subscriptions.GET("/:id", subscriptionHandler.GetSubscription)
+				subscriptions.PATCH("/:id", subscriptionHandler.UpdateSubscription)
+				subscriptions.DELETE("/:id", subscriptionHandler.DeleteSubscription)
+				subscriptions.POST("/:id/check", subscriptionHandler.CheckSubscription)
+			}
+		}
+
+		// Archive report endpoints
+		reportHandler := handlers.NewReportHandler(reportGenerator, logAdapter.GetSingleLogger())
+		reports := v1.Group("/reports")
+		{
+			reports.POST("", reportHandler.GenerateReport)
+			reports.GET("", reportHandler.ListReports)
+			reports.GET("/:name", reportHandler.GetReport)
+		}
+
+		// Retention endpoints
+		retentionHandler := handlers.NewRetentionHandler(retentionMgr, logAdapter.GetSingleLogger())
+		v1.POST("/retention/run", retentionHandler.RunRetention)
+
+		// Archive endpoints (move completed files to another volume)
+		v1.POST("/archive", archiveHandler.Archive)
+
+		// Runtime settings endpoints
+		settingsHandler := handlers.NewSettingsHandler(settingsMgr, logAdapter.GetSingleLogger())
+		v1.GET("/settings", settingsHandler.GetSettings)
+		v1.PATCH("/settings", settingsHandler.UpdateSettings)
+		v1.GET("/admin/config", settingsHandler.GetEffectiveConfig)
+		v1.GET("/config", settingsHandler.GetEffectiveConfig)
+		v1.PUT("/config", settingsHandler.UpdateConfig)
+		v1.POST("/config/reload", settingsHandler.ReloadConfig)
+
+		// Self-test of the download pipeline
+		selfTestHandler := handlers.NewSelfTestHandler(selfTester, logAdapter.GetSingleLogger())
+		v1.POST("/admin/selftest", selfTestHandler.RunSelfTest)
+
+		// Maintenance endpoints
+		maintenanceHandler := handlers.NewMaintenanceHandler(metadataRegenerator, maintenanceWindow)
+		maintenance := v1.Group("/maintenance")
+		{
+			maintenance.POST("/regenerate-metadata", maintenanceHandler.RegenerateMetadata)
+			maintenance.GET("/regenerate-metadata", maintenanceHandler.GetRegenerateMetadata)
+			maintenance.GET("/window", maintenanceHandler.GetWindow)
+		}
+
+		// Telegram channel list endpoints (sync, re-render renamed channels, aliases)
+		channelHandler := handlers.NewTelegramChannelHandler(channelSyncer, channelAliasRepo, logAdapter.GetSingleLogger())
+		telegram := v1.Group("/telegram/channels")
+		{
+			telegram.GET("", channelHandler.ListChannels)
+			telegram.POST("/sync", channelHandler.SyncChannels)
+			telegram.POST("/:id/rerender", channelHandler.RerenderChannel)
+			telegram.GET("/aliases", channelHandler.ListAliases)
+			telegram.PUT("/:id/alias", channelHandler.SetAlias)
+			telegram.DELETE("/:id/alias", channelHandler.DeleteAlias)
+		}
+
+		// Server keepalive endpoints (suppress auto-exit)
+		serverHandler := handlers.NewServerHandler(queueMgr)
+		server := v1.Group("/server")
+		{
+			server.GET("/keepalive", serverHandler.GetKeepAlive)
+			server.POST("/keepalive", serverHandler.KeepAlive)
+			server.DELETE("/keepalive", serverHandler.ReleaseKeepAlive)
+		}
+
+		// Platform status endpoints (cookie health)
+		platformHandler := handlers.NewPlatformHandler(cookieMonitors)
+		v1.GET("/platforms/:platform/status", platformHandler.GetStatus)
+
+		// Cookie file endpoints (status and import)
+		cookieHandler := handlers.NewCookieHandler(cookieMonitors)
+		cookies := v1.Group("/cookies")
+		{
+			cookies.GET("", cookieHandler.ListCookies)
+			cookies.GET("/:platform", cookieHandler.GetCookies)
+			cookies.PUT("/:platform", cookieHandler.ImportCookies)
+		}
+
+		// Queue event replay, read from the queue logs
+		v1.GET("/queue/events", logHandler.GetQueueEvents)
+		v1.POST("/queue/reorder", downloadHandler.ReorderQueue)
+
 		// Log endpoints
 		logs := v1.Group("/logs")
 		{
@@ -90,12 +256,12 @@ func SetupRouterWithMultiLogger(
 	})
 
 	// Explicitly handle root path
-	router.GET("/", func(c *gin.Context) {
+	router.GET("/", middleware.DashboardSignIn(serverConfig), func(c *gin.Context) {
 		serveIndexHTML(c, dashboardFS)
 	})
 
 	// Serve all other routes with SPA routing
-	router.NoRoute(func(c *gin.Context) {
+	router.NoRoute(middleware.DashboardSignIn(serverConfig), func(c *gin.Context) {
 		path := c.Request.URL.Path
 
 		// Don't serve dashboard for API routes
@@ -118,6 +118,11 @@ async function addDownload(url) {
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ url }),
   });
+  if (resp.status === 409) {
+    // Already queued or downloaded
+    const body = await resp.json().catch(() => ({}));
+    return { ...body.download, duplicate: true };
+  }
   if (!resp.ok) {
     const body = await resp.json().catch(() => ({}));
     throw new Error(body.error || `HTTP ${resp.status}`);
@@ -151,13 +156,13 @@ function createButton(tweetUrl) {
     tooltip.textContent = 'Adding…';
 
     try {
-      await addDownload(tweetUrl);
+      const result = await addDownload(tweetUrl);
 
       // --- success ---
       btn.innerHTML = ICON_OK;
       btn.appendChild(tooltip);
       btn.classList.add('xe-ok');
-      tooltip.textContent = 'Added!';
+      tooltip.textContent = result.duplicate ? 'Already added' : 'Added!';
 
       setTimeout(() => {
         btn.innerHTML = ICON_DOWNLOAD;
@@ -200,6 +200,11 @@ async function addDownload(url) {
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ url }),
   });
+  if (resp.status === 409) {
+    // Already queued or downloaded
+    const body = await resp.json().catch(() => ({}));
+    return { ...body.download, duplicate: true };
+  }
   if (!resp.ok) {
     const body = await resp.json().catch(() => ({}));
     throw new Error(body.error || `HTTP ${resp.status}`);
@@ -233,12 +238,12 @@ function createButton(postUrl) {
     tooltip.textContent = 'Adding…';
 
     try {
-      await addDownload(postUrl);
+      const result = await addDownload(postUrl);
 
       btn.innerHTML = ICON_OK;
       btn.appendChild(tooltip);
       btn.classList.add('xei-ok');
-      tooltip.textContent = 'Added!';
+      tooltip.textContent = result.duplicate ? 'Already added' : 'Added!';
 
       setTimeout(() => {
         btn.innerHTML = ICON_DOWNLOAD;
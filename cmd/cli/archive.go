@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [download-id]",
+	Short: "Move completed downloads to an archive volume",
+	Long: `Move the files of completed downloads, with their .info.json and
+.description.txt, to another volume such as an external drive. Files keep
+their path relative to the completed directory. The downloads stay completed
+and point at the new location, so they are still served and are not
+downloaded again, even while the volume is not mounted.
+
+Without a download ID, every download completed before --older-than is
+archived.`,
+	Example: `  x-extract archive --older-than 30d --dest /Volumes/Archive --dry-run
+  x-extract archive --older-than 30d --dest /Volumes/Archive
+  x-extract archive 550e8400-e29b-41d4-a716-446655440000 --dest /Volumes/Archive`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dest, _ := cmd.Flags().GetString("dest")
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dest == "" {
+			fmt.Fprintln(os.Stderr, "Error: --dest is required")
+			os.Exit(1)
+		}
+		if len(args) == 0 && olderThan == "" {
+			fmt.Fprintln(os.Stderr, "Error: --older-than is required without a download ID")
+			os.Exit(1)
+		}
+		if olderThan != "" {
+			if _, err := domain.ParseAge(olderThan); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		// The server resolves the destination; make it independent of this directory
+		dest, err := filepath.Abs(dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ensureServer()
+
+		verb := "Archived"
+		if dryRun {
+			verb = "Would archive"
+		}
+		if len(args) == 1 {
+			archived := doJSONRequest(http.MethodPost, "/api/v1/downloads/"+args[0]+"/archive",
+				map[string]interface{}{"dest": dest, "dry_run": dryRun}, http.StatusOK)
+			files, _ := archived["files"].([]interface{})
+			for _, file := range files {
+				fmt.Printf("  %v\n", file)
+			}
+			fmt.Printf("%s %v (%d files)\n", verb, archived["id"], len(files))
+			return
+		}
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/archive",
+			map[string]interface{}{"dest": dest, "older_than": olderThan, "dry_run": dryRun}, http.StatusOK)
+
+		downloads, _ := result["downloads"].([]interface{})
+		if len(downloads) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSIZE\tCOMPLETED\tURL")
+			for _, d := range downloads {
+				dl, _ := d.(map[string]interface{})
+				size, _ := dl["file_size"].(float64)
+				fmt.Fprintf(w, "%v\t%s\t%v\t%v\n", dl["id"], domain.FormatBytes(int64(size)), dl["completed_at"], dl["url"])
+			}
+			w.Flush()
+		}
+
+		moved, _ := result["moved_bytes"].(float64)
+		fmt.Printf("%s %v downloads (%s) to %s\n", verb, result["archived"], domain.FormatBytes(int64(moved)), dest)
+		if failed, _ := result["failed"].(float64); failed > 0 {
+			fmt.Fprintf(os.Stderr, "%d downloads could not be archived; see the app log\n", int(failed))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	archiveCmd.Flags().String("dest", "", "Directory on the archive volume (required)")
+	archiveCmd.Flags().String("older-than", "", "Archive downloads completed longer ago than this (e.g. 30d, 2w, 36h)")
+	archiveCmd.Flags().Bool("dry-run", false, "Only list the downloads that would be archived")
+
+	rootCmd.AddCommand(archiveCmd)
+}
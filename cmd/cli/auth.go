@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// apiTokenEnv is the environment variable the CLI reads the API token from
+// when --token is not given
+const apiTokenEnv = "X_EXTRACT_TOKEN"
+
+// getDefaultAPIToken returns the API token from $X_EXTRACT_TOKEN or, when it
+// is unset, server.auth_token of config (which may be nil)
+func getDefaultAPIToken(config *domain.Config) string {
+	if token := os.Getenv(apiTokenEnv); token != "" {
+		return token
+	}
+	if config == nil {
+		return ""
+	}
+	return config.Server.AuthToken
+}
+
+// tokenTransport sends the API token with the requests to the server, and
+// only to it (not e.g. to GitHub when installing tools)
+type tokenTransport struct {
+	base  http.RoundTripper
+	host  string
+	token string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+// useAPIToken makes every HTTP request of the CLI to server carry token.
+// Requests go through http.DefaultTransport whichever client sends them.
+func useAPIToken(token, server string) {
+	u, err := url.Parse(server)
+	if token == "" || err != nil {
+		return
+	}
+	http.DefaultTransport = &tokenTransport{base: http.DefaultTransport, host: u.Host, token: token}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect, change and reload the configuration",
+}
+
+var configDocCmd = &cobra.Command{
+	Use:   "doc [prefix]",
+	Short: "List every config key with its type, default and effective value",
+	Long: `List every config key with its type, built-in default and effective value
+after the system config and the user override (base_dir/config/config.yaml)
+are merged, and which of them set it. Keys marked * differ from the default.
+A prefix such as "queue" or "twitter.cookie" limits the list.`,
+	Example: `  x-extract config doc
+  x-extract config doc queue
+  x-extract config doc --changed`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Note: This command doesn't need the server running
+		changedOnly, _ := cmd.Flags().GetBool("changed")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		config, err := app.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		options, err := app.DescribeConfig(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var shown []app.ConfigOption
+		for _, option := range options {
+			if len(args) > 0 && !strings.HasPrefix(option.Key, args[0]) {
+				continue
+			}
+			if changedOnly && !option.Changed() {
+				continue
+			}
+			shown = append(shown, option)
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(shown, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		fmt.Printf("System config: %s\n", domain.DefaultConfigPath())
+		userPath := app.UserConfigPath(config)
+		if _, err := os.Stat(userPath); err == nil {
+			fmt.Printf("User override: %s\n", userPath)
+		} else {
+			fmt.Printf("User override: %s (not present)\n", userPath)
+		}
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT\tVALUE\tSOURCE")
+		for _, option := range shown {
+			marker := ""
+			if option.Changed() {
+				marker = " *"
+			}
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\n",
+				option.Key, marker, option.Type,
+				truncate(option.Default, 40), truncate(option.Value, 40), option.Source)
+		}
+		w.Flush()
+	},
+}
+
+var configReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Apply config file changes to the running server",
+	Long: `Make the running server read its config files again. Changed runtime
+settings (queue, retries, concurrency, rate limits, notifications) and
+logging.level take effect at once; other changed keys are listed and take
+effect on the next start. A config that does not load or validate is
+rejected and the server keeps its current settings.
+
+Sending SIGHUP to the server does the same.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printConfigReload(doJSONRequest("POST", "/api/v1/config/reload", nil, 200))
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set key=value...",
+	Short: "Change config keys of the running server",
+	Long: `Save config keys to the user override (base_dir/config/config.yaml) and
+apply them to the running server. Values are YAML, e.g. 5s, true, 3 or
+{x: 2MiB}. The changed config is validated as at startup and rejected as a
+whole when invalid. Keys that only take effect on the next start are listed.
+
+Keys that run commands or point at binaries, files or directories, and the
+storage, retention and server access keys, can only be changed in the config
+file.`,
+	Example: `  x-extract config set queue.check_interval=5s download.max_retries=5
+  x-extract config set 'download.rate_limit_overrides={telegram: 1MiB}'`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		values := make(map[string]interface{})
+		for _, arg := range args {
+			key, raw, ok := strings.Cut(arg, "=")
+			if !ok || key == "" {
+				fmt.Fprintf(os.Stderr, "Error: %q is not key=value\n", arg)
+				os.Exit(1)
+			}
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+				value = raw
+			}
+			values[key] = value
+		}
+		printConfigReload(doJSONRequest("PUT", "/api/v1/config", map[string]interface{}{"values": values}, 200))
+	},
+}
+
+// printConfigReload prints the applied and restart-required keys of a config
+// reload or update
+func printConfigReload(result map[string]interface{}) {
+	applied, _ := result["applied"].([]interface{})
+	restart, _ := result["restart_required"].([]interface{})
+	if len(applied) == 0 && len(restart) == 0 {
+		fmt.Println("No config changes")
+		return
+	}
+	for _, key := range applied {
+		fmt.Printf("Applied: %v\n", key)
+	}
+	for _, key := range restart {
+		fmt.Printf("Needs restart: %v\n", key)
+	}
+}
+
+func init() {
+	configDocCmd.Flags().Bool("changed", false, "Only show keys whose value differs from the default")
+	configDocCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	configCmd.AddCommand(configDocCmd)
+	configCmd.AddCommand(configReloadCmd)
+	configCmd.AddCommand(configSetCmd)
+	rootCmd.AddCommand(configCmd)
+}
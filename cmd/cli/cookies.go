@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var cookiesCmd = &cobra.Command{
+	Use:   "cookies",
+	Short: "Check and replace platform cookie files",
+	Long: `Check the cookie files downloads authenticate with, and replace them with a
+refreshed export. Expired X cookies otherwise only show up as failing yt-dlp
+downloads.
+
+Cookie files use the Netscape cookies.txt format exported by browser
+extensions such as "Get cookies.txt LOCALLY".`,
+}
+
+var cookiesStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state and expiry of each cookie file",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		refresh, _ := cmd.Flags().GetBool("refresh")
+
+		apiPath := "/api/v1/cookies"
+		if refresh {
+			apiPath += "?refresh=true"
+		}
+		var result struct {
+			Cookies []map[string]interface{} `json:"cookies"`
+		}
+		json.Unmarshal(doGetRequest(apiPath), &result)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLATFORM\tSTATE\tAUTH_TOKEN EXPIRES\tFILE\tMESSAGE")
+		for _, status := range result.Cookies {
+			fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%v\n",
+				status["platform"],
+				status["state"],
+				valueOrDash(status["auth_token_expires_at"]),
+				status["cookie_file"],
+				valueOrDash(status["message"]))
+		}
+		w.Flush()
+	},
+}
+
+var cookiesImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Replace a platform's cookie file with a refreshed export",
+	Long: `Validate a Netscape cookie file and, if its auth cookies are present and not
+expired, install it as the platform's cookie file (twitter.cookie_file for X).
+An invalid file is rejected and the current cookies stay in use.`,
+	Example: `  x-extract cookies import ~/Downloads/x.com_cookies.txt`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		platform, _ := cmd.Flags().GetString("platform")
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ensureServer()
+		req, err := http.NewRequest(http.MethodPut, serverURL+"/api/v1/cookies/"+platform, bytes.NewReader(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		req.Header.Set("Content-Type", "text/plain")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		var result map[string]interface{}
+		json.Unmarshal(body, &result)
+		if resp.StatusCode != http.StatusOK {
+			if result["error"] != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", result["error"])
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", string(body))
+			}
+			os.Exit(1)
+		}
+
+		status, _ := result["cookie"].(map[string]interface{})
+		fmt.Printf("Cookies imported to %s\n", status["cookie_file"])
+		fmt.Printf("State:   %s\n", status["state"])
+		if status["auth_token_expires_at"] != nil {
+			fmt.Printf("Expires: %s\n", status["auth_token_expires_at"])
+		}
+		if status["message"] != nil {
+			fmt.Printf("Message: %s\n", status["message"])
+		}
+	},
+}
+
+func init() {
+	cookiesStatusCmd.Flags().Bool("refresh", false, "Check the cookies now instead of showing the last check")
+	cookiesImportCmd.Flags().StringP("platform", "p", "x", "Platform whose cookie file to replace")
+
+	cookiesCmd.AddCommand(cookiesStatusCmd)
+	cookiesCmd.AddCommand(cookiesImportCmd)
+	rootCmd.AddCommand(cookiesCmd)
+}
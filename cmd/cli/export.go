@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export downloads as CSV or JSON lines",
+	Long: `Write every download, with its title, uploader, upload date, tags, files
+and sizes, as CSV (one row per download) or JSON lines, to load the archive
+into a spreadsheet or another catalog. Lists in CSV cells (tags, files) are
+separated by "|".
+
+When the server is not running (or with --offline) the database is read
+directly instead of starting the server.`,
+	Example: `  x-extract export -o downloads.csv
+  x-extract export --format jsonl --status completed --platform telegram > telegram.jsonl`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		if err := domain.ValidateExportFormat(format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		filters := make(map[string]interface{})
+		for _, name := range []string{"status", "platform", "source", "uploader"} {
+			if value, _ := cmd.Flags().GetString(name); value != "" {
+				filters[name] = value
+			}
+		}
+
+		out := io.Writer(os.Stdout)
+		if path, _ := cmd.Flags().GetString("output"); path != "" {
+			file, err := os.Create(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		var err error
+		if readOffline(cmd) {
+			err = offlineExport(out, format, filters)
+		} else {
+			err = streamExport(out, format, filters)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// streamExport copies the server's export to out as it arrives
+func streamExport(out io.Writer, format string, filters map[string]interface{}) error {
+	params := url.Values{}
+	params.Set("format", format)
+	for key, value := range filters {
+		params.Set(key, fmt.Sprint(value))
+	}
+	resp, err := http.Get(serverURL + "/api/v1/downloads/export?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s", body)
+	}
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+// offlineExport writes the export from the database, as the export API would
+func offlineExport(out io.Writer, format string, filters map[string]interface{}) error {
+	repo, _ := openOfflineRepo()
+	defer repo.Close()
+
+	writer, err := domain.NewExportWriter(out, format)
+	if err != nil {
+		return err
+	}
+	_, err = app.ExportDownloads(context.Background(), repo, writer, filters, domain.ListOptions{SortDir: domain.SortAsc})
+	return err
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", domain.ExportCSV, "Output format: csv or jsonl")
+	exportCmd.Flags().StringP("output", "o", "", "Write to this file (default: stdout)")
+	exportCmd.Flags().StringP("status", "s", "", "Only downloads with this status")
+	exportCmd.Flags().StringP("platform", "p", "", "Only downloads of this platform")
+	exportCmd.Flags().String("source", "", "Only downloads added this way (api, cli, monitor, ...)")
+	exportCmd.Flags().String("uploader", "", "Only downloads of this uploader")
+	exportCmd.Flags().Bool("offline", false, "Read the database directly even if the server is running")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+var importLibraryCmd = &cobra.Command{
+	Use:   "import-library",
+	Short: "Import an existing media library into the database",
+	Long: `Scan a directory (the completed directory by default) for media files with
+a .info.json sidecar, as written by yt-dlp, gallery-dl or x-extract, and record
+them as completed downloads so duplicate detection and search cover them.
+Files with the same webpage URL become one download; URLs that already have a
+completed download are skipped. Files are not moved or changed.`,
+	Example: `  x-extract import-library --dry-run
+  x-extract import-library --dir ~/Downloads/old-archive`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Note: This command doesn't need the server running
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		dir, _ := cmd.Flags().GetString("dir")
+
+		config, err := app.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if dir == "" {
+			dir = config.Download.CompletedDir()
+		}
+
+		repo, err := infrastructure.NewSQLiteDownloadRepository(config.Queue.DatabasePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+			os.Exit(1)
+		}
+		defer repo.Close()
+
+		fmt.Printf("Scanning %s...\n", dir)
+		result, err := app.ImportLibrary(repo, dir, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(result.Downloads) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PLATFORM\tFILES\tSIZE\tURL")
+			for _, dl := range result.Downloads {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", dl.Platform, dl.Files, domain.FormatBytes(dl.FileSize), dl.URL)
+			}
+			w.Flush()
+		}
+
+		verb := "Imported"
+		if dryRun {
+			verb = "Would import"
+		}
+		fmt.Printf("%s %d downloads (%d already in the database, %d media files without .info.json or URL skipped)\n",
+			verb, result.Imported, result.Existing, result.Skipped)
+	},
+}
+
+func init() {
+	importLibraryCmd.Flags().String("dir", "", "Directory to scan (default: the completed directory)")
+	importLibraryCmd.Flags().Bool("dry-run", false, "Only list the downloads that would be imported")
+
+	rootCmd.AddCommand(importLibraryCmd)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var keepaliveCmd = &cobra.Command{
+	Use:   "keepalive",
+	Short: "Keep the server from auto-exiting on an empty queue",
+	Long: `Keep the server running while the queue is empty, e.g. while adding URLs
+one by one. Without --ttl the server stays up for queue.keepalive_ttl; with
+--hold it stays up until "keepalive --release". The server is started if it is
+not running.`,
+	Example: `  x-extract keepalive
+  x-extract keepalive --ttl 2h
+  x-extract keepalive --hold
+  x-extract keepalive --release
+  x-extract keepalive --status`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ttl, _ := cmd.Flags().GetString("ttl")
+		hold, _ := cmd.Flags().GetBool("hold")
+		release, _ := cmd.Flags().GetBool("release")
+		status, _ := cmd.Flags().GetBool("status")
+
+		var result map[string]interface{}
+		switch {
+		case release || status:
+			// Releasing or checking a stopped server has nothing to do
+			if !isServerRunning() {
+				fmt.Println("Server is not running")
+				return
+			}
+			if release {
+				result = doJSONRequest(http.MethodDelete, "/api/v1/server/keepalive", nil, http.StatusOK)
+			} else {
+				result = doJSONRequest(http.MethodGet, "/api/v1/server/keepalive", nil, http.StatusOK)
+			}
+		default:
+			ensureServer()
+			payload := map[string]interface{}{"hold": hold}
+			if ttl != "" {
+				payload["ttl"] = ttl
+			}
+			result = doJSONRequest(http.MethodPost, "/api/v1/server/keepalive", payload, http.StatusOK)
+		}
+		printKeepAliveStatus(result)
+	},
+}
+
+// printKeepAliveStatus prints a keepalive status returned by the server
+func printKeepAliveStatus(status map[string]interface{}) {
+	if autoExit, _ := status["auto_exit_on_empty"].(bool); !autoExit {
+		fmt.Println("Auto-exit is disabled (queue.auto_exit_on_empty: false)")
+		return
+	}
+	var reasons []string
+	if held, _ := status["held"].(bool); held {
+		reasons = append(reasons, "held until released")
+	}
+	if until, ok := status["keep_alive_until"].(string); ok {
+		reasons = append(reasons, "kept alive until "+until)
+	}
+	if inhibited, _ := status["inhibited"].(bool); inhibited {
+		reasons = append(reasons, "enabled schedules or subscriptions")
+	}
+	if len(reasons) == 0 {
+		fmt.Println("No keepalive; the server exits once the queue has been empty for queue.empty_wait_time")
+		return
+	}
+	fmt.Println("Auto-exit suppressed:")
+	for _, reason := range reasons {
+		fmt.Printf("  - %s\n", reason)
+	}
+}
+
+func init() {
+	keepaliveCmd.Flags().String("ttl", "", "How long to keep the server alive, e.g. 30m (default: queue.keepalive_ttl)")
+	keepaliveCmd.Flags().Bool("hold", false, "Keep the server alive until --release")
+	keepaliveCmd.Flags().Bool("release", false, "End the keepalive")
+	keepaliveCmd.Flags().Bool("status", false, "Show what keeps the server alive")
+	keepaliveCmd.MarkFlagsMutuallyExclusive("release", "status", "hold")
+	keepaliveCmd.MarkFlagsMutuallyExclusive("release", "status", "ttl")
+
+	rootCmd.AddCommand(keepaliveCmd)
+}
@@ -3,11 +3,14 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -23,6 +26,7 @@ import (
 
 var (
 	serverURL   string
+	apiToken    string
 	noAutoStart bool
 	rootCmd     = &cobra.Command{
 		Use:   "x-extract",
@@ -31,25 +35,32 @@ var (
 	}
 )
 
-// getDefaultServerURL loads the server URL from config
-func getDefaultServerURL() string {
-	config, err := app.LoadConfig()
-	if err != nil {
+// getDefaultServerURL returns the server URL of config (which may be nil)
+func getDefaultServerURL(config *domain.Config) string {
+	if config == nil {
 		// Fallback to default if config loading fails
 		return "http://localhost:9091"
 	}
-	return fmt.Sprintf("http://%s:%d", config.Server.Host, config.Server.Port)
+	return fmt.Sprintf("%s://%s:%d", config.Server.Scheme(), config.Server.Host, config.Server.Port)
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "Server URL (default: from config)")
+	rootCmd.PersistentFlags().StringVar(&apiToken, "token", "", "API token (default: $X_EXTRACT_TOKEN, then server.auth_token from config)")
 	rootCmd.PersistentFlags().BoolVar(&noAutoStart, "no-auto-start", false, "Don't auto-start server if not running")
 
-	// Set serverURL from config if not provided via flag
+	// Set serverURL and apiToken from config if not provided via flag, and
+	// trust the server's certificate
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		config, _ := app.LoadConfig()
 		if serverURL == "" {
-			serverURL = getDefaultServerURL()
+			serverURL = getDefaultServerURL(config)
+		}
+		if apiToken == "" {
+			apiToken = getDefaultAPIToken(config)
 		}
+		trustServerCert(config)
+		useAPIToken(apiToken, serverURL)
 	}
 
 	rootCmd.AddCommand(addCmd)
@@ -59,7 +70,6 @@ func init() {
 	rootCmd.AddCommand(cancelCmd)
 	rootCmd.AddCommand(retryCmd)
 	rootCmd.AddCommand(logsCmd)
-	rootCmd.AddCommand(regenerateMetadataCmd)
 	rootCmd.AddCommand(eagleImportCmd)
 	rootCmd.AddCommand(eagleRenameCmd)
 	rootCmd.AddCommand(toolsCmd)
@@ -78,6 +88,76 @@ func ensureServer() {
 var timelineFlag bool
 var filterFlags []string
 
+// fetchAPI fetches a server API path. A non-200 response is an error with
+// the response body as its message. Returns the raw response body and headers.
+func fetchAPI(apiPath string) ([]byte, http.Header, error) {
+	resp, err := http.Get(serverURL + apiPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errors.New(string(body))
+	}
+	return body, resp.Header, nil
+}
+
+// doGetRequest fetches a server API path and exits on a non-200 response.
+// Returns the raw response body.
+func doGetRequest(apiPath string) []byte {
+	body, _, err := fetchAPI(apiPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return body
+}
+
+// sendJSON sends a JSON request to the server API. A response other than
+// wantStatus is an error with the response body as its message. Returns the
+// raw response body.
+func sendJSON(method, apiPath string, payload map[string]interface{}, wantStatus int) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		data, _ := json.Marshal(payload)
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, serverURL+apiPath, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != wantStatus {
+		return nil, errors.New(string(body))
+	}
+	return body, nil
+}
+
+// doJSONRequest sends a JSON request to the server API and exits on failure.
+// Returns the decoded JSON object response.
+func doJSONRequest(method, apiPath string, payload map[string]interface{}, wantStatus int) map[string]interface{} {
+	body, err := sendJSON(method, apiPath, payload, wantStatus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(body, &result)
+	return result
+}
+
 var addCmd = &cobra.Command{
 	Use:   "add [url]",
 	Short: "Add a download to the queue",
@@ -88,6 +168,13 @@ var addCmd = &cobra.Command{
 		url := args[0]
 		mode, _ := cmd.Flags().GetString("mode")
 		explicitPlatform, _ := cmd.Flags().GetString("platform")
+		priority, _ := cmd.Flags().GetInt("priority")
+		rangeEnd, _ := cmd.Flags().GetInt("to")
+		allVariants, _ := cmd.Flags().GetBool("all-variants")
+		force, _ := cmd.Flags().GetBool("force")
+		callbackURL, _ := cmd.Flags().GetString("callback-url")
+		account, _ := cmd.Flags().GetString("account")
+		telegramProfile, _ := cmd.Flags().GetString("profile")
 
 		xURLType := domain.DetectXURLType(url)
 		igURLType := domain.DetectInstagramURLType(url)
@@ -102,6 +189,8 @@ var addCmd = &cobra.Command{
 		platform := explicitPlatform
 		if platform == "" {
 			switch {
+			case domain.DownloadMode(mode) == domain.ModeProfile:
+				platform = string(domain.PlatformX)
 			case xURLType == domain.XURLTypeTimeline || timelineFlag:
 				platform = string(domain.PlatformGallery)
 			case igURLType != "":
@@ -112,13 +201,15 @@ var addCmd = &cobra.Command{
 		}
 
 		// Warn if user forced --platform x on a timeline (yt-dlp doesn't handle timelines well)
-		if domain.Platform(platform) == domain.PlatformX && xURLType == domain.XURLTypeTimeline {
+		if domain.Platform(platform) == domain.PlatformX && xURLType == domain.XURLTypeTimeline &&
+			domain.DownloadMode(mode) != domain.ModeProfile {
 			fmt.Fprintf(os.Stderr, "Note: %s looks like an account timeline. gallery-dl may work better (use --timeline).\n", url)
 		}
 
-		payload := map[string]string{
+		payload := map[string]interface{}{
 			"url":      url,
 			"platform": platform,
+			"source":   domain.SourceCLI,
 		}
 		if mode != "" {
 			payload["mode"] = mode
@@ -126,6 +217,27 @@ var addCmd = &cobra.Command{
 		if len(filterFlags) > 0 {
 			payload["filters"] = strings.Join(filterFlags, "|")
 		}
+		if priority != 0 {
+			payload["priority"] = priority
+		}
+		if rangeEnd != 0 {
+			payload["range_end"] = rangeEnd
+		}
+		if allVariants {
+			payload["all_variants"] = true
+		}
+		if force {
+			payload["force"] = true
+		}
+		if callbackURL != "" {
+			payload["callback_url"] = callbackURL
+		}
+		if account != "" {
+			payload["account"] = account
+		}
+		if telegramProfile != "" {
+			payload["tdl_profile"] = telegramProfile
+		}
 
 		data, _ := json.Marshal(payload)
 		resp, err := http.Post(serverURL+"/api/v1/downloads", "application/json", bytes.NewBuffer(data))
@@ -136,6 +248,21 @@ var addCmd = &cobra.Command{
 		defer resp.Body.Close()
 
 		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusConflict {
+			var conflict struct {
+				Error    string                 `json:"error"`
+				Download map[string]interface{} `json:"download"`
+			}
+			json.Unmarshal(body, &conflict)
+			if conflict.Download["status"] == string(domain.StatusCompleted) {
+				fmt.Printf("Already downloaded at %s (use --force to download again)\n", valueOrDash(conflict.Download["file_path"]))
+			} else {
+				fmt.Printf("Already %s, not queued again\n", conflict.Download["status"])
+			}
+			fmt.Printf("ID: %s\n", conflict.Download["id"])
+			fmt.Printf("Status: %s\n", conflict.Download["status"])
+			return
+		}
 		if resp.StatusCode != http.StatusCreated {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", string(body))
 			os.Exit(1)
@@ -146,87 +273,307 @@ var addCmd = &cobra.Command{
 		fmt.Printf("Download added successfully!\n")
 		fmt.Printf("ID: %s\n", result["id"])
 		fmt.Printf("Status: %s\n", result["status"])
+		if rangeEnd != 0 {
+			fmt.Printf("Messages: %v-%v\n", result["range_start"], result["range_end"])
+		}
 	},
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all downloads",
+	Long: `List downloads, newest first. When the server is not running (or with
+--offline) the database is read directly instead of starting the server.
+
+With --watch, the list is refreshed in place every --interval, with the
+progress, speed and ETA of running downloads, until Ctrl-C.`,
+	Example: `  x-extract list --status processing
+  x-extract list --watch --limit 20`,
 	Run: func(cmd *cobra.Command, args []string) {
-		ensureServer()
-		status, _ := cmd.Flags().GetString("status")
+		offline := readOffline(cmd)
+		watchOrRun(cmd, func(out io.Writer) error {
+			return printDownloadList(cmd, out, offline)
+		})
+	},
+}
 
-		url := serverURL + "/api/v1/downloads"
+// printDownloadList prints the downloads selected by the flags of cmd, read
+// from the database when offline or else from the server
+func printDownloadList(cmd *cobra.Command, out io.Writer, offline bool) error {
+	status, _ := cmd.Flags().GetString("status")
+	source, _ := cmd.Flags().GetString("source")
+	language, _ := cmd.Flags().GetString("language")
+	errorCode, _ := cmd.Flags().GetString("error-code")
+	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	sortBy, _ := cmd.Flags().GetString("sort-by")
+	asc, _ := cmd.Flags().GetBool("asc")
+	minSize, _ := cmd.Flags().GetString("min-size")
+	maxSize, _ := cmd.Flags().GetString("max-size")
+
+	var body []byte
+	total := ""
+	if offline {
+		filters := make(map[string]interface{})
 		if status != "" {
-			url += "?status=" + status
+			filters["status"] = status
+		}
+		if source != "" {
+			filters["source"] = source
+		}
+		if language != "" {
+			filters["language"] = language
+		}
+		if errorCode != "" {
+			filters["error_code"] = errorCode
+		}
+		opts := domain.ListOptions{Limit: limit, Offset: offset, SortBy: sortBy}
+		if asc {
+			opts.SortDir = domain.SortAsc
+		}
+		var err error
+		if opts.MinSize, err = domain.ParseByteSize(minSize); err != nil {
+			return fmt.Errorf("invalid --min-size: %w", err)
+		}
+		if opts.MaxSize, err = domain.ParseByteSize(maxSize); err != nil {
+			return fmt.Errorf("invalid --max-size: %w", err)
+		}
+		var count int64
+		body, count = offlineList(filters, opts)
+		total = strconv.FormatInt(count, 10)
+	} else {
+		params := url.Values{}
+		if status != "" {
+			params.Set("status", status)
+		}
+		if source != "" {
+			params.Set("source", source)
+		}
+		if language != "" {
+			params.Set("language", language)
+		}
+		if errorCode != "" {
+			params.Set("error_code", errorCode)
+		}
+		if limit > 0 {
+			params.Set("limit", strconv.Itoa(limit))
+		}
+		if offset > 0 {
+			params.Set("offset", strconv.Itoa(offset))
+		}
+		if sortBy != "" {
+			params.Set("sort_by", sortBy)
+		}
+		if asc {
+			params.Set("sort_dir", domain.SortAsc)
+		}
+		if minSize != "" {
+			params.Set("min_size", minSize)
+		}
+		if maxSize != "" {
+			params.Set("max_size", maxSize)
+		}
+		apiPath := "/api/v1/downloads"
+		if len(params) > 0 {
+			apiPath += "?" + params.Encode()
 		}
 
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		var header http.Header
+		var err error
+		if body, header, err = fetchAPI(apiPath); err != nil {
+			return err
 		}
-		defer resp.Body.Close()
+		total = header.Get("X-Total-Count")
+	}
+	var downloads []map[string]interface{}
+	json.Unmarshal(body, &downloads)
 
-		body, _ := io.ReadAll(resp.Body)
-		var downloads []map[string]interface{}
-		json.Unmarshal(body, &downloads)
-
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tURL\tPLATFORM\tSTATUS\tCREATED")
-		for _, d := range downloads {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-				truncate(d["id"].(string), 8),
-				truncate(d["url"].(string), 40),
-				d["platform"],
-				d["status"],
-				d["created_at"])
-		}
-		w.Flush()
-	},
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tURL\tPLATFORM\tSTATUS\tPROGRESS\tCREATED")
+	for _, d := range downloads {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			truncate(d["id"].(string), 8),
+			truncate(d["url"].(string), 40),
+			d["platform"],
+			d["status"],
+			formatProgress(d),
+			d["created_at"])
+	}
+	w.Flush()
+	if total != "" && (limit > 0 || offset > 0) {
+		fmt.Fprintf(out, "Showing %d of %s downloads\n", len(downloads), total)
+	}
+	return nil
 }
 
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show download statistics",
+	Long: `Show download statistics. When the server is not running (or with
+--offline) the database is read directly instead of starting the server.
+
+With --days, show the downloads added and completed per day instead, with
+the bytes downloaded and the platforms they came from.
+
+With --watch, the statistics are refreshed in place every --interval until
+Ctrl-C.`,
+	Example: `  x-extract stats
+  x-extract stats --days 14
+  x-extract stats --watch --interval 5s`,
 	Run: func(cmd *cobra.Command, args []string) {
-		ensureServer()
-		resp, err := http.Get(serverURL + "/api/v1/downloads/stats")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		days, _ := cmd.Flags().GetInt("days")
+		if days > 0 {
+			if err := domain.ValidateStatsHistoryDays(days); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		}
-		defer resp.Body.Close()
-
-		body, _ := io.ReadAll(resp.Body)
-		var stats map[string]interface{}
-		json.Unmarshal(body, &stats)
-
-		fmt.Println("Download Statistics:")
-		fmt.Printf("  Total:      %v\n", stats["total"])
-		fmt.Printf("  Queued:     %v\n", stats["queued"])
-		fmt.Printf("  Processing: %v\n", stats["processing"])
-		fmt.Printf("  Completed:  %v\n", stats["completed"])
-		fmt.Printf("  Failed:     %v\n", stats["failed"])
-		fmt.Printf("  Cancelled:  %v\n", stats["cancelled"])
+		top, _ := cmd.Flags().GetInt("top")
+		offline := readOffline(cmd)
+		watchOrRun(cmd, func(out io.Writer) error {
+			if days > 0 {
+				return printStatsHistory(out, offline, days)
+			}
+			return printStats(out, offline, top)
+		})
 	},
 }
 
+// printStats prints the download statistics, read from the database when
+// offline or else from the server, with the top largest tags and collections
+func printStats(out io.Writer, offline bool, top int) error {
+	var body []byte
+	if offline {
+		body = offlineStats()
+	} else {
+		var err error
+		if body, _, err = fetchAPI("/api/v1/downloads/stats"); err != nil {
+			return err
+		}
+	}
+	var stats map[string]interface{}
+	json.Unmarshal(body, &stats)
+
+	fmt.Fprintln(out, "Download Statistics:")
+	fmt.Fprintf(out, "  Total:      %v\n", stats["total"])
+	fmt.Fprintf(out, "  Queued:     %v\n", stats["queued"])
+	fmt.Fprintf(out, "  Processing: %v\n", stats["processing"])
+	fmt.Fprintf(out, "  Recording:  %v\n", stats["recording"])
+	fmt.Fprintf(out, "  Completed:  %v\n", stats["completed"])
+	fmt.Fprintf(out, "  Failed:     %v\n", stats["failed"])
+	fmt.Fprintf(out, "  Cancelled:  %v\n", stats["cancelled"])
+	fmt.Fprintf(out, "  Expired:    %v\n", stats["expired"])
+	fmt.Fprintf(out, "  Items:      %v (%v failed)\n", stats["items"], stats["failed_items"])
+	bytes, _ := stats["bytes"].(float64)
+	bytesToday, _ := stats["bytes_today"].(float64)
+	fmt.Fprintf(out, "  Downloaded: %s (%s today)\n", domain.FormatBytes(int64(bytes)), domain.FormatBytes(int64(bytesToday)))
+	if seconds, _ := stats["media_duration"].(float64); seconds > 0 {
+		fmt.Fprintf(out, "  Play time:  %s\n", (time.Duration(seconds) * time.Second).String())
+	}
+
+	printGroupStats(out, "By tag", stats["tags"], top)
+	printGroupStats(out, "By collection", stats["collections"], top)
+	printResourceStats(out, stats["resources"])
+	return nil
+}
+
+// printStatsHistory prints the downloads added and completed per day of the
+// last days days, oldest first
+func printStatsHistory(out io.Writer, offline bool, days int) error {
+	var body []byte
+	if offline {
+		body = offlineStatsHistory(days)
+	} else {
+		var err error
+		if body, _, err = fetchAPI(fmt.Sprintf("/api/v1/downloads/stats/history?days=%d", days)); err != nil {
+			return err
+		}
+	}
+	var history domain.StatsHistory
+	if err := json.Unmarshal(body, &history); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tADDED\tCOMPLETED\tBYTES\tPLATFORMS")
+	for _, day := range history.History {
+		platforms := make([]string, 0, len(day.Platforms))
+		for platform, counts := range day.Platforms {
+			platforms = append(platforms, fmt.Sprintf("%s %d/%d", platform, counts.Added, counts.Completed))
+		}
+		sort.Strings(platforms)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", day.Date, day.Added, day.Completed,
+			domain.FormatBytes(day.Bytes), strings.Join(platforms, ", "))
+	}
+	w.Flush()
+	return nil
+}
+
+// printResourceStats prints the resource usage of the stats response and the
+// downloads that used the most CPU time
+func printResourceStats(out io.Writer, resources interface{}) {
+	usage, _ := resources.(map[string]interface{})
+	if usage == nil || usage["cpu_ms"] == float64(0) {
+		return
+	}
+	fmt.Fprintf(out, "\nResource usage (last attempts):\n")
+	fmt.Fprintf(out, "  Total:      %s\n", formatUsage(usage["cpu_ms"], usage["wall_ms"], usage["peak_rss"]))
+	heaviest, _ := usage["heaviest"].([]interface{})
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, h := range heaviest {
+		download, _ := h.(map[string]interface{})
+		fmt.Fprintf(w, "  %v\t%v\t%s\t%s\n", download["id"], download["status"],
+			formatUsage(download["cpu_ms"], download["wall_ms"], download["peak_rss"]), truncate(fmt.Sprint(download["url"]), 50))
+	}
+	w.Flush()
+}
+
+// formatUsage formats CPU and wall-clock milliseconds and a peak RSS in bytes
+// from a JSON response
+func formatUsage(cpuMs, wallMs, peakRSS interface{}) string {
+	ms := func(v interface{}) time.Duration {
+		n, _ := v.(float64)
+		return (time.Duration(n) * time.Millisecond).Round(100 * time.Millisecond)
+	}
+	rss, _ := peakRSS.(float64)
+	return fmt.Sprintf("cpu %s, wall %s, peak %s", ms(cpuMs), ms(wallMs), domain.FormatBytes(int64(rss)))
+}
+
+// printGroupStats prints the first top (all when 0) tag or collection
+// breakdowns of the stats response
+func printGroupStats(out io.Writer, title string, groups interface{}, top int) {
+	list, _ := groups.([]interface{})
+	if len(list) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s:\n", title)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, g := range list {
+		if top > 0 && i == top {
+			fmt.Fprintf(w, "  ... %d more\n", len(list)-top)
+			break
+		}
+		group, _ := g.(map[string]interface{})
+		bytes, _ := group["bytes"].(float64)
+		fmt.Fprintf(w, "  %v\t%v downloads\t%s\n", group["name"], group["count"], domain.FormatBytes(int64(bytes)))
+	}
+	w.Flush()
+}
+
 var getCmd = &cobra.Command{
 	Use:   "get [id]",
 	Short: "Get download details",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show the details of a download. When the server is not running (or with
+--offline) the database is read directly instead of starting the server.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		ensureServer()
 		id := args[0]
-		resp, err := http.Get(serverURL + "/api/v1/downloads/" + id)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		var body []byte
+		if readOffline(cmd) {
+			body = offlineGet(id)
+		} else {
+			body = doGetRequest("/api/v1/downloads/" + id)
 		}
-		defer resp.Body.Close()
-
-		body, _ := io.ReadAll(resp.Body)
 		var download map[string]interface{}
 		json.Unmarshal(body, &download)
 
@@ -237,9 +584,43 @@ var getCmd = &cobra.Command{
 		fmt.Printf("  Status:   %s\n", download["status"])
 		fmt.Printf("  Mode:     %s\n", download["mode"])
 		fmt.Printf("  Created:  %s\n", download["created_at"])
+		if timeline := formatTimeline(download["timeline"]); timeline != "" {
+			fmt.Printf("  Timeline: %s\n", timeline)
+		}
+		if download["status"] == "processing" || download["status"] == "recording" {
+			fmt.Printf("  Progress: %s\n", formatProgress(download))
+			if download["current_file"] != nil {
+				fmt.Printf("  Current:  %s\n", download["current_file"])
+			}
+		}
+		if items, ok := download["items"].([]interface{}); ok {
+			if download["item_count"] != nil {
+				fmt.Printf("  Items:    %d of %v\n", len(items), download["item_count"])
+			} else {
+				fmt.Printf("  Items:    %d\n", len(items))
+			}
+			for _, item := range items {
+				if item, ok := item.(map[string]interface{}); ok {
+					fmt.Printf("    %s\n", formatItem(item))
+				}
+			}
+		}
 		if download["file_path"] != nil {
 			fmt.Printf("  File:     %s\n", download["file_path"])
 		}
+		if download["client_profile"] != nil {
+			fmt.Printf("  Client:   %s\n", download["client_profile"])
+		}
+		if download["process_count"] != nil {
+			fmt.Printf("  Usage:    %s (%v processes)\n", formatUsage(download["process_cpu_ms"], download["process_wall_ms"], download["process_peak_rss"]), download["process_count"])
+		}
+		if download["error_message"] != nil {
+			if download["error_code"] != nil {
+				fmt.Printf("  Error:    [%s] %s\n", download["error_code"], download["error_message"])
+			} else {
+				fmt.Printf("  Error:    %s\n", download["error_message"])
+			}
+		}
 	},
 }
 
@@ -263,8 +644,23 @@ var cancelCmd = &cobra.Command{
 var retryCmd = &cobra.Command{
 	Use:   "retry [id]",
 	Short: "Retry a failed download",
-	Args:  cobra.ExactArgs(1),
+	Long: `Retry a failed, cancelled or expired download. With --all, every failed
+download matching --platform, --error and the age flags is queued again.`,
+	Example: `  x-extract retry 550e8400-e29b-41d4-a716-446655440000
+  x-extract retry --all --error rate_limited
+  x-extract retry --all --platform telegram --newer-than 1d`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) == 1) {
+			fmt.Fprintln(os.Stderr, "Error: give either a download ID or --all")
+			os.Exit(1)
+		}
+		if all {
+			retryAll(cmd)
+			return
+		}
+
 		ensureServer()
 		id := args[0]
 		resp, err := http.Post(serverURL+"/api/v1/downloads/"+id+"/retry", "application/json", nil)
@@ -277,15 +673,60 @@ var retryCmd = &cobra.Command{
 	},
 }
 
+// retryAll queues again the failed downloads selected by retry's flags
+func retryAll(cmd *cobra.Command) {
+	platform, _ := cmd.Flags().GetString("platform")
+	errorCode, _ := cmd.Flags().GetString("error")
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	newerThan, _ := cmd.Flags().GetString("newer-than")
+	if errorCode != "" {
+		if _, err := domain.ParseErrorCode(errorCode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, age := range []string{olderThan, newerThan} {
+		if age == "" {
+			continue
+		}
+		if _, err := domain.ParseAge(age); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ensureServer()
+	result := doJSONRequest(http.MethodPost, "/api/v1/downloads/retry-all", map[string]interface{}{
+		"platform":   platform,
+		"error_code": errorCode,
+		"older_than": olderThan,
+		"newer_than": newerThan,
+	}, http.StatusOK)
+	fmt.Printf("Queued %v failed downloads for retry\n", result["retried"])
+}
+
 var logsCmd = &cobra.Command{
 	Use:   "logs [id]",
 	Short: "View download process logs",
-	Args:  cobra.ExactArgs(1),
+	Long: `View the process (yt-dlp, tdl, gallery-dl) output of a download. When the
+server is not running (or with --offline) the database and log files are read
+directly instead of starting the server.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		ensureServer()
 		id := args[0]
 		jsonOutput, _ := cmd.Flags().GetBool("json")
 
+		if readOffline(cmd) {
+			logs := offlineLogs(id)
+			if jsonOutput {
+				prettyJSON, _ := json.MarshalIndent(map[string]interface{}{"id": id, "logs": logs}, "", "  ")
+				fmt.Println(string(prettyJSON))
+			} else {
+				fmt.Print(logs)
+			}
+			return
+		}
+
 		req, err := http.NewRequest("GET", serverURL+"/api/v1/downloads/"+id+"/logs", nil)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -320,269 +761,6 @@ var logsCmd = &cobra.Command{
 	},
 }
 
-var regenerateMetadataCmd = &cobra.Command{
-	Use:   "regenerate-metadata",
-	Short: "Regenerate metadata JSON files for downloads with missing text",
-	Long: `Regenerates metadata JSON files for Telegram downloads that have
-empty descriptions. This command queries the message cache to find the
-correct text for each downloaded file based on the message ID in the filename.
-It uses grouped message resolution (media albums) and nearby message fallback
-to find the correct text. Does NOT re-download any files.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// Note: This command doesn't need the server running
-		// It reads the database and files directly
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		completedDir, _ := cmd.Flags().GetString("completed-dir")
-
-		config, err := app.LoadConfig()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-			os.Exit(1)
-		}
-
-		if completedDir == "" {
-			completedDir = config.Download.CompletedDir()
-		}
-
-		dbPath := config.Queue.DatabasePath
-
-		// Open database using repository interface
-		repo, err := infrastructure.NewSQLiteDownloadRepository(dbPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
-			os.Exit(1)
-		}
-		defer repo.Close()
-
-		// Phase 1: Update .info.json files in the completed directory
-		fmt.Println("Scanning completed directory for Telegram .info.json files...")
-		updated := 0
-		files, err := os.ReadDir(completedDir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading completed dir: %v\n", err)
-			os.Exit(1)
-		}
-
-		for _, f := range files {
-			if f.IsDir() {
-				continue
-			}
-			name := f.Name()
-			if !strings.HasSuffix(name, ".info.json") {
-				continue
-			}
-
-			// Extract channel ID from filename (format: {channel_id}_{message_id}_{rest}.info.json)
-			channelID := extractChannelIDFromFilename(name)
-			if channelID == "" {
-				continue
-			}
-
-			// Extract message ID from filename
-			msgID := extractMessageIDFromFilename(name)
-			if msgID == "" {
-				continue
-			}
-
-			// Read the JSON file
-			jsonPath := filepath.Join(completedDir, name)
-			data, err := os.ReadFile(jsonPath)
-			if err != nil {
-				continue
-			}
-
-			var metadata map[string]interface{}
-			if err := json.Unmarshal(data, &metadata); err != nil {
-				continue
-			}
-
-			// Check if description is empty
-			desc, _ := metadata["description"].(string)
-			if desc != "" {
-				continue // Already has description
-			}
-
-			// Resolve text using repository with grouped message resolution
-			text := resolveMessageText(repo, channelID, msgID)
-
-			// If not found by filename message ID, try the URL message ID from metadata
-			if text == "" {
-				if urlMsgID, ok := metadata["id"].(string); ok && urlMsgID != msgID {
-					text = resolveMessageText(repo, channelID, urlMsgID)
-				}
-			}
-
-			if text == "" {
-				continue // No text found
-			}
-
-			// Update metadata
-			metadata["description"] = text
-
-			// Write back JSON file
-			if !dryRun {
-				newData, _ := json.MarshalIndent(metadata, "", "  ")
-				os.WriteFile(jsonPath, newData, 0644)
-			}
-			fmt.Printf("Updated: %s (msg %s)\n", name, msgID)
-			updated++
-		}
-
-		// Phase 2: Update database entries for completed Telegram downloads
-		fmt.Printf("\nUpdating database entries...\n")
-		dbUpdated := 0
-
-		downloads, err := repo.FindAll(map[string]interface{}{
-			"platform": domain.PlatformTelegram,
-			"status":   domain.StatusCompleted,
-		})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error querying downloads: %v\n", err)
-		} else {
-			for _, dl := range downloads {
-				if dl.Metadata == "" {
-					continue
-				}
-
-				var metadata map[string]interface{}
-				if err := json.Unmarshal([]byte(dl.Metadata), &metadata); err != nil {
-					continue
-				}
-
-				// Check if description is empty
-				desc, _ := metadata["description"].(string)
-				if desc != "" {
-					continue // Already has description
-				}
-
-				// Extract channel and message IDs from the download's files or URL
-				channelID, msgID := extractIDsFromDownload(dl, metadata)
-				if channelID == "" || msgID == "" {
-					continue
-				}
-
-				// Resolve text using repository with grouped message resolution
-				text := resolveMessageText(repo, channelID, msgID)
-				if text == "" {
-					continue
-				}
-
-				// Update metadata
-				metadata["description"] = text
-				newMetadataBytes, _ := json.Marshal(metadata)
-
-				// Update database
-				if !dryRun {
-					dl.Metadata = string(newMetadataBytes)
-					if err := repo.Update(dl); err != nil {
-						fmt.Fprintf(os.Stderr, "Error updating download %s: %v\n", dl.ID[:8], err)
-						continue
-					}
-				}
-				fmt.Printf("Updated DB: %s (msg %s)\n", dl.ID[:8], msgID)
-				dbUpdated++
-			}
-		}
-
-		if dryRun {
-			fmt.Printf("\nDry run: would update %d JSON files and %d DB entries\n", updated, dbUpdated)
-		} else {
-			fmt.Printf("\nUpdated %d JSON files and %d DB entries\n", updated, dbUpdated)
-		}
-	},
-}
-
-// extractChannelIDFromFilename extracts the channel ID from a Telegram filename.
-// Format: {channel_id}_{message_id}_{media_id}.{ext}
-// Returns empty string if the first part is not a numeric channel ID.
-func extractChannelIDFromFilename(filename string) string {
-	name := strings.TrimSuffix(filename, filepath.Ext(filename))
-	// Handle .info.json double extension
-	name = strings.TrimSuffix(name, ".info")
-	parts := strings.Split(name, "_")
-	if len(parts) < 2 {
-		return ""
-	}
-	// Validate that it's a numeric channel ID (Telegram private channels)
-	if _, err := strconv.ParseInt(parts[0], 10, 64); err != nil {
-		return ""
-	}
-	return parts[0]
-}
-
-// resolveMessageText looks up message text from the cache repository,
-// using grouped message resolution and nearby message fallback.
-func resolveMessageText(repo *infrastructure.SQLiteDownloadRepository, channelID, messageID string) string {
-	// First try direct lookup
-	cached, err := repo.GetMessage(channelID, messageID)
-	if err != nil {
-		return ""
-	}
-	if cached != nil && cached.Text != "" {
-		return cached.Text
-	}
-
-	// If message exists but has no text, try grouped message resolution
-	if cached != nil && cached.GroupedID != "" {
-		grouped, err := repo.GetMessagesByGroupedID(channelID, cached.GroupedID)
-		if err == nil {
-			for _, g := range grouped {
-				if g.Text != "" {
-					return g.Text
-				}
-			}
-		}
-	}
-
-	// Fallback: search nearby message IDs (±3) for text
-	nearby, err := repo.GetNearbyMessages(channelID, messageID, 3)
-	if err == nil {
-		for _, n := range nearby {
-			if n.Text != "" {
-				return n.Text
-			}
-		}
-	}
-
-	return ""
-}
-
-// extractIDsFromDownload extracts channel ID and message ID from a download record.
-// Tries to extract from the files list first (filename), then from the URL.
-func extractIDsFromDownload(dl *domain.Download, metadata map[string]interface{}) (channelID, msgID string) {
-	// Try extracting from files list in metadata
-	if filesRaw, ok := metadata["files"].([]interface{}); ok && len(filesRaw) > 0 {
-		if filePath, ok := filesRaw[0].(string); ok {
-			filename := filepath.Base(filePath)
-			channelID = extractChannelIDFromFilename(filename)
-			msgID = extractMessageIDFromFilename(filename)
-			if channelID != "" && msgID != "" {
-				return channelID, msgID
-			}
-		}
-	}
-
-	// Fallback: extract from URL (format: https://t.me/c/{channel_id}/{message_id})
-	url := dl.URL
-	parts := strings.Split(url, "/")
-	if len(parts) >= 5 && parts[3] == "c" {
-		// Private channel: https://t.me/c/1234567890/messageid
-		return parts[4], parts[len(parts)-1]
-	}
-
-	return "", ""
-}
-
-// Format: {channel_id}_{message_id}_{media_id}.{ext}
-func extractMessageIDFromFilename(filename string) string {
-	name := strings.TrimSuffix(filename, filepath.Ext(filename))
-	parts := strings.Split(name, "_")
-	if len(parts) >= 2 {
-		return parts[1]
-	}
-	return ""
-}
-
 var eagleImportCmd = &cobra.Command{
 	Use:   "eagle-import",
 	Short: "Import completed downloads into Eagle App",
@@ -635,7 +813,7 @@ to prevent duplicate imports.`,
 		}
 
 		// Scan completed directory for media files
-		items, skipped := scanForEagleItems(completedDir)
+		items, skipped := scanForEagleItems(completedDir, eagleCfg.ImportedSubdir)
 		if len(items) == 0 {
 			writeEagleImportStdout(importLog, "No media files found to import.\n")
 			return nil
@@ -756,40 +934,46 @@ func checkEagleRunning(apiEndpoint string) error {
 	return nil
 }
 
-// scanForEagleItems scans the completed directory for media files with .info.json metadata.
+// scanForEagleItems scans the completed directory (including download.organize_by
+// subdirectories, but not importedSubdir) for media files with .info.json metadata.
 // Returns the list of EagleItems and the count of media files skipped (no metadata).
-func scanForEagleItems(completedDir string) ([]*domain.EagleItem, int) {
-	files, err := os.ReadDir(completedDir)
-	if err != nil {
-		return nil, 0
-	}
-
+func scanForEagleItems(completedDir, importedSubdir string) ([]*domain.EagleItem, int) {
 	var items []*domain.EagleItem
 	skipped := 0
 
-	for _, f := range files {
+	importedDir := ""
+	if importedSubdir != "" {
+		importedDir = filepath.Join(completedDir, importedSubdir)
+	}
+
+	filepath.WalkDir(completedDir, func(filePath string, f os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
 		if f.IsDir() {
-			continue
+			if filePath == importedDir {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-		filePath := filepath.Join(completedDir, f.Name())
 		if !infrastructure.IsMediaFile(filePath) {
-			continue
+			return nil
 		}
 
 		// Look for corresponding .info.json
 		baseName := strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))
-		infoPath := filepath.Join(completedDir, baseName+".info.json")
+		infoPath := filepath.Join(filepath.Dir(filePath), baseName+".info.json")
 
 		data, err := os.ReadFile(infoPath)
 		if err != nil {
 			skipped++
-			continue
+			return nil
 		}
 
 		var meta domain.MediaMetadata
 		if err := json.Unmarshal(data, &meta); err != nil {
 			skipped++
-			continue
+			return nil
 		}
 
 		item := meta.ToEagleItem(filePath)
@@ -798,7 +982,8 @@ func scanForEagleItems(completedDir string) ([]*domain.EagleItem, int) {
 			item.Name = baseName
 		}
 		items = append(items, item)
-	}
+		return nil
+	})
 
 	return items, skipped
 }
@@ -1390,7 +1575,7 @@ func updateEagleItemName(libraryPath, itemID, newName string) error {
 	buf.Write(newNameJSON)
 	buf.Write(data[oldValueEnd:])
 
-	if err := os.WriteFile(metadataPath, buf.Bytes(), 0644); err != nil {
+	if err := infrastructure.WriteFileAtomic(metadataPath, buf.Bytes(), 0644); err != nil {
 		return fmt.Errorf("write metadata: %w", err)
 	}
 
@@ -1631,14 +1816,42 @@ func init() {
 	toolsCmd.AddCommand(toolsInstallCmd)
 	toolsCmd.AddCommand(toolsUpdateCmd)
 
-	addCmd.Flags().StringP("mode", "m", "", "Download mode (single, group, default)")
+	addCmd.Flags().StringP("mode", "m", "", "Download mode (single, group, default; profile backs up an X profile with yt-dlp)")
 	addCmd.Flags().StringP("platform", "p", "", "Platform (x, telegram, gallery)")
 	addCmd.Flags().BoolVar(&timelineFlag, "timeline", false, "Use gallery-dl for account/media timeline URLs (auto-detected if omitted)")
 	addCmd.Flags().StringArrayVar(&filterFlags, "filter", nil, "gallery-dl option in key=value form, e.g. --filter retweets=false (can repeat)")
+	addCmd.Flags().Int("priority", 0, "Queue priority (higher values are downloaded first)")
+	addCmd.Flags().Bool("all-variants", false, "X: keep every image at original resolution and every video rendition (archival)")
+	addCmd.Flags().Bool("force", false, "Download again even if the URL is already downloaded")
+	addCmd.Flags().String("callback-url", "", "URL to POST the download to when it completes, fails or is cancelled")
+	addCmd.Flags().String("account", "", "X only: download with this twitter.accounts account (default: each account in turn)")
+	addCmd.Flags().String("profile", "", "Telegram only: download with this telegram.profiles tdl profile (default: telegram.profile)")
+	addCmd.Flags().Int("to", 0, "Telegram: also download every message up to this message ID (e.g. add https://t.me/c/12345/100 --to 250)")
 	listCmd.Flags().StringP("status", "s", "", "Filter by status")
+	listCmd.Flags().String("language", "", "Filter by language of the post text (ISO 639-1, e.g. zh)")
+	listCmd.Flags().String("error-code", "", "Filter by why downloads failed (auth_expired, not_found, rate_limited, network, disk_full, tool_missing, parse_error)")
+	listCmd.Flags().String("source", "", "Filter by how downloads were added (api, cli, dashboard, monitor, telegram-bot, watch-folder, import)")
+	listCmd.Flags().Int("limit", 0, "Show at most this many downloads (0 = all)")
+	listCmd.Flags().Int("offset", 0, "Skip this many downloads")
+	listCmd.Flags().String("sort-by", "", "Sort by created_at (default), updated_at, completed_at, priority, file_size, media_duration, title, uploader, status or platform")
+	listCmd.Flags().Bool("asc", false, "Sort ascending (default: descending)")
+	listCmd.Flags().String("min-size", "", "Only downloads of at least this size, e.g. 100MB")
+	listCmd.Flags().String("max-size", "", "Only downloads of at most this size, e.g. 1GiB")
+	retryCmd.Flags().Bool("all", false, "Retry every failed download matching --platform, --error and the age flags")
+	retryCmd.Flags().StringP("platform", "p", "", "With --all: only downloads of this platform (x, telegram, gallery)")
+	retryCmd.Flags().String("error", "", "With --all: only downloads that failed with this error code (e.g. rate_limited, network)")
+	retryCmd.Flags().String("older-than", "", "With --all: only downloads that failed longer ago than this (e.g. 30d, 2w, 36h)")
+	retryCmd.Flags().String("newer-than", "", "With --all: only downloads that failed more recently than this")
+	statsCmd.Flags().Int("top", 10, "Number of tags and collections to show (0 = all)")
+	statsCmd.Flags().Int("days", 0, "Show the downloads added and completed per day of the last N days")
+	for _, c := range []*cobra.Command{listCmd, statsCmd} {
+		c.Flags().BoolP("watch", "w", false, "Refresh in place until Ctrl-C")
+		c.Flags().Duration("interval", defaultWatchInterval, "With --watch: time between refreshes")
+	}
 	logsCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
-	regenerateMetadataCmd.Flags().BoolP("dry-run", "n", false, "Show what would be updated without making changes")
-	regenerateMetadataCmd.Flags().StringP("completed-dir", "d", "", "Completed downloads directory (default: from config)")
+	for _, c := range []*cobra.Command{listCmd, getCmd, statsCmd, logsCmd} {
+		c.Flags().Bool("offline", false, "Read the database directly even if the server is running")
+	}
 	eagleImportCmd.Flags().BoolP("dry-run", "n", false, "Preview what would be imported without making changes")
 	eagleImportCmd.Flags().StringP("completed-dir", "d", "", "Completed downloads directory (default: from config)")
 	eagleRenameCmd.Flags().IntP("max-length", "m", 180, "Maximum name length in bytes (default: 180)")
@@ -1649,6 +1862,67 @@ func init() {
 	eagleRenameCmd.Flags().StringSlice("ids", nil, "Only apply to specific item IDs (comma-separated)")
 }
 
+// formatProgress renders a download's live progress, e.g. "45.3% 1.23MiB/s ETA 00:05".
+// Live recordings have no percentage and show "recording" instead.
+// Returns "-" for downloads that are not processing or recording.
+func formatProgress(d map[string]interface{}) string {
+	var parts []string
+	switch d["status"] {
+	case "processing":
+		percent, _ := d["progress"].(float64)
+		parts = append(parts, fmt.Sprintf("%.1f%%", percent))
+	case "recording":
+		parts = append(parts, "recording")
+	default:
+		return "-"
+	}
+	if speed, ok := d["speed"].(string); ok && speed != "" {
+		parts = append(parts, speed)
+	}
+	if eta, ok := d["eta"].(string); ok && eta != "" {
+		parts = append(parts, "ETA "+eta)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatTimeline renders a download's status timeline in local time, e.g.
+// "queued 10:03 → started 10:04 → retry #1 10:12 → completed 10:20".
+// Failure messages are left out; they are shown by the error field.
+func formatTimeline(v interface{}) string {
+	entries, _ := v.([]interface{})
+	parts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		label := fmt.Sprint(entry["event"])
+		if msg, ok := entry["message"].(string); ok && msg != "" && label == "retry" {
+			label += " " + msg
+		}
+		if ts, ok := entry["time"].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				label += " " + t.Local().Format("15:04")
+			}
+		}
+		parts = append(parts, label)
+	}
+	return strings.Join(parts, " → ")
+}
+
+// formatItem renders one item of a download, e.g.
+// "completed /completed/a.mp4 (1024 bytes)" or "failed 300: tweet unavailable".
+func formatItem(item map[string]interface{}) string {
+	if item["status"] == "failed" {
+		return fmt.Sprintf("failed %v: %v", item["source_id"], item["error_message"])
+	}
+	line := fmt.Sprintf("%v %v", item["status"], item["file_path"])
+	if size, ok := item["file_size"].(float64); ok {
+		line += fmt.Sprintf(" (%.0f bytes)", size)
+	}
+	return line
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
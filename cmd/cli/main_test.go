@@ -1,168 +1,213 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
 	"github.com/yourusername/x-extract-go/internal/domain"
 	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/pkg/logger"
 )
 
-// --- extractChannelIDFromFilename tests ---
-
-func TestExtractChannelIDFromFilename_ValidMediaFile(t *testing.T) {
-	result := extractChannelIDFromFilename("3464638440_1907_blacktiger88-15-11-2025-0i57ki4zky2rp84sk21ht_source.m4v")
-	assert.Equal(t, "3464638440", result)
-}
-
-func TestExtractChannelIDFromFilename_InfoJsonFile(t *testing.T) {
-	result := extractChannelIDFromFilename("3464638440_1907_blacktiger88-15-11-2025-0i57ki4zky2rp84sk21ht_source.info.json")
-	assert.Equal(t, "3464638440", result)
-}
-
-func TestExtractChannelIDFromFilename_NonNumericID(t *testing.T) {
-	result := extractChannelIDFromFilename("somechannel_1907_media.m4v")
-	assert.Equal(t, "", result)
-}
-
-func TestExtractChannelIDFromFilename_TooFewParts(t *testing.T) {
-	result := extractChannelIDFromFilename("singlepart.m4v")
-	assert.Equal(t, "", result)
-}
-
-func TestExtractChannelIDFromFilename_EmptyString(t *testing.T) {
-	result := extractChannelIDFromFilename("")
-	assert.Equal(t, "", result)
-}
-
-func TestExtractChannelIDFromFilename_DifferentChannelID(t *testing.T) {
-	result := extractChannelIDFromFilename("9876543210_42_some_media.mp4")
-	assert.Equal(t, "9876543210", result)
-}
-
-// --- resolveMessageText tests ---
-
-func setupTestRepoForCLI(t *testing.T) (*infrastructure.SQLiteDownloadRepository, func()) {
-	t.Helper()
-	tmpDir, err := os.MkdirTemp("", "cli-test-*")
-	require.NoError(t, err)
-
-	dbPath := filepath.Join(tmpDir, "test.db")
-	repo, err := infrastructure.NewSQLiteDownloadRepository(dbPath)
-	require.NoError(t, err)
-
-	cleanup := func() {
-		repo.Close()
-		os.RemoveAll(tmpDir)
-	}
-	return repo, cleanup
-}
-
-func TestResolveMessageText_DirectLookup(t *testing.T) {
-	repo, cleanup := setupTestRepoForCLI(t)
-	defer cleanup()
-
-	// Save a message with text
-	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
-		{ChannelID: "123", MessageID: "100", Text: "Hello world"},
+func TestFormatProgress(t *testing.T) {
+	assert.Equal(t, "-", formatProgress(map[string]interface{}{"status": "queued", "progress": 0.0}))
+	assert.Equal(t, "45.3% 1.23MiB/s ETA 00:05", formatProgress(map[string]interface{}{
+		"status": "processing", "progress": 45.3, "speed": "1.23MiB/s", "eta": "00:05",
 	}))
-
-	result := resolveMessageText(repo, "123", "100")
-	assert.Equal(t, "Hello world", result)
-}
-
-func TestResolveMessageText_GroupedResolution(t *testing.T) {
-	repo, cleanup := setupTestRepoForCLI(t)
-	defer cleanup()
-
-	// Simulate grouped messages: 1906 has no text, 1907 has text, same group
-	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
-		{ChannelID: "3464638440", MessageID: "1906", Text: "", GroupedID: "14126963880319333"},
-		{ChannelID: "3464638440", MessageID: "1907", Text: "Kengo系列六期。本期共3个批次，第2批次。#DJ0005 🔺会员专享🔻", GroupedID: "14126963880319333"},
+	assert.Equal(t, "0.0%", formatProgress(map[string]interface{}{"status": "processing"}))
+	assert.Equal(t, "recording 2.10MiB/s", formatProgress(map[string]interface{}{
+		"status": "recording", "speed": "2.10MiB/s",
 	}))
-
-	result := resolveMessageText(repo, "3464638440", "1906")
-	assert.Equal(t, "Kengo系列六期。本期共3个批次，第2批次。#DJ0005 🔺会员专享🔻", result)
 }
 
-func TestResolveMessageText_NearbyFallback(t *testing.T) {
-	repo, cleanup := setupTestRepoForCLI(t)
-	defer cleanup()
-
-	// Message 50 has no text and no grouped ID, but nearby message 52 has text
-	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
-		{ChannelID: "123", MessageID: "50", Text: ""},
-		{ChannelID: "123", MessageID: "52", Text: "Nearby text"},
+func TestFormatItem(t *testing.T) {
+	assert.Equal(t, "completed /c/a.mp4 (1024 bytes)", formatItem(map[string]interface{}{
+		"status": "completed", "file_path": "/c/a.mp4", "file_size": 1024.0,
 	}))
-
-	result := resolveMessageText(repo, "123", "50")
-	assert.Equal(t, "Nearby text", result)
-}
-
-func TestResolveMessageText_NoTextFound(t *testing.T) {
-	repo, cleanup := setupTestRepoForCLI(t)
-	defer cleanup()
-
-	// Message exists but no text anywhere nearby
-	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
-		{ChannelID: "123", MessageID: "50", Text: ""},
+	assert.Equal(t, "failed 300: tweet unavailable", formatItem(map[string]interface{}{
+		"status": "failed", "source_id": "300", "error_message": "tweet unavailable",
 	}))
-
-	result := resolveMessageText(repo, "123", "50")
-	assert.Equal(t, "", result)
 }
 
-func TestResolveMessageText_MessageNotInCache(t *testing.T) {
-	repo, cleanup := setupTestRepoForCLI(t)
-	defer cleanup()
-
-	// No messages in cache at all
-	result := resolveMessageText(repo, "123", "999")
-	assert.Equal(t, "", result)
+func TestFormatTimeline(t *testing.T) {
+	at := func(hour, min int) string {
+		return time.Date(2024, 1, 2, hour, min, 0, 0, time.Local).Format(time.RFC3339Nano)
+	}
+	timeline := []interface{}{
+		map[string]interface{}{"time": at(10, 3), "event": "queued"},
+		map[string]interface{}{"time": at(10, 4), "event": "started"},
+		map[string]interface{}{"time": at(10, 12), "event": "retry", "message": "#1"},
+		map[string]interface{}{"time": at(10, 15), "event": "failed", "message": "exit status 1"},
+	}
+	assert.Equal(t, "queued 10:03 → started 10:04 → retry #1 10:12 → failed 10:15", formatTimeline(timeline))
+	assert.Equal(t, "", formatTimeline(nil))
 }
 
-// --- extractIDsFromDownload tests ---
-
-func TestExtractIDsFromDownload_FromFilesList(t *testing.T) {
-	dl := domain.NewDownload("https://t.me/c/3464638440/1907", domain.PlatformTelegram, domain.ModeDefault)
-	metadata := map[string]interface{}{
-		"files": []interface{}{
-			"/path/to/completed/3464638440_1907_blacktiger88_source.m4v",
-		},
+func TestScanForEagleItems_WalksSubdirsExceptImported(t *testing.T) {
+	completedDir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(completedDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 	}
-
-	channelID, msgID := extractIDsFromDownload(dl, metadata)
-	assert.Equal(t, "3464638440", channelID)
-	assert.Equal(t, "1907", msgID)
+	write("flat.mp4", "v")
+	write("flat.info.json", `{"title":"Flat"}`)
+	write("someuser/nested.mp4", "v")
+	write("someuser/nested.info.json", `{"title":"Nested"}`)
+	write("someuser/nometa.jpg", "v")
+	write("imported/done.mp4", "v")
+	write("imported/done.info.json", `{"title":"Done"}`)
+
+	items, skipped := scanForEagleItems(completedDir, "imported")
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	assert.ElementsMatch(t, []string{"Flat", "Nested"}, names)
+	assert.Equal(t, 1, skipped)
+}
+
+func TestTokenTransport_OnlyToServer(t *testing.T) {
+	var got []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	other := httptest.NewServer(handler)
+	defer other.Close()
+
+	client := &http.Client{Transport: &tokenTransport{base: http.DefaultTransport, host: server.Listener.Addr().String(), token: "0123456789abcdef"}}
+	for _, url := range []string{server.URL + "/api/v1/version", other.URL + "/release"} {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, []string{"Bearer 0123456789abcdef", ""}, got)
 }
 
-func TestExtractIDsFromDownload_FromURL(t *testing.T) {
-	dl := domain.NewDownload("https://t.me/c/3464638440/1907", domain.PlatformTelegram, domain.ModeDefault)
-	metadata := map[string]interface{}{} // no files
-
-	channelID, msgID := extractIDsFromDownload(dl, metadata)
-	assert.Equal(t, "3464638440", channelID)
-	assert.Equal(t, "1907", msgID)
-}
+func TestTrustServerCert(t *testing.T) {
+	config := domain.DefaultConfig()
+	config.Download.BaseDir = t.TempDir()
+	config.Server.TLS = domain.TLSConfig{Enabled: true, SelfSigned: true}
+	certFile, keyFile, err := infrastructure.EnsureSelfSignedCert(config.Download.TLSDir(), config.Server.SelfSignedHosts())
+	require.NoError(t, err)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
 
-func TestExtractIDsFromDownload_EmptyMetadata(t *testing.T) {
-	dl := domain.NewDownload("https://example.com/video", domain.PlatformTelegram, domain.ModeDefault)
-	metadata := map[string]interface{}{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
 
-	channelID, msgID := extractIDsFromDownload(dl, metadata)
-	assert.Equal(t, "", channelID)
-	assert.Equal(t, "", msgID)
-}
+	defaultTransport := http.DefaultTransport
+	defer func() { http.DefaultTransport = defaultTransport }()
 
-func TestExtractIDsFromDownload_NonTelegramURL(t *testing.T) {
-	dl := domain.NewDownload("https://x.com/user/status/123", domain.PlatformTelegram, domain.ModeDefault)
-	metadata := map[string]interface{}{}
+	_, err = (&http.Client{}).Get(server.URL)
+	assert.Error(t, err, "self-signed certificates don't verify by default")
 
-	channelID, msgID := extractIDsFromDownload(dl, metadata)
-	assert.Equal(t, "", channelID)
-	assert.Equal(t, "", msgID)
+	trustServerCert(config)
+	resp, err := (&http.Client{}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestWatchFrame(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	frame := watchFrame("Every 2s: x-extract-cli list", now, func(out io.Writer) error {
+		fmt.Fprintln(out, "ID  URL")
+		return nil
+	})
+	assert.True(t, strings.HasPrefix(frame, clearScreen), "clears the screen first")
+	assert.Contains(t, frame, "Every 2s: x-extract-cli list    2026-03-10 12:00:00\n\nID  URL\n")
+
+	frame = watchFrame("title", now, func(out io.Writer) error {
+		fmt.Fprintln(out, "partial")
+		return errors.New("connection refused")
+	})
+	assert.NotContains(t, frame, "partial", "a failed render is replaced by its error")
+	assert.Contains(t, frame, "Error: connection refused\n")
+}
+
+func TestRunWatch_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	renders := 0
+	runWatch(ctx, &buf, "title", time.Millisecond, func(out io.Writer) error {
+		if renders++; renders == 3 {
+			cancel()
+		}
+		return nil
+	})
+	assert.Equal(t, 3, renders)
+	assert.Equal(t, 3, strings.Count(buf.String(), clearScreen))
+}
+
+func TestDecodeKeys(t *testing.T) {
+	assert.Equal(t, []string{"up", "down", "j", "tab", "shift-tab", "pgdn", "esc"},
+		decodeKeys([]byte("\033[A\033[Bj\t\033[Z\033[6~\033")))
+	assert.Equal(t, []string{"é", "q"}, decodeKeys([]byte("éq")))
+}
+
+func TestFitWidth(t *testing.T) {
+	assert.Equal(t, "abc  ", fitWidth("abc", 5))
+	assert.Equal(t, "a b", fitWidth("a\nbcd", 3), "control characters become spaces")
+	assert.Equal(t, "日本 ", fitWidth("日本語", 5), "wide characters take two columns")
+	assert.Equal(t, "[████░░░░]", progressBar(50, 10))
+	assert.Equal(t, "[░░░░░░░░]", progressBar(-5, 10))
+}
+
+func TestTUIModel(t *testing.T) {
+	running := &domain.Download{ID: "11111111-aaaa", URL: "https://x.com/a/status/1", Platform: domain.PlatformX,
+		Status: domain.StatusProcessing, Progress: 42, Speed: "1.5MiB/s", ETA: "00:10"}
+	queued := []*domain.Download{
+		{ID: "22222222-bbbb", Status: domain.StatusQueued, CreatedAt: time.Unix(100, 0)},
+		{ID: "33333333-cccc", Status: domain.StatusQueued, CreatedAt: time.Unix(200, 0), Priority: 5},
+		{ID: "44444444-dddd", Status: domain.StatusQueued, CreatedAt: time.Unix(50, 0)},
+	}
+	sortQueue(queued)
+	assert.Equal(t, []string{"33333333-cccc", "44444444-dddd", "22222222-bbbb"},
+		[]string{queued[0].ID, queued[1].ID, queued[2].ID}, "highest priority, then oldest first")
+
+	m := &tuiModel{width: 100, height: 20}
+	m.setSnapshot(tuiSnapshot{
+		stats:     &domain.DownloadStats{Queued: 3, Processing: 1},
+		downloads: append([]*domain.Download{running}, queued...),
+		logs:      []logger.LogEntry{{Timestamp: "2026-03-10T12:00:00.000Z", Level: "info", Message: "Download started", Fields: map[string]interface{}{"download_id": running.ID}}},
+	})
+	assert.Contains(t, strings.Join(m.render(time.Now()), "\n"), "INFO  Download started 11111...")
+	assert.Equal(t, tuiNone, m.handleKey("down"))
+	assert.Equal(t, tuiPrioritize, m.handleKey("p"))
+	assert.Equal(t, "33333333-cccc", m.selectedDownload().ID)
+
+	// A refresh that reorders the list keeps the selection on the same download
+	m.setSnapshot(tuiSnapshot{downloads: []*domain.Download{queued[0], running}})
+	assert.Equal(t, 0, m.selected)
+
+	lines := m.render(time.Now())
+	require.Len(t, lines, m.height, "fills the screen")
+	screen := strings.Join(lines, "\n")
+	assert.Contains(t, screen, "42.0% 1.5MiB/s 00:10")
+	assert.Contains(t, screen, "priority 5")
+
+	assert.Equal(t, tuiRefresh, m.handleKey("tab"))
+	assert.Equal(t, 1, m.view)
+	assert.Empty(t, m.snapshot.downloads)
+	m.setSnapshot(tuiSnapshot{view: 0, downloads: queued})
+	assert.Empty(t, m.snapshot.downloads, "a late snapshot of the previous view is dropped")
+	assert.Equal(t, tuiQuit, m.handleKey("q"))
 }
-
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var regenerateMetadataCmd = &cobra.Command{
+	Use:   "regenerate-metadata",
+	Short: "Regenerate metadata for Telegram downloads with missing text",
+	Long: `Fills in empty descriptions of Telegram downloads from the message cache,
+for every channel: first in the .info.json files in the completed directory,
+then in the download records. It uses grouped message resolution (media
+albums) and nearby message fallback to find the text. Does NOT re-download
+any files.
+
+The server does the work; this command starts it and shows its progress.`,
+	Example: `  x-extract regenerate-metadata --dry-run
+  x-extract regenerate-metadata`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		const path = "/api/v1/maintenance/regenerate-metadata"
+		doJSONRequest(http.MethodPost, path, map[string]interface{}{"dry_run": dryRun}, http.StatusAccepted)
+
+		var status app.RegenerateMetadataStatus
+		lastPhase := ""
+		for {
+			if err := json.Unmarshal(doGetRequest(path), &status); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if status.Phase != lastPhase {
+				if lastPhase != "" {
+					fmt.Println()
+				}
+				lastPhase = status.Phase
+			}
+			fmt.Printf("\rChecking %s: %d/%d", status.Phase, status.Checked, status.Total)
+			if !status.Running {
+				break
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		fmt.Println()
+
+		if status.Error != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", status.Error)
+			os.Exit(1)
+		}
+		if dryRun {
+			fmt.Printf("Dry run: would update %d JSON files and %d DB entries\n", status.FilesUpdated, status.DownloadsUpdated)
+		} else {
+			fmt.Printf("Updated %d JSON files and %d DB entries\n", status.FilesUpdated, status.DownloadsUpdated)
+		}
+	},
+}
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Show the maintenance window and the jobs waiting for it",
+	Long: `Show maintenance.window: whether it is open, the heavy jobs it holds
+(maintenance.jobs) and how many completed downloads have transcodes or
+uploads waiting for it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		var status domain.MaintenanceStatus
+		if err := json.Unmarshal(doGetRequest("/api/v1/maintenance/window"), &status); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if status.Window == "" {
+			fmt.Println("No maintenance window: heavy jobs run any time")
+		} else {
+			state := "closed"
+			if status.Open {
+				state = "open"
+			}
+			fmt.Printf("Window:   %s (%s)\n", status.Window, state)
+			if status.NextOpenAt != nil {
+				fmt.Printf("Opens:    %s\n", status.NextOpenAt.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Printf("Jobs:     %s\n", strings.Join(status.Jobs, ", "))
+		}
+		fmt.Printf("Deferred: %d downloads\n", status.Deferred)
+		if status.LastRunAt != nil {
+			fmt.Printf("Last run: %s\n", status.LastRunAt.Local().Format("2006-01-02 15:04"))
+		}
+		if status.LastVacuumAt != nil {
+			fmt.Printf("Vacuumed: %s\n", status.LastVacuumAt.Local().Format("2006-01-02 15:04"))
+		}
+	},
+}
+
+func init() {
+	regenerateMetadataCmd.Flags().BoolP("dry-run", "n", false, "Show what would be updated without making changes")
+	// The server regenerates the metadata under its own completed directory
+	regenerateMetadataCmd.Flags().StringP("completed-dir", "d", "", "")
+	regenerateMetadataCmd.Flags().MarkDeprecated("completed-dir", "the server's completed directory is used")
+
+	rootCmd.AddCommand(regenerateMetadataCmd)
+	rootCmd.AddCommand(maintenanceCmd)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+// readOffline reports whether a read-only command (list, get, stats, logs)
+// should read the database directly instead of asking the server: when
+// --offline is given, or when the server is not running. Starting the server
+// just to read would keep it running until auto-exit, so these commands only
+// use it when it is already running.
+func readOffline(cmd *cobra.Command) bool {
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		return true
+	}
+	if isServerRunning() {
+		return false
+	}
+	fmt.Fprintln(os.Stderr, "Server is not running; reading the database directly (read-only)")
+	return true
+}
+
+// openOfflineRepo loads the config and opens its database read-only, exiting
+// on failure
+func openOfflineRepo() (*infrastructure.SQLiteDownloadRepository, *domain.Config) {
+	config, err := app.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	repo, err := infrastructure.NewReadOnlySQLiteDownloadRepository(config.Queue.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	return repo, config
+}
+
+// offlineList returns the downloads matching filters and opts as the list
+// API would, along with the number of matches ignoring limit and offset
+func offlineList(filters map[string]interface{}, opts domain.ListOptions) ([]byte, int64) {
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	repo, _ := openOfflineRepo()
+	defer repo.Close()
+
+	downloads, err := repo.FindAll(filters, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	total, err := repo.CountAll(filters, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	body, _ := json.Marshal(downloads)
+	return body, total
+}
+
+// offlineGet returns a download as the get API would
+func offlineGet(id string) []byte {
+	repo, _ := openOfflineRepo()
+	defer repo.Close()
+
+	download, err := repo.FindByID(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: download not found: %s\n", id)
+		os.Exit(1)
+	}
+	body, _ := json.Marshal(download)
+	return body
+}
+
+// offlineStats returns the download statistics as the stats API would
+func offlineStats() []byte {
+	repo, _ := openOfflineRepo()
+	defer repo.Close()
+
+	stats, err := repo.GetStats()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	body, _ := json.Marshal(stats)
+	return body
+}
+
+// offlineStatsHistory returns the stats of the last days days as GET
+// /api/v1/downloads/stats/history does
+func offlineStatsHistory(days int) []byte {
+	repo, _ := openOfflineRepo()
+	defer repo.Close()
+
+	history, err := repo.GetStatsHistory(days, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	body, _ := json.Marshal(history)
+	return body
+}
+
+// offlineLogs returns the process log of a download: the log stored with the
+// download, or else its dl-<id>.log file in the logs directory
+func offlineLogs(id string) string {
+	repo, config := openOfflineRepo()
+	defer repo.Close()
+
+	download, err := repo.FindByID(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: download not found: %s\n", id)
+		os.Exit(1)
+	}
+	if download.ProcessLog != "" {
+		return download.ProcessLog
+	}
+	data, err := os.ReadFile(filepath.Join(config.Download.LogsDir(), "dl-"+download.ID+".log"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no process log for download %s\n", id)
+		os.Exit(1)
+	}
+	return string(data)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write an archive report now",
+	Long: `Write a report of the last full day or week: downloads, failures, top
+sources and storage growth. Reports are saved to base_dir/reports; set
+report.enabled to write one automatically after each period.`,
+	Example: `  x-extract report
+  x-extract report --period weekly --format html`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		period, _ := cmd.Flags().GetString("period")
+		format, _ := cmd.Flags().GetString("format")
+		payload := map[string]interface{}{}
+		if period != "" {
+			payload["period"] = period
+		}
+		if format != "" {
+			payload["format"] = format
+		}
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/reports", payload, http.StatusCreated)
+		report, _ := result["report"].(map[string]interface{})
+		fmt.Printf("Report written: %s\n", result["path"])
+		fmt.Printf("  Completed: %v\n", report["completed"])
+		fmt.Printf("  Failed:    %v\n", report["failed"])
+		fmt.Printf("  Cancelled: %v\n", report["cancelled"])
+		if size, ok := report["bytes"].(float64); ok {
+			fmt.Printf("  Growth:    %.0f bytes\n", size)
+		}
+	},
+}
+
+var reportListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List written reports",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		body := doGetRequest("/api/v1/reports")
+		var reports []map[string]interface{}
+		json.Unmarshal(body, &reports)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tWRITTEN")
+		for _, r := range reports {
+			fmt.Fprintf(w, "%s\t%v\t%v\n", r["name"], r["size"], r["modified_at"])
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	reportCmd.Flags().String("period", "", "Report period: daily or weekly (default: report.period)")
+	reportCmd.Flags().String("format", "", "Report format: markdown or html (default: report.format)")
+
+	reportCmd.AddCommand(reportListCmd)
+	rootCmd.AddCommand(reportCmd)
+}
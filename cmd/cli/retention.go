@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var retentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Apply the retention policy now",
+	Long: `Expire completed downloads older than retention.max_age_days, or the
+oldest ones while the archive is larger than retention.max_size. Their files
+are deleted and the records are kept with status "expired". Set
+retention.enabled to apply the policy automatically.`,
+	Example: `  x-extract retention --dry-run
+  x-extract retention`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		result := doJSONRequest(http.MethodPost, "/api/v1/retention/run",
+			map[string]interface{}{"dry_run": dryRun}, http.StatusOK)
+
+		downloads, _ := result["downloads"].([]interface{})
+		if len(downloads) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSIZE\tCOMPLETED\tURL")
+			for _, d := range downloads {
+				dl, _ := d.(map[string]interface{})
+				size, _ := dl["file_size"].(float64)
+				fmt.Fprintf(w, "%v\t%s\t%v\t%v\n", dl["id"], domain.FormatBytes(int64(size)), dl["completed_at"], dl["url"])
+			}
+			w.Flush()
+		}
+
+		verb := "Expired"
+		if dryRun {
+			verb = "Would expire"
+		}
+		freed, _ := result["freed_bytes"].(float64)
+		fmt.Printf("%s %v downloads (%s)\n", verb, result["expired"], domain.FormatBytes(int64(freed)))
+	},
+}
+
+func init() {
+	retentionCmd.Flags().Bool("dry-run", false, "Only list the downloads that would expire")
+
+	rootCmd.AddCommand(retentionCmd)
+}
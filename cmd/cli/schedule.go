@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage recurring channel/account syncs",
+	Long: `Manage schedules that periodically sync a Telegram channel or X account
+and enqueue new messages/tweets as downloads.
+
+Cron expressions use the standard 5 fields (minute hour day-of-month month
+day-of-week) or one of @hourly, @daily, @weekly, @monthly, @yearly.`,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add [source-url]",
+	Short: "Add a schedule",
+	Example: `  x-extract schedule add https://t.me/c/123456789 --cron "0 * * * *"
+  x-extract schedule add https://x.com/someuser --cron @daily --name someuser`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		cron, _ := cmd.Flags().GetString("cron")
+		name, _ := cmd.Flags().GetString("name")
+		platform, _ := cmd.Flags().GetString("platform")
+
+		payload := map[string]interface{}{
+			"source_url": args[0],
+			"cron":       cron,
+		}
+		if name != "" {
+			payload["name"] = name
+		}
+		if platform != "" {
+			payload["platform"] = platform
+		}
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/schedules", payload, http.StatusCreated)
+		fmt.Printf("Schedule added successfully!\n")
+		fmt.Printf("ID:       %s\n", result["id"])
+		fmt.Printf("Next run: %v\n", result["next_run_at"])
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List schedules",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		body := doGetRequest("/api/v1/schedules")
+		var schedules []map[string]interface{}
+		json.Unmarshal(body, &schedules)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSOURCE\tCRON\tENABLED\tNEXT RUN\tLAST ERROR")
+		for _, s := range schedules {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\t%v\t%s\n",
+				s["id"],
+				truncate(fmt.Sprint(s["name"]), 20),
+				truncate(fmt.Sprint(s["source_url"]), 40),
+				s["cron_expr"],
+				s["enabled"],
+				valueOrDash(s["next_run_at"]),
+				truncate(fmt.Sprint(valueOrDash(s["last_error"])), 40))
+		}
+		w.Flush()
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodDelete, "/api/v1/schedules/"+args[0], nil, http.StatusOK)
+		fmt.Println("Schedule removed successfully")
+	},
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run [id]",
+	Short: "Run a schedule now",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		result := doJSONRequest(http.MethodPost, "/api/v1/schedules/"+args[0]+"/run", nil, http.StatusOK)
+		fmt.Printf("Enqueued: %v\n", result["last_enqueued"])
+		if errMsg, ok := result["last_error"].(string); ok && errMsg != "" {
+			fmt.Printf("Error:    %s\n", errMsg)
+		}
+	},
+}
+
+var scheduleEnableCmd = &cobra.Command{
+	Use:   "enable [id]",
+	Short: "Enable a schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodPatch, "/api/v1/schedules/"+args[0], map[string]interface{}{"enabled": true}, http.StatusOK)
+		fmt.Println("Schedule enabled")
+	},
+}
+
+var scheduleDisableCmd = &cobra.Command{
+	Use:   "disable [id]",
+	Short: "Disable a schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodPatch, "/api/v1/schedules/"+args[0], map[string]interface{}{"enabled": false}, http.StatusOK)
+		fmt.Println("Schedule disabled")
+	},
+}
+
+// valueOrDash renders nil or empty values as "-" for table output
+func valueOrDash(v interface{}) interface{} {
+	if v == nil || v == "" {
+		return "-"
+	}
+	return v
+}
+
+func init() {
+	scheduleAddCmd.Flags().String("cron", "", "Cron expression (e.g. \"0 * * * *\" or @daily)")
+	scheduleAddCmd.Flags().String("name", "", "Schedule name")
+	scheduleAddCmd.Flags().StringP("platform", "p", "", "Platform (telegram, x); auto-detected if omitted")
+	scheduleAddCmd.MarkFlagRequired("cron")
+
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleRunCmd)
+	scheduleCmd.AddCommand(scheduleEnableCmd)
+	scheduleCmd.AddCommand(scheduleDisableCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
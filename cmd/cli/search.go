@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// searchDateLayout is the date format accepted by --from/--to
+const searchDateLayout = "2006-01-02"
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Manage saved searches",
+	Long: `Save named download filters and run them as one-click views.
+
+A saved search can be referenced by its ID or name.`,
+}
+
+var searchSaveCmd = &cobra.Command{
+	Use:   "save [name]",
+	Short: "Save a search",
+	Example: `  x-extract search save failed-x --status failed --platform x
+  x-extract search save january --from 2024-01-01 --to 2024-01-31 --query cats
+  x-extract search save chinese-posts --platform telegram --language zh`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		filter, err := searchFilterFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		payload := map[string]interface{}{
+			"name":   args[0],
+			"filter": filter,
+		}
+		result := doJSONRequest(http.MethodPost, "/api/v1/searches", payload, http.StatusCreated)
+		fmt.Printf("Search saved successfully!\n")
+		fmt.Printf("ID: %s\n", result["id"])
+	},
+}
+
+var searchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved searches",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		body := doGetRequest("/api/v1/searches")
+		var searches []map[string]interface{}
+		json.Unmarshal(body, &searches)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tFILTER")
+		for _, s := range searches {
+			filter, _ := json.Marshal(s["filter"])
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s["id"], s["name"], filter)
+		}
+		w.Flush()
+	},
+}
+
+var searchRunCmd = &cobra.Command{
+	Use:   "run [id-or-name]",
+	Short: "Show the downloads matching a saved search",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		path := "/api/v1/searches/" + url.PathEscape(args[0]) + "/results"
+		if limit > 0 {
+			path += fmt.Sprintf("?limit=%d", limit)
+		}
+		body := doGetRequest(path)
+		var downloads []map[string]interface{}
+		json.Unmarshal(body, &downloads)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tURL\tPLATFORM\tSTATUS\tUPLOADER\tCREATED")
+		for _, d := range downloads {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				truncate(fmt.Sprint(d["id"]), 8),
+				truncate(fmt.Sprint(d["url"]), 40),
+				d["platform"],
+				d["status"],
+				truncate(fmt.Sprint(valueOrDash(d["uploader"])), 20),
+				d["created_at"])
+		}
+		w.Flush()
+	},
+}
+
+var searchDeleteCmd = &cobra.Command{
+	Use:   "delete [id-or-name]",
+	Short: "Delete a saved search",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodDelete, "/api/v1/searches/"+url.PathEscape(args[0]), nil, http.StatusOK)
+		fmt.Println("Saved search deleted successfully")
+	},
+}
+
+// searchFilterFromFlags builds the filter payload from the save command flags.
+// --to is inclusive: the whole day is part of the range.
+func searchFilterFromFlags(cmd *cobra.Command) (map[string]interface{}, error) {
+	filter := make(map[string]interface{})
+	for _, name := range []string{"status", "platform", "tag", "uploader", "language", "query", "text"} {
+		if value, _ := cmd.Flags().GetString(name); value != "" {
+			filter[name] = value
+		}
+	}
+
+	if from, _ := cmd.Flags().GetString("from"); from != "" {
+		t, err := time.ParseInLocation(searchDateLayout, from, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --from date (want YYYY-MM-DD): %s", from)
+		}
+		filter["from"] = t.Format(time.RFC3339)
+	}
+	if to, _ := cmd.Flags().GetString("to"); to != "" {
+		t, err := time.ParseInLocation(searchDateLayout, to, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --to date (want YYYY-MM-DD): %s", to)
+		}
+		filter["to"] = t.AddDate(0, 0, 1).Format(time.RFC3339)
+	}
+	return filter, nil
+}
+
+func init() {
+	searchSaveCmd.Flags().StringP("status", "s", "", "Filter by status")
+	searchSaveCmd.Flags().StringP("platform", "p", "", "Filter by platform")
+	searchSaveCmd.Flags().String("tag", "", "Filter by metadata tag")
+	searchSaveCmd.Flags().String("uploader", "", "Filter by uploader name or ID")
+	searchSaveCmd.Flags().String("language", "", "Filter by language of the post text (ISO 639-1, e.g. zh)")
+	searchSaveCmd.Flags().String("from", "", "Downloads added on or after this date (YYYY-MM-DD)")
+	searchSaveCmd.Flags().String("to", "", "Downloads added on or before this date (YYYY-MM-DD)")
+	searchSaveCmd.Flags().StringP("query", "q", "", "Text to match in URL, title or uploader")
+	searchSaveCmd.Flags().String("text", "", "Full-text terms to match in URL, title, description, uploader, tags, alt text or poll options")
+	searchRunCmd.Flags().IntP("limit", "l", 0, "Maximum number of results (0 = all)")
+
+	searchCmd.AddCommand(searchSaveCmd)
+	searchCmd.AddCommand(searchListCmd)
+	searchCmd.AddCommand(searchRunCmd)
+	searchCmd.AddCommand(searchDeleteCmd)
+	rootCmd.AddCommand(searchCmd)
+}
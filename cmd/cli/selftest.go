@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var selfTestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check the download pipeline end to end",
+	Long: `Check the database, the download directories, and for each platform its
+tools, its cookies and the listing of the media of a known-safe URL
+(selftest.urls, or --url). Nothing is downloaded. Run it after upgrades or
+cookie refreshes; it exits with status 1 when a stage fails.`,
+	Example: `  x-extract selftest
+  x-extract selftest --platform x --url x=https://x.com/user/status/123`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		payload := map[string]interface{}{}
+		if platforms, _ := cmd.Flags().GetStringSlice("platform"); len(platforms) > 0 {
+			payload["platforms"] = platforms
+		}
+		urls := map[string]string{}
+		values, _ := cmd.Flags().GetStringSlice("url")
+		for _, value := range values {
+			platform, url, ok := strings.Cut(value, "=")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: --url %q is not platform=url\n", value)
+				os.Exit(1)
+			}
+			urls[platform] = url
+		}
+		if len(urls) > 0 {
+			payload["urls"] = urls
+		}
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/admin/selftest", payload, http.StatusOK)
+		data, _ := json.MarshalIndent(result, "", "  ")
+		var selfTest domain.SelfTestResult
+		json.Unmarshal(data, &selfTest)
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			fmt.Println(string(data))
+		} else {
+			printSelfTest(&selfTest)
+		}
+		if !selfTest.Passed {
+			os.Exit(1)
+		}
+	},
+}
+
+// printSelfTest prints the stages of a self-test for humans
+func printSelfTest(result *domain.SelfTestResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLATFORM\tSTAGE\tSTATUS\tTIME\tMESSAGE")
+	for _, stage := range result.Stages {
+		fmt.Fprintf(w, "-\t%s\t%s\t%dms\t%s\n", stage.Name, stage.Status, stage.DurationMs, valueOrDash(stage.Message))
+	}
+	for _, platform := range result.Platforms {
+		for _, stage := range platform.Stages {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%dms\t%s\n", platform.Platform, stage.Name, stage.Status, stage.DurationMs, valueOrDash(stage.Message))
+		}
+	}
+	w.Flush()
+
+	if result.Passed {
+		fmt.Printf("\nSelf-test passed (%dms)\n", result.DurationMs)
+	} else {
+		fmt.Printf("\nSelf-test failed (%dms)\n", result.DurationMs)
+	}
+}
+
+func init() {
+	selfTestCmd.Flags().StringSlice("platform", nil, "Only test these platforms (x, telegram, instagram, gallery)")
+	selfTestCmd.Flags().StringSlice("url", nil, "URL to list for a platform instead of selftest.urls, as platform=url")
+	selfTestCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	rootCmd.AddCommand(selfTestCmd)
+}
@@ -6,7 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"time"
+
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
 )
 
 const (
@@ -27,28 +31,37 @@ func isServerRunning() bool {
 
 // findServerBinary locates the x-extract-server binary
 func findServerBinary() (string, error) {
+	name := "x-extract-server"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
 	// 1. Check same directory as CLI binary
 	execPath, err := os.Executable()
 	if err == nil {
 		execDir := filepath.Dir(execPath)
-		serverPath := filepath.Join(execDir, "x-extract-server")
+		serverPath := filepath.Join(execDir, name)
 		if _, err := os.Stat(serverPath); err == nil {
 			return serverPath, nil
 		}
 	}
 
 	// 2. Check PATH
-	serverPath, err := exec.LookPath("x-extract-server")
+	serverPath, err := exec.LookPath(name)
 	if err == nil {
 		return serverPath, nil
 	}
 
 	// 3. Check common locations
-	commonPaths := []string{
-		"/usr/local/bin/x-extract-server",
-		"/usr/bin/x-extract-server",
-		filepath.Join(os.Getenv("HOME"), "go/bin/x-extract-server"),
-		filepath.Join(os.Getenv("HOME"), ".local/bin/x-extract-server"),
+	var commonPaths []string
+	if runtime.GOOS != "windows" {
+		commonPaths = append(commonPaths, "/usr/local/bin/"+name, "/usr/bin/"+name)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		commonPaths = append(commonPaths,
+			filepath.Join(home, "go", "bin", name),
+			filepath.Join(home, ".local", "bin", name),
+		)
 	}
 
 	for _, p := range commonPaths {
@@ -57,7 +70,7 @@ func findServerBinary() (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("x-extract-server binary not found")
+	return "", fmt.Errorf("%s binary not found", name)
 }
 
 // startServerBackground starts the server as a detached background process
@@ -70,6 +83,7 @@ func startServerBackground() error {
 
 	// Start server in background
 	cmd := exec.Command(serverPath, "-server-mode")
+	setSysProcAttr(cmd)
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start server: %w", err)
@@ -97,12 +111,31 @@ func waitForServerReady() error {
 	return fmt.Errorf("server did not start within %v", serverStartTimeout)
 }
 
-// ensureServerRunning checks if server is running, starts it if not
+// runningServerPID returns the PID of the server holding the PID file of
+// the configured base_dir, or 0 when none does
+func runningServerPID() int {
+	config, err := app.LoadConfig()
+	if err != nil {
+		return 0
+	}
+	return infrastructure.RunningPID(config.Download.PIDFilePath())
+}
+
+// ensureServerRunning checks if server is running, starts it if not. A
+// server that holds the PID file but does not answer yet (still starting) is
+// waited for instead of starting a second one.
 func ensureServerRunning() error {
 	if isServerRunning() {
 		return nil
 	}
 
+	if pid := runningServerPID(); pid != 0 {
+		if err := waitForServerReady(); err != nil {
+			return fmt.Errorf("server (PID %d) is running but not responding at %s: %w", pid, serverURL, err)
+		}
+		return nil
+	}
+
 	fmt.Println("Server not running, starting...")
 
 	if err := startServerBackground(); err != nil {
@@ -7,9 +7,13 @@ import (
 	"syscall"
 )
 
+// detachedProcess is DETACHED_PROCESS: the process gets no console
+const detachedProcess = 0x00000008
+
 // setSysProcAttr sets platform-specific process attributes for detaching
 func setSysProcAttr(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+		CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
 	}
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe [channel-or-account-url]",
+	Short: "Subscribe to a Telegram channel or X account",
+	Long: `Subscribe to a Telegram channel or X account: every interval the server
+exports the channel, or lists the account's timeline with yt-dlp, and
+enqueues the new media posts as downloads.
+
+Only messages newer than the newest one in the message cache are downloaded
+(or, when nothing of the channel is cached and for X accounts, those posted
+after subscribing); --backfill downloads the whole channel, or the newest
+tweets of the account, a batch per check.`,
+	Example: `  x-extract subscribe https://t.me/channelname
+  x-extract subscribe https://t.me/c/1234567890 --interval 1h --profile work
+  x-extract subscribe https://t.me/channelname --backfill
+  x-extract subscribe https://x.com/someuser --interval 2h`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		payload := map[string]interface{}{"url": args[0]}
+		for _, flag := range []string{"name", "interval", "profile"} {
+			if value, _ := cmd.Flags().GetString(flag); value != "" {
+				payload[flag] = value
+			}
+		}
+		if backfill, _ := cmd.Flags().GetBool("backfill"); backfill {
+			payload["backfill"] = true
+		}
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/subscriptions", payload, http.StatusCreated)
+		fmt.Printf("Subscribed to %s\n", result["source_url"])
+		fmt.Printf("ID:       %s\n", result["id"])
+		fmt.Printf("Interval: %s\n", result["interval"])
+	},
+}
+
+var unsubscribeCmd = &cobra.Command{
+	Use:   "unsubscribe [id]",
+	Short: "Remove a subscription",
+	Long:  `Remove a subscription. Downloads it already enqueued are kept.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodDelete, "/api/v1/subscriptions/"+args[0], nil, http.StatusOK)
+		fmt.Println("Unsubscribed")
+	},
+}
+
+var subscriptionsCmd = &cobra.Command{
+	Use:   "subscriptions",
+	Short: "List and manage channel and account subscriptions",
+	Run:   subscriptionsListCmd.Run,
+}
+
+var subscriptionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subscriptions",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		body := doGetRequest("/api/v1/subscriptions")
+		var subscriptions []map[string]interface{}
+		json.Unmarshal(body, &subscriptions)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tPLATFORM\tSOURCE\tPROFILE\tINTERVAL\tENABLED\tENQUEUED\tCHECKS\tFAILED\tLAST NEW\tNEXT CHECK\tLAST ERROR")
+		for _, s := range subscriptions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%v\t%v\t%v\t%v\t%v\t%v\t%s\n",
+				s["id"],
+				s["platform"],
+				truncate(fmt.Sprint(s["source_url"]), 40),
+				valueOrDash(s["profile"]),
+				s["interval"],
+				s["enabled"],
+				s["total_enqueued"],
+				s["checks"],
+				s["failed_checks"],
+				valueOrDash(s["last_new_at"]),
+				valueOrDash(s["next_check_at"]),
+				truncate(fmt.Sprint(valueOrDash(s["last_error"])), 40))
+		}
+		w.Flush()
+	},
+}
+
+var subscriptionsCheckCmd = &cobra.Command{
+	Use:   "check [id]",
+	Short: "Check a subscription for new posts now",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		result := doJSONRequest(http.MethodPost, "/api/v1/subscriptions/"+args[0]+"/check", nil, http.StatusOK)
+		fmt.Printf("Enqueued: %v\n", result["last_enqueued"])
+		if errMsg, ok := result["last_error"].(string); ok && errMsg != "" {
+			fmt.Printf("Error:    %s\n", errMsg)
+		}
+	},
+}
+
+var subscriptionsUpdateCmd = &cobra.Command{
+	Use:     "update [id]",
+	Short:   "Change the name or interval of a subscription",
+	Example: `  x-extract subscriptions update a1b2c3d4 --interval 1h`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		payload := map[string]interface{}{}
+		for _, flag := range []string{"name", "interval"} {
+			if cmd.Flags().Changed(flag) {
+				value, _ := cmd.Flags().GetString(flag)
+				payload[flag] = value
+			}
+		}
+		if len(payload) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: nothing to update (use --name or --interval)")
+			os.Exit(1)
+		}
+
+		doJSONRequest(http.MethodPatch, "/api/v1/subscriptions/"+args[0], payload, http.StatusOK)
+		fmt.Println("Subscription updated")
+	},
+}
+
+var subscriptionsEnableCmd = &cobra.Command{
+	Use:   "enable [id]",
+	Short: "Enable a subscription",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodPatch, "/api/v1/subscriptions/"+args[0], map[string]interface{}{"enabled": true}, http.StatusOK)
+		fmt.Println("Subscription enabled")
+	},
+}
+
+var subscriptionsDisableCmd = &cobra.Command{
+	Use:   "disable [id]",
+	Short: "Disable a subscription",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+		doJSONRequest(http.MethodPatch, "/api/v1/subscriptions/"+args[0], map[string]interface{}{"enabled": false}, http.StatusOK)
+		fmt.Println("Subscription disabled")
+	},
+}
+
+func init() {
+	subscribeCmd.Flags().String("name", "", "Subscription name")
+	subscribeCmd.Flags().String("interval", "", "How often the source is checked, e.g. 30m (default: subscriptions.interval or its platform override)")
+	subscribeCmd.Flags().String("profile", "", "Telegram: tdl profile to export and download with (default: telegram.profile)")
+	subscribeCmd.Flags().Bool("backfill", false, "Download every post of the source, not only new ones")
+
+	subscriptionsUpdateCmd.Flags().String("name", "", "Subscription name")
+	subscriptionsUpdateCmd.Flags().String("interval", "", "How often the source is checked, e.g. 30m")
+
+	subscriptionsCmd.AddCommand(subscriptionsListCmd)
+	subscriptionsCmd.AddCommand(subscriptionsCheckCmd)
+	subscriptionsCmd.AddCommand(subscriptionsUpdateCmd)
+	subscriptionsCmd.AddCommand(subscriptionsEnableCmd)
+	subscriptionsCmd.AddCommand(subscriptionsDisableCmd)
+	rootCmd.AddCommand(subscribeCmd)
+	rootCmd.AddCommand(unsubscribeCmd)
+	rootCmd.AddCommand(subscriptionsCmd)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect config, logs, stats and versions into a zip for bug reports",
+	Long: `Write a zip with what is needed to debug an installation:
+
+  config.json       every config key with its effective value and source
+  environment.json  OS, paths, free disk space and X-Extract env var names
+  database.json     database size, download stats, the last week's activity
+                    and the most recent failures
+  tools.json        paths and versions of yt-dlp, tdl and gallery-dl
+  server.json       health of the running server, when it runs
+  logs/             the last 5MiB of each log file of the last --days days
+  manifest.json     when and by which build the bundle was made, and what
+                    could not be collected
+
+Passwords, keys and API tokens are redacted. The database is read directly,
+so the server does not have to run and is not started.`,
+	Example: `  x-extract support-bundle
+  x-extract support-bundle --days 7 -o /tmp/x-extract-support.zip`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		output, _ := cmd.Flags().GetString("output")
+		if days < 1 {
+			fmt.Fprintf(os.Stderr, "Error: invalid --days: %d\n", days)
+			os.Exit(1)
+		}
+		if output == "" {
+			output = fmt.Sprintf("x-extract-support-%s.zip", time.Now().Format("20060102-150405"))
+		}
+
+		config, err := app.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		bundle := app.NewSupportBundle(config, days)
+		if repo, err := infrastructure.NewReadOnlySQLiteDownloadRepository(config.Queue.DatabasePath); err != nil {
+			bundle.SetDatabase(nil, err)
+		} else {
+			defer repo.Close()
+			bundle.SetDatabase(repo, nil)
+		}
+		bundle.SetServer(serverURL, fetchServerHealth())
+
+		f, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		info, err := bundle.Write(context.Background(), f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Wrote %s (%d files)\n", output, len(info.Files))
+		for name, reason := range info.Errors {
+			fmt.Printf("  missing %s: %s\n", name, reason)
+		}
+		fmt.Println("Check it for anything private before sharing it.")
+	},
+}
+
+// fetchServerHealth returns what GET /health of the server at serverURL
+// returns, or nil when it is not running. The server is not started for this.
+func fetchServerHealth() interface{} {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(serverURL + "/health")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var health map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil
+	}
+	return health
+}
+
+func init() {
+	supportBundleCmd.Flags().Int("days", app.DefaultSupportLogDays, "Days of logs to include, today included")
+	supportBundleCmd.Flags().StringP("output", "o", "", "Zip file to write (default: x-extract-support-<time>.zip)")
+
+	rootCmd.AddCommand(supportBundleCmd)
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/app"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/internal/infrastructure/binmanager"
+)
+
+var telegramCmd = &cobra.Command{
+	Use:   "telegram",
+	Short: "Manage the tdl session and channel list used for Telegram downloads",
+	Long: `Log in to Telegram with tdl and check the stored session. The commands use
+the tdl profile and storage configured under telegram (profile, storage_type,
+storage_path), so the session is the one Telegram downloads use. --profile
+picks another profile of telegram.profiles.`,
+}
+
+var telegramLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to Telegram with tdl and verify the session",
+	Long: `Run "tdl login" with the configured profile and storage, passing its prompts
+through to this terminal, then verify the new session by listing chats with
+"tdl chat ls". The result is stored in the database and shown by
+"telegram status".`,
+	Example: `  x-extract telegram login
+  x-extract telegram login --type qr
+  x-extract telegram login --profile work`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Note: This command doesn't need the server running
+		loginType, _ := cmd.Flags().GetString("type")
+		switch loginType {
+		case "", "desktop", "code", "qr":
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid login type %q (expected desktop, code or qr)\n", loginType)
+			os.Exit(1)
+		}
+
+		config, downloader := newTelegramSessionDownloader()
+		profile := telegramProfileFlag(cmd, config)
+		downloader = downloader.ForProfile(profile)
+		fmt.Printf("Logging in to Telegram (tdl profile %q)...\n", profile)
+		login := downloader.LoginCommand(loginType)
+		login.Stdin, login.Stdout, login.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := login.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: tdl login failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Verifying session...")
+		session := verifyTelegramSession(config, downloader)
+		if !session.Verified {
+			os.Exit(1)
+		}
+	},
+}
+
+var telegramStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the stored state of the tdl session",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Note: This command doesn't need the server running
+		verify, _ := cmd.Flags().GetBool("verify")
+
+		if verify {
+			config, downloader := newTelegramSessionDownloader()
+			verifyTelegramSession(config, downloader.ForProfile(telegramProfileFlag(cmd, config)))
+			return
+		}
+
+		repo, config := openOfflineRepo()
+		defer repo.Close()
+		profile := telegramProfileFlag(cmd, config)
+		session, err := repo.GetTelegramSession(profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Printf("Profile %q has not been verified; run 'x-extract telegram login' or 'telegram status --verify'\n",
+				profile)
+			return
+		}
+		printTelegramSession(session)
+	},
+}
+
+var telegramChannelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "List the stored Telegram channel names",
+	Long: `List the Telegram channels whose names are used in download titles, with
+the name they had before their last rename. The list is refreshed by the
+server every telegram.channel_sync_interval; use "channels sync" to refresh
+it now.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Note: This command doesn't need the server running
+		repo, _ := openOfflineRepo()
+		defer repo.Close()
+		channels, err := repo.ListChannels()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(channels) == 0 {
+			fmt.Println("No channels stored yet; run 'x-extract telegram channels sync'")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tUSERNAME\tPREVIOUS NAME\tRENAMED")
+		for _, ch := range channels {
+			renamed := ""
+			if ch.RenamedAt != nil {
+				renamed = ch.RenamedAt.Local().Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ch.ChannelID, ch.ChannelName, ch.Username, ch.PreviousName, renamed)
+		}
+		w.Flush()
+	},
+}
+
+var telegramChannelsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Refresh the channel list now and show renamed channels",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/telegram/channels/sync", nil, http.StatusOK)
+		channels, _ := result["channels"].(float64)
+		renamed, _ := result["renamed"].([]interface{})
+		fmt.Printf("Synced %d channels (%v new, %d renamed)\n", int(channels), result["added"], len(renamed))
+		for _, r := range renamed {
+			rename, _ := r.(map[string]interface{})
+			fmt.Printf("  %v: %q -> %q\n", rename["channel_id"], rename["old_name"], rename["new_name"])
+		}
+		if len(renamed) > 0 {
+			fmt.Println("Run 'x-extract telegram channels rerender <channel-id>' to update their downloads")
+		}
+	},
+}
+
+var telegramChannelsRerenderCmd = &cobra.Command{
+	Use:   "rerender <channel-id>",
+	Short: "Update a renamed channel's downloads to its current name",
+	Long: `Update the completed downloads of a channel that were saved under an older
+name: the channel, uploader and title in their metadata and .info.json
+sidecars, and file names containing the old name.`,
+	Example: `  x-extract telegram channels rerender 1234567890 --dry-run
+  x-extract telegram channels rerender 1234567890`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		result := doJSONRequest(http.MethodPost, "/api/v1/telegram/channels/"+args[0]+"/rerender",
+			map[string]interface{}{"dry_run": dryRun}, http.StatusOK)
+
+		verb := "Updated"
+		if dryRun {
+			verb = "Would update"
+		}
+		fmt.Printf("%s %v downloads of %q (%v sidecars, %v files renamed)\n",
+			verb, result["downloads"], result["name"], result["sidecars"], result["files_renamed"])
+	},
+}
+
+var telegramChannelsAliasCmd = &cobra.Command{
+	Use:   "alias <channel> [successor]",
+	Short: "Record that a channel continues as another one",
+	Long: `Record that a channel continues as another one, e.g. a group migrated to a
+supergroup (new ID) or a public channel with a new username. Downloads of
+both then count as one source: uploader stats are merged, organize_by folders
+use the successor, and a message already downloaded from one channel is a
+duplicate of the same message of the other. Channels are given as numeric ID,
+username or t.me link. Without a successor, the aliases (of the channel, if
+given) are listed.`,
+	Example: `  x-extract telegram channels alias oldnews 1234567890
+  x-extract telegram channels alias https://t.me/c/1111111111 2222222222
+  x-extract telegram channels alias oldnews --remove
+  x-extract telegram channels alias`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		remove, _ := cmd.Flags().GetBool("remove")
+		switch {
+		case remove:
+			if len(args) != 1 {
+				fmt.Fprintln(os.Stderr, "Error: --remove takes the channel only")
+				os.Exit(1)
+			}
+			doJSONRequest(http.MethodDelete, "/api/v1/telegram/channels/"+url.PathEscape(args[0])+"/alias", nil, http.StatusOK)
+			fmt.Printf("Removed the alias of %s\n", args[0])
+		case len(args) == 2:
+			alias := doJSONRequest(http.MethodPut, "/api/v1/telegram/channels/"+url.PathEscape(args[0])+"/alias",
+				map[string]interface{}{"successor_id": args[1]}, http.StatusOK)
+			fmt.Printf("%v now continues as %v\n", alias["channel_id"], alias["successor_id"])
+		default:
+			result := doJSONRequest(http.MethodGet, "/api/v1/telegram/channels/aliases", nil, http.StatusOK)
+			aliases, _ := result["aliases"].([]interface{})
+			if len(aliases) == 0 {
+				fmt.Println("No channel aliases")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHANNEL\tSUCCESSOR")
+			for _, a := range aliases {
+				alias, _ := a.(map[string]interface{})
+				if len(args) == 1 && alias["channel_id"] != args[0] && alias["successor_id"] != args[0] {
+					continue
+				}
+				fmt.Fprintf(w, "%v\t%v\n", alias["channel_id"], alias["successor_id"])
+			}
+			w.Flush()
+		}
+	},
+}
+
+// newTelegramSessionDownloader loads the config and returns a Telegram
+// downloader running the resolved tdl binary, exiting on failure
+func newTelegramSessionDownloader() (*domain.Config, *infrastructure.TelegramDownloader) {
+	config, err := app.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	tdlBinary, err := binmanager.ResolveBinary("tdl", config.Telegram.TDLBinary,
+		config.Download.BinDirectory(), config.Download.PreferManagedBinaries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: tdl not found: %v (install it with 'x-extract tools install tdl')\n", err)
+		os.Exit(1)
+	}
+	config.Telegram.TDLBinary = tdlBinary
+
+	downloader := infrastructure.NewTelegramDownloader(&config.Telegram,
+		config.Download.IncomingDir(), config.Download.CompletedDir(), config.Download.LogsDir(), nil)
+	return config, downloader
+}
+
+// telegramProfileFlag returns the tdl profile of the --profile flag, or
+// telegram.profile without it, exiting on a profile that is not configured
+func telegramProfileFlag(cmd *cobra.Command, config *domain.Config) string {
+	profile, _ := cmd.Flags().GetString("profile")
+	if profile == "" {
+		return config.Telegram.Profile
+	}
+	if !config.Telegram.HasProfile(profile) {
+		fmt.Fprintf(os.Stderr, "Error: unknown Telegram profile %q (configure it under telegram.profiles)\n", profile)
+		os.Exit(1)
+	}
+	return profile
+}
+
+// verifyTelegramSession probes the tdl session, stores the result and prints it
+func verifyTelegramSession(config *domain.Config, downloader *infrastructure.TelegramDownloader) *domain.TelegramSession {
+	session := downloader.VerifySession(context.Background())
+
+	repo, err := infrastructure.NewSQLiteDownloadRepository(config.Queue.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
+	if err := repo.SaveTelegramSession(session); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving session state: %v\n", err)
+		os.Exit(1)
+	}
+
+	printTelegramSession(session)
+	return session
+}
+
+func printTelegramSession(session *domain.TelegramSession) {
+	fmt.Printf("Profile:  %s\n", session.Profile)
+	if session.Verified {
+		fmt.Printf("Session:  ✓ verified (%d chats)\n", session.Chats)
+	} else {
+		fmt.Printf("Session:  ✗ not working: %s\n", session.Error)
+	}
+	fmt.Printf("Checked:  %s\n", session.VerifiedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+func init() {
+	telegramLoginCmd.Flags().String("type", "", "tdl login type: desktop (import Telegram Desktop), code or qr (default: tdl's default)")
+	telegramStatusCmd.Flags().Bool("verify", false, "Probe the session with tdl chat ls and store the result")
+	telegramLoginCmd.Flags().String("profile", "", "tdl profile of telegram.profiles (default: telegram.profile)")
+	telegramStatusCmd.Flags().String("profile", "", "tdl profile of telegram.profiles (default: telegram.profile)")
+
+	telegramChannelsRerenderCmd.Flags().Bool("dry-run", false, "Only count what would change")
+	telegramChannelsAliasCmd.Flags().Bool("remove", false, "Remove the alias of the channel")
+
+	telegramChannelsCmd.AddCommand(telegramChannelsSyncCmd)
+	telegramChannelsCmd.AddCommand(telegramChannelsRerenderCmd)
+	telegramChannelsCmd.AddCommand(telegramChannelsAliasCmd)
+	telegramCmd.AddCommand(telegramLoginCmd)
+	telegramCmd.AddCommand(telegramStatusCmd)
+	telegramCmd.AddCommand(telegramChannelsCmd)
+	rootCmd.AddCommand(telegramCmd)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var testURLCmd = &cobra.Command{
+	Use:   "test-url [url]",
+	Short: "Show what a download would fetch without downloading it",
+	Long: `Run the download's tool in simulate mode (yt-dlp --simulate for X,
+a tdl chat export for Telegram) and report the media it would download, the
+settings applied to the download and its exact command line. Nothing is
+queued or downloaded.`,
+	Example: `  x-extract test-url https://x.com/user/status/123
+  x-extract test-url https://x.com/user/media --mode profile
+  x-extract test-url https://t.me/channel/100 --to 120 --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureServer()
+
+		payload := map[string]interface{}{"url": args[0]}
+		for flag, key := range map[string]string{"platform": "platform", "mode": "mode", "account": "account", "profile": "tdl_profile"} {
+			if value, _ := cmd.Flags().GetString(flag); value != "" {
+				payload[key] = value
+			}
+		}
+		if rangeEnd, _ := cmd.Flags().GetInt("to"); rangeEnd != 0 {
+			payload["range_end"] = rangeEnd
+		}
+		if allVariants, _ := cmd.Flags().GetBool("all-variants"); allVariants {
+			payload["all_variants"] = true
+		}
+
+		result := doJSONRequest(http.MethodPost, "/api/v1/downloads/simulate", payload, http.StatusOK)
+		data, _ := json.MarshalIndent(result, "", "  ")
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			fmt.Println(string(data))
+			return
+		}
+		var sim domain.Simulation
+		json.Unmarshal(data, &sim)
+		printSimulation(&sim)
+	},
+}
+
+// printSimulation prints a simulation for humans
+func printSimulation(sim *domain.Simulation) {
+	fmt.Printf("URL:      %s\n", sim.URL)
+	fmt.Printf("Platform: %s\n", sim.Platform)
+	fmt.Printf("Mode:     %s\n", sim.Mode)
+	fmt.Printf("Tool:     %s\n", sim.Tool)
+	fmt.Printf("\nCommand:\n  %s\n", sim.Command)
+	fmt.Printf("\nProbe (run to list the media):\n  %s\n", sim.Probe)
+
+	keys := make([]string, 0, len(sim.Config))
+	for key := range sim.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Printf("\nConfiguration:\n")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s\t%s\n", key, sim.Config[key])
+	}
+	w.Flush()
+
+	fmt.Printf("\nWould download %d item(s):\n", len(sim.Items))
+	if len(sim.Items) > 0 {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  ID\tFILENAME\tSIZE\tTITLE")
+		for _, item := range sim.Items {
+			size := "-"
+			if item.Size > 0 {
+				size = domain.FormatBytes(item.Size)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n",
+				valueOrDash(item.ID), valueOrDash(item.Filename), size, truncate(item.Title, 40))
+		}
+		w.Flush()
+	}
+
+	if sim.Error != "" {
+		fmt.Printf("\nError: %s\n", sim.Error)
+		if sim.ErrorCode != "" {
+			fmt.Printf("Error code: %s\n", sim.ErrorCode)
+		}
+	}
+}
+
+func init() {
+	testURLCmd.Flags().StringP("platform", "p", "", "Platform (x, telegram); detected from the URL by default")
+	testURLCmd.Flags().StringP("mode", "m", "", "Download mode (default, single, group, profile)")
+	testURLCmd.Flags().String("account", "", "X: twitter.accounts account to download with")
+	testURLCmd.Flags().String("profile", "", "Telegram: telegram.profiles tdl profile to download with")
+	testURLCmd.Flags().Int("to", 0, "Telegram: last message ID of a range starting at the URL's message")
+	testURLCmd.Flags().Bool("all-variants", false, "X: every image at original resolution and every video rendition")
+	testURLCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	rootCmd.AddCommand(testURLCmd)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+// serverCertFile returns the certificate the server of config serves with
+// server.tls, or "" without TLS
+func serverCertFile(config *domain.Config) string {
+	tlsConfig := config.Server.TLS
+	switch {
+	case !tlsConfig.Enabled:
+		return ""
+	case tlsConfig.SelfSigned:
+		return filepath.Join(config.Download.TLSDir(), infrastructure.SelfSignedCertFile)
+	default:
+		return tlsConfig.CertFile
+	}
+}
+
+// trustServerCert makes the CLI trust the certificate of the server of config
+// (which may be nil) besides the system's, so a self-signed certificate
+// verifies. The file is read at each handshake, since the server creates the
+// self-signed one only when it first starts, possibly started by this CLI.
+func trustServerCert(config *domain.Config) {
+	if config == nil {
+		return
+	}
+	certFile := serverCertFile(config)
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if certFile == "" || !ok {
+		return
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: true, // Verified by VerifyConnection instead
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return verifyServerCert(cs, certFile)
+		},
+	}
+	http.DefaultTransport = transport
+}
+
+// verifyServerCert verifies the certificate of a connection as crypto/tls
+// does, trusting certFile besides the system's roots
+func verifyServerCert(cs tls.ConnectionState, certFile string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server sent no certificate")
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if data, err := os.ReadFile(certFile); err == nil {
+		roots.AppendCertsFromPEM(data)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
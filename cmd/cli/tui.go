@@ -0,0 +1,716 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// Terminal control sequences of the TUI
+const (
+	enterAltScreen = "\033[?1049h"
+	exitAltScreen  = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	cursorHome     = "\033[H"
+	clearLine      = "\033[K"
+	clearBelow     = "\033[J"
+	reverseVideo   = "\033[7m"
+	boldText       = "\033[1m"
+	resetStyle     = "\033[0m"
+)
+
+// tuiFetchLimit is how many downloads of each status a view fetches, and
+// tuiLogLines how many lines of the queue log are fetched
+const (
+	tuiFetchLimit = 200
+	tuiLogLines   = 50
+)
+
+// tuiView is a list of downloads of the TUI, switched with Tab
+type tuiView struct {
+	name     string
+	statuses []domain.DownloadStatus // Fetched in this order (none = every status, newest first)
+}
+
+var tuiViews = []tuiView{
+	{name: "Queue", statuses: []domain.DownloadStatus{domain.StatusProcessing, domain.StatusRecording, domain.StatusQueued}},
+	{name: "Failed", statuses: []domain.DownloadStatus{domain.StatusFailed}},
+	{name: "Completed", statuses: []domain.DownloadStatus{domain.StatusCompleted}},
+	{name: "All"},
+}
+
+// tuiSnapshot is what one refresh of the TUI fetched from the server
+type tuiSnapshot struct {
+	view      int
+	stats     *domain.DownloadStats
+	downloads []*domain.Download
+	logs      []logger.LogEntry
+	err       error
+}
+
+// tuiAction is what a key asks the TUI loop to do
+type tuiAction int
+
+const (
+	tuiNone tuiAction = iota
+	tuiQuit
+	tuiRefresh
+	tuiCancel
+	tuiRetry
+	tuiPrioritize
+)
+
+// tuiModel is the state of the TUI: the last snapshot, the selection and the
+// result of the last action. It only renders; the loop of runTUI fetches
+// and calls the server.
+type tuiModel struct {
+	view       int
+	snapshot   tuiSnapshot
+	selected   int
+	selectedID string // Keeps the selection on the same download across refreshes
+	offset     int    // First download shown
+	message    string
+	width      int
+	height     int
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Monitor and manage downloads in a terminal UI",
+	Long: `Show a terminal version of the dashboard: download statistics, the queue
+with a progress bar for each running download, the failed, completed or all
+downloads, and the latest lines of the queue log. It refreshes every
+--interval until you quit.
+
+Keys:
+  ↑/↓, j/k    select a download (g/G: first/last)
+  Tab, 1-4    switch between the Queue, Failed, Completed and All views
+  c           cancel the selected download
+  r           retry the selected download
+  p           move the selected queued download to the top of the queue
+  R           refresh now
+  q           quit`,
+	Example: `  x-extract tui
+  x-extract tui --interval 5s`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval < minWatchInterval {
+			fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+			os.Exit(1)
+		}
+		ensureServer()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runTUI(ctx, interval); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runTUI runs the terminal UI on stdin and stdout until q is pressed or ctx
+// is done. Fetches and actions run in the background so keys are handled
+// while the server answers.
+func runTUI(ctx context.Context, interval time.Duration) error {
+	fd := int(os.Stdin.Fd())
+	state, err := makeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("the terminal UI needs a terminal: %w", err)
+	}
+	defer restoreTerminal(fd, state)
+	fmt.Print(enterAltScreen + hideCursor)
+	defer fmt.Print(resetStyle + showCursor + exitAltScreen)
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+
+	snapshots := make(chan tuiSnapshot)
+	messages := make(chan string)
+	refresh := func(view int) {
+		go func() { snapshots <- fetchTUISnapshot(view) }()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m := &tuiModel{message: "Loading..."}
+	refresh(m.view)
+	for {
+		m.width, m.height = 80, 24
+		if width, height, err := terminalSize(fd); err == nil && width > 0 && height > 0 {
+			m.width, m.height = width, height
+		}
+		io.WriteString(os.Stdout, drawTUI(m.render(time.Now())))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resized:
+		case <-ticker.C:
+			refresh(m.view)
+		case snapshot := <-snapshots:
+			m.setSnapshot(snapshot)
+		case message := <-messages:
+			m.message = message
+			refresh(m.view)
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch action := m.handleKey(key); action {
+			case tuiQuit:
+				return nil
+			case tuiRefresh:
+				refresh(m.view)
+			case tuiCancel, tuiRetry, tuiPrioritize:
+				d := m.selectedDownload()
+				go func() { messages <- runTUIAction(action, d) }()
+			}
+		}
+	}
+}
+
+// readKeys sends the keys read from r to keys until r fails
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range decodeKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// decodeKeys splits what one read of the terminal returned into keys: the
+// arrow, Home/End and Page Up/Down escape sequences are named ("up", "pgdn"),
+// other keys are their characters
+func decodeKeys(data []byte) []string {
+	sequences := map[string]string{
+		"\033[A": "up", "\033[B": "down", "\033[H": "home", "\033[F": "end",
+		"\033[5~": "pgup", "\033[6~": "pgdn", "\033[Z": "shift-tab",
+		"\033OA": "up", "\033OB": "down", "\033OH": "home", "\033OF": "end",
+	}
+	var keys []string
+	for s := string(data); s != ""; {
+		matched := false
+		for seq, name := range sequences {
+			if strings.HasPrefix(s, seq) {
+				keys, s, matched = append(keys, name), s[len(seq):], true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		switch r {
+		case '\033':
+			keys = append(keys, "esc")
+		case '\t':
+			keys = append(keys, "tab")
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		default:
+			keys = append(keys, string(r))
+		}
+		s = s[size:]
+	}
+	return keys
+}
+
+// fetchTUISnapshot fetches the statistics, the downloads of view and the
+// latest lines of the queue log
+func fetchTUISnapshot(view int) tuiSnapshot {
+	snapshot := tuiSnapshot{view: view}
+
+	body, _, err := fetchAPI("/api/v1/downloads/stats")
+	if err != nil {
+		snapshot.err = err
+		return snapshot
+	}
+	json.Unmarshal(body, &snapshot.stats)
+
+	statuses := tuiViews[view].statuses
+	if len(statuses) == 0 {
+		statuses = []domain.DownloadStatus{""}
+	}
+	for _, status := range statuses {
+		downloads, err := fetchTUIDownloads(status)
+		if err != nil {
+			snapshot.err = err
+			return snapshot
+		}
+		snapshot.downloads = append(snapshot.downloads, downloads...)
+	}
+
+	if body, _, err := fetchAPI(fmt.Sprintf("/api/v1/logs/queue?limit=%d", tuiLogLines)); err == nil {
+		var logs struct {
+			Entries []logger.LogEntry `json:"entries"`
+		}
+		json.Unmarshal(body, &logs)
+		snapshot.logs = logs.Entries
+	}
+	return snapshot
+}
+
+// fetchTUIDownloads fetches the downloads of status (empty = all, newest
+// first). Queued downloads come in the order the queue runs them.
+func fetchTUIDownloads(status domain.DownloadStatus) ([]*domain.Download, error) {
+	params := url.Values{}
+	params.Set("limit", fmt.Sprint(tuiFetchLimit))
+	if status != "" {
+		params.Set("status", string(status))
+	}
+	if status == domain.StatusQueued {
+		params.Set("sort_by", "priority")
+	}
+	body, _, err := fetchAPI("/api/v1/downloads?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var downloads []*domain.Download
+	if err := json.Unmarshal(body, &downloads); err != nil {
+		return nil, err
+	}
+	if status == domain.StatusQueued {
+		sortQueue(downloads)
+	}
+	return downloads, nil
+}
+
+// sortQueue sorts queued downloads in the order the queue runs them: highest
+// priority first, then oldest first
+func sortQueue(downloads []*domain.Download) {
+	sort.SliceStable(downloads, func(i, j int) bool {
+		if downloads[i].Priority != downloads[j].Priority {
+			return downloads[i].Priority > downloads[j].Priority
+		}
+		return downloads[i].CreatedAt.Before(downloads[j].CreatedAt)
+	})
+}
+
+// runTUIAction cancels, retries or prioritizes d and returns the message
+// to show. The server decides what can be cancelled and retried.
+func runTUIAction(action tuiAction, d *domain.Download) string {
+	if d == nil {
+		return "No download selected"
+	}
+	short := truncate(d.ID, 8)
+
+	var err error
+	switch action {
+	case tuiCancel:
+		_, err = sendJSON(http.MethodPost, "/api/v1/downloads/"+d.ID+"/cancel", nil, http.StatusOK)
+		if err == nil {
+			return "Cancelled " + short
+		}
+	case tuiRetry:
+		_, err = sendJSON(http.MethodPost, "/api/v1/downloads/"+d.ID+"/retry", nil, http.StatusOK)
+		if err == nil {
+			return "Retrying " + short
+		}
+	case tuiPrioritize:
+		if d.Status != domain.StatusQueued {
+			return fmt.Sprintf("%s is %s; only queued downloads can be moved", short, d.Status)
+		}
+		var queue []*domain.Download
+		if queue, err = fetchTUIDownloads(domain.StatusQueued); err != nil {
+			break
+		}
+		if len(queue) == 0 || queue[0].ID == d.ID {
+			return short + " is already at the top of the queue"
+		}
+		_, err = sendJSON(http.MethodPost, "/api/v1/queue/reorder", map[string]interface{}{
+			"id": d.ID, "before": queue[0].ID,
+		}, http.StatusOK)
+		if err == nil {
+			return "Moved " + short + " to the top of the queue"
+		}
+	}
+	return "Error: " + apiErrorMessage(err)
+}
+
+// apiErrorMessage returns the "error" field of an API error response, or
+// the error itself when it is not one
+func apiErrorMessage(err error) string {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal([]byte(err.Error()), &body) == nil && body.Error != "" {
+		return body.Error
+	}
+	return err.Error()
+}
+
+// setSnapshot shows snapshot, keeping the selection on the same download.
+// Snapshots of another view than the current one arrive late and are dropped.
+func (m *tuiModel) setSnapshot(snapshot tuiSnapshot) {
+	if snapshot.view != m.view {
+		return
+	}
+	if m.message == "Loading..." {
+		m.message = ""
+	}
+	if snapshot.err != nil {
+		// Keep showing the last downloads while the server is away
+		m.snapshot.err = snapshot.err
+		return
+	}
+	m.snapshot = snapshot
+	for i, d := range snapshot.downloads {
+		if d.ID == m.selectedID {
+			m.selected = i
+		}
+	}
+	m.selectRow(m.selected)
+}
+
+// selectRow selects the download at row i, clamped to the list
+func (m *tuiModel) selectRow(i int) {
+	if last := len(m.snapshot.downloads) - 1; i > last {
+		i = last
+	}
+	if i < 0 {
+		i = 0
+	}
+	m.selected = i
+	m.selectedID = ""
+	if d := m.selectedDownload(); d != nil {
+		m.selectedID = d.ID
+	}
+}
+
+// selectedDownload returns the selected download, nil when the list is empty
+func (m *tuiModel) selectedDownload() *domain.Download {
+	if m.selected < len(m.snapshot.downloads) {
+		return m.snapshot.downloads[m.selected]
+	}
+	return nil
+}
+
+// switchView shows view i, dropping the downloads of the previous view
+func (m *tuiModel) switchView(i int) tuiAction {
+	m.view = (i + len(tuiViews)) % len(tuiViews)
+	m.snapshot = tuiSnapshot{view: m.view, stats: m.snapshot.stats, logs: m.snapshot.logs}
+	m.selected, m.selectedID, m.offset = 0, "", 0
+	return tuiRefresh
+}
+
+// handleKey updates the model for key and returns what the loop must do
+func (m *tuiModel) handleKey(key string) tuiAction {
+	page := m.listRows()
+	switch key {
+	case "q", "Q", "\x03":
+		return tuiQuit
+	case "up", "k":
+		m.selectRow(m.selected - 1)
+	case "down", "j":
+		m.selectRow(m.selected + 1)
+	case "pgup":
+		m.selectRow(m.selected - page)
+	case "pgdn":
+		m.selectRow(m.selected + page)
+	case "home", "g":
+		m.selectRow(0)
+	case "end", "G":
+		m.selectRow(len(m.snapshot.downloads) - 1)
+	case "tab":
+		return m.switchView(m.view + 1)
+	case "shift-tab":
+		return m.switchView(m.view - 1)
+	case "1", "2", "3", "4":
+		return m.switchView(int(key[0] - '1'))
+	case "R":
+		return tuiRefresh
+	case "c":
+		return tuiCancel
+	case "r":
+		return tuiRetry
+	case "p":
+		return tuiPrioritize
+	}
+	return tuiNone
+}
+
+// logRows is how many lines of the queue log are shown: a quarter of the
+// screen, at most 8
+func (m *tuiModel) logRows() int {
+	rows := m.height / 4
+	if rows > 8 {
+		rows = 8
+	}
+	if rows > len(m.snapshot.logs) {
+		rows = len(m.snapshot.logs)
+	}
+	return rows
+}
+
+// listRows is how many downloads fit on the screen: all but the 5 lines of
+// header and footer and the log
+func (m *tuiModel) listRows() int {
+	rows := m.height - 5
+	if logs := m.logRows(); logs > 0 {
+		rows -= logs + 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// render returns the lines of the screen, each at most m.width wide
+func (m *tuiModel) render(now time.Time) []string {
+	var lines []string
+	add := func(style, text string) {
+		text = fitWidth(text, m.width)
+		if style != "" {
+			text = style + text + resetStyle
+		}
+		lines = append(lines, text)
+	}
+
+	// Title with the views, current one highlighted
+	title := boldText + "X-Extract" + resetStyle
+	used := len("X-Extract")
+	for i, view := range tuiViews {
+		label := fmt.Sprintf(" %d %s ", i+1, view.name)
+		if used+1+len(label) > m.width {
+			break
+		}
+		used += 1 + len(label)
+		if i == m.view {
+			label = reverseVideo + label + resetStyle
+		}
+		title += " " + label
+	}
+	if clock := now.Format("15:04:05"); used+len(clock)+1 <= m.width {
+		title += strings.Repeat(" ", m.width-used-len(clock)) + clock
+	}
+	lines = append(lines, title)
+
+	if stats := m.snapshot.stats; stats != nil {
+		add("", fmt.Sprintf("Queued %d  Running %d  Recording %d  Completed %d  Failed %d  Cancelled %d  Today %s",
+			stats.Queued, stats.Processing, stats.Recording, stats.Completed, stats.Failed, stats.Cancelled,
+			domain.FormatBytes(stats.BytesToday)))
+	} else {
+		add("", "")
+	}
+	switch {
+	case m.snapshot.err != nil:
+		add(boldText, "Error: "+apiErrorMessage(m.snapshot.err))
+	default:
+		add("", m.message)
+	}
+
+	// Downloads, scrolled to keep the selection in view
+	rows := m.listRows()
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+rows {
+		m.offset = m.selected - rows + 1
+	}
+	add(boldText, formatTUIRow("ID", "STATUS", "PLATFORM", "PROGRESS", "TITLE / URL"))
+	downloads := m.snapshot.downloads
+	for i := m.offset; i < m.offset+rows; i++ {
+		if i >= len(downloads) {
+			add("", "")
+			continue
+		}
+		d := downloads[i]
+		style := ""
+		if i == m.selected {
+			style = reverseVideo
+		}
+		add(style, formatTUIRow(truncate(d.ID, 8), string(d.Status), string(d.Platform), tuiProgress(d), tuiTitle(d)))
+	}
+
+	// Latest lines of the queue log, newest last
+	if logRows := m.logRows(); logRows > 0 {
+		add(boldText, "Queue log")
+		for _, entry := range m.snapshot.logs[len(m.snapshot.logs)-logRows:] {
+			add("", formatTUILog(entry))
+		}
+	}
+
+	add(reverseVideo, fmt.Sprintf(" %d/%d  ↑↓ select  Tab view  c cancel  r retry  p top of queue  R refresh  q quit",
+		m.selected+min(1, len(downloads)), len(downloads)))
+	return lines
+}
+
+// drawTUI returns what redraws the screen with lines: each line is cleared
+// to its end instead of clearing the whole screen, so it doesn't flicker
+func drawTUI(lines []string) string {
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for i, line := range lines {
+		b.WriteString(line)
+		b.WriteString(clearLine)
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(clearBelow)
+	return b.String()
+}
+
+// tuiProgressWidth is the width of the progress column, bar and numbers
+const tuiProgressWidth = 36
+
+// formatTUIRow lays out a row of the download list; the title takes the
+// rest of the line
+func formatTUIRow(id, status, platform, progress, title string) string {
+	return fmt.Sprintf("%s %s %s %s %s",
+		fitWidth(id, 8), fitWidth(status, 10), fitWidth(platform, 8), fitWidth(progress, tuiProgressWidth), title)
+}
+
+// tuiProgress describes where d is: a progress bar with speed and ETA while
+// it runs, the priority while queued, the error once failed, the size once
+// completed
+func tuiProgress(d *domain.Download) string {
+	switch d.Status {
+	case domain.StatusProcessing:
+		text := fmt.Sprintf(" %5.1f%%", d.Progress)
+		if d.Speed != "" {
+			text += " " + d.Speed
+		}
+		if d.ETA != "" {
+			text += " " + d.ETA
+		}
+		return progressBar(d.Progress, tuiProgressWidth-displayWidth(text)) + text
+	case domain.StatusRecording:
+		return strings.TrimSpace("● rec " + d.Speed)
+	case domain.StatusQueued:
+		if d.Deferred != "" {
+			return "deferred: " + d.Deferred
+		}
+		return fmt.Sprintf("priority %d", d.Priority)
+	case domain.StatusFailed:
+		if d.ErrorCode != "" {
+			return string(d.ErrorCode)
+		}
+		return d.ErrorMessage
+	case domain.StatusCompleted:
+		if d.FileSize > 0 {
+			return domain.FormatBytes(d.FileSize)
+		}
+	}
+	return "-"
+}
+
+// progressBar draws percent (0-100) as a bar of width cells
+func progressBar(percent float64, width int) string {
+	if width < 3 {
+		return ""
+	}
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	inner := width - 2
+	filled := int(percent / 100 * float64(inner))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", inner-filled) + "]"
+}
+
+// tuiTitle returns the title of d, or its URL until it is known
+func tuiTitle(d *domain.Download) string {
+	if d.Title != "" {
+		return d.Title
+	}
+	return d.URL
+}
+
+// formatTUILog renders a queue log entry on one line: time, level, message
+// and the download it is about
+func formatTUILog(entry logger.LogEntry) string {
+	timestamp := entry.Timestamp
+	for _, layout := range []string{"2006-01-02T15:04:05.000Z0700", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, entry.Timestamp); err == nil {
+			timestamp = t.Local().Format("15:04:05")
+			break
+		}
+	}
+	line := fmt.Sprintf("%s %-5s %s", timestamp, strings.ToUpper(entry.Level), entry.Message)
+	if id, ok := entry.Fields["download_id"].(string); ok {
+		line += " " + truncate(id, 8)
+	}
+	return line
+}
+
+// fitWidth pads or cuts s to exactly width terminal columns. Control
+// characters become spaces, and wide (CJK, emoji) characters take two columns.
+func fitWidth(s string, width int) string {
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			r = ' '
+		}
+		w := runeWidth(r)
+		if used+w > width {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + strings.Repeat(" ", width-used)
+}
+
+// displayWidth returns how many terminal columns s takes
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth returns how many terminal columns r takes: two for East Asian
+// wide characters and emoji, one for the others
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
+
+func init() {
+	tuiCmd.Flags().Duration("interval", time.Second, "Time between refreshes")
+
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -0,0 +1,32 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// errNoTUI is returned where the terminal cannot be put in raw mode
+var errNoTUI = errors.New("the terminal UI is not supported on this system; use list --watch instead")
+
+// terminalState is the terminal mode to restore when the TUI exits
+type terminalState struct{}
+
+// makeRaw is not supported on this system
+func makeRaw(fd int) (*terminalState, error) {
+	return nil, errNoTUI
+}
+
+// restoreTerminal is not supported on this system
+func restoreTerminal(fd int, state *terminalState) error {
+	return errNoTUI
+}
+
+// terminalSize is not supported on this system
+func terminalSize(fd int) (width, height int, err error) {
+	return 0, 0, errNoTUI
+}
+
+// notifyResize does nothing: resizes are picked up at the next refresh
+func notifyResize(c chan<- os.Signal) {}
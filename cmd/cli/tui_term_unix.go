@@ -0,0 +1,54 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalState is the terminal mode to restore when the TUI exits
+type terminalState struct {
+	termios unix.Termios
+}
+
+// makeRaw puts the terminal of fd in raw mode: keys are read one at a time
+// and not echoed. Output processing and signals are left on, so "\n" still
+// starts a new line and Ctrl-C still interrupts.
+func makeRaw(fd int) (*terminalState, error) {
+	termios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	state := &terminalState{termios: *termios}
+
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.IEXTEN
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, termios); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// restoreTerminal puts the terminal of fd back in the mode makeRaw found it in
+func restoreTerminal(fd int, state *terminalState) error {
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, &state.termios)
+}
+
+// terminalSize returns the columns and rows of the terminal of fd
+func terminalSize(fd int) (width, height int, err error) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// notifyResize sends to c when the terminal is resized
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, unix.SIGWINCH)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the build of the CLI and of the running server",
+	Long: `Show the version, commit and build date of the CLI and, when it is running,
+of the server. The server is not started for this. Include the output in bug
+reports.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := domain.CurrentBuild()
+		server, serverErr := fetchServerBuild()
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			output := map[string]interface{}{"cli": client, "server": server}
+			data, _ := json.MarshalIndent(output, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		fmt.Printf("CLI:    %s\n", client)
+		if serverErr != nil {
+			fmt.Printf("Server: not reachable at %s\n", serverURL)
+			return
+		}
+		fmt.Printf("Server: %s\n", server)
+		if server.Version != client.Version || server.Commit != client.Commit {
+			fmt.Println("Warning: the CLI and the server are different builds")
+		}
+	},
+}
+
+// fetchServerBuild returns the build of the server at serverURL, without
+// starting it
+func fetchServerBuild() (*domain.BuildInfo, error) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(serverURL + "/api/v1/version")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	var build domain.BuildInfo
+	if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+func init() {
+	versionCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	rootCmd.Version = domain.CurrentBuild().String()
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultWatchInterval is the time between refreshes of --watch
+const defaultWatchInterval = 2 * time.Second
+
+// minWatchInterval keeps --watch from polling the server in a tight loop
+const minWatchInterval = 500 * time.Millisecond
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// watchOrRun prints once with render, exiting on an error, or with --watch
+// refreshes it in place every --interval until interrupted
+func watchOrRun(cmd *cobra.Command, render func(out io.Writer) error) {
+	watch, _ := cmd.Flags().GetBool("watch")
+	if !watch {
+		if err := render(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval < minWatchInterval {
+		fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runWatch(ctx, os.Stdout, watchTitle(cmd, interval), interval, render)
+}
+
+// runWatch redraws the output of render on out every interval until ctx is
+// done. Errors are shown in place of the output, so a server restart
+// doesn't end the watch.
+func runWatch(ctx context.Context, out io.Writer, title string, interval time.Duration, render func(out io.Writer) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		io.WriteString(out, watchFrame(title, time.Now(), render))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchFrame renders one screen of a watch: the title and time, then the
+// output of render or its error. The frame is built before the screen is
+// cleared so that it doesn't flicker while the data is fetched.
+func watchFrame(title string, now time.Time, render func(out io.Writer) error) string {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		buf.Reset()
+		fmt.Fprintf(&buf, "Error: %v\n", err)
+	}
+	return fmt.Sprintf("%s%s    %s\n\n%s", clearScreen, title, now.Format("2006-01-02 15:04:05"), buf.String())
+}
+
+// watchTitle describes a watch like watch(1): the interval and the command
+// with the flags that were set, the watch flags left out
+func watchTitle(cmd *cobra.Command, interval time.Duration) string {
+	parts := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "watch" || f.Name == "interval" {
+			return
+		}
+		if f.Value.Type() == "bool" {
+			parts = append(parts, "--"+f.Name)
+		} else {
+			parts = append(parts, fmt.Sprintf("--%s=%s", f.Name, f.Value))
+		}
+	})
+	return fmt.Sprintf("Every %s: %s (Ctrl-C to quit)", interval, strings.Join(parts, " "))
+}
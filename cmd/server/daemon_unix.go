@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+// daemonSysProcAttr detaches the forked server from the terminal
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setsid: true, // Create new session
+	}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+// detachedProcess is DETACHED_PROCESS: the process gets no console
+const detachedProcess = 0x00000008
+
+// daemonSysProcAttr detaches the forked server from the console, so closing
+// the console window does not stop it
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+}
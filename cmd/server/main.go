@@ -24,10 +24,16 @@ import (
 
 var serverMode = flag.Bool("server-mode", false, "Internal flag: run in server mode (called by daemon)")
 var noExit = flag.Bool("no-exit", false, "Disable auto-exit when queue is empty (for LaunchAgent / always-on service use)")
+var showVersion = flag.Bool("version", false, "Print the build version and exit")
 
 func main() {
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(domain.CurrentBuild())
+		return
+	}
+
 	// If not in server mode, run as daemon
 	if !*serverMode {
 		startAsDaemon()
@@ -40,6 +46,14 @@ func main() {
 
 // startAsDaemon forks the current process and runs the server in background
 func startAsDaemon() {
+	// Report a running server here; the forked server's output is discarded
+	if config, err := app.LoadConfig(); err == nil {
+		if pid := infrastructure.RunningPID(config.Download.PIDFilePath()); pid != 0 {
+			fmt.Fprintln(os.Stderr, &infrastructure.AlreadyRunningError{PID: pid, Path: config.Download.PIDFilePath()})
+			os.Exit(1)
+		}
+	}
+
 	// Get the executable path
 	execPath, err := os.Executable()
 	if err != nil {
@@ -59,14 +73,12 @@ func startAsDaemon() {
 	cmd.Env = os.Environ()
 
 	// Detach from parent process
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true, // Create new session
-	}
+	cmd.SysProcAttr = daemonSysProcAttr()
 
-	// Redirect output to /dev/null
+	// Redirect output to the null device
 	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to open /dev/null: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", os.DevNull, err)
 		os.Exit(1)
 	}
 	cmd.Stdin = devNull
@@ -91,6 +103,15 @@ func runServer() {
 		os.Exit(1)
 	}
 
+	// One server per base_dir: a second one would share the database and
+	// the download directories
+	pidFile, err := infrastructure.AcquirePIDFile(config.Download.PIDFilePath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start: %v\n", err)
+		os.Exit(1)
+	}
+	defer pidFile.Release()
+
 	// Create logs directory
 	if err := os.MkdirAll(config.Download.LogsDir(), 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create logs directory: %v\n", err)
@@ -116,18 +137,29 @@ func runServer() {
 		config.Queue.AutoExitOnEmpty = false
 	}
 
+	build := domain.CurrentBuild()
 	log.Info("Starting X-Extract server",
-		zap.String("version", "1.0.0"),
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_date", build.BuildDate),
+		zap.String("go_version", build.GoVersion),
 		zap.String("host", config.Server.Host),
 		zap.Int("port", config.Server.Port),
 		zap.Bool("auto_exit_on_empty", config.Queue.AutoExitOnEmpty),
+		zap.String("twitter_backend", config.Twitter.Backend),
 		zap.Bool("telegram_takeout", config.Telegram.Takeout),
-		zap.String("telegram_profile", config.Telegram.Profile))
+		zap.String("telegram_profile", config.Telegram.Profile),
+		zap.String("storage", config.Storage.Backend))
 
 	// Create directories
 	if err := createDirectories(config); err != nil {
 		log.Fatal("Failed to create directories", zap.Error(err))
 	}
+	if !infrastructure.SameFileSystem(config.Download.IncomingDir(), config.Download.CompletedDir()) {
+		log.Info("Incoming and completed directories are on different disks, finished files will be copied across",
+			zap.String("incoming_dir", config.Download.IncomingDir()),
+			zap.String("completed_dir", config.Download.CompletedDir()))
+	}
 
 	// Migrate old directory structure if needed
 	if err := app.MigrateOldStructure(config); err != nil {
@@ -166,6 +198,12 @@ func runServer() {
 	telegramDownloader.SetChannelRepository(repo)
 	// Set message cache repository for caching message metadata
 	telegramDownloader.SetMessageCacheRepository(repo)
+	// Set channel aliases: channels merged into a successor share its organize_by folder
+	telegramDownloader.SetChannelAliasRepository(repo)
+	telegramDownloader.SetExtraMetadataFields(config.Metadata.ExtraFields)
+	telegramDownloader.SetWriteDescriptionFile(config.Metadata.WriteDescriptionFile)
+	telegramDownloader.SetFilenameTemplate(config.Download.FilenameTemplateFor(domain.PlatformTelegram))
+	telegramDownloader.SetOrganizeBy(config.Download.OrganizeBy)
 
 	galleryDownloader := infrastructure.NewGalleryDownloader(
 		&config.GalleryDL,
@@ -175,6 +213,8 @@ func runServer() {
 		logsDir,
 		multiLog,
 	)
+	galleryDownloader.SetExtraMetadataFields(config.Metadata.ExtraFields)
+	galleryDownloader.SetWriteDescriptionFile(config.Metadata.WriteDescriptionFile)
 
 	twitterDownloader := infrastructure.NewTwitterDownloader(
 		&config.Twitter,
@@ -186,6 +226,13 @@ func runServer() {
 	// Photo-only tweets: yt-dlp errors with "No video could be found"; use
 	// gallery-dl as a fallback so image posts still get downloaded.
 	twitterDownloader.SetFallback(galleryDownloader)
+	twitterDownloader.SetExtraMetadataFields(config.Metadata.ExtraFields)
+	twitterDownloader.SetWriteDescriptionFile(config.Metadata.WriteDescriptionFile)
+	twitterDownloader.SetFilenameTemplate(config.Download.FilenameTemplateFor(domain.PlatformX))
+	twitterDownloader.SetOrganizeBy(config.Download.OrganizeBy)
+	if config.Twitter.NativeExtractor {
+		twitterDownloader.SetNativeExtractor(infrastructure.NewXAPIClient(config.Twitter.CookieFile, config.Twitter.NativeQueryID))
+	}
 
 	downloaders := map[domain.Platform]domain.Downloader{
 		domain.PlatformX:         twitterDownloader,
@@ -194,11 +241,88 @@ func runServer() {
 		domain.PlatformGallery:   galleryDownloader,
 	}
 
+	// Live broadcasts (X broadcasts, Telegram live streams) are recorded with
+	// yt-dlp from the start, whatever their platform
+	liveRecorder := infrastructure.NewLiveRecorder(
+		config.Twitter.YTDLPBinary,
+		config.Twitter.CookieFile,
+		config.Download.IncomingDir(),
+		config.Download.CompletedDir(),
+		logsDir,
+		multiLog,
+	)
+	liveRecorder.SetClientOptions(config.Twitter.Impersonate, config.Twitter.UserAgent)
+	liveRecorder.SetWrapper(config.Twitter.Wrapper)
+	liveRecorder.SetExtraMetadataFields(config.Metadata.ExtraFields)
+	liveRecorder.SetWriteDescriptionFile(config.Metadata.WriteDescriptionFile)
+	liveRecorder.SetFilenameTemplate(config.Download.FilenameTemplate)
+	liveRecorder.SetOrganizeBy(config.Download.OrganizeBy)
+
 	// Initialize download manager
 	downloadMgr := app.NewDownloadManager(repo, downloaders, notifier, &config.Download, log)
+	downloadMgr.SetDiskGuard(infrastructure.NewDiskGuard(config.Download.CompletedDir(),
+		config.Download.IncomingDir(), config.Download.BaseDir,
+		config.Download.MinFreeSpaceBytes(), config.Download.QuotaBytes()))
+	downloadMgr.SetLiveRecorder(liveRecorder)
+	// Fallback chains (download.fallback_chains): backends tried in order when
+	// one fails with an extractor error
+	backends := map[string]domain.Downloader{
+		domain.BackendYTDLP:     twitterDownloader.Backend(domain.BackendYTDLP),
+		domain.BackendXAPI:      twitterDownloader.Backend(domain.BackendXAPI),
+		domain.BackendGalleryDL: galleryDownloader,
+		domain.BackendTDL:       telegramDownloader,
+	}
+	fallbackChains := make(domain.FallbackChains)
+	for platform, names := range config.Download.FallbackChains {
+		for _, name := range names {
+			fallbackChains[domain.Platform(platform)] = append(fallbackChains[domain.Platform(platform)],
+				domain.Backend{Name: name, Downloader: backends[name]})
+		}
+	}
+	downloadMgr.SetFallbackChains(fallbackChains)
+	// Downloaders look up download.rate_limit as each download starts, so
+	// changes through the settings API apply without a restart
+	twitterDownloader.SetRateLimit(downloadMgr.RateLimitFor)
+	telegramDownloader.SetRateLimit(downloadMgr.RateLimitFor)
+	galleryDownloader.SetRateLimit(downloadMgr.RateLimitFor)
+	metrics := app.NewMetrics(repo)
+	downloadMgr.SetMetrics(metrics)
+	downloadMgr.SetCallbackSender(infrastructure.NewCallbackSender(log))
+	downloadMgr.SetProgressNotification(config.Notification.ProgressAfter)
+	filePermissions, err := infrastructure.NewFilePermissions(config.Download.CompletedDir(),
+		config.Download.FileMode, config.Download.DirMode, config.Download.FileOwner)
+	if err != nil {
+		log.Fatal("Invalid completed file permissions", zap.Error(err))
+	}
+	if filePermissions != nil {
+		downloadMgr.SetFilePermissions(filePermissions)
+	}
+	if postProcessor := infrastructure.NewPostProcessor(&config.PostProcess); postProcessor != nil {
+		downloadMgr.SetPostProcessor(postProcessor)
+	}
+	if enrichers := infrastructure.ConfiguredEnrichers(&config.Metadata, config.PostProcess.FFprobeBinary); len(enrichers) > 0 {
+		downloadMgr.SetMetadataEnrichment(infrastructure.NewMetadataEnrichment(enrichers, config.Metadata.EnricherTimeout))
+	}
+	storage, err := infrastructure.NewStorage(&config.Storage)
+	if err != nil {
+		log.Fatal("Invalid storage configuration", zap.Error(err))
+	}
+	// Completed files are uploaded through the file store, which fetches files
+	// whose local copy was removed back into base_dir/storage-cache on demand
+	fileStore := infrastructure.NewFileStore(storage, config.Download.CompletedDir(), config.Download.StorageCacheDir(), config.Storage.CacheMaxSizeBytes())
+	downloadMgr.SetStorage(fileStore, config.Storage.DeleteLocal)
+	downloadMgr.SetContentIndex(repo)
+
+	// Heavy jobs (maintenance.jobs) wait for maintenance.window: transcodes
+	// and uploads of downloads completed outside it are deferred until it opens
+	maintenanceWindow := app.NewMaintenanceWindow(&config.Maintenance, repo, downloadMgr, multiLog)
+	downloadMgr.SetMaintenanceWindow(maintenanceWindow)
 
 	// Initialize queue manager
 	queueMgr := app.NewQueueManager(repo, downloadMgr, &config.Queue, multiLog, config.Download.CompletedDir())
+	queueMgr.SetChannelAliases(repo)
+	queueMgr.SetXAccounts(&config.Twitter)
+	queueMgr.SetTelegramProfiles(&config.Telegram)
 
 	// Start queue manager
 	ctx, cancel := context.WithCancel(context.Background())
@@ -210,21 +334,151 @@ func runServer() {
 		}
 	}
 
+	// Start metadata backfill worker (repairs empty descriptions/uploaders)
+	if config.Metadata.BackfillEnabled {
+		backfiller := app.NewMetadataBackfiller(repo, repo, &config.Metadata, multiLog)
+		backfiller.SetResolver(downloadMgr)
+		go backfiller.Run(ctx)
+	}
+
+	// Start scheduler for recurring channel/account syncs
+	var scheduler *app.Scheduler
+	if config.Scheduler.Enabled {
+		syncers := map[domain.Platform]domain.SourceSyncer{
+			domain.PlatformTelegram: telegramDownloader,
+			domain.PlatformX:        galleryDownloader,
+		}
+		scheduler = app.NewScheduler(repo, queueMgr, syncers, &config.Scheduler, multiLog)
+		scheduler.SetMaintenanceWindow(maintenanceWindow)
+		// Keep the server alive while schedules are pending
+		queueMgr.AddAutoExitInhibitor(scheduler.HasEnabledSchedules)
+		go scheduler.Run(ctx)
+	}
+
+	// Start subscriptions: subscribed Telegram channels and X accounts are
+	// checked for new media posts every interval
+	var subscriber *app.Subscriber
+	if config.Subscriptions.Enabled {
+		sources := func(platform domain.Platform, profile string) domain.SourceSyncer {
+			switch platform {
+			case domain.PlatformTelegram:
+				return telegramDownloader.ForProfile(profile)
+			case domain.PlatformX:
+				return twitterDownloader
+			}
+			return nil
+		}
+		subscriber = app.NewSubscriber(repo, queueMgr, sources, &config.Telegram, &config.Subscriptions, multiLog)
+		// Keep the server alive while subscriptions are pending
+		queueMgr.AddAutoExitInhibitor(subscriber.HasEnabledSubscriptions)
+		go subscriber.Run(ctx)
+	}
+
+	// Start cookie health checks so expiring X cookies are noticed before
+	// downloads start failing
+	xCookieMonitor := app.NewCookieMonitor(domain.PlatformX, twitterDownloader, notifier,
+		config.Twitter.CookieCheckInterval, config.Twitter.CookieExpiryWarning, multiLog)
+	go xCookieMonitor.Run(ctx)
+	cookieMonitors := map[domain.Platform]*app.CookieMonitor{
+		domain.PlatformX: xCookieMonitor,
+	}
+
+	// Degraded platform gauges for alerting (expired cookies, missing tools,
+	// repeated failures), with the tools each platform's downloader runs
+	platformHealth := app.NewPlatformHealthChecker(map[domain.Platform][]string{
+		domain.PlatformX:         {config.Twitter.YTDLPBinary},
+		domain.PlatformTelegram:  {config.Telegram.TDLBinary},
+		domain.PlatformInstagram: {config.GalleryDL.GalleryDLBinary},
+		domain.PlatformGallery:   {config.GalleryDL.GalleryDLBinary},
+	}, cookieMonitors, metrics)
+	metrics.SetPlatformHealth(platformHealth)
+	selfTester := app.NewSelfTester(repo, downloadMgr, platformHealth, &config.SelfTest,
+		config.Download.IncomingDir(), config.Download.CompletedDir())
+
+	// Archive reports can always be written on request; the periodic report
+	// is written only when enabled
+	reportGenerator := app.NewReportGenerator(repo, notifier, &config.Report, config.Download.ReportsDir(), multiLog)
+	if config.Report.Enabled {
+		go reportGenerator.Run(ctx)
+	}
+
+	// The retention policy can always be applied on request; it runs on a
+	// schedule only when enabled
+	retentionMgr := app.NewRetentionManager(repo, &config.Retention, multiLog)
+	retentionMgr.SetStoredFiles(fileStore)
+	if config.Retention.Enabled {
+		go retentionMgr.Run(ctx)
+	}
+
+	// Runtime settings changed through the API are saved to the config file
+	settingsMgr := app.NewSettingsManager(config, queueMgr, downloadMgr, notifier)
+	settingsMgr.SetLogLevelSetter(multiLog)
+
+	// Auto-exit waits while the dashboard or another API client is connected
+	clientTracker := app.NewClientTracker()
+	queueMgr.SetClientTracker(clientTracker)
+
+	// Thumbnails of downloaded media are made on request and cached
+	var thumbnailer domain.Thumbnailer
+	if config.Thumbnails.Enabled {
+		thumbnailer = infrastructure.NewThumbnailer(config.Download.ThumbnailsDir(), config.Thumbnails.MaxSize, config.Thumbnails.FFmpegBinary)
+	}
+
+	// Maintenance job filling in empty Telegram descriptions on request
+	metadataRegenerator := app.NewMetadataRegenerator(repo, repo, config.Download.CompletedDir(), multiLog)
+
+	// The Telegram channel list can always be synced on request; it is
+	// refreshed on a schedule unless channel_sync_interval is 0
+	channelSyncer := app.NewChannelSyncer(telegramDownloader, repo, config.Telegram.ChannelSyncInterval, multiLog)
+	if config.Telegram.ChannelSyncInterval > 0 {
+		go channelSyncer.Run(ctx)
+	}
+
+	if maintenanceWindow.Enabled() {
+		go maintenanceWindow.Run(ctx)
+	}
+
+	archiver := app.NewArchiver(repo, config.Download.CompletedDir(), multiLog)
+	archiver.SetStoredFiles(fileStore)
+
 	// Setup HTTP router
-	router := api.SetupRouterWithMultiLogger(queueMgr, downloadMgr, logAdapter, config.Download.LogsDir())
+	router := api.SetupRouterWithMultiLogger(queueMgr, downloadMgr, logAdapter, config.Download.LogsDir(), repo, repo, scheduler, subscriber, cookieMonitors, reportGenerator, retentionMgr, settingsMgr, clientTracker, metrics, platformHealth, thumbnailer, fileStore, metadataRegenerator, maintenanceWindow, channelSyncer, repo, archiver, selfTester, &config.Server)
 
 	// Create HTTP server
 	addr := fmt.Sprintf("%s:%d", config.Server.Host, config.Server.Port)
+	if !config.Server.Loopback() && !config.Server.AuthEnabled() {
+		log.Warn("HTTP server is reachable from other hosts without an API token; set server.auth_token", zap.String("host", config.Server.Host))
+	}
 	server := &http.Server{
 		Addr:    addr,
 		Handler: router,
 	}
 
+	// HTTPS with the configured certificate, or a self-signed one generated
+	// (and renewed) in base_dir/config/tls
+	certFile, keyFile := config.Server.TLS.CertFile, config.Server.TLS.KeyFile
+	if config.Server.TLS.Enabled && config.Server.TLS.SelfSigned {
+		hosts := config.Server.SelfSignedHosts()
+		if hostname, err := os.Hostname(); err == nil {
+			hosts = append(hosts, hostname)
+		}
+		certFile, keyFile, err = infrastructure.EnsureSelfSignedCert(config.Download.TLSDir(), hosts)
+		if err != nil {
+			log.Fatal("Failed to create self-signed certificate", zap.Error(err))
+		}
+	}
+
 	// Start server in goroutine
 	go func() {
-		log.Info("HTTP server listening", zap.String("addr", addr))
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("Failed to start server", zap.Error(err))
+		log.Info("HTTP server listening", zap.String("addr", addr), zap.String("scheme", config.Server.Scheme()))
+		var serveErr error
+		if config.Server.TLS.Enabled {
+			serveErr = server.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			serveErr = server.ListenAndServe()
+		}
+		if serveErr != nil && serveErr != http.ErrServerClosed {
+			log.Fatal("Failed to start server", zap.Error(serveErr))
 		}
 	}()
 
@@ -232,6 +486,22 @@ func runServer() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// SIGHUP reloads the config files, as POST /api/v1/config/reload does
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			result, err := settingsMgr.Reload()
+			if err != nil {
+				log.Error("Config reload rejected, keeping the running config", zap.Error(err))
+				continue
+			}
+			log.Info("Config reloaded",
+				zap.Strings("applied", result.Applied),
+				zap.Strings("restart_required", result.RestartRequired))
+		}
+	}()
+
 	select {
 	case <-quit:
 		log.Info("Received shutdown signal")
@@ -239,13 +509,23 @@ func runServer() {
 		log.Info("Queue manager triggered auto-exit (all downloads complete)")
 	}
 
-	log.Info("Shutting down server...")
+	log.Info("Shutting down server...",
+		zap.Duration("drain_timeout", config.Queue.DrainTimeout))
 
 	// Graceful shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
-	// Stop queue manager
+	// A second signal stops running downloads without waiting for them;
+	// they are queued again like downloads outlasting the drain timeout
+	go func() {
+		<-quit
+		log.Warn("Received second shutdown signal, stopping running downloads")
+		cancel()
+	}()
+
+	// Stop queue manager: wait up to queue.drain_timeout for running
+	// downloads, then stop their tools and queue them again
 	if err := queueMgr.Stop(); err != nil {
 		log.Error("Error stopping queue manager", zap.Error(err))
 	}
@@ -270,6 +550,9 @@ func createDirectories(config *domain.Config) error {
 		filepath.Join(config.Download.CookiesDir(), "x.com"),
 		config.Telegram.StoragePath, // cookies/telegram (storage_path is a directory; profile is a file inside it)
 	}
+	for _, path := range config.Telegram.Profiles {
+		dirs = append(dirs, path) // Empty paths share storage_path
+	}
 
 	for _, dir := range dirs {
 		// Skip empty paths (may be optional paths not configured)
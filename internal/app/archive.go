@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// archiveRepository is the persistence used by Archiver
+type archiveRepository interface {
+	FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error)
+	Update(download *domain.Download) error
+}
+
+// storedFileRestorer fetches completed files whose local copy was removed
+// after upload back from a remote storage.backend (infrastructure.FileStore)
+type storedFileRestorer interface {
+	Restore(ctx context.Context, file string) error
+}
+
+// ArchivedDownload is a download archived (or, in a dry run, to be archived)
+type ArchivedDownload struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Title       string     `json:"title,omitempty"`
+	FileSize    int64      `json:"file_size"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Files       []string   `json:"files"` // Paths on the archive volume
+}
+
+// ArchiveResult summarizes an archive pass
+type ArchiveResult struct {
+	DryRun     bool               `json:"dry_run"`
+	Dest       string             `json:"dest"`
+	Archived   int                `json:"archived"`
+	MovedBytes int64              `json:"moved_bytes"`
+	Failed     int                `json:"failed"` // Downloads left in place after an error
+	Downloads  []ArchivedDownload `json:"downloads"`
+}
+
+// Archiver moves the files of completed downloads to another volume (an
+// external drive), keeping their path relative to completed/ and their
+// .info.json and .description.txt. The records keep status completed with
+// FilePath, items and metadata pointing at the new location, so the files
+// are still served and count as downloaded.
+type Archiver struct {
+	repo         archiveRepository
+	completedDir string
+	storedFiles  storedFileRestorer // Fetches uploaded files back (optional)
+	multiLogger  *logger.MultiLogger
+	mu           sync.Mutex // Serializes archive passes
+}
+
+// NewArchiver creates an archiver. multiLogger may be nil.
+func NewArchiver(repo archiveRepository, completedDir string, multiLogger *logger.MultiLogger) *Archiver {
+	return &Archiver{
+		repo:         repo,
+		completedDir: completedDir,
+		multiLogger:  multiLogger,
+	}
+}
+
+// SetStoredFiles sets where files whose local copy was removed after upload
+// (storage.delete_local) are fetched back from before they are moved
+func (a *Archiver) SetStoredFiles(storedFiles storedFileRestorer) {
+	a.storedFiles = storedFiles
+}
+
+// ValidateDest checks that dest is an existing directory outside the
+// completed directory
+func (a *Archiver) ValidateDest(dest string) error {
+	if !filepath.IsAbs(dest) {
+		return fmt.Errorf("archive destination must be an absolute path: %s", dest)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return fmt.Errorf("archive destination is not available: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("archive destination is not a directory: %s", dest)
+	}
+	if rel, err := filepath.Rel(a.completedDir, dest); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("archive destination must be outside the completed directory: %s", dest)
+	}
+	return nil
+}
+
+// Archive moves the downloads completed more than olderThan before now to
+// dest, oldest first. A dry run only lists them. A download whose files
+// cannot all be moved is counted as failed; the files already moved stay on
+// the archive volume and the record points at them.
+func (a *Archiver) Archive(dest string, olderThan time.Duration, now time.Time, dryRun bool) (*ArchiveResult, error) {
+	if err := a.ValidateDest(dest); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	downloads, err := a.repo.FindAll(map[string]interface{}{
+		"status": domain.StatusCompleted,
+	}, domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed downloads: %w", err)
+	}
+
+	result := &ArchiveResult{DryRun: dryRun, Dest: dest, Downloads: []ArchivedDownload{}}
+	for _, dl := range domain.SelectArchivable(downloads, now.Add(-olderThan)) {
+		archived, err := a.archive(dl, dest, dryRun)
+		if err != nil {
+			result.Failed++
+			if a.multiLogger != nil {
+				a.multiLogger.LogAppError("Failed to archive download",
+					zap.String("download_id", dl.ID), zap.Error(err))
+			}
+			continue
+		}
+		result.Archived++
+		result.MovedBytes += archived.FileSize
+		result.Downloads = append(result.Downloads, *archived)
+	}
+
+	if !dryRun && result.Archived > 0 && a.multiLogger != nil {
+		a.multiLogger.LogQueueEvent("archive_pass_complete",
+			zap.String("dest", dest),
+			zap.Int("archived", result.Archived),
+			zap.Int("failed", result.Failed),
+			zap.Int64("moved_bytes", result.MovedBytes))
+	}
+	return result, nil
+}
+
+// ArchiveDownload moves one completed download to dest
+func (a *Archiver) ArchiveDownload(dl *domain.Download, dest string, dryRun bool) (*ArchivedDownload, error) {
+	if dl.Status != domain.StatusCompleted {
+		return nil, fmt.Errorf("only completed downloads can be archived (status: %s)", dl.Status)
+	}
+	if dir := dl.ArchiveDir(); dir != "" {
+		return nil, fmt.Errorf("download is already archived to %s", dir)
+	}
+	if err := a.ValidateDest(dest); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.archive(dl, dest, dryRun)
+}
+
+// archive moves the download's files under dest and updates its record
+func (a *Archiver) archive(dl *domain.Download, dest string, dryRun bool) (*ArchivedDownload, error) {
+	archived := &ArchivedDownload{
+		ID:          dl.ID,
+		URL:         dl.URL,
+		Title:       dl.Title,
+		FileSize:    dl.FileSize,
+		CompletedAt: dl.CompletedAt,
+		Files:       []string{},
+	}
+
+	renamed := make(map[string]string)
+	var moveErr error
+	for _, file := range dl.Files() {
+		target := filepath.Join(dest, filepath.FromSlash(infrastructure.RelativeToCompleted(a.completedDir, file)))
+		if dryRun {
+			archived.Files = append(archived.Files, target)
+			continue
+		}
+		if a.storedFiles != nil && dl.RemoteURLOf(file) != "" {
+			if err := a.storedFiles.Restore(context.Background(), file); err != nil {
+				moveErr = fmt.Errorf("failed to fetch the uploaded copy of %s: %w", file, err)
+				break
+			}
+		}
+		moved, err := infrastructure.MoveWithSidecarsAcross(file, target, nil)
+		if moved != file {
+			renamed[file] = moved
+			archived.Files = append(archived.Files, moved)
+		}
+		if err != nil {
+			moveErr = err
+			break
+		}
+	}
+	if dryRun {
+		return archived, nil
+	}
+
+	// Record what was moved even when a later file failed
+	dl.RenameFiles(renamed)
+	if moveErr != nil {
+		if len(renamed) > 0 {
+			if err := a.repo.Update(dl); err != nil {
+				return nil, fmt.Errorf("%w (and failed to update download: %v)", moveErr, err)
+			}
+		}
+		return nil, moveErr
+	}
+	dl.MarkArchived(dest, time.Now())
+	if err := a.repo.Update(dl); err != nil {
+		return nil, fmt.Errorf("failed to update download: %w", err)
+	}
+
+	if a.multiLogger != nil {
+		a.multiLogger.LogQueueEvent("download_archived",
+			zap.String("download_id", dl.ID),
+			zap.String("dest", dest),
+			zap.Int("files", len(archived.Files)),
+			zap.Int64("file_size", dl.FileSize))
+	}
+	return archived, nil
+}
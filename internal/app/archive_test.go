@@ -0,0 +1,79 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestArchiver_Archive(t *testing.T) {
+	completedDir := t.TempDir()
+	dest := t.TempDir()
+	write := func(rel string) string {
+		path := filepath.Join(completedDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(rel), 0644))
+		return path
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldAt := now.AddDate(0, 0, -100)
+	newAt := now.AddDate(0, 0, -1)
+
+	oldFile := write("alice/old.mp4")
+	write("alice/old.info.json")
+	newFile := write("new.mp4")
+	old := &domain.Download{ID: "old", Status: domain.StatusCompleted, FilePath: oldFile, FileSize: 10, CompletedAt: &oldAt,
+		Metadata: `{"relative_path":"alice/old.mp4"}`,
+		Items:    []domain.DownloadItem{{Status: domain.StatusCompleted, FilePath: oldFile, FileSize: 10}}}
+	recent := &domain.Download{ID: "new", Status: domain.StatusCompleted, FilePath: newFile, FileSize: 20, CompletedAt: &newAt}
+	repo := &mockRetentionRepo{downloads: []*domain.Download{old, recent}}
+	archiver := NewArchiver(repo, completedDir, nil)
+
+	// Destinations must be existing directories outside completed/
+	_, err := archiver.Archive(filepath.Join(dest, "missing"), 30*24*time.Hour, now, true)
+	assert.Error(t, err)
+	_, err = archiver.Archive(filepath.Join(completedDir, "alice"), 30*24*time.Hour, now, true)
+	assert.Error(t, err)
+
+	// A dry run lists where files would go without moving them
+	archivedPath := filepath.Join(dest, "alice", "old.mp4")
+	result, err := archiver.Archive(dest, 30*24*time.Hour, now, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Archived)
+	assert.Equal(t, []string{archivedPath}, result.Downloads[0].Files)
+	assert.FileExists(t, oldFile)
+	assert.Empty(t, repo.updated)
+
+	result, err = archiver.Archive(dest, 30*24*time.Hour, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Archived)
+	assert.Equal(t, int64(10), result.MovedBytes)
+	assert.NoFileExists(t, oldFile)
+	assert.FileExists(t, archivedPath)
+	assert.FileExists(t, filepath.Join(dest, "alice", "old.info.json"))
+	assert.FileExists(t, newFile)
+
+	assert.Equal(t, []string{"old"}, repo.updated)
+	assert.Equal(t, archivedPath, old.FilePath)
+	assert.Equal(t, archivedPath, old.Items[0].FilePath)
+	assert.Equal(t, dest, old.ArchiveDir())
+	assert.Equal(t, domain.StatusCompleted, old.Status)
+
+	// Archived downloads are not archived again
+	result, err = archiver.Archive(dest, 30*24*time.Hour, now, false)
+	require.NoError(t, err)
+	assert.Zero(t, result.Archived)
+	_, err = archiver.ArchiveDownload(old, dest, false)
+	assert.Error(t, err)
+
+	// One download at a time, whatever its age
+	archived, err := archiver.ArchiveDownload(recent, dest, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dest, "new.mp4")}, archived.Files)
+	assert.Equal(t, filepath.Join(dest, "new.mp4"), recent.FilePath)
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// channelSource refreshes the stored Telegram channel list from tdl
+type channelSource interface {
+	SyncChannelList(ctx context.Context) (*domain.ChannelSyncResult, error)
+}
+
+// channelSyncRepository is the persistence used by ChannelSyncer
+type channelSyncRepository interface {
+	GetChannel(channelID string) (*domain.TelegramChannel, error)
+	ListChannels() ([]*domain.TelegramChannel, error)
+	ShouldUpdateChannelList(maxAge time.Duration) (bool, error)
+	FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error)
+	Update(download *domain.Download) error
+}
+
+// ChannelRerenderResult summarizes a re-render of a channel's downloads
+type ChannelRerenderResult struct {
+	ChannelID    string `json:"channel_id"`
+	Name         string `json:"name"` // Current channel name the downloads were re-rendered with
+	DryRun       bool   `json:"dry_run"`
+	Downloads    int    `json:"downloads"`     // Download records updated (or to be updated)
+	Sidecars     int    `json:"sidecars"`      // .info.json sidecars updated
+	FilesRenamed int    `json:"files_renamed"` // Files whose name contained the old channel name
+}
+
+// ChannelSyncer refreshes the stored Telegram channel list every interval,
+// on top of the refresh before a download when the list is older than 7
+// days, and re-renders the downloads of renamed channels on request.
+type ChannelSyncer struct {
+	source      channelSource
+	repo        channelSyncRepository
+	interval    time.Duration
+	multiLogger *logger.MultiLogger
+	mu          sync.Mutex // Serializes syncs and re-renders
+	lastResult  *domain.ChannelSyncResult
+}
+
+// NewChannelSyncer creates a channel syncer. multiLogger may be nil.
+func NewChannelSyncer(source channelSource, repo channelSyncRepository, interval time.Duration, multiLogger *logger.MultiLogger) *ChannelSyncer {
+	return &ChannelSyncer{
+		source:      source,
+		repo:        repo,
+		interval:    interval,
+		multiLogger: multiLogger,
+	}
+}
+
+// Run syncs the channel list whenever it is older than the interval, checking
+// at start and then every interval until ctx is cancelled
+func (s *ChannelSyncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if stale, err := s.repo.ShouldUpdateChannelList(s.interval); err == nil && stale {
+			if _, err := s.Sync(ctx); err != nil && s.multiLogger != nil {
+				s.multiLogger.LogAppError("Telegram channel sync failed", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync refreshes the channel list now
+func (s *ChannelSyncer) Sync(ctx context.Context) (*domain.ChannelSyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, err := s.source.SyncChannelList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.lastResult = result
+	return result, nil
+}
+
+// LastResult returns the result of the last sync by this syncer, or nil
+func (s *ChannelSyncer) LastResult() *domain.ChannelSyncResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResult
+}
+
+// Channels returns the stored channels
+func (s *ChannelSyncer) Channels() ([]*domain.TelegramChannel, error) {
+	return s.repo.ListChannels()
+}
+
+// Rerender updates the completed downloads of a channel that were saved under
+// another name (or under its ID, before the name was known) to its current
+// name: the channel, uploader and title of their metadata and .info.json
+// sidecars, and file names containing the old name (with their sidecars).
+// A dry run only counts what would change. Returns nil for an unknown channel.
+func (s *ChannelSyncer) Rerender(channelID string, dryRun bool) (*ChannelRerenderResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel, err := s.repo.GetChannel(channelID)
+	if err != nil || channel == nil {
+		return nil, err
+	}
+	downloads, err := s.repo.FindAll(map[string]interface{}{
+		"platform": domain.PlatformTelegram,
+		"status":   domain.StatusCompleted,
+	}, domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed downloads: %w", err)
+	}
+
+	result := &ChannelRerenderResult{ChannelID: channelID, Name: channel.ChannelName, DryRun: dryRun}
+	for _, dl := range downloads {
+		if id, _ := TelegramIDsFromDownload(dl, dl.Files()); id != channelID {
+			continue
+		}
+		if err := s.rerenderDownload(dl, channel, dryRun, result); err != nil && s.multiLogger != nil {
+			s.multiLogger.LogAppError("Failed to re-render download",
+				zap.String("download_id", dl.ID), zap.Error(err))
+		}
+	}
+
+	if s.multiLogger != nil && !dryRun {
+		s.multiLogger.LogQueueEvent("telegram_channel_rerendered",
+			zap.String("channel_id", channelID),
+			zap.String("name", channel.ChannelName),
+			zap.Int("downloads", result.Downloads),
+			zap.Int("sidecars", result.Sidecars),
+			zap.Int("files_renamed", result.FilesRenamed))
+	}
+	return result, nil
+}
+
+// rerenderDownload re-renders one download with the channel's current name,
+// adding what it changed to result
+func (s *ChannelSyncer) rerenderDownload(dl *domain.Download, channel *domain.TelegramChannel, dryRun bool, result *ChannelRerenderResult) error {
+	var meta map[string]interface{}
+	if dl.Metadata == "" || json.Unmarshal([]byte(dl.Metadata), &meta) != nil {
+		return nil
+	}
+	oldName, _ := meta["channel"].(string)
+	if oldName == "" || !domain.RenameChannelInMetadata(meta, oldName, channel.ChannelName) {
+		return nil
+	}
+	result.Downloads++
+
+	// Names are only replaced in file names when the old one was a real name:
+	// Telegram files start with the channel ID
+	oldFilePart := infrastructure.SanitizeFilename(oldName)
+	newFilePart := infrastructure.SanitizeFilename(channel.ChannelName)
+	renameFiles := oldName != channel.ChannelID && oldFilePart != ""
+
+	renamed := make(map[string]string)
+	for _, file := range dl.Files() {
+		if sidecar := readSidecar(file); sidecar != nil && domain.RenameChannelInMetadata(sidecar, oldName, channel.ChannelName) {
+			result.Sidecars++
+			if !dryRun {
+				if data, err := json.MarshalIndent(sidecar, "", "  "); err == nil {
+					if err := infrastructure.WriteFileAtomic(sidecarPath(file), data, 0644); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		name := filepath.Base(file)
+		if !renameFiles || !strings.Contains(name, oldFilePart) {
+			continue
+		}
+		result.FilesRenamed++
+		if dryRun {
+			continue
+		}
+		dest := filepath.Join(filepath.Dir(file), strings.ReplaceAll(name, oldFilePart, newFilePart))
+		dest, err := infrastructure.RenameWithSidecars(file, dest)
+		if err != nil {
+			return err
+		}
+		renamed[file] = dest
+	}
+	if dryRun {
+		return nil
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	dl.Metadata = string(data)
+	dl.RenameFiles(renamed)
+	dl.SyncMetadataColumns()
+	return s.repo.Update(dl)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+// renamingChannelSource renames channel 100 to "Daily News" on sync
+type renamingChannelSource struct {
+	repo *infrastructure.SQLiteDownloadRepository
+}
+
+func (s *renamingChannelSource) SyncChannelList(ctx context.Context) (*domain.ChannelSyncResult, error) {
+	stored, err := s.repo.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+	fetched := map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "Daily News", ChannelType: "channel", Username: "-"},
+	}
+	result := domain.DiffChannelList(stored, fetched, time.Now())
+	return result, s.repo.UpdateChannelList(fetched)
+}
+
+func TestChannelSyncer_SyncAndRerender(t *testing.T) {
+	repo := newRegenerateTestRepo(t)
+	require.NoError(t, repo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "News", ChannelType: "channel", Username: "-"},
+	}))
+
+	completedDir := t.TempDir()
+	file := filepath.Join(completedDir, "100_42_News.mp4")
+	require.NoError(t, os.WriteFile(file, []byte("video"), 0644))
+	sidecar := map[string]interface{}{"id": "42", "channel": "News", "uploader": "News", "title": "News_News_42"}
+	data, _ := json.Marshal(sidecar)
+	require.NoError(t, os.WriteFile(filepath.Join(completedDir, "100_42_News.info.json"), data, 0644))
+
+	dl := domain.NewDownload("https://t.me/c/100/42", domain.PlatformTelegram, domain.ModeDefault)
+	dl.Metadata = `{"id":"42","channel":"News","uploader":"News_Alice","title":"Alice_News_42"}`
+	dl.SyncMetadataColumns()
+	completeDownload(dl, file)
+	require.NoError(t, repo.Create(dl))
+
+	// A download of another channel is left alone
+	other := domain.NewDownload("https://t.me/c/200/1", domain.PlatformTelegram, domain.ModeDefault)
+	other.Metadata = `{"id":"1","channel":"News","uploader":"News","title":"News_News_1"}`
+	completeDownload(other, filepath.Join(completedDir, "200_1_News.mp4"))
+	require.NoError(t, repo.Create(other))
+
+	syncer := NewChannelSyncer(&renamingChannelSource{repo: repo}, repo, time.Hour, nil)
+	result, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Renamed, 1)
+	assert.Equal(t, result, syncer.LastResult())
+
+	preview, err := syncer.Rerender("100", true)
+	require.NoError(t, err)
+	assert.Equal(t, &ChannelRerenderResult{ChannelID: "100", Name: "Daily News", DryRun: true,
+		Downloads: 1, Sidecars: 1, FilesRenamed: 1}, preview)
+	assert.FileExists(t, file, "a dry run changes nothing")
+
+	done, err := syncer.Rerender("100", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, done.Downloads)
+
+	renamed := filepath.Join(completedDir, "100_42_Daily News.mp4")
+	assert.FileExists(t, renamed)
+	data, err = os.ReadFile(filepath.Join(completedDir, "100_42_Daily News.info.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"title": "Daily News_Daily News_42"`)
+
+	updated, err := repo.FindByID(dl.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice_Daily News_42", updated.Title)
+	assert.Equal(t, "Daily News_Alice", updated.Uploader)
+	assert.Equal(t, []string{renamed}, updated.Files())
+
+	unchanged, err := repo.FindByID(other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other.Metadata, unchanged.Metadata)
+
+	// Nothing left to re-render; unknown channels return nil
+	again, err := syncer.Rerender("100", false)
+	require.NoError(t, err)
+	assert.Zero(t, again.Downloads)
+	missing, err := syncer.Rerender("999", false)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
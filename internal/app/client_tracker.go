@@ -0,0 +1,59 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// ClientTracker records dashboard and API clients of the server so auto-exit
+// can wait for them: open WebSocket sessions (the dashboard's live logs) and
+// the time of the latest API request.
+type ClientTracker struct {
+	mu       sync.Mutex
+	sessions int
+	lastSeen time.Time
+}
+
+// NewClientTracker creates a client tracker with no clients
+func NewClientTracker() *ClientTracker {
+	return &ClientTracker{}
+}
+
+// Touch records a client request at now
+func (t *ClientTracker) Touch(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.After(t.lastSeen) {
+		t.lastSeen = now
+	}
+}
+
+// SessionStarted records a newly opened WebSocket session
+func (t *ClientTracker) SessionStarted(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sessions++
+	t.lastSeen = now
+}
+
+// SessionEnded records a closed WebSocket session; the client counts as
+// active for another idle timeout
+func (t *ClientTracker) SessionEnded(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions > 0 {
+		t.sessions--
+	}
+	t.lastSeen = now
+}
+
+// Connected reports whether a WebSocket session is open or a client was seen
+// within idleTimeout before now
+func (t *ClientTracker) Connected(idleTimeout time.Duration, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions > 0 {
+		return true
+	}
+	return !t.lastSeen.IsZero() && now.Sub(t.lastSeen) < idleTimeout
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestClientTracker_Connected(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewClientTracker()
+	assert.False(t, tracker.Connected(time.Minute, now), "no client seen yet")
+
+	tracker.Touch(now)
+	assert.True(t, tracker.Connected(time.Minute, now.Add(30*time.Second)))
+	assert.False(t, tracker.Connected(time.Minute, now.Add(2*time.Minute)), "idle for longer than the timeout")
+
+	// An open session keeps the client connected however long it is idle
+	tracker.SessionStarted(now)
+	assert.True(t, tracker.Connected(time.Minute, now.Add(time.Hour)))
+	tracker.SessionEnded(now.Add(time.Hour))
+	assert.True(t, tracker.Connected(time.Minute, now.Add(time.Hour+30*time.Second)), "idle timeout starts on disconnect")
+	assert.False(t, tracker.Connected(time.Minute, now.Add(time.Hour+2*time.Minute)))
+}
+
+func TestQueueManager_ShouldAutoExitWaitsForClients(t *testing.T) {
+	t.Setenv("DOCKER_MODE", "")
+	config := domain.DefaultConfig()
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	emptySince := time.Now().Add(-time.Hour)
+	assert.True(t, qm.shouldAutoExit(emptySince), "no tracker")
+
+	tracker := NewClientTracker()
+	qm.SetClientTracker(tracker)
+	assert.True(t, qm.shouldAutoExit(emptySince), "no client seen")
+
+	tracker.Touch(time.Now())
+	assert.False(t, qm.shouldAutoExit(emptySince), "recent API request")
+
+	settings := qm.queueSettings()
+	settings.DeferExitForClients = false
+	qm.SetQueueSettings(settings)
+	assert.True(t, qm.shouldAutoExit(emptySince), "override disables the wait")
+}
+
+func TestQueueManager_KeepAliveSuppressesAutoExit(t *testing.T) {
+	t.Setenv("DOCKER_MODE", "")
+	config := domain.DefaultConfig()
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	emptySince := time.Now().Add(-time.Hour)
+	assert.True(t, qm.shouldAutoExit(emptySince))
+
+	status := qm.KeepAlive(0, false)
+	require.NotNil(t, status.KeepAliveUntil)
+	assert.WithinDuration(t, time.Now().Add(config.Queue.KeepAliveTTL), *status.KeepAliveUntil, time.Second)
+	assert.False(t, status.Held)
+	assert.False(t, qm.shouldAutoExit(emptySince), "default ttl")
+
+	// A shorter ttl does not cut the keepalive short
+	status = qm.KeepAlive(time.Second, false)
+	assert.WithinDuration(t, time.Now().Add(config.Queue.KeepAliveTTL), *status.KeepAliveUntil, time.Second)
+
+	status = qm.ReleaseKeepAlive()
+	assert.Nil(t, status.KeepAliveUntil)
+	assert.True(t, qm.shouldAutoExit(emptySince), "released")
+
+	qm.KeepAlive(time.Nanosecond, true)
+	time.Sleep(time.Millisecond)
+	assert.False(t, qm.shouldAutoExit(emptySince), "held past the ttl")
+	assert.True(t, qm.KeepAliveStatus().Held)
+
+	qm.ReleaseKeepAlive()
+	assert.True(t, qm.shouldAutoExit(emptySince))
+}
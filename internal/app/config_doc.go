@@ -0,0 +1,200 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// Where the effective value of a config key came from
+const (
+	ConfigSourceDefault = "default"       // Not set in any config file
+	ConfigSourceSystem  = "system config" // Set in the system config file
+	ConfigSourceUser    = "user override" // Set in base_dir/config/config.yaml
+	ConfigSourceEnv     = "environment"   // Set in a config file as $VAR, expanded from the environment
+)
+
+// ConfigOption describes one config key: its type, its built-in default and
+// its effective value after the config files are merged
+type ConfigOption struct {
+	Key     string `json:"key"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+	Runtime bool   `json:"runtime"` // Applied to a running server by a config reload, without a restart
+}
+
+// Changed reports whether the effective value differs from the default
+func (o ConfigOption) Changed() bool {
+	return o.Value != o.Default
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// DescribeConfig lists every key of domain.Config (by its mapstructure tag, in
+// declaration order) with config's value, as loaded by LoadConfig, and the
+// config file that set it
+func DescribeConfig(config *domain.Config) ([]ConfigOption, error) {
+	return describeConfig(config, domain.DefaultConfigPath(), UserConfigPath(config))
+}
+
+func describeConfig(config *domain.Config, systemPath, userPath string) ([]ConfigOption, error) {
+	system, err := readConfigKeys(systemPath)
+	if err != nil {
+		return nil, err
+	}
+	user, err := readConfigKeys(userPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []ConfigOption
+	collectConfigOptions(reflect.ValueOf(domain.DefaultConfig()).Elem(), reflect.ValueOf(config).Elem(), "", &options)
+	runtime := runtimeConfigKeys()
+	for i := range options {
+		options[i].Runtime = runtime[options[i].Key]
+		var file *viper.Viper
+		switch {
+		case user != nil && user.InConfig(options[i].Key):
+			file, options[i].Source = user, ConfigSourceUser
+		case system != nil && system.InConfig(options[i].Key):
+			file, options[i].Source = system, ConfigSourceSystem
+		default:
+			options[i].Source = ConfigSourceDefault
+		}
+		if file != nil {
+			if raw, ok := file.Get(options[i].Key).(string); ok && strings.Contains(raw, "$") {
+				options[i].Source = ConfigSourceEnv
+			}
+		}
+	}
+	return options, nil
+}
+
+// secretURLRe matches the password of URL credentials, e.g. in a proxy URL
+var secretURLRe = regexp.MustCompile(`(\w+://[^/@\s:]+:)[^@\s]+@`)
+
+// secretFlagRe matches the value of password, token and API key flags in
+// extra_params
+var secretFlagRe = regexp.MustCompile(`(?i)((?:^|\s)(?:-p|--password|--passwd|--token|--api-key|--api_key|--twofactor)(?:=|\s+))\S+`)
+
+// secretKeySuffixes end the config keys whose whole value is a secret
+var secretKeySuffixes = []string{"password", "secret_access_key", "auth_token", "auth_tokens"}
+
+// RedactConfigOptions returns options with secret keys, passwords in URLs and
+// the values of password and token flags replaced by ***
+func RedactConfigOptions(options []ConfigOption) []ConfigOption {
+	redacted := make([]ConfigOption, len(options))
+	for i, option := range options {
+		if isSecretConfigKey(option.Key) {
+			if option.Value != "" && option.Value != option.Default {
+				option.Value = "***"
+			}
+			redacted[i] = option
+			continue
+		}
+		option.Default = redactConfigValue(option.Default)
+		option.Value = redactConfigValue(option.Value)
+		redacted[i] = option
+	}
+	return redacted
+}
+
+func isSecretConfigKey(key string) bool {
+	for _, suffix := range secretKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactConfigValue(value string) string {
+	value = secretURLRe.ReplaceAllString(value, "${1}***@")
+	return secretFlagRe.ReplaceAllString(value, "${1}***")
+}
+
+// readConfigKeys reads a config file without defaults, so InConfig reports
+// the keys the file sets. A missing file returns nil.
+func readConfigKeys(path string) (*viper.Viper, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// collectConfigOptions appends an option per leaf field of the config
+// structs defaults and values, recursing into nested sections
+func collectConfigOptions(defaults, values reflect.Value, prefix string, options *[]ConfigOption) {
+	for i := 0; i < values.NumField(); i++ {
+		field := values.Type().Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		if field.Type.Kind() == reflect.Struct {
+			collectConfigOptions(defaults.Field(i), values.Field(i), key+".", options)
+			continue
+		}
+		*options = append(*options, ConfigOption{
+			Key:     key,
+			Type:    configTypeName(field.Type),
+			Default: formatConfigValue(defaults.Field(i)),
+			Value:   formatConfigValue(values.Field(i)),
+		})
+	}
+}
+
+// configTypeName names a config field type as written in YAML terms
+func configTypeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return "[]" + configTypeName(t.Elem())
+	case t.Kind() == reflect.Map:
+		return "map[" + configTypeName(t.Key()) + "]" + configTypeName(t.Elem())
+	default:
+		return t.Kind().String()
+	}
+}
+
+// formatConfigValue formats a config value as it would be written in YAML
+func formatConfigValue(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.String:
+		if v.String() == "" {
+			return `""`
+		}
+		return v.String()
+	case v.Kind() == reflect.Slice && v.Len() == 0:
+		return "[]"
+	case v.Kind() == reflect.Map && v.Len() == 0:
+		return "{}"
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Map:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// ConfigReload is the result of reloading the config files
+type ConfigReload struct {
+	Applied         []string `json:"applied"`          // Changed keys applied to the running server
+	RestartRequired []string `json:"restart_required"` // Changed keys that take effect on the next start
+}
+
+// Reload reads the config files again and applies what changed in the
+// runtime settings (queue, retries, concurrency, rate limits, notifications)
+// and logging.level to the running server. Other changed keys are reported as
+// needing a restart and keep their running values. When the files do not
+// load or validate nothing is applied.
+func (m *SettingsManager) Reload() (*ConfigReload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reload()
+}
+
+// reload is Reload for callers holding m.mu
+func (m *SettingsManager) reload() (*ConfigReload, error) {
+	fresh, err := m.loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload config: %w", err)
+	}
+
+	patch := domain.RuntimeSettingsFrom(fresh).ChangesFrom(domain.RuntimeSettingsFrom(m.config))
+	values, err := patch.ConfigValues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload config: %w", err)
+	}
+	applied := make(map[string]bool, len(values)+1)
+	for key := range values {
+		applied[key] = true
+	}
+	if fresh.Logging.Level != m.config.Logging.Level && m.logLevel != nil {
+		if err := m.logLevel.SetLevel(fresh.Logging.Level); err != nil {
+			return nil, fmt.Errorf("failed to reload config: %w", err)
+		}
+		m.config.Logging.Level = fresh.Logging.Level
+		applied["logging.level"] = true
+	}
+	m.apply(patch)
+
+	result := &ConfigReload{Applied: []string{}, RestartRequired: []string{}}
+	for _, key := range changedConfigKeys(m.config, fresh) {
+		if !applied[key] {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+	for key := range applied {
+		result.Applied = append(result.Applied, key)
+	}
+	sort.Strings(result.Applied)
+	return result, nil
+}
+
+// changedConfigKeys lists the keys whose values differ between two configs,
+// in declaration order
+func changedConfigKeys(from, to *domain.Config) []string {
+	var before, after []ConfigOption
+	defaults := reflect.ValueOf(domain.DefaultConfig()).Elem()
+	collectConfigOptions(defaults, reflect.ValueOf(from).Elem(), "", &before)
+	collectConfigOptions(defaults, reflect.ValueOf(to).Elem(), "", &after)
+
+	var keys []string
+	for i := range after {
+		if after[i].Value != before[i].Value {
+			keys = append(keys, after[i].Key)
+		}
+	}
+	return keys
+}
+
+// runtimeConfigKeys are the config keys Reload applies to a running server:
+// those of the runtime settings, and logging.level
+func runtimeConfigKeys() map[string]bool {
+	values, _ := domain.RuntimeSettingsFrom(domain.DefaultConfig()).Patch().ConfigValues()
+	keys := map[string]bool{"logging.level": true}
+	for key := range values {
+		keys[key] = true
+	}
+	return keys
+}
+
+// apiConfigKeys are the keys UpdateConfig (PUT /api/v1/config) may change
+// besides the runtime ones. Any web page the user opens can reach the API
+// when server.auth_token is not set, so keys that run commands (wrappers,
+// exec_enrichers, extra_params), point at binaries, files or directories,
+// send data elsewhere (storage, eagle, proxies), delete files (retention) or
+// change who can reach the server are left out: those are only changed in
+// the config file.
+var apiConfigKeys = map[string]bool{
+	"download.auto_start_workers":       true,
+	"download.concurrent_limit":         true,
+	"download.dedupe":                   true,
+	"download.disk_full_action":         true,
+	"download.fallback_chains":          true,
+	"download.large_concurrency":        true,
+	"download.large_size":               true,
+	"download.min_free_space":           true,
+	"download.organize_by":              true,
+	"download.quota":                    true,
+	"download.rate_limit_delay":         true,
+	"download.rate_limit_max_delay":     true,
+	"gallerydl.write_metadata":          true,
+	"logging.format":                    true,
+	"maintenance.jobs":                  true,
+	"maintenance.window":                true,
+	"metadata.backfill_batch_size":      true,
+	"metadata.backfill_enabled":         true,
+	"metadata.backfill_interval":        true,
+	"metadata.enricher_timeout":         true,
+	"metadata.enrichers":                true,
+	"metadata.extra_fields":             true,
+	"metadata.write_description_file":   true,
+	"notification.method":               true,
+	"notification.progress_after":       true,
+	"postprocess.platform_steps":        true,
+	"postprocess.steps":                 true,
+	"postprocess.timeout":               true,
+	"queue.client_idle_timeout":         true,
+	"queue.drain_timeout":               true,
+	"queue.keepalive_ttl":               true,
+	"report.enabled":                    true,
+	"report.format":                     true,
+	"report.notify":                     true,
+	"report.period":                     true,
+	"scheduler.check_interval":          true,
+	"scheduler.enabled":                 true,
+	"scheduler.max_items_per_run":       true,
+	"selftest.timeout":                  true,
+	"server.port":                       true,
+	"subscriptions.enabled":             true,
+	"subscriptions.interval":            true,
+	"subscriptions.interval_overrides":  true,
+	"subscriptions.max_items_per_check": true,
+	"telegram.channel_sync_interval":    true,
+	"telegram.rewrite_ext":              true,
+	"telegram.takeout":                  true,
+	"telegram.use_group":                true,
+	"thumbnails.enabled":                true,
+	"thumbnails.max_size":               true,
+	"twitter.account_cooldown":          true,
+	"twitter.backend":                   true,
+	"twitter.cookie_check_interval":     true,
+	"twitter.cookie_expiry_warning":     true,
+	"twitter.impersonate":               true,
+	"twitter.native_extractor":          true,
+	"twitter.user_agent":                true,
+	"twitter.write_metadata":            true,
+}
+
+// InvalidConfigError is returned by UpdateConfig for values that are
+// rejected; the config file is left as it was
+type InvalidConfigError struct {
+	Err error
+}
+
+func (e *InvalidConfigError) Error() string { return e.Err.Error() }
+func (e *InvalidConfigError) Unwrap() error { return e.Err }
+
+// UpdateConfig sets dotted config keys (e.g. "queue.check_interval") in the
+// user override (base_dir/config/config.yaml) and reloads the config, applying
+// what can be applied at once as Reload does. Only the runtime keys and
+// apiConfigKeys can be set. The values are checked against the config types
+// first, and the merged config must pass the validation of LoadConfig;
+// otherwise the file is restored and an *InvalidConfigError is returned.
+func (m *SettingsManager) UpdateConfig(values map[string]interface{}) (*ConfigReload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkConfigValues(values); err != nil {
+		return nil, &InvalidConfigError{Err: err}
+	}
+
+	path := UserConfigPath(m.config)
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	existed := err == nil
+	if err := UpdateConfigFile(path, values); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	result, err := m.reload()
+	if err != nil {
+		restoreErr := os.Remove(path)
+		if existed {
+			restoreErr = os.WriteFile(path, previous, 0644)
+		}
+		if restoreErr != nil {
+			return nil, fmt.Errorf("failed to restore %s after %v: %w", path, err, restoreErr)
+		}
+		return nil, &InvalidConfigError{Err: err}
+	}
+	return result, nil
+}
+
+// checkConfigValues checks that values only set known keys that the API may
+// change, to values that decode into the config types. Redacted values (as
+// returned by EffectiveConfig) are rejected so they don't overwrite the secret.
+func checkConfigValues(values map[string]interface{}) error {
+	var options []ConfigOption
+	defaults := reflect.ValueOf(domain.DefaultConfig()).Elem()
+	collectConfigOptions(defaults, defaults, "", &options)
+	known := make(map[string]bool, len(options))
+	for _, option := range options {
+		known[option.Key] = true
+	}
+	runtime := runtimeConfigKeys()
+
+	v := viper.New()
+	for key, value := range values {
+		if !known[key] {
+			return fmt.Errorf("unknown config key: %s", key)
+		}
+		if !runtime[key] && !apiConfigKeys[key] {
+			return fmt.Errorf("%s can only be changed in the config file", key)
+		}
+		if s, ok := value.(string); ok && strings.Contains(s, "***") {
+			return fmt.Errorf("%s: value is redacted; send the real value or leave the key out", key)
+		}
+		v.Set(key, value)
+	}
+	if err := v.Unmarshal(domain.DefaultConfig()); err != nil {
+		return fmt.Errorf("invalid config value: %w", err)
+	}
+	return nil
+}
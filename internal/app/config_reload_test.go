@@ -0,0 +1,210 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// mockLogLevel records log level changes
+type mockLogLevel struct {
+	level string
+}
+
+func (m *mockLogLevel) SetLevel(level string) error {
+	if level == "loud" {
+		return errors.New("invalid log level")
+	}
+	m.level = level
+	return nil
+}
+
+func newReloadTestManager(t *testing.T, fresh *domain.Config) (*SettingsManager, *QueueManager, *DownloadManager, *mockLogLevel) {
+	t.Helper()
+	config := domain.DefaultConfig()
+	downloaders := map[domain.Platform]domain.Downloader{domain.PlatformX: &blockingDownloader{}}
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), downloaders, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	m := NewSettingsManager(config, qm, dm, &mockSettingsNotifier{})
+	logLevel := &mockLogLevel{}
+	m.SetLogLevelSetter(logLevel)
+	m.loadConfig = func() (*domain.Config, error) { return fresh, nil }
+	return m, qm, dm, logLevel
+}
+
+func TestSettingsManager_ReloadAppliesRuntimeChanges(t *testing.T) {
+	fresh := domain.DefaultConfig()
+	fresh.Queue.CheckInterval = 7 * time.Second
+	fresh.Download.MaxRetries = 9
+	fresh.Download.RateLimit = "2M"
+	fresh.Logging.Level = "debug"
+	fresh.Download.BaseDir = "/elsewhere"
+	fresh.Server.Port = 9999
+	m, qm, dm, logLevel := newReloadTestManager(t, fresh)
+
+	result, err := m.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"download.max_retries", "download.rate_limit", "logging.level", "queue.check_interval"}, result.Applied)
+	assert.Equal(t, []string{"server.port", "download.base_dir"}, result.RestartRequired)
+
+	assert.Equal(t, 7*time.Second, qm.queueSettings().CheckInterval)
+	maxRetries, _ := dm.retryPolicy()
+	assert.Equal(t, 9, maxRetries)
+	assert.Equal(t, "debug", logLevel.level)
+	assert.Equal(t, "7s", m.Get().CheckInterval, "settings follow the reload")
+}
+
+func TestSettingsManager_ReloadWithoutChanges(t *testing.T) {
+	m, _, _, _ := newReloadTestManager(t, domain.DefaultConfig())
+
+	result, err := m.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, result.Applied)
+	assert.Empty(t, result.RestartRequired)
+}
+
+func TestSettingsManager_ReloadKeepsConfigOnError(t *testing.T) {
+	m, qm, _, logLevel := newReloadTestManager(t, nil)
+	m.loadConfig = func() (*domain.Config, error) { return nil, errors.New("invalid queue.check_interval") }
+
+	_, err := m.Reload()
+	assert.ErrorContains(t, err, "invalid queue.check_interval")
+
+	fresh := domain.DefaultConfig()
+	fresh.Queue.CheckInterval = 7 * time.Second
+	fresh.Logging.Level = "loud"
+	m.loadConfig = func() (*domain.Config, error) { return fresh, nil }
+	_, err = m.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, domain.DefaultConfig().Queue.CheckInterval, qm.queueSettings().CheckInterval, "nothing is applied")
+	assert.Empty(t, logLevel.level)
+}
+
+// newUpdateConfigTestManager returns a settings manager whose config is
+// loaded from the user override in a temp base_dir, as LoadConfig would
+func newUpdateConfigTestManager(t *testing.T) (*SettingsManager, *QueueManager, string) {
+	t.Helper()
+	baseDir := t.TempDir()
+	load := func() (*domain.Config, error) {
+		config := domain.DefaultConfig()
+		config.Download.BaseDir = baseDir
+		config.Queue.DatabasePath = filepath.Join(baseDir, "queue.db")
+		if _, err := os.Stat(UserConfigPath(config)); err == nil {
+			v := viper.New()
+			v.SetConfigFile(UserConfigPath(config))
+			if err := v.ReadInConfig(); err != nil {
+				return nil, err
+			}
+			if err := v.Unmarshal(config); err != nil {
+				return nil, err
+			}
+		}
+		if err := validateConfig(config); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+	config, err := load()
+	require.NoError(t, err)
+
+	downloaders := map[domain.Platform]domain.Downloader{domain.PlatformX: &blockingDownloader{}}
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), downloaders, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	m := NewSettingsManager(config, qm, dm, &mockSettingsNotifier{})
+	m.loadConfig = load
+	return m, qm, UserConfigPath(config)
+}
+
+func TestSettingsManager_UpdateConfig(t *testing.T) {
+	m, qm, path := newUpdateConfigTestManager(t)
+
+	result, err := m.UpdateConfig(map[string]interface{}{
+		"queue.check_interval": "9s",
+		"server.port":          9300,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queue.check_interval"}, result.Applied)
+	assert.Equal(t, []string{"server.port"}, result.RestartRequired)
+	assert.Equal(t, 9*time.Second, qm.queueSettings().CheckInterval)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "check_interval: 9s")
+	assert.Contains(t, string(data), "port: 9300")
+}
+
+func TestSettingsManager_UpdateConfigRejectsInvalidValues(t *testing.T) {
+	m, qm, path := newUpdateConfigTestManager(t)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("# mine\nqueue:\n  check_interval: 3s\n"), 0644))
+
+	for name, values := range map[string]map[string]interface{}{
+		"unknown key":      {"queue.nope": 1},
+		"section key":      {"queue": map[string]interface{}{"check_interval": "1s"}},
+		"wrong type":       {"server.port": "ninety"},
+		"bad duration":     {"queue.check_interval": "soon"},
+		"fails validation": {"server.port": 70000},
+		"redacted secret":  {"storage.s3.secret_access_key": "***"},
+		"runs a command":   {"twitter.wrapper": []interface{}{"sh", "-c", "touch /tmp/pwned"}},
+		"tool binary":      {"postprocess.ffmpeg_binary": "/tmp/ffmpeg"},
+		"network access":   {"server.host": "0.0.0.0"},
+	} {
+		_, err := m.UpdateConfig(values)
+		var invalid *InvalidConfigError
+		assert.ErrorAs(t, err, &invalid, name)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\nqueue:\n  check_interval: 3s\n", string(data), "the file is restored")
+	assert.Equal(t, domain.DefaultConfig().Queue.CheckInterval, qm.queueSettings().CheckInterval)
+}
+
+func TestSettingsManager_UpdateConfigRemovesCreatedFile(t *testing.T) {
+	m, _, path := newUpdateConfigTestManager(t)
+
+	_, err := m.UpdateConfig(map[string]interface{}{"server.port": 0})
+	assert.Error(t, err)
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestDescribeConfig_MarksRuntimeKeys(t *testing.T) {
+	options, err := describeConfig(domain.DefaultConfig(), "", "")
+	require.NoError(t, err)
+	runtime := map[string]bool{}
+	for _, option := range options {
+		runtime[option.Key] = option.Runtime
+	}
+	assert.True(t, runtime["queue.check_interval"])
+	assert.True(t, runtime["download.rate_limit_overrides"])
+	assert.True(t, runtime["logging.level"])
+	assert.False(t, runtime["server.port"])
+}
+
+func TestAPIConfigKeys_AreKnown(t *testing.T) {
+	var options []ConfigOption
+	defaults := reflect.ValueOf(domain.DefaultConfig()).Elem()
+	collectConfigOptions(defaults, defaults, "", &options)
+	known := make(map[string]bool, len(options))
+	for _, option := range options {
+		known[option.Key] = true
+	}
+	for key := range apiConfigKeys {
+		assert.True(t, known[key], key)
+		assert.NotContains(t, key, "binary", "binaries are only set in the config file")
+	}
+	for _, key := range []string{"twitter.wrapper", "telegram.wrapper", "metadata.exec_enrichers",
+		"gallerydl.extra_params", "telegram.extra_params", "download.bin_dir", "server.auth_token"} {
+		assert.Error(t, checkConfigValues(map[string]interface{}{key: nil}), key)
+	}
+}
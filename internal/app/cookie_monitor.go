@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// cookieNotifier is the notification used by CookieMonitor
+type cookieNotifier interface {
+	NotifyCookieProblem(platform domain.Platform, status domain.CookieStatus)
+}
+
+// CookieMonitor periodically checks a platform's cookie file and notifies
+// when the cookies are about to expire or stop working, so they can be
+// refreshed before downloads start failing. The latest result is served by
+// GET /api/v1/platforms/:platform/status and GET /api/v1/cookies.
+type CookieMonitor struct {
+	platform      domain.Platform
+	checker       domain.CookieChecker
+	notifier      cookieNotifier
+	interval      time.Duration
+	warnWithin    time.Duration
+	multiLogger   *logger.MultiLogger
+	mu            sync.Mutex
+	status        domain.CookieStatus
+	notifiedState domain.CookieState // State of the last notification, reset when healthy
+}
+
+// NewCookieMonitor creates a cookie monitor. notifier and multiLogger may be nil.
+func NewCookieMonitor(
+	platform domain.Platform,
+	checker domain.CookieChecker,
+	notifier cookieNotifier,
+	interval time.Duration,
+	warnWithin time.Duration,
+	multiLogger *logger.MultiLogger,
+) *CookieMonitor {
+	return &CookieMonitor{
+		platform:    platform,
+		checker:     checker,
+		notifier:    notifier,
+		interval:    interval,
+		warnWithin:  warnWithin,
+		multiLogger: multiLogger,
+		status:      domain.CookieStatus{Platform: platform, State: domain.CookieUnknown},
+	}
+}
+
+// Run checks the cookies immediately and then every interval until ctx is
+// cancelled. With a zero interval it returns at once and cookies are only
+// checked on request.
+func (m *CookieMonitor) Run(ctx context.Context) {
+	if m.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs a cookie check now, records the result and notifies if the
+// cookies newly need attention.
+func (m *CookieMonitor) Check(ctx context.Context) domain.CookieStatus {
+	status := m.checker.CheckCookies(ctx, m.warnWithin)
+	if ctx.Err() != nil {
+		// Interrupted by shutdown; keep the previous result
+		return m.Status()
+	}
+
+	m.mu.Lock()
+	m.status = status
+	notify := false
+	if status.NeedsAttention() {
+		notify = status.State != m.notifiedState
+		m.notifiedState = status.State
+	} else {
+		m.notifiedState = ""
+	}
+	m.mu.Unlock()
+
+	if m.multiLogger != nil {
+		m.multiLogger.LogQueueEvent("cookie_check",
+			zap.String("platform", string(m.platform)),
+			zap.String("state", string(status.State)),
+			zap.String("message", status.Message))
+	}
+	if notify && m.notifier != nil {
+		m.notifier.NotifyCookieProblem(m.platform, status)
+	}
+	return status
+}
+
+// Status returns the result of the most recent check
+func (m *CookieMonitor) Status() domain.CookieStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// Import replaces the platform's cookie file with data, if its checker is a
+// domain.CookieImporter and the cookies are valid, then checks the new
+// cookies. Rejected cookies return their status with a
+// *domain.InvalidCookieError and the previous cookie file stays in use.
+func (m *CookieMonitor) Import(ctx context.Context, data []byte) (domain.CookieStatus, error) {
+	importer, ok := m.checker.(domain.CookieImporter)
+	if !ok {
+		return domain.CookieStatus{}, fmt.Errorf("cookie import is not supported for %s", m.platform)
+	}
+	status, err := importer.ImportCookies(data, m.warnWithin)
+	if m.multiLogger != nil {
+		m.multiLogger.LogQueueEvent("cookie_import",
+			zap.String("platform", string(m.platform)),
+			zap.String("state", string(status.State)),
+			zap.Bool("imported", err == nil))
+	}
+	if err != nil {
+		return status, err
+	}
+	return m.Check(ctx), nil
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// mockCookieChecker returns a preset cookie state
+type mockCookieChecker struct {
+	state domain.CookieState
+}
+
+func (m *mockCookieChecker) CheckCookies(ctx context.Context, warnWithin time.Duration) domain.CookieStatus {
+	return domain.CookieStatus{Platform: domain.PlatformX, State: m.state, CheckedAt: time.Now()}
+}
+
+// mockCookieNotifier records cookie notifications
+type mockCookieNotifier struct {
+	notified []domain.CookieState
+}
+
+func (m *mockCookieNotifier) NotifyCookieProblem(platform domain.Platform, status domain.CookieStatus) {
+	m.notified = append(m.notified, status.State)
+}
+
+func TestCookieMonitor_Status(t *testing.T) {
+	checker := &mockCookieChecker{state: domain.CookieOK}
+	monitor := NewCookieMonitor(domain.PlatformX, checker, nil, time.Hour, 72*time.Hour, nil)
+
+	assert.Equal(t, domain.CookieUnknown, monitor.Status().State)
+	monitor.Check(context.Background())
+	assert.Equal(t, domain.CookieOK, monitor.Status().State)
+}
+
+func TestCookieMonitor_NotifiesOncePerProblem(t *testing.T) {
+	checker := &mockCookieChecker{state: domain.CookieOK}
+	notifier := &mockCookieNotifier{}
+	monitor := NewCookieMonitor(domain.PlatformX, checker, notifier, time.Hour, 72*time.Hour, nil)
+	ctx := context.Background()
+
+	monitor.Check(ctx)
+	assert.Empty(t, notifier.notified)
+
+	checker.state = domain.CookieExpiring
+	monitor.Check(ctx)
+	monitor.Check(ctx)
+	assert.Equal(t, []domain.CookieState{domain.CookieExpiring}, notifier.notified)
+
+	checker.state = domain.CookieExpired
+	monitor.Check(ctx)
+	assert.Equal(t, []domain.CookieState{domain.CookieExpiring, domain.CookieExpired}, notifier.notified)
+
+	// Refreshed cookies reset the notification so a later problem is reported again
+	checker.state = domain.CookieOK
+	monitor.Check(ctx)
+	checker.state = domain.CookieExpired
+	monitor.Check(ctx)
+	assert.Len(t, notifier.notified, 3)
+}
+
+// mockCookieImporter accepts cookies equal to valid and then reports them ok
+type mockCookieImporter struct {
+	mockCookieChecker
+	valid string
+}
+
+func (m *mockCookieImporter) ImportCookies(data []byte, warnWithin time.Duration) (domain.CookieStatus, error) {
+	if string(data) != m.valid {
+		status := domain.CookieStatus{Platform: domain.PlatformX, State: domain.CookieInvalid, Message: "no auth_token"}
+		return status, &domain.InvalidCookieError{Status: status}
+	}
+	m.state = domain.CookieOK
+	return domain.CookieStatus{Platform: domain.PlatformX, State: domain.CookieOK}, nil
+}
+
+func TestCookieMonitor_Import(t *testing.T) {
+	importer := &mockCookieImporter{mockCookieChecker: mockCookieChecker{state: domain.CookieExpired}, valid: "cookies"}
+	monitor := NewCookieMonitor(domain.PlatformX, importer, nil, time.Hour, 72*time.Hour, nil)
+	ctx := context.Background()
+	monitor.Check(ctx)
+
+	status, err := monitor.Import(ctx, []byte("garbage"))
+	var invalid *domain.InvalidCookieError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, domain.CookieInvalid, status.State)
+	assert.Equal(t, domain.CookieExpired, monitor.Status().State, "rejected cookies don't change the status")
+
+	status, err = monitor.Import(ctx, []byte("cookies"))
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CookieOK, status.State)
+	assert.Equal(t, domain.CookieOK, monitor.Status().State)
+
+	// Checkers that can't import are rejected
+	plain := NewCookieMonitor(domain.PlatformX, &mockCookieChecker{state: domain.CookieOK}, nil, time.Hour, 72*time.Hour, nil)
+	_, err = plain.Import(ctx, []byte("cookies"))
+	assert.Error(t, err)
+}
@@ -2,7 +2,13 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,11 +24,105 @@ type DownloadManager struct {
 	notifier           *infrastructure.NotificationService
 	config             *domain.DownloadConfig
 	logger             *zap.Logger
-	platformSemaphores map[domain.Platform]chan struct{} // Per-platform semaphores (limit=1 each)
-	activeCancels      sync.Map                         // downloadID -> context.CancelFunc for running downloads
+	platformSemaphores map[domain.Platform]chan struct{} // Per-platform semaphores (download.platform_concurrency each)
+	largeSlots         chan struct{}                     // Slots of large downloads (download.large_concurrency); nil without size classes
+	activeDownloads    sync.Map                          // downloadID -> *activeDownload for running downloads
+	pausedUntil        map[domain.Platform]time.Time     // Platforms paused after being rate limited
+	rateLimitStreak    map[domain.Platform]int           // Rate limits in a row per platform, reset by a completed download
+	liveRecorder       domain.Downloader                 // Records live broadcasts of any platform (optional)
+	fallbackChains     domain.FallbackChains             // Backends tried in order per platform (download.fallback_chains)
+	diskGuard          diskChecker                       // Checks for room before a download starts (optional)
+	diskCheckInterval  time.Duration                     // How often a held download rechecks for room
+	metrics            *Metrics                          // Records finished downloads and tool failures (optional)
+	callbacks          callbackSender                    // POSTs finished downloads to their callback URL (optional)
+	progressNotify     time.Duration                     // Interval of progress notifications for long downloads (0 = none)
+	permissions        filePermissions                   // Sets the mode and owner of completed files (optional)
+	postProcessor      postProcessor                     // Runs the post-processing steps on completed files (optional)
+	enrichment         metadataEnricher                  // Runs the metadata enrichers on completed files (optional)
+	storage            fileStorage                       // Uploads completed files to a remote storage.backend (optional)
+	deleteLocal        bool                              // Remove the local copy of each file once uploaded
+	contentIndex       contentIndex                      // Finds completed files by content for download.dedupe (optional)
+	maintenance        maintenanceGate                   // Holds transcodes and uploads outside maintenance.window (optional)
+	draining           context.Context                   // Cancelled by Drain: downloads that have not started stay queued
+	drain              context.CancelFunc                // Cancels draining
 	mu                 sync.RWMutex
 }
 
+// activeDownload tracks a running download so CancelDownload can stop its
+// subprocess and wait for the downloader to clean up.
+type activeDownload struct {
+	cancel context.CancelFunc
+	done   chan struct{} // closed when ProcessDownload returns
+}
+
+// diskChecker is the disk guard used by DownloadManager
+type diskChecker interface {
+	Check() error
+}
+
+// callbackSender delivers a finished download to its callback URL
+type callbackSender interface {
+	Send(download *domain.Download)
+}
+
+// filePermissions sets the mode and owner of completed files
+type filePermissions interface {
+	Apply(files []string) error
+}
+
+// postProcessor runs the post-processing steps on the files of a completed
+// download, returning the files it replaced (old path -> new path) and a
+// result per step applied
+type postProcessor interface {
+	Process(ctx context.Context, platform domain.Platform, files []string) (map[string]string, []domain.PostProcessResult)
+}
+
+// metadataEnricher runs the metadata enrichers on the files of a completed
+// download, recording their fields in its metadata, and returns the failures
+type metadataEnricher interface {
+	Enrich(ctx context.Context, download *domain.Download) []domain.EnrichmentError
+}
+
+// fileStorage keeps completed files (storage.backend). Store returns the URL a
+// file was uploaded to, or "" when it stays local only.
+type fileStorage interface {
+	Store(ctx context.Context, file, key string) (string, error)
+}
+
+// contentIndex finds completed files by the SHA-256 of their content
+type contentIndex interface {
+	FindItemsByContentHash(hash string) ([]domain.DownloadItem, error)
+	FindDuplicateItems() ([]domain.DownloadItem, error)
+}
+
+// sizeEstimateTimeout bounds the simulated run that estimates the size of a
+// download for download.large_size
+const sizeEstimateTimeout = 2 * time.Minute
+
+// metadataResolveTimeout bounds the run that fetches the metadata of a
+// completed download again for the metadata backfill
+const metadataResolveTimeout = 2 * time.Minute
+
+// diskCheckInterval is how often a download held for disk space rechecks
+const diskCheckInterval = time.Minute
+
+// progressPersistInterval throttles how often live progress is written to the
+// repository; progress bars can redraw many times per second.
+const progressPersistInterval = time.Second
+
+// maxRateLimitWait caps the pause of a rate-limited platform when
+// download.rate_limit_max_delay is unset, so a bogus Retry-After cannot park
+// it for days.
+const maxRateLimitWait = time.Hour
+
+// rateLimitJitter is the largest fraction added at random to a rate limit
+// pause, so downloads paused together do not all retry at the same instant
+const rateLimitJitter = 0.2
+
+// cancelWaitTimeout bounds how long CancelDownload waits for a killed
+// subprocess to exit and its downloader to remove temp files.
+const cancelWaitTimeout = infrastructure.SubprocessKillGrace + 5*time.Second
+
 // NewDownloadManager creates a new download manager
 func NewDownloadManager(
 	repo domain.DownloadRepository,
@@ -31,21 +131,218 @@ func NewDownloadManager(
 	config *domain.DownloadConfig,
 	logger *zap.Logger,
 ) *DownloadManager {
-	// Initialize per-platform semaphores with limit=1 for each platform
-	// This allows different platforms to download in parallel,
-	// while serializing downloads within the same platform
+	draining, drain := context.WithCancel(context.Background())
+	dm := &DownloadManager{
+		repo:              repo,
+		downloaders:       downloaders,
+		notifier:          notifier,
+		config:            config,
+		logger:            logger,
+		pausedUntil:       make(map[domain.Platform]time.Time),
+		rateLimitStreak:   make(map[domain.Platform]int),
+		diskCheckInterval: diskCheckInterval,
+		draining:          draining,
+		drain:             drain,
+	}
+	dm.SetPlatformConcurrency(config.PlatformConcurrency)
+	if config.LargeSizeBytes() > 0 {
+		dm.largeSlots = make(chan struct{}, max(config.LargeConcurrency, 1))
+	}
+	return dm
+}
+
+// SetPlatformConcurrency sets how many downloads of the same platform run at
+// once (at least 1). Different platforms always download in parallel. Running
+// downloads keep their slot in the old semaphores, so the new limit applies
+// fully once they finish.
+func (dm *DownloadManager) SetPlatformConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
 	platformSemaphores := make(map[domain.Platform]chan struct{})
-	for platform := range downloaders {
-		platformSemaphores[platform] = make(chan struct{}, 1)
+	for platform := range dm.downloaders {
+		platformSemaphores[platform] = make(chan struct{}, n)
 	}
 
-	return &DownloadManager{
-		repo:               repo,
-		downloaders:        downloaders,
-		notifier:           notifier,
-		config:             config,
-		logger:             logger,
-		platformSemaphores: platformSemaphores,
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.config.PlatformConcurrency = n
+	dm.platformSemaphores = platformSemaphores
+}
+
+// PlatformConcurrency returns how many downloads of the same platform run at once
+func (dm *DownloadManager) PlatformConcurrency() int {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.config.PlatformConcurrency
+}
+
+// SetRetryPolicy sets the retries after a failed attempt and the wait before each
+func (dm *DownloadManager) SetRetryPolicy(maxRetries int, retryDelay time.Duration) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.config.MaxRetries = maxRetries
+	dm.config.RetryDelay = retryDelay
+}
+
+// retryPolicy returns the current max retries and retry delay
+func (dm *DownloadManager) retryPolicy() (int, time.Duration) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.config.MaxRetries, dm.config.RetryDelay
+}
+
+// SetRateLimits sets download.rate_limit and download.rate_limit_overrides.
+// Running downloads keep the limit they started with.
+func (dm *DownloadManager) SetRateLimits(rateLimit string, overrides map[string]string) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.config.RateLimit = rateLimit
+	dm.config.RateLimitOverrides = overrides
+}
+
+// RateLimitFor returns the current rate limit of a platform in bytes per
+// second (0 = unlimited). Downloaders look it up as each download starts.
+func (dm *DownloadManager) RateLimitFor(platform domain.Platform) int64 {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.config.RateLimitFor(platform)
+}
+
+// SetLiveRecorder sets the downloader used for live broadcasts
+// (domain.IsLiveURL) instead of their platform's downloader
+func (dm *DownloadManager) SetLiveRecorder(recorder domain.Downloader) {
+	dm.liveRecorder = recorder
+}
+
+// SetFallbackChains sets the backends tried in order for each platform's
+// downloads instead of its downloader (download.fallback_chains): when one
+// fails with an extractor error (domain.IsExtractorError), the next is tried
+func (dm *DownloadManager) SetFallbackChains(chains domain.FallbackChains) {
+	dm.fallbackChains = chains
+}
+
+// SetDiskGuard sets the check for room (download.min_free_space and
+// download.quota) run before each download starts
+func (dm *DownloadManager) SetDiskGuard(guard diskChecker) {
+	dm.diskGuard = guard
+}
+
+// SetMetrics sets the collector of finished downloads and tool failures
+func (dm *DownloadManager) SetMetrics(metrics *Metrics) {
+	dm.metrics = metrics
+}
+
+// SetCallbackSender sets the sender of download callbacks (callback_url)
+func (dm *DownloadManager) SetCallbackSender(sender callbackSender) {
+	dm.callbacks = sender
+}
+
+// SetFilePermissions sets the mode and owner applied to completed files
+// (download.file_mode, dir_mode and file_owner)
+func (dm *DownloadManager) SetFilePermissions(permissions filePermissions) {
+	dm.permissions = permissions
+}
+
+// SetPostProcessor sets the post-processing steps run on completed files
+func (dm *DownloadManager) SetPostProcessor(processor postProcessor) {
+	dm.postProcessor = processor
+}
+
+// SetMetadataEnrichment sets the metadata enrichers run on completed files
+// after post-processing (metadata.enrichers and metadata.exec_enrichers)
+func (dm *DownloadManager) SetMetadataEnrichment(enrichment metadataEnricher) {
+	dm.enrichment = enrichment
+}
+
+// SetStorage sets where completed files are kept. With deleteLocal, the
+// local copy of a file is removed once it was uploaded.
+func (dm *DownloadManager) SetStorage(storage fileStorage, deleteLocal bool) {
+	dm.storage = storage
+	dm.deleteLocal = deleteLocal
+}
+
+// SetContentIndex sets where completed files are looked up by content. With
+// download.dedupe, files already downloaded under another URL are replaced
+// by a hard link to the earlier copy.
+func (dm *DownloadManager) SetContentIndex(index contentIndex) {
+	dm.contentIndex = index
+}
+
+// SetMaintenanceWindow sets the window transcodes and uploads of completed
+// files wait for (maintenance.window). Outside it they are recorded on the
+// download and run by RunDeferred once the window opens.
+func (dm *DownloadManager) SetMaintenanceWindow(gate maintenanceGate) {
+	dm.maintenance = gate
+}
+
+// SetProgressNotification sets notification.progress_after: downloads still
+// running after this long get a notification of their percent complete and
+// ETA, repeated at the same interval. 0 disables them.
+func (dm *DownloadManager) SetProgressNotification(after time.Duration) {
+	dm.progressNotify = after
+}
+
+// progressReminder schedules the progress notifications of a long download:
+// the first once it has run for every, then one each further every
+type progressReminder struct {
+	every time.Duration
+	next  time.Time
+}
+
+func newProgressReminder(startedAt time.Time, every time.Duration) *progressReminder {
+	return &progressReminder{every: every, next: startedAt.Add(every)}
+}
+
+// due reports whether a notification is due at now, scheduling the next one.
+// Intervals without progress updates are skipped rather than sent late.
+func (r *progressReminder) due(now time.Time) bool {
+	if r.every <= 0 || now.Before(r.next) {
+		return false
+	}
+	for !r.next.After(now) {
+		r.next = r.next.Add(r.every)
+	}
+	return true
+}
+
+// Drain stops downloads from starting, for a shutdown: downloads waiting for
+// a semaphore, a paused platform, disk space or a retry stop waiting and stay
+// queued for the next start. Running tools are not stopped; cancel the
+// context passed to ProcessDownload for that.
+func (dm *DownloadManager) Drain() {
+	dm.drain()
+}
+
+// waitEnded returns the result of ProcessDownload for err, the error of a
+// wait before the tool runs: nil when the wait was ended by Drain
+func (dm *DownloadManager) waitEnded(err error) error {
+	if errors.Is(err, context.Canceled) && dm.draining.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// interruptDownload queues a download again whose tool was stopped by a
+// shutdown, unless it was cancelled meanwhile
+func (dm *DownloadManager) interruptDownload(download *domain.Download) {
+	if aborted, _ := dm.isDownloadAborted(download.ID); aborted {
+		return
+	}
+	download.MarkInterrupted("server shut down")
+	if err := dm.repo.Update(download); err != nil {
+		dm.logger.Error("Failed to requeue interrupted download", zap.String("id", download.ID), zap.Error(err))
+		return
+	}
+	dm.logger.Info("Download interrupted by shutdown, queued to resume on next start", zap.String("id", download.ID))
+}
+
+// downloadFinished records a download that reached a terminal state in the
+// metrics and sends its callback
+func (dm *DownloadManager) downloadFinished(download *domain.Download) {
+	dm.metrics.DownloadFinished(download)
+	if dm.callbacks != nil {
+		dm.callbacks.Send(download)
 	}
 }
 
@@ -71,23 +368,38 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 		return nil
 	}
 
-	// Get platform-specific semaphore
-	// This allows different platforms to download in parallel,
-	// while serializing downloads within the same platform
-	dm.mu.RLock()
-	platformSem, ok := dm.platformSemaphores[download.Platform]
-	dm.mu.RUnlock()
+	// The waits before the tool runs also end when the server starts draining
+	waitCtx, stopWaiting := context.WithCancel(ctx)
+	defer stopWaiting()
+	defer context.AfterFunc(dm.draining, stopWaiting)()
 
-	if !ok {
-		return fmt.Errorf("no semaphore for platform: %s", download.Platform)
+	// Live broadcasts can run for hours, so recordings don't take the
+	// platform semaphore and never hold up regular downloads
+	live := download.IsLive()
+	if !live {
+		// Get platform-specific semaphore
+		// This allows different platforms to download in parallel,
+		// while limiting downloads within the same platform
+		dm.mu.RLock()
+		platformSem, ok := dm.platformSemaphores[download.Platform]
+		dm.mu.RUnlock()
+
+		if !ok {
+			return fmt.Errorf("no semaphore for platform: %s", download.Platform)
+		}
+
+		// Acquire platform-specific semaphore
+		select {
+		case platformSem <- struct{}{}:
+			defer func() { <-platformSem }()
+		case <-waitCtx.Done():
+			return dm.waitEnded(waitCtx.Err())
+		}
 	}
 
-	// Acquire platform-specific semaphore
-	select {
-	case platformSem <- struct{}{}:
-		defer func() { <-platformSem }()
-	case <-ctx.Done():
-		return ctx.Err()
+	// Hold off while the platform is paused after a rate limit
+	if err := dm.waitForPlatform(waitCtx, download.Platform); err != nil {
+		return dm.waitEnded(err)
 	}
 
 	// Check again after acquiring semaphore (may have been cancelled while waiting)
@@ -98,16 +410,37 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 		return nil
 	}
 
+	// Large downloads also wait for one of the few large slots
+	if !live {
+		release, err := dm.acquireSizeSlot(waitCtx, download)
+		if err != nil {
+			return dm.waitEnded(err)
+		}
+		defer release()
+	}
+
+	// Never start a download without room for it
+	if ok, err := dm.ensureDiskSpace(waitCtx, download); !ok {
+		return dm.waitEnded(err)
+	}
+
 	// Create a per-download cancellable context so CancelDownload can kill the subprocess.
 	dlCtx, dlCancel := context.WithCancel(ctx)
-	dm.activeCancels.Store(download.ID, dlCancel)
+	active := &activeDownload{cancel: dlCancel, done: make(chan struct{})}
+	dm.activeDownloads.Store(download.ID, active)
 	defer func() {
 		dlCancel()
-		dm.activeCancels.Delete(download.ID)
+		dm.activeDownloads.Delete(download.ID)
+		close(active.done)
 	}()
 
-	// Mark as processing now that we hold the semaphore and are about to run the tool.
-	download.MarkProcessing()
+	// Mark as processing (recording for live broadcasts) now that we hold the
+	// semaphore and are about to run the tool.
+	if live {
+		download.MarkRecording()
+	} else {
+		download.MarkProcessing()
+	}
 	if err := dm.repo.Update(download); err != nil {
 		dm.logger.Error("Failed to mark download as processing", zap.Error(err))
 	}
@@ -122,17 +455,53 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 
 	// Get appropriate downloader
 	downloader, ok := dm.downloaders[download.Platform]
+	chain := dm.fallbackChains[download.Platform]
+	if live {
+		chain = nil
+		downloader, ok = dm.liveRecorder, dm.liveRecorder != nil
+	}
 	if !ok {
 		err := fmt.Errorf("no downloader for platform: %s", download.Platform)
 		download.MarkFailed(err)
 		dm.repo.Update(download)
+		dm.downloadFinished(download)
 		dm.notifier.NotifyDownloadFailed(download.URL, download.Platform, err)
 		return err
 	}
 
+	// Persist live progress (percent, speed, ETA, current file) so API clients
+	// can show it without scraping logs. Long downloads also report it in a
+	// notification now and then; recordings have no percent to report.
+	var lastProgressSave time.Time
+	startedAt := time.Now()
+	reminder := newProgressReminder(startedAt, dm.progressNotify)
+	onProgress := func(progress domain.DownloadProgress) {
+		if progress.Percent < 0 {
+			return // failure is recorded by MarkFailed
+		}
+		download.ApplyProgress(progress)
+		if !live && progress.Percent < 100 && reminder.due(time.Now()) {
+			// Sent in the background so the tool's output keeps draining
+			go dm.notifier.NotifyDownloadProgress(download.URL, download.Platform,
+				download.Progress, download.ETA, time.Since(startedAt))
+		}
+		if progress.Percent < 100 && time.Since(lastProgressSave) < progressPersistInterval {
+			return
+		}
+		lastProgressSave = time.Now()
+		if err := dm.repo.UpdateProgress(download); err != nil {
+			dm.logger.Warn("Failed to save download progress", zap.String("id", download.ID), zap.Error(err))
+		}
+	}
+
 	// Attempt download with retries
 	var lastErr error
-	for attempt := 0; attempt <= dm.config.MaxRetries; attempt++ {
+	for attempt := 0; ; attempt++ {
+		maxRetries, retryDelay := dm.retryPolicy()
+		if attempt > maxRetries {
+			break
+		}
+
 		// Check for cancellation before each attempt
 		if aborted, err := dm.isDownloadAborted(download.ID); err != nil {
 			return err
@@ -145,13 +514,26 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 			dm.logger.Info("Retrying download",
 				zap.String("id", download.ID),
 				zap.Int("attempt", attempt),
-				zap.Int("max_retries", dm.config.MaxRetries))
+				zap.Int("max_retries", maxRetries))
 
-			// Wait before retry
+			// Wait before retry (longer if the platform is rate limited)
+			delay := retryDelay
+			if paused := dm.platformPauseRemaining(download.Platform); paused > delay {
+				delay = paused
+			}
 			select {
-			case <-time.After(dm.config.RetryDelay):
+			case <-time.After(delay):
 			case <-dlCtx.Done():
+				if ctx.Err() != nil {
+					dm.interruptDownload(download)
+					return nil
+				}
+				dm.cancelStoppedRecording(ctx, download)
 				return dlCtx.Err()
+			case <-dm.draining.Done():
+				// Don't start another attempt while the server shuts down
+				dm.interruptDownload(download)
+				return nil
 			}
 
 			download.IncrementRetry()
@@ -159,10 +541,42 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 		}
 
 		// Perform download — dlCtx cancellation kills the subprocess immediately.
-		err := downloader.Download(dlCtx, download, nil)
+		// The tools run for the attempt, post-processing included, are metered.
+		attemptStart := time.Now()
+		meter := &domain.UsageMeter{}
+		attemptCtx := domain.WithUsageMeter(dlCtx, meter)
+		var result *domain.DownloadResult
+		var backend string
+		var backendFailures []domain.BackendFailure
+		var err error
+		if len(chain) > 0 {
+			result, backend, backendFailures, err = dm.downloadWithFallback(attemptCtx, chain, download, onProgress)
+		} else {
+			result, err = downloader.Download(attemptCtx, download, onProgress)
+		}
 		if err == nil {
 			// Success
-			download.MarkCompleted(download.FilePath)
+			applyResult(download, result, attemptStart)
+			if backend != "" {
+				download.RecordBackend(backend, backendFailures)
+			}
+			completeDownload(download, download.FilePath)
+			dm.rateLimitRecovered(download.Platform)
+			dm.postProcess(attemptCtx, download)
+			dm.dedupeCompletedFiles(download)
+			dm.enrichMetadata(attemptCtx, download)
+			recordMediaDuration(download)
+			dm.applyFilePermissions(download)
+			// Flush the files to disk before the download is saved as completed
+			dm.syncCompletedFiles(download)
+			dm.storeCompletedFiles(dlCtx, download)
+			download.ApplyResourceUsage(meter.Usage())
+			// A cancel while the files were processed wins over the completion
+			if latest, err := dm.repo.FindByID(download.ID); err == nil && latest != nil && latest.Status == domain.StatusCancelled {
+				dm.logger.Info("Download cancelled while its files were processed, keeping it cancelled",
+					zap.String("id", download.ID))
+				return nil
+			}
 			if err := dm.repo.Update(download); err != nil {
 				dm.logger.Error("Failed to update download status", zap.Error(err))
 			}
@@ -170,24 +584,52 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 			dm.logger.Info("Download completed",
 				zap.String("id", download.ID),
 				zap.String("url", download.URL),
-				zap.String("file", download.FilePath))
+				zap.String("file", download.FilePath),
+				zap.Int("files", len(result.Files)),
+				zap.Int64("bytes", result.Bytes),
+				zap.Duration("duration", result.Duration),
+				zap.Int64("cpu_ms", download.ProcessCPUMs),
+				zap.Int64("peak_rss", download.ProcessPeakRSS))
 
+			dm.downloadFinished(download)
 			dm.notifier.NotifyDownloadCompleted(download.URL, download.Platform)
 			return nil
 		}
 
+		download.ApplyAttemptError(err)
+
 		// If the context was cancelled, the subprocess was killed intentionally —
 		// don't retry and don't overwrite the cancelled status in the DB.
 		if dlCtx.Err() != nil {
+			if ctx.Err() != nil {
+				// Stopped by a shutdown that did not wait for it to finish
+				dm.interruptDownload(download)
+				return nil
+			}
 			dm.logger.Info("Download subprocess killed by cancellation", zap.String("id", download.ID))
+			dm.cancelStoppedRecording(ctx, download)
 			return nil
 		}
 
 		lastErr = err
+		download.ApplyResourceUsage(meter.Usage())
 		dm.logger.Warn("Download attempt failed",
 			zap.String("id", download.ID),
 			zap.Int("attempt", attempt),
 			zap.Error(err))
+		dm.metrics.AttemptFailed(err)
+
+		var rateLimit *domain.RateLimitError
+		if errors.As(err, &rateLimit) {
+			dm.pausePlatform(download.Platform, rateLimit.RetryAfter)
+		}
+		// Don't burn attempts on failures another attempt cannot fix
+		if code := domain.ErrorCodeOf(err); !code.Retryable() {
+			dm.logger.Info("Download failure is not retryable",
+				zap.String("id", download.ID),
+				zap.String("error_code", string(code)))
+			break
+		}
 	}
 
 	// All retries exhausted — only mark failed if not already cancelled.
@@ -200,12 +642,561 @@ func (dm *DownloadManager) ProcessDownload(ctx context.Context, download *domain
 			zap.String("id", download.ID),
 			zap.String("url", download.URL),
 			zap.Error(lastErr))
+		dm.downloadFinished(download)
 		dm.notifier.NotifyDownloadFailed(download.URL, download.Platform, lastErr)
 	}
 	return lastErr
 }
 
-// CancelDownload cancels a download
+// downloadWithFallback downloads with the backends of a fallback chain in
+// order until one succeeds, moving on to the next when one fails with an
+// extractor error. It returns the result and name of the backend that
+// succeeded and the failures of those tried before it; when none succeeds,
+// the error of the last one tried.
+func (dm *DownloadManager) downloadWithFallback(ctx context.Context, chain []domain.Backend, download *domain.Download, onProgress domain.DownloadProgressCallback) (*domain.DownloadResult, string, []domain.BackendFailure, error) {
+	var failures []domain.BackendFailure
+	for i, backend := range chain {
+		result, err := backend.Downloader.Download(ctx, download, onProgress)
+		if err == nil {
+			return result, backend.Name, failures, nil
+		}
+		if ctx.Err() != nil || !domain.IsExtractorError(err) || i == len(chain)-1 {
+			return nil, "", nil, err
+		}
+		dm.logger.Warn("Download backend failed, trying the next one",
+			zap.String("id", download.ID),
+			zap.String("backend", backend.Name),
+			zap.String("next_backend", chain[i+1].Name),
+			zap.Error(err))
+		dm.metrics.AttemptFailed(err)
+		failures = append(failures, domain.BackendFailure{Backend: backend.Name, Error: err.Error()})
+	}
+	return nil, "", nil, fmt.Errorf("no download backends")
+}
+
+// applyResult records a downloader's result on the download, measuring the
+// size and duration of the download where the downloader did not
+func applyResult(download *domain.Download, result *domain.DownloadResult, started time.Time) {
+	if result.Bytes == 0 {
+		result.Bytes = infrastructure.TotalFileSize(result.Files)
+	}
+	if result.Duration == 0 {
+		result.Duration = time.Since(started)
+	}
+	download.ApplyResult(result)
+}
+
+// completeDownload marks a download completed with one item per file (unless
+// its downloader reported the items) and records the file sizes
+func completeDownload(download *domain.Download, filePath string) {
+	download.MarkCompleted(filePath)
+	download.EnsureItems()
+	recordFileSizes(download)
+}
+
+// recordFileSizes sets the size of each completed item and of the download
+func recordFileSizes(download *domain.Download) {
+	for i := range download.Items {
+		if download.Items[i].Status == domain.StatusCompleted {
+			download.Items[i].FileSize = infrastructure.TotalFileSize([]string{download.Items[i].FilePath})
+		}
+	}
+	download.FileSize = download.ItemsSize()
+}
+
+// recordMediaDuration sets the media duration of a completed download: the
+// "duration" in seconds yt-dlp (or the ffprobe enricher) wrote into the
+// .info.json sidecar of each file, summed, else the one of its metadata
+func recordMediaDuration(download *domain.Download) {
+	var total float64
+	seen := make(map[string]bool)
+	for _, file := range download.Files() {
+		sidecar := sidecarPath(file)
+		if seen[sidecar] {
+			continue
+		}
+		seen[sidecar] = true
+		if duration, ok := readSidecar(file)["duration"].(float64); ok && duration > 0 {
+			total += duration
+		}
+	}
+	if total == 0 && download.Metadata != "" {
+		var meta map[string]interface{}
+		if json.Unmarshal([]byte(download.Metadata), &meta) == nil {
+			if duration, ok := meta["duration"].(float64); ok && duration > 0 {
+				total = duration
+			}
+		}
+	}
+	download.MediaDuration = total
+}
+
+// postProcess runs the post-processing steps on the files of a completed
+// download and records the results in its metadata under "postprocess".
+// Failed steps are logged; the download stays completed.
+func (dm *DownloadManager) postProcess(ctx context.Context, download *domain.Download) {
+	if dm.postProcessor == nil || dm.deferJob(download, domain.MaintenanceTranscodes) {
+		return
+	}
+	renamed, results := dm.postProcessor.Process(ctx, download.Platform, download.Files())
+	if len(results) == 0 {
+		return
+	}
+	download.RenameFiles(renamed)
+	recordFileSizes(download)
+	download.SetMetadataField("postprocess", results)
+	for _, result := range results {
+		if result.Error != "" {
+			dm.logger.Warn("Post-processing step failed",
+				zap.String("id", download.ID),
+				zap.String("step", result.Step),
+				zap.String("file", result.File),
+				zap.String("error", result.Error))
+		}
+	}
+}
+
+// deferJob records job on the download when it waits for the maintenance
+// window, and reports whether it does
+func (dm *DownloadManager) deferJob(download *domain.Download, job string) bool {
+	if dm.maintenance == nil || !dm.maintenance.Holds(job) {
+		return false
+	}
+	download.DeferJob(job)
+	return true
+}
+
+// RunDeferred runs the transcodes and uploads of a completed download that
+// waited for the maintenance window, and saves the download. Jobs the window
+// holds again (it closed meanwhile) stay deferred.
+func (dm *DownloadManager) RunDeferred(ctx context.Context, download *domain.Download) error {
+	jobs := download.DeferredJobs()
+	transcode := download.HasDeferred(domain.MaintenanceTranscodes)
+	upload := download.HasDeferred(domain.MaintenanceRemoteSync)
+	download.Deferred = ""
+	if transcode {
+		dm.postProcess(ctx, download)
+		dm.applyFilePermissions(download)
+		dm.syncCompletedFiles(download)
+	}
+	if upload {
+		dm.storeCompletedFiles(ctx, download)
+	}
+	if err := dm.repo.Update(download); err != nil {
+		return fmt.Errorf("failed to save download %s: %w", download.ID, err)
+	}
+	dm.logger.Info("Deferred jobs ran",
+		zap.String("id", download.ID),
+		zap.Strings("jobs", jobs),
+		zap.String("deferred", download.Deferred))
+	return nil
+}
+
+// dedupeCompletedFiles hashes the files of a completed download
+// (download.dedupe) and replaces each file another download already has with
+// a hard link to that copy, recording the download on the item. Failures are
+// logged; the file is kept as it is.
+func (dm *DownloadManager) dedupeCompletedFiles(download *domain.Download) {
+	if !dm.config.Dedupe || dm.contentIndex == nil {
+		return
+	}
+	for i := range download.Items {
+		item := &download.Items[i]
+		if item.Status != domain.StatusCompleted || item.FilePath == "" {
+			continue
+		}
+		hash, err := infrastructure.HashFile(item.FilePath)
+		if err != nil {
+			dm.logger.Warn("Failed to hash completed file",
+				zap.String("id", download.ID), zap.String("file", item.FilePath), zap.Error(err))
+			continue
+		}
+		item.ContentHash = hash
+
+		original := dm.findOriginal(hash, download.ID)
+		if original == nil {
+			continue
+		}
+		if err := infrastructure.LinkDuplicate(original.FilePath, item.FilePath); err != nil {
+			dm.logger.Warn("Failed to link duplicate file",
+				zap.String("id", download.ID), zap.String("file", item.FilePath),
+				zap.String("original", original.FilePath), zap.Error(err))
+			continue
+		}
+		item.DuplicateOf = original.DownloadID
+		dm.logger.Info("Linked duplicate file",
+			zap.String("id", download.ID), zap.String("file", item.FilePath),
+			zap.String("duplicate_of", original.DownloadID), zap.String("original", original.FilePath))
+	}
+}
+
+// findOriginal returns a completed file of another download with the given
+// content that is still on disk, or nil
+func (dm *DownloadManager) findOriginal(hash, downloadID string) *domain.DownloadItem {
+	items, err := dm.contentIndex.FindItemsByContentHash(hash)
+	if err != nil {
+		dm.logger.Warn("Failed to look up files by content", zap.String("id", downloadID), zap.Error(err))
+		return nil
+	}
+	for i := range items {
+		if items[i].DownloadID == downloadID {
+			continue
+		}
+		if _, err := os.Stat(items[i].FilePath); err == nil {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// DedupeReport lists the completed files that share their content with
+// another (download.dedupe)
+func (dm *DownloadManager) DedupeReport() (*domain.DedupeReport, error) {
+	if dm.contentIndex == nil {
+		return nil, fmt.Errorf("content index not configured")
+	}
+	items, err := dm.contentIndex.FindDuplicateItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate files: %w", err)
+	}
+	return domain.NewDedupeReport(items), nil
+}
+
+// enrichMetadata runs the metadata enrichers on the files of a completed
+// download. Failed enrichers are logged; the download stays completed.
+func (dm *DownloadManager) enrichMetadata(ctx context.Context, download *domain.Download) {
+	if dm.enrichment == nil {
+		return
+	}
+	for _, failure := range dm.enrichment.Enrich(ctx, download) {
+		dm.logger.Warn("Metadata enricher failed",
+			zap.String("id", download.ID),
+			zap.String("enricher", failure.Enricher),
+			zap.String("file", failure.File),
+			zap.String("error", failure.Error))
+	}
+}
+
+// applyFilePermissions sets the configured mode and owner on the files of a
+// completed download and their sidecars, files dedupe linked to an earlier
+// download aside. Failures are logged; the download stays completed.
+func (dm *DownloadManager) applyFilePermissions(download *domain.Download) {
+	if dm.permissions == nil {
+		return
+	}
+	if err := dm.permissions.Apply(download.Files()); err != nil {
+		dm.logger.Warn("Failed to set permissions of completed files",
+			zap.String("id", download.ID), zap.Error(err))
+	}
+}
+
+// syncCompletedFiles flushes the files of a completed download and their
+// .info.json sidecars to disk, so a power loss right after the download is
+// saved as completed can't leave them truncated. Failures are logged.
+func (dm *DownloadManager) syncCompletedFiles(download *domain.Download) {
+	var paths []string
+	for _, file := range download.Files() {
+		paths = append(paths, file, sidecarPath(file))
+	}
+	if err := infrastructure.SyncFiles(paths); err != nil {
+		dm.logger.Warn("Failed to sync completed files", zap.String("id", download.ID), zap.Error(err))
+	}
+}
+
+// storeCompletedFiles hands the files of a completed download to the storage
+// backend and records the URL each was uploaded to on its item. A file that
+// failed to upload keeps its local copy; the error is logged and recorded in
+// the download's metadata under "storage_errors". The download stays completed.
+func (dm *DownloadManager) storeCompletedFiles(ctx context.Context, download *domain.Download) {
+	if dm.storage == nil {
+		return
+	}
+	// Files still to be transcoded are uploaded after their transcode
+	if download.HasDeferred(domain.MaintenanceTranscodes) {
+		download.DeferJob(domain.MaintenanceRemoteSync)
+		return
+	}
+	if dm.deferJob(download, domain.MaintenanceRemoteSync) {
+		return
+	}
+	completedDir := dm.config.CompletedDir()
+	var storageErrors []string
+	for i := range download.Items {
+		item := &download.Items[i]
+		if item.Status != domain.StatusCompleted || item.FilePath == "" {
+			continue
+		}
+		key, err := filepath.Rel(completedDir, item.FilePath)
+		if err != nil || strings.HasPrefix(key, "..") {
+			key = filepath.Base(item.FilePath)
+		}
+		remoteURL, err := dm.storage.Store(ctx, item.FilePath, filepath.ToSlash(key))
+		if err != nil {
+			dm.logger.Warn("Failed to upload completed file",
+				zap.String("id", download.ID), zap.String("file", item.FilePath), zap.Error(err))
+			storageErrors = append(storageErrors, fmt.Sprintf("%s: %v", filepath.Base(item.FilePath), err))
+			continue
+		}
+		if remoteURL == "" {
+			continue
+		}
+		item.RemoteURL = remoteURL
+		if item.FilePath == download.FilePath {
+			download.RemoteURL = remoteURL
+		}
+		if dm.deleteLocal {
+			if err := os.Remove(item.FilePath); err != nil {
+				dm.logger.Warn("Failed to remove uploaded file",
+					zap.String("id", download.ID), zap.String("file", item.FilePath), zap.Error(err))
+			}
+		}
+	}
+	if len(storageErrors) > 0 {
+		download.SetMetadataField("storage_errors", storageErrors)
+	}
+}
+
+// cancelStoppedRecording marks a recording cancelled when it was stopped
+// (CancelDownload) without anything to complete. Recordings interrupted by
+// shutdown (ctx done) stay recording and are requeued on the next start.
+func (dm *DownloadManager) cancelStoppedRecording(ctx context.Context, download *domain.Download) {
+	if !download.IsRecording() || ctx.Err() != nil {
+		return
+	}
+	download.MarkCancelled()
+	if err := dm.repo.Update(download); err != nil {
+		dm.logger.Error("Failed to update download status", zap.Error(err))
+	}
+	dm.downloadFinished(download)
+}
+
+// ensureDiskSpace runs the disk guard before a download starts and reports
+// whether it may start. Without room, the fail action marks the download
+// failed with the reason; the hold action keeps it queued, with the reason as
+// its error message, until there is room again or it is cancelled.
+func (dm *DownloadManager) ensureDiskSpace(ctx context.Context, download *domain.Download) (bool, error) {
+	if dm.diskGuard == nil {
+		return true, nil
+	}
+
+	held := false
+	for {
+		err := dm.diskGuard.Check()
+		if err == nil {
+			if held {
+				download.ErrorMessage = ""
+				dm.logger.Info("Disk space available, resuming download", zap.String("id", download.ID))
+			}
+			return true, nil
+		}
+
+		if dm.config.DiskFullAction == domain.DiskFullFail {
+			download.MarkFailed(err)
+			if updateErr := dm.repo.Update(download); updateErr != nil {
+				dm.logger.Error("Failed to update download status", zap.Error(updateErr))
+			}
+			dm.logger.Error("Download failed: no disk space", zap.String("id", download.ID), zap.Error(err))
+			dm.downloadFinished(download)
+			dm.notifier.NotifyDownloadFailed(download.URL, download.Platform, err)
+			return false, err
+		}
+
+		if !held {
+			held = true
+			download.ErrorMessage = "held: " + err.Error()
+			if updateErr := dm.repo.Update(download); updateErr != nil {
+				dm.logger.Error("Failed to update download status", zap.Error(updateErr))
+			}
+			dm.logger.Warn("Download held until there is disk space", zap.String("id", download.ID), zap.Error(err))
+			dm.notifier.NotifyDiskSpaceLow(err)
+		}
+
+		select {
+		case <-time.After(dm.diskCheckInterval):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		if aborted, err := dm.isDownloadAborted(download.ID); err != nil {
+			return false, err
+		} else if aborted {
+			dm.logger.Info("Download cancelled while held for disk space", zap.String("id", download.ID))
+			return false, nil
+		}
+	}
+}
+
+// acquireSizeSlot takes a large slot for a download estimated at
+// download.large_size or more, waiting while they are all taken, and returns
+// its release. Without size classes, or for smaller downloads and downloads
+// whose size cannot be estimated, it returns at once.
+func (dm *DownloadManager) acquireSizeSlot(ctx context.Context, download *domain.Download) (func(), error) {
+	noop := func() {}
+	if dm.largeSlots == nil {
+		return noop, nil
+	}
+	if download.EstimatedSize == 0 {
+		download.EstimatedSize = dm.estimateSize(ctx, download)
+	}
+	if download.EstimatedSize < dm.config.LargeSizeBytes() {
+		return noop, nil
+	}
+
+	select {
+	case dm.largeSlots <- struct{}{}:
+	default:
+		dm.logger.Info("Large download waiting for a large slot",
+			zap.String("id", download.ID),
+			zap.Int64("estimated_size", download.EstimatedSize))
+		select {
+		case dm.largeSlots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return func() { <-dm.largeSlots }, nil
+}
+
+// estimateSize returns the size a simulated run of the download's tool
+// reports, or 0 when its downloader cannot simulate or the run fails
+func (dm *DownloadManager) estimateSize(ctx context.Context, download *domain.Download) int64 {
+	simulator, ok := dm.downloaders[download.Platform].(domain.Simulator)
+	if !ok {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, sizeEstimateTimeout)
+	defer cancel()
+	simulation, err := simulator.Simulate(ctx, download)
+	if err == nil && simulation.Error != "" {
+		err = errors.New(simulation.Error)
+	}
+	if err != nil {
+		dm.logger.Warn("Failed to estimate download size",
+			zap.String("id", download.ID),
+			zap.Error(err))
+		return 0
+	}
+	return simulation.TotalSize()
+}
+
+// ResolveMetadata fetches the metadata of a completed download again from its
+// platform's tool, without downloading its media (metadata backfill)
+func (dm *DownloadManager) ResolveMetadata(ctx context.Context, download *domain.Download, files []string) (*domain.MediaMetadata, error) {
+	resolver, ok := dm.downloaders[download.Platform].(domain.MetadataResolver)
+	if !ok {
+		return nil, fmt.Errorf("metadata resolution is not supported for platform: %s", download.Platform)
+	}
+	ctx, cancel := context.WithTimeout(ctx, metadataResolveTimeout)
+	defer cancel()
+	return resolver.ResolveMetadata(ctx, download, files)
+}
+
+// pausePlatform stops new attempts on platform for retryAfter, or when the
+// server gave none for the configured rate limit delay doubled for each rate
+// limit in a row. Up to rateLimitJitter is added at random and the pause is
+// capped at download.rate_limit_max_delay.
+func (dm *DownloadManager) pausePlatform(platform domain.Platform, retryAfter time.Duration) {
+	dm.mu.Lock()
+	dm.rateLimitStreak[platform]++
+	streak := dm.rateLimitStreak[platform]
+	dm.mu.Unlock()
+
+	maxWait := dm.config.RateLimitMaxDelay
+	if maxWait <= 0 {
+		maxWait = maxRateLimitWait
+	}
+	wait := retryAfter
+	if wait <= 0 {
+		delay := dm.config.RateLimitDelay
+		if delay <= 0 {
+			_, delay = dm.retryPolicy()
+		}
+		wait = rateLimitBackoff(delay, streak, maxWait)
+	}
+	if wait > 0 {
+		wait += time.Duration(rand.Int63n(int64(float64(wait)*rateLimitJitter) + 1))
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+
+	until := time.Now().Add(wait)
+	dm.mu.Lock()
+	if until.After(dm.pausedUntil[platform]) {
+		dm.pausedUntil[platform] = until
+	}
+	dm.mu.Unlock()
+
+	dm.logger.Warn("Platform rate limited, pausing downloads",
+		zap.String("platform", string(platform)),
+		zap.Duration("retry_after", retryAfter),
+		zap.Int("streak", streak),
+		zap.Duration("pause", wait))
+}
+
+// rateLimitBackoff returns delay doubled for each rate limit in a row after
+// the first, capped at maxWait
+func rateLimitBackoff(delay time.Duration, streak int, maxWait time.Duration) time.Duration {
+	wait := delay
+	for i := 1; i < streak && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait
+}
+
+// rateLimitRecovered resets the backoff of platform after a completed download
+func (dm *DownloadManager) rateLimitRecovered(platform domain.Platform) {
+	dm.mu.Lock()
+	delete(dm.rateLimitStreak, platform)
+	dm.mu.Unlock()
+}
+
+// PausedPlatforms returns the platforms paused after a rate limit, with the
+// end of each pause. The queue does not dispatch their downloads meanwhile.
+func (dm *DownloadManager) PausedPlatforms() map[domain.Platform]time.Time {
+	now := time.Now()
+	paused := make(map[domain.Platform]time.Time)
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	for platform, until := range dm.pausedUntil {
+		if until.After(now) {
+			paused[platform] = until
+		}
+	}
+	return paused
+}
+
+// platformPauseRemaining returns how long platform stays paused (0 if not paused)
+func (dm *DownloadManager) platformPauseRemaining(platform domain.Platform) time.Duration {
+	dm.mu.RLock()
+	until := dm.pausedUntil[platform]
+	dm.mu.RUnlock()
+	if remaining := time.Until(until); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// waitForPlatform blocks until platform is no longer paused or ctx is done
+func (dm *DownloadManager) waitForPlatform(ctx context.Context, platform domain.Platform) error {
+	for {
+		remaining := dm.platformPauseRemaining(platform)
+		if remaining == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(remaining):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CancelDownload cancels a download. If it is running, the external tool is sent
+// SIGTERM (then SIGKILL after a grace period) and CancelDownload waits for the
+// downloader to exit and remove its temp files from the incoming directory.
 func (dm *DownloadManager) CancelDownload(id string) error {
 	download, err := dm.repo.FindByID(id)
 	if err != nil {
@@ -216,23 +1207,41 @@ func (dm *DownloadManager) CancelDownload(id string) error {
 		return fmt.Errorf("download already in terminal state: %s", download.Status)
 	}
 
-	download.Status = domain.StatusCancelled
-	download.UpdatedAt = time.Now()
+	// Cancelling a recording stops it but keeps what was recorded: the
+	// recorder finalizes the file and the worker marks the download completed.
+	if download.IsRecording() {
+		if value, ok := dm.activeDownloads.Load(id); ok {
+			value.(*activeDownload).cancel()
+			dm.logger.Info("Recording stopped", zap.String("id", id))
+			return nil
+		}
+	}
+
+	// Mark cancelled first so the worker does not retry or mark it failed
+	// once its subprocess is killed.
+	download.MarkCancelled()
 
 	if err := dm.repo.Update(download); err != nil {
 		return fmt.Errorf("failed to update download: %w", err)
 	}
+	dm.downloadFinished(download)
 
 	// Kill the subprocess if it is actively running.
-	if cancelFn, ok := dm.activeCancels.Load(id); ok {
-		cancelFn.(context.CancelFunc)()
+	if value, ok := dm.activeDownloads.Load(id); ok {
+		active := value.(*activeDownload)
+		active.cancel()
+		select {
+		case <-active.done:
+		case <-time.After(cancelWaitTimeout):
+			dm.logger.Warn("Timed out waiting for cancelled download to stop", zap.String("id", id))
+		}
 	}
 
 	dm.logger.Info("Download cancelled", zap.String("id", id))
 	return nil
 }
 
-// RetryDownload retries a failed or cancelled download
+// RetryDownload retries a failed, cancelled or expired download
 func (dm *DownloadManager) RetryDownload(ctx context.Context, id string) error {
 	download, err := dm.repo.FindByID(id)
 	if err != nil {
@@ -242,11 +1251,11 @@ func (dm *DownloadManager) RetryDownload(ctx context.Context, id string) error {
 		return fmt.Errorf("download not found: %s", id)
 	}
 
-	// Allow retry for failed or cancelled downloads
+	// Allow retry for failed, cancelled or expired downloads
 	if download.Status == domain.StatusQueued {
 		return fmt.Errorf("download is already queued: %s", download.Status)
 	}
-	if download.Status == domain.StatusProcessing {
+	if download.IsRunning() {
 		return fmt.Errorf("download is currently processing: %s", download.Status)
 	}
 	if download.Status == domain.StatusCompleted {
@@ -254,12 +1263,7 @@ func (dm *DownloadManager) RetryDownload(ctx context.Context, id string) error {
 	}
 
 	// Reset download state
-	download.Status = domain.StatusQueued
-	download.RetryCount = 0
-	download.ErrorMessage = ""
-	download.StartedAt = nil
-	download.CompletedAt = nil
-	download.UpdatedAt = time.Now()
+	download.MarkRequeued()
 
 	if err := dm.repo.Update(download); err != nil {
 		return fmt.Errorf("failed to update download: %w", err)
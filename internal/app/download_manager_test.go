@@ -1,16 +1,27 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"go.uber.org/zap"
 )
 
 // mockDownloadManagerRepo implements domain.DownloadRepository for testing
 type mockDownloadManagerRepo struct {
-	downloads map[string]*domain.Download
+	downloads       map[string]*domain.Download
+	progressUpdates []float64
 }
 
 func newMockDownloadManagerRepo() *mockDownloadManagerRepo {
@@ -27,6 +38,11 @@ func (m *mockDownloadManagerRepo) Update(download *domain.Download) error {
 	return nil
 }
 
+func (m *mockDownloadManagerRepo) UpdateProgress(download *domain.Download) error {
+	m.progressUpdates = append(m.progressUpdates, download.Progress)
+	return nil
+}
+
 func (m *mockDownloadManagerRepo) Delete(id string) error {
 	delete(m.downloads, id)
 	return nil
@@ -43,6 +59,10 @@ func (m *mockDownloadManagerRepo) FindByURL(url string, statuses []domain.Downlo
 	return nil, nil
 }
 
+func (m *mockDownloadManagerRepo) UpdateQueuedPriorities(priorities map[string]int) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockDownloadManagerRepo) FindByStatus(status domain.DownloadStatus) ([]*domain.Download, error) {
 	return nil, nil
 }
@@ -51,8 +71,25 @@ func (m *mockDownloadManagerRepo) FindPending() ([]*domain.Download, error) {
 	return nil, nil
 }
 
-func (m *mockDownloadManagerRepo) FindAll(filters map[string]interface{}) ([]*domain.Download, error) {
-	return nil, nil
+func (m *mockDownloadManagerRepo) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
+	var downloads []*domain.Download
+	for _, d := range m.downloads {
+		columns := map[string]string{"status": string(d.Status), "platform": string(d.Platform), "error_code": string(d.ErrorCode)}
+		matched := true
+		for column, value := range filters {
+			if columns[column] != fmt.Sprint(value) {
+				matched = false
+			}
+		}
+		if matched {
+			downloads = append(downloads, d)
+		}
+	}
+	return downloads, nil
+}
+
+func (m *mockDownloadManagerRepo) CountAll(filters map[string]interface{}, opts domain.ListOptions) (int64, error) {
+	return 0, nil
 }
 
 func (m *mockDownloadManagerRepo) Count() (int64, error) {
@@ -67,14 +104,18 @@ func (m *mockDownloadManagerRepo) CountActive() (int64, error) {
 	return 0, nil
 }
 
-func (m *mockDownloadManagerRepo) ResetOrphanedProcessing() (int64, error) {
-	return 0, nil
+func (m *mockDownloadManagerRepo) ResetOrphanedProcessing(maxRetries int) ([]*domain.Download, error) {
+	return nil, nil
 }
 
 func (m *mockDownloadManagerRepo) GetStats() (*domain.DownloadStats, error) {
 	return nil, nil
 }
 
+func (m *mockDownloadManagerRepo) GetStatsHistory(days int, now time.Time) (*domain.StatsHistory, error) {
+	return nil, nil
+}
+
 func TestRetryDownload_Failed(t *testing.T) {
 	repo := newMockDownloadManagerRepo()
 	dm := NewDownloadManager(repo, nil, nil, &domain.DownloadConfig{MaxRetries: 3}, nil)
@@ -169,3 +210,969 @@ func TestRetryDownload_NotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
+
+// blockingDownloader blocks until its context is cancelled, then simulates
+// temp-file cleanup before returning.
+type blockingDownloader struct {
+	started chan struct{}
+	cleaned bool
+}
+
+func (b *blockingDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	close(b.started)
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	b.cleaned = true
+	return nil, ctx.Err()
+}
+func (b *blockingDownloader) Platform() domain.Platform { return domain.PlatformTelegram }
+func (b *blockingDownloader) Validate(url string) error { return nil }
+
+func TestCancelDownload_StopsRunningDownload(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &blockingDownloader{started: make(chan struct{})}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformTelegram: downloader},
+		notifier, &domain.DownloadConfig{MaxRetries: 3}, zap.NewNop())
+
+	download := domain.NewDownload("https://t.me/test/6", domain.PlatformTelegram, domain.ModeDefault)
+	repo.Create(download)
+
+	result := make(chan error, 1)
+	go func() { result <- dm.ProcessDownload(context.Background(), download) }()
+	<-downloader.started
+
+	require.NoError(t, dm.CancelDownload(download.ID))
+	assert.True(t, downloader.cleaned, "CancelDownload should wait for the downloader to clean up")
+	assert.Equal(t, domain.StatusCancelled, repo.downloads[download.ID].Status)
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ProcessDownload did not return after cancel")
+	}
+	assert.Equal(t, 0, download.RetryCount, "cancelled download should not be retried")
+}
+
+func TestProcessDownload_ShutdownRequeuesRunningDownload(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &blockingDownloader{started: make(chan struct{})}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformTelegram: downloader},
+		notifier, &domain.DownloadConfig{MaxRetries: 3}, zap.NewNop())
+
+	download := domain.NewDownload("https://t.me/test/7", domain.PlatformTelegram, domain.ModeDefault)
+	repo.Create(download)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- dm.ProcessDownload(ctx, download) }()
+	<-downloader.started
+
+	dm.Drain()
+	cancel()
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ProcessDownload did not return after shutdown")
+	}
+	assert.Equal(t, domain.StatusQueued, repo.downloads[download.ID].Status)
+	assert.Equal(t, 0, download.RetryCount, "an interrupted download is not retried")
+	assert.Equal(t, domain.TimelineInterrupted, download.Timeline[len(download.Timeline)-1].Event)
+}
+
+func TestProcessDownload_DrainKeepsWaitingDownloadsQueued(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &blockingDownloader{started: make(chan struct{})}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformTelegram: downloader},
+		notifier, &domain.DownloadConfig{PlatformConcurrency: 1}, zap.NewNop())
+
+	running := domain.NewDownload("https://t.me/test/8", domain.PlatformTelegram, domain.ModeDefault)
+	waiting := domain.NewDownload("https://t.me/test/9", domain.PlatformTelegram, domain.ModeDefault)
+	repo.Create(running)
+	repo.Create(waiting)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dm.ProcessDownload(ctx, running)
+	<-downloader.started
+
+	result := make(chan error, 1)
+	go func() { result <- dm.ProcessDownload(ctx, waiting) }()
+	dm.Drain()
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("waiting download did not stop waiting on drain")
+	}
+	assert.Equal(t, domain.StatusQueued, waiting.Status)
+	assert.Equal(t, domain.StatusProcessing, running.Status, "running downloads may finish")
+}
+
+// liveDownloader records until its context is cancelled and keeps the
+// recording, like LiveRecorder.
+type liveDownloader struct {
+	started chan struct{}
+}
+
+func (l *liveDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	close(l.started)
+	<-ctx.Done()
+	return &domain.DownloadResult{Files: []string{"/completed/live.mp4"}}, nil
+}
+func (l *liveDownloader) Platform() domain.Platform { return "" }
+func (l *liveDownloader) Validate(url string) error { return nil }
+
+func TestCancelDownload_StopsRecordingAndKeepsIt(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	recorder := &liveDownloader{started: make(chan struct{})}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo, map[domain.Platform]domain.Downloader{},
+		notifier, &domain.DownloadConfig{MaxRetries: 3}, zap.NewNop())
+	dm.SetLiveRecorder(recorder)
+
+	download := domain.NewDownload("https://x.com/i/broadcasts/1YqKDqWqdPdJV", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+
+	result := make(chan error, 1)
+	go func() { result <- dm.ProcessDownload(context.Background(), download) }()
+	<-recorder.started
+	assert.Equal(t, domain.StatusRecording, repo.downloads[download.ID].Status)
+
+	require.NoError(t, dm.CancelDownload(download.ID))
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ProcessDownload did not return after stop")
+	}
+	assert.Equal(t, domain.StatusCompleted, repo.downloads[download.ID].Status)
+	assert.Equal(t, "/completed/live.mp4", repo.downloads[download.ID].FilePath)
+}
+
+// progressDownloader reports a fixed sequence of progress updates and succeeds
+type progressDownloader struct {
+	updates []domain.DownloadProgress
+}
+
+func (p *progressDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	for _, update := range p.updates {
+		progressCallback(update)
+	}
+	return &domain.DownloadResult{Files: []string{"/completed/file.mp4"}}, nil
+}
+func (p *progressDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (p *progressDownloader) Validate(url string) error { return nil }
+
+func TestProcessDownload_PersistsProgress(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &progressDownloader{updates: []domain.DownloadProgress{
+		{Percent: 10, Speed: "1.00MiB/s", ETA: "00:09", CurrentFile: "file.mp4"},
+		{Percent: 50, Speed: "2.00MiB/s", ETA: "00:02"}, // throttled
+		{Percent: 100},
+	}}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{MaxRetries: 0}, zap.NewNop())
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+	assert.Equal(t, []float64{10, 100}, repo.progressUpdates)
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Equal(t, float64(100), download.Progress)
+	assert.Empty(t, download.Speed)
+	assert.Empty(t, download.CurrentFile)
+}
+
+func TestProgressReminder(t *testing.T) {
+	start := time.Now()
+	reminder := newProgressReminder(start, 10*time.Minute)
+	assert.False(t, reminder.due(start.Add(9*time.Minute)))
+	assert.True(t, reminder.due(start.Add(10*time.Minute)))
+	assert.False(t, reminder.due(start.Add(15*time.Minute)), "one per interval")
+	// A long gap between progress updates yields one notification, not a backlog
+	assert.True(t, reminder.due(start.Add(45*time.Minute)))
+	assert.False(t, reminder.due(start.Add(49*time.Minute)))
+	assert.True(t, reminder.due(start.Add(50*time.Minute)))
+
+	disabled := newProgressReminder(start, 0)
+	assert.False(t, disabled.due(start.Add(time.Hour)))
+}
+
+// rateLimitedDownloader fails with a rate limit error until calls reach failures
+type rateLimitedDownloader struct {
+	failures   int
+	retryAfter time.Duration
+	calls      int
+}
+
+func (r *rateLimitedDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	r.calls++
+	if r.calls <= r.failures {
+		return nil, &domain.RateLimitError{RetryAfter: r.retryAfter, Err: errors.New("HTTP Error 429")}
+	}
+	return &domain.DownloadResult{Files: []string{"/completed/file.mp4"}}, nil
+}
+func (r *rateLimitedDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (r *rateLimitedDownloader) Validate(url string) error { return nil }
+
+// resultDownloader completes every download with result
+type resultDownloader struct {
+	result *domain.DownloadResult
+}
+
+func (r *resultDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	return r.result, nil
+}
+func (r *resultDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (r *resultDownloader) Validate(url string) error { return nil }
+
+func TestProcessDownload_AppliesResult(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.jpg")
+	b := filepath.Join(dir, "b.mp4")
+	require.NoError(t, os.WriteFile(a, make([]byte, 3), 0644))
+	require.NoError(t, os.WriteFile(b, make([]byte, 5), 0644))
+
+	repo := newMockDownloadManagerRepo()
+	downloader := &resultDownloader{result: &domain.DownloadResult{
+		Files:    []string{a, b},
+		Metadata: map[string]interface{}{"title": "A post", "files": []string{a, b}},
+	}}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Equal(t, a, download.FilePath)
+	assert.Contains(t, download.Metadata, `"title":"A post"`)
+	require.Len(t, download.Items, 2)
+	assert.Equal(t, int64(8), download.FileSize)
+	assert.Equal(t, int64(8), downloader.result.Bytes, "measured when the downloader did not report it")
+	assert.Greater(t, downloader.result.Duration, time.Duration(0))
+}
+
+// cancelledOnReturnDownloader succeeds, but the download is cancelled in the
+// repository as the tool returns, while its files are still being processed
+type cancelledOnReturnDownloader struct {
+	repo *mockDownloadManagerRepo
+	file string
+}
+
+func (c *cancelledOnReturnDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	cancelled := *download
+	cancelled.MarkCancelled()
+	c.repo.downloads[download.ID] = &cancelled
+	return &domain.DownloadResult{Files: []string{c.file}}, nil
+}
+func (c *cancelledOnReturnDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (c *cancelledOnReturnDownloader) Validate(url string) error { return nil }
+
+func TestProcessDownload_CancelDuringPostProcessingWins(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.mp4")
+	require.NoError(t, os.WriteFile(file, make([]byte, 3), 0644))
+
+	repo := newMockDownloadManagerRepo()
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: &cancelledOnReturnDownloader{repo: repo, file: file}},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	stored, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, stored.Status, "not overwritten by the completion")
+}
+
+// errorDownloader fails every download with err
+type errorDownloader struct {
+	err   error
+	calls int
+}
+
+func (f *errorDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	f.calls++
+	return nil, f.err
+}
+func (f *errorDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (f *errorDownloader) Validate(url string) error { return nil }
+
+func TestProcessDownload_FallbackChain(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.jpg")
+	require.NoError(t, os.WriteFile(file, make([]byte, 3), 0644))
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+
+	t.Run("extractor errors move on to the next backend", func(t *testing.T) {
+		repo := newMockDownloadManagerRepo()
+		ytdlp := &errorDownloader{err: &domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1"), Code: domain.ErrorParse}}
+		dm := NewDownloadManager(repo, map[domain.Platform]domain.Downloader{domain.PlatformX: ytdlp}, notifier, &domain.DownloadConfig{}, zap.NewNop())
+		dm.SetFallbackChains(domain.FallbackChains{domain.PlatformX: {
+			{Name: domain.BackendYTDLP, Downloader: ytdlp},
+			{Name: domain.BackendGalleryDL, Downloader: &resultDownloader{result: &domain.DownloadResult{Files: []string{file}}}},
+		}})
+
+		download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+		repo.Create(download)
+		require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+		assert.Equal(t, domain.StatusCompleted, download.Status)
+		assert.Equal(t, 1, ytdlp.calls)
+		assert.Contains(t, download.Metadata, `"backend":"gallery-dl"`)
+		assert.Contains(t, download.Metadata, `"backend_failures":[{"backend":"ytdlp","error":"yt-dlp failed: exit status 1"}]`)
+	})
+
+	t.Run("other errors stop the chain", func(t *testing.T) {
+		repo := newMockDownloadManagerRepo()
+		ytdlp := &errorDownloader{err: &domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1"), Code: domain.ErrorNotFound}}
+		galleryDL := &errorDownloader{err: errors.New("unexpected")}
+		dm := NewDownloadManager(repo, map[domain.Platform]domain.Downloader{domain.PlatformX: ytdlp}, notifier, &domain.DownloadConfig{}, zap.NewNop())
+		dm.SetFallbackChains(domain.FallbackChains{domain.PlatformX: {
+			{Name: domain.BackendYTDLP, Downloader: ytdlp},
+			{Name: domain.BackendGalleryDL, Downloader: galleryDL},
+		}})
+
+		download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+		repo.Create(download)
+		assert.Error(t, dm.ProcessDownload(context.Background(), download))
+
+		assert.Equal(t, domain.StatusFailed, download.Status)
+		assert.Equal(t, domain.ErrorNotFound, download.ErrorCode)
+		assert.Zero(t, galleryDL.calls)
+	})
+}
+
+// meteredDownloader records the usage of the processes it "runs" on the
+// meter of its context
+type meteredDownloader struct {
+	resultDownloader
+	usage domain.ResourceUsage
+}
+
+func (m *meteredDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	domain.UsageMeterFrom(ctx).Record(m.usage)
+	domain.UsageMeterFrom(ctx).Record(m.usage)
+	return m.resultDownloader.Download(ctx, download, progressCallback)
+}
+
+func TestProcessDownload_RecordsResourceUsage(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.mp4")
+	require.NoError(t, os.WriteFile(file, make([]byte, 3), 0644))
+
+	repo := newMockDownloadManagerRepo()
+	downloader := &meteredDownloader{
+		resultDownloader: resultDownloader{result: &domain.DownloadResult{Files: []string{file}}},
+		usage:            domain.ResourceUsage{Processes: 1, CPUTime: 1500 * time.Millisecond, WallTime: time.Second, PeakRSS: 64 << 20},
+	}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	assert.Equal(t, 2, download.ProcessCount)
+	assert.Equal(t, int64(3000), download.ProcessCPUMs)
+	assert.Equal(t, int64(2000), download.ProcessWallMs)
+	assert.Equal(t, int64(64<<20), download.ProcessPeakRSS)
+}
+
+// fakeContentIndex finds completed files among items by content hash
+type fakeContentIndex struct {
+	items []domain.DownloadItem
+}
+
+func (f *fakeContentIndex) FindItemsByContentHash(hash string) ([]domain.DownloadItem, error) {
+	var items []domain.DownloadItem
+	for _, item := range f.items {
+		if item.ContentHash == hash {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeContentIndex) FindDuplicateItems() ([]domain.DownloadItem, error) {
+	return f.items, nil
+}
+
+func TestProcessDownload_LinksDuplicateFiles(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.mp4")
+	gone := filepath.Join(dir, "gone.mp4")
+	video := filepath.Join(dir, "video.mp4")
+	photo := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(original, []byte("video"), 0644))
+	require.NoError(t, os.WriteFile(video, []byte("video"), 0644))
+	require.NoError(t, os.WriteFile(photo, []byte("photo"), 0644))
+	hash, err := infrastructure.HashFile(original)
+	require.NoError(t, err)
+
+	repo := newMockDownloadManagerRepo()
+	downloader := &resultDownloader{result: &domain.DownloadResult{Files: []string{video, photo}}}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{Dedupe: true}, zap.NewNop())
+	dm.SetContentIndex(&fakeContentIndex{items: []domain.DownloadItem{
+		{DownloadID: "deleted", FilePath: gone, ContentHash: hash}, // File no longer on disk
+		{DownloadID: "earlier", FilePath: original, ContentHash: hash},
+	}})
+
+	download := domain.NewDownload("https://x.com/user/status/2", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	require.Len(t, download.Items, 2)
+	assert.Equal(t, hash, download.Items[0].ContentHash)
+	assert.Equal(t, "earlier", download.Items[0].DuplicateOf)
+	originalInfo, err := os.Stat(original)
+	require.NoError(t, err)
+	videoInfo, err := os.Stat(video)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(originalInfo, videoInfo), "the duplicate is linked to the earlier copy")
+	assert.NotEmpty(t, download.Items[1].ContentHash)
+	assert.Empty(t, download.Items[1].DuplicateOf, "new content is kept")
+
+	report, err := dm.DedupeReport()
+	require.NoError(t, err)
+	assert.NotNil(t, report)
+}
+
+func TestProcessDownload_WaitsForRetryAfter(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &rateLimitedDownloader{failures: 1, retryAfter: 100 * time.Millisecond}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{MaxRetries: 1, RetryDelay: time.Millisecond}, zap.NewNop())
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+
+	start := time.Now()
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "retry should wait for Retry-After, not RetryDelay")
+	assert.Equal(t, 2, downloader.calls)
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+}
+
+func TestPausePlatform_DelaysOtherDownloads(t *testing.T) {
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil, nil,
+		&domain.DownloadConfig{RateLimitDelay: 50 * time.Millisecond}, zap.NewNop())
+
+	dm.pausePlatform(domain.PlatformX, 0) // no Retry-After: falls back to RateLimitDelay
+	assert.Greater(t, dm.platformPauseRemaining(domain.PlatformX), time.Duration(0))
+	assert.Equal(t, time.Duration(0), dm.platformPauseRemaining(domain.PlatformTelegram))
+
+	start := time.Now()
+	require.NoError(t, dm.waitForPlatform(context.Background(), domain.PlatformX))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	// Bogus Retry-After values are capped
+	dm.pausePlatform(domain.PlatformTelegram, 48*time.Hour)
+	assert.LessOrEqual(t, dm.platformPauseRemaining(domain.PlatformTelegram), maxRateLimitWait)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, dm.waitForPlatform(ctx, domain.PlatformTelegram), context.Canceled)
+}
+
+// toolErrorDownloader always fails with err
+type toolErrorDownloader struct {
+	err   error
+	calls int
+}
+
+func (f *toolErrorDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	f.calls++
+	return nil, f.err
+}
+func (f *toolErrorDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (f *toolErrorDownloader) Validate(url string) error { return nil }
+
+func TestProcessDownload_DoesNotRetryPermanentFailures(t *testing.T) {
+	for _, tt := range []struct {
+		code  domain.ErrorCode
+		calls int
+	}{
+		{domain.ErrorNotFound, 1},
+		{domain.ErrorAuthExpired, 1},
+		{domain.ErrorNetwork, 3},
+		{"", 3},
+	} {
+		repo := newMockDownloadManagerRepo()
+		downloader := &toolErrorDownloader{err: &domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1"), Code: tt.code}}
+		notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+		dm := NewDownloadManager(repo,
+			map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+			notifier, &domain.DownloadConfig{MaxRetries: 2, RetryDelay: time.Millisecond}, zap.NewNop())
+
+		download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+		repo.Create(download)
+
+		assert.Error(t, dm.ProcessDownload(context.Background(), download))
+		assert.Equal(t, tt.calls, downloader.calls, tt.code)
+		assert.Equal(t, domain.StatusFailed, download.Status)
+		assert.Equal(t, tt.code, download.ErrorCode)
+	}
+}
+
+func TestProcessDownload_RecordsClientProfile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.mp4")
+	require.NoError(t, os.WriteFile(file, make([]byte, 3), 0644))
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+
+	repo := newMockDownloadManagerRepo()
+	downloader := &resultDownloader{result: &domain.DownloadResult{Files: []string{file}, ClientProfile: "account=alt"}}
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+	assert.Equal(t, "account=alt", download.ClientProfile)
+
+	// A failed attempt returns its client settings with the error
+	repo = newMockDownloadManagerRepo()
+	toolErr := &domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1"), Code: domain.ErrorNotFound}
+	failing := &toolErrorDownloader{err: domain.WithClientProfile(toolErr, "impersonate=chrome")}
+	dm = NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: failing},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+	download = domain.NewDownload("https://x.com/user/status/2", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	assert.Error(t, dm.ProcessDownload(context.Background(), download))
+	assert.Equal(t, "impersonate=chrome", download.ClientProfile)
+	assert.Equal(t, domain.ErrorNotFound, download.ErrorCode, "the tool error is still classified")
+}
+
+func TestRateLimitBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Minute, rateLimitBackoff(5*time.Minute, 1, time.Hour))
+	assert.Equal(t, 10*time.Minute, rateLimitBackoff(5*time.Minute, 2, time.Hour))
+	assert.Equal(t, 40*time.Minute, rateLimitBackoff(5*time.Minute, 4, time.Hour))
+	assert.Equal(t, time.Hour, rateLimitBackoff(5*time.Minute, 5, time.Hour))
+	assert.Equal(t, time.Hour, rateLimitBackoff(5*time.Minute, 1000, time.Hour))
+}
+
+func TestPausePlatform_BacksOffUntilRecovered(t *testing.T) {
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil, nil,
+		&domain.DownloadConfig{RateLimitDelay: time.Second, RateLimitMaxDelay: 3 * time.Second}, zap.NewNop())
+
+	dm.pausePlatform(domain.PlatformX, 0)
+	first := dm.platformPauseRemaining(domain.PlatformX)
+	assert.InDelta(t, 1.1, first.Seconds(), 0.11, "the delay plus up to 20% jitter")
+
+	dm.pausePlatform(domain.PlatformX, 0)
+	assert.InDelta(t, 2.2, dm.platformPauseRemaining(domain.PlatformX).Seconds(), 0.21, "doubled for a second rate limit in a row")
+
+	dm.pausePlatform(domain.PlatformX, 0)
+	assert.LessOrEqual(t, dm.platformPauseRemaining(domain.PlatformX), 3*time.Second, "capped at rate_limit_max_delay")
+
+	paused := dm.PausedPlatforms()
+	assert.Contains(t, paused, domain.PlatformX)
+	assert.NotContains(t, paused, domain.PlatformTelegram)
+
+	// A completed download resets the backoff
+	dm.rateLimitRecovered(domain.PlatformX)
+	dm.pausedUntil[domain.PlatformX] = time.Time{}
+	dm.pausePlatform(domain.PlatformX, 0)
+	assert.InDelta(t, 1.1, dm.platformPauseRemaining(domain.PlatformX).Seconds(), 0.11)
+}
+
+func TestCompleteDownload_CreatesSizedItems(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.jpg")
+	b := filepath.Join(dir, "b.mp4")
+	require.NoError(t, os.WriteFile(a, make([]byte, 3), 0644))
+	require.NoError(t, os.WriteFile(b, make([]byte, 5), 0644))
+
+	download := domain.NewDownload("https://t.me/channel/1", domain.PlatformTelegram, domain.ModeGroup)
+	download.Metadata = `{"files":["` + a + `","` + b + `"]}`
+	completeDownload(download, a)
+
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	require.Len(t, download.Items, 2)
+	assert.Equal(t, int64(3), download.Items[0].FileSize)
+	assert.Equal(t, int64(5), download.Items[1].FileSize)
+	assert.Equal(t, int64(8), download.FileSize)
+}
+
+// fakeDiskGuard reports no disk space for its first failures checks
+type fakeDiskGuard struct {
+	failures int
+	calls    int
+	watch    *domain.Download // ErrorMessage of watch is recorded on each check
+	messages []string
+}
+
+func (g *fakeDiskGuard) Check() error {
+	g.calls++
+	if g.watch != nil {
+		g.messages = append(g.messages, g.watch.ErrorMessage)
+	}
+	if g.calls <= g.failures {
+		return &domain.DiskSpaceError{Reason: "100 MiB free"}
+	}
+	return nil
+}
+
+func TestProcessDownload_HoldsUntilDiskSpace(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &rateLimitedDownloader{}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{DiskFullAction: domain.DiskFullHold}, zap.NewNop())
+	dm.diskCheckInterval = time.Millisecond
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	guard := &fakeDiskGuard{failures: 2, watch: download}
+	dm.SetDiskGuard(guard)
+
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+	assert.Equal(t, 3, guard.calls)
+	assert.Equal(t, "held: not enough disk space: 100 MiB free", guard.messages[1], "the reason is shown while held")
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Empty(t, download.ErrorMessage)
+	assert.Equal(t, 1, downloader.calls)
+}
+
+func TestProcessDownload_FailsWithoutDiskSpace(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	downloader := &rateLimitedDownloader{}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{DiskFullAction: domain.DiskFullFail}, zap.NewNop())
+	dm.SetDiskGuard(&fakeDiskGuard{failures: 1})
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+
+	err := dm.ProcessDownload(context.Background(), download)
+	var diskErr *domain.DiskSpaceError
+	assert.ErrorAs(t, err, &diskErr)
+	assert.Equal(t, domain.StatusFailed, download.Status)
+	assert.Equal(t, "not enough disk space: 100 MiB free", download.ErrorMessage)
+	assert.Zero(t, downloader.calls, "the tool never starts")
+}
+
+// recordingCallbackSender records the statuses of the downloads it is sent
+type recordingCallbackSender struct {
+	statuses []domain.DownloadStatus
+}
+
+func (s *recordingCallbackSender) Send(download *domain.Download) {
+	s.statuses = append(s.statuses, download.Status)
+}
+
+// failingDownloader fails every attempt
+type failingDownloader struct{}
+
+func (failingDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	return nil, errors.New("tdl failed: exit status 1")
+}
+func (failingDownloader) Platform() domain.Platform { return domain.PlatformTelegram }
+func (failingDownloader) Validate(url string) error { return nil }
+
+func TestProcessDownload_SendsCallback(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: &progressDownloader{}, domain.PlatformTelegram: failingDownloader{}},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+	callbacks := &recordingCallbackSender{}
+	dm.SetCallbackSender(callbacks)
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	failed := domain.NewDownload("https://t.me/channel/1", domain.PlatformTelegram, domain.ModeDefault)
+	repo.Create(failed)
+	assert.Error(t, dm.ProcessDownload(context.Background(), failed))
+
+	assert.Equal(t, []domain.DownloadStatus{domain.StatusCompleted, domain.StatusFailed}, callbacks.statuses)
+}
+
+// transcodingPostProcessor replaces every file with a transcoded copy
+type transcodingPostProcessor struct {
+	platform domain.Platform
+}
+
+func (p *transcodingPostProcessor) Process(ctx context.Context, platform domain.Platform, files []string) (map[string]string, []domain.PostProcessResult) {
+	p.platform = platform
+	renamed := make(map[string]string)
+	var results []domain.PostProcessResult
+	for _, file := range files {
+		output := strings.TrimSuffix(file, filepath.Ext(file)) + "_2.mp4"
+		renamed[file] = output
+		results = append(results, domain.PostProcessResult{Step: domain.PostProcessH264, File: file, Output: output})
+	}
+	return renamed, results
+}
+
+func TestProcessDownload_PostProcessesCompletedFiles(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: &progressDownloader{}},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+	processor := &transcodingPostProcessor{}
+	dm.SetPostProcessor(processor)
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	assert.Equal(t, domain.PlatformX, processor.platform)
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Equal(t, "/completed/file_2.mp4", download.FilePath)
+	assert.Equal(t, []string{"/completed/file_2.mp4"}, download.Files())
+
+	var meta struct {
+		PostProcess []domain.PostProcessResult `json:"postprocess"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(download.Metadata), &meta))
+	assert.Equal(t, []domain.PostProcessResult{
+		{Step: domain.PostProcessH264, File: "/completed/file.mp4", Output: "/completed/file_2.mp4"},
+	}, meta.PostProcess)
+}
+
+// recordingEnrichment records the files it saw, after post-processing
+type recordingEnrichment struct {
+	files []string
+}
+
+func (e *recordingEnrichment) Enrich(ctx context.Context, download *domain.Download) []domain.EnrichmentError {
+	e.files = download.Files()
+	download.SetMetadataField("sha256", "abc")
+	return []domain.EnrichmentError{{Enricher: "exif", File: download.FilePath, Error: "exit status 1"}}
+}
+
+func TestProcessDownload_EnrichesCompletedFiles(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: &progressDownloader{}},
+		notifier, &domain.DownloadConfig{}, zap.NewNop())
+	dm.SetPostProcessor(&transcodingPostProcessor{})
+	enrichment := &recordingEnrichment{}
+	dm.SetMetadataEnrichment(enrichment)
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	// A failed enricher leaves the download completed
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Equal(t, []string{"/completed/file_2.mp4"}, enrichment.files)
+	assert.Contains(t, download.Metadata, `"sha256":"abc"`)
+}
+
+// uploadingStorage records the keys it was given and fails for failKey
+type uploadingStorage struct {
+	keys    []string
+	failKey string
+}
+
+func (s *uploadingStorage) Store(ctx context.Context, file, key string) (string, error) {
+	s.keys = append(s.keys, key)
+	if key == s.failKey {
+		return "", errors.New("connection reset")
+	}
+	return "https://bucket.example.com/" + key, nil
+}
+
+func TestProcessDownload_StoresCompletedFiles(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: &progressDownloader{}},
+		notifier, &domain.DownloadConfig{BaseDir: "/"}, zap.NewNop())
+	storage := &uploadingStorage{}
+	dm.SetStorage(storage, false)
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Equal(t, []string{"file.mp4"}, storage.keys)
+	assert.Equal(t, "https://bucket.example.com/file.mp4", download.RemoteURL)
+	require.Len(t, download.Items, 1)
+	assert.Equal(t, "https://bucket.example.com/file.mp4", download.Items[0].RemoteURL)
+}
+
+// heldJobs is a maintenance window holding the jobs set to true
+type heldJobs map[string]bool
+
+func (h heldJobs) Holds(job string) bool { return h[job] }
+
+func TestProcessDownload_DefersHeldJobs(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: &progressDownloader{}},
+		notifier, &domain.DownloadConfig{BaseDir: "/"}, zap.NewNop())
+	dm.SetPostProcessor(&transcodingPostProcessor{})
+	storage := &uploadingStorage{}
+	dm.SetStorage(storage, false)
+	window := heldJobs{domain.MaintenanceTranscodes: true}
+	dm.SetMaintenanceWindow(window)
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	repo.Create(download)
+	require.NoError(t, dm.ProcessDownload(context.Background(), download))
+
+	assert.Equal(t, domain.StatusCompleted, download.Status)
+	assert.Equal(t, "transcodes,remote_sync", download.Deferred, "files are uploaded after their transcode")
+	assert.Equal(t, "/completed/file.mp4", download.FilePath)
+	assert.Empty(t, storage.keys)
+
+	// The window opens
+	delete(window, domain.MaintenanceTranscodes)
+	require.NoError(t, dm.RunDeferred(context.Background(), download))
+	assert.Empty(t, download.Deferred)
+	assert.Equal(t, "/completed/file_2.mp4", download.FilePath)
+	assert.Equal(t, []string{"file_2.mp4"}, storage.keys)
+	saved, _ := repo.FindByID(download.ID)
+	assert.Empty(t, saved.Deferred)
+}
+
+func TestStoreCompletedFiles_DeleteLocal(t *testing.T) {
+	completedDir := filepath.Join(t.TempDir(), "completed")
+	require.NoError(t, os.MkdirAll(filepath.Join(completedDir, "alice"), 0755))
+	uploaded := filepath.Join(completedDir, "alice", "a.jpg")
+	failed := filepath.Join(completedDir, "alice", "b.jpg")
+	for _, file := range []string{uploaded, failed} {
+		require.NoError(t, os.WriteFile(file, []byte("image"), 0644))
+	}
+
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil,
+		infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop()),
+		&domain.DownloadConfig{CompletedPath: completedDir}, zap.NewNop())
+	storage := &uploadingStorage{failKey: "alice/b.jpg"}
+	dm.SetStorage(storage, true)
+
+	download := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+	download.Items = []domain.DownloadItem{
+		{Status: domain.StatusCompleted, FilePath: uploaded},
+		{Status: domain.StatusCompleted, FilePath: failed},
+	}
+	dm.storeCompletedFiles(context.Background(), download)
+
+	assert.Equal(t, []string{"alice/a.jpg", "alice/b.jpg"}, storage.keys)
+	assert.Equal(t, "https://bucket.example.com/alice/a.jpg", download.Items[0].RemoteURL)
+	assert.Empty(t, download.Items[1].RemoteURL)
+	assert.NoFileExists(t, uploaded)
+	assert.FileExists(t, failed, "a file that failed to upload keeps its local copy")
+	assert.Contains(t, download.Metadata, "connection reset")
+}
+
+func TestRecordMediaDuration(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "a.mp4"), filepath.Join(dir, "b.mp4"), filepath.Join(dir, "c.jpg")}
+	for _, file := range files {
+		require.NoError(t, os.WriteFile(file, []byte("media"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.info.json"), []byte(`{"duration": 12.5}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.info.json"), []byte(`{"duration": 30}`), 0644))
+
+	download := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+	for _, file := range files {
+		download.Items = append(download.Items, domain.DownloadItem{Status: domain.StatusCompleted, FilePath: file})
+	}
+	recordMediaDuration(download)
+	assert.Equal(t, 42.5, download.MediaDuration)
+
+	// Without sidecars the duration of the metadata is used
+	download = domain.NewDownload("https://x.com/alice/status/2", domain.PlatformX, domain.ModeDefault)
+	download.Metadata = `{"duration": 7}`
+	recordMediaDuration(download)
+	assert.Equal(t, 7.0, download.MediaDuration)
+}
+
+// sizedDownloader simulates downloads with the size of their URL in sizes
+type sizedDownloader struct {
+	resultDownloader
+	sizes     map[string]int64
+	simulated int
+}
+
+func (s *sizedDownloader) Simulate(ctx context.Context, download *domain.Download) (*domain.Simulation, error) {
+	s.simulated++
+	return &domain.Simulation{Items: []domain.SimulatedItem{{Size: s.sizes[download.URL]}}}, nil
+}
+
+func TestAcquireSizeSlot(t *testing.T) {
+	downloader := &sizedDownloader{sizes: map[string]int64{
+		"https://x.com/a/status/1": 500 << 20,
+		"https://x.com/a/status/2": 300 << 20,
+		"https://x.com/a/status/3": 2 << 20,
+	}}
+	dm := NewDownloadManager(newMockDownloadManagerRepo(),
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		nil, &domain.DownloadConfig{LargeSize: "100MiB", LargeConcurrency: 1}, zap.NewNop())
+
+	big := domain.NewDownload("https://x.com/a/status/1", domain.PlatformX, domain.ModeDefault)
+	release, err := dm.acquireSizeSlot(context.Background(), big)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500<<20), big.EstimatedSize)
+
+	small := domain.NewDownload("https://x.com/a/status/3", domain.PlatformX, domain.ModeDefault)
+	releaseSmall, err := dm.acquireSizeSlot(context.Background(), small)
+	require.NoError(t, err, "small downloads do not wait for large ones")
+	releaseSmall()
+
+	other := domain.NewDownload("https://x.com/a/status/2", domain.PlatformX, domain.ModeDefault)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = dm.acquireSizeSlot(ctx, other)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "one large download at a time")
+
+	release()
+	releaseOther, err := dm.acquireSizeSlot(context.Background(), other)
+	require.NoError(t, err)
+	releaseOther()
+	assert.Equal(t, 3, downloader.simulated, "the estimate is kept across waits")
+}
+
+func TestAcquireSizeSlot_WithoutSizeClasses(t *testing.T) {
+	downloader := &sizedDownloader{sizes: map[string]int64{"https://x.com/a/status/1": 500 << 20}}
+	dm := NewDownloadManager(newMockDownloadManagerRepo(),
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		nil, &domain.DownloadConfig{LargeConcurrency: 1}, zap.NewNop())
+
+	download := domain.NewDownload("https://x.com/a/status/1", domain.PlatformX, domain.ModeDefault)
+	for i := 0; i < 2; i++ {
+		release, err := dm.acquireSizeSlot(context.Background(), download)
+		require.NoError(t, err)
+		defer release()
+	}
+	assert.Zero(t, downloader.simulated, "nothing is estimated")
+	assert.Zero(t, download.EstimatedSize)
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// exportPageSize is how many downloads ExportDownloads loads at a time
+const exportPageSize = 500
+
+// ExportDownloads exports the downloads of the queue database, as the
+// ExportDownloads function does
+func (qm *QueueManager) ExportDownloads(ctx context.Context, w *domain.ExportWriter, filters map[string]interface{}, opts domain.ListOptions) (int, error) {
+	return ExportDownloads(ctx, qm.repo, w, filters, opts)
+}
+
+// ExportDownloads writes the downloads of repo selected by filters and opts
+// to w, a page at a time so the whole archive is never held in memory.
+// opts.Limit caps the number of downloads exported (0 = all). It returns the
+// number of downloads written.
+func ExportDownloads(ctx context.Context, repo domain.DownloadRepository, w *domain.ExportWriter, filters map[string]interface{}, opts domain.ListOptions) (int, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	written := 0
+	page := opts
+	for opts.Limit == 0 || written < opts.Limit {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		page.Limit = exportPageSize
+		if opts.Limit > 0 && opts.Limit-written < exportPageSize {
+			page.Limit = opts.Limit - written
+		}
+		page.Offset = opts.Offset + written
+		downloads, err := repo.FindAll(filters, page)
+		if err != nil {
+			return written, fmt.Errorf("failed to list downloads: %w", err)
+		}
+		for _, download := range downloads {
+			if err := w.Write(download); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if err := w.Flush(); err != nil {
+			return written, err
+		}
+		if len(downloads) < page.Limit {
+			break
+		}
+	}
+	return written, nil
+}
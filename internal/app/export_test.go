@@ -0,0 +1,60 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// pagingRepo serves FindAll pages of downloads, in order
+type pagingRepo struct {
+	mockRepo
+	all   []*domain.Download
+	pages []domain.ListOptions
+}
+
+func (r *pagingRepo) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
+	r.pages = append(r.pages, opts)
+	if opts.Offset >= len(r.all) {
+		return nil, nil
+	}
+	end := len(r.all)
+	if opts.Limit > 0 && opts.Offset+opts.Limit < end {
+		end = opts.Offset + opts.Limit
+	}
+	return r.all[opts.Offset:end], nil
+}
+
+func TestExportDownloads(t *testing.T) {
+	repo := &pagingRepo{}
+	for i := 0; i < 5; i++ {
+		repo.all = append(repo.all, domain.NewDownload(fmt.Sprintf("https://x.com/a/status/%d", i), domain.PlatformX, domain.ModeDefault))
+	}
+
+	var buf bytes.Buffer
+	w, err := domain.NewExportWriter(&buf, domain.ExportCSV)
+	require.NoError(t, err)
+	written, err := ExportDownloads(context.Background(), repo, w, nil, domain.ListOptions{Offset: 1, Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 3, written)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4, "header and three downloads")
+	assert.Equal(t, "https://x.com/a/status/1", rows[1][1])
+	assert.Equal(t, "https://x.com/a/status/3", rows[3][1])
+
+	// Everything: one short page ends the export
+	repo.pages = nil
+	written, err = ExportDownloads(context.Background(), repo, w, nil, domain.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, written)
+	require.Len(t, repo.pages, 1)
+	assert.Equal(t, exportPageSize, repo.pages[0].Limit)
+}
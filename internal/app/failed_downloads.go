@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// findFailed returns the failed downloads selected by filter, newest first
+func (dm *DownloadManager) findFailed(filter domain.FailedFilter) ([]*domain.Download, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	downloads, err := dm.repo.FindAll(filter.Filters(), domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed downloads: %w", err)
+	}
+	now := time.Now()
+	var matched []*domain.Download
+	for _, download := range downloads {
+		if filter.Matches(download, now) {
+			matched = append(matched, download)
+		}
+	}
+	return matched, nil
+}
+
+// FailedDownloads returns the failed downloads selected by filter grouped by
+// error code, the largest group first
+func (dm *DownloadManager) FailedDownloads(filter domain.FailedFilter) ([]domain.FailedGroup, error) {
+	downloads, err := dm.findFailed(filter)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[domain.ErrorCode]int)
+	groups := []domain.FailedGroup{}
+	for _, download := range downloads {
+		i, ok := index[download.ErrorCode]
+		if !ok {
+			i = len(groups)
+			index[download.ErrorCode] = i
+			groups = append(groups, domain.FailedGroup{
+				ErrorCode: download.ErrorCode,
+				Retryable: download.ErrorCode.Retryable(),
+			})
+		}
+		groups[i].Count++
+		groups[i].Downloads = append(groups[i].Downloads, download)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].ErrorCode < groups[j].ErrorCode
+	})
+	return groups, nil
+}
+
+// RetryFailed queues every failed download selected by filter again and
+// returns their IDs
+func (dm *DownloadManager) RetryFailed(ctx context.Context, filter domain.FailedFilter) ([]string, error) {
+	downloads, err := dm.findFailed(filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, download := range downloads {
+		if err := ctx.Err(); err != nil {
+			return ids, err
+		}
+		download.MarkRequeued()
+		if err := dm.repo.Update(download); err != nil {
+			return ids, fmt.Errorf("failed to update download %s: %w", download.ID, err)
+		}
+		ids = append(ids, download.ID)
+	}
+
+	if dm.logger != nil {
+		dm.logger.Info("Failed downloads queued for retry",
+			zap.Int("count", len(ids)),
+			zap.String("platform", string(filter.Platform)),
+			zap.String("error_code", string(filter.ErrorCode)))
+	}
+	return ids, nil
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// addFailed adds a download of platform that failed with code age ago
+func addFailed(repo *mockDownloadManagerRepo, platform domain.Platform, code domain.ErrorCode, age time.Duration) *domain.Download {
+	download := domain.NewDownload("https://example.com/"+string(code), platform, domain.ModeDefault)
+	download.MarkFailed(&domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1"), Code: code})
+	download.UpdatedAt = time.Now().Add(-age)
+	repo.Create(download)
+	return download
+}
+
+func TestFailedDownloads_GroupsByErrorCode(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	addFailed(repo, domain.PlatformX, domain.ErrorRateLimited, time.Hour)
+	addFailed(repo, domain.PlatformX, domain.ErrorRateLimited, 2*time.Hour)
+	addFailed(repo, domain.PlatformTelegram, domain.ErrorNotFound, time.Hour)
+	addFailed(repo, domain.PlatformX, "", time.Hour)
+	repo.Create(domain.NewDownload("https://x.com/a/status/1", domain.PlatformX, domain.ModeDefault)) // Queued
+	dm := NewDownloadManager(repo, nil, nil, &domain.DownloadConfig{}, nil)
+
+	groups, err := dm.FailedDownloads(domain.FailedFilter{})
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Equal(t, domain.ErrorRateLimited, groups[0].ErrorCode, "largest group first")
+	assert.Equal(t, 2, groups[0].Count)
+	assert.Len(t, groups[0].Downloads, 2)
+	assert.True(t, groups[0].Retryable)
+	assert.Equal(t, domain.ErrorCode(""), groups[1].ErrorCode, "unclassified failures")
+	assert.Equal(t, domain.ErrorNotFound, groups[2].ErrorCode)
+	assert.False(t, groups[2].Retryable)
+
+	groups, err = dm.FailedDownloads(domain.FailedFilter{Platform: domain.PlatformTelegram})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, domain.ErrorNotFound, groups[0].ErrorCode)
+
+	_, err = dm.FailedDownloads(domain.FailedFilter{Platform: "myspace"})
+	assert.Error(t, err)
+}
+
+func TestRetryFailed_RequeuesMatchingDownloads(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	recent := addFailed(repo, domain.PlatformX, domain.ErrorRateLimited, time.Hour)
+	old := addFailed(repo, domain.PlatformX, domain.ErrorRateLimited, 3*24*time.Hour)
+	network := addFailed(repo, domain.PlatformX, domain.ErrorNetwork, time.Hour)
+	dm := NewDownloadManager(repo, nil, nil, &domain.DownloadConfig{}, nil)
+
+	ids, err := dm.RetryFailed(context.Background(), domain.FailedFilter{
+		ErrorCode: domain.ErrorRateLimited,
+		NewerThan: 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent.ID}, ids)
+	assert.Equal(t, domain.StatusQueued, recent.Status)
+	assert.Empty(t, recent.ErrorCode)
+	assert.Equal(t, domain.StatusFailed, old.Status)
+	assert.Equal(t, domain.StatusFailed, network.Status)
+
+	ids, err = dm.RetryFailed(context.Background(), domain.FailedFilter{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{old.ID, network.ID}, ids)
+}
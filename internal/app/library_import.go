@@ -0,0 +1,191 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+// libraryRepository is the persistence used by ImportLibrary
+type libraryRepository interface {
+	FindByURL(url string, statuses []domain.DownloadStatus) (*domain.Download, error)
+	Create(download *domain.Download) error
+}
+
+// ImportedDownload is a download created (or, in a dry run, to be created) by
+// ImportLibrary
+type ImportedDownload struct {
+	ID       string          `json:"id,omitempty"`
+	URL      string          `json:"url"`
+	Platform domain.Platform `json:"platform"`
+	Title    string          `json:"title,omitempty"`
+	Files    int             `json:"files"`
+	FileSize int64           `json:"file_size"`
+}
+
+// LibraryImportResult summarizes an import-library run
+type LibraryImportResult struct {
+	DryRun    bool               `json:"dry_run"`
+	Imported  int                `json:"imported"` // Downloads created
+	Existing  int                `json:"existing"` // URLs that already have a completed download
+	Skipped   int                `json:"skipped"`  // Media files without a readable .info.json or URL
+	Downloads []ImportedDownload `json:"downloads"`
+}
+
+// libraryEntry collects the media files of one URL
+type libraryEntry struct {
+	url         string
+	meta        *domain.MediaMetadata
+	files       []string
+	completedAt time.Time
+}
+
+// ImportLibrary scans dir and its subdirectories for media files with a
+// <name>.info.json sidecar (as written by yt-dlp, gallery-dl or x-extract) and
+// creates a completed download per webpage URL, so duplicate detection and
+// search cover files downloaded by other tools. Files sharing a URL become the
+// items of one download. URLs with a completed download are left alone, so
+// the import can be run again after adding files. A dry run only reports what
+// would be imported.
+func ImportLibrary(repo libraryRepository, dir string, dryRun bool) (*LibraryImportResult, error) {
+	entries, skipped, err := scanLibrary(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LibraryImportResult{DryRun: dryRun, Skipped: skipped, Downloads: []ImportedDownload{}}
+	for _, entry := range entries {
+		existing, err := repo.FindByURL(entry.url, []domain.DownloadStatus{domain.StatusCompleted})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for completed download: %w", err)
+		}
+		if existing != nil {
+			result.Existing++
+			continue
+		}
+
+		download, err := newLibraryDownload(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !dryRun {
+			if err := repo.Create(download); err != nil {
+				return nil, fmt.Errorf("failed to create download for %s: %w", entry.url, err)
+			}
+		}
+		imported := ImportedDownload{
+			URL:      download.URL,
+			Platform: download.Platform,
+			Title:    download.Title,
+			Files:    len(download.Items),
+			FileSize: download.FileSize,
+		}
+		if !dryRun {
+			imported.ID = download.ID
+		}
+		result.Imported++
+		result.Downloads = append(result.Downloads, imported)
+	}
+	return result, nil
+}
+
+// scanLibrary groups the media files under dir by URL, in path order, and
+// counts the media files that cannot be imported
+func scanLibrary(dir string) ([]*libraryEntry, int, error) {
+	var entries []*libraryEntry
+	byURL := make(map[string]*libraryEntry)
+	skipped := 0
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !infrastructure.IsMediaFile(path) {
+			return nil
+		}
+
+		meta, err := readInfoJSON(path)
+		if err != nil {
+			skipped++
+			return nil
+		}
+		url := meta.WebpageURL
+		if url == "" {
+			url = meta.URL
+		}
+		if domain.DetectPlatform(url) == "" {
+			skipped++
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			skipped++
+			return nil
+		}
+		entry, ok := byURL[url]
+		if !ok {
+			entry = &libraryEntry{url: url, meta: meta}
+			byURL[url] = entry
+			entries = append(entries, entry)
+		}
+		entry.files = append(entry.files, path)
+		if info.ModTime().After(entry.completedAt) {
+			entry.completedAt = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return entries, skipped, nil
+}
+
+// readInfoJSON parses the .info.json sidecar of a media file
+func readInfoJSON(mediaPath string) (*domain.MediaMetadata, error) {
+	infoPath := strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath)) + ".info.json"
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return nil, err
+	}
+	var meta domain.MediaMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// newLibraryDownload builds the completed download of a library entry, with
+// one item per file
+func newLibraryDownload(entry *libraryEntry) (*domain.Download, error) {
+	platform := domain.Platform(entry.meta.Platform)
+	if !domain.ValidatePlatform(platform) {
+		platform = domain.DetectPlatform(entry.url)
+	}
+
+	download := domain.NewImportedDownload(entry.url, platform, entry.files[0], entry.completedAt)
+
+	meta := *entry.meta
+	meta.Files = entry.files
+	data, err := json.Marshal(meta.ToMap())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata for %s: %w", entry.url, err)
+	}
+	download.Metadata = string(data)
+	download.SyncMetadataColumns()
+
+	download.EnsureItems()
+	for i := range download.Items {
+		download.Items[i].FileSize = infrastructure.TotalFileSize([]string{download.Items[i].FilePath})
+	}
+	download.FileSize = download.ItemsSize()
+	recordMediaDuration(download)
+	return download, nil
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// mockLibraryRepo holds completed downloads by URL and records creates
+type mockLibraryRepo struct {
+	completed map[string]*domain.Download
+	created   []*domain.Download
+}
+
+func (m *mockLibraryRepo) FindByURL(url string, statuses []domain.DownloadStatus) (*domain.Download, error) {
+	return m.completed[url], nil
+}
+
+func (m *mockLibraryRepo) Create(download *domain.Download) error {
+	m.created = append(m.created, download)
+	return nil
+}
+
+func TestImportLibrary(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Two images of one tweet in an organize_by subdirectory
+	tweet := `{"title":"A tweet","uploader":"Alice","uploader_id":"alice","webpage_url":"https://x.com/alice/status/1","platform":"x","tags":["cats"]}`
+	write("alice/1_1.jpg", "1234")
+	write("alice/1_1.info.json", tweet)
+	write("alice/1_2.jpg", "123456")
+	write("alice/1_2.info.json", tweet)
+	// A yt-dlp video with only url and no platform
+	write("video.mp4", "12345678")
+	write("video.info.json", `{"title":"A video","url":"https://www.youtube.com/watch?v=abc"}`)
+	// Already in the database
+	write("known.mp4", "1")
+	write("known.info.json", `{"webpage_url":"https://x.com/bob/status/2"}`)
+	// No sidecar, and a sidecar without URL
+	write("orphan.mp4", "1")
+	write("nourl.mp4", "1")
+	write("nourl.info.json", `{"title":"No URL"}`)
+
+	repo := &mockLibraryRepo{completed: map[string]*domain.Download{
+		"https://x.com/bob/status/2": {ID: "known", Status: domain.StatusCompleted},
+	}}
+
+	// A dry run reports without creating records
+	result, err := ImportLibrary(repo, dir, true)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 1, result.Existing)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Empty(t, repo.created)
+
+	result, err = ImportLibrary(repo, dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, repo.created, 2)
+
+	tweetDl := repo.created[0]
+	assert.Equal(t, "https://x.com/alice/status/1", tweetDl.URL)
+	assert.Equal(t, domain.PlatformX, tweetDl.Platform)
+	assert.Equal(t, domain.StatusCompleted, tweetDl.Status)
+	assert.Equal(t, "A tweet", tweetDl.Title)
+	assert.Equal(t, "alice", tweetDl.UploaderID)
+	assert.Equal(t, filepath.Join(dir, "alice/1_1.jpg"), tweetDl.FilePath)
+	require.Len(t, tweetDl.Items, 2)
+	assert.Equal(t, int64(6), tweetDl.Items[1].FileSize)
+	assert.Equal(t, int64(10), tweetDl.FileSize)
+	assert.Equal(t, []string{filepath.Join(dir, "alice/1_1.jpg"), filepath.Join(dir, "alice/1_2.jpg")}, tweetDl.Files())
+	require.NotNil(t, tweetDl.CompletedAt)
+	require.Len(t, tweetDl.Timeline, 1)
+	assert.Equal(t, domain.TimelineImported, tweetDl.Timeline[0].Event)
+	assert.Contains(t, tweetDl.Metadata, `"cats"`)
+
+	videoDl := repo.created[1]
+	assert.Equal(t, "https://www.youtube.com/watch?v=abc", videoDl.URL)
+	assert.Equal(t, domain.DetectPlatform(videoDl.URL), videoDl.Platform)
+	assert.Equal(t, int64(8), videoDl.FileSize)
+}
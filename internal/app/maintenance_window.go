@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// maintenanceGate tells whether a heavy job has to wait for the maintenance
+// window (MaintenanceWindow)
+type maintenanceGate interface {
+	Holds(job string) bool
+}
+
+// maintenanceRepository is the persistence used by MaintenanceWindow
+type maintenanceRepository interface {
+	FindDeferred(limit int) ([]*domain.Download, error)
+	CountDeferred() (int64, error)
+	Vacuum() error
+}
+
+// deferredJobRunner runs the jobs of a completed download that waited for the
+// window (DownloadManager)
+type deferredJobRunner interface {
+	RunDeferred(ctx context.Context, download *domain.Download) error
+}
+
+// maintenanceCheckInterval is how often MaintenanceWindow checks whether the
+// window is open
+const maintenanceCheckInterval = time.Minute
+
+// maintenanceBatchSize is how many downloads with deferred jobs are run per
+// check
+const maintenanceBatchSize = 50
+
+// MaintenanceWindow holds heavy background jobs (maintenance.jobs) until the
+// daily maintenance.window opens, so they don't compete with interactive
+// downloads: scheduled channel exports wait, transcodes and uploads of
+// completed downloads are deferred and run in the window, and the database is
+// vacuumed once per window. Without a window nothing is held.
+type MaintenanceWindow struct {
+	config       *domain.MaintenanceConfig
+	window       *domain.DailyWindow // nil without maintenance.window
+	repo         maintenanceRepository
+	downloads    deferredJobRunner
+	multiLogger  *logger.MultiLogger
+	now          func() time.Time
+	runMu        sync.Mutex // Serializes runs
+	mu           sync.Mutex // Guards the times below
+	lastRunAt    *time.Time
+	lastVacuumAt *time.Time
+}
+
+// NewMaintenanceWindow creates the maintenance window of a validated config.
+// multiLogger may be nil.
+func NewMaintenanceWindow(config *domain.MaintenanceConfig, repo maintenanceRepository, downloads deferredJobRunner, multiLogger *logger.MultiLogger) *MaintenanceWindow {
+	return &MaintenanceWindow{
+		config:      config,
+		window:      config.DailyWindow(),
+		repo:        repo,
+		downloads:   downloads,
+		multiLogger: multiLogger,
+		now:         time.Now,
+	}
+}
+
+// Enabled reports whether a window is set
+func (m *MaintenanceWindow) Enabled() bool {
+	return m.window != nil
+}
+
+// Holds reports whether job has to wait: it is one of maintenance.jobs and
+// the window is closed
+func (m *MaintenanceWindow) Holds(job string) bool {
+	return m.window != nil && m.config.Holds(job) && !m.window.Contains(m.now())
+}
+
+// Run runs the held jobs whenever the window is open, checking at start and
+// then every minute until ctx is cancelled
+func (m *MaintenanceWindow) Run(ctx context.Context) {
+	ticker := time.NewTicker(maintenanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		m.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs the held jobs when the window is open: the database is vacuumed
+// when it was not yet in this window, then the deferred jobs of a batch of
+// downloads run, as long as the window stays open. Returns the number of
+// downloads whose jobs ran.
+func (m *MaintenanceWindow) RunDue(ctx context.Context) int {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	if m.window == nil || !m.window.Contains(m.now()) {
+		return 0
+	}
+	m.vacuum()
+
+	downloads, err := m.repo.FindDeferred(maintenanceBatchSize)
+	if err != nil {
+		if m.multiLogger != nil {
+			m.multiLogger.LogAppError("Failed to list downloads with deferred jobs", zap.Error(err))
+		}
+		return 0
+	}
+	ran := 0
+	for _, download := range downloads {
+		if ctx.Err() != nil || !m.window.Contains(m.now()) {
+			break
+		}
+		if err := m.downloads.RunDeferred(ctx, download); err != nil {
+			if m.multiLogger != nil {
+				m.multiLogger.LogAppError("Deferred jobs failed", zap.String("download_id", download.ID), zap.Error(err))
+			}
+			continue
+		}
+		ran++
+	}
+	if ran > 0 {
+		now := m.now()
+		m.mu.Lock()
+		m.lastRunAt = &now
+		m.mu.Unlock()
+	}
+	return ran
+}
+
+// vacuum vacuums the database once per window
+func (m *MaintenanceWindow) vacuum() {
+	if !m.config.Holds(domain.MaintenanceVacuum) {
+		return
+	}
+	now := m.now()
+	m.mu.Lock()
+	done := m.lastVacuumAt != nil && !m.lastVacuumAt.Before(m.window.OpenedAt(now))
+	m.mu.Unlock()
+	if done {
+		return
+	}
+
+	start := time.Now()
+	if err := m.repo.Vacuum(); err != nil {
+		if m.multiLogger != nil {
+			m.multiLogger.LogAppError("Database vacuum failed", zap.Error(err))
+		}
+		return
+	}
+	m.mu.Lock()
+	m.lastVacuumAt = &now
+	m.mu.Unlock()
+	if m.multiLogger != nil {
+		m.multiLogger.LogQueueEvent("database_vacuumed", zap.Duration("duration", time.Since(start)))
+	}
+}
+
+// Status returns the state of the window
+func (m *MaintenanceWindow) Status() (*domain.MaintenanceStatus, error) {
+	deferred, err := m.repo.CountDeferred()
+	if err != nil {
+		return nil, err
+	}
+	status := &domain.MaintenanceStatus{Jobs: []string{}, Deferred: deferred}
+	if m.window != nil {
+		now := m.now()
+		status.Window = m.window.String()
+		status.Open = m.window.Contains(now)
+		if !status.Open {
+			next := m.window.NextOpen(now)
+			status.NextOpenAt = &next
+		}
+		status.Jobs = append(status.Jobs, m.config.Jobs...)
+	}
+	m.mu.Lock()
+	status.LastRunAt, status.LastVacuumAt = m.lastRunAt, m.lastVacuumAt
+	m.mu.Unlock()
+	return status, nil
+}
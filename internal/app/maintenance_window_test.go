@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// fakeMaintenanceRepo holds downloads with deferred jobs and counts vacuums
+type fakeMaintenanceRepo struct {
+	deferred []*domain.Download
+	vacuums  int
+}
+
+func (r *fakeMaintenanceRepo) FindDeferred(limit int) ([]*domain.Download, error) {
+	var found []*domain.Download
+	for _, d := range r.deferred {
+		if d.Deferred != "" && len(found) < limit {
+			found = append(found, d)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeMaintenanceRepo) CountDeferred() (int64, error) {
+	found, _ := r.FindDeferred(len(r.deferred))
+	return int64(len(found)), nil
+}
+
+func (r *fakeMaintenanceRepo) Vacuum() error {
+	r.vacuums++
+	return nil
+}
+
+// clearingRunner clears the deferred jobs of the downloads it runs
+type clearingRunner struct {
+	ran []string
+}
+
+func (r *clearingRunner) RunDeferred(ctx context.Context, download *domain.Download) error {
+	r.ran = append(r.ran, download.ID)
+	download.Deferred = ""
+	return nil
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	deferred := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+	deferred.DeferJob(domain.MaintenanceTranscodes)
+	repo := &fakeMaintenanceRepo{deferred: []*domain.Download{deferred}}
+	runner := &clearingRunner{}
+	config := &domain.MaintenanceConfig{Window: "01:00-06:00", Jobs: domain.MaintenanceJobs}
+	m := NewMaintenanceWindow(config, repo, runner, nil)
+
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+
+	// Closed: jobs are held and nothing runs
+	assert.True(t, m.Holds(domain.MaintenanceTranscodes))
+	assert.Equal(t, 0, m.RunDue(context.Background()))
+	status, err := m.Status()
+	require.NoError(t, err)
+	assert.False(t, status.Open)
+	assert.Equal(t, int64(1), status.Deferred)
+	require.NotNil(t, status.NextOpenAt)
+	assert.Equal(t, time.Date(2026, 3, 11, 1, 0, 0, 0, time.Local), *status.NextOpenAt)
+
+	// Open: deferred jobs run and the database is vacuumed once
+	now = time.Date(2026, 3, 11, 1, 30, 0, 0, time.Local)
+	assert.False(t, m.Holds(domain.MaintenanceTranscodes))
+	assert.Equal(t, 1, m.RunDue(context.Background()))
+	assert.Equal(t, []string{deferred.ID}, runner.ran)
+	assert.Equal(t, 0, m.RunDue(context.Background()))
+	assert.Equal(t, 1, repo.vacuums, "once per window")
+
+	// The next night's window vacuums again
+	now = now.Add(24 * time.Hour)
+	m.RunDue(context.Background())
+	assert.Equal(t, 2, repo.vacuums)
+
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.True(t, status.Open)
+	assert.Nil(t, status.NextOpenAt)
+	assert.Equal(t, int64(0), status.Deferred)
+	assert.NotNil(t, status.LastRunAt)
+	assert.NotNil(t, status.LastVacuumAt)
+}
+
+func TestMaintenanceWindow_WithoutWindow(t *testing.T) {
+	repo := &fakeMaintenanceRepo{}
+	m := NewMaintenanceWindow(&domain.MaintenanceConfig{Jobs: domain.MaintenanceJobs}, repo, &clearingRunner{}, nil)
+
+	assert.False(t, m.Enabled())
+	assert.False(t, m.Holds(domain.MaintenanceChannelExports), "every job runs any time")
+	assert.Equal(t, 0, m.RunDue(context.Background()))
+	assert.Equal(t, 0, repo.vacuums)
+	status, err := m.Status()
+	require.NoError(t, err)
+	assert.Empty(t, status.Window)
+	assert.Empty(t, status.Jobs)
+}
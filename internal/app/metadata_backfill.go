@@ -0,0 +1,401 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// MetadataBackfiller periodically repairs completed downloads whose metadata
+// is missing a description or uploader. It re-resolves the missing fields from
+// the Telegram message cache (grouped/nearby text resolution) or from the
+// yt-dlp .info.json sidecar next to the media file. When the sidecars are
+// missing or empty, the download's tool is asked again (yt-dlp or gallery-dl
+// JSON dump, tdl chat export). It then updates both the DB record and the
+// per-file .info.json sidecars, writing the missing ones.
+//
+// This automates what the `regenerate-metadata` CLI command does manually.
+type MetadataBackfiller struct {
+	repo         domain.DownloadRepository
+	messageCache domain.TelegramMessageCacheRepository
+	config       *domain.MetadataConfig
+	multiLogger  *logger.MultiLogger
+	resolver     metadataResolver // Optional
+	mu           sync.Mutex
+	attempted    map[string]time.Time // downloadID -> UpdatedAt at last unresolved attempt
+}
+
+// NewMetadataBackfiller creates a new metadata backfill worker.
+// messageCache may be nil, in which case Telegram downloads are only repaired
+// from their on-disk sidecars.
+func NewMetadataBackfiller(
+	repo domain.DownloadRepository,
+	messageCache domain.TelegramMessageCacheRepository,
+	config *domain.MetadataConfig,
+	multiLogger *logger.MultiLogger,
+) *MetadataBackfiller {
+	return &MetadataBackfiller{
+		repo:         repo,
+		messageCache: messageCache,
+		config:       config,
+		multiLogger:  multiLogger,
+		attempted:    make(map[string]time.Time),
+	}
+}
+
+// metadataResolver fetches the metadata of a completed download again from its
+// platform's tool (DownloadManager.ResolveMetadata)
+type metadataResolver interface {
+	ResolveMetadata(ctx context.Context, download *domain.Download, files []string) (*domain.MediaMetadata, error)
+}
+
+// SetResolver sets the resolver of downloads whose sidecars are missing or
+// empty. Without one they are only repaired from the message cache.
+func (b *MetadataBackfiller) SetResolver(resolver metadataResolver) {
+	b.resolver = resolver
+}
+
+// Run runs a backfill pass immediately and then every BackfillInterval until
+// ctx is cancelled.
+func (b *MetadataBackfiller) Run(ctx context.Context) {
+	interval := b.config.BackfillInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := b.RunOnce(ctx); err != nil && b.multiLogger != nil {
+			b.multiLogger.LogAppError("Metadata backfill pass failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single backfill pass and returns the number of downloads updated.
+func (b *MetadataBackfiller) RunOnce(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	downloads, err := b.repo.FindAll(map[string]interface{}{
+		"status": domain.StatusCompleted,
+	}, domain.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list completed downloads: %w", err)
+	}
+
+	batchSize := b.config.BackfillBatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	updated := 0
+	for _, dl := range downloads {
+		if updated >= batchSize {
+			break
+		}
+		if dl.Metadata == "" {
+			continue
+		}
+		// Skip records that could not be resolved last time and haven't changed since.
+		if at, ok := b.attempted[dl.ID]; ok && !dl.UpdatedAt.After(at) {
+			continue
+		}
+
+		var metadata map[string]interface{}
+		if err := json.Unmarshal([]byte(dl.Metadata), &metadata); err != nil {
+			continue
+		}
+		if !needsBackfill(metadata) {
+			continue
+		}
+
+		changed := b.backfillDownload(ctx, dl, metadata)
+		if !changed {
+			b.attempted[dl.ID] = dl.UpdatedAt
+			continue
+		}
+
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			continue
+		}
+		dl.Metadata = string(data)
+		if err := b.repo.Update(dl); err != nil {
+			if b.multiLogger != nil {
+				b.multiLogger.LogAppError("Failed to update backfilled metadata",
+					zap.String("id", dl.ID),
+					zap.Error(err))
+			}
+			continue
+		}
+		delete(b.attempted, dl.ID)
+		updated++
+
+		if b.multiLogger != nil {
+			b.multiLogger.LogQueueEvent("metadata_backfilled",
+				zap.String("id", dl.ID),
+				zap.String("platform", string(dl.Platform)))
+		}
+	}
+
+	if b.multiLogger != nil && updated > 0 {
+		b.multiLogger.LogQueueEvent("metadata_backfill_pass_complete",
+			zap.Int("updated", updated))
+	}
+
+	return updated, nil
+}
+
+// needsBackfill reports whether a metadata map is missing a description or uploader.
+func needsBackfill(metadata map[string]interface{}) bool {
+	desc, _ := metadata["description"].(string)
+	uploader, _ := metadata["uploader"].(string)
+	return desc == "" || uploader == ""
+}
+
+// backfillDownload fills in missing description/uploader fields in metadata and
+// rewrites the per-file sidecars. Returns true if any field was filled.
+func (b *MetadataBackfiller) backfillDownload(ctx context.Context, dl *domain.Download, metadata map[string]interface{}) bool {
+	files := metadataFiles(metadata)
+	if len(files) == 0 && dl.FilePath != "" {
+		files = []string{dl.FilePath}
+	}
+
+	fields := map[string]string{}
+
+	// Telegram: resolve text from the message cache
+	if dl.Platform == domain.PlatformTelegram && b.messageCache != nil {
+		channelID, msgID := TelegramIDsFromDownload(dl, files)
+		if channelID != "" && msgID != "" {
+			if text := ResolveCachedMessageText(b.messageCache, channelID, msgID); text != "" {
+				fields["description"] = text
+			}
+		}
+	}
+
+	// Any platform: fall back to values present in the on-disk sidecars
+	// (yt-dlp writes rich .info.json files that may be newer than the DB record).
+	for _, file := range files {
+		sidecar := readSidecar(file)
+		if sidecar == nil {
+			continue
+		}
+		for _, key := range []string{"description", "uploader", "uploader_id"} {
+			if fields[key] != "" {
+				continue
+			}
+			if v, _ := sidecar[key].(string); v != "" {
+				fields[key] = v
+			}
+		}
+	}
+
+	// Sidecars missing or empty: ask the download's tool again
+	var resolved *domain.MediaMetadata
+	if b.resolver != nil && unresolvedFields(metadata, fields) && sidecarsMissing(files) {
+		meta, err := b.resolver.ResolveMetadata(ctx, dl, files)
+		if err != nil {
+			if b.multiLogger != nil {
+				b.multiLogger.LogAppError("Failed to resolve download metadata",
+					zap.String("id", dl.ID),
+					zap.Error(err))
+			}
+		} else {
+			resolved = meta
+			for key, value := range map[string]string{
+				"description": meta.Description,
+				"uploader":    meta.Uploader,
+				"uploader_id": meta.UploaderID,
+			} {
+				if fields[key] == "" && value != "" {
+					fields[key] = value
+				}
+			}
+		}
+	}
+
+	changed := false
+	for key, value := range fields {
+		if current, _ := metadata[key].(string); current == "" && value != "" {
+			metadata[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return false
+	}
+
+	for _, file := range files {
+		if resolved != nil && readSidecar(file) == nil {
+			if _, err := os.Stat(file); err == nil {
+				_ = infrastructure.WriteInfoJSON(file, resolved)
+			}
+		} else {
+			updateSidecar(file, fields)
+		}
+		if b.config.WriteDescriptionFile && fields["description"] != "" {
+			if _, err := os.Stat(infrastructure.DescriptionTxtPath(file)); os.IsNotExist(err) {
+				_ = infrastructure.WriteDescriptionTxt(file, fields["description"])
+			}
+		}
+	}
+	return true
+}
+
+// unresolvedFields reports whether the description or uploader is still
+// missing from both metadata and the resolved fields
+func unresolvedFields(metadata map[string]interface{}, fields map[string]string) bool {
+	for _, key := range []string{"description", "uploader"} {
+		if current, _ := metadata[key].(string); current == "" && fields[key] == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sidecarsMissing reports whether a file has no sidecar, an invalid one, or one
+// without a description and uploader (or there are no files at all)
+func sidecarsMissing(files []string) bool {
+	if len(files) == 0 {
+		return true
+	}
+	for _, file := range files {
+		sidecar := readSidecar(file)
+		desc, _ := sidecar["description"].(string)
+		uploader, _ := sidecar["uploader"].(string)
+		if desc == "" && uploader == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// metadataFiles returns the "files" list from a download metadata map.
+func metadataFiles(metadata map[string]interface{}) []string {
+	raw, ok := metadata["files"].([]interface{})
+	if !ok {
+		return nil
+	}
+	files := make([]string, 0, len(raw))
+	for _, f := range raw {
+		if s, ok := f.(string); ok && s != "" {
+			files = append(files, s)
+		}
+	}
+	return files
+}
+
+// sidecarPath returns the .info.json path for a media file.
+func sidecarPath(filePath string) string {
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".info.json"
+}
+
+// readSidecar reads and parses the .info.json sidecar for a media file.
+// Returns nil if the sidecar is missing or invalid.
+func readSidecar(filePath string) map[string]interface{} {
+	data, err := os.ReadFile(sidecarPath(filePath))
+	if err != nil {
+		return nil
+	}
+	var sidecar map[string]interface{}
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil
+	}
+	return sidecar
+}
+
+// updateSidecar fills empty fields in an existing .info.json sidecar. Best effort:
+// missing or unreadable sidecars are left untouched.
+func updateSidecar(filePath string, fields map[string]string) {
+	sidecar := readSidecar(filePath)
+	if sidecar == nil {
+		return
+	}
+	changed := false
+	for key, value := range fields {
+		if current, _ := sidecar[key].(string); current == "" && value != "" {
+			sidecar[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	data, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = infrastructure.WriteFileAtomic(sidecarPath(filePath), data, 0644)
+}
+
+// ResolveCachedMessageText looks up message text from the message cache,
+// using grouped message resolution and nearby message fallback (±3).
+func ResolveCachedMessageText(cache domain.TelegramMessageCacheRepository, channelID, messageID string) string {
+	cached, err := cache.GetMessage(channelID, messageID)
+	if err != nil {
+		return ""
+	}
+	if cached != nil && cached.Text != "" {
+		return cached.Text
+	}
+
+	// If message exists but has no text, try grouped message resolution
+	if cached != nil && cached.GroupedID != "" {
+		grouped, err := cache.GetMessagesByGroupedID(channelID, cached.GroupedID)
+		if err == nil {
+			for _, g := range grouped {
+				if g.Text != "" {
+					return g.Text
+				}
+			}
+		}
+	}
+
+	// Fallback: search nearby message IDs (±3) for text
+	nearby, err := cache.GetNearbyMessages(channelID, messageID, 3)
+	if err == nil {
+		for _, n := range nearby {
+			if n.Text != "" {
+				return n.Text
+			}
+		}
+	}
+
+	return ""
+}
+
+// TelegramIDsFromDownload extracts the channel ID and message ID for a Telegram download.
+// Tries the first file name ({channel_id}_{message_id}_{media_id}.{ext}) first,
+// then falls back to the URL (https://t.me/c/{channel_id}/{message_id}).
+func TelegramIDsFromDownload(dl *domain.Download, files []string) (channelID, msgID string) {
+	if len(files) > 0 {
+		if channelID, msgID = telegramIDsFromFilename(filepath.Base(files[0])); channelID != "" {
+			return channelID, msgID
+		}
+	}
+
+	parts := strings.Split(dl.URL, "/")
+	if len(parts) >= 5 && parts[3] == "c" {
+		return parts[4], parts[len(parts)-1]
+	}
+
+	return "", ""
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// backfillRepo returns all stored downloads from FindAll
+type backfillRepo struct {
+	*mockDownloadManagerRepo
+}
+
+func (r *backfillRepo) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
+	var result []*domain.Download
+	for _, d := range r.downloads {
+		if status, ok := filters["status"]; ok && d.Status != status {
+			continue
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// stubMessageCache implements domain.TelegramMessageCacheRepository for testing
+type stubMessageCache struct {
+	messages map[string]*domain.TelegramMessageCache
+}
+
+func (s *stubMessageCache) GetMessage(channelID, messageID string) (*domain.TelegramMessageCache, error) {
+	return s.messages[channelID+"/"+messageID], nil
+}
+func (s *stubMessageCache) SaveMessage(cache *domain.TelegramMessageCache) error    { return nil }
+func (s *stubMessageCache) SaveMessages(caches []domain.TelegramMessageCache) error { return nil }
+func (s *stubMessageCache) HasChannelCache(channelID string) (bool, error)          { return false, nil }
+func (s *stubMessageCache) GetMaxDate(channelID string) (int64, error)              { return 0, nil }
+func (s *stubMessageCache) GetMaxMessageID(channelID string) (int, error)           { return 0, nil }
+func (s *stubMessageCache) GetCachedMessages(channelID string) (map[string]bool, error) {
+	return nil, nil
+}
+func (s *stubMessageCache) GetMessagesByGroupedID(channelID, groupedID string) ([]domain.TelegramMessageCache, error) {
+	return nil, nil
+}
+func (s *stubMessageCache) GetNearbyMessages(channelID, messageID string, msgRange int) ([]domain.TelegramMessageCache, error) {
+	return nil, nil
+}
+
+func TestMetadataBackfiller_TelegramFromCache(t *testing.T) {
+	dir := t.TempDir()
+	mediaPath := filepath.Join(dir, "3464638440_1907_abc.mp4")
+	require.NoError(t, os.WriteFile(mediaPath, []byte("x"), 0644))
+	sidecar := map[string]interface{}{"description": "", "uploader": "chan"}
+	data, _ := json.Marshal(sidecar)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3464638440_1907_abc.info.json"), data, 0644))
+
+	meta, _ := json.Marshal(map[string]interface{}{
+		"description": "",
+		"uploader":    "chan",
+		"files":       []string{mediaPath},
+	})
+	repo := &backfillRepo{newMockDownloadManagerRepo()}
+	dl := &domain.Download{
+		ID:       "tg-1",
+		URL:      "https://t.me/c/3464638440/1907",
+		Platform: domain.PlatformTelegram,
+		Status:   domain.StatusCompleted,
+		FilePath: mediaPath,
+		Metadata: string(meta),
+	}
+	repo.Create(dl)
+
+	cache := &stubMessageCache{messages: map[string]*domain.TelegramMessageCache{
+		"3464638440/1907": {ChannelID: "3464638440", MessageID: "1907", Text: "hello world"},
+	}}
+
+	b := NewMetadataBackfiller(repo, cache, &domain.MetadataConfig{BackfillBatchSize: 10}, nil)
+	updated, err := b.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(dl.Metadata), &result))
+	assert.Equal(t, "hello world", result["description"])
+
+	onDisk := readSidecar(mediaPath)
+	require.NotNil(t, onDisk)
+	assert.Equal(t, "hello world", onDisk["description"])
+}
+
+func TestMetadataBackfiller_FromYTDLPSidecar(t *testing.T) {
+	dir := t.TempDir()
+	mediaPath := filepath.Join(dir, "user_123.mp4")
+	sidecar := map[string]interface{}{"description": "tweet text", "uploader": "User"}
+	data, _ := json.Marshal(sidecar)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user_123.info.json"), data, 0644))
+
+	meta, _ := json.Marshal(map[string]interface{}{"description": "", "uploader": ""})
+	repo := &backfillRepo{newMockDownloadManagerRepo()}
+	dl := &domain.Download{
+		ID:       "x-1",
+		URL:      "https://x.com/user/status/123",
+		Platform: domain.PlatformX,
+		Status:   domain.StatusCompleted,
+		FilePath: mediaPath,
+		Metadata: string(meta),
+	}
+	repo.Create(dl)
+
+	b := NewMetadataBackfiller(repo, nil, &domain.MetadataConfig{WriteDescriptionFile: true}, nil)
+	updated, err := b.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(dl.Metadata), &result))
+	assert.Equal(t, "tweet text", result["description"])
+	assert.Equal(t, "User", result["uploader"])
+
+	txt, err := os.ReadFile(filepath.Join(dir, "user_123.description.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "tweet text\n", string(txt))
+}
+
+// stubMetadataResolver returns meta for every download
+type stubMetadataResolver struct {
+	meta  *domain.MediaMetadata
+	calls int
+}
+
+func (s *stubMetadataResolver) ResolveMetadata(ctx context.Context, download *domain.Download, files []string) (*domain.MediaMetadata, error) {
+	s.calls++
+	return s.meta, nil
+}
+
+func TestMetadataBackfiller_ResolvesMissingSidecar(t *testing.T) {
+	dir := t.TempDir()
+	mediaPath := filepath.Join(dir, "user_789.mp4")
+	require.NoError(t, os.WriteFile(mediaPath, []byte("x"), 0644))
+
+	meta, _ := json.Marshal(map[string]interface{}{"description": "", "uploader": ""})
+	repo := &backfillRepo{newMockDownloadManagerRepo()}
+	dl := &domain.Download{
+		ID:       "x-3",
+		URL:      "https://x.com/user/status/789",
+		Platform: domain.PlatformX,
+		Status:   domain.StatusCompleted,
+		FilePath: mediaPath,
+		Metadata: string(meta),
+	}
+	repo.Create(dl)
+
+	resolver := &stubMetadataResolver{meta: &domain.MediaMetadata{
+		ID: "789", Description: "fresh text", Uploader: "User", UploaderID: "user",
+	}}
+	b := NewMetadataBackfiller(repo, nil, &domain.MetadataConfig{}, nil)
+	b.SetResolver(resolver)
+	updated, err := b.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, 1, resolver.calls)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(dl.Metadata), &result))
+	assert.Equal(t, "fresh text", result["description"])
+	assert.Equal(t, "User", result["uploader"])
+	assert.Equal(t, "user", result["uploader_id"])
+
+	sidecar := readSidecar(mediaPath)
+	require.NotNil(t, sidecar, "the missing sidecar is written")
+	assert.Equal(t, "fresh text", sidecar["description"])
+
+	// Complete sidecars are not resolved again
+	dl.Metadata = string(meta)
+	dl.UpdatedAt = dl.UpdatedAt.Add(time.Second)
+	_, err = b.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolver.calls)
+}
+
+func TestMetadataBackfiller_SkipsUnresolvableUntilChanged(t *testing.T) {
+	meta, _ := json.Marshal(map[string]interface{}{"description": "", "uploader": ""})
+	repo := &backfillRepo{newMockDownloadManagerRepo()}
+	dl := &domain.Download{
+		ID:       "x-2",
+		URL:      "https://x.com/user/status/456",
+		Platform: domain.PlatformX,
+		Status:   domain.StatusCompleted,
+		FilePath: filepath.Join(t.TempDir(), "missing.mp4"),
+		Metadata: string(meta),
+	}
+	repo.Create(dl)
+
+	b := NewMetadataBackfiller(repo, nil, &domain.MetadataConfig{}, nil)
+	updated, err := b.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated)
+	assert.Contains(t, b.attempted, "x-2")
+}
+
+func TestTelegramIDsFromDownload(t *testing.T) {
+	dl := &domain.Download{URL: "https://t.me/c/111/222"}
+
+	channelID, msgID := TelegramIDsFromDownload(dl, []string{"/tmp/333_444_555.jpg"})
+	assert.Equal(t, "333", channelID)
+	assert.Equal(t, "444", msgID)
+
+	channelID, msgID = TelegramIDsFromDownload(dl, nil)
+	assert.Equal(t, "111", channelID)
+	assert.Equal(t, "222", msgID)
+
+	channelID, msgID = TelegramIDsFromDownload(&domain.Download{URL: "https://t.me/public/5"}, nil)
+	assert.Empty(t, channelID)
+	assert.Empty(t, msgID)
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// Phases of a metadata regeneration
+const (
+	RegeneratePhaseFiles     = "files"     // Updating .info.json sidecars in the completed directory
+	RegeneratePhaseDownloads = "downloads" // Updating the metadata of download records
+)
+
+// RegenerateMetadataStatus is the progress of the current or last metadata
+// regeneration
+type RegenerateMetadataStatus struct {
+	Running          bool       `json:"running"`
+	DryRun           bool       `json:"dry_run"`
+	Phase            string     `json:"phase,omitempty"`
+	Total            int        `json:"total"` // Sidecars or downloads to check in this phase
+	Checked          int        `json:"checked"`
+	FilesUpdated     int        `json:"files_updated"`
+	DownloadsUpdated int        `json:"downloads_updated"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// MetadataRegenerator fills in empty descriptions of Telegram downloads from
+// the message cache, for every channel: first in the .info.json sidecars under
+// the completed directory, then in the metadata of completed downloads. Text
+// is resolved like the metadata backfill does (grouped and nearby messages).
+// Nothing is downloaded again. One regeneration runs at a time, in the
+// background; Status reports its progress.
+type MetadataRegenerator struct {
+	repo         domain.DownloadRepository
+	messageCache domain.TelegramMessageCacheRepository
+	completedDir string
+	multiLogger  *logger.MultiLogger
+	mu           sync.Mutex
+	status       RegenerateMetadataStatus
+}
+
+// NewMetadataRegenerator creates a metadata regenerator for the sidecars under
+// completedDir
+func NewMetadataRegenerator(
+	repo domain.DownloadRepository,
+	messageCache domain.TelegramMessageCacheRepository,
+	completedDir string,
+	multiLogger *logger.MultiLogger,
+) *MetadataRegenerator {
+	return &MetadataRegenerator{
+		repo:         repo,
+		messageCache: messageCache,
+		completedDir: completedDir,
+		multiLogger:  multiLogger,
+	}
+}
+
+// Start starts a regeneration in the background. With dryRun nothing is
+// written; the counts are what would be updated. Returns false when a
+// regeneration is already running.
+func (g *MetadataRegenerator) Start(dryRun bool) (RegenerateMetadataStatus, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.status.Running {
+		return g.status, false
+	}
+	now := time.Now()
+	g.status = RegenerateMetadataStatus{Running: true, DryRun: dryRun, StartedAt: &now}
+	go g.run(dryRun)
+	return g.status, true
+}
+
+// Status returns the progress of the current or last regeneration
+func (g *MetadataRegenerator) Status() RegenerateMetadataStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
+
+// update changes the status under the lock
+func (g *MetadataRegenerator) update(change func(status *RegenerateMetadataStatus)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	change(&g.status)
+}
+
+func (g *MetadataRegenerator) run(dryRun bool) {
+	err := g.regenerateSidecars(dryRun)
+	if err == nil {
+		err = g.regenerateDownloads(dryRun)
+	}
+
+	now := time.Now()
+	g.update(func(status *RegenerateMetadataStatus) {
+		status.Running = false
+		status.FinishedAt = &now
+		if err != nil {
+			status.Error = err.Error()
+		}
+	})
+	if g.multiLogger == nil {
+		return
+	}
+	if err != nil {
+		g.multiLogger.LogAppError("Metadata regeneration failed", zap.Error(err))
+		return
+	}
+	status := g.Status()
+	g.multiLogger.LogQueueEvent("metadata_regenerated",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("files_updated", status.FilesUpdated),
+		zap.Int("downloads_updated", status.DownloadsUpdated))
+}
+
+// regenerateSidecars fills in empty descriptions of the Telegram .info.json
+// sidecars under the completed directory, including organize_by subdirectories
+func (g *MetadataRegenerator) regenerateSidecars(dryRun bool) error {
+	var sidecars []string
+	err := filepath.WalkDir(g.completedDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".info.json") {
+			sidecars = append(sidecars, path)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read completed dir: %w", err)
+	}
+	g.update(func(status *RegenerateMetadataStatus) {
+		status.Phase, status.Total, status.Checked = RegeneratePhaseFiles, len(sidecars), 0
+	})
+
+	for _, path := range sidecars {
+		updated := g.regenerateSidecar(path, dryRun)
+		g.update(func(status *RegenerateMetadataStatus) {
+			status.Checked++
+			if updated {
+				status.FilesUpdated++
+			}
+		})
+	}
+	return nil
+}
+
+// regenerateSidecar fills in the description of one sidecar named
+// {channel_id}_{message_id}_*.info.json, reporting whether it was (or in a dry
+// run would be) updated
+func (g *MetadataRegenerator) regenerateSidecar(path string, dryRun bool) bool {
+	channelID, msgID := telegramIDsFromFilename(filepath.Base(path))
+	if channelID == "" || msgID == "" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var sidecar map[string]interface{}
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return false
+	}
+	if desc, _ := sidecar["description"].(string); desc != "" {
+		return false
+	}
+
+	text := ResolveCachedMessageText(g.messageCache, channelID, msgID)
+	// Albums are saved under the media's message; the sidecar's id is the
+	// message the download was added with
+	if urlMsgID, ok := sidecar["id"].(string); text == "" && ok && urlMsgID != msgID {
+		text = ResolveCachedMessageText(g.messageCache, channelID, urlMsgID)
+	}
+	if text == "" {
+		return false
+	}
+	if dryRun {
+		return true
+	}
+
+	sidecar["description"] = text
+	data, err = json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return false
+	}
+	if err := infrastructure.WriteFileAtomic(path, data, 0644); err != nil {
+		if g.multiLogger != nil {
+			g.multiLogger.LogAppError("Failed to write regenerated metadata", zap.String("path", path), zap.Error(err))
+		}
+		return false
+	}
+	return true
+}
+
+// regenerateDownloads fills in empty descriptions in the metadata of
+// completed Telegram downloads
+func (g *MetadataRegenerator) regenerateDownloads(dryRun bool) error {
+	downloads, err := g.repo.FindAll(map[string]interface{}{
+		"platform": domain.PlatformTelegram,
+		"status":   domain.StatusCompleted,
+	}, domain.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list completed downloads: %w", err)
+	}
+	g.update(func(status *RegenerateMetadataStatus) {
+		status.Phase, status.Total, status.Checked = RegeneratePhaseDownloads, len(downloads), 0
+	})
+
+	for _, dl := range downloads {
+		updated := g.regenerateDownload(dl, dryRun)
+		g.update(func(status *RegenerateMetadataStatus) {
+			status.Checked++
+			if updated {
+				status.DownloadsUpdated++
+			}
+		})
+	}
+	return nil
+}
+
+// regenerateDownload fills in the description of one download's metadata,
+// reporting whether it was (or in a dry run would be) updated
+func (g *MetadataRegenerator) regenerateDownload(dl *domain.Download, dryRun bool) bool {
+	if dl.Metadata == "" {
+		return false
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(dl.Metadata), &metadata); err != nil {
+		return false
+	}
+	if desc, _ := metadata["description"].(string); desc != "" {
+		return false
+	}
+
+	channelID, msgID := TelegramIDsFromDownload(dl, metadataFiles(metadata))
+	if channelID == "" || msgID == "" {
+		return false
+	}
+	text := ResolveCachedMessageText(g.messageCache, channelID, msgID)
+	if text == "" {
+		return false
+	}
+	if dryRun {
+		return true
+	}
+
+	metadata["description"] = text
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return false
+	}
+	dl.Metadata = string(data)
+	if err := g.repo.Update(dl); err != nil {
+		if g.multiLogger != nil {
+			g.multiLogger.LogAppError("Failed to update regenerated metadata", zap.String("id", dl.ID), zap.Error(err))
+		}
+		return false
+	}
+	return true
+}
+
+// telegramIDsFromFilename extracts the channel and message ID from a Telegram
+// file name ({channel_id}_{message_id}_{media_id}.{ext}, or its .info.json
+// sidecar). Returns empty strings when the name does not start with a numeric
+// channel ID.
+func telegramIDsFromFilename(name string) (channelID, msgID string) {
+	name = strings.TrimSuffix(name, ".info.json")
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	if _, err := strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+func newRegenerateTestRepo(t *testing.T) *infrastructure.SQLiteDownloadRepository {
+	t.Helper()
+	repo, err := infrastructure.NewSQLiteDownloadRepository(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestTelegramIDsFromFilename(t *testing.T) {
+	tests := []struct {
+		name      string
+		channelID string
+		msgID     string
+	}{
+		{"3464638440_1907_blacktiger88-15-11-2025-0i57ki4zky2rp84sk21ht_source.m4v", "3464638440", "1907"},
+		{"3464638440_1907_blacktiger88-15-11-2025-0i57ki4zky2rp84sk21ht_source.info.json", "3464638440", "1907"},
+		{"9876543210_42.info.json", "9876543210", "42"},
+		{"somechannel_1907_media.m4v", "", ""},
+		{"singlepart.m4v", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		channelID, msgID := telegramIDsFromFilename(tt.name)
+		assert.Equal(t, tt.channelID, channelID, tt.name)
+		assert.Equal(t, tt.msgID, msgID, tt.name)
+	}
+}
+
+func TestResolveCachedMessageText(t *testing.T) {
+	repo := newRegenerateTestRepo(t)
+	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
+		{ChannelID: "123", MessageID: "100", Text: "Hello world"},
+		// Album: 1906 has no text, 1907 of the same group has
+		{ChannelID: "3464638440", MessageID: "1906", Text: "", GroupedID: "14126963880319333"},
+		{ChannelID: "3464638440", MessageID: "1907", Text: "Kengo系列六期。#DJ0005", GroupedID: "14126963880319333"},
+		// No group, but nearby message 52 has text
+		{ChannelID: "123", MessageID: "50", Text: ""},
+		{ChannelID: "123", MessageID: "52", Text: "Nearby text"},
+		{ChannelID: "456", MessageID: "50", Text: ""},
+	}))
+
+	assert.Equal(t, "Hello world", ResolveCachedMessageText(repo, "123", "100"))
+	assert.Equal(t, "Kengo系列六期。#DJ0005", ResolveCachedMessageText(repo, "3464638440", "1906"))
+	assert.Equal(t, "Nearby text", ResolveCachedMessageText(repo, "123", "50"))
+	assert.Equal(t, "", ResolveCachedMessageText(repo, "456", "50"), "no text anywhere nearby")
+	assert.Equal(t, "", ResolveCachedMessageText(repo, "123", "999"), "not in the cache")
+}
+
+func TestMetadataRegenerator_AllChannels(t *testing.T) {
+	repo := newRegenerateTestRepo(t)
+	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
+		{ChannelID: "111", MessageID: "1", Text: "first channel"},
+		{ChannelID: "222", MessageID: "7", Text: "second channel"},
+	}))
+
+	completedDir := t.TempDir()
+	sidecar := filepath.Join(completedDir, "someuser", "111_1_abc.info.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(sidecar), 0755))
+	require.NoError(t, os.WriteFile(sidecar, []byte(`{"description": ""}`), 0644))
+
+	dl := domain.NewDownload("https://t.me/c/222/7", domain.PlatformTelegram, domain.ModeDefault)
+	dl.Metadata = `{"description": ""}`
+	completeDownload(dl, "")
+	require.NoError(t, repo.Create(dl))
+
+	g := NewMetadataRegenerator(repo, repo, completedDir, nil)
+	wait := func() RegenerateMetadataStatus {
+		require.Eventually(t, func() bool { return !g.Status().Running }, 5*time.Second, 10*time.Millisecond)
+		return g.Status()
+	}
+
+	// A dry run counts without writing
+	_, started := g.Start(true)
+	require.True(t, started)
+	status := wait()
+	assert.Empty(t, status.Error)
+	assert.Equal(t, 1, status.FilesUpdated)
+	assert.Equal(t, 1, status.DownloadsUpdated)
+	data, err := os.ReadFile(sidecar)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"description": ""}`, string(data))
+
+	_, started = g.Start(false)
+	require.True(t, started)
+	status = wait()
+	assert.Equal(t, RegeneratePhaseDownloads, status.Phase)
+	assert.Equal(t, 1, status.Checked)
+	assert.NotNil(t, status.FinishedAt)
+
+	var meta map[string]interface{}
+	data, err = os.ReadFile(sidecar)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &meta))
+	assert.Equal(t, "first channel", meta["description"])
+
+	found, err := repo.FindByID(dl.ID)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(found.Metadata), &meta))
+	assert.Equal(t, "second channel", meta["description"])
+}
@@ -0,0 +1,307 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the download duration
+// histogram: from single images to hour-long recordings
+var durationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600, 7200}
+
+// fileSizeBuckets are the upper bounds, in bytes, of the file size histogram
+var fileSizeBuckets = []float64{
+	1 << 20, 10 << 20, 50 << 20, 100 << 20, 500 << 20, 1 << 30, 5 << 30, 10 << 30,
+}
+
+// metricsRepository is the persistence read by Metrics for the queue gauges
+type metricsRepository interface {
+	CountByStatus(status domain.DownloadStatus) (int64, error)
+}
+
+// platformHealthSource reports the degraded platforms for the degraded gauges
+type platformHealthSource interface {
+	Check() []domain.PlatformHealth
+}
+
+// histogram is a cumulative Prometheus histogram
+type histogram struct {
+	counts []int64 // Observations <= the bucket bound, per bucket
+	count  int64
+	sum    float64
+}
+
+func (h *histogram) observe(buckets []float64, value float64) {
+	if h.counts == nil {
+		h.counts = make([]int64, len(buckets))
+	}
+	for i, bound := range buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// outcomeKey labels the downloads counter
+type outcomeKey struct {
+	platform domain.Platform
+	status   domain.DownloadStatus
+}
+
+// Metrics collects download metrics of this server and writes them in the
+// Prometheus text format: finished downloads by platform and status, download
+// duration and file size histograms by platform, external tool failures,
+// queue depth gauges read from the repository, and platform degraded gauges
+// when a health source is set. Counters start at zero when the server starts.
+// A nil *Metrics records nothing.
+type Metrics struct {
+	repo         metricsRepository
+	mu           sync.Mutex
+	downloads    map[outcomeKey]int64
+	durations    map[domain.Platform]*histogram
+	fileSizes    map[domain.Platform]*histogram
+	toolFailures map[string]int64
+
+	// Times of the failed downloads of the last RepeatedFailureWindow
+	failures map[domain.Platform][]time.Time
+	health   platformHealthSource
+	now      func() time.Time
+}
+
+// NewMetrics creates a metrics collector reading the queue gauges from repo
+func NewMetrics(repo metricsRepository) *Metrics {
+	return &Metrics{
+		repo:         repo,
+		downloads:    make(map[outcomeKey]int64),
+		durations:    make(map[domain.Platform]*histogram),
+		fileSizes:    make(map[domain.Platform]*histogram),
+		toolFailures: make(map[string]int64),
+		failures:     make(map[domain.Platform][]time.Time),
+		now:          time.Now,
+	}
+}
+
+// SetPlatformHealth sets the source of the platform degraded gauges
+func (m *Metrics) SetPlatformHealth(health platformHealthSource) {
+	m.health = health
+}
+
+// RecentFailures returns the number of downloads of platform that failed
+// within window
+func (m *Metrics) RecentFailures(platform domain.Platform, window time.Duration) int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	since := m.now().Add(-window)
+	count := 0
+	for _, failed := range m.failures[platform] {
+		if failed.After(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// DownloadFinished records a download that completed, failed or was cancelled.
+// Completed downloads are also observed in the duration and size histograms.
+func (m *Metrics) DownloadFinished(download *domain.Download) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.downloads[outcomeKey{download.Platform, download.Status}]++
+	if download.Status == domain.StatusFailed {
+		m.recordFailure(download.Platform)
+	}
+	if download.Status != domain.StatusCompleted {
+		return
+	}
+	if download.StartedAt != nil && download.CompletedAt != nil {
+		h, ok := m.durations[download.Platform]
+		if !ok {
+			h = &histogram{}
+			m.durations[download.Platform] = h
+		}
+		h.observe(durationBuckets, download.CompletedAt.Sub(*download.StartedAt).Seconds())
+	}
+	h, ok := m.fileSizes[download.Platform]
+	if !ok {
+		h = &histogram{}
+		m.fileSizes[download.Platform] = h
+	}
+	h.observe(fileSizeBuckets, float64(download.FileSize))
+}
+
+// recordFailure remembers when a download of platform failed, forgetting
+// failures older than RepeatedFailureWindow
+func (m *Metrics) recordFailure(platform domain.Platform) {
+	now := m.now()
+	since := now.Add(-domain.RepeatedFailureWindow)
+	recent := m.failures[platform][:0]
+	for _, failed := range m.failures[platform] {
+		if failed.After(since) {
+			recent = append(recent, failed)
+		}
+	}
+	m.failures[platform] = append(recent, now)
+}
+
+// AttemptFailed records a failed download attempt, counting it against the
+// external tool when err is a domain.ToolError
+func (m *Metrics) AttemptFailed(err error) {
+	if m == nil {
+		return
+	}
+	var toolErr *domain.ToolError
+	if !errors.As(err, &toolErr) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toolFailures[toolErr.Tool]++
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	queued, err := m.repo.CountByStatus(domain.StatusQueued)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count queued downloads: %w", err)
+	}
+	var active int64
+	for _, status := range []domain.DownloadStatus{domain.StatusProcessing, domain.StatusRecording} {
+		count, err := m.repo.CountByStatus(status)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count %s downloads: %w", status, err)
+		}
+		active += count
+	}
+	var health []domain.PlatformHealth
+	if m.health != nil {
+		health = m.health.Check()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mw := &metricsWriter{w: w}
+	mw.header("x_extract_downloads_total", "counter", "Downloads finished by this server, by platform and final status.")
+	keys := make([]outcomeKey, 0, len(m.downloads))
+	for key := range m.downloads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].platform != keys[j].platform {
+			return keys[i].platform < keys[j].platform
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, key := range keys {
+		mw.sample("x_extract_downloads_total",
+			fmt.Sprintf(`platform=%q,status=%q`, key.platform, key.status), float64(m.downloads[key]))
+	}
+
+	mw.histograms("x_extract_download_duration_seconds",
+		"Time from start to completion of completed downloads.", durationBuckets, m.durations)
+	mw.histograms("x_extract_download_file_size_bytes",
+		"Total file size of completed downloads.", fileSizeBuckets, m.fileSizes)
+
+	mw.header("x_extract_tool_failures_total", "counter", "Failed download attempts by external tool.")
+	tools := make([]string, 0, len(m.toolFailures))
+	for tool := range m.toolFailures {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		mw.sample("x_extract_tool_failures_total", fmt.Sprintf(`tool=%q`, tool), float64(m.toolFailures[tool]))
+	}
+
+	build := domain.CurrentBuild()
+	mw.header("x_extract_build_info", "gauge", "Always 1; the labels identify the build of the server.")
+	mw.sample("x_extract_build_info", fmt.Sprintf(`version=%q,commit=%q,build_date=%q,go_version=%q`,
+		build.Version, build.Commit, build.BuildDate, build.GoVersion), 1)
+
+	mw.header("x_extract_queue_depth", "gauge", "Downloads waiting in the queue.")
+	mw.sample("x_extract_queue_depth", "", float64(queued))
+	mw.header("x_extract_downloads_active", "gauge", "Downloads being downloaded or recorded.")
+	mw.sample("x_extract_downloads_active", "", float64(active))
+
+	if m.health != nil {
+		mw.header("x_extract_platform_degraded", "gauge",
+			"1 when downloads of the platform are likely to fail, by reason (cookie_expired, binary_missing, repeated_failures).")
+		for _, h := range health {
+			for _, reason := range domain.DegradedReasons {
+				value := 0.0
+				if h.HasReason(reason) {
+					value = 1
+				}
+				mw.sample("x_extract_platform_degraded", fmt.Sprintf(`platform=%q,reason=%q`, h.Platform, reason), value)
+			}
+		}
+		mw.header("x_extract_platform_recent_failures", "gauge", "Downloads of the platform that failed in the last hour.")
+		for _, h := range health {
+			mw.sample("x_extract_platform_recent_failures", fmt.Sprintf(`platform=%q`, h.Platform), float64(h.RecentFailures))
+		}
+	}
+	return mw.n, mw.err
+}
+
+// metricsWriter writes Prometheus text lines, keeping the first error
+type metricsWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (mw *metricsWriter) printf(format string, args ...interface{}) {
+	if mw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(mw.w, format, args...)
+	mw.n += int64(n)
+	mw.err = err
+}
+
+func (mw *metricsWriter) header(name, kind, help string) {
+	mw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (mw *metricsWriter) sample(name, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	mw.printf("%s %s\n", name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// histograms writes one histogram per platform
+func (mw *metricsWriter) histograms(name, help string, buckets []float64, byPlatform map[domain.Platform]*histogram) {
+	mw.header(name, "histogram", help)
+	platforms := make([]domain.Platform, 0, len(byPlatform))
+	for platform := range byPlatform {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i] < platforms[j] })
+
+	for _, platform := range platforms {
+		h := byPlatform[platform]
+		label := fmt.Sprintf(`platform=%q`, platform)
+		for i, bound := range buckets {
+			mw.sample(name+"_bucket", fmt.Sprintf(`%s,le="%s"`, label, strconv.FormatFloat(bound, 'f', -1, 64)), float64(h.counts[i]))
+		}
+		mw.sample(name+"_bucket", label+`,le="+Inf"`, float64(h.count))
+		mw.sample(name+"_sum", label, h.sum)
+		mw.sample(name+"_count", label, float64(h.count))
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// statusCountRepo returns fixed counts per status
+type statusCountRepo map[domain.DownloadStatus]int64
+
+func (r statusCountRepo) CountByStatus(status domain.DownloadStatus) (int64, error) {
+	return r[status], nil
+}
+
+func TestMetrics_WriteTo(t *testing.T) {
+	metrics := NewMetrics(statusCountRepo{
+		domain.StatusQueued:     3,
+		domain.StatusProcessing: 1,
+		domain.StatusRecording:  1,
+	})
+
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	metrics.DownloadFinished(&domain.Download{
+		Platform:    domain.PlatformX,
+		Status:      domain.StatusCompleted,
+		StartedAt:   &started,
+		CompletedAt: &completed,
+		FileSize:    20 << 20,
+	})
+	metrics.DownloadFinished(&domain.Download{Platform: domain.PlatformTelegram, Status: domain.StatusFailed})
+	metrics.AttemptFailed(&domain.RateLimitError{Err: &domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1")}})
+	metrics.AttemptFailed(&domain.ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1")})
+	metrics.AttemptFailed(errors.New("not a tool failure"))
+
+	var buf bytes.Buffer
+	_, err := metrics.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE x_extract_downloads_total counter\n")
+	assert.Contains(t, out, `x_extract_downloads_total{platform="telegram",status="failed"} 1`+"\n")
+	assert.Contains(t, out, `x_extract_downloads_total{platform="x",status="completed"} 1`+"\n")
+	assert.Contains(t, out, `x_extract_download_duration_seconds_bucket{platform="x",le="60"} 0`+"\n")
+	assert.Contains(t, out, `x_extract_download_duration_seconds_bucket{platform="x",le="120"} 1`+"\n")
+	assert.Contains(t, out, `x_extract_download_duration_seconds_sum{platform="x"} 90`+"\n")
+	assert.Contains(t, out, `x_extract_download_file_size_bytes_bucket{platform="x",le="10485760"} 0`+"\n")
+	assert.Contains(t, out, `x_extract_download_file_size_bytes_bucket{platform="x",le="52428800"} 1`+"\n")
+	assert.Contains(t, out, `x_extract_download_file_size_bytes_bucket{platform="x",le="+Inf"} 1`+"\n")
+	assert.Contains(t, out, `x_extract_tool_failures_total{tool="yt-dlp"} 2`+"\n")
+	assert.Contains(t, out, `x_extract_build_info{version="`+domain.Version+`",`)
+	assert.Contains(t, out, "x_extract_queue_depth 3\n")
+	assert.Contains(t, out, "x_extract_downloads_active 2\n")
+	assert.NotContains(t, out, `platform="telegram",le=`, "only completed downloads are observed")
+}
+
+// fixedPlatformHealth is a platform health source with preset results
+type fixedPlatformHealth []domain.PlatformHealth
+
+func (h fixedPlatformHealth) Check() []domain.PlatformHealth { return h }
+
+func TestMetrics_RecentFailures(t *testing.T) {
+	metrics := NewMetrics(statusCountRepo{})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	metrics.now = func() time.Time { return now }
+
+	metrics.DownloadFinished(&domain.Download{Platform: domain.PlatformX, Status: domain.StatusFailed})
+	now = now.Add(45 * time.Minute)
+	metrics.DownloadFinished(&domain.Download{Platform: domain.PlatformX, Status: domain.StatusFailed})
+	metrics.DownloadFinished(&domain.Download{Platform: domain.PlatformX, Status: domain.StatusCancelled})
+	assert.Equal(t, 2, metrics.RecentFailures(domain.PlatformX, time.Hour))
+
+	now = now.Add(30 * time.Minute)
+	assert.Equal(t, 1, metrics.RecentFailures(domain.PlatformX, time.Hour))
+	assert.Zero(t, metrics.RecentFailures(domain.PlatformTelegram, time.Hour))
+}
+
+func TestMetrics_WriteToPlatformDegraded(t *testing.T) {
+	metrics := NewMetrics(statusCountRepo{})
+	metrics.SetPlatformHealth(fixedPlatformHealth{
+		domain.NewPlatformHealth(domain.PlatformTelegram, "", []string{"tdl"}, 1),
+		domain.NewPlatformHealth(domain.PlatformX, domain.CookieOK, nil, 0),
+	})
+
+	var buf bytes.Buffer
+	_, err := metrics.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE x_extract_platform_degraded gauge\n")
+	assert.Contains(t, out, `x_extract_platform_degraded{platform="telegram",reason="binary_missing"} 1`+"\n")
+	assert.Contains(t, out, `x_extract_platform_degraded{platform="telegram",reason="cookie_expired"} 0`+"\n")
+	assert.Contains(t, out, `x_extract_platform_degraded{platform="x",reason="repeated_failures"} 0`+"\n")
+	assert.Contains(t, out, `x_extract_platform_recent_failures{platform="telegram"} 1`+"\n")
+}
+
+func TestMetrics_NilRecordsNothing(t *testing.T) {
+	var metrics *Metrics
+	assert.NotPanics(t, func() {
+		metrics.DownloadFinished(&domain.Download{Status: domain.StatusCompleted})
+		metrics.AttemptFailed(&domain.ToolError{Tool: "tdl", Err: errors.New("exit status 1")})
+	})
+}
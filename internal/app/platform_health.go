@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"os/exec"
+	"sort"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// PlatformHealthChecker tells which platforms are degraded: their cookies
+// expired, a tool they need is missing, or their downloads keep failing. It
+// backs the degraded gauges of /metrics and the platforms of /health.
+type PlatformHealthChecker struct {
+	binaries map[domain.Platform][]string
+	cookies  map[domain.Platform]*CookieMonitor
+	metrics  *Metrics
+	lookPath func(file string) (string, error)
+}
+
+// NewPlatformHealthChecker creates a checker for the platforms of binaries,
+// the tool paths each platform runs. cookies are the platforms with a cookie
+// check; metrics counts the recent failures.
+func NewPlatformHealthChecker(binaries map[domain.Platform][]string, cookies map[domain.Platform]*CookieMonitor, metrics *Metrics) *PlatformHealthChecker {
+	return &PlatformHealthChecker{
+		binaries: binaries,
+		cookies:  cookies,
+		metrics:  metrics,
+		lookPath: exec.LookPath,
+	}
+}
+
+// Check returns the health of every platform, by platform name
+func (c *PlatformHealthChecker) Check() []domain.PlatformHealth {
+	platforms := make([]domain.Platform, 0, len(c.binaries))
+	for platform := range c.binaries {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i] < platforms[j] })
+
+	health := make([]domain.PlatformHealth, 0, len(platforms))
+	for _, platform := range platforms {
+		var cookie domain.CookieState
+		if monitor, ok := c.cookies[platform]; ok {
+			cookie = monitor.Status().State
+		}
+		var missing []string
+		for _, binary := range c.binaries[platform] {
+			if _, err := c.lookPath(binary); err != nil {
+				missing = append(missing, binary)
+			}
+		}
+		recent := c.metrics.RecentFailures(platform, domain.RepeatedFailureWindow)
+		health = append(health, domain.NewPlatformHealth(platform, cookie, missing, recent))
+	}
+	return health
+}
+
+// CheckCookies checks the cookies of platform now. ok is false for platforms
+// without a cookie check.
+func (c *PlatformHealthChecker) CheckCookies(ctx context.Context, platform domain.Platform) (status domain.CookieStatus, ok bool) {
+	monitor, ok := c.cookies[platform]
+	if !ok {
+		return domain.CookieStatus{}, false
+	}
+	return monitor.Check(ctx), true
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestPlatformHealthChecker_Check(t *testing.T) {
+	monitor := NewCookieMonitor(domain.PlatformX, &mockCookieChecker{state: domain.CookieExpired}, nil, time.Hour, time.Hour, nil)
+	monitor.Check(context.Background())
+	metrics := NewMetrics(statusCountRepo{})
+	for i := 0; i < domain.RepeatedFailureThreshold; i++ {
+		metrics.DownloadFinished(&domain.Download{Platform: domain.PlatformGallery, Status: domain.StatusFailed})
+	}
+
+	checker := NewPlatformHealthChecker(map[domain.Platform][]string{
+		domain.PlatformX:        {"yt-dlp"},
+		domain.PlatformTelegram: {"tdl"},
+		domain.PlatformGallery:  {"gallery-dl"},
+	}, map[domain.Platform]*CookieMonitor{domain.PlatformX: monitor}, metrics)
+	checker.lookPath = func(file string) (string, error) {
+		if file == "tdl" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+
+	health := checker.Check()
+	require.Len(t, health, 3)
+	assert.Equal(t, domain.PlatformGallery, health[0].Platform)
+	assert.Equal(t, []string{domain.DegradedRepeatedFailures}, health[0].Reasons)
+	assert.Equal(t, domain.PlatformTelegram, health[1].Platform)
+	assert.Equal(t, []string{domain.DegradedBinaryMissing}, health[1].Reasons)
+	assert.Equal(t, []string{"tdl"}, health[1].MissingBinaries)
+	assert.Equal(t, domain.PlatformX, health[2].Platform)
+	assert.Equal(t, domain.CookieExpired, health[2].Cookie)
+	assert.Equal(t, []string{domain.DegradedCookieExpired}, health[2].Reasons)
+}
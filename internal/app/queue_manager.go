@@ -33,8 +33,50 @@ type QueueManager struct {
 	stopChan       chan struct{}
 	exitChan       chan struct{} // Signals when auto-exit is triggered
 	workerWg       sync.WaitGroup
-	processingURLs sync.Map   // In-memory guard: URL -> bool, prevents double-dispatch
-	addMu          sync.Mutex // Serializes AddDownload calls for atomic duplicate check+create
+	processingURLs sync.Map      // In-memory guard: URL -> bool, prevents double-dispatch
+	addMu          sync.Mutex    // Serializes AddDownload calls for atomic duplicate check+create
+	exitInhibitors []func() bool // Auto-exit is suppressed while any of these returns true
+
+	// Downloads dispatched per platform, live recordings aside: no more than
+	// the platform has slots, so the rest wait in the queue in priority order
+	dispatchMu sync.Mutex
+	dispatched map[domain.Platform]int
+
+	// Auto-exit waits for connected clients (queue.defer_exit_for_clients)
+	clients *ClientTracker
+
+	// Stops the tools of running downloads when Stop gives up draining
+	cancelWork context.CancelFunc
+
+	// Auto-exit is suppressed through the keepalive API: until keepAliveUntil,
+	// or until released while keepAliveHeld
+	keepAliveUntil time.Time
+	keepAliveHeld  bool
+
+	// A Telegram message URL is a duplicate of the same message URL of the
+	// channel's aliases
+	channelAliases channelAliasSource
+
+	// X accounts a download can be pinned to (twitter.accounts)
+	xAccounts xAccountSource
+
+	// tdl profiles a Telegram download can use (telegram.profiles)
+	telegramProfiles telegramProfileSource
+}
+
+// channelAliasSource lists the stored Telegram channel aliases
+type channelAliasSource interface {
+	ListChannelAliases() ([]*domain.TelegramChannelAlias, error)
+}
+
+// xAccountSource knows the configured X accounts
+type xAccountSource interface {
+	HasAccount(name string) bool
+}
+
+// telegramProfileSource knows the configured tdl profiles
+type telegramProfileSource interface {
+	HasProfile(name string) bool
 }
 
 // NewQueueManager creates a new queue manager
@@ -53,6 +95,7 @@ func NewQueueManager(
 		completedDir: completedDir,
 		stopChan:     make(chan struct{}),
 		exitChan:     make(chan struct{}),
+		dispatched:   make(map[domain.Platform]int),
 	}
 }
 
@@ -69,6 +112,10 @@ func (qm *QueueManager) Start(ctx context.Context) error {
 		return fmt.Errorf("queue manager already running")
 	}
 	qm.running = true
+	// Downloads run under their own context so Stop can let them finish
+	// before their tools are stopped
+	workCtx, cancelWork := context.WithCancel(ctx)
+	qm.cancelWork = cancelWork
 	qm.mu.Unlock()
 
 	// Reset any downloads that were stuck in processing state (server was killed)
@@ -83,27 +130,45 @@ func (qm *QueueManager) Start(ctx context.Context) error {
 	}
 
 	qm.workerWg.Add(1)
-	go qm.processQueue(ctx)
+	go qm.processQueue(workCtx)
 
 	return nil
 }
 
-// resetOrphanedProcessing resets downloads that are stuck in processing state
+// resetOrphanedProcessing recovers downloads that are stuck in processing
+// state: they are queued again while they have retries left and fail otherwise
 func (qm *QueueManager) resetOrphanedProcessing() error {
-	count, err := qm.repo.ResetOrphanedProcessing()
+	maxRetries := 0
+	if qm.downloadMgr != nil {
+		maxRetries, _ = qm.downloadMgr.retryPolicy()
+	}
+	orphans, err := qm.repo.ResetOrphanedProcessing(maxRetries)
 	if err != nil {
 		return err
 	}
-	if count > 0 {
-		if qm.multiLogger != nil {
-			qm.multiLogger.LogQueueEvent("orphaned_processing_reset",
-				zap.Int64("count", count))
+	var failed int
+	for _, download := range orphans {
+		if download.Status != domain.StatusFailed {
+			continue
 		}
+		failed++
+		if qm.downloadMgr != nil {
+			qm.downloadMgr.downloadFinished(download)
+		}
+	}
+	if len(orphans) > 0 && qm.multiLogger != nil {
+		qm.multiLogger.LogQueueEvent("orphaned_processing_reset",
+			zap.Int("count", len(orphans)),
+			zap.Int("requeued", len(orphans)-failed),
+			zap.Int("failed", failed))
 	}
 	return nil
 }
 
-// Stop stops the queue processor
+// Stop stops the queue processor and drains it: no further downloads are
+// started, and running downloads get queue.drain_timeout to finish. Then
+// their tools are stopped and they are queued again, to resume on the next
+// start.
 func (qm *QueueManager) Stop() error {
 	qm.mu.Lock()
 	if !qm.running {
@@ -111,14 +176,34 @@ func (qm *QueueManager) Stop() error {
 		return fmt.Errorf("queue manager not running")
 	}
 	qm.running = false
+	drainTimeout := qm.config.DrainTimeout
 	qm.mu.Unlock()
 
+	close(qm.stopChan)
+	if qm.downloadMgr != nil {
+		qm.downloadMgr.Drain()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		qm.workerWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		if qm.multiLogger != nil {
+			qm.multiLogger.LogQueueEvent("queue_drain_timeout",
+				zap.Duration("drain_timeout", drainTimeout))
+		}
+		qm.cancelWork()
+		<-done
+	}
+	qm.cancelWork()
+
 	if qm.multiLogger != nil {
 		qm.multiLogger.LogQueueEvent("queue_stopped")
 	}
-	close(qm.stopChan)
-	qm.workerWg.Wait()
-
 	return nil
 }
 
@@ -129,8 +214,35 @@ func (qm *QueueManager) IsRunning() bool {
 	return qm.running
 }
 
+// AddDownloadOptions holds optional per-download settings for AddDownloadWithOptions
+type AddDownloadOptions struct {
+	Filters     string                // gallery-dl filter options (key=value|key=value)
+	Priority    int                   // Higher values are picked from the queue first
+	RangeEnd    int                   // Telegram only: download every message from the URL's message ID up to this one
+	AllVariants bool                  // X only: keep every image at original resolution and every video rendition
+	Force       bool                  // Download again even if the URL is already downloaded
+	CallbackURL string                // POSTed when the download reaches a terminal state
+	Source      domain.DownloadSource // How the download was added; empty when unknown
+	Account     string                // X only: cookie account to download with (empty = any, in turn)
+	Profile     string                // Telegram only: tdl profile to download with (empty = telegram.profile)
+}
+
+// matches reports whether an existing download of the same URL fetches the
+// same media as these options, making a new one a duplicate
+func (opts AddDownloadOptions) matches(download *domain.Download) bool {
+	return download.RangeEnd == opts.RangeEnd && download.AllVariants == opts.AllVariants
+}
+
 // AddDownload adds a download to the queue
 func (qm *QueueManager) AddDownload(url string, platform domain.Platform, mode domain.DownloadMode, filters string) (*domain.Download, error) {
+	return qm.AddDownloadWithOptions(url, platform, mode, AddDownloadOptions{Filters: filters})
+}
+
+// AddDownloadWithOptions adds a download to the queue with optional settings.
+// A URL that is already queued, in progress or downloaded (with its files on
+// disk) returns a *domain.DuplicateDownloadError holding that download;
+// opts.Force re-downloads a downloaded URL.
+func (qm *QueueManager) AddDownloadWithOptions(url string, platform domain.Platform, mode domain.DownloadMode, opts AddDownloadOptions) (*domain.Download, error) {
 	// Validate platform
 	if !domain.ValidatePlatform(platform) {
 		return nil, fmt.Errorf("invalid platform: %s", platform)
@@ -141,41 +253,89 @@ func (qm *QueueManager) AddDownload(url string, platform domain.Platform, mode d
 		return nil, fmt.Errorf("invalid mode: %s", mode)
 	}
 
+	// Profile backups take an X profile URL
+	if mode == domain.ModeProfile {
+		if platform != domain.PlatformX {
+			return nil, fmt.Errorf("profile mode is only supported for the x platform")
+		}
+		if domain.XProfileUsername(url) == "" {
+			return nil, fmt.Errorf("profile mode needs an X profile URL (https://x.com/<user>/media): %s", url)
+		}
+	}
+
+	if err := domain.ValidateCallbackURL(opts.CallbackURL); err != nil {
+		return nil, err
+	}
+	if opts.Source != "" && !domain.ValidateSource(opts.Source) {
+		return nil, fmt.Errorf("invalid source: %s", opts.Source)
+	}
+
+	if opts.Account != "" {
+		if platform != domain.PlatformX {
+			return nil, fmt.Errorf("accounts are only supported for the x platform")
+		}
+		if qm.xAccounts != nil && !qm.xAccounts.HasAccount(opts.Account) {
+			return nil, fmt.Errorf("unknown X account: %s", opts.Account)
+		}
+	}
+	if opts.Profile != "" {
+		if platform != domain.PlatformTelegram {
+			return nil, fmt.Errorf("tdl profiles are only supported for the telegram platform")
+		}
+		if qm.telegramProfiles != nil && !qm.telegramProfiles.HasProfile(opts.Profile) {
+			return nil, fmt.Errorf("unknown Telegram profile: %s", opts.Profile)
+		}
+	}
+
+	// Validate message range
+	rangeStart := 0
+	if opts.RangeEnd != 0 {
+		if platform != domain.PlatformTelegram {
+			return nil, fmt.Errorf("message ranges are only supported for the telegram platform")
+		}
+		start, err := domain.ValidateMessageRange(url, opts.RangeEnd)
+		if err != nil {
+			return nil, err
+		}
+		rangeStart = start
+	}
+
 	// Serialize duplicate check + create to prevent TOCTOU race condition
 	// where concurrent AddDownload calls for the same URL both pass the check
 	qm.addMu.Lock()
 	defer qm.addMu.Unlock()
 
 	// Check for existing download with the same URL that is still active
-	// (queued, processing)
+	// (queued, processing, recording)
 	// Note: We do NOT include StatusCompleted here because:
 	// 1. If the file exists, user can re-request it via retry
 	// 2. If the file is missing, we should allow re-downloading
 	activeStatuses := []domain.DownloadStatus{
 		domain.StatusQueued,
 		domain.StatusProcessing,
+		domain.StatusRecording,
 	}
-	existing, err := qm.repo.FindByURL(url, activeStatuses)
+	existing, err := qm.findByURL(url, platform, activeStatuses)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check for existing download: %w", err)
 	}
-	if existing != nil {
+	if existing != nil && opts.matches(existing) {
 		if qm.multiLogger != nil {
 			qm.multiLogger.LogQueueEvent("download_duplicate_skipped",
 				zap.String("existing_id", existing.ID),
 				zap.String("url", url),
 				zap.String("status", string(existing.Status)))
 		}
-		return existing, nil
+		return nil, &domain.DuplicateDownloadError{Existing: existing}
 	}
 
-	// Also check for completed downloads - if file exists, return existing
-	// If file is missing, allow re-downloading
-	completed, err := qm.repo.FindByURL(url, []domain.DownloadStatus{domain.StatusCompleted})
+	// Also check for completed downloads - if file exists (or was archived),
+	// return existing. If file is missing, allow re-downloading
+	completed, err := qm.findByURL(url, platform, []domain.DownloadStatus{domain.StatusCompleted})
 	if err != nil {
 		return nil, fmt.Errorf("failed to check for completed download: %w", err)
 	}
-	if completed != nil {
+	if completed != nil && opts.matches(completed) && !opts.Force {
 		// Check if file exists on disk
 		if completed.FilePath != "" {
 			if _, statErr := os.Stat(completed.FilePath); statErr == nil {
@@ -186,8 +346,18 @@ func (qm *QueueManager) AddDownload(url string, platform domain.Platform, mode d
 						zap.String("url", url),
 						zap.String("file_path", completed.FilePath))
 				}
-				return completed, nil
+				return nil, &domain.DuplicateDownloadError{Existing: completed}
+			}
+		}
+		// Archived files may be on a volume that is not mounted right now
+		if archiveDir := completed.ArchiveDir(); archiveDir != "" {
+			if qm.multiLogger != nil {
+				qm.multiLogger.LogQueueEvent("download_already_archived",
+					zap.String("existing_id", completed.ID),
+					zap.String("url", url),
+					zap.String("archive_dir", archiveDir))
 			}
+			return nil, &domain.DuplicateDownloadError{Existing: completed}
 		}
 		// File doesn't exist, proceed with new download
 		if qm.multiLogger != nil {
@@ -199,28 +369,44 @@ func (qm *QueueManager) AddDownload(url string, platform domain.Platform, mode d
 	}
 
 	// Scan completed directory for files matching this URL's content ID
-	// This catches cases where DB record is missing/incomplete but files exist on disk
-	if foundFile := qm.scanCompletedDirForURL(url, platform); foundFile != "" {
-		if qm.multiLogger != nil {
-			qm.multiLogger.LogQueueEvent("download_found_in_completed_dir",
-				zap.String("url", url),
-				zap.String("found_file", foundFile))
-		}
-		// Create a completed download record so future checks can use the DB
-		download := domain.NewDownload(url, platform, mode)
-		download.MarkCompleted(foundFile)
-		if err := qm.repo.Create(download); err != nil {
-			return nil, fmt.Errorf("failed to create completed download record: %w", err)
+	// This catches cases where DB record is missing/incomplete but files exist on disk.
+	// Range, all-variants and profile downloads are skipped: a file for the
+	// first message, the default rendition or one tweet says nothing about
+	// the rest.
+	if opts.RangeEnd == 0 && !opts.AllVariants && mode != domain.ModeProfile && !opts.Force {
+		if foundFile := qm.scanCompletedDirForURL(url, platform); foundFile != "" {
+			if qm.multiLogger != nil {
+				qm.multiLogger.LogQueueEvent("download_found_in_completed_dir",
+					zap.String("url", url),
+					zap.String("found_file", foundFile))
+			}
+			// Create a completed download record so future checks can use the DB
+			download := domain.NewDownload(url, platform, mode)
+			download.Source = opts.Source
+			completeDownload(download, foundFile)
+			if err := qm.repo.Create(download); err != nil {
+				return nil, fmt.Errorf("failed to create completed download record: %w", err)
+			}
+			return nil, &domain.DuplicateDownloadError{Existing: download}
 		}
-		return download, nil
 	}
 
 	// Create download
 	download := domain.NewDownload(url, platform, mode)
+	download.Priority = opts.Priority
+	download.CallbackURL = opts.CallbackURL
+	download.Source = opts.Source
+	download.Account = opts.Account
+	download.TDLProfile = opts.Profile
+	if opts.RangeEnd != 0 {
+		download.RangeStart = rangeStart
+		download.RangeEnd = opts.RangeEnd
+	}
+	download.AllVariants = opts.AllVariants
 
 	// Encode gallery-dl filters into Metadata for use by GalleryDownloader
-	if filters != "" {
-		meta := map[string]interface{}{domain.MetadataKeyGalleryFilters: filters}
+	if opts.Filters != "" {
+		meta := map[string]interface{}{domain.MetadataKeyGalleryFilters: opts.Filters}
 		data, _ := json.Marshal(meta)
 		download.Metadata = string(data)
 	}
@@ -236,20 +422,133 @@ func (qm *QueueManager) AddDownload(url string, platform domain.Platform, mode d
 			zap.String("id", download.ID),
 			zap.String("url", url),
 			zap.String("platform", string(platform)),
-			zap.String("mode", string(mode)))
+			zap.String("mode", string(mode)),
+			zap.String("source", string(download.Source)),
+			zap.Int("priority", download.Priority))
 	}
 
 	return download, nil
 }
 
+// SetPriority changes the priority of a queued download. Downloads that have
+// already been picked up by a worker (or finished) cannot be re-prioritized:
+// they return a *domain.NotQueuedError.
+func (qm *QueueManager) SetPriority(id string, priority int) (*domain.Download, error) {
+	download, err := qm.repo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("download not found: %w", err)
+	}
+	if download == nil {
+		return nil, fmt.Errorf("download not found: %s", id)
+	}
+
+	if download.Status != domain.StatusQueued {
+		return nil, &domain.NotQueuedError{ID: id, Status: download.Status}
+	}
+
+	// Only the priority of a row that is still queued is written, so a worker
+	// starting the download meanwhile is not undone
+	updated, err := qm.repo.UpdateQueuedPriorities(map[string]int{id: priority})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update download priority: %w", err)
+	}
+	if updated == 0 {
+		status := domain.DownloadStatus("unknown")
+		if current, err := qm.repo.FindByID(id); err == nil && current != nil {
+			status = current.Status
+		}
+		return nil, &domain.NotQueuedError{ID: id, Status: status}
+	}
+	previous := download.Priority
+	download.Priority = priority
+
+	if qm.multiLogger != nil {
+		qm.multiLogger.LogQueueEvent("download_reprioritized",
+			zap.String("id", id),
+			zap.Int("previous_priority", previous),
+			zap.Int("priority", priority))
+	}
+
+	return download, nil
+}
+
+// ReorderQueue puts queued downloads in a new order (see domain.QueueReorder)
+// by rewriting the priorities of the whole queue, and returns the queue in its
+// new order. A download that is not queued, or leaves the queue while it is
+// reordered, returns a *domain.NotQueuedError; the others are reordered.
+func (qm *QueueManager) ReorderQueue(reorder domain.QueueReorder) ([]*domain.Download, error) {
+	queue, err := qm.repo.FindPending()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued downloads: %w", err)
+	}
+	ordered, err := reorder.Apply(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities := domain.QueuePriorities(ordered)
+	updated, err := qm.repo.UpdateQueuedPriorities(priorities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update download priorities: %w", err)
+	}
+	if updated < int64(len(priorities)) {
+		return nil, qm.dequeuedError(ordered)
+	}
+	for _, download := range ordered {
+		download.Priority = priorities[download.ID]
+	}
+
+	if qm.multiLogger != nil {
+		qm.multiLogger.LogQueueEvent("queue_reordered",
+			zap.Int("queued", len(ordered)),
+			zap.Strings("ids", reorder.IDs),
+			zap.String("id", reorder.ID),
+			zap.String("before", reorder.Before),
+			zap.String("after", reorder.After))
+	}
+
+	return ordered, nil
+}
+
+// dequeuedError returns a *domain.NotQueuedError for the first of downloads
+// that has left the queue
+func (qm *QueueManager) dequeuedError(downloads []*domain.Download) error {
+	for _, download := range downloads {
+		current, err := qm.repo.FindByID(download.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch download: %w", err)
+		}
+		if current == nil || current.Status != domain.StatusQueued {
+			notQueued := &domain.NotQueuedError{ID: download.ID}
+			if current != nil {
+				notQueued.Status = current.Status
+			}
+			return notQueued
+		}
+	}
+	return fmt.Errorf("failed to update the priority of every queued download")
+}
+
 // GetDownload retrieves a download by ID
 func (qm *QueueManager) GetDownload(id string) (*domain.Download, error) {
 	return qm.repo.FindByID(id)
 }
 
-// ListDownloads lists all downloads with optional filters
-func (qm *QueueManager) ListDownloads(filters map[string]interface{}) ([]*domain.Download, error) {
-	return qm.repo.FindAll(filters)
+// ListDownloads lists the downloads matching the optional filters, ordered
+// and paged by opts, and returns the number of matching downloads on all pages
+func (qm *QueueManager) ListDownloads(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, int64, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, 0, err
+	}
+	downloads, err := qm.repo.FindAll(filters, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := qm.repo.CountAll(filters, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return downloads, total, nil
 }
 
 // GetStats returns queue statistics
@@ -257,6 +556,15 @@ func (qm *QueueManager) GetStats() (*domain.DownloadStats, error) {
 	return qm.repo.GetStats()
 }
 
+// GetStatsHistory returns the downloads added and completed per day, by
+// platform, for the last days days
+func (qm *QueueManager) GetStatsHistory(days int) (*domain.StatsHistory, error) {
+	if err := domain.ValidateStatsHistoryDays(days); err != nil {
+		return nil, err
+	}
+	return qm.repo.GetStatsHistory(days, time.Now())
+}
+
 // DeleteDownload deletes a download by ID
 func (qm *QueueManager) DeleteDownload(id string) error {
 	// Check if download exists
@@ -265,9 +573,9 @@ func (qm *QueueManager) DeleteDownload(id string) error {
 		return fmt.Errorf("download not found: %w", err)
 	}
 
-	// Don't allow deletion of processing downloads
-	if download.Status == domain.StatusProcessing {
-		return fmt.Errorf("cannot delete download in processing state")
+	// Don't allow deletion of running downloads
+	if download.IsRunning() {
+		return fmt.Errorf("cannot delete download in %s state", download.Status)
 	}
 
 	if err := qm.repo.Delete(id); err != nil {
@@ -278,19 +586,163 @@ func (qm *QueueManager) DeleteDownload(id string) error {
 	return nil
 }
 
+// SetQueueSettings changes the queue check interval and auto-exit settings
+// of a running queue to those of settings; its database path is ignored.
+// A new check interval applies from the next tick.
+func (qm *QueueManager) SetQueueSettings(settings domain.QueueConfig) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.config.CheckInterval = settings.CheckInterval
+	qm.config.AutoExitOnEmpty = settings.AutoExitOnEmpty
+	qm.config.EmptyWaitTime = settings.EmptyWaitTime
+	qm.config.DeferExitForClients = settings.DeferExitForClients
+	qm.config.ClientIdleTimeout = settings.ClientIdleTimeout
+	qm.config.KeepAliveTTL = settings.KeepAliveTTL
+	qm.config.DrainTimeout = settings.DrainTimeout
+}
+
+// queueSettings returns a copy of the current queue settings
+func (qm *QueueManager) queueSettings() domain.QueueConfig {
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+	return *qm.config
+}
+
+// AddAutoExitInhibitor registers a check that keeps the server alive on an
+// empty queue while it returns true (e.g. enabled schedules).
+func (qm *QueueManager) AddAutoExitInhibitor(inhibit func() bool) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.exitInhibitors = append(qm.exitInhibitors, inhibit)
+}
+
+// autoExitInhibited reports whether any registered inhibitor is active.
+func (qm *QueueManager) autoExitInhibited() bool {
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+	for _, inhibit := range qm.exitInhibitors {
+		if inhibit() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetChannelAliases sets the Telegram channel aliases the duplicate check
+// treats as one channel
+func (qm *QueueManager) SetChannelAliases(aliases channelAliasSource) {
+	qm.channelAliases = aliases
+}
+
+// SetXAccounts sets the X accounts downloads can be added with
+func (qm *QueueManager) SetXAccounts(accounts xAccountSource) {
+	qm.xAccounts = accounts
+}
+
+// SetTelegramProfiles sets the tdl profiles Telegram downloads can be added with
+func (qm *QueueManager) SetTelegramProfiles(profiles telegramProfileSource) {
+	qm.telegramProfiles = profiles
+}
+
+// SetClientTracker sets the tracker of dashboard and API clients that
+// auto-exit waits for while queue.defer_exit_for_clients is enabled
+func (qm *QueueManager) SetClientTracker(clients *ClientTracker) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.clients = clients
+}
+
+// clientsConnected reports whether auto-exit waits for a connected client
+func (qm *QueueManager) clientsConnected(settings domain.QueueConfig) bool {
+	qm.mu.RLock()
+	clients := qm.clients
+	qm.mu.RUnlock()
+	return settings.DeferExitForClients && clients != nil &&
+		clients.Connected(settings.ClientIdleTimeout, time.Now())
+}
+
+// KeepAlive keeps the server from auto-exiting on an empty queue for ttl from
+// now, or for queue.keepalive_ttl when ttl is 0. With hold, it is kept alive
+// until ReleaseKeepAlive. A shorter ttl never cuts an earlier keepalive short.
+func (qm *QueueManager) KeepAlive(ttl time.Duration, hold bool) domain.KeepAliveStatus {
+	qm.mu.Lock()
+	if ttl <= 0 {
+		ttl = qm.config.KeepAliveTTL
+	}
+	if until := time.Now().Add(ttl); until.After(qm.keepAliveUntil) {
+		qm.keepAliveUntil = until
+	}
+	if hold {
+		qm.keepAliveHeld = true
+	}
+	qm.mu.Unlock()
+
+	if qm.multiLogger != nil {
+		qm.multiLogger.LogQueueEvent("keepalive",
+			zap.Duration("ttl", ttl),
+			zap.Bool("hold", hold))
+	}
+	return qm.KeepAliveStatus()
+}
+
+// ReleaseKeepAlive ends a held or timed keepalive, so the server may
+// auto-exit again once the queue has been empty for queue.empty_wait_time
+func (qm *QueueManager) ReleaseKeepAlive() domain.KeepAliveStatus {
+	qm.mu.Lock()
+	qm.keepAliveUntil = time.Time{}
+	qm.keepAliveHeld = false
+	qm.mu.Unlock()
+
+	if qm.multiLogger != nil {
+		qm.multiLogger.LogQueueEvent("keepalive_released")
+	}
+	return qm.KeepAliveStatus()
+}
+
+// KeepAliveStatus reports what currently keeps the server from auto-exiting
+func (qm *QueueManager) KeepAliveStatus() domain.KeepAliveStatus {
+	settings := qm.queueSettings()
+	status := domain.KeepAliveStatus{
+		AutoExitOnEmpty:  settings.AutoExitOnEmpty,
+		Inhibited:        qm.autoExitInhibited(),
+		ClientsConnected: qm.clientsConnected(settings),
+	}
+
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+	status.Held = qm.keepAliveHeld
+	if time.Now().Before(qm.keepAliveUntil) {
+		until := qm.keepAliveUntil
+		status.KeepAliveUntil = &until
+	}
+	return status
+}
+
+// keptAlive reports whether a keepalive suppresses auto-exit at now
+func (qm *QueueManager) keptAlive(now time.Time) bool {
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+	return qm.keepAliveHeld || now.Before(qm.keepAliveUntil)
+}
+
 // shouldAutoExit returns true when the queue has been empty long enough to trigger auto-exit.
 func (qm *QueueManager) shouldAutoExit(emptyStartTime time.Time) bool {
+	settings := qm.queueSettings()
 	return !IsDockerMode() &&
-		qm.config.AutoExitOnEmpty &&
+		settings.AutoExitOnEmpty &&
 		!emptyStartTime.IsZero() &&
-		time.Since(emptyStartTime) > qm.config.EmptyWaitTime
+		time.Since(emptyStartTime) > settings.EmptyWaitTime &&
+		!qm.autoExitInhibited() &&
+		!qm.keptAlive(time.Now()) &&
+		!qm.clientsConnected(settings)
 }
 
 // processQueue processes the download queue
 func (qm *QueueManager) processQueue(ctx context.Context) {
 	defer qm.workerWg.Done()
 
-	ticker := time.NewTicker(qm.config.CheckInterval)
+	checkInterval := qm.queueSettings().CheckInterval
+	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
 	emptyStartTime := time.Time{}
@@ -310,6 +762,11 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 			}
 			return
 		case <-ticker.C:
+			if d := qm.queueSettings().CheckInterval; d != checkInterval {
+				checkInterval = d
+				ticker.Reset(d)
+			}
+
 			// Get pending downloads
 			pending, err := qm.repo.FindPending()
 			if err != nil {
@@ -329,11 +786,15 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 				continue
 			}
 
+			// Platforms rate limited: their downloads stay queued until the pause ends
+			paused := qm.downloadMgr.PausedPlatforms()
+
 			// Diagnostic: log queue state each tick when there's activity
 			if qm.multiLogger != nil && (len(pending) > 0 || activeCount > 0) {
 				qm.multiLogger.LogQueueEvent("queue_tick",
 					zap.Int("pending_count", len(pending)),
-					zap.Int64("active_count", activeCount))
+					zap.Int64("active_count", activeCount),
+					zap.Int("paused_platforms", len(paused)))
 			}
 
 			if len(pending) == 0 && activeCount == 0 {
@@ -347,7 +808,7 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 					if qm.multiLogger != nil {
 						qm.multiLogger.LogQueueEvent("queue_auto_exit",
 							zap.String("reason", "empty_timeout"),
-							zap.Duration("wait_time", qm.config.EmptyWaitTime))
+							zap.Duration("wait_time", qm.queueSettings().EmptyWaitTime))
 					}
 					close(qm.exitChan)
 					return
@@ -358,12 +819,18 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 			// Reset empty timer if there are active downloads
 			emptyStartTime = time.Time{}
 
-			// Process downloads in parallel using goroutines
+			// Process downloads in parallel using goroutines, top of the queue
+			// first. Each platform gets no more downloads than it has slots:
+			// the rest stay in the queue, where a new priority still counts.
+			slots := qm.downloadMgr.PlatformConcurrency()
 			for _, download := range pending {
 				// Check if file already exists (might have been completed but status wasn't updated)
 				if qm.skipIfFileExists(download) {
 					continue
 				}
+				if _, ok := paused[download.Platform]; ok {
+					continue
+				}
 
 				// Capture the download variable for the goroutine
 				dl := download
@@ -380,6 +847,13 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 					continue
 				}
 
+				// Live recordings don't take the platform semaphore
+				live := dl.IsLive()
+				if !live && !qm.takeDispatchSlot(dl.Platform, slots) {
+					qm.processingURLs.Delete(dl.URL)
+					continue
+				}
+
 				// Log dispatch (download stays "queued" until it acquires the semaphore
 				// inside ProcessDownload and is actually started)
 				if qm.multiLogger != nil {
@@ -396,6 +870,9 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 				go func(download *domain.Download) {
 					defer qm.workerWg.Done()
 					defer qm.processingURLs.Delete(download.URL) // Release in-memory guard when done
+					if !live {
+						defer qm.releaseDispatchSlot(download.Platform)
+					}
 
 					if err := qm.downloadMgr.ProcessDownload(ctx, download); err != nil {
 						// Log download failure
@@ -422,6 +899,25 @@ func (qm *QueueManager) processQueue(ctx context.Context) {
 	}
 }
 
+// takeDispatchSlot reserves one of the slots of platform for a download
+// about to be dispatched, failing when all of them are taken
+func (qm *QueueManager) takeDispatchSlot(platform domain.Platform, slots int) bool {
+	qm.dispatchMu.Lock()
+	defer qm.dispatchMu.Unlock()
+	if qm.dispatched[platform] >= slots {
+		return false
+	}
+	qm.dispatched[platform]++
+	return true
+}
+
+// releaseDispatchSlot frees the slot of a dispatched download once it is done
+func (qm *QueueManager) releaseDispatchSlot(platform domain.Platform) {
+	qm.dispatchMu.Lock()
+	defer qm.dispatchMu.Unlock()
+	qm.dispatched[platform]--
+}
+
 // skipIfFileExists checks if a download's file already exists and marks it as completed
 // Returns true if the download was skipped
 func (qm *QueueManager) skipIfFileExists(download *domain.Download) bool {
@@ -429,7 +925,7 @@ func (qm *QueueManager) skipIfFileExists(download *domain.Download) bool {
 	if download.FilePath != "" {
 		if _, err := os.Stat(download.FilePath); err == nil {
 			// File exists, mark as completed
-			download.MarkCompleted(download.FilePath)
+			completeDownload(download, download.FilePath)
 			if err := qm.repo.Update(download); err != nil {
 				if qm.multiLogger != nil {
 					qm.multiLogger.LogAppError("Failed to update download status",
@@ -437,6 +933,9 @@ func (qm *QueueManager) skipIfFileExists(download *domain.Download) bool {
 						zap.Error(err))
 				}
 			}
+			if qm.downloadMgr != nil {
+				qm.downloadMgr.downloadFinished(download)
+			}
 			if qm.multiLogger != nil {
 				qm.multiLogger.LogQueueEvent("download_skipped_file_exists",
 					zap.String("id", download.ID),
@@ -448,7 +947,33 @@ func (qm *QueueManager) skipIfFileExists(download *domain.Download) bool {
 	return false
 }
 
-// scanCompletedDirForURL scans the completed directory for files matching a URL's content ID.
+// findByURL finds the most recent download of url with any of the statuses.
+// A Telegram URL not found is looked up again with the channel replaced by
+// each of its aliases.
+func (qm *QueueManager) findByURL(url string, platform domain.Platform, statuses []domain.DownloadStatus) (*domain.Download, error) {
+	found, err := qm.repo.FindByURL(url, statuses)
+	if err != nil || found != nil || platform != domain.PlatformTelegram || qm.channelAliases == nil {
+		return found, err
+	}
+
+	stored, err := qm.channelAliases.ListChannelAliases()
+	if err != nil || len(stored) == 0 {
+		return nil, err
+	}
+	channel := domain.TelegramURLChannel(url)
+	for _, alias := range domain.NewTelegramChannelAliases(stored).Group(channel) {
+		if alias == channel {
+			continue
+		}
+		found, err := qm.repo.FindByURL(domain.TelegramURLWithChannel(url, alias), statuses)
+		if err != nil || found != nil {
+			return found, err
+		}
+	}
+	return nil, nil
+}
+
+// scanCompletedDirForURL scans the completed directory tree for files matching a URL's content ID.
 // This provides file-based deduplication as a fallback when DB records are missing/incomplete.
 // Returns the path of the first matching file found, or empty string if none found.
 func (qm *QueueManager) scanCompletedDirForURL(url string, platform domain.Platform) string {
@@ -462,29 +987,28 @@ func (qm *QueueManager) scanCompletedDirForURL(url string, platform domain.Platf
 		return ""
 	}
 
-	// Scan completed directory for files matching the content ID pattern
-	entries, err := os.ReadDir(qm.completedDir)
-	if err != nil {
-		return ""
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	// Scan completed directory (including download.organize_by subdirectories)
+	// for files matching the content ID pattern
+	found := ""
+	filepath.WalkDir(qm.completedDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
 		}
 		name := entry.Name()
 		// Skip metadata files
 		if strings.HasSuffix(name, ".info.json") {
-			continue
+			return nil
 		}
 		// Check if this is a media file containing our content ID
 		nameWithoutExt := strings.TrimSuffix(name, filepath.Ext(name))
 		if matchesContentID(nameWithoutExt, contentID, platform) {
-			return filepath.Join(qm.completedDir, name)
+			found = path
+			return filepath.SkipAll
 		}
-	}
+		return nil
+	})
 
-	return ""
+	return found
 }
 
 // extractContentIDFromURL extracts a unique content identifier from a download URL.
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"go.uber.org/zap"
 )
 
 // mockRepo implements domain.DownloadRepository for testing
@@ -35,6 +38,19 @@ func (m *mockRepo) Update(download *domain.Download) error {
 	return nil
 }
 
+func (m *mockRepo) UpdateProgress(download *domain.Download) error { return nil }
+
+func (m *mockRepo) UpdateQueuedPriorities(priorities map[string]int) (int64, error) {
+	var updated int64
+	for _, d := range m.downloads {
+		if priority, ok := priorities[d.ID]; ok && d.Status == domain.StatusQueued {
+			d.Priority = priority
+			updated++
+		}
+	}
+	return updated, nil
+}
+
 func (m *mockRepo) Delete(id string) error { return nil }
 
 func (m *mockRepo) FindByID(id string) (*domain.Download, error) {
@@ -64,14 +80,22 @@ func (m *mockRepo) FindByStatus(status domain.DownloadStatus) ([]*domain.Downloa
 	return nil, nil
 }
 func (m *mockRepo) FindPending() ([]*domain.Download, error) { return nil, nil }
-func (m *mockRepo) FindAll(filters map[string]interface{}) ([]*domain.Download, error) {
+func (m *mockRepo) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
 	return nil, nil
 }
+func (m *mockRepo) CountAll(filters map[string]interface{}, opts domain.ListOptions) (int64, error) {
+	return 0, nil
+}
 func (m *mockRepo) Count() (int64, error)                                     { return 0, nil }
 func (m *mockRepo) CountByStatus(status domain.DownloadStatus) (int64, error) { return 0, nil }
 func (m *mockRepo) CountActive() (int64, error)                               { return 0, nil }
-func (m *mockRepo) ResetOrphanedProcessing() (int64, error)                   { return 0, nil }
-func (m *mockRepo) GetStats() (*domain.DownloadStats, error)                  { return nil, nil }
+func (m *mockRepo) ResetOrphanedProcessing(maxRetries int) ([]*domain.Download, error) {
+	return nil, nil
+}
+func (m *mockRepo) GetStats() (*domain.DownloadStats, error) { return nil, nil }
+func (m *mockRepo) GetStatsHistory(days int, now time.Time) (*domain.StatsHistory, error) {
+	return nil, nil
+}
 
 func newTestQueueManager(repo domain.DownloadRepository) *QueueManager {
 	config := &domain.QueueConfig{
@@ -94,6 +118,254 @@ func TestAddDownload_NewURL(t *testing.T) {
 	assert.Len(t, repo.downloads, 1)
 }
 
+func TestAddDownloadWithOptions_SetsPriority(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	dl, err := qm.AddDownloadWithOptions("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Priority: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 7, dl.Priority)
+	assert.Empty(t, dl.Metadata)
+}
+
+func TestAddDownloadWithOptions_Source(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	dl, err := qm.AddDownloadWithOptions("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Source: domain.SourceDashboard})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SourceDashboard, dl.Source)
+
+	_, err = qm.AddDownloadWithOptions("https://t.me/channel/124", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Source: "cron"})
+	assert.Error(t, err)
+	assert.Len(t, repo.downloads, 1)
+}
+
+func TestAddDownloadWithOptions_Account(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+	qm.SetXAccounts(&domain.TwitterConfig{Accounts: map[string]string{"alt": "/cookies/alt.cookie"}})
+
+	dl, err := qm.AddDownloadWithOptions("https://x.com/user/status/123", domain.PlatformX, domain.ModeDefault, AddDownloadOptions{Account: "alt"})
+	require.NoError(t, err)
+	assert.Equal(t, "alt", dl.Account)
+
+	_, err = qm.AddDownloadWithOptions("https://x.com/user/status/124", domain.PlatformX, domain.ModeDefault, AddDownloadOptions{Account: "other"})
+	assert.Error(t, err)
+	_, err = qm.AddDownloadWithOptions("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Account: "alt"})
+	assert.Error(t, err)
+	assert.Len(t, repo.downloads, 1)
+}
+
+func TestAddDownloadWithOptions_TelegramProfile(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+	qm.SetTelegramProfiles(&domain.TelegramConfig{Profile: "default", Profiles: map[string]string{"work": ""}})
+
+	dl, err := qm.AddDownloadWithOptions("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Profile: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, "work", dl.TDLProfile)
+
+	_, err = qm.AddDownloadWithOptions("https://t.me/channel/124", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Profile: "other"})
+	assert.Error(t, err)
+	_, err = qm.AddDownloadWithOptions("https://x.com/user/status/123", domain.PlatformX, domain.ModeDefault, AddDownloadOptions{Profile: "work"})
+	assert.Error(t, err)
+	assert.Len(t, repo.downloads, 1)
+}
+
+func TestAddDownloadWithOptions_MessageRange(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	single, err := qm.AddDownload("https://t.me/c/12345/100", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+
+	// A range starting at an already queued message is a separate download
+	ranged, err := qm.AddDownloadWithOptions("https://t.me/c/12345/100", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{RangeEnd: 250})
+	require.NoError(t, err)
+	assert.NotEqual(t, single.ID, ranged.ID)
+	assert.Equal(t, 100, ranged.RangeStart)
+	assert.Equal(t, 250, ranged.RangeEnd)
+
+	_, err = qm.AddDownloadWithOptions("https://t.me/c/12345/100", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{RangeEnd: 250})
+	var duplicate *domain.DuplicateDownloadError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, ranged.ID, duplicate.Existing.ID)
+
+	_, err = qm.AddDownloadWithOptions("https://x.com/user/status/100", domain.PlatformX, domain.ModeDefault, AddDownloadOptions{RangeEnd: 250})
+	assert.Error(t, err)
+	_, err = qm.AddDownloadWithOptions("https://t.me/c/12345/100", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{RangeEnd: 50})
+	assert.Error(t, err)
+	assert.Len(t, repo.downloads, 2)
+}
+
+func TestAddDownloadWithOptions_AllVariants(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	plain, err := qm.AddDownload("https://x.com/user/status/123", domain.PlatformX, domain.ModeDefault, "")
+	require.NoError(t, err)
+
+	// Asking for every variant of a queued tweet queues a separate download
+	archival, err := qm.AddDownloadWithOptions("https://x.com/user/status/123", domain.PlatformX, domain.ModeDefault, AddDownloadOptions{AllVariants: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, plain.ID, archival.ID)
+	assert.True(t, archival.AllVariants)
+	assert.Len(t, repo.downloads, 2)
+}
+
+func TestAddDownload_ProfileMode(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	download, err := qm.AddDownload("https://x.com/alice/media", domain.PlatformX, domain.ModeProfile, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ModeProfile, download.Mode)
+
+	_, err = qm.AddDownload("https://x.com/alice/status/123", domain.PlatformX, domain.ModeProfile, "")
+	assert.Error(t, err)
+	_, err = qm.AddDownload("https://x.com/alice/media", domain.PlatformGallery, domain.ModeProfile, "")
+	assert.Error(t, err)
+	assert.Len(t, repo.downloads, 1)
+}
+
+func TestSetPriority_Queued(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	dl, err := qm.AddDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+
+	updated, err := qm.SetPriority(dl.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, updated.Priority)
+	assert.Equal(t, 10, repo.downloads[0].Priority)
+}
+
+func TestSetPriority_RejectsNonQueued(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	dl, err := qm.AddDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+	dl.MarkProcessing()
+
+	_, err = qm.SetPriority(dl.ID, 10)
+	var notQueued *domain.NotQueuedError
+	require.ErrorAs(t, err, &notQueued)
+	assert.Equal(t, domain.StatusProcessing, notQueued.Status)
+	assert.Equal(t, 0, repo.downloads[0].Priority)
+}
+
+// orderDownloader reports each download it starts on started and finishes
+// it once release is closed
+type orderDownloader struct {
+	dir     string
+	started chan string
+	release chan struct{}
+}
+
+func (o *orderDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	o.started <- download.URL
+	select {
+	case <-o.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	file := filepath.Join(o.dir, download.ID+".mp4")
+	if err := os.WriteFile(file, []byte("v"), 0644); err != nil {
+		return nil, err
+	}
+	return &domain.DownloadResult{Files: []string{file}}, nil
+}
+func (o *orderDownloader) Platform() domain.Platform { return domain.PlatformX }
+func (o *orderDownloader) Validate(url string) error { return nil }
+
+func TestSetPriority_ChangesRunOrder(t *testing.T) {
+	repo := newRegenerateTestRepo(t)
+	downloader := &orderDownloader{dir: t.TempDir(), started: make(chan string, 3), release: make(chan struct{})}
+	notifier := infrastructure.NewNotificationService(&domain.NotificationConfig{}, zap.NewNop())
+	dm := NewDownloadManager(repo,
+		map[domain.Platform]domain.Downloader{domain.PlatformX: downloader},
+		notifier, &domain.DownloadConfig{PlatformConcurrency: 1}, zap.NewNop())
+	qm := NewQueueManager(repo, dm, &domain.QueueConfig{CheckInterval: 10 * time.Millisecond, DrainTimeout: time.Second}, nil, t.TempDir())
+
+	var ids []string
+	for _, url := range []string{"https://x.com/a/status/1", "https://x.com/b/status/2", "https://x.com/c/status/3"} {
+		download, err := qm.AddDownload(url, domain.PlatformX, domain.ModeDefault, "")
+		require.NoError(t, err)
+		ids = append(ids, download.ID)
+	}
+
+	require.NoError(t, qm.Start(context.Background()))
+	defer qm.Stop()
+	next := func() string {
+		select {
+		case url := <-downloader.started:
+			return url
+		case <-time.After(5 * time.Second):
+			t.Fatal("no download started")
+			return ""
+		}
+	}
+
+	assert.Equal(t, "https://x.com/a/status/1", next())
+	// Raised while the first one runs, the last download runs next
+	_, err := qm.SetPriority(ids[2], 10)
+	require.NoError(t, err)
+	close(downloader.release)
+	assert.Equal(t, "https://x.com/c/status/3", next())
+	assert.Equal(t, "https://x.com/b/status/2", next())
+}
+
+// dequeuingRepo lists the queued downloads, then starts dequeue as a worker
+// would before the new priorities are written
+type dequeuingRepo struct {
+	*mockRepo
+	dequeue string
+}
+
+func (r *dequeuingRepo) FindPending() ([]*domain.Download, error) {
+	var queue []*domain.Download
+	for _, d := range r.downloads {
+		if d.Status == domain.StatusQueued {
+			copied := *d
+			queue = append(queue, &copied)
+		}
+	}
+	for _, d := range r.downloads {
+		if d.ID == r.dequeue {
+			d.MarkProcessing()
+		}
+	}
+	return queue, nil
+}
+
+func TestReorderQueue_ReportsDequeuedDownload(t *testing.T) {
+	repo := &dequeuingRepo{mockRepo: newMockRepo()}
+	qm := newTestQueueManager(repo)
+	var ids []string
+	for _, url := range []string{"https://t.me/channel/1", "https://t.me/channel/2"} {
+		download, err := qm.AddDownload(url, domain.PlatformTelegram, domain.ModeDefault, "")
+		require.NoError(t, err)
+		ids = append(ids, download.ID)
+	}
+
+	queue, err := qm.ReorderQueue(domain.QueueReorder{IDs: []string{ids[1], ids[0]}})
+	require.NoError(t, err)
+	assert.Equal(t, ids[1], queue[0].ID)
+
+	repo.dequeue = ids[0]
+	_, err = qm.ReorderQueue(domain.QueueReorder{IDs: []string{ids[0], ids[1]}})
+	var notQueued *domain.NotQueuedError
+	require.ErrorAs(t, err, &notQueued)
+	assert.Equal(t, ids[0], notQueued.ID)
+	assert.Equal(t, domain.StatusProcessing, notQueued.Status)
+
+	_, err = qm.ReorderQueue(domain.QueueReorder{IDs: []string{ids[0]}})
+	assert.ErrorAs(t, err, &notQueued, "not in the queue")
+}
+
 func TestAddDownload_DuplicateQueued(t *testing.T) {
 	repo := newMockRepo()
 	qm := newTestQueueManager(repo)
@@ -102,11 +374,46 @@ func TestAddDownload_DuplicateQueued(t *testing.T) {
 	first, err := qm.AddDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, "")
 	require.NoError(t, err)
 
-	// Try to add same URL again - should return existing
-	second, err := qm.AddDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, "")
-	require.NoError(t, err)
-	assert.Equal(t, first.ID, second.ID, "should return existing download, not create new one")
+	// Try to add same URL again - should report the existing one
+	_, err = qm.AddDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, "")
+	var duplicate *domain.DuplicateDownloadError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, first.ID, duplicate.Existing.ID, "should return existing download, not create new one")
 	assert.Len(t, repo.downloads, 1, "should not create a second entry")
+
+	// Forcing does not queue a URL twice
+	_, err = qm.AddDownloadWithOptions("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Force: true})
+	require.ErrorAs(t, err, &duplicate)
+	assert.Len(t, repo.downloads, 1)
+}
+
+// staticChannelAliases serves a fixed list of Telegram channel aliases
+type staticChannelAliases []*domain.TelegramChannelAlias
+
+func (a staticChannelAliases) ListChannelAliases() ([]*domain.TelegramChannelAlias, error) {
+	return a, nil
+}
+
+func TestAddDownload_DuplicateOfChannelAlias(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+	qm.SetChannelAliases(staticChannelAliases{{ChannelID: "oldnews", SuccessorID: "1234567890"}})
+
+	first, err := qm.AddDownload("https://t.me/oldnews/42", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+
+	// The same message of the successor is a duplicate
+	_, err = qm.AddDownload("https://t.me/c/1234567890/42", domain.PlatformTelegram, domain.ModeDefault, "")
+	var duplicate *domain.DuplicateDownloadError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, first.ID, duplicate.Existing.ID)
+
+	// Other messages and channels are not
+	_, err = qm.AddDownload("https://t.me/c/1234567890/43", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+	_, err = qm.AddDownload("https://t.me/othernews/42", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+	assert.Len(t, repo.downloads, 3)
 }
 
 func TestAddDownload_DuplicateCompleted_FileExists(t *testing.T) {
@@ -125,12 +432,21 @@ func TestAddDownload_DuplicateCompleted_FileExists(t *testing.T) {
 	require.NoError(t, err)
 	first.MarkCompleted(tmpFilePath)
 
-	// Try to add same URL again - should return existing completed since file exists
-	second, err := qm.AddDownload("https://t.me/channel/exists", domain.PlatformTelegram, domain.ModeDefault, "")
-	require.NoError(t, err)
-	assert.Equal(t, first.ID, second.ID, "should return existing completed download")
-	assert.Equal(t, domain.StatusCompleted, second.Status)
+	// Try to add same URL again - should report existing completed since file exists
+	_, err = qm.AddDownload("https://t.me/channel/exists", domain.PlatformTelegram, domain.ModeDefault, "")
+	var duplicate *domain.DuplicateDownloadError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, first.ID, duplicate.Existing.ID, "should return existing completed download")
+	assert.Equal(t, domain.StatusCompleted, duplicate.Existing.Status)
+	assert.Equal(t, "already downloaded as "+first.ID, err.Error())
 	assert.Len(t, repo.downloads, 1, "should not create a second entry")
+
+	// Forcing queues a new download
+	forced, err := qm.AddDownloadWithOptions("https://t.me/channel/exists", domain.PlatformTelegram, domain.ModeDefault, AddDownloadOptions{Force: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, forced.ID)
+	assert.Equal(t, domain.StatusQueued, forced.Status)
+	assert.Len(t, repo.downloads, 2)
 }
 
 func TestAddDownload_DuplicateCompleted_FileMissing(t *testing.T) {
@@ -150,6 +466,23 @@ func TestAddDownload_DuplicateCompleted_FileMissing(t *testing.T) {
 	assert.Len(t, repo.downloads, 2, "should create a second entry for re-download")
 }
 
+func TestAddDownload_DuplicateCompleted_Archived(t *testing.T) {
+	repo := newMockRepo()
+	qm := newTestQueueManager(repo)
+
+	// Archived to a volume that is not mounted
+	first, err := qm.AddDownload("https://t.me/channel/archived", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.NoError(t, err)
+	first.MarkCompleted("/Volumes/Archive/file.mp4")
+	first.MarkArchived("/Volumes/Archive", time.Now())
+
+	_, err = qm.AddDownload("https://t.me/channel/archived", domain.PlatformTelegram, domain.ModeDefault, "")
+	var duplicate *domain.DuplicateDownloadError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, first.ID, duplicate.Existing.ID)
+	assert.Len(t, repo.downloads, 1)
+}
+
 func TestAddDownload_AllowsRetryAfterFailure(t *testing.T) {
 	repo := newMockRepo()
 	qm := newTestQueueManager(repo)
@@ -281,6 +614,13 @@ func TestScanCompletedDirForURL(t *testing.T) {
 		assert.Equal(t, telegramFile, result)
 	})
 
+	t.Run("finds file in an organize_by subdirectory", func(t *testing.T) {
+		organizedFile := filepath.Join(completedDir, "subdir", "someone_4242.mp4")
+		require.NoError(t, os.WriteFile(organizedFile, []byte("fake"), 0644))
+		result := qm.scanCompletedDirForURL("https://x.com/someone/status/4242", domain.PlatformX)
+		assert.Equal(t, organizedFile, result)
+	})
+
 	t.Run("returns empty for non-matching twitter URL", func(t *testing.T) {
 		result := qm.scanCompletedDirForURL("https://x.com/user/status/9999999999", domain.PlatformX)
 		assert.Empty(t, result)
@@ -337,16 +677,17 @@ func TestAddDownload_FileScanDedup(t *testing.T) {
 	qm := NewQueueManager(repo, nil, config, nil, completedDir)
 
 	// Add a download for the same content — should be found on disk and returned as completed
-	dl, err := qm.AddDownload("https://t.me/somechannel/789", domain.PlatformTelegram, domain.ModeDefault, "")
-	require.NoError(t, err)
-	require.NotNil(t, dl)
+	_, err = qm.AddDownload("https://t.me/somechannel/789", domain.PlatformTelegram, domain.ModeDefault, "")
+	var duplicate *domain.DuplicateDownloadError
+	require.ErrorAs(t, err, &duplicate)
+	dl := duplicate.Existing
 	assert.Equal(t, domain.StatusCompleted, dl.Status, "should be auto-completed from file scan")
 	assert.Equal(t, telegramFile, dl.FilePath, "should have the found file path")
 	assert.Len(t, repo.downloads, 1, "should create one completed record")
 
 	// Adding the same URL again should now hit the DB-level completed check
-	dl2, err := qm.AddDownload("https://t.me/somechannel/789", domain.PlatformTelegram, domain.ModeDefault, "")
-	require.NoError(t, err)
-	assert.Equal(t, dl.ID, dl2.ID, "should return same completed download from DB")
+	_, err = qm.AddDownload("https://t.me/somechannel/789", domain.PlatformTelegram, domain.ModeDefault, "")
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, dl.ID, duplicate.Existing.ID, "should return same completed download from DB")
 	assert.Len(t, repo.downloads, 1, "should not create another record")
 }
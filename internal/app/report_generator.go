@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// reportCheckInterval is how often Run checks whether a report period has ended
+const reportCheckInterval = 10 * time.Minute
+
+// reportNotifier is the notification used by ReportGenerator
+type reportNotifier interface {
+	NotifyArchiveReport(report *domain.ArchiveReport, path string)
+}
+
+// ReportFile is a report written to the reports directory
+type ReportFile struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ReportGenerator writes archive reports (downloads, failures, top sources
+// and storage growth of a day or week) to base_dir/reports. While enabled it
+// writes the configured report once each period has ended, including a
+// period that ended while the server was down, and sends its summary as a
+// digest notification.
+type ReportGenerator struct {
+	repo        domain.ReportRepository
+	notifier    reportNotifier
+	config      *domain.ReportConfig
+	reportsDir  string
+	multiLogger *logger.MultiLogger
+	mu          sync.Mutex // Serializes report writes (ticker and API)
+}
+
+// NewReportGenerator creates a report generator. notifier and multiLogger may be nil.
+func NewReportGenerator(
+	repo domain.ReportRepository,
+	notifier reportNotifier,
+	config *domain.ReportConfig,
+	reportsDir string,
+	multiLogger *logger.MultiLogger,
+) *ReportGenerator {
+	return &ReportGenerator{
+		repo:        repo,
+		notifier:    notifier,
+		config:      config,
+		reportsDir:  reportsDir,
+		multiLogger: multiLogger,
+	}
+}
+
+// Run writes the report of the last full period if it is missing, then checks
+// again every 10 minutes until ctx is cancelled.
+func (g *ReportGenerator) Run(ctx context.Context) {
+	ticker := time.NewTicker(reportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := g.GenerateDue(time.Now()); err != nil && g.multiLogger != nil {
+			g.multiLogger.LogAppError("Failed to write archive report", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GenerateDue writes the configured report of the last full period before
+// now unless it was already written, and notifies when enabled. It returns
+// the report's path, or "" when there was nothing to do.
+func (g *ReportGenerator) GenerateDue(now time.Time) (string, error) {
+	from, _ := domain.ReportPeriodBounds(g.config.Period, now)
+	name := domain.ReportFileName(g.config.Period, g.config.Format, from)
+	if _, err := os.Stat(filepath.Join(g.reportsDir, name)); err == nil {
+		return "", nil
+	}
+
+	report, path, err := g.Generate(g.config.Period, g.config.Format, now)
+	if err != nil {
+		return "", err
+	}
+	if g.config.Notify && g.notifier != nil {
+		g.notifier.NotifyArchiveReport(report, path)
+	}
+	return path, nil
+}
+
+// Generate builds the report of the last full period before now and writes
+// it to the reports directory, replacing an earlier copy. Empty period and
+// format use the configured ones.
+func (g *ReportGenerator) Generate(period, format string, now time.Time) (*domain.ArchiveReport, string, error) {
+	if period == "" {
+		period = g.config.Period
+	}
+	if format == "" {
+		format = g.config.Format
+	}
+	if err := domain.ValidateReportPeriod(period); err != nil {
+		return nil, "", err
+	}
+	if err := domain.ValidateReportFormat(format); err != nil {
+		return nil, "", err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	from, to := domain.ReportPeriodBounds(period, now)
+	downloads, err := g.repo.FindFinishedBetween(from, to)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find downloads: %w", err)
+	}
+	totalBytes, err := g.repo.CompletedSizeBefore(to)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sum archive size: %w", err)
+	}
+
+	report := domain.BuildArchiveReport(period, from, to, downloads, totalBytes)
+	data, err := report.Render(format)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.MkdirAll(g.reportsDir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+	path := filepath.Join(g.reportsDir, domain.ReportFileName(period, format, from))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if g.multiLogger != nil {
+		g.multiLogger.LogQueueEvent("report_written",
+			zap.String("period", period),
+			zap.String("path", path),
+			zap.Int("completed", report.Completed),
+			zap.Int("failed", report.Failed),
+			zap.Int64("bytes", report.Bytes))
+	}
+	return report, path, nil
+}
+
+// ListReports returns the reports in the reports directory, newest first
+func (g *ReportGenerator) ListReports() ([]ReportFile, error) {
+	entries, err := os.ReadDir(g.reportsDir)
+	if os.IsNotExist(err) {
+		return []ReportFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+
+	reports := []ReportFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "report_") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		reports = append(reports, ReportFile{Name: entry.Name(), Size: info.Size(), ModifiedAt: info.ModTime()})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].ModifiedAt.After(reports[j].ModifiedAt)
+	})
+	return reports, nil
+}
+
+// ReportPath returns the path of a report by file name. Names that are not a
+// plain file name in the reports directory are rejected.
+func (g *ReportGenerator) ReportPath(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || !strings.HasPrefix(name, "report_") {
+		return "", fmt.Errorf("invalid report name: %s", name)
+	}
+	path := filepath.Join(g.reportsDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("report not found: %s", name)
+	}
+	return path, nil
+}
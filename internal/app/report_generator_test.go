@@ -0,0 +1,97 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// mockReportRepo implements domain.ReportRepository for testing
+type mockReportRepo struct {
+	downloads []*domain.Download
+	total     int64
+	from, to  time.Time
+}
+
+func (m *mockReportRepo) FindFinishedBetween(from, to time.Time) ([]*domain.Download, error) {
+	m.from, m.to = from, to
+	return m.downloads, nil
+}
+
+func (m *mockReportRepo) CompletedSizeBefore(t time.Time) (int64, error) {
+	return m.total, nil
+}
+
+// mockReportNotifier records digest notifications
+type mockReportNotifier struct {
+	paths []string
+}
+
+func (m *mockReportNotifier) NotifyArchiveReport(report *domain.ArchiveReport, path string) {
+	m.paths = append(m.paths, path)
+}
+
+func TestReportGenerator_GenerateDueWritesOncePerPeriod(t *testing.T) {
+	repo := &mockReportRepo{
+		downloads: []*domain.Download{{Status: domain.StatusCompleted, Platform: domain.PlatformX, FileSize: 10, FilePath: "/c/a.mp4"}},
+		total:     100,
+	}
+	notifier := &mockReportNotifier{}
+	config := &domain.ReportConfig{Enabled: true, Period: domain.ReportDaily, Format: domain.ReportFormatMarkdown, Notify: true}
+	dir := filepath.Join(t.TempDir(), "reports")
+	g := NewReportGenerator(repo, notifier, config, dir, nil)
+
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	path, err := g.GenerateDue(now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "report_daily_2024-01-14.md"), path)
+	assert.Equal(t, time.Date(2024, 1, 14, 0, 0, 0, 0, time.Local), repo.from)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "| Completed | 1 (1 files) |")
+	assert.Equal(t, []string{path}, notifier.paths)
+
+	// Later in the same day the report already exists
+	path, err = g.GenerateDue(now.Add(5 * time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, path)
+	assert.Len(t, notifier.paths, 1)
+
+	reports, err := g.ListReports()
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "report_daily_2024-01-14.md", reports[0].Name)
+}
+
+func TestReportGenerator_GenerateOverridesConfig(t *testing.T) {
+	config := &domain.ReportConfig{Period: domain.ReportDaily, Format: domain.ReportFormatMarkdown}
+	g := NewReportGenerator(&mockReportRepo{}, nil, config, t.TempDir(), nil)
+
+	report, path, err := g.Generate(domain.ReportWeekly, domain.ReportFormatHTML, time.Date(2024, 1, 17, 9, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReportWeekly, report.Period)
+	assert.Equal(t, "report_weekly_2024-01-08.html", filepath.Base(path))
+
+	_, _, err = g.Generate("monthly", "", time.Now())
+	assert.Error(t, err)
+}
+
+func TestReportGenerator_ReportPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report_daily_2024-01-14.md"), []byte("# report"), 0644))
+	g := NewReportGenerator(&mockReportRepo{}, nil, &domain.ReportConfig{}, dir, nil)
+
+	path, err := g.ReportPath("report_daily_2024-01-14.md")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "report_daily_2024-01-14.md"), path)
+
+	for _, name := range []string{"", "../config.yaml", "report_../../x", "queue.db", "report_daily_2024-01-15.md"} {
+		_, err := g.ReportPath(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// retentionRepository is the persistence used by RetentionManager
+type retentionRepository interface {
+	FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error)
+	Update(download *domain.Download) error
+}
+
+// storedFileRemover removes the copies of completed files kept by a remote
+// storage.backend (infrastructure.FileStore)
+type storedFileRemover interface {
+	Delete(ctx context.Context, file string) error
+}
+
+// ExpiredDownload is a download expired (or, in a dry run, to be expired) by
+// the retention policy
+type ExpiredDownload struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Title       string     `json:"title,omitempty"`
+	FileSize    int64      `json:"file_size"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// RetentionResult summarizes a retention pass
+type RetentionResult struct {
+	DryRun     bool              `json:"dry_run"`
+	Expired    int               `json:"expired"`
+	FreedBytes int64             `json:"freed_bytes"`
+	Downloads  []ExpiredDownload `json:"downloads"`
+}
+
+// RetentionManager expires old completed downloads: it deletes their files
+// (and optionally the .info.json and .description.txt sidecars) once they are
+// older than max_age_days, or oldest first while the archive is over
+// max_size, and keeps the records with status expired.
+type RetentionManager struct {
+	repo        retentionRepository
+	config      *domain.RetentionConfig
+	storedFiles storedFileRemover // Removes uploaded copies (optional)
+	multiLogger *logger.MultiLogger
+	mu          sync.Mutex // Serializes passes (ticker and API)
+}
+
+// NewRetentionManager creates a retention manager. multiLogger may be nil.
+func NewRetentionManager(repo retentionRepository, config *domain.RetentionConfig, multiLogger *logger.MultiLogger) *RetentionManager {
+	return &RetentionManager{
+		repo:        repo,
+		config:      config,
+		multiLogger: multiLogger,
+	}
+}
+
+// SetStoredFiles sets where the uploaded copies of expired files are removed
+// from (storage.backend). Copies of archived downloads are kept.
+func (m *RetentionManager) SetStoredFiles(storedFiles storedFileRemover) {
+	m.storedFiles = storedFiles
+}
+
+// Run applies the policy immediately and then every CheckInterval until ctx
+// is cancelled.
+func (m *RetentionManager) Run(ctx context.Context) {
+	interval := m.config.CheckInterval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.RunOnce(time.Now(), false); err != nil && m.multiLogger != nil {
+			m.multiLogger.LogAppError("Retention pass failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies the policy as of now. A dry run only reports what would be
+// expired. A download whose files cannot all be removed stays completed and
+// is retried on the next pass.
+func (m *RetentionManager) RunOnce(now time.Time, dryRun bool) (*RetentionResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	downloads, err := m.repo.FindAll(map[string]interface{}{
+		"status": domain.StatusCompleted,
+	}, domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed downloads: %w", err)
+	}
+
+	result := &RetentionResult{DryRun: dryRun, Downloads: []ExpiredDownload{}}
+	for _, dl := range domain.SelectExpired(downloads, m.config.MaxAge(), m.config.MaxSizeBytes(), now) {
+		if !dryRun {
+			if err := m.expire(dl); err != nil {
+				if m.multiLogger != nil {
+					m.multiLogger.LogAppError("Failed to expire download",
+						zap.String("download_id", dl.ID), zap.Error(err))
+				}
+				continue
+			}
+		}
+		result.Expired++
+		result.FreedBytes += dl.FileSize
+		result.Downloads = append(result.Downloads, ExpiredDownload{
+			ID:          dl.ID,
+			URL:         dl.URL,
+			Title:       dl.Title,
+			FileSize:    dl.FileSize,
+			CompletedAt: dl.CompletedAt,
+		})
+	}
+
+	if !dryRun && result.Expired > 0 && m.multiLogger != nil {
+		m.multiLogger.LogQueueEvent("retention_pass_complete",
+			zap.Int("expired", result.Expired),
+			zap.Int64("freed_bytes", result.FreedBytes))
+	}
+	return result, nil
+}
+
+// expire removes the download's files and marks it expired
+func (m *RetentionManager) expire(dl *domain.Download) error {
+	for _, file := range dl.Files() {
+		if err := infrastructure.RemoveWithSidecars(file, m.config.RemoveSidecars); err != nil {
+			return err
+		}
+		if m.storedFiles != nil && dl.RemoteURLOf(file) != "" && dl.ArchiveDir() == "" {
+			if err := m.storedFiles.Delete(context.Background(), file); err != nil {
+				return fmt.Errorf("failed to remove the uploaded copy of %s: %w", file, err)
+			}
+		}
+	}
+	dl.MarkExpired()
+	if err := m.repo.Update(dl); err != nil {
+		return fmt.Errorf("failed to update download: %w", err)
+	}
+	if m.multiLogger != nil {
+		m.multiLogger.LogQueueEvent("download_expired",
+			zap.String("download_id", dl.ID),
+			zap.String("url", dl.URL),
+			zap.Int64("file_size", dl.FileSize))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+)
+
+// mockRetentionRepo serves completed downloads and records updates
+type mockRetentionRepo struct {
+	downloads []*domain.Download
+	updated   []string
+}
+
+func (m *mockRetentionRepo) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
+	var found []*domain.Download
+	for _, d := range m.downloads {
+		if d.Status == filters["status"] {
+			found = append(found, d)
+		}
+	}
+	return found, nil
+}
+
+func (m *mockRetentionRepo) Update(download *domain.Download) error {
+	m.updated = append(m.updated, download.ID)
+	return nil
+}
+
+func TestRetentionManager_RunOnce(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		return path
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldAt := now.AddDate(0, 0, -100)
+	newAt := now.AddDate(0, 0, -1)
+
+	oldFile := write("old.mp4")
+	write("old.info.json")
+	newFile := write("new.mp4")
+	old := &domain.Download{ID: "old", Status: domain.StatusCompleted, FilePath: oldFile, FileSize: 10, CompletedAt: &oldAt,
+		Items: []domain.DownloadItem{{Status: domain.StatusCompleted, FilePath: oldFile, FileSize: 10}}}
+	recent := &domain.Download{ID: "new", Status: domain.StatusCompleted, FilePath: newFile, FileSize: 20, CompletedAt: &newAt}
+	repo := &mockRetentionRepo{downloads: []*domain.Download{old, recent}}
+	config := &domain.RetentionConfig{Enabled: true, MaxAgeDays: 90, RemoveSidecars: true}
+	m := NewRetentionManager(repo, config, nil)
+
+	// A dry run reports without touching files or records
+	result, err := m.RunOnce(now, true)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Expired)
+	assert.Equal(t, int64(10), result.FreedBytes)
+	assert.Equal(t, "old", result.Downloads[0].ID)
+	assert.True(t, infrastructure.FileExists(oldFile))
+	assert.Empty(t, repo.updated)
+
+	result, err = m.RunOnce(now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Expired)
+	assert.False(t, infrastructure.FileExists(oldFile))
+	assert.False(t, infrastructure.FileExists(filepath.Join(dir, "old.info.json")))
+	assert.True(t, infrastructure.FileExists(newFile))
+	assert.Equal(t, []string{"old"}, repo.updated)
+	assert.Equal(t, domain.StatusExpired, old.Status)
+	assert.Equal(t, domain.StatusExpired, old.Items[0].Status)
+
+	// Expired downloads are not selected again
+	result, err = m.RunOnce(now, false)
+	require.NoError(t, err)
+	assert.Zero(t, result.Expired)
+}
+
+func TestRetentionManager_RunOnceMaxSize(t *testing.T) {
+	now := time.Now()
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	repo := &mockRetentionRepo{downloads: []*domain.Download{
+		{ID: "a", Status: domain.StatusCompleted, FileSize: 300, CompletedAt: at(3)},
+		{ID: "b", Status: domain.StatusCompleted, FileSize: 300, CompletedAt: at(2)},
+		{ID: "c", Status: domain.StatusCompleted, FileSize: 300, CompletedAt: at(1)},
+	}}
+	m := NewRetentionManager(repo, &domain.RetentionConfig{MaxSize: "500B"}, nil)
+
+	result, err := m.RunOnce(now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Expired)
+	assert.Equal(t, int64(600), result.FreedBytes)
+	assert.Equal(t, []string{"a", "b"}, repo.updated)
+}
+
+// removedFiles records the files whose uploaded copies were removed
+type removedFiles []string
+
+func (r *removedFiles) Delete(ctx context.Context, file string) error {
+	*r = append(*r, file)
+	return nil
+}
+
+func TestRetentionManager_RemovesUploadedCopies(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldAt := now.AddDate(0, 0, -100)
+	uploaded := &domain.Download{ID: "uploaded", Status: domain.StatusCompleted, CompletedAt: &oldAt,
+		FilePath: "/completed/a.mp4", RemoteURL: "https://bucket.example.com/a.mp4"}
+	local := &domain.Download{ID: "local", Status: domain.StatusCompleted, CompletedAt: &oldAt,
+		FilePath: "/completed/b.mp4"}
+	repo := &mockRetentionRepo{downloads: []*domain.Download{uploaded, local}}
+	m := NewRetentionManager(repo, &domain.RetentionConfig{Enabled: true, MaxAgeDays: 90}, nil)
+	removed := &removedFiles{}
+	m.SetStoredFiles(removed)
+
+	result, err := m.RunOnce(now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Expired)
+	assert.Equal(t, removedFiles{"/completed/a.mp4"}, *removed)
+}
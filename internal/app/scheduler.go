@@ -0,0 +1,316 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// Scheduler runs recurring channel/profile syncs. On each due cron tick it
+// lists the source's items newer than the schedule cursor and enqueues them
+// as downloads through the QueueManager.
+type Scheduler struct {
+	repo        domain.ScheduleRepository
+	queueMgr    *QueueManager
+	syncers     map[domain.Platform]domain.SourceSyncer
+	config      *domain.SchedulerConfig
+	multiLogger *logger.MultiLogger
+	maintenance maintenanceGate // Holds due schedules outside maintenance.window (optional)
+	runMu       sync.Mutex      // Serializes schedule runs (ticker and manual "run now")
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(
+	repo domain.ScheduleRepository,
+	queueMgr *QueueManager,
+	syncers map[domain.Platform]domain.SourceSyncer,
+	config *domain.SchedulerConfig,
+	multiLogger *logger.MultiLogger,
+) *Scheduler {
+	return &Scheduler{
+		repo:        repo,
+		queueMgr:    queueMgr,
+		syncers:     syncers,
+		config:      config,
+		multiLogger: multiLogger,
+	}
+}
+
+// SetMaintenanceWindow sets the window scheduled syncs wait for
+// (maintenance.jobs channel_exports): due schedules stay due until it opens.
+// Runs started with RunNow do not wait.
+func (s *Scheduler) SetMaintenanceWindow(gate maintenanceGate) {
+	s.maintenance = gate
+}
+
+// ScheduleUpdate holds the fields of a schedule that can be changed after creation.
+// Nil fields are left untouched.
+type ScheduleUpdate struct {
+	Name     *string
+	CronExpr *string
+	Enabled  *bool
+}
+
+// Run checks for due schedules every CheckInterval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	interval := s.config.CheckInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.maintenance != nil && s.maintenance.Holds(domain.MaintenanceChannelExports) {
+				continue
+			}
+			s.RunDue(ctx, time.Now())
+		}
+	}
+}
+
+// RunDue runs every enabled schedule whose NextRunAt is at or before now and
+// returns the number of schedules run.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) int {
+	schedules, err := s.repo.FindAllSchedules()
+	if err != nil {
+		if s.multiLogger != nil {
+			s.multiLogger.LogAppError("Failed to list schedules", zap.Error(err))
+		}
+		return 0
+	}
+
+	ran := 0
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			break
+		}
+		if !schedule.IsDue(now) {
+			continue
+		}
+		s.runSchedule(ctx, schedule)
+		ran++
+	}
+	return ran
+}
+
+// RunNow runs a schedule immediately regardless of its next run time (or
+// whether it is enabled) and returns the updated schedule.
+func (s *Scheduler) RunNow(ctx context.Context, id string) (*domain.Schedule, error) {
+	schedule, err := s.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	s.runSchedule(ctx, schedule)
+	return schedule, nil
+}
+
+// runSchedule syncs a single schedule, enqueues new items, and records the
+// outcome (cursor, last run, error, next run) on the schedule.
+func (s *Scheduler) runSchedule(ctx context.Context, schedule *domain.Schedule) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	// Reload under the lock: a concurrent run may have advanced the cursor.
+	if latest, err := s.repo.FindScheduleByID(schedule.ID); err == nil && latest != nil {
+		*schedule = *latest
+	}
+
+	enqueued, err := s.syncSchedule(ctx, schedule)
+
+	now := time.Now()
+	schedule.LastRunAt = &now
+	schedule.LastEnqueued = enqueued
+	schedule.LastError = ""
+	if err != nil {
+		schedule.LastError = err.Error()
+	}
+	if nextErr := schedule.ScheduleNext(now); nextErr != nil {
+		// Cron expressions are validated on create/update; a schedule that can
+		// never fire again is disabled rather than retried every tick.
+		schedule.Enabled = false
+		schedule.NextRunAt = nil
+	}
+
+	if updateErr := s.repo.UpdateSchedule(schedule); updateErr != nil && s.multiLogger != nil {
+		s.multiLogger.LogAppError("Failed to update schedule",
+			zap.String("schedule_id", schedule.ID),
+			zap.Error(updateErr))
+	}
+
+	if s.multiLogger == nil {
+		return
+	}
+	if err != nil {
+		s.multiLogger.LogAppError("Schedule run failed",
+			zap.String("schedule_id", schedule.ID),
+			zap.String("source_url", schedule.SourceURL),
+			zap.Int("enqueued", enqueued),
+			zap.Error(err))
+		return
+	}
+	s.multiLogger.LogQueueEvent("schedule_run",
+		zap.String("schedule_id", schedule.ID),
+		zap.String("source_url", schedule.SourceURL),
+		zap.Int("enqueued", enqueued),
+		zap.String("cursor", schedule.Cursor))
+}
+
+// syncSchedule lists new items and enqueues them, advancing the cursor after
+// each enqueued item so a partial failure resumes where it stopped.
+func (s *Scheduler) syncSchedule(ctx context.Context, schedule *domain.Schedule) (int, error) {
+	syncer, ok := s.syncers[schedule.Platform]
+	if !ok {
+		return 0, fmt.Errorf("no syncer for platform: %s", schedule.Platform)
+	}
+
+	items, err := syncer.ListNewItems(ctx, schedule.SourceURL, schedule.Cursor, s.config.MaxItemsPerRun)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list new items: %w", err)
+	}
+
+	return enqueueSyncItems(s.queueMgr, items, AddDownloadOptions{Source: domain.SourceMonitor}, &schedule.Cursor)
+}
+
+// enqueueSyncItems enqueues the items listed by a sync with opts, setting
+// cursor to each item once it is enqueued or found to be a duplicate. It
+// stops at the first failure, returning how many items were enqueued.
+func enqueueSyncItems(queueMgr *QueueManager, items []domain.SyncItem, opts AddDownloadOptions, cursor *string) (int, error) {
+	enqueued := 0
+	for _, item := range items {
+		_, err := queueMgr.AddDownloadWithOptions(item.URL, item.Platform, item.Mode, opts)
+		var duplicate *domain.DuplicateDownloadError
+		if errors.As(err, &duplicate) {
+			// Already queued or downloaded, e.g. added by hand
+			*cursor = item.ID
+			continue
+		}
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to enqueue %s: %w", item.URL, err)
+		}
+		*cursor = item.ID
+		enqueued++
+	}
+	return enqueued, nil
+}
+
+// CreateSchedule validates and stores a new schedule. An empty platform is
+// detected from the source URL.
+func (s *Scheduler) CreateSchedule(name, sourceURL string, platform domain.Platform, cronExpr string) (*domain.Schedule, error) {
+	if platform == "" {
+		platform = domain.DetectPlatform(sourceURL)
+	}
+	if _, ok := s.syncers[platform]; !ok {
+		return nil, fmt.Errorf("scheduled syncs are not supported for platform: %s", platform)
+	}
+	if platform == domain.PlatformX && domain.DetectXURLType(sourceURL) != domain.XURLTypeTimeline {
+		return nil, fmt.Errorf("X schedules need an account URL (https://x.com/<user>), got: %s", sourceURL)
+	}
+
+	schedule, err := domain.NewSchedule(name, sourceURL, platform, cronExpr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateSchedule(schedule); err != nil {
+		return nil, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	if s.multiLogger != nil {
+		s.multiLogger.LogQueueEvent("schedule_created",
+			zap.String("schedule_id", schedule.ID),
+			zap.String("source_url", sourceURL),
+			zap.String("cron", cronExpr))
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule applies changes to a schedule. Changing the cron expression
+// or re-enabling a schedule recomputes its next run time.
+func (s *Scheduler) UpdateSchedule(id string, update ScheduleUpdate) (*domain.Schedule, error) {
+	schedule, err := s.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reschedule := false
+	if update.Name != nil {
+		schedule.Name = *update.Name
+	}
+	if update.CronExpr != nil && *update.CronExpr != schedule.CronExpr {
+		schedule.CronExpr = *update.CronExpr
+		reschedule = true
+	}
+	if update.Enabled != nil {
+		if *update.Enabled && !schedule.Enabled {
+			reschedule = true
+		}
+		schedule.Enabled = *update.Enabled
+	}
+	if reschedule {
+		if err := schedule.ScheduleNext(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateSchedule(schedule); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule deletes a schedule. Downloads it already enqueued are kept.
+func (s *Scheduler) DeleteSchedule(id string) error {
+	if _, err := s.GetSchedule(id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSchedule(id); err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if s.multiLogger != nil {
+		s.multiLogger.LogQueueEvent("schedule_deleted", zap.String("schedule_id", id))
+	}
+	return nil
+}
+
+// GetSchedule retrieves a schedule by ID
+func (s *Scheduler) GetSchedule(id string) (*domain.Schedule, error) {
+	schedule, err := s.repo.FindScheduleByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find schedule: %w", err)
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found: %s", id)
+	}
+	return schedule, nil
+}
+
+// ListSchedules returns all schedules
+func (s *Scheduler) ListSchedules() ([]*domain.Schedule, error) {
+	return s.repo.FindAllSchedules()
+}
+
+// HasEnabledSchedules reports whether any schedule is enabled. Used to keep
+// the server from auto-exiting while it still has syncs to run.
+func (s *Scheduler) HasEnabledSchedules() bool {
+	schedules, err := s.repo.FindAllSchedules()
+	if err != nil {
+		return false
+	}
+	for _, schedule := range schedules {
+		if schedule.Enabled {
+			return true
+		}
+	}
+	return false
+}
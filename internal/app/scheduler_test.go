@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// mockScheduleRepo implements domain.ScheduleRepository for testing
+type mockScheduleRepo struct {
+	schedules []*domain.Schedule
+}
+
+func (m *mockScheduleRepo) CreateSchedule(schedule *domain.Schedule) error {
+	m.schedules = append(m.schedules, schedule)
+	return nil
+}
+
+func (m *mockScheduleRepo) UpdateSchedule(schedule *domain.Schedule) error {
+	for i, s := range m.schedules {
+		if s.ID == schedule.ID {
+			copied := *schedule
+			m.schedules[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *mockScheduleRepo) DeleteSchedule(id string) error {
+	for i, s := range m.schedules {
+		if s.ID == id {
+			m.schedules = append(m.schedules[:i], m.schedules[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *mockScheduleRepo) FindScheduleByID(id string) (*domain.Schedule, error) {
+	for _, s := range m.schedules {
+		if s.ID == id {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockScheduleRepo) FindAllSchedules() ([]*domain.Schedule, error) {
+	result := make([]*domain.Schedule, 0, len(m.schedules))
+	for _, s := range m.schedules {
+		copied := *s
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// mockSyncer returns items with IDs greater than the cursor
+type mockSyncer struct {
+	items   []domain.SyncItem
+	err     error
+	cursors []string
+}
+
+func (m *mockSyncer) ListNewItems(ctx context.Context, sourceURL, cursor string, limit int) ([]domain.SyncItem, error) {
+	m.cursors = append(m.cursors, cursor)
+	if m.err != nil {
+		return nil, m.err
+	}
+	var result []domain.SyncItem
+	for _, item := range m.items {
+		if item.ID > cursor {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func newTestScheduler(syncer domain.SourceSyncer) (*Scheduler, *mockScheduleRepo, *mockRepo) {
+	downloads := newMockRepo()
+	schedules := &mockScheduleRepo{}
+	syncers := map[domain.Platform]domain.SourceSyncer{domain.PlatformTelegram: syncer}
+	config := &domain.SchedulerConfig{Enabled: true, CheckInterval: time.Second, MaxItemsPerRun: 50}
+	return NewScheduler(schedules, newTestQueueManager(downloads), syncers, config, nil), schedules, downloads
+}
+
+func TestScheduler_RunDue_EnqueuesNewItemsAndAdvancesCursor(t *testing.T) {
+	syncer := &mockSyncer{items: []domain.SyncItem{
+		{ID: "101", URL: "https://t.me/c/123/101", Platform: domain.PlatformTelegram, Mode: domain.ModeSingle},
+		{ID: "102", URL: "https://t.me/c/123/102", Platform: domain.PlatformTelegram, Mode: domain.ModeSingle},
+	}}
+	s, schedules, downloads := newTestScheduler(syncer)
+
+	schedule, err := s.CreateSchedule("chan", "https://t.me/c/123", "", "*/5 * * * *")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlatformTelegram, schedule.Platform)
+
+	// Not due yet
+	assert.Equal(t, 0, s.RunDue(context.Background(), time.Now()))
+
+	now := schedule.NextRunAt.Add(time.Second)
+	assert.Equal(t, 1, s.RunDue(context.Background(), now))
+	assert.Len(t, downloads.downloads, 2)
+	for _, dl := range downloads.downloads {
+		assert.Equal(t, domain.SourceMonitor, dl.Source)
+	}
+
+	stored := schedules.schedules[0]
+	assert.Equal(t, "102", stored.Cursor)
+	assert.Equal(t, 2, stored.LastEnqueued)
+	assert.Empty(t, stored.LastError)
+	require.NotNil(t, stored.LastRunAt)
+	require.NotNil(t, stored.NextRunAt)
+	assert.True(t, stored.NextRunAt.After(*stored.LastRunAt))
+
+	// A second run only asks for items after the cursor
+	_, err = s.RunNow(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "102"}, syncer.cursors)
+	assert.Len(t, downloads.downloads, 2)
+	assert.Equal(t, 0, schedules.schedules[0].LastEnqueued)
+}
+
+func TestScheduler_RunNow_SkipsQueuedItems(t *testing.T) {
+	syncer := &mockSyncer{items: []domain.SyncItem{
+		{ID: "101", URL: "https://t.me/c/123/101", Platform: domain.PlatformTelegram, Mode: domain.ModeSingle},
+		{ID: "102", URL: "https://t.me/c/123/102", Platform: domain.PlatformTelegram, Mode: domain.ModeSingle},
+	}}
+	s, schedules, downloads := newTestScheduler(syncer)
+	_, err := s.queueMgr.AddDownload("https://t.me/c/123/101", domain.PlatformTelegram, domain.ModeSingle, "")
+	require.NoError(t, err)
+
+	schedule, err := s.CreateSchedule("", "https://t.me/c/123", "", "@hourly")
+	require.NoError(t, err)
+	result, err := s.RunNow(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	assert.Empty(t, result.LastError)
+	assert.Equal(t, 1, schedules.schedules[0].LastEnqueued)
+	assert.Equal(t, "102", schedules.schedules[0].Cursor)
+	assert.Len(t, downloads.downloads, 2)
+}
+
+func TestScheduler_RunNow_RecordsSyncError(t *testing.T) {
+	syncer := &mockSyncer{err: errors.New("tdl failed")}
+	s, schedules, _ := newTestScheduler(syncer)
+
+	schedule, err := s.CreateSchedule("", "https://t.me/c/123", domain.PlatformTelegram, "@hourly")
+	require.NoError(t, err)
+
+	result, err := s.RunNow(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	assert.Contains(t, result.LastError, "tdl failed")
+	assert.Contains(t, schedules.schedules[0].LastError, "tdl failed")
+	assert.True(t, schedules.schedules[0].Enabled)
+}
+
+func TestScheduler_CreateSchedule_Validation(t *testing.T) {
+	s, _, _ := newTestScheduler(&mockSyncer{})
+
+	_, err := s.CreateSchedule("", "https://t.me/c/123", "", "not a cron")
+	assert.Error(t, err)
+
+	// No syncer registered for X in this scheduler
+	_, err = s.CreateSchedule("", "https://x.com/someone", "", "@daily")
+	assert.Error(t, err)
+
+	_, err = s.RunNow(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestScheduler_UpdateSchedule(t *testing.T) {
+	s, _, _ := newTestScheduler(&mockSyncer{})
+
+	schedule, err := s.CreateSchedule("", "https://t.me/c/123", "", "@daily")
+	require.NoError(t, err)
+	assert.True(t, s.HasEnabledSchedules())
+
+	disabled := false
+	updated, err := s.UpdateSchedule(schedule.ID, ScheduleUpdate{Enabled: &disabled})
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.False(t, s.HasEnabledSchedules())
+
+	bad := "61 * * * *"
+	_, err = s.UpdateSchedule(schedule.ID, ScheduleUpdate{CronExpr: &bad})
+	assert.Error(t, err)
+
+	require.NoError(t, s.DeleteSchedule(schedule.ID))
+	_, err = s.GetSchedule(schedule.ID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// selfTestRepository is the persistence the self-test checks
+type selfTestRepository interface {
+	Count() (int64, error)
+}
+
+// selfTestSimulator lists the media of a URL (DownloadManager.Simulate)
+type selfTestSimulator interface {
+	Simulate(ctx context.Context, url string, platform domain.Platform, mode domain.DownloadMode, opts AddDownloadOptions) (*domain.Simulation, error)
+}
+
+// SelfTestOptions narrows a self-test run
+type SelfTestOptions struct {
+	Platforms []domain.Platform // Platforms to test (empty = all)
+	URLs      map[string]string // URLs replacing those of selftest.urls, by platform
+}
+
+// SelfTester checks the download pipeline end to end without downloading: the
+// database, the download directories, and per platform its tools, its cookies
+// and the listing of the media of a known-safe URL (selftest.urls). It is
+// meant to be run after upgrades or cookie refreshes.
+type SelfTester struct {
+	repo      selfTestRepository
+	simulator selfTestSimulator
+	health    *PlatformHealthChecker
+	config    *domain.SelfTestConfig
+	dirs      []string
+}
+
+// NewSelfTester creates a self-tester. dirs are the directories downloads
+// are written to (incoming/ and completed/).
+func NewSelfTester(repo selfTestRepository, simulator selfTestSimulator, health *PlatformHealthChecker, config *domain.SelfTestConfig, dirs ...string) *SelfTester {
+	return &SelfTester{
+		repo:      repo,
+		simulator: simulator,
+		health:    health,
+		config:    config,
+		dirs:      dirs,
+	}
+}
+
+// Run runs the self-test. Failed stages are reported in the result; the error
+// is for options that are invalid.
+func (t *SelfTester) Run(ctx context.Context, opts SelfTestOptions) (*domain.SelfTestResult, error) {
+	for platform := range opts.URLs {
+		if !domain.ValidatePlatform(domain.Platform(platform)) {
+			return nil, fmt.Errorf("invalid platform: %s", platform)
+		}
+	}
+	selected := make(map[domain.Platform]bool, len(opts.Platforms))
+	for _, platform := range opts.Platforms {
+		if !domain.ValidatePlatform(platform) {
+			return nil, fmt.Errorf("invalid platform: %s", platform)
+		}
+		selected[platform] = true
+	}
+
+	result := &domain.SelfTestResult{StartedAt: time.Now()}
+	result.Stages = []domain.SelfTestStage{
+		runStage(domain.SelfTestDatabase, t.checkDatabase),
+		runStage(domain.SelfTestDirectories, t.checkDirectories),
+	}
+	result.Passed = domain.StagesPassed(result.Stages)
+
+	for _, health := range t.health.Check() {
+		if len(selected) > 0 && !selected[health.Platform] {
+			continue
+		}
+		platform := t.testPlatform(ctx, health, opts.URLs)
+		result.Passed = result.Passed && platform.Passed
+		result.Platforms = append(result.Platforms, platform)
+	}
+	result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+	return result, nil
+}
+
+// testPlatform runs the stages of one platform
+func (t *SelfTester) testPlatform(ctx context.Context, health domain.PlatformHealth, urls map[string]string) domain.SelfTestPlatform {
+	platform := domain.SelfTestPlatform{Platform: health.Platform, URL: urls[string(health.Platform)]}
+	if platform.URL == "" {
+		platform.URL = t.config.URLs[string(health.Platform)]
+	}
+
+	binary := runStage(domain.SelfTestBinary, func() (string, string) {
+		if len(health.MissingBinaries) > 0 {
+			return domain.SelfTestFailed, "not found: " + strings.Join(health.MissingBinaries, ", ")
+		}
+		return domain.SelfTestPassed, ""
+	})
+	cookies := runStage(domain.SelfTestCookies, func() (string, string) {
+		return t.checkCookies(ctx, health.Platform)
+	})
+	simulate := runStage(domain.SelfTestSimulate, func() (string, string) {
+		switch {
+		case platform.URL == "":
+			return domain.SelfTestSkipped, fmt.Sprintf("no selftest.urls.%s configured", health.Platform)
+		case binary.Status == domain.SelfTestFailed:
+			return domain.SelfTestSkipped, "the tool is missing"
+		}
+		return t.simulate(ctx, health.Platform, platform.URL)
+	})
+
+	platform.Stages = []domain.SelfTestStage{binary, cookies, simulate}
+	platform.Passed = domain.StagesPassed(platform.Stages)
+	return platform
+}
+
+// runStage times check, which returns the stage status and message
+func runStage(name string, check func() (string, string)) domain.SelfTestStage {
+	started := time.Now()
+	status, message := check()
+	return domain.SelfTestStage{
+		Name:       name,
+		Status:     status,
+		Message:    message,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+}
+
+func (t *SelfTester) checkDatabase() (string, string) {
+	count, err := t.repo.Count()
+	if err != nil {
+		return domain.SelfTestFailed, err.Error()
+	}
+	return domain.SelfTestPassed, fmt.Sprintf("%d download(s)", count)
+}
+
+// checkDirectories creates and removes a file in each download directory
+func (t *SelfTester) checkDirectories() (string, string) {
+	for _, dir := range t.dirs {
+		file, err := os.CreateTemp(dir, ".selftest-*")
+		if err != nil {
+			return domain.SelfTestFailed, fmt.Sprintf("%s is not writable: %v", dir, err)
+		}
+		file.Close()
+		os.Remove(file.Name())
+	}
+	return domain.SelfTestPassed, ""
+}
+
+// checkCookies checks the cookies of platform now. Expired or rejected
+// cookies fail; platforms without a cookie check or cookie file are skipped.
+func (t *SelfTester) checkCookies(ctx context.Context, platform domain.Platform) (string, string) {
+	status, ok := t.health.CheckCookies(ctx, platform)
+	if !ok {
+		return domain.SelfTestSkipped, "no cookie check for this platform"
+	}
+	message := string(status.State)
+	if status.Message != "" {
+		message += ": " + status.Message
+	}
+	switch status.State {
+	case domain.CookieOK, domain.CookieExpiring:
+		return domain.SelfTestPassed, message
+	case domain.CookieExpired, domain.CookieInvalid:
+		return domain.SelfTestFailed, message
+	}
+	return domain.SelfTestSkipped, message
+}
+
+// simulate lists the media of url, failing when the tool reports an error or
+// lists nothing
+func (t *SelfTester) simulate(ctx context.Context, platform domain.Platform, url string) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	sim, err := t.simulator.Simulate(ctx, url, platform, domain.ModeDefault, AddDownloadOptions{})
+	switch {
+	case err != nil:
+		return domain.SelfTestFailed, err.Error()
+	case sim.Error != "":
+		if sim.ErrorCode != "" {
+			return domain.SelfTestFailed, fmt.Sprintf("%s (%s)", sim.Error, sim.ErrorCode)
+		}
+		return domain.SelfTestFailed, sim.Error
+	case len(sim.Items) == 0:
+		return domain.SelfTestFailed, "no media listed"
+	}
+	message := fmt.Sprintf("%d item(s)", len(sim.Items))
+	if size := sim.TotalSize(); size > 0 {
+		message += ", " + domain.FormatBytes(size)
+	}
+	return domain.SelfTestPassed, message
+}
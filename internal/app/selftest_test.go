@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// countRepo counts a fixed number of downloads
+type countRepo int64
+
+func (r countRepo) Count() (int64, error) { return int64(r), nil }
+
+// mockSimulator lists preset media per URL
+type mockSimulator map[string]*domain.Simulation
+
+func (m mockSimulator) Simulate(ctx context.Context, url string, platform domain.Platform, mode domain.DownloadMode, opts AddDownloadOptions) (*domain.Simulation, error) {
+	if sim, ok := m[url]; ok {
+		return sim, nil
+	}
+	return nil, errors.New("unsupported URL")
+}
+
+func newSelfTestHealth(t *testing.T, cookie domain.CookieState) *PlatformHealthChecker {
+	t.Helper()
+	monitor := NewCookieMonitor(domain.PlatformX, &mockCookieChecker{state: cookie}, nil, time.Hour, time.Hour, nil)
+	health := NewPlatformHealthChecker(map[domain.Platform][]string{
+		domain.PlatformX:        {"yt-dlp"},
+		domain.PlatformTelegram: {"tdl"},
+	}, map[domain.Platform]*CookieMonitor{domain.PlatformX: monitor}, nil)
+	health.lookPath = func(file string) (string, error) {
+		if file == "tdl" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+	return health
+}
+
+func stageStatuses(stages []domain.SelfTestStage) map[string]string {
+	statuses := make(map[string]string, len(stages))
+	for _, stage := range stages {
+		statuses[stage.Name] = stage.Status
+	}
+	return statuses
+}
+
+func TestSelfTester_Run(t *testing.T) {
+	simulator := mockSimulator{
+		"https://x.com/user/status/1": {Items: []domain.SimulatedItem{{ID: "1", Size: 2048}}},
+	}
+	config := &domain.SelfTestConfig{
+		URLs:    map[string]string{"x": "https://x.com/user/status/1", "telegram": "https://t.me/channel/1"},
+		Timeout: time.Minute,
+	}
+	tester := NewSelfTester(countRepo(4), simulator, newSelfTestHealth(t, domain.CookieOK), config, t.TempDir())
+
+	result, err := tester.Run(context.Background(), SelfTestOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, map[string]string{
+		domain.SelfTestDatabase:    domain.SelfTestPassed,
+		domain.SelfTestDirectories: domain.SelfTestPassed,
+	}, stageStatuses(result.Stages))
+
+	require.Len(t, result.Platforms, 2)
+	telegram, x := result.Platforms[0], result.Platforms[1]
+	assert.False(t, telegram.Passed)
+	assert.Equal(t, map[string]string{
+		domain.SelfTestBinary:   domain.SelfTestFailed,
+		domain.SelfTestCookies:  domain.SelfTestSkipped,
+		domain.SelfTestSimulate: domain.SelfTestSkipped,
+	}, stageStatuses(telegram.Stages))
+	assert.True(t, x.Passed)
+	assert.Equal(t, "https://x.com/user/status/1", x.URL)
+	assert.Equal(t, domain.SelfTestPassed, x.Stages[2].Status)
+	assert.Equal(t, "1 item(s), 2.0 KiB", x.Stages[2].Message)
+}
+
+func TestSelfTester_RunSelectedPlatform(t *testing.T) {
+	simulator := mockSimulator{
+		"https://x.com/user/status/2": {Error: "403 Forbidden", ErrorCode: domain.ErrorAuthExpired},
+	}
+	config := &domain.SelfTestConfig{Timeout: time.Minute}
+	tester := NewSelfTester(countRepo(0), simulator, newSelfTestHealth(t, domain.CookieExpired), config, t.TempDir())
+
+	result, err := tester.Run(context.Background(), SelfTestOptions{
+		Platforms: []domain.Platform{domain.PlatformX},
+		URLs:      map[string]string{"x": "https://x.com/user/status/2"},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	require.Len(t, result.Platforms, 1)
+	assert.Equal(t, map[string]string{
+		domain.SelfTestBinary:   domain.SelfTestPassed,
+		domain.SelfTestCookies:  domain.SelfTestFailed,
+		domain.SelfTestSimulate: domain.SelfTestFailed,
+	}, stageStatuses(result.Platforms[0].Stages))
+	assert.Equal(t, "403 Forbidden (auth_expired)", result.Platforms[0].Stages[2].Message)
+
+	_, err = tester.Run(context.Background(), SelfTestOptions{Platforms: []domain.Platform{"myspace"}})
+	assert.Error(t, err)
+}
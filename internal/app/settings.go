@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// settingsNotifier is the notification toggle used by SettingsManager
+type settingsNotifier interface {
+	SetEnabled(enabled, sound bool)
+}
+
+// logLevelSetter changes the level of the server's logs, for config reloads
+type logLevelSetter interface {
+	SetLevel(level string) error
+}
+
+// SettingsManager reads and changes the runtime settings (queue check
+// interval and auto-exit, retries, per-platform concurrency, rate limits,
+// notifications) of a running server and saves changed settings back to the config file.
+// The managers and the notifier must share config's sections, which they
+// update under their own locks.
+type SettingsManager struct {
+	config      *domain.Config
+	queueMgr    *QueueManager
+	downloadMgr *DownloadManager
+	notifier    settingsNotifier
+	mu          sync.Mutex // Serializes updates and reloads
+
+	logLevel   logLevelSetter                 // Optional
+	loadConfig func() (*domain.Config, error) // Reads the config files for Reload
+}
+
+// NewSettingsManager creates a settings manager that saves to the user
+// override (base_dir/config/config.yaml), like UpdateConfig. notifier may be
+// nil.
+func NewSettingsManager(
+	config *domain.Config,
+	queueMgr *QueueManager,
+	downloadMgr *DownloadManager,
+	notifier settingsNotifier,
+) *SettingsManager {
+	return &SettingsManager{
+		config:      config,
+		queueMgr:    queueMgr,
+		downloadMgr: downloadMgr,
+		notifier:    notifier,
+		loadConfig:  LoadConfig,
+	}
+}
+
+// SetLogLevelSetter sets the logger whose level Reload changes
+func (m *SettingsManager) SetLogLevelSetter(logLevel logLevelSetter) {
+	m.logLevel = logLevel
+}
+
+// Get returns the current runtime settings
+func (m *SettingsManager) Get() domain.RuntimeSettings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return domain.RuntimeSettingsFrom(m.config)
+}
+
+// Update validates patch, saves the settings it changes to the user override
+// and applies them. Nothing is applied when the patch is invalid or cannot be saved.
+func (m *SettingsManager) Update(patch domain.RuntimeSettingsPatch) (domain.RuntimeSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values, err := patch.ConfigValues()
+	if err != nil {
+		return domain.RuntimeSettings{}, err
+	}
+	if len(values) == 0 {
+		return domain.RuntimeSettingsFrom(m.config), nil
+	}
+	if err := UpdateConfigFile(UserConfigPath(m.config), values); err != nil {
+		return domain.RuntimeSettings{}, fmt.Errorf("failed to save settings: %w", err)
+	}
+	m.apply(patch)
+	return domain.RuntimeSettingsFrom(m.config), nil
+}
+
+// apply applies a validated patch to the managers and the notifier. The
+// caller holds m.mu.
+func (m *SettingsManager) apply(patch domain.RuntimeSettingsPatch) {
+	queue := m.queueMgr.queueSettings()
+	if patch.CheckInterval != nil {
+		queue.CheckInterval, _ = time.ParseDuration(*patch.CheckInterval)
+	}
+	if patch.AutoExitOnEmpty != nil {
+		queue.AutoExitOnEmpty = *patch.AutoExitOnEmpty
+	}
+	if patch.EmptyWaitTime != nil {
+		queue.EmptyWaitTime, _ = time.ParseDuration(*patch.EmptyWaitTime)
+	}
+	if patch.DeferExitForClients != nil {
+		queue.DeferExitForClients = *patch.DeferExitForClients
+	}
+	m.queueMgr.SetQueueSettings(queue)
+
+	if patch.MaxRetries != nil || patch.RetryDelay != nil {
+		maxRetries, retryDelay := m.downloadMgr.retryPolicy()
+		if patch.MaxRetries != nil {
+			maxRetries = *patch.MaxRetries
+		}
+		if patch.RetryDelay != nil {
+			retryDelay, _ = time.ParseDuration(*patch.RetryDelay)
+		}
+		m.downloadMgr.SetRetryPolicy(maxRetries, retryDelay)
+	}
+	if patch.PlatformConcurrency != nil {
+		m.downloadMgr.SetPlatformConcurrency(*patch.PlatformConcurrency)
+	}
+	if patch.RateLimit != nil || patch.RateLimitOverrides != nil {
+		rateLimit, overrides := m.config.Download.RateLimit, m.config.Download.RateLimitOverrides
+		if patch.RateLimit != nil {
+			rateLimit = *patch.RateLimit
+		}
+		if patch.RateLimitOverrides != nil {
+			overrides = *patch.RateLimitOverrides
+		}
+		m.downloadMgr.SetRateLimits(rateLimit, overrides)
+	}
+
+	if patch.NotificationsEnabled != nil || patch.NotificationSound != nil {
+		enabled, sound := m.config.Notification.Enabled, m.config.Notification.Sound
+		if patch.NotificationsEnabled != nil {
+			enabled = *patch.NotificationsEnabled
+		}
+		if patch.NotificationSound != nil {
+			sound = *patch.NotificationSound
+		}
+		if m.notifier != nil {
+			m.notifier.SetEnabled(enabled, sound)
+		} else {
+			m.config.Notification.Enabled, m.config.Notification.Sound = enabled, sound
+		}
+	}
+}
+
+// EffectiveConfig is the merged configuration of a running server
+type EffectiveConfig struct {
+	SystemConfig string         `json:"system_config"` // Path of the system config file
+	UserConfig   string         `json:"user_config"`   // Path of the user override file
+	Options      []ConfigOption `json:"options"`
+}
+
+// EffectiveConfig describes every config key with its current value, runtime
+// changes included, and where it came from. Secrets are redacted.
+func (m *SettingsManager) EffectiveConfig() (*EffectiveConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	options, err := DescribeConfig(m.config)
+	if err != nil {
+		return nil, err
+	}
+	return &EffectiveConfig{
+		SystemConfig: domain.DefaultConfigPath(),
+		UserConfig:   UserConfigPath(m.config),
+		Options:      RedactConfigOptions(options),
+	}, nil
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+// mockSettingsNotifier records notification toggles
+type mockSettingsNotifier struct {
+	enabled, sound bool
+}
+
+func (m *mockSettingsNotifier) SetEnabled(enabled, sound bool) {
+	m.enabled, m.sound = enabled, sound
+}
+
+func TestSettingsManager_UpdateAppliesAndSaves(t *testing.T) {
+	config := domain.DefaultConfig()
+	config.Download.BaseDir = t.TempDir()
+	configPath := UserConfigPath(config)
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0755))
+	require.NoError(t, os.WriteFile(configPath, []byte(`# X-Extract Configuration
+queue:
+  # How often the queue is checked
+  check_interval: 2s
+  auto_exit_on_empty: true
+download:
+  max_retries: 3 # per download
+`), 0644))
+
+	downloaders := map[domain.Platform]domain.Downloader{domain.PlatformX: &blockingDownloader{}}
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), downloaders, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	notifier := &mockSettingsNotifier{}
+	m := NewSettingsManager(config, qm, dm, notifier)
+
+	interval, autoExit, retries, concurrency, enabled := "5s", false, 1, 2, false
+	settings, err := m.Update(domain.RuntimeSettingsPatch{
+		CheckInterval:        &interval,
+		AutoExitOnEmpty:      &autoExit,
+		MaxRetries:           &retries,
+		PlatformConcurrency:  &concurrency,
+		NotificationsEnabled: &enabled,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "5s", settings.CheckInterval)
+	assert.False(t, settings.AutoExitOnEmpty)
+	assert.Equal(t, 1, settings.MaxRetries)
+	assert.Equal(t, "30s", settings.RetryDelay, "unchanged")
+	assert.Equal(t, 2, settings.PlatformConcurrency)
+
+	assert.Equal(t, 5*time.Second, qm.queueSettings().CheckInterval)
+	maxRetries, _ := dm.retryPolicy()
+	assert.Equal(t, 1, maxRetries)
+	assert.Equal(t, 2, cap(dm.platformSemaphores[domain.PlatformX]))
+	assert.False(t, notifier.enabled)
+	assert.True(t, notifier.sound, "sound keeps its value")
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "# X-Extract Configuration")
+	assert.Contains(t, content, "  # How often the queue is checked\n  check_interval: 5s\n")
+	assert.Contains(t, content, "  auto_exit_on_empty: false\n")
+	assert.Contains(t, content, "  max_retries: 1 # per download\n")
+	assert.Contains(t, content, "  platform_concurrency: 2\n")
+	assert.Contains(t, content, "notification:\n  enabled: false\n", "missing sections are added")
+	assert.NotContains(t, content, "retry_delay", "only changed settings are written")
+
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	require.NoError(t, v.ReadInConfig())
+	loaded := domain.DefaultConfig()
+	require.NoError(t, v.Unmarshal(loaded))
+	assert.Equal(t, 5*time.Second, loaded.Queue.CheckInterval)
+	assert.Equal(t, 2, loaded.Download.PlatformConcurrency)
+}
+
+func TestSettingsManager_UpdateRejectsInvalidPatch(t *testing.T) {
+	config := domain.DefaultConfig()
+	config.Download.BaseDir = t.TempDir()
+	configPath := UserConfigPath(config)
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	m := NewSettingsManager(config, qm, dm, nil)
+
+	for _, patch := range []domain.RuntimeSettingsPatch{
+		{CheckInterval: strPtr("0s")},
+		{RetryDelay: strPtr("soon")},
+		{MaxRetries: intPtr(-1)},
+		{PlatformConcurrency: intPtr(0)},
+		{RateLimit: strPtr("fast")},
+		{RateLimitOverrides: &map[string]string{"myspace": "1MB"}},
+	} {
+		_, err := m.Update(patch)
+		assert.Error(t, err)
+	}
+	assert.NoFileExists(t, configPath)
+	assert.Equal(t, domain.DefaultConfig().Queue.CheckInterval, qm.queueSettings().CheckInterval)
+}
+
+func TestSettingsManager_UpdateRateLimits(t *testing.T) {
+	config := domain.DefaultConfig()
+	config.Download.BaseDir = t.TempDir()
+	configPath := UserConfigPath(config)
+	dm := NewDownloadManager(newMockDownloadManagerRepo(), nil, nil, &config.Download, zap.NewNop())
+	qm := NewQueueManager(newMockRepo(), dm, &config.Queue, nil, t.TempDir())
+	m := NewSettingsManager(config, qm, dm, nil)
+
+	settings, err := m.Update(domain.RuntimeSettingsPatch{
+		RateLimit:          strPtr("1MiB"),
+		RateLimitOverrides: &map[string]string{"telegram": "0"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1MiB", settings.RateLimit)
+	assert.Equal(t, int64(1<<20), dm.RateLimitFor(domain.PlatformX))
+	assert.Zero(t, dm.RateLimitFor(domain.PlatformTelegram))
+
+	// Changing the global limit keeps the overrides
+	_, err = m.Update(domain.RuntimeSettingsPatch{RateLimit: strPtr("")})
+	require.NoError(t, err)
+	assert.Zero(t, dm.RateLimitFor(domain.PlatformX))
+	assert.Equal(t, map[string]string{"telegram": "0"}, config.Download.RateLimitOverrides)
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "rate_limit_overrides:\n    telegram: \"0\"\n")
+}
+
+func strPtr(s string) *string { return &s }
+func intPtr(n int) *int       { return &n }
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// Simulate reports what a download of url with opts would fetch, and how,
+// without queueing it or downloading anything. Only the options that change
+// the download are used: range end, all variants, account and tdl profile.
+func (dm *DownloadManager) Simulate(ctx context.Context, url string, platform domain.Platform, mode domain.DownloadMode, opts AddDownloadOptions) (*domain.Simulation, error) {
+	if !domain.ValidatePlatform(platform) {
+		return nil, fmt.Errorf("invalid platform: %s", platform)
+	}
+	if !domain.ValidateMode(mode) {
+		return nil, fmt.Errorf("invalid mode: %s", mode)
+	}
+	if mode == domain.ModeProfile && platform != domain.PlatformX {
+		return nil, fmt.Errorf("profile mode is only supported for the x platform")
+	}
+	if opts.Account != "" && platform != domain.PlatformX {
+		return nil, fmt.Errorf("accounts are only supported for the x platform")
+	}
+	if opts.Profile != "" && platform != domain.PlatformTelegram {
+		return nil, fmt.Errorf("tdl profiles are only supported for the telegram platform")
+	}
+
+	download := domain.NewDownload(url, platform, mode)
+	download.Account = opts.Account
+	download.TDLProfile = opts.Profile
+	download.AllVariants = opts.AllVariants
+	if opts.RangeEnd != 0 {
+		if platform != domain.PlatformTelegram {
+			return nil, fmt.Errorf("message ranges are only supported for the telegram platform")
+		}
+		start, err := domain.ValidateMessageRange(url, opts.RangeEnd)
+		if err != nil {
+			return nil, err
+		}
+		download.RangeStart = start
+		download.RangeEnd = opts.RangeEnd
+	}
+
+	simulator, ok := dm.downloaders[platform].(domain.Simulator)
+	if !ok {
+		return nil, fmt.Errorf("simulation is not supported for platform: %s", platform)
+	}
+	return simulator.Simulate(ctx, download)
+}
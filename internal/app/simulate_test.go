@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// simulatingDownloader records the download it simulated
+type simulatingDownloader struct {
+	resultDownloader
+	simulated *domain.Download
+}
+
+func (s *simulatingDownloader) Simulate(ctx context.Context, download *domain.Download) (*domain.Simulation, error) {
+	s.simulated = download
+	return &domain.Simulation{URL: download.URL, Platform: download.Platform, Tool: "tdl"}, nil
+}
+
+func TestSimulate(t *testing.T) {
+	repo := newMockDownloadManagerRepo()
+	simulator := &simulatingDownloader{}
+	dm := NewDownloadManager(repo, map[domain.Platform]domain.Downloader{
+		domain.PlatformTelegram: simulator,
+		domain.PlatformX:        &resultDownloader{},
+	}, nil, &domain.DownloadConfig{}, nil)
+
+	sim, err := dm.Simulate(context.Background(), "https://t.me/news/100", domain.PlatformTelegram, domain.ModeDefault,
+		AddDownloadOptions{RangeEnd: 120, Profile: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, "tdl", sim.Tool)
+	assert.Equal(t, 100, simulator.simulated.RangeStart)
+	assert.Equal(t, 120, simulator.simulated.RangeEnd)
+	assert.Equal(t, "work", simulator.simulated.TDLProfile)
+
+	downloads, err := repo.FindAll(nil, domain.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, downloads, "nothing is queued")
+
+	_, err = dm.Simulate(context.Background(), "https://x.com/a/status/1", domain.PlatformX, domain.ModeDefault, AddDownloadOptions{})
+	assert.Error(t, err, "downloader without simulation support")
+
+	_, err = dm.Simulate(context.Background(), "https://t.me/news/100", domain.PlatformTelegram, domain.ModeDefault,
+		AddDownloadOptions{Account: "alt"})
+	assert.Error(t, err, "accounts are for X")
+}
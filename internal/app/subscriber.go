@@ -0,0 +1,356 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+// subscriptionCheckTick is how often the subscriber looks for due
+// subscriptions
+const subscriptionCheckTick = 30 * time.Second
+
+// cachedSource is a subscription source that keeps the posts it has seen
+// (TelegramDownloader and its message cache)
+type cachedSource interface {
+	// NewestCachedID returns the ID of the newest post of sourceURL already
+	// seen, or "" when none is
+	NewestCachedID(sourceURL string) (string, error)
+}
+
+// SubscriptionSources returns the source listing the posts of platform
+// (TelegramDownloader with a tdl profile, TwitterDownloader), or nil for
+// platforms without subscriptions
+type SubscriptionSources func(platform domain.Platform, profile string) domain.SourceSyncer
+
+// SubscribeOptions holds the optional settings of a new subscription
+type SubscribeOptions struct {
+	Name     string
+	Profile  string // Telegram: tdl profile (empty = telegram.profile)
+	Interval string // Check interval (empty = subscriptions.interval or its platform override)
+	Backfill bool   // Enqueue every post of the source, not only new ones
+}
+
+// SubscriptionUpdate holds the fields of a subscription that can be changed
+// after creation. Nil fields are left untouched.
+type SubscriptionUpdate struct {
+	Name     *string
+	Interval *string
+	Enabled  *bool
+}
+
+// Subscriber checks subscribed Telegram channels and X accounts every
+// interval and enqueues their new media posts as downloads through the
+// QueueManager. The first check of a subscription starts after the newest
+// post in the message cache; with nothing cached (or for X accounts) it only
+// records the newest post of the source, so only posts published after
+// subscribing are downloaded.
+type Subscriber struct {
+	repo        domain.SubscriptionRepository
+	queueMgr    *QueueManager
+	sources     SubscriptionSources
+	profiles    telegramProfileSource
+	config      *domain.SubscriptionConfig
+	multiLogger *logger.MultiLogger
+	checkMu     sync.Mutex // Serializes checks (ticker and manual "check now")
+}
+
+// NewSubscriber creates a subscriber. profiles validates the tdl profile of
+// new subscriptions.
+func NewSubscriber(
+	repo domain.SubscriptionRepository,
+	queueMgr *QueueManager,
+	sources SubscriptionSources,
+	profiles telegramProfileSource,
+	config *domain.SubscriptionConfig,
+	multiLogger *logger.MultiLogger,
+) *Subscriber {
+	return &Subscriber{
+		repo:        repo,
+		queueMgr:    queueMgr,
+		sources:     sources,
+		profiles:    profiles,
+		config:      config,
+		multiLogger: multiLogger,
+	}
+}
+
+// Run checks for due subscriptions until ctx is cancelled
+func (s *Subscriber) Run(ctx context.Context) {
+	ticker := time.NewTicker(subscriptionCheckTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckDue(ctx, time.Now())
+		}
+	}
+}
+
+// CheckDue checks every enabled subscription whose NextCheckAt is at or
+// before now and returns the number of subscriptions checked
+func (s *Subscriber) CheckDue(ctx context.Context, now time.Time) int {
+	subscriptions, err := s.repo.FindAllSubscriptions()
+	if err != nil {
+		if s.multiLogger != nil {
+			s.multiLogger.LogAppError("Failed to list subscriptions", zap.Error(err))
+		}
+		return 0
+	}
+
+	checked := 0
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			break
+		}
+		if !subscription.IsDue(now) {
+			continue
+		}
+		s.check(ctx, subscription)
+		checked++
+	}
+	return checked
+}
+
+// CheckNow checks a subscription immediately regardless of its next check
+// time (or whether it is enabled) and returns the updated subscription
+func (s *Subscriber) CheckNow(ctx context.Context, id string) (*domain.Subscription, error) {
+	subscription, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	s.check(ctx, subscription)
+	return subscription, nil
+}
+
+// check lists and enqueues the new posts of a subscription and records the
+// outcome (cursor, counts, error, next check) on it
+func (s *Subscriber) check(ctx context.Context, subscription *domain.Subscription) {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	// Reload under the lock: a concurrent check may have advanced the cursor
+	if latest, err := s.repo.FindSubscriptionByID(subscription.ID); err == nil && latest != nil {
+		*subscription = *latest
+	}
+
+	enqueued, err := s.enqueueNewPosts(ctx, subscription)
+
+	now := time.Now()
+	subscription.LastEnqueued = enqueued
+	subscription.TotalEnqueued += enqueued
+	subscription.Checks++
+	if enqueued > 0 {
+		subscription.LastNewAt = &now
+	}
+	subscription.LastError = ""
+	if err != nil {
+		subscription.LastError = err.Error()
+		subscription.FailedChecks++
+	}
+	subscription.Checked(now)
+
+	if updateErr := s.repo.UpdateSubscription(subscription); updateErr != nil && s.multiLogger != nil {
+		s.multiLogger.LogAppError("Failed to update subscription",
+			zap.String("subscription_id", subscription.ID),
+			zap.Error(updateErr))
+	}
+
+	if s.multiLogger == nil {
+		return
+	}
+	if err != nil {
+		s.multiLogger.LogAppError("Subscription check failed",
+			zap.String("subscription_id", subscription.ID),
+			zap.String("source_url", subscription.SourceURL),
+			zap.Int("enqueued", enqueued),
+			zap.Error(err))
+		return
+	}
+	s.multiLogger.LogQueueEvent("subscription_checked",
+		zap.String("subscription_id", subscription.ID),
+		zap.String("source_url", subscription.SourceURL),
+		zap.String("platform", string(subscription.Platform)),
+		zap.Int("enqueued", enqueued),
+		zap.String("cursor", subscription.Cursor))
+}
+
+// enqueueNewPosts enqueues the posts of a subscription newer than its cursor,
+// advancing the cursor after each one so a partial failure resumes where it
+// stopped. A subscription without a cursor starts after the newest cached
+// post, or else after the newest post of the source, enqueuing nothing.
+func (s *Subscriber) enqueueNewPosts(ctx context.Context, subscription *domain.Subscription) (int, error) {
+	source := s.sources(subscription.Platform, subscription.Profile)
+	if source == nil {
+		return 0, fmt.Errorf("subscriptions are not supported for platform: %s", subscription.Platform)
+	}
+
+	if subscription.Cursor == "" {
+		cursor := ""
+		if cached, ok := source.(cachedSource); ok {
+			var err error
+			if cursor, err = cached.NewestCachedID(subscription.SourceURL); err != nil {
+				return 0, fmt.Errorf("failed to read the message cache: %w", err)
+			}
+		}
+		if cursor == "" {
+			return 0, s.startAtNewest(ctx, source, subscription)
+		}
+		subscription.Cursor = cursor
+	}
+
+	items, err := source.ListNewItems(ctx, subscription.SourceURL, subscription.Cursor, s.config.MaxItemsPerCheck)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list new posts: %w", err)
+	}
+
+	return enqueueSyncItems(s.queueMgr, items, AddDownloadOptions{
+		Source:  domain.SourceMonitor,
+		Profile: subscription.Profile,
+	}, &subscription.Cursor)
+}
+
+// startAtNewest sets the cursor of a subscription to the newest post of its
+// source. For Telegram, listing every post also fills the message cache.
+func (s *Subscriber) startAtNewest(ctx context.Context, source domain.SourceSyncer, subscription *domain.Subscription) error {
+	items, err := source.ListNewItems(ctx, subscription.SourceURL, "", 0)
+	if err != nil {
+		return fmt.Errorf("failed to list posts: %w", err)
+	}
+	subscription.Cursor = domain.SubscriptionFromStart
+	if len(items) > 0 {
+		subscription.Cursor = items[len(items)-1].ID
+	}
+	return nil
+}
+
+// Subscribe validates and stores a subscription to the Telegram channel or X
+// account of sourceURL
+func (s *Subscriber) Subscribe(sourceURL string, opts SubscribeOptions) (*domain.Subscription, error) {
+	if opts.Interval == "" {
+		opts.Interval = s.config.IntervalFor(domain.DetectPlatform(sourceURL))
+	}
+
+	subscription, err := domain.NewSubscription(sourceURL, opts.Profile, opts.Interval, opts.Backfill)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Profile != "" && s.profiles != nil && !s.profiles.HasProfile(opts.Profile) {
+		return nil, fmt.Errorf("unknown Telegram profile: %s", opts.Profile)
+	}
+	if s.sources(subscription.Platform, subscription.Profile) == nil {
+		return nil, fmt.Errorf("subscriptions are not supported for platform: %s", subscription.Platform)
+	}
+	subscription.Name = opts.Name
+
+	existing, err := s.repo.FindAllSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	for _, other := range existing {
+		if other.SameSource(subscription) {
+			return nil, &domain.DuplicateSubscriptionError{Existing: other}
+		}
+	}
+
+	if err := s.repo.CreateSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	if s.multiLogger != nil {
+		s.multiLogger.LogQueueEvent("subscription_created",
+			zap.String("subscription_id", subscription.ID),
+			zap.String("source_url", subscription.SourceURL),
+			zap.String("platform", string(subscription.Platform)),
+			zap.String("interval", subscription.Interval))
+	}
+	return subscription, nil
+}
+
+// UpdateSubscription applies changes to a subscription. Changing the interval
+// reschedules the next check from the last one; re-enabling makes it due now.
+func (s *Subscriber) UpdateSubscription(id string, update SubscriptionUpdate) (*domain.Subscription, error) {
+	subscription, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		subscription.Name = *update.Name
+	}
+	if update.Interval != nil {
+		if _, err := domain.ParseSubscriptionInterval(*update.Interval); err != nil {
+			return nil, err
+		}
+		subscription.Interval = *update.Interval
+		if subscription.LastCheckAt != nil {
+			subscription.Checked(*subscription.LastCheckAt)
+		}
+	}
+	if update.Enabled != nil {
+		if *update.Enabled && !subscription.Enabled {
+			now := time.Now()
+			subscription.NextCheckAt = &now
+		}
+		subscription.Enabled = *update.Enabled
+	}
+
+	if err := s.repo.UpdateSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// Unsubscribe deletes a subscription. Downloads it already enqueued are kept.
+func (s *Subscriber) Unsubscribe(id string) error {
+	if _, err := s.GetSubscription(id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSubscription(id); err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if s.multiLogger != nil {
+		s.multiLogger.LogQueueEvent("subscription_deleted", zap.String("subscription_id", id))
+	}
+	return nil
+}
+
+// GetSubscription retrieves a subscription by ID
+func (s *Subscriber) GetSubscription(id string) (*domain.Subscription, error) {
+	subscription, err := s.repo.FindSubscriptionByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, fmt.Errorf("subscription not found: %s", id)
+	}
+	return subscription, nil
+}
+
+// ListSubscriptions returns all subscriptions
+func (s *Subscriber) ListSubscriptions() ([]*domain.Subscription, error) {
+	return s.repo.FindAllSubscriptions()
+}
+
+// HasEnabledSubscriptions reports whether any subscription is enabled. Used
+// to keep the server from auto-exiting while it still has sources to check.
+func (s *Subscriber) HasEnabledSubscriptions() bool {
+	subscriptions, err := s.repo.FindAllSubscriptions()
+	if err != nil {
+		return false
+	}
+	for _, subscription := range subscriptions {
+		if subscription.Enabled {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// mockSubscriptionRepo implements domain.SubscriptionRepository for testing
+type mockSubscriptionRepo struct {
+	subscriptions []*domain.Subscription
+}
+
+func (m *mockSubscriptionRepo) CreateSubscription(subscription *domain.Subscription) error {
+	m.subscriptions = append(m.subscriptions, subscription)
+	return nil
+}
+
+func (m *mockSubscriptionRepo) UpdateSubscription(subscription *domain.Subscription) error {
+	for i, s := range m.subscriptions {
+		if s.ID == subscription.ID {
+			copied := *subscription
+			m.subscriptions[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *mockSubscriptionRepo) DeleteSubscription(id string) error {
+	for i, s := range m.subscriptions {
+		if s.ID == id {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *mockSubscriptionRepo) FindSubscriptionByID(id string) (*domain.Subscription, error) {
+	for _, s := range m.subscriptions {
+		if s.ID == id {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSubscriptionRepo) FindAllSubscriptions() ([]*domain.Subscription, error) {
+	result := make([]*domain.Subscription, 0, len(m.subscriptions))
+	for _, s := range m.subscriptions {
+		copied := *s
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// mockSubscriptionSource is a mockSyncer with a newest cached post
+type mockSubscriptionSource struct {
+	mockSyncer
+	cached string
+}
+
+func (m *mockSubscriptionSource) NewestCachedID(sourceURL string) (string, error) {
+	return m.cached, nil
+}
+
+// fixedProfiles knows a fixed set of tdl profiles
+type fixedProfiles map[string]bool
+
+func (p fixedProfiles) HasProfile(name string) bool { return p[name] }
+
+func telegramItems(ids ...string) []domain.SyncItem {
+	items := make([]domain.SyncItem, len(ids))
+	for i, id := range ids {
+		items[i] = domain.SyncItem{ID: id, URL: "https://t.me/somechannel/" + id, Platform: domain.PlatformTelegram, Mode: domain.ModeSingle}
+	}
+	return items
+}
+
+func newTestSubscriber(source *mockSubscriptionSource) (*Subscriber, *mockSubscriptionRepo, *mockRepo) {
+	return newTestSubscriberWithX(source, &mockSyncer{})
+}
+
+func newTestSubscriberWithX(source *mockSubscriptionSource, xSource *mockSyncer) (*Subscriber, *mockSubscriptionRepo, *mockRepo) {
+	downloads := newMockRepo()
+	subscriptions := &mockSubscriptionRepo{}
+	sources := func(platform domain.Platform, profile string) domain.SourceSyncer {
+		switch platform {
+		case domain.PlatformTelegram:
+			return source
+		case domain.PlatformX:
+			return xSource
+		}
+		return nil
+	}
+	config := &domain.SubscriptionConfig{Enabled: true, Interval: "15m", MaxItemsPerCheck: 50,
+		IntervalOverrides: map[string]string{"x": "1h"}}
+	return NewSubscriber(subscriptions, newTestQueueManager(downloads), sources, fixedProfiles{"work": true}, config, nil), subscriptions, downloads
+}
+
+func TestSubscriber_FirstCheckStartsAtNewestPost(t *testing.T) {
+	source := &mockSubscriptionSource{mockSyncer: mockSyncer{items: telegramItems("101", "102")}}
+	s, subscriptions, downloads := newTestSubscriber(source)
+
+	subscription, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "15m", subscription.Interval)
+
+	assert.Equal(t, 1, s.CheckDue(context.Background(), time.Now()))
+	assert.Empty(t, downloads.downloads, "posts from before subscribing are not downloaded")
+	stored := subscriptions.subscriptions[0]
+	assert.Equal(t, "102", stored.Cursor)
+	assert.Empty(t, stored.LastError)
+	require.NotNil(t, stored.NextCheckAt)
+	assert.Equal(t, stored.LastCheckAt.Add(15*time.Minute), *stored.NextCheckAt)
+
+	// Not due again until the interval passed
+	assert.Equal(t, 0, s.CheckDue(context.Background(), time.Now()))
+
+	source.items = telegramItems("101", "102", "103")
+	_, err = s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "102"}, source.cursors)
+	require.Len(t, downloads.downloads, 1)
+	for _, dl := range downloads.downloads {
+		assert.Equal(t, "https://t.me/somechannel/103", dl.URL)
+		assert.Equal(t, domain.SourceMonitor, dl.Source)
+	}
+	assert.Equal(t, "103", subscriptions.subscriptions[0].Cursor)
+	assert.Equal(t, 1, subscriptions.subscriptions[0].TotalEnqueued)
+}
+
+func TestSubscriber_FirstCheckStartsAfterCachedPosts(t *testing.T) {
+	source := &mockSubscriptionSource{mockSyncer: mockSyncer{items: telegramItems("101", "102", "103")}, cached: "101"}
+	s, subscriptions, downloads := newTestSubscriber(source)
+
+	subscription, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{Profile: "work"})
+	require.NoError(t, err)
+	_, err = s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"101"}, source.cursors)
+	assert.Len(t, downloads.downloads, 2)
+	for _, dl := range downloads.downloads {
+		assert.Equal(t, "work", dl.TDLProfile)
+	}
+	assert.Equal(t, 2, subscriptions.subscriptions[0].LastEnqueued)
+}
+
+func TestSubscriber_Backfill(t *testing.T) {
+	source := &mockSubscriptionSource{mockSyncer: mockSyncer{items: telegramItems("101", "102")}, cached: "102"}
+	s, _, downloads := newTestSubscriber(source)
+
+	subscription, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{Backfill: true})
+	require.NoError(t, err)
+	_, err = s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	assert.Len(t, downloads.downloads, 2)
+}
+
+func TestSubscriber_CheckRecordsError(t *testing.T) {
+	source := &mockSubscriptionSource{mockSyncer: mockSyncer{err: errors.New("tdl failed")}, cached: "100"}
+	s, subscriptions, _ := newTestSubscriber(source)
+
+	subscription, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{})
+	require.NoError(t, err)
+	result, err := s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	assert.Contains(t, result.LastError, "tdl failed")
+	assert.Contains(t, subscriptions.subscriptions[0].LastError, "tdl failed")
+	assert.True(t, subscriptions.subscriptions[0].Enabled)
+}
+
+func TestSubscriber_Subscribe_Validation(t *testing.T) {
+	s, _, _ := newTestSubscriber(&mockSubscriptionSource{})
+
+	_, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{Interval: "10s"})
+	assert.Error(t, err)
+	_, err = s.Subscribe("https://t.me/somechannel", SubscribeOptions{Profile: "unknown"})
+	assert.Error(t, err)
+	_, err = s.Subscribe("https://www.instagram.com/someone", SubscribeOptions{})
+	assert.Error(t, err)
+
+	first, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{})
+	require.NoError(t, err)
+	_, err = s.Subscribe("https://t.me/somechannel/55", SubscribeOptions{})
+	var duplicate *domain.DuplicateSubscriptionError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, first.ID, duplicate.Existing.ID)
+
+	// Another profile is another subscription
+	_, err = s.Subscribe("https://t.me/somechannel", SubscribeOptions{Profile: "work"})
+	assert.NoError(t, err)
+}
+
+func TestSubscriber_XAccount(t *testing.T) {
+	xItems := func(ids ...string) []domain.SyncItem {
+		items := make([]domain.SyncItem, len(ids))
+		for i, id := range ids {
+			items[i] = domain.SyncItem{ID: id, URL: "https://x.com/someone/status/" + id, Platform: domain.PlatformX, Mode: domain.ModeDefault}
+		}
+		return items
+	}
+	xSource := &mockSyncer{items: xItems("1001", "1002")}
+	s, subscriptions, downloads := newTestSubscriberWithX(&mockSubscriptionSource{}, xSource)
+
+	subscription, err := s.Subscribe("https://x.com/someone", SubscribeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlatformX, subscription.Platform)
+	assert.Equal(t, "1h", subscription.Interval, "X uses its interval override")
+
+	// Without a message cache the first check only records the newest tweet
+	_, err = s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	assert.Empty(t, downloads.downloads)
+	assert.Equal(t, "1002", subscriptions.subscriptions[0].Cursor)
+
+	xSource.items = xItems("1001", "1002", "1003")
+	_, err = s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	require.Len(t, downloads.downloads, 1)
+	for _, dl := range downloads.downloads {
+		assert.Equal(t, domain.PlatformX, dl.Platform)
+	}
+
+	xSource.err = errors.New("rate limited")
+	_, err = s.CheckNow(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	stored := subscriptions.subscriptions[0]
+	assert.Equal(t, 3, stored.Checks)
+	assert.Equal(t, 1, stored.FailedChecks)
+	assert.Equal(t, 1, stored.TotalEnqueued)
+	assert.NotNil(t, stored.LastNewAt)
+}
+
+func TestSubscriber_UpdateSubscription(t *testing.T) {
+	s, _, _ := newTestSubscriber(&mockSubscriptionSource{})
+
+	subscription, err := s.Subscribe("https://t.me/somechannel", SubscribeOptions{})
+	require.NoError(t, err)
+	assert.True(t, s.HasEnabledSubscriptions())
+
+	disabled := false
+	updated, err := s.UpdateSubscription(subscription.ID, SubscriptionUpdate{Enabled: &disabled})
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.False(t, s.HasEnabledSubscriptions())
+
+	bad := "1s"
+	_, err = s.UpdateSubscription(subscription.ID, SubscriptionUpdate{Interval: &bad})
+	assert.Error(t, err)
+
+	require.NoError(t, s.Unsubscribe(subscription.ID))
+	_, err = s.GetSubscription(subscription.ID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,373 @@
+package app
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/internal/infrastructure"
+	"github.com/yourusername/x-extract-go/internal/infrastructure/binmanager"
+)
+
+// supportBundleRepository is the persistence read by SupportBundle
+type supportBundleRepository interface {
+	GetStats() (*domain.DownloadStats, error)
+	GetStatsHistory(days int, now time.Time) (*domain.StatsHistory, error)
+	FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error)
+}
+
+// DefaultSupportLogDays is how many days of logs a support bundle includes by
+// default, today included
+const DefaultSupportLogDays = 3
+
+const (
+	supportLogTailBytes   = 5 << 20 // Tail of each log file included
+	supportHistoryDays    = 7
+	supportRecentFailures = 20
+	supportToolTimeout    = 10 * time.Second
+)
+
+// supportTools are the external tools whose versions a support bundle
+// records, with the arguments that print the version
+var supportTools = []struct {
+	name string
+	args []string
+}{
+	{"yt-dlp", []string{"--version"}},
+	{"tdl", []string{"version"}},
+	{"gallery-dl", []string{"--version"}},
+}
+
+// SupportBundle collects what is needed to debug an installation into a zip
+// (x-extract-cli support-bundle): the config, the environment, database
+// stats, the versions of the external tools and recent logs of all
+// categories, with passwords, keys and API tokens redacted. It works without
+// the server.
+type SupportBundle struct {
+	config    *domain.Config
+	logDays   int
+	repo      supportBundleRepository // nil when the database could not be opened
+	repoErr   error
+	serverURL string
+	health    interface{} // GET /health of the running server (nil = not running)
+	now       func() time.Time
+}
+
+// supportPart is a JSON file of a support bundle and how it is collected
+type supportPart struct {
+	name    string
+	collect func() (interface{}, error)
+}
+
+// NewSupportBundle creates a support bundle of config with logDays days of
+// logs
+func NewSupportBundle(config *domain.Config, logDays int) *SupportBundle {
+	return &SupportBundle{config: config, logDays: logDays, now: time.Now}
+}
+
+// SetDatabase sets the database the stats are read from, or why it could not
+// be opened
+func (b *SupportBundle) SetDatabase(repo supportBundleRepository, err error) {
+	b.repo, b.repoErr = repo, err
+}
+
+// SetServer records where the server is looked for and what its health
+// endpoint returned (nil when it is not running)
+func (b *SupportBundle) SetServer(url string, health interface{}) {
+	b.serverURL, b.health = url, health
+}
+
+// Write writes the bundle to w as a zip and returns its manifest. A part that
+// can't be collected is listed in the manifest's errors instead of failing
+// the bundle; only failing to write does.
+func (b *SupportBundle) Write(ctx context.Context, w io.Writer) (*domain.SupportBundleInfo, error) {
+	zw := zip.NewWriter(w)
+	info := &domain.SupportBundleInfo{
+		CreatedAt: b.now(),
+		Build:     domain.CurrentBuild(),
+		LogDays:   b.logDays,
+		Files:     []string{},
+		Errors:    map[string]string{},
+	}
+	secrets := configSecrets(b.config)
+
+	parts := []supportPart{
+		{domain.SupportBundleConfig, b.configOptions},
+		{domain.SupportBundleEnvironment, func() (interface{}, error) { return b.environment(), nil }},
+		{domain.SupportBundleDatabase, b.database},
+		{domain.SupportBundleTools, func() (interface{}, error) { return b.tools(ctx), nil }},
+	}
+	if b.health != nil {
+		parts = append(parts, supportPart{domain.SupportBundleServer, func() (interface{}, error) { return b.health, nil }})
+	}
+	for _, part := range parts {
+		value, err := part.collect()
+		if err != nil {
+			info.Errors[part.name] = err.Error()
+			continue
+		}
+		if err := writeSupportJSON(zw, part.name, value, secrets); err != nil {
+			return nil, err
+		}
+		info.Files = append(info.Files, part.name)
+	}
+
+	if err := b.writeLogs(zw, info, secrets); err != nil {
+		return nil, err
+	}
+
+	if len(info.Errors) == 0 {
+		info.Errors = nil
+	}
+	info.Files = append(info.Files, domain.SupportBundleManifest)
+	if err := writeSupportJSON(zw, domain.SupportBundleManifest, info, secrets); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	return info, nil
+}
+
+// configOptions lists every config key, redacted
+func (b *SupportBundle) configOptions() (interface{}, error) {
+	options, err := DescribeConfig(b.config)
+	if err != nil {
+		return nil, err
+	}
+	return RedactConfigOptions(options), nil
+}
+
+// environment describes the system, the config paths and the server
+func (b *SupportBundle) environment() *domain.SupportEnvironment {
+	baseDir := b.config.Download.BaseDir
+	free, err := infrastructure.FreeSpace(baseDir)
+	if err != nil {
+		free = -1
+	}
+	env := &domain.SupportEnvironment{
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CPUs:       runtime.NumCPU(),
+		Docker:     domain.IsDocker(),
+		Timezone:   b.now().Format("MST -07:00"),
+		ConfigPath: domain.DefaultConfigPath(),
+		UserConfig: UserConfigPath(b.config),
+		BaseDir:    baseDir,
+		FreeBytes:  free,
+		EnvVars:    []string{},
+		ServerURL:  b.serverURL,
+		ServerUp:   b.health != nil,
+	}
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "XEXTRACT_") || strings.HasPrefix(name, "X_EXTRACT_") {
+			env.EnvVars = append(env.EnvVars, name)
+		}
+	}
+	sort.Strings(env.EnvVars)
+	return env
+}
+
+// database reads the size of the database files, the download stats and the
+// most recent failures
+func (b *SupportBundle) database() (interface{}, error) {
+	if b.repo == nil {
+		return nil, fmt.Errorf("failed to open database: %v", b.repoErr)
+	}
+	path := b.config.Queue.DatabasePath
+	db := &domain.SupportDatabase{Path: path, RecentFailures: []domain.SupportFailure{}}
+	if stat, err := os.Stat(path); err == nil {
+		db.SizeBytes = stat.Size()
+	}
+	if stat, err := os.Stat(path + "-wal"); err == nil {
+		db.WALBytes = stat.Size()
+	}
+
+	var err error
+	if db.Stats, err = b.repo.GetStats(); err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	if db.History, err = b.repo.GetStatsHistory(supportHistoryDays, b.now()); err != nil {
+		return nil, fmt.Errorf("failed to read stats history: %w", err)
+	}
+	failed, err := b.repo.FindAll(domain.FailedFilter{}.Filters(), domain.ListOptions{Limit: supportRecentFailures, SortBy: "updated_at"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed downloads: %w", err)
+	}
+	for _, d := range failed {
+		db.RecentFailures = append(db.RecentFailures, domain.SupportFailure{
+			ID:           d.ID,
+			URL:          redactConfigValue(d.URL),
+			Platform:     d.Platform,
+			ErrorCode:    d.ErrorCode,
+			ErrorMessage: redactConfigValue(d.ErrorMessage),
+			RetryCount:   d.RetryCount,
+			UpdatedAt:    d.UpdatedAt,
+		})
+	}
+	return db, nil
+}
+
+// tools resolves the external tools as the downloaders do and asks them for
+// their versions
+func (b *SupportBundle) tools(ctx context.Context) []domain.SupportTool {
+	configPaths := map[string]string{
+		"yt-dlp":     b.config.Twitter.YTDLPBinary,
+		"tdl":        b.config.Telegram.TDLBinary,
+		"gallery-dl": b.config.GalleryDL.GalleryDLBinary,
+	}
+	pins := map[string]string{
+		"yt-dlp":     b.config.Download.YTDLPVersion,
+		"tdl":        b.config.Download.TDLVersion,
+		"gallery-dl": b.config.Download.GalleryDLVersion,
+	}
+
+	tools := make([]domain.SupportTool, 0, len(supportTools))
+	for _, t := range supportTools {
+		tool := domain.SupportTool{Name: t.name, Pin: pins[t.name]}
+		path, err := binmanager.ResolveBinary(t.name, configPaths[t.name], b.config.Download.BinDirectory(), b.config.Download.PreferManagedBinaries)
+		if err != nil {
+			tool.Error = err.Error()
+			tools = append(tools, tool)
+			continue
+		}
+		tool.Path = path
+
+		runCtx, cancel := context.WithTimeout(ctx, supportToolTimeout)
+		output, err := exec.CommandContext(runCtx, path, t.args...).CombinedOutput()
+		cancel()
+		if err != nil {
+			tool.Error = err.Error()
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				tool.Version = line
+				break
+			}
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// writeLogs adds the tails of the log files changed in the last logDays days,
+// redacted line by line
+func (b *SupportBundle) writeLogs(zw *zip.Writer, info *domain.SupportBundleInfo, secrets []string) error {
+	dir := b.config.Download.LogsDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		info.Errors[domain.SupportBundleLogsDir] = err.Error()
+		return nil
+	}
+	since := domain.StatsHistoryStart(b.logDays, b.now())
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != ".log" {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil || stat.ModTime().Before(since) {
+			continue
+		}
+		name := domain.SupportBundleLogsDir + entry.Name()
+		tail, err := readTail(filepath.Join(dir, entry.Name()), supportLogTailBytes)
+		if err != nil {
+			info.Errors[name] = err.Error()
+			continue
+		}
+
+		f, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to write support bundle: %w", err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(tail))
+		scanner.Buffer(make([]byte, 64*1024), len(tail)+1)
+		for scanner.Scan() {
+			if _, err := io.WriteString(f, redactSecrets(redactConfigValue(scanner.Text()), secrets)+"\n"); err != nil {
+				return fmt.Errorf("failed to write support bundle: %w", err)
+			}
+		}
+		info.Files = append(info.Files, name)
+	}
+	return nil
+}
+
+// readTail reads the last limit bytes of a file, from the start of a line
+func readTail(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() <= limit {
+		return io.ReadAll(f)
+	}
+	if _, err := f.Seek(stat.Size()-limit, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return append([]byte("[earlier lines left out]\n"), data...), nil
+}
+
+// writeSupportJSON adds value to the bundle as indented JSON
+func writeSupportJSON(zw *zip.Writer, name string, value interface{}, secrets []string) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	if _, err := io.WriteString(f, redactSecrets(string(data), secrets)+"\n"); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	return nil
+}
+
+// configSecrets returns the secret values of config (see secretKeySuffixes),
+// to redact wherever they appear
+func configSecrets(config *domain.Config) []string {
+	candidates := []string{config.Server.AuthToken, config.Storage.S3.SecretAccessKey, config.Storage.WebDAV.Password}
+	for _, token := range config.Server.AuthTokens {
+		candidates = append(candidates, token)
+	}
+	var secrets []string
+	for _, secret := range candidates {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// redactSecrets replaces every occurrence of secrets in s with ***
+func redactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, "***")
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// bundleRepo returns fixed stats and failed downloads
+type bundleRepo struct {
+	failed []*domain.Download
+}
+
+func (r *bundleRepo) GetStats() (*domain.DownloadStats, error) {
+	return &domain.DownloadStats{Total: 10, Failed: int64(len(r.failed))}, nil
+}
+
+func (r *bundleRepo) GetStatsHistory(days int, now time.Time) (*domain.StatsHistory, error) {
+	return domain.BuildStatsHistory(nil, days, now), nil
+}
+
+func (r *bundleRepo) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
+	return r.failed, nil
+}
+
+// readBundle returns the files of a support bundle by name
+func readBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestSupportBundle(t *testing.T) {
+	config := domain.DefaultConfig()
+	config.Download.BaseDir = t.TempDir()
+	config.Download.PreferManagedBinaries = true
+	config.Queue.DatabasePath = filepath.Join(config.Download.ConfigDir(), "queue.db")
+	config.Server.AuthToken = "cli-0123456789abcdef"
+
+	logsDir := config.Download.LogsDir()
+	require.NoError(t, os.MkdirAll(logsDir, 0755))
+	recent := filepath.Join(logsDir, "queue-20260310.log")
+	require.NoError(t, os.WriteFile(recent, []byte("{\"msg\":\"request\",\"token\":\"cli-0123456789abcdef\"}\nyt-dlp --password hunter2 https://x.com/a\n"), 0644))
+	old := filepath.Join(logsDir, "error-20260301.log")
+	require.NoError(t, os.WriteFile(old, []byte("old\n"), 0644))
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	require.NoError(t, os.Chtimes(recent, now, now))
+	require.NoError(t, os.Chtimes(old, now.AddDate(0, 0, -9), now.AddDate(0, 0, -9)))
+
+	failed := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+	failed.MarkFailed(errors.New("yt-dlp -p hunter2 failed"))
+
+	bundle := NewSupportBundle(config, 3)
+	bundle.now = func() time.Time { return now }
+	bundle.SetDatabase(&bundleRepo{failed: []*domain.Download{failed}}, nil)
+	bundle.SetServer("http://localhost:9091", map[string]interface{}{"status": "healthy"})
+
+	var buf bytes.Buffer
+	info, err := bundle.Write(context.Background(), &buf)
+	require.NoError(t, err)
+	assert.Empty(t, info.Errors)
+
+	files := readBundle(t, buf.Bytes())
+	for _, name := range []string{
+		domain.SupportBundleManifest, domain.SupportBundleConfig, domain.SupportBundleEnvironment,
+		domain.SupportBundleDatabase, domain.SupportBundleTools, domain.SupportBundleServer,
+		"logs/queue-20260310.log",
+	} {
+		assert.Contains(t, files, name)
+	}
+	assert.NotContains(t, files, "logs/error-20260301.log", "older than the days of logs")
+	assert.Len(t, info.Files, len(files))
+
+	for name, content := range files {
+		assert.NotContains(t, content, "cli-0123456789abcdef", name)
+		assert.NotContains(t, content, "hunter2", name)
+	}
+
+	var db domain.SupportDatabase
+	require.NoError(t, json.Unmarshal([]byte(files[domain.SupportBundleDatabase]), &db))
+	assert.Equal(t, int64(10), db.Stats.Total)
+	require.Len(t, db.RecentFailures, 1)
+	assert.Equal(t, "yt-dlp -p *** failed", db.RecentFailures[0].ErrorMessage)
+
+	var tools []domain.SupportTool
+	require.NoError(t, json.Unmarshal([]byte(files[domain.SupportBundleTools]), &tools))
+	require.Len(t, tools, 3)
+	assert.NotEmpty(t, tools[0].Error, "no managed yt-dlp installed")
+}
+
+func TestSupportBundle_WithoutDatabase(t *testing.T) {
+	config := domain.DefaultConfig()
+	config.Download.BaseDir = t.TempDir()
+	config.Download.PreferManagedBinaries = true
+
+	bundle := NewSupportBundle(config, DefaultSupportLogDays)
+	bundle.SetDatabase(nil, errors.New("unable to open database file"))
+
+	var buf bytes.Buffer
+	info, err := bundle.Write(context.Background(), &buf)
+	require.NoError(t, err)
+	assert.Contains(t, info.Errors[domain.SupportBundleDatabase], "unable to open database file")
+	assert.Contains(t, info.Errors, domain.SupportBundleLogsDir, "no logs directory")
+
+	files := readBundle(t, buf.Bytes())
+	assert.NotContains(t, files, domain.SupportBundleDatabase)
+	assert.NotContains(t, files, domain.SupportBundleServer, "the server is not running")
+	assert.Contains(t, files, domain.SupportBundleManifest)
+}
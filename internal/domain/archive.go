@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata fields set on a download whose files were moved to an archive
+// volume. The files keep their path relative to completed/ under the archive
+// directory, so "relative_path" stays valid relative to "archive_dir".
+const (
+	MetadataArchivedAt = "archived_at" // RFC 3339 time of the move
+	MetadataArchiveDir = "archive_dir" // Directory the files were moved to
+)
+
+// ParseAge parses an age such as "30d", "2w" or "36h": a number of days (d)
+// or weeks (w), or anything time.ParseDuration accepts
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number := strings.TrimSuffix(s, suffix); number != s {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q (e.g. 30d, 2w, 36h)", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (e.g. 30d, 2w, 36h)", s)
+	}
+	return d, nil
+}
+
+// ArchiveDir returns the directory the download's files were archived to, or
+// "" when they were not
+func (d *Download) ArchiveDir() string {
+	var meta map[string]interface{}
+	if d.Metadata == "" || json.Unmarshal([]byte(d.Metadata), &meta) != nil {
+		return ""
+	}
+	return metadataString(meta, MetadataArchiveDir)
+}
+
+// MarkArchived records that the download's files were moved to dir
+func (d *Download) MarkArchived(dir string, at time.Time) {
+	d.SetMetadataField(MetadataArchiveDir, dir)
+	d.SetMetadataField(MetadataArchivedAt, at.UTC().Format(time.RFC3339))
+}
+
+// SelectArchivable returns the completed downloads that completed before
+// cutoff and are not archived yet, oldest first
+func SelectArchivable(downloads []*Download, cutoff time.Time) []*Download {
+	var selected []*Download
+	for _, d := range downloads {
+		if d.Status == StatusCompleted && completedTime(d).Before(cutoff) && d.ArchiveDir() == "" {
+			selected = append(selected, d)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return completedTime(selected[i]).Before(completedTime(selected[j]))
+	})
+	return selected
+}
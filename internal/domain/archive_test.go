@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAge(t *testing.T) {
+	for input, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"0d":  0,
+	} {
+		got, err := ParseAge(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	for _, input := range []string{"", "d", "-3d", "1.5d", "soon"} {
+		_, err := ParseAge(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestSelectArchivable(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	completedAt := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+		return &at
+	}
+	old := &Download{ID: "old", Status: StatusCompleted, CompletedAt: completedAt(90)}
+	older := &Download{ID: "older", Status: StatusCompleted, CompletedAt: completedAt(120)}
+	recent := &Download{ID: "recent", Status: StatusCompleted, CompletedAt: completedAt(1)}
+	archived := &Download{ID: "archived", Status: StatusCompleted, CompletedAt: completedAt(200)}
+	archived.MarkArchived("/Volumes/Archive", now)
+	failed := &Download{ID: "failed", Status: StatusFailed, CreatedAt: now.AddDate(-1, 0, 0)}
+
+	selected := SelectArchivable([]*Download{recent, old, archived, failed, older}, now.AddDate(0, 0, -30))
+	require.Len(t, selected, 2)
+	assert.Equal(t, "older", selected[0].ID)
+	assert.Equal(t, "old", selected[1].ID)
+
+	assert.Equal(t, "/Volumes/Archive", archived.ArchiveDir())
+	assert.Empty(t, old.ArchiveDir())
+	assert.Empty(t, SelectExpired([]*Download{archived}, time.Hour, 0, now), "archived downloads are kept")
+}
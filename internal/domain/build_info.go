@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, set at build time with
+//
+//	-ldflags "-X github.com/yourusername/x-extract-go/internal/domain.Version=v1.2.3
+//	          -X github.com/yourusername/x-extract-go/internal/domain.Commit=abc1234
+//	          -X github.com/yourusername/x-extract-go/internal/domain.BuildDate=2024-01-01T00:00:00Z"
+//
+// (see the Makefile). Commit and BuildDate fall back to the VCS stamp of
+// go build when not set.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// BuildInfo identifies the build of a binary, to tell which build a bug
+// report or a machine runs
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// CurrentBuild returns the build info of the running binary
+func CurrentBuild() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.applyVCS(bi.Settings)
+	}
+	return info
+}
+
+// applyVCS fills the commit and build date missing from the ldflags with the
+// VCS stamp of go build
+func (b *BuildInfo) applyVCS(settings []debug.BuildSetting) {
+	stamped := b.Commit == ""
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if b.Commit == "" {
+				b.Commit = setting.Value
+			}
+		case "vcs.time":
+			if b.BuildDate == "" {
+				b.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			if stamped {
+				b.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if len(b.Commit) > 12 {
+		b.Commit = b.Commit[:12]
+	}
+}
+
+// String formats the build info on one line, e.g.
+// "v1.2.3 (commit abc1234, built 2024-01-01T00:00:00Z, go1.21.0 linux/amd64)"
+func (b BuildInfo) String() string {
+	s := b.Version + " ("
+	if b.Commit != "" {
+		s += "commit " + b.Commit
+		if b.Modified {
+			s += "-dirty"
+		}
+		s += ", "
+	}
+	if b.BuildDate != "" {
+		s += "built " + b.BuildDate + ", "
+	}
+	return s + b.GoVersion + " " + b.OS + "/" + b.Arch + ")"
+}
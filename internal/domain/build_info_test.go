@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInfo_ApplyVCS(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+		{Key: "vcs.time", Value: "2024-01-01T00:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	info := BuildInfo{Version: "dev"}
+	info.applyVCS(settings)
+	assert.Equal(t, "0123456789ab", info.Commit)
+	assert.Equal(t, "2024-01-01T00:00:00Z", info.BuildDate)
+	assert.True(t, info.Modified)
+
+	// Values from the ldflags win
+	info = BuildInfo{Version: "v1.2.3", Commit: "abc1234", BuildDate: "2024-02-02T00:00:00Z"}
+	info.applyVCS(settings)
+	assert.Equal(t, "abc1234", info.Commit)
+	assert.Equal(t, "2024-02-02T00:00:00Z", info.BuildDate)
+	assert.False(t, info.Modified)
+}
+
+func TestBuildInfo_String(t *testing.T) {
+	info := BuildInfo{Version: "v1.2.3", Commit: "abc1234", BuildDate: "2024-01-01T00:00:00Z", GoVersion: "go1.21.0", OS: "linux", Arch: "amd64"}
+	assert.Equal(t, "v1.2.3 (commit abc1234, built 2024-01-01T00:00:00Z, go1.21.0 linux/amd64)", info.String())
+
+	info.Modified = true
+	assert.Contains(t, info.String(), "commit abc1234-dirty")
+
+	assert.Equal(t, "dev (go1.21.0 darwin/arm64)", BuildInfo{Version: "dev", GoVersion: "go1.21.0", OS: "darwin", Arch: "arm64"}.String())
+}
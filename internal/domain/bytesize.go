@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// byteSizeUnits maps size suffixes to their multiplier. KB/MB/GB/TB are
+// decimal, KiB/MiB/GiB/TiB binary; a bare K/M/G/T is binary like ls -h.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1000,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1000 * 1000,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1000 * 1000 * 1000,
+	"GIB": 1 << 30,
+	"T":   1 << 40,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"TIB": 1 << 40,
+}
+
+// ParseByteSize parses a size such as "500MB", "2GiB" or "1.5T". An empty
+// string is 0.
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	idx := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	number, unit := s, ""
+	if idx >= 0 {
+		number, unit = s[:idx], strings.ToUpper(strings.TrimSpace(s[idx:]))
+	}
+	multiplier, ok := byteSizeUnits[unit]
+	value, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 500MB, 2GiB)", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// FormatBytes formats a size in bytes with binary units, e.g. "1.5 GiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
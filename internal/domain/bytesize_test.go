@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"":       0,
+		"0":      0,
+		"512":    512,
+		"10B":    10,
+		"500MB":  500 * 1000 * 1000,
+		"2GiB":   2 << 30,
+		"1.5 gb": 1500 * 1000 * 1000,
+		"1T":     1 << 40,
+	}
+	for in, want := range cases {
+		got, err := ParseByteSize(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"abc", "10XB", "-1GB", "GB"} {
+		_, err := ParseByteSize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "3.0 MiB", FormatBytes(3<<20))
+}
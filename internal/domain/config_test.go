@@ -1,10 +1,12 @@
 package domain
 
 import (
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -27,3 +29,265 @@ func TestDefaultConfig(t *testing.T) {
 	assert.True(t, config.Notification.Enabled)
 	assert.Equal(t, "info", config.Logging.Level)
 }
+
+func TestServerConfig_Auth(t *testing.T) {
+	config := DefaultConfig().Server
+	assert.False(t, config.AuthEnabled())
+	assert.True(t, config.Loopback())
+	assert.NoError(t, config.ValidateAuth())
+	_, ok := config.MatchAuthToken("")
+	assert.False(t, ok)
+
+	config.Host = "0.0.0.0"
+	config.AuthToken = "cli-0123456789abcdef"
+	config.AuthTokens = map[string]string{"phone": "phone-0123456789abcdef"}
+	assert.False(t, config.Loopback())
+	assert.True(t, config.AuthEnabled())
+	require.NoError(t, config.ValidateAuth())
+
+	name, ok := config.MatchAuthToken("cli-0123456789abcdef")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultAuthTokenName, name)
+	name, ok = config.MatchAuthToken("phone-0123456789abcdef")
+	assert.True(t, ok)
+	assert.Equal(t, "phone", name)
+	_, ok = config.MatchAuthToken("phone-0123456789abcde")
+	assert.False(t, ok)
+
+	assert.Error(t, (&ServerConfig{AuthToken: "short"}).ValidateAuth())
+	assert.Error(t, (&ServerConfig{AuthTokens: map[string]string{"tv": "0123456789 abcdef"}}).ValidateAuth())
+	assert.Error(t, (&ServerConfig{AuthToken: "cli-0123456789abcdef", AuthTokens: map[string]string{DefaultAuthTokenName: "phone-0123456789abcdef"}}).ValidateAuth())
+}
+
+func TestServerConfig_TLS(t *testing.T) {
+	config := DefaultConfig().Server
+	assert.NoError(t, config.TLS.Validate())
+	assert.Equal(t, "http", config.Scheme())
+
+	config.TLS = TLSConfig{Enabled: true, SelfSigned: true, Hosts: []string{"nas.example.ts.net", "localhost"}}
+	assert.NoError(t, config.TLS.Validate())
+	assert.Equal(t, "https", config.Scheme())
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1", "nas.example.ts.net"}, config.SelfSignedHosts())
+
+	config.Host = "192.168.1.10"
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1", "192.168.1.10", "nas.example.ts.net"}, config.SelfSignedHosts())
+	config.Host = "0.0.0.0"
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1", "nas.example.ts.net"}, config.SelfSignedHosts(), "wildcard addresses are left out")
+
+	assert.NoError(t, (&TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}).Validate())
+	assert.Error(t, (&TLSConfig{Enabled: true}).Validate())
+	assert.Error(t, (&TLSConfig{Enabled: true, CertFile: "cert.pem"}).Validate())
+	assert.Error(t, (&TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", SelfSigned: true}).Validate())
+}
+
+func TestValidateTwitterBackend(t *testing.T) {
+	assert.Equal(t, TwitterBackendYTDLP, DefaultConfig().Twitter.Backend)
+	assert.NoError(t, ValidateTwitterBackend(""))
+	assert.NoError(t, ValidateTwitterBackend(TwitterBackendYTDLP))
+	assert.NoError(t, ValidateTwitterBackend(TwitterBackendGalleryDL))
+	assert.Error(t, ValidateTwitterBackend("youtube-dl"))
+}
+
+func TestValidateImpersonateTarget(t *testing.T) {
+	for _, target := range []string{"", "chrome", "chrome-124", "chrome-124:macos-14", "safari:ios", ":windows"} {
+		assert.NoError(t, ValidateImpersonateTarget(target), target)
+	}
+	for _, target := range []string{"Chrome", "chrome 124", "chrome:macos:14", "--impersonate"} {
+		assert.Error(t, ValidateImpersonateTarget(target), target)
+	}
+}
+
+func TestTwitterConfig_Accounts(t *testing.T) {
+	config := &TwitterConfig{
+		CookieFile: "/cookies/main.cookie",
+		Accounts:   map[string]string{"protected": "/cookies/protected.cookie", "alt": "/cookies/alt.cookie"},
+	}
+	assert.Equal(t, []string{DefaultXAccount, "alt", "protected"}, config.AccountNames())
+	file, ok := config.AccountCookieFile(DefaultXAccount)
+	assert.True(t, ok)
+	assert.Equal(t, "/cookies/main.cookie", file)
+	assert.True(t, config.HasAccount("protected"))
+	assert.False(t, config.HasAccount("other"))
+	assert.NoError(t, config.ValidateAccounts())
+
+	// Without cookie_file there is no default account
+	config.CookieFile = ""
+	assert.Equal(t, []string{"alt", "protected"}, config.AccountNames())
+	assert.False(t, config.HasAccount(DefaultXAccount))
+
+	assert.Error(t, (&TwitterConfig{Accounts: map[string]string{DefaultXAccount: "/cookies/x.cookie"}}).ValidateAccounts())
+	assert.Error(t, (&TwitterConfig{Accounts: map[string]string{"alt": ""}}).ValidateAccounts())
+	assert.Error(t, (&TwitterConfig{AccountCooldown: -time.Minute}).ValidateAccounts())
+}
+
+func TestTelegramConfig_Profiles(t *testing.T) {
+	config := &TelegramConfig{
+		Profile:     "default",
+		StorageType: "bolt",
+		StoragePath: "/cookies/telegram",
+		Profiles:    map[string]string{"work": "/cookies/tdl-work", "alt": ""},
+	}
+	assert.Equal(t, []string{"default", "alt", "work"}, config.ProfileNames())
+	assert.True(t, config.HasProfile("default"))
+	assert.True(t, config.HasProfile("work"))
+	assert.False(t, config.HasProfile("other"))
+	assert.NoError(t, config.ValidateProfiles())
+
+	work := config.ForProfile("work")
+	assert.Equal(t, "work", work.Profile)
+	assert.Equal(t, "/cookies/tdl-work", work.StoragePath)
+	assert.Equal(t, "bolt", work.StorageType)
+	alt := config.ForProfile("alt")
+	assert.Equal(t, "alt", alt.Profile)
+	assert.Equal(t, "/cookies/telegram", alt.StoragePath, "an empty path shares storage_path")
+	assert.Equal(t, "default", config.ForProfile("").Profile)
+	assert.Equal(t, "default", config.ForProfile("other").Profile)
+
+	config.Profiles["default"] = "/cookies/other"
+	assert.Error(t, config.ValidateProfiles())
+}
+
+func TestMetadataConfig_ValidateEnrichers(t *testing.T) {
+	assert.NoError(t, (&MetadataConfig{
+		Enrichers:     MetadataEnrichers,
+		ExecEnrichers: map[string]string{"exif": "exiftool -json"},
+	}).ValidateEnrichers())
+	assert.Error(t, (&MetadataConfig{Enrichers: []string{"md5"}}).ValidateEnrichers())
+	assert.Error(t, (&MetadataConfig{ExecEnrichers: map[string]string{EnricherSHA256: "sha256sum"}}).ValidateEnrichers())
+	assert.Error(t, (&MetadataConfig{ExecEnrichers: map[string]string{"exif": " "}}).ValidateEnrichers())
+}
+
+func TestClientProfile(t *testing.T) {
+	assert.Equal(t, "", (&TwitterConfig{}).ClientProfile())
+	assert.Equal(t, "impersonate=chrome | user_agent=Mozilla/5.0 (X11; Linux x86_64)",
+		(&TwitterConfig{Impersonate: "chrome", UserAgent: "Mozilla/5.0 (X11; Linux x86_64)"}).ClientProfile())
+
+	assert.Equal(t, "", (&TelegramConfig{}).ClientProfile())
+	assert.Equal(t, "proxy=socks5://127.0.0.1:1080 | ntp=pool.ntp.org",
+		(&TelegramConfig{Proxy: "socks5://user:p@ss@127.0.0.1:1080", NTP: "pool.ntp.org"}).ClientProfile(),
+		"proxy credentials are not recorded")
+}
+
+func TestValidateDiskGuard(t *testing.T) {
+	config := &DownloadConfig{MinFreeSpace: "2GiB", Quota: "500GB", DiskFullAction: DiskFullFail}
+	assert.NoError(t, config.ValidateDiskGuard())
+	assert.Equal(t, int64(2<<30), config.MinFreeSpaceBytes())
+	assert.Equal(t, int64(500*1000*1000*1000), config.QuotaBytes())
+	assert.NoError(t, (&DownloadConfig{}).ValidateDiskGuard(), "empty settings disable the guard")
+
+	assert.Error(t, (&DownloadConfig{MinFreeSpace: "lots"}).ValidateDiskGuard())
+	assert.Error(t, (&DownloadConfig{Quota: "1PB"}).ValidateDiskGuard())
+	assert.Error(t, (&DownloadConfig{DiskFullAction: "wait"}).ValidateDiskGuard())
+}
+
+func TestDownloadConfig_Dirs(t *testing.T) {
+	config := &DownloadConfig{BaseDir: "/downloads"}
+	assert.Equal(t, "/downloads/incoming", config.IncomingDir())
+	assert.Equal(t, "/downloads/completed", config.CompletedDir())
+
+	config.IncomingPath = "/scratch/incoming"
+	config.CompletedPath = "/archive"
+	assert.Equal(t, "/scratch/incoming", config.IncomingDir())
+	assert.Equal(t, "/archive", config.CompletedDir())
+	assert.Equal(t, "/downloads/logs", config.LogsDir(), "other directories stay under base_dir")
+}
+
+func TestValidateFilePermissions(t *testing.T) {
+	assert.NoError(t, (&DownloadConfig{}).ValidateFilePermissions())
+	assert.NoError(t, (&DownloadConfig{FileMode: "0644", DirMode: "755", FileOwner: "plex:media"}).ValidateFilePermissions())
+	assert.Error(t, (&DownloadConfig{FileMode: "644x"}).ValidateFilePermissions())
+	assert.Error(t, (&DownloadConfig{DirMode: "01777"}).ValidateFilePermissions())
+	assert.Error(t, (&DownloadConfig{FileOwner: ":"}).ValidateFilePermissions())
+
+	mode, err := ParseFileMode("0640")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), mode)
+}
+
+func TestDownloadConfig_RateLimits(t *testing.T) {
+	config := &DownloadConfig{RateLimit: "2MiB", RateLimitOverrides: map[string]string{"telegram": "0", "x": "500KB"}}
+	assert.NoError(t, config.ValidateRateLimits())
+	assert.Equal(t, int64(2<<20), config.RateLimitFor(PlatformGallery))
+	assert.Equal(t, int64(500*1000), config.RateLimitFor(PlatformX))
+	assert.Zero(t, config.RateLimitFor(PlatformTelegram), "a 0 override exempts the platform")
+	assert.Zero(t, (&DownloadConfig{}).RateLimitFor(PlatformX), "unlimited by default")
+
+	assert.Error(t, (&DownloadConfig{RateLimit: "fast"}).ValidateRateLimits())
+	assert.Error(t, (&DownloadConfig{RateLimitOverrides: map[string]string{"myspace": "1MB"}}).ValidateRateLimits())
+	assert.Error(t, (&DownloadConfig{RateLimitOverrides: map[string]string{"x": "-1MB"}}).ValidateRateLimits())
+}
+
+func TestDownloadConfig_FallbackChains(t *testing.T) {
+	config := &DownloadConfig{FallbackChains: map[string][]string{"x": {BackendYTDLP, BackendGalleryDL, BackendXAPI}}}
+	assert.NoError(t, config.ValidateFallbackChains())
+	assert.True(t, config.UsesBackend(BackendXAPI))
+	assert.False(t, config.UsesBackend(BackendTDL))
+
+	assert.Error(t, (&DownloadConfig{FallbackChains: map[string][]string{"myspace": {BackendYTDLP}}}).ValidateFallbackChains())
+	assert.Error(t, (&DownloadConfig{FallbackChains: map[string][]string{"x": {}}}).ValidateFallbackChains())
+	assert.Error(t, (&DownloadConfig{FallbackChains: map[string][]string{"telegram": {BackendYTDLP}}}).ValidateFallbackChains(),
+		"yt-dlp is not a Telegram backend")
+	assert.Error(t, (&DownloadConfig{FallbackChains: map[string][]string{"x": {BackendYTDLP, BackendYTDLP}}}).ValidateFallbackChains())
+}
+
+func TestRetentionConfig_Validate(t *testing.T) {
+	config := &RetentionConfig{Enabled: true, MaxAgeDays: 90, MaxSize: "500GB"}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, 90*24*time.Hour, config.MaxAge())
+	assert.Equal(t, int64(500*1000*1000*1000), config.MaxSizeBytes())
+	assert.NoError(t, (&RetentionConfig{}).Validate(), "disabled without limits")
+
+	assert.Error(t, (&RetentionConfig{Enabled: true}).Validate(), "enabled without limits")
+	assert.Error(t, (&RetentionConfig{MaxAgeDays: -1}).Validate())
+	assert.Error(t, (&RetentionConfig{MaxSize: "huge"}).Validate())
+}
+
+func TestPostProcessConfig(t *testing.T) {
+	config := &PostProcessConfig{
+		Steps:         []string{PostProcessRemuxMP4},
+		PlatformSteps: map[string][]string{"telegram": {PostProcessH264, PostProcessStripEXIF}, "x": {}},
+	}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, []string{PostProcessRemuxMP4}, config.StepsFor(PlatformInstagram))
+	assert.Equal(t, []string{PostProcessH264, PostProcessStripEXIF}, config.StepsFor(PlatformTelegram))
+	assert.Empty(t, config.StepsFor(PlatformX), "an empty list turns the steps off for a platform")
+
+	assert.Error(t, (&PostProcessConfig{Steps: []string{"upscale"}}).Validate())
+	assert.Error(t, (&PostProcessConfig{PlatformSteps: map[string][]string{"x": {"upscale"}}}).Validate())
+}
+
+func TestStorageConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&StorageConfig{Backend: StorageLocal}).Validate())
+	assert.NoError(t, (&StorageConfig{}).Validate(), "empty backend means local")
+	assert.Error(t, (&StorageConfig{Backend: StorageLocal, DeleteLocal: true}).Validate())
+	assert.Error(t, (&StorageConfig{Backend: "ftp"}).Validate())
+
+	s3 := StorageConfig{Backend: StorageS3, DeleteLocal: true, S3: S3Config{
+		Endpoint: "https://s3.us-west-004.backblazeb2.com", Bucket: "archive",
+		AccessKeyID: "key", SecretAccessKey: "secret",
+	}}
+	assert.NoError(t, s3.Validate())
+	s3.S3.SecretAccessKey = ""
+	assert.Error(t, s3.Validate())
+	s3.S3.SecretAccessKey, s3.S3.Endpoint = "secret", "s3.amazonaws.com"
+	assert.Error(t, s3.Validate(), "endpoint needs a scheme")
+
+	assert.NoError(t, (&StorageConfig{Backend: StorageWebDAV, WebDAV: WebDAVConfig{URL: "https://cloud.example.com/dav"}}).Validate())
+	assert.Error(t, (&StorageConfig{Backend: StorageWebDAV}).Validate())
+}
+
+func TestValidateCommandWrapper(t *testing.T) {
+	assert.NoError(t, ValidateCommandWrapper("twitter.wrapper", nil))
+	assert.NoError(t, ValidateCommandWrapper("twitter.wrapper", []string{"nice", "-n", "10"}))
+	err := ValidateCommandWrapper("telegram.wrapper", []string{"firejail", " "})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "telegram.wrapper")
+}
+
+func TestDownloadConfig_ValidateSizeClasses(t *testing.T) {
+	assert.NoError(t, (&DownloadConfig{}).ValidateSizeClasses())
+	assert.NoError(t, (&DownloadConfig{LargeSize: "200MiB", LargeConcurrency: 1}).ValidateSizeClasses())
+	assert.Error(t, (&DownloadConfig{LargeSize: "big", LargeConcurrency: 1}).ValidateSizeClasses())
+	assert.Error(t, (&DownloadConfig{LargeSize: "200MiB"}).ValidateSizeClasses())
+	assert.Equal(t, int64(200<<20), (&DownloadConfig{LargeSize: "200MiB"}).LargeSizeBytes())
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CookieState summarizes the health of a platform's cookie file
+type CookieState string
+
+const (
+	CookieUnknown  CookieState = "unknown"  // Not checked yet
+	CookieOK       CookieState = "ok"       // Present, not expiring soon, accepted by the platform
+	CookieExpiring CookieState = "expiring" // Auth cookies expire within the warning window
+	CookieExpired  CookieState = "expired"  // Auth cookies have expired
+	CookieInvalid  CookieState = "invalid"  // Missing auth cookies or rejected by the platform
+	CookieMissing  CookieState = "missing"  // No cookie file configured or found
+)
+
+// CookieStatus is the result of checking a platform's cookie file
+type CookieStatus struct {
+	Platform     Platform    `json:"platform"`
+	CookieFile   string      `json:"cookie_file"`
+	State        CookieState `json:"state"`
+	ExpiresAt    *time.Time  `json:"expires_at,omitempty"`    // Earliest expiry of the auth cookies
+	LastModified *time.Time  `json:"last_modified,omitempty"` // Cookie file modification time
+	Validated    bool        `json:"validated"`               // An authenticated request was made
+	Message      string      `json:"message,omitempty"`
+	CheckedAt    time.Time   `json:"checked_at"`
+
+	// AuthTokenExpiresAt is the expiry of the session cookie (auth_token on X)
+	AuthTokenExpiresAt *time.Time `json:"auth_token_expires_at,omitempty"`
+}
+
+// NeedsAttention reports whether downloads are failing or about to fail
+// because of the cookies
+func (s CookieStatus) NeedsAttention() bool {
+	return s.State == CookieExpiring || s.State == CookieExpired || s.State == CookieInvalid
+}
+
+// CookieChecker validates the cookie file a downloader authenticates with
+type CookieChecker interface {
+	// CheckCookies inspects the cookie file and, when configured, makes a
+	// lightweight authenticated request. warnWithin is how far ahead an
+	// upcoming expiry is reported as CookieExpiring.
+	CheckCookies(ctx context.Context, warnWithin time.Duration) CookieStatus
+}
+
+// CookieImporter replaces the cookie file a downloader authenticates with
+type CookieImporter interface {
+	// ImportCookies validates data as a cookie file and, if the cookies would
+	// work, replaces the cookie file with it. warnWithin is as for
+	// CheckCookies. Cookies that would not work are rejected with an
+	// *InvalidCookieError.
+	ImportCookies(data []byte, warnWithin time.Duration) (CookieStatus, error)
+}
+
+// InvalidCookieError is returned by a CookieImporter for a cookie file that
+// is unreadable, lacks the auth cookies or has expired
+type InvalidCookieError struct {
+	Status CookieStatus
+}
+
+// Error implements error
+func (e *InvalidCookieError) Error() string {
+	return "invalid cookie file: " + e.Status.Message
+}
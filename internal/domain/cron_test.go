@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronExpression_Next(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 7, 30, 0, time.UTC) // Monday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 6 * * *", time.Date(2024, 1, 16, 6, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0,30 10 * * *", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		// dom and dow both restricted: either matches (15th is today at 10:07, next is Friday the 19th)
+		{"0 8 15 * 5", time.Date(2024, 1, 19, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Next(base))
+		})
+	}
+}
+
+func TestCronExpression_NextImpossible(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, c.Next(time.Now()).IsZero())
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "sort"
+
+// DuplicateGroup is the completed files sharing a content hash, the files
+// that are not linked duplicates first
+type DuplicateGroup struct {
+	ContentHash string         `json:"content_hash"`
+	FileSize    int64          `json:"file_size"`
+	Files       []DownloadItem `json:"files"`
+}
+
+// DedupeReport lists the files downloaded more than once (download.dedupe)
+type DedupeReport struct {
+	Groups     []DuplicateGroup `json:"groups"`
+	Duplicates int              `json:"duplicates"`  // Files whose content an earlier file already had
+	Linked     int              `json:"linked"`      // Duplicates replaced by a hard link to the earlier file
+	SavedBytes int64            `json:"saved_bytes"` // Size of the linked duplicates
+}
+
+// NewDedupeReport groups items by content hash, largest files first. Items
+// without a hash or with a hash no other item shares are left out.
+func NewDedupeReport(items []DownloadItem) *DedupeReport {
+	byHash := make(map[string][]DownloadItem)
+	for _, item := range items {
+		if item.ContentHash != "" {
+			byHash[item.ContentHash] = append(byHash[item.ContentHash], item)
+		}
+	}
+
+	report := &DedupeReport{Groups: []DuplicateGroup{}}
+	for hash, files := range byHash {
+		if len(files) < 2 {
+			continue
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			if (files[i].DuplicateOf == "") != (files[j].DuplicateOf == "") {
+				return files[i].DuplicateOf == ""
+			}
+			return files[i].ID < files[j].ID
+		})
+		report.Groups = append(report.Groups, DuplicateGroup{ContentHash: hash, FileSize: files[0].FileSize, Files: files})
+		report.Duplicates += len(files) - 1
+		for _, file := range files {
+			if file.DuplicateOf != "" {
+				report.Linked++
+				report.SavedBytes += file.FileSize
+			}
+		}
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].FileSize != report.Groups[j].FileSize {
+			return report.Groups[i].FileSize > report.Groups[j].FileSize
+		}
+		return report.Groups[i].ContentHash < report.Groups[j].ContentHash
+	})
+	return report
+}
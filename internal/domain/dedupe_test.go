@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDedupeReport(t *testing.T) {
+	report := NewDedupeReport([]DownloadItem{
+		{ID: 3, DownloadID: "b", FilePath: "/c/b.mp4", FileSize: 100, ContentHash: "video", DuplicateOf: "a"},
+		{ID: 1, DownloadID: "a", FilePath: "/c/a.mp4", FileSize: 100, ContentHash: "video"},
+		{ID: 4, DownloadID: "c", FilePath: "/c/c.mp4", FileSize: 100, ContentHash: "video"}, // Link failed
+		{ID: 2, DownloadID: "a", FilePath: "/c/a.jpg", FileSize: 10, ContentHash: "photo"},
+		{ID: 5, DownloadID: "d", FilePath: "/c/d.jpg", FileSize: 10, ContentHash: "photo", DuplicateOf: "a"},
+		{ID: 6, DownloadID: "e", FilePath: "/c/e.jpg", FileSize: 5, ContentHash: "unique"},
+		{ID: 7, DownloadID: "f", FilePath: "/c/f.jpg", FileSize: 5},
+	})
+
+	require.Len(t, report.Groups, 2)
+	assert.Equal(t, "video", report.Groups[0].ContentHash, "largest files first")
+	assert.Equal(t, int64(100), report.Groups[0].FileSize)
+	require.Len(t, report.Groups[0].Files, 3)
+	assert.Equal(t, "a", report.Groups[0].Files[0].DownloadID, "the original leads")
+	assert.Equal(t, "c", report.Groups[0].Files[1].DownloadID)
+	assert.Equal(t, "b", report.Groups[0].Files[2].DownloadID)
+	assert.Equal(t, 3, report.Duplicates)
+	assert.Equal(t, 2, report.Linked)
+	assert.Equal(t, int64(110), report.SavedBytes)
+
+	empty := NewDedupeReport(nil)
+	assert.NotNil(t, empty.Groups)
+	assert.Zero(t, empty.Duplicates)
+}
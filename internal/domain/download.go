@@ -1,6 +1,11 @@
 package domain
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,9 +18,11 @@ type DownloadStatus string
 const (
 	StatusQueued     DownloadStatus = "queued"
 	StatusProcessing DownloadStatus = "processing"
+	StatusRecording  DownloadStatus = "recording" // Live broadcast being recorded
 	StatusCompleted  DownloadStatus = "completed"
 	StatusFailed     DownloadStatus = "failed"
 	StatusCancelled  DownloadStatus = "cancelled"
+	StatusExpired    DownloadStatus = "expired" // Files removed by the retention policy
 )
 
 // Platform represents the source platform for downloads
@@ -35,30 +42,85 @@ const (
 	ModeDefault DownloadMode = "default" // Use config settings
 	ModeSingle  DownloadMode = "single"  // Single file download
 	ModeGroup   DownloadMode = "group"   // Group download
+	ModeProfile DownloadMode = "profile" // X only: every tweet with media on a profile (https://x.com/<user>/media)
+)
+
+// DownloadSource records how a download was added
+type DownloadSource string
+
+const (
+	SourceAPI         DownloadSource = "api"          // HTTP API callers that name no source
+	SourceCLI         DownloadSource = "cli"          // x-extract download
+	SourceDashboard   DownloadSource = "dashboard"    // Web dashboard
+	SourceMonitor     DownloadSource = "monitor"      // Schedules and subscriptions watching a source for new posts
+	SourceTelegramBot DownloadSource = "telegram-bot" // Links sent to the Telegram bot
+	SourceWatchFolder DownloadSource = "watch-folder" // URL files dropped in a watched folder
+	SourceImport      DownloadSource = "import"       // x-extract import-library
 )
 
 // Download represents a download task
 type Download struct {
-	ID           string         `json:"id" gorm:"primaryKey"`
-	URL          string         `json:"url" gorm:"not null"`
-	Platform     Platform       `json:"platform" gorm:"not null"`
-	Status       DownloadStatus `json:"status" gorm:"not null;index"`
-	Mode         DownloadMode   `json:"mode" gorm:"default:default"`
-	Priority     int            `json:"priority" gorm:"default:0;index"`
-	RetryCount   int            `json:"retry_count" gorm:"default:0"`
-	ErrorMessage string         `json:"error_message,omitempty"`
-	FilePath     string         `json:"file_path,omitempty"`
-	Metadata     string         `json:"metadata,omitempty" gorm:"type:text"`    // JSON metadata
-	ProcessLog   string         `json:"process_log,omitempty" gorm:"type:text"` // Process output log (yt-dlp/tdl)
-	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	StartedAt    *time.Time     `json:"started_at,omitempty"`
-	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
+	ID            string         `json:"id" gorm:"primaryKey"`
+	URL           string         `json:"url" gorm:"not null"`
+	Platform      Platform       `json:"platform" gorm:"not null"`
+	Status        DownloadStatus `json:"status" gorm:"not null;index"`
+	Mode          DownloadMode   `json:"mode" gorm:"default:default"`
+	Priority      int            `json:"priority" gorm:"default:0;index"`
+	RetryCount    int            `json:"retry_count" gorm:"default:0"`
+	ErrorMessage  string         `json:"error_message,omitempty"`
+	ErrorCode     ErrorCode      `json:"error_code,omitempty" gorm:"index"` // Why the download failed (empty when not recognised)
+	FilePath      string         `json:"file_path,omitempty"`
+	FileSize      int64          `json:"file_size,omitempty"`                          // Total size in bytes of all downloaded files
+	MediaDuration float64        `json:"media_duration,omitempty"`                     // Total play time in seconds of the downloaded videos, from their .info.json "duration" (0 = unknown or none)
+	RemoteURL     string         `json:"remote_url,omitempty"`                         // Where FilePath was uploaded by a remote storage.backend
+	Metadata      string         `json:"metadata,omitempty" gorm:"type:text"`          // JSON metadata
+	Title         string         `json:"title,omitempty"`                              // Promoted from Metadata
+	Uploader      string         `json:"uploader,omitempty" gorm:"index"`              // Promoted from Metadata
+	UploaderID    string         `json:"uploader_id,omitempty" gorm:"index"`           // Promoted from Metadata
+	UploadDate    string         `json:"upload_date,omitempty" gorm:"index"`           // Promoted from Metadata (YYYYMMDD)
+	WebpageURL    string         `json:"webpage_url,omitempty"`                        // Promoted from Metadata
+	Language      string         `json:"language,omitempty" gorm:"index"`              // Promoted from Metadata, else detected from its description or title
+	ProcessLog    string         `json:"process_log,omitempty" gorm:"type:text"`       // Process output log (yt-dlp/tdl)
+	Timeline      Timeline       `json:"timeline,omitempty" gorm:"type:text"`          // Status transitions, oldest first
+	Progress      float64        `json:"progress"`                                     // Percent complete (0-100) of the current file
+	Speed         string         `json:"speed,omitempty"`                              // Transfer speed reported by the tool
+	ETA           string         `json:"eta,omitempty"`                                // Time remaining reported by the tool
+	CurrentFile   string         `json:"current_file,omitempty"`                       // File currently being downloaded
+	RangeStart    int            `json:"range_start,omitempty"`                        // First Telegram message ID of a range download
+	RangeEnd      int            `json:"range_end,omitempty"`                          // Last Telegram message ID of a range download (0 = single message)
+	AllVariants   bool           `json:"all_variants,omitempty"`                       // Fetch every image at original resolution and every video rendition
+	ItemCount     int            `json:"item_count,omitempty"`                         // Number of tweets in a profile download, as reported by yt-dlp
+	Items         []DownloadItem `json:"items,omitempty" gorm:"foreignKey:DownloadID"` // Files of the download, loaded by FindByID and FindAll
+	ClientProfile string         `json:"client_profile,omitempty"`                     // Client settings of the last attempt (impersonation, user agent, proxy)
+	CallbackURL   string         `json:"callback_url,omitempty"`                       // POSTed when the download reaches a terminal state
+	Source        DownloadSource `json:"source,omitempty" gorm:"index"`                // How the download was added (empty for downloads added before it was recorded)
+	Account       string         `json:"account,omitempty"`                            // X only: twitter.accounts cookie account to download with (empty = each in turn)
+	TDLProfile    string         `json:"tdl_profile,omitempty"`                        // Telegram only: telegram.profiles tdl profile to download with (empty = telegram.profile)
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	StartedAt     *time.Time     `json:"started_at,omitempty"`
+	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
+
+	// Resource usage of the external tools of the last attempt, to spot
+	// downloads that take far more CPU or memory than they should
+	ProcessCount   int   `json:"process_count,omitempty"`
+	ProcessCPUMs   int64 `json:"process_cpu_ms,omitempty" gorm:"index"` // User plus system CPU time
+	ProcessWallMs  int64 `json:"process_wall_ms,omitempty"`             // Time the tools ran
+	ProcessPeakRSS int64 `json:"process_peak_rss,omitempty"`            // Largest resident set size in bytes
+
+	// EstimatedSize is the size in bytes a simulated run of the tool reported
+	// before the download first started, for download.large_size (0 = unknown)
+	EstimatedSize int64 `json:"estimated_size,omitempty"`
+
+	// Deferred lists the maintenance jobs (MaintenanceTranscodes,
+	// MaintenanceRemoteSync) of the completed download that wait for
+	// maintenance.window, comma-separated
+	Deferred string `json:"deferred,omitempty" gorm:"index"`
 }
 
 // NewDownload creates a new download task
 func NewDownload(url string, platform Platform, mode DownloadMode) *Download {
-	return &Download{
+	d := &Download{
 		ID:         uuid.New().String()[:8],
 		URL:        url,
 		Platform:   platform,
@@ -69,11 +131,88 @@ func NewDownload(url string, platform Platform, mode DownloadMode) *Download {
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
+	d.Timeline.add(TimelineQueued, "")
+	return d
+}
+
+// NewImportedDownload creates a completed download for files that are already
+// in the archive (x-extract import-library) rather than downloaded by x-extract
+func NewImportedDownload(url string, platform Platform, filePath string, completedAt time.Time) *Download {
+	now := time.Now()
+	d := &Download{
+		ID:          uuid.New().String()[:8],
+		URL:         url,
+		Platform:    platform,
+		Status:      StatusCompleted,
+		Mode:        ModeDefault,
+		Progress:    100,
+		FilePath:    filePath,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Source:      SourceImport,
+		CompletedAt: &completedAt,
+	}
+	d.Timeline.add(TimelineImported, "")
+	return d
+}
+
+// ValidateCallbackURL checks a download's callback URL: empty, or an absolute
+// http or https URL
+func ValidateCallbackURL(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid callback_url %q (must be an http or https URL)", callbackURL)
+	}
+	return nil
+}
+
+// DuplicateDownloadError is returned when a download is added for a URL that
+// is already queued, in progress or downloaded. Existing is that download.
+type DuplicateDownloadError struct {
+	Existing *Download
+}
+
+// Error implements error
+func (e *DuplicateDownloadError) Error() string {
+	if e.Existing.Status == StatusCompleted {
+		return fmt.Sprintf("already downloaded as %s", e.Existing.ID)
+	}
+	return fmt.Sprintf("already %s as %s", e.Existing.Status, e.Existing.ID)
+}
+
+// NotQueuedError is returned when a change that only applies to queued
+// downloads, like a new priority, is made to one that has left the queue
+type NotQueuedError struct {
+	ID     string
+	Status DownloadStatus
+}
+
+// Error implements error
+func (e *NotQueuedError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("download %s is not queued", e.ID)
+	}
+	return fmt.Sprintf("download %s is %s, not queued", e.ID, e.Status)
 }
 
 // MarkProcessing marks the download as processing
 func (d *Download) MarkProcessing() {
 	d.Status = StatusProcessing
+	d.resetProgress()
+	d.Timeline.add(TimelineStarted, "")
+	now := time.Now()
+	d.StartedAt = &now
+	d.UpdatedAt = now
+}
+
+// MarkRecording marks a live download as recording
+func (d *Download) MarkRecording() {
+	d.Status = StatusRecording
+	d.resetProgress()
+	d.Timeline.add(TimelineRecording, "")
 	now := time.Now()
 	d.StartedAt = &now
 	d.UpdatedAt = now
@@ -83,21 +222,157 @@ func (d *Download) MarkProcessing() {
 func (d *Download) MarkCompleted(filePath string) {
 	d.Status = StatusCompleted
 	d.FilePath = filePath
+	d.resetProgress()
+	d.Progress = 100
+	d.Timeline.add(TimelineCompleted, "")
 	now := time.Now()
 	d.CompletedAt = &now
 	d.UpdatedAt = now
 }
 
-// MarkFailed marks the download as failed
+// ApplyResult records what a downloader produced: the main file, metadata,
+// items, item count and client settings. Without items, downloads that have
+// none get one per file; MarkCompleted then completes the download.
+func (d *Download) ApplyResult(r *DownloadResult) {
+	if len(r.Files) > 0 {
+		d.FilePath = r.Files[0]
+	}
+	if r.Metadata != nil {
+		if data, err := json.Marshal(r.Metadata); err == nil {
+			d.Metadata = string(data)
+		}
+	}
+	switch {
+	case r.Items != nil:
+		d.Items = r.Items
+	case len(d.Items) == 0:
+		for _, file := range r.Files {
+			d.Items = append(d.Items, DownloadItem{Status: StatusCompleted, FilePath: file})
+		}
+	}
+	if r.ItemCount > d.ItemCount {
+		d.ItemCount = r.ItemCount
+	}
+	if r.ClientProfile != "" {
+		d.ClientProfile = r.ClientProfile
+	}
+}
+
+// ApplyAttemptError records the client settings of a failed attempt, when
+// its downloader returned them in a ClientProfileError
+func (d *Download) ApplyAttemptError(err error) {
+	var profileErr *ClientProfileError
+	if errors.As(err, &profileErr) {
+		d.ClientProfile = profileErr.Profile
+	}
+}
+
+// ApplyResourceUsage records the resource usage of an attempt
+func (d *Download) ApplyResourceUsage(u ResourceUsage) {
+	d.ProcessCount = u.Processes
+	d.ProcessCPUMs = u.CPUTime.Milliseconds()
+	d.ProcessWallMs = u.WallTime.Milliseconds()
+	d.ProcessPeakRSS = u.PeakRSS
+}
+
+// MarkFailed marks the download as failed, classifying err (see ErrorCodeOf)
 func (d *Download) MarkFailed(err error) {
 	d.Status = StatusFailed
 	d.ErrorMessage = err.Error()
+	d.ErrorCode = ErrorCodeOf(err)
+	d.Timeline.add(TimelineFailed, d.ErrorMessage)
+	d.UpdatedAt = time.Now()
+}
+
+// MarkCancelled marks the download as cancelled
+func (d *Download) MarkCancelled() {
+	d.Status = StatusCancelled
+	d.Timeline.add(TimelineCancelled, "")
 	d.UpdatedAt = time.Now()
 }
 
+// MarkExpired marks a completed download whose files were removed by the
+// retention policy. Its completed items are marked expired too.
+func (d *Download) MarkExpired() {
+	d.Status = StatusExpired
+	for i := range d.Items {
+		if d.Items[i].Status == StatusCompleted {
+			d.Items[i].Status = StatusExpired
+		}
+	}
+	d.Timeline.add(TimelineExpired, "")
+	d.UpdatedAt = time.Now()
+}
+
+// MarkRequeued resets a failed, cancelled or expired download so it is picked up again
+func (d *Download) MarkRequeued() {
+	d.Status = StatusQueued
+	d.RetryCount = 0
+	d.ErrorMessage = ""
+	d.ErrorCode = ""
+	d.StartedAt = nil
+	d.CompletedAt = nil
+	d.resetProgress()
+	d.Timeline.add(TimelineRequeued, "")
+	d.UpdatedAt = time.Now()
+}
+
+// MarkInterrupted queues a download again whose tool was stopped before it
+// finished, e.g. because the server shut down, so it resumes on the next
+// start. Its retry count is kept.
+func (d *Download) MarkInterrupted(reason string) {
+	d.Status = StatusQueued
+	d.StartedAt = nil
+	d.resetProgress()
+	d.Timeline.add(TimelineInterrupted, reason)
+	d.UpdatedAt = time.Now()
+}
+
+// RecoverOrphaned handles a download left processing or recording by a server
+// that crashed or was killed. The lost attempt counts as a retry, so a download
+// that keeps taking the server down is not retried forever: it is queued again
+// while retries remain and fails once they are used up.
+func (d *Download) RecoverOrphaned(maxRetries int) {
+	if d.RetryCount >= maxRetries {
+		d.MarkFailed(fmt.Errorf("server stopped during download, no retries left"))
+		return
+	}
+	d.IncrementRetry()
+	d.MarkInterrupted("server stopped during download")
+}
+
+// ApplyProgress records a progress update from the downloader. Empty fields
+// and negative (failure) percentages keep the previous values.
+func (d *Download) ApplyProgress(p DownloadProgress) {
+	if p.Percent >= 0 {
+		d.Progress = p.Percent
+	}
+	if p.Speed != "" {
+		d.Speed = p.Speed
+	}
+	if p.ETA != "" {
+		d.ETA = p.ETA
+	}
+	if p.CurrentFile != "" {
+		d.CurrentFile = p.CurrentFile
+	}
+	if p.ItemCount > 0 {
+		d.ItemCount = p.ItemCount
+	}
+}
+
+// resetProgress clears the live progress fields
+func (d *Download) resetProgress() {
+	d.Progress = 0
+	d.Speed = ""
+	d.ETA = ""
+	d.CurrentFile = ""
+}
+
 // IncrementRetry increments the retry count
 func (d *Download) IncrementRetry() {
 	d.RetryCount++
+	d.Timeline.add(TimelineRetry, fmt.Sprintf("#%d", d.RetryCount))
 	d.UpdatedAt = time.Now()
 }
 
@@ -108,7 +383,154 @@ func (d *Download) CanRetry(maxRetries int) bool {
 
 // IsTerminal checks if the download is in a terminal state
 func (d *Download) IsTerminal() bool {
-	return d.Status == StatusCompleted || d.Status == StatusCancelled
+	return d.Status == StatusCompleted || d.Status == StatusCancelled || d.Status == StatusExpired
+}
+
+// SyncMetadataColumns copies the frequently queried metadata fields (title,
+// uploader, uploader_id, upload_date, webpage_url) from the Metadata JSON blob
+// into their own columns. Metadata that is empty or not a JSON object leaves
+// the columns untouched.
+func (d *Download) SyncMetadataColumns() {
+	if d.Metadata == "" {
+		return
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Metadata), &meta); err != nil {
+		return
+	}
+	d.Title = metadataString(meta, "title")
+	d.Uploader = metadataString(meta, "uploader")
+	d.UploaderID = metadataString(meta, "uploader_id")
+	d.UploadDate = metadataString(meta, "upload_date")
+	d.WebpageURL = metadataString(meta, "webpage_url")
+	d.Language = metadataString(meta, "language")
+	if d.Language == "" {
+		// Metadata written before languages were detected
+		if description := metadataString(meta, "description"); description != "" {
+			d.Language = DetectLanguage(description)
+		} else {
+			d.Language = DetectLanguage(d.Title)
+		}
+	}
+}
+
+// Files returns the paths of all files produced by the download: its
+// completed items when loaded, else the "files" list from Metadata when
+// present, otherwise FilePath.
+func (d *Download) Files() []string {
+	if len(d.Items) > 0 {
+		var files []string
+		for _, item := range d.Items {
+			if item.Status == StatusCompleted && item.FilePath != "" {
+				files = append(files, item.FilePath)
+			}
+		}
+		return files
+	}
+	if d.Metadata != "" {
+		var meta struct {
+			Files []string `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(d.Metadata), &meta); err == nil && len(meta.Files) > 0 {
+			return meta.Files
+		}
+	}
+	if d.FilePath != "" {
+		return []string{d.FilePath}
+	}
+	return nil
+}
+
+// RemoteURLOf returns the URL a file of the download was uploaded to, or ""
+func (d *Download) RemoteURLOf(file string) string {
+	for _, item := range d.Items {
+		if item.FilePath == file {
+			return item.RemoteURL
+		}
+	}
+	if file == d.FilePath {
+		return d.RemoteURL
+	}
+	return ""
+}
+
+// RenameFiles replaces the paths of files that were renamed (old path -> new
+// path) in FilePath, the items and the "files" list of Metadata
+func (d *Download) RenameFiles(renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+	if path, ok := renamed[d.FilePath]; ok {
+		d.FilePath = path
+	}
+	for i := range d.Items {
+		if path, ok := renamed[d.Items[i].FilePath]; ok {
+			d.Items[i].FilePath = path
+		}
+	}
+
+	var meta map[string]interface{}
+	if d.Metadata == "" || json.Unmarshal([]byte(d.Metadata), &meta) != nil {
+		return
+	}
+	files, ok := meta["files"].([]interface{})
+	if !ok {
+		return
+	}
+	for i, file := range files {
+		if path, ok := renamed[fmt.Sprint(file)]; ok {
+			files[i] = path
+		}
+	}
+	d.SetMetadataField("files", files)
+}
+
+// SetMetadataField sets one top-level field of the JSON Metadata, creating it
+// when empty. Metadata that is not a JSON object is left unchanged.
+func (d *Download) SetMetadataField(key string, value interface{}) {
+	var meta map[string]interface{}
+	if d.Metadata != "" && json.Unmarshal([]byte(d.Metadata), &meta) != nil {
+		return
+	}
+	if meta == nil {
+		meta = map[string]interface{}{}
+	}
+	meta[key] = value
+	if data, err := json.Marshal(meta); err == nil {
+		d.Metadata = string(data)
+	}
+}
+
+// metadataString returns meta[key] as a string. Numeric IDs are formatted
+// without exponent; other types yield "".
+func metadataString(meta map[string]interface{}, key string) string {
+	switch v := meta[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// IsRange reports whether the download fetches a span of Telegram messages
+// (RangeStart..RangeEnd) instead of a single message
+func (d *Download) IsRange() bool {
+	return d.RangeEnd > 0
+}
+
+// MessageURLs returns the Telegram message URLs the download covers: one per
+// message ID from RangeStart to RangeEnd for range downloads, otherwise just URL.
+func (d *Download) MessageURLs() []string {
+	if !d.IsRange() {
+		return []string{d.URL}
+	}
+	urls := make([]string, 0, d.RangeEnd-d.RangeStart+1)
+	for id := d.RangeStart; id <= d.RangeEnd; id++ {
+		urls = append(urls, TelegramMessageURL(d.URL, id))
+	}
+	return urls
 }
 
 // IsPending checks if the download is pending
@@ -121,6 +543,21 @@ func (d *Download) IsProcessing() bool {
 	return d.Status == StatusProcessing
 }
 
+// IsRecording checks if the download is recording a live broadcast
+func (d *Download) IsRecording() bool {
+	return d.Status == StatusRecording
+}
+
+// IsRunning checks if a worker is currently downloading or recording it
+func (d *Download) IsRunning() bool {
+	return d.Status == StatusProcessing || d.Status == StatusRecording
+}
+
+// IsLive reports whether the download is a live broadcast (see IsLiveURL)
+func (d *Download) IsLive() bool {
+	return IsLiveURL(d.URL)
+}
+
 // platformDef holds the URL prefixes that identify a platform.
 // PlatformGallery has no prefixes — it is the catch-all fallback.
 // To add a new platform: define its constant above and add an entry here.
@@ -179,7 +616,54 @@ func ValidatePlatform(platform Platform) bool {
 
 // ValidateMode checks if a download mode is valid
 func ValidateMode(mode DownloadMode) bool {
-	return mode == ModeDefault || mode == ModeSingle || mode == ModeGroup
+	return mode == ModeDefault || mode == ModeSingle || mode == ModeGroup || mode == ModeProfile
+}
+
+// ValidateSource checks if a download source is valid
+func ValidateSource(source DownloadSource) bool {
+	switch source {
+	case SourceAPI, SourceCLI, SourceDashboard, SourceMonitor, SourceTelegramBot, SourceWatchFolder, SourceImport:
+		return true
+	}
+	return false
+}
+
+// Metadata keys of the backend of a fallback chain that downloaded a download
+const (
+	MetadataBackend         = "backend"          // Name of the backend that succeeded
+	MetadataBackendFailures = "backend_failures" // Backends that failed before it ([]BackendFailure)
+)
+
+// RecordBackend records in the metadata which backend of a fallback chain
+// downloaded the download, and the backends that failed before it
+func (d *Download) RecordBackend(backend string, failures []BackendFailure) {
+	d.SetMetadataField(MetadataBackend, backend)
+	if len(failures) > 0 {
+		d.SetMetadataField(MetadataBackendFailures, failures)
+	}
+}
+
+// DeferJob records a maintenance job of the download that waits for
+// maintenance.window
+func (d *Download) DeferJob(job string) {
+	if !d.HasDeferred(job) {
+		d.Deferred = strings.Join(append(d.DeferredJobs(), job), ",")
+	}
+}
+
+// HasDeferred reports whether a maintenance job of the download waits for
+// maintenance.window
+func (d *Download) HasDeferred(job string) bool {
+	return containsString(d.DeferredJobs(), job)
+}
+
+// DeferredJobs returns the maintenance jobs of the download that wait for
+// maintenance.window
+func (d *Download) DeferredJobs() []string {
+	if d.Deferred == "" {
+		return nil
+	}
+	return strings.Split(d.Deferred, ",")
 }
 
 // MetadataKeyGalleryFilters is the JSON key used to store gallery-dl filter options
@@ -240,3 +724,97 @@ func DetectXURLType(url string) XURLType {
 	}
 	return XURLTypeTimeline
 }
+
+// reservedXPaths are first path segments of x.com URLs that are not usernames
+var reservedXPaths = map[string]bool{
+	"i": true, "home": true, "explore": true, "search": true, "notifications": true,
+	"messages": true, "settings": true, "hashtag": true, "compose": true,
+}
+
+// XProfileUsername returns the username of an X profile URL
+// (https://x.com/<user> or https://x.com/<user>/media), or "" if url is not one
+func XProfileUsername(url string) string {
+	if DetectXURLType(url) != XURLTypeTimeline {
+		return ""
+	}
+	if idx := strings.IndexAny(url, "?#"); idx >= 0 {
+		url = url[:idx]
+	}
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://x.com/"), "https://twitter.com/")
+	parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "media") {
+		return ""
+	}
+	if parts[0] == "" || reservedXPaths[strings.ToLower(parts[0])] {
+		return ""
+	}
+	return parts[0]
+}
+
+// MaxTelegramRange is the largest number of messages a single range download
+// may span
+const MaxTelegramRange = 1000
+
+// TelegramMessageID returns the message ID a Telegram message URL ends with
+// (https://t.me/channel/123 or https://t.me/c/12345/123), or 0 if it has none.
+func TelegramMessageID(url string) int {
+	if idx := strings.IndexByte(url, '?'); idx >= 0 {
+		url = url[:idx]
+	}
+	id, err := strconv.Atoi(url[strings.LastIndexByte(url, '/')+1:])
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+// TelegramMessageURL returns the Telegram message URL with its message ID
+// replaced by id. The query string, if any, is dropped.
+func TelegramMessageURL(url string, id int) string {
+	if idx := strings.IndexByte(url, '?'); idx >= 0 {
+		url = url[:idx]
+	}
+	return url[:strings.LastIndexByte(url, '/')+1] + strconv.Itoa(id)
+}
+
+// ValidateMessageRange checks that url is a Telegram message URL and that end
+// lies after its message ID, no more than MaxTelegramRange messages away. It
+// returns the message ID of url, the start of the range.
+func ValidateMessageRange(url string, end int) (int, error) {
+	if DetectPlatform(url) != PlatformTelegram {
+		return 0, fmt.Errorf("message ranges are only supported for Telegram URLs")
+	}
+	start := TelegramMessageID(url)
+	if start == 0 {
+		return 0, fmt.Errorf("URL does not end in a message ID: %s", url)
+	}
+	if end <= start {
+		return 0, fmt.Errorf("range end %d must be greater than the start message ID %d", end, start)
+	}
+	if end-start+1 > MaxTelegramRange {
+		return 0, fmt.Errorf("range %d-%d spans more than %d messages", start, end, MaxTelegramRange)
+	}
+	return start, nil
+}
+
+// IsLiveURL reports whether url is a live broadcast: an X broadcast
+// (https://x.com/i/broadcasts/<id>) or a Telegram live stream
+// (https://t.me/<channel>?livestream, ?videochat or ?voicechat)
+func IsLiveURL(url string) bool {
+	path, query := url, ""
+	if idx := strings.IndexByte(url, '?'); idx >= 0 {
+		path, query = url[:idx], url[idx+1:]
+	}
+	switch DetectPlatform(url) {
+	case PlatformX:
+		return strings.Contains(path, "/i/broadcasts/")
+	case PlatformTelegram:
+		for _, param := range strings.Split(query, "&") {
+			key, _, _ := strings.Cut(param, "=")
+			if key == "livestream" || key == "videochat" || key == "voicechat" {
+				return true
+			}
+		}
+	}
+	return false
+}
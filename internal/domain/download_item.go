@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// DownloadItem is a single file of a download: one photo or video of a
+// Telegram group, a multi-media tweet, a message range or a profile backup.
+// Items are stored in their own table and returned nested in their download.
+type DownloadItem struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	DownloadID   string         `json:"download_id" gorm:"not null;index"`
+	Position     int            `json:"position"`                     // Order within the download, from 0
+	Status       DownloadStatus `json:"status" gorm:"not null;index"` // completed, failed for a file the tool could not fetch, or expired
+	FilePath     string         `json:"file_path,omitempty"`
+	FileSize     int64          `json:"file_size,omitempty"`     // Size in bytes
+	SourceID     string         `json:"source_id,omitempty"`     // Tweet or message ID the file belongs to, if it differs across items
+	SourceURL    string         `json:"source_url,omitempty"`    // Tweet or message URL of SourceID
+	Title        string         `json:"title,omitempty"`         // Tweet or message text
+	UploadDate   string         `json:"upload_date,omitempty"`   // YYYYMMDD
+	ErrorMessage string         `json:"error_message,omitempty"` // Why a failed item could not be fetched
+	RemoteURL    string         `json:"remote_url,omitempty"`    // Where the file was uploaded by a remote storage.backend
+	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime"`
+
+	// Recorded by download.dedupe
+	ContentHash string `json:"content_hash,omitempty" gorm:"index"` // SHA-256 of the file
+	DuplicateOf string `json:"duplicate_of,omitempty"`              // Download whose file this one is a hard link to, having the same content
+}
+
+// TableName specifies the table name for GORM
+func (DownloadItem) TableName() string {
+	return "download_items"
+}
+
+// EnsureItems gives a completed download one item per file when its
+// downloader did not report items itself, and numbers all items
+func (d *Download) EnsureItems() {
+	if len(d.Items) == 0 {
+		for _, file := range d.Files() {
+			d.Items = append(d.Items, DownloadItem{Status: StatusCompleted, FilePath: file})
+		}
+	}
+	for i := range d.Items {
+		d.Items[i].ID = 0 // Items are replaced, never updated in place
+		d.Items[i].DownloadID = d.ID
+		d.Items[i].Position = i
+	}
+}
+
+// ItemsSize returns the total size of the completed items
+func (d *Download) ItemsSize() int64 {
+	var total int64
+	for _, item := range d.Items {
+		if item.Status == StatusCompleted {
+			total += item.FileSize
+		}
+	}
+	return total
+}
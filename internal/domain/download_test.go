@@ -2,9 +2,12 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDownload(t *testing.T) {
@@ -51,6 +54,44 @@ func TestDownload_MarkFailed(t *testing.T) {
 
 	assert.Equal(t, StatusFailed, download.Status)
 	assert.Equal(t, "download failed", download.ErrorMessage)
+	assert.Empty(t, download.ErrorCode)
+}
+
+func TestDownload_ApplyResult(t *testing.T) {
+	download := NewDownload("https://x.com/alice/media", PlatformX, ModeProfile)
+	download.ItemCount = 5 // Reported by the tool while downloading
+
+	download.ApplyResult(&DownloadResult{
+		Files:     []string{"/completed/a.jpg", "/completed/b.mp4"},
+		Metadata:  map[string]interface{}{"title": "A post"},
+		ItemCount: 2,
+	})
+	assert.Equal(t, "/completed/a.jpg", download.FilePath)
+	assert.JSONEq(t, `{"title":"A post"}`, download.Metadata)
+	assert.Equal(t, 5, download.ItemCount, "the larger count is kept")
+	require.Len(t, download.Items, 2, "one item per file")
+	assert.Equal(t, "/completed/b.mp4", download.Items[1].FilePath)
+
+	// Items of the result replace the download's; without any, its own are kept
+	download.ApplyResult(&DownloadResult{Files: []string{"/completed/a.jpg"}})
+	assert.Len(t, download.Items, 2)
+	assert.JSONEq(t, `{"title":"A post"}`, download.Metadata, "nil metadata keeps the current one")
+	download.ApplyResult(&DownloadResult{
+		Files: []string{"/completed/a.jpg"},
+		Items: []DownloadItem{{Status: StatusCompleted, FilePath: "/completed/a.jpg", SourceID: "1"}},
+	})
+	require.Len(t, download.Items, 1)
+	assert.Equal(t, "1", download.Items[0].SourceID)
+}
+
+func TestDownload_MarkFailedClassifies(t *testing.T) {
+	download := NewDownload("https://x.com/test", PlatformX, ModeDefault)
+
+	download.MarkFailed(&ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1"), Code: ErrorNotFound})
+	assert.Equal(t, ErrorNotFound, download.ErrorCode)
+
+	download.MarkRequeued()
+	assert.Empty(t, download.ErrorCode)
 }
 
 func TestDownload_IncrementRetry(t *testing.T) {
@@ -129,9 +170,18 @@ func TestValidateMode(t *testing.T) {
 	assert.True(t, ValidateMode(ModeDefault))
 	assert.True(t, ValidateMode(ModeSingle))
 	assert.True(t, ValidateMode(ModeGroup))
+	assert.True(t, ValidateMode(ModeProfile))
 	assert.False(t, ValidateMode("invalid"))
 }
 
+func TestValidateSource(t *testing.T) {
+	assert.True(t, ValidateSource(SourceCLI))
+	assert.True(t, ValidateSource(SourceTelegramBot))
+	assert.True(t, ValidateSource(SourceWatchFolder))
+	assert.False(t, ValidateSource(""))
+	assert.False(t, ValidateSource("cron"))
+}
+
 func TestDetectXURLType(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -188,3 +238,281 @@ func TestDetectInstagramURLType(t *testing.T) {
 		})
 	}
 }
+
+func TestDownload_SyncMetadataColumns(t *testing.T) {
+	download := NewDownload("https://x.com/user/status/123", PlatformX, ModeDefault)
+	download.Metadata = `{"title":"Hello","uploader":"User","uploader_id":12345,"upload_date":"20240114","webpage_url":"https://x.com/user/status/123"}`
+
+	download.SyncMetadataColumns()
+
+	assert.Equal(t, "Hello", download.Title)
+	assert.Equal(t, "User", download.Uploader)
+	assert.Equal(t, "12345", download.UploaderID)
+	assert.Equal(t, "20240114", download.UploadDate)
+	assert.Equal(t, "https://x.com/user/status/123", download.WebpageURL)
+	assert.Empty(t, download.Language)
+
+	// Invalid JSON leaves the columns untouched
+	download.Metadata = "not json"
+	download.SyncMetadataColumns()
+	assert.Equal(t, "User", download.Uploader)
+
+	// Metadata without a language gets one detected from its description
+	download.Metadata = `{"title":"新视频","description":"今天的新视频已经上传了"}`
+	download.SyncMetadataColumns()
+	assert.Equal(t, "zh", download.Language)
+	download.Metadata = `{"title":"新视频","description":"今天的新视频已经上传了","language":"ja"}`
+	download.SyncMetadataColumns()
+	assert.Equal(t, "ja", download.Language)
+}
+
+func TestDownload_Files(t *testing.T) {
+	download := NewDownload("https://x.com/user/status/123", PlatformX, ModeDefault)
+	assert.Nil(t, download.Files())
+
+	download.FilePath = "/completed/a.mp4"
+	assert.Equal(t, []string{"/completed/a.mp4"}, download.Files())
+
+	download.Metadata = `{"files":["/completed/a.jpg","/completed/b.jpg"]}`
+	assert.Equal(t, []string{"/completed/a.jpg", "/completed/b.jpg"}, download.Files())
+}
+
+func TestDownload_MessageURLs(t *testing.T) {
+	download := NewDownload("https://t.me/c/12345/100", PlatformTelegram, ModeDefault)
+	assert.False(t, download.IsRange())
+	assert.Equal(t, []string{"https://t.me/c/12345/100"}, download.MessageURLs())
+
+	download.RangeStart, download.RangeEnd = 100, 102
+	assert.True(t, download.IsRange())
+	assert.Equal(t, []string{
+		"https://t.me/c/12345/100",
+		"https://t.me/c/12345/101",
+		"https://t.me/c/12345/102",
+	}, download.MessageURLs())
+}
+
+func TestTelegramMessageID(t *testing.T) {
+	assert.Equal(t, 100, TelegramMessageID("https://t.me/c/12345/100"))
+	assert.Equal(t, 42, TelegramMessageID("https://t.me/channel/42?single"))
+	assert.Equal(t, 0, TelegramMessageID("https://t.me/channel"))
+	assert.Equal(t, "https://t.me/channel/43", TelegramMessageURL("https://t.me/channel/42?single", 43))
+}
+
+func TestValidateMessageRange(t *testing.T) {
+	start, err := ValidateMessageRange("https://t.me/c/12345/100", 250)
+	require.NoError(t, err)
+	assert.Equal(t, 100, start)
+
+	_, err = ValidateMessageRange("https://x.com/user/status/100", 250)
+	assert.Error(t, err)
+	_, err = ValidateMessageRange("https://t.me/channel", 250)
+	assert.Error(t, err)
+	_, err = ValidateMessageRange("https://t.me/c/12345/100", 100)
+	assert.Error(t, err)
+	_, err = ValidateMessageRange("https://t.me/c/12345/100", 100+MaxTelegramRange)
+	assert.Error(t, err)
+}
+
+func TestIsLiveURL(t *testing.T) {
+	assert.True(t, IsLiveURL("https://x.com/i/broadcasts/1YqKDqWqdPdJV"))
+	assert.True(t, IsLiveURL("https://twitter.com/i/broadcasts/1YqKDqWqdPdJV"))
+	assert.True(t, IsLiveURL("https://t.me/channel?livestream"))
+	assert.True(t, IsLiveURL("https://t.me/channel?videochat=abc"))
+	assert.True(t, IsLiveURL("https://t.me/channel?voicechat"))
+
+	assert.False(t, IsLiveURL("https://x.com/user/status/123"))
+	assert.False(t, IsLiveURL("https://t.me/channel/42?single"))
+	assert.False(t, IsLiveURL("https://example.com/i/broadcasts/1"))
+}
+
+func TestDownload_MarkRecording(t *testing.T) {
+	d := NewDownload("https://x.com/i/broadcasts/1YqKDqWqdPdJV", PlatformX, ModeDefault)
+	assert.True(t, d.IsLive())
+	assert.False(t, d.IsRunning())
+
+	d.MarkRecording()
+	assert.Equal(t, StatusRecording, d.Status)
+	assert.True(t, d.IsRunning())
+	assert.NotNil(t, d.StartedAt)
+	assert.Equal(t, TimelineRecording, d.Timeline[len(d.Timeline)-1].Event)
+}
+
+func TestXProfileUsername(t *testing.T) {
+	assert.Equal(t, "alice", XProfileUsername("https://x.com/alice/media"))
+	assert.Equal(t, "alice", XProfileUsername("https://x.com/alice"))
+	assert.Equal(t, "alice", XProfileUsername("https://twitter.com/alice/media/?lang=en"))
+
+	assert.Equal(t, "", XProfileUsername("https://x.com/alice/status/123"))
+	assert.Equal(t, "", XProfileUsername("https://x.com/alice/likes"))
+	assert.Equal(t, "", XProfileUsername("https://x.com/i/broadcasts/1YqK"))
+	assert.Equal(t, "", XProfileUsername("https://x.com/home"))
+	assert.Equal(t, "", XProfileUsername("https://t.me/alice"))
+}
+
+func TestDownload_EnsureItems(t *testing.T) {
+	d := NewDownload("https://t.me/channel/1", PlatformTelegram, ModeGroup)
+	d.Metadata = `{"files":["/c/a.jpg","/c/b.mp4"]}`
+	d.EnsureItems()
+	require.Len(t, d.Items, 2)
+	assert.Equal(t, DownloadItem{DownloadID: d.ID, Position: 1, Status: StatusCompleted, FilePath: "/c/b.mp4"}, d.Items[1])
+
+	// Items reported by the downloader are kept and numbered
+	d.Items = []DownloadItem{
+		{Status: StatusCompleted, FilePath: "/c/a.jpg", SourceID: "1", FileSize: 10},
+		{Status: StatusFailed, SourceID: "2", ErrorMessage: "gone"},
+	}
+	d.EnsureItems()
+	assert.Equal(t, 1, d.Items[1].Position)
+	assert.Equal(t, []string{"/c/a.jpg"}, d.Files(), "failed items have no file")
+	assert.Equal(t, int64(10), d.ItemsSize())
+}
+
+func TestDownload_ApplyProgress(t *testing.T) {
+	download := NewDownload("https://x.com/user/status/123", PlatformX, ModeDefault)
+	download.MarkProcessing()
+
+	download.ApplyProgress(DownloadProgress{Percent: 42.5, Speed: "1.2MiB/s", ETA: "00:10", CurrentFile: "a.mp4"})
+	assert.Equal(t, 42.5, download.Progress)
+	assert.Equal(t, "1.2MiB/s", download.Speed)
+	assert.Equal(t, "00:10", download.ETA)
+	assert.Equal(t, "a.mp4", download.CurrentFile)
+
+	// Empty fields and failure signals keep the previous values
+	download.ApplyProgress(DownloadProgress{Percent: -1})
+	assert.Equal(t, 42.5, download.Progress)
+	assert.Equal(t, "a.mp4", download.CurrentFile)
+
+	download.MarkCompleted("/completed/a.mp4")
+	assert.Equal(t, float64(100), download.Progress)
+	assert.Empty(t, download.Speed)
+	assert.Empty(t, download.ETA)
+}
+
+func TestDownload_TimelineRecordsTransitions(t *testing.T) {
+	download := NewDownload("https://x.com/user/status/123", PlatformX, ModeDefault)
+	download.MarkProcessing()
+	download.IncrementRetry()
+	download.MarkFailed(errors.New("boom"))
+	download.MarkRequeued()
+	download.MarkProcessing()
+	download.MarkCompleted("/completed/a.mp4")
+
+	events := make([]string, len(download.Timeline))
+	for i, entry := range download.Timeline {
+		events[i] = entry.Event
+	}
+	assert.Equal(t, []string{
+		TimelineQueued, TimelineStarted, TimelineRetry, TimelineFailed,
+		TimelineRequeued, TimelineStarted, TimelineCompleted,
+	}, events)
+	assert.Equal(t, "#1", download.Timeline[2].Message)
+	assert.Equal(t, "boom", download.Timeline[3].Message)
+	assert.Equal(t, 0, download.RetryCount)
+	assert.Empty(t, download.ErrorMessage)
+}
+
+func TestTimeline_KeepsFirstAndLatestEntries(t *testing.T) {
+	var timeline Timeline
+	timeline.add(TimelineQueued, "")
+	for i := 0; i < maxTimelineEntries+10; i++ {
+		timeline.add(TimelineRetry, fmt.Sprintf("#%d", i+1))
+	}
+
+	require.Len(t, timeline, maxTimelineEntries)
+	assert.Equal(t, TimelineQueued, timeline[0].Event)
+	assert.Equal(t, fmt.Sprintf("#%d", maxTimelineEntries+10), timeline[len(timeline)-1].Message)
+}
+
+func TestTimeline_ValueAndScan(t *testing.T) {
+	value, err := Timeline(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	original := Timeline{{Time: time.Date(2024, 1, 2, 10, 3, 0, 0, time.UTC), Event: TimelineQueued}}
+	value, err = original.Value()
+	require.NoError(t, err)
+
+	var scanned Timeline
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, original, scanned)
+
+	require.NoError(t, scanned.Scan(""))
+	assert.Nil(t, scanned)
+}
+
+func TestMediaTypeOf(t *testing.T) {
+	assert.Equal(t, MediaTypeImage, MediaTypeOf("/downloads/photo.JPG"))
+	assert.Equal(t, MediaTypeImage, MediaTypeOf("/downloads/photo.webp"))
+	assert.Equal(t, MediaTypeVideo, MediaTypeOf("/downloads/clip.mp4"))
+	assert.Equal(t, MediaTypeVideo, MediaTypeOf("/downloads/stream.ts"))
+	assert.Equal(t, MediaTypeOther, MediaTypeOf("/downloads/clip.info.json"))
+	assert.Equal(t, MediaTypeOther, MediaTypeOf("/downloads/README"))
+}
+
+func TestDownload_MarkInterrupted(t *testing.T) {
+	download := NewDownload("https://t.me/test/1", PlatformTelegram, ModeDefault)
+	download.MarkProcessing()
+	download.IncrementRetry()
+	download.ApplyProgress(DownloadProgress{Percent: 40, ETA: "10:00"})
+
+	download.MarkInterrupted("server shut down")
+	assert.Equal(t, StatusQueued, download.Status)
+	assert.Nil(t, download.StartedAt)
+	assert.Zero(t, download.Progress)
+	assert.Empty(t, download.ETA)
+	assert.Equal(t, 1, download.RetryCount, "retries are kept")
+	last := download.Timeline[len(download.Timeline)-1]
+	assert.Equal(t, TimelineInterrupted, last.Event)
+	assert.Equal(t, "server shut down", last.Message)
+}
+
+func TestDownload_RecoverOrphaned(t *testing.T) {
+	download := NewDownload("https://x.com/user/status/1", PlatformX, ModeDefault)
+	download.MarkProcessing()
+	download.RecoverOrphaned(1)
+	assert.Equal(t, StatusQueued, download.Status)
+	assert.Equal(t, 1, download.RetryCount)
+
+	download.MarkProcessing()
+	download.RecoverOrphaned(1)
+	assert.Equal(t, StatusFailed, download.Status)
+	assert.Equal(t, 1, download.RetryCount)
+	assert.Equal(t, TimelineFailed, download.Timeline[len(download.Timeline)-1].Event)
+}
+
+func TestDownload_RenameFiles(t *testing.T) {
+	d := NewDownload("https://x.com/user/status/1", PlatformX, ModeDefault)
+	d.FilePath = "/completed/a.mkv"
+	d.Metadata = `{"title":"clip","files":["/completed/a.mkv","/completed/b.jpg"]}`
+	d.Items = []DownloadItem{
+		{Status: StatusCompleted, FilePath: "/completed/a.mkv"},
+		{Status: StatusCompleted, FilePath: "/completed/b.jpg"},
+	}
+
+	d.RenameFiles(map[string]string{"/completed/a.mkv": "/completed/a.mp4"})
+	assert.Equal(t, "/completed/a.mp4", d.FilePath)
+	assert.Equal(t, []string{"/completed/a.mp4", "/completed/b.jpg"}, d.Files())
+	assert.JSONEq(t, `{"title":"clip","files":["/completed/a.mp4","/completed/b.jpg"]}`, d.Metadata)
+
+	d.SetMetadataField("postprocess", []string{"done"})
+	assert.JSONEq(t, `{"title":"clip","files":["/completed/a.mp4","/completed/b.jpg"],"postprocess":["done"]}`, d.Metadata)
+
+	empty := &Download{}
+	empty.SetMetadataField("postprocess", 1)
+	assert.JSONEq(t, `{"postprocess":1}`, empty.Metadata)
+}
+
+func TestDownload_RemoteURLOf(t *testing.T) {
+	d := NewDownload("https://x.com/user/status/1", PlatformX, ModeDefault)
+	d.FilePath = "/completed/a.mp4"
+	d.RemoteURL = "https://bucket.example.com/a.mp4"
+	assert.Equal(t, "https://bucket.example.com/a.mp4", d.RemoteURLOf("/completed/a.mp4"))
+
+	d.Items = []DownloadItem{
+		{FilePath: "/completed/a.mp4", RemoteURL: "https://bucket.example.com/a.mp4"},
+		{FilePath: "/completed/b.jpg"},
+	}
+	assert.Equal(t, "https://bucket.example.com/a.mp4", d.RemoteURLOf("/completed/a.mp4"))
+	assert.Empty(t, d.RemoteURLOf("/completed/b.jpg"), "not uploaded")
+	assert.Empty(t, d.RemoteURLOf("/completed/c.jpg"))
+}
@@ -1,16 +1,107 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// DownloadProgress is a snapshot of a running download's progress as reported
+// by the external tool. Fields the tool did not report are left empty.
+type DownloadProgress struct {
+	Percent     float64 // 0-100; -1 signals failure
+	Speed       string  // e.g. "1.23MiB/s"
+	ETA         string  // e.g. "00:05"
+	CurrentFile string  // File currently being downloaded
+	ItemCount   int     // Number of items in the playlist being downloaded (profile downloads)
+}
 
 // DownloadProgressCallback is called with progress updates during download
-type DownloadProgressCallback func(output string, percent float64)
+type DownloadProgressCallback func(progress DownloadProgress)
+
+// RateLimitError is returned by a Downloader when the platform rejected the
+// request as rate limited (e.g. HTTP 429). RetryAfter is the wait requested by
+// the server, or zero if it did not say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error implements error
+func (e *RateLimitError) Error() string {
+	return "rate limited: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// ToolError is returned by a Downloader when an external tool (yt-dlp, tdl,
+// gallery-dl) failed, so failures can be counted per tool. Code classifies
+// the failure from the tool's output (empty when not recognised).
+type ToolError struct {
+	Tool string
+	Err  error
+	Code ErrorCode
+}
+
+// Error implements error
+func (e *ToolError) Error() string {
+	return e.Tool + " failed: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// DiskSpaceError is returned by the disk guard when a download has no room:
+// the completed directory's volume is below download.min_free_space or
+// base_dir has reached download.quota
+type DiskSpaceError struct {
+	Reason string
+}
+
+// Error implements error
+func (e *DiskSpaceError) Error() string {
+	return "not enough disk space: " + e.Reason
+}
+
+// ClientProfileError is returned by a Downloader when an attempt that ran its
+// tool with the client settings of Profile failed
+type ClientProfileError struct {
+	Profile string
+	Err     error
+}
+
+// Error implements error
+func (e *ClientProfileError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ClientProfileError) Unwrap() error {
+	return e.Err
+}
+
+// WithClientProfile returns err as the error of an attempt that ran with the
+// client settings of profile. A nil err or empty profile is returned as is.
+func WithClientProfile(err error, profile string) error {
+	if err == nil || profile == "" {
+		return err
+	}
+	return &ClientProfileError{Profile: profile, Err: err}
+}
 
 // Downloader defines the interface for platform-specific downloaders
 type Downloader interface {
-	// Download downloads media from the given URL.
+	// Download downloads media from the given URL and returns what it
+	// produced; DownloadManager applies the result to the download and saves
+	// it. The download is only read. The client settings of the attempt are
+	// returned in the result, or in a ClientProfileError on failure.
 	// ctx is cancelled when the download is cancelled; the implementation must
-	// use exec.CommandContext so the subprocess is killed immediately.
-	Download(ctx context.Context, download *Download, progressCallback DownloadProgressCallback) error
+	// terminate its subprocess and remove any partial files before returning.
+	Download(ctx context.Context, download *Download, progressCallback DownloadProgressCallback) (*DownloadResult, error)
 
 	// Platform returns the platform this downloader handles
 	Platform() Platform
@@ -19,9 +110,68 @@ type Downloader interface {
 	Validate(url string) error
 }
 
-// DownloadResult represents the result of a download operation
+// MetadataResolver is implemented by downloaders that can fetch the metadata
+// of a completed download again without downloading its media (metadata
+// backfill)
+type MetadataResolver interface {
+	// ResolveMetadata asks the download's tool for the metadata of its post
+	// (yt-dlp or gallery-dl JSON dump, tdl chat export). files are the
+	// completed files the metadata describes.
+	ResolveMetadata(ctx context.Context, download *Download, files []string) (*MediaMetadata, error)
+}
+
+// DownloadResult is what a Downloader produced for a download
 type DownloadResult struct {
-	FilePath string
-	Metadata map[string]interface{}
-	Error    error
+	Files         []string               // Completed files, the main one (FilePath) first
+	Metadata      map[string]interface{} // Metadata of the download (nil = keep the current metadata)
+	Items         []DownloadItem         // Files with their source message or tweet, and failed ones (nil = keep the current items, or one per file)
+	ItemCount     int                    // Number of tweets of a profile, as reported by the tool (0 = unknown)
+	Bytes         int64                  // Total size of Files (measured by DownloadManager when 0)
+	Duration      time.Duration          // Time the download took (measured by DownloadManager when 0)
+	ClientProfile string                 // Client settings of the attempt (impersonation, user agent, proxy; "" = keep the current ones)
+}
+
+// Download backends, the extraction strategies fallback chains are made of
+// (download.fallback_chains)
+const (
+	BackendYTDLP     = "ytdlp"      // yt-dlp
+	BackendGalleryDL = "gallery-dl" // gallery-dl
+	BackendXAPI      = "x-api"      // The native X API extractor (twitter.native_extractor): photos and the best MP4 of each video
+	BackendTDL       = "tdl"        // tdl
+)
+
+// PlatformBackends lists the backends able to download each platform's URLs
+var PlatformBackends = map[Platform][]string{
+	PlatformX:         {BackendYTDLP, BackendGalleryDL, BackendXAPI},
+	PlatformTelegram:  {BackendTDL},
+	PlatformInstagram: {BackendGalleryDL},
+	PlatformGallery:   {BackendGalleryDL},
+}
+
+// Backend is a downloader that uses a single backend, one link of a fallback
+// chain
+type Backend struct {
+	Name       string
+	Downloader Downloader
+}
+
+// FallbackChains are the backends tried in order for each platform's
+// downloads (download.fallback_chains)
+type FallbackChains map[Platform][]Backend
+
+// BackendFailure is a backend of a fallback chain that failed a download
+// before another one was tried
+type BackendFailure struct {
+	Backend string `json:"backend"`
+	Error   string `json:"error"`
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Built-in metadata enrichers (metadata.enrichers)
+const (
+	EnricherFFprobe  = "ffprobe"  // Duration, resolution, codecs and bit rate of each video
+	EnricherLanguage = "language" // Language of the post text, when the metadata has none
+	EnricherSHA256   = "sha256"   // SHA-256 checksum of each file
+)
+
+// MetadataEnrichers lists the built-in metadata enrichers
+var MetadataEnrichers = []string{EnricherFFprobe, EnricherLanguage, EnricherSHA256}
+
+// MetadataEnricher computes metadata of a completed file after its download,
+// apart from the downloaders' own metadata. Built-in enrichers and
+// metadata.exec_enrichers commands implement it.
+type MetadataEnricher interface {
+	// Name identifies the enricher in logs and errors
+	Name() string
+
+	// Enrich returns the fields to add to the metadata of file, or nil when
+	// the enricher does not apply to it. metadata is the file's .info.json
+	// (or the download's metadata when it has none) and must not be modified.
+	Enrich(ctx context.Context, file string, metadata map[string]interface{}) (map[string]interface{}, error)
+}
+
+// EnrichmentError records an enricher that failed on a file. They are saved
+// in the download's metadata under "enrich_errors".
+type EnrichmentError struct {
+	Enricher string `json:"enricher"`
+	File     string `json:"file"`
+	Error    string `json:"error"`
+}
+
+// ValidateEnrichers checks metadata.enrichers and metadata.exec_enrichers:
+// known built-ins, and commands named unlike any built-in
+func (c *MetadataConfig) ValidateEnrichers() error {
+	for _, name := range c.Enrichers {
+		if !isBuiltinEnricher(name) {
+			return fmt.Errorf("invalid metadata.enrichers: unknown enricher %q (supported: %s)",
+				name, strings.Join(MetadataEnrichers, ", "))
+		}
+	}
+	for name, command := range c.ExecEnrichers {
+		if isBuiltinEnricher(name) {
+			return fmt.Errorf("metadata.exec_enrichers cannot name an enricher %q: it is built in", name)
+		}
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("metadata.exec_enrichers.%s has no command", name)
+		}
+	}
+	if c.EnricherTimeout < 0 {
+		return fmt.Errorf("invalid metadata.enricher_timeout: %s", c.EnricherTimeout)
+	}
+	return nil
+}
+
+// isBuiltinEnricher reports whether name is a built-in metadata enricher
+func isBuiltinEnricher(name string) bool {
+	for _, builtin := range MetadataEnrichers {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
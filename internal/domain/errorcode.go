@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode classifies why a download failed (Download.ErrorCode). Empty means
+// the failure was not recognised.
+type ErrorCode string
+
+const (
+	ErrorAuthExpired ErrorCode = "auth_expired" // Cookies or session rejected, expired or not allowed to see the post
+	ErrorNotFound    ErrorCode = "not_found"    // Post, media or channel deleted or never existed
+	ErrorRateLimited ErrorCode = "rate_limited" // The platform rate limited the requests (HTTP 429)
+	ErrorNetwork     ErrorCode = "network"      // Connection failed, reset or timed out
+	ErrorDiskFull    ErrorCode = "disk_full"    // No room for the files
+	ErrorToolMissing ErrorCode = "tool_missing" // The external tool (yt-dlp, tdl, gallery-dl) is not installed
+	ErrorParse       ErrorCode = "parse_error"  // The tool could not make sense of the page or its own output
+)
+
+// ErrorCodes lists the error codes
+var ErrorCodes = []ErrorCode{ErrorAuthExpired, ErrorNotFound, ErrorRateLimited, ErrorNetwork, ErrorDiskFull, ErrorToolMissing, ErrorParse}
+
+// ParseErrorCode validates an error code given by a user
+func ParseErrorCode(s string) (ErrorCode, error) {
+	for _, code := range ErrorCodes {
+		if string(code) == s {
+			return code, nil
+		}
+	}
+	names := make([]string, len(ErrorCodes))
+	for i, code := range ErrorCodes {
+		names[i] = string(code)
+	}
+	return "", fmt.Errorf("invalid error code %q (supported: %s)", s, strings.Join(names, ", "))
+}
+
+// Retryable reports whether another attempt may succeed. Failures that need
+// the user to act (new cookies, installing a tool, freeing disk space) or that
+// will not change (deleted posts, unsupported pages) are not retried.
+// Unrecognised failures are.
+func (c ErrorCode) Retryable() bool {
+	switch c {
+	case ErrorAuthExpired, ErrorNotFound, ErrorDiskFull, ErrorToolMissing, ErrorParse:
+		return false
+	}
+	return true
+}
+
+// IsExtractorError reports whether a download failed because its backend
+// could not extract the media (parse_error) or is not installed
+// (tool_missing), so another backend of a fallback chain may succeed
+func IsExtractorError(err error) bool {
+	switch ErrorCodeOf(err) {
+	case ErrorParse, ErrorToolMissing:
+		return true
+	}
+	return false
+}
+
+// ErrorCodeOf returns the error code of a download failure: rate limit and
+// disk space errors, else the code a downloader set on its ToolError
+func ErrorCodeOf(err error) ErrorCode {
+	var rateLimit *RateLimitError
+	if errors.As(err, &rateLimit) {
+		return ErrorRateLimited
+	}
+	var diskErr *DiskSpaceError
+	if errors.As(err, &diskErr) {
+		return ErrorDiskFull
+	}
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Code
+	}
+	return ""
+}
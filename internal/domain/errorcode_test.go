@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	toolErr := &ToolError{Tool: "tdl", Err: errors.New("exit status 1"), Code: ErrorAuthExpired}
+
+	assert.Equal(t, ErrorAuthExpired, ErrorCodeOf(toolErr))
+	assert.Equal(t, ErrorAuthExpired, ErrorCodeOf(fmt.Errorf("download: %w", toolErr)))
+	assert.Equal(t, ErrorRateLimited, ErrorCodeOf(&RateLimitError{Err: &ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1")}}))
+	assert.Equal(t, ErrorDiskFull, ErrorCodeOf(&DiskSpaceError{Reason: "100 MiB free"}))
+	assert.Empty(t, ErrorCodeOf(&ToolError{Tool: "yt-dlp", Err: errors.New("exit status 1")}))
+	assert.Empty(t, ErrorCodeOf(errors.New("boom")))
+}
+
+func TestErrorCode_Retryable(t *testing.T) {
+	for _, code := range []ErrorCode{"", ErrorRateLimited, ErrorNetwork} {
+		assert.True(t, code.Retryable(), code)
+	}
+	for _, code := range []ErrorCode{ErrorAuthExpired, ErrorNotFound, ErrorDiskFull, ErrorToolMissing, ErrorParse} {
+		assert.False(t, code.Retryable(), code)
+	}
+}
+
+func TestParseErrorCode(t *testing.T) {
+	code, err := ParseErrorCode("not_found")
+	require.NoError(t, err)
+	assert.Equal(t, ErrorNotFound, code)
+
+	_, err = ParseErrorCode("missing")
+	assert.ErrorContains(t, err, "auth_expired")
+}
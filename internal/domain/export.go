@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats of the downloads export (x-extract export)
+const (
+	ExportCSV   = "csv"   // One row per download, with a header row
+	ExportJSONL = "jsonl" // One JSON object per line
+)
+
+// ValidateExportFormat checks an export format. Empty means CSV.
+func ValidateExportFormat(format string) error {
+	switch format {
+	case "", ExportCSV, ExportJSONL:
+		return nil
+	default:
+		return fmt.Errorf("invalid export format %q (supported: %s, %s)", format, ExportCSV, ExportJSONL)
+	}
+}
+
+// ExportListSeparator joins the tags and files of a download in a CSV cell
+const ExportListSeparator = "|"
+
+// ExportColumns are the CSV columns of an export, in the order of ExportRecord
+var ExportColumns = []string{
+	"id", "url", "platform", "mode", "status", "source",
+	"title", "uploader", "uploader_id", "upload_date", "webpage_url", "language",
+	"description", "tags", "file_path", "files", "file_count", "file_size",
+	"media_duration", "error_code", "error_message", "created_at", "completed_at",
+}
+
+// ExportRecord is a download flattened for spreadsheets and other catalogs:
+// the promoted metadata columns plus the description and tags of the metadata
+type ExportRecord struct {
+	ID            string         `json:"id"`
+	URL           string         `json:"url"`
+	Platform      Platform       `json:"platform"`
+	Mode          DownloadMode   `json:"mode"`
+	Status        DownloadStatus `json:"status"`
+	Source        DownloadSource `json:"source"`
+	Title         string         `json:"title"`
+	Uploader      string         `json:"uploader"`
+	UploaderID    string         `json:"uploader_id"`
+	UploadDate    string         `json:"upload_date"` // YYYYMMDD
+	WebpageURL    string         `json:"webpage_url"`
+	Language      string         `json:"language"`
+	Description   string         `json:"description"`
+	Tags          []string       `json:"tags"`
+	FilePath      string         `json:"file_path"`
+	Files         []string       `json:"files"`
+	FileCount     int            `json:"file_count"`
+	FileSize      int64          `json:"file_size"`
+	MediaDuration float64        `json:"media_duration"` // Seconds
+	ErrorCode     ErrorCode      `json:"error_code"`
+	ErrorMessage  string         `json:"error_message"`
+	CreatedAt     time.Time      `json:"created_at"`
+	CompletedAt   *time.Time     `json:"completed_at"`
+}
+
+// NewExportRecord flattens a download
+func NewExportRecord(d *Download) ExportRecord {
+	record := ExportRecord{
+		ID:            d.ID,
+		URL:           d.URL,
+		Platform:      d.Platform,
+		Mode:          d.Mode,
+		Status:        d.Status,
+		Source:        d.Source,
+		Title:         d.Title,
+		Uploader:      d.Uploader,
+		UploaderID:    d.UploaderID,
+		UploadDate:    d.UploadDate,
+		WebpageURL:    d.WebpageURL,
+		Language:      d.Language,
+		Tags:          []string{},
+		FilePath:      d.FilePath,
+		Files:         d.Files(),
+		FileSize:      d.FileSize,
+		MediaDuration: d.MediaDuration,
+		ErrorCode:     d.ErrorCode,
+		ErrorMessage:  d.ErrorMessage,
+		CreatedAt:     d.CreatedAt,
+		CompletedAt:   d.CompletedAt,
+	}
+	if record.Files == nil {
+		record.Files = []string{}
+	}
+	record.FileCount = len(record.Files)
+
+	var meta struct {
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+	}
+	if d.Metadata != "" && json.Unmarshal([]byte(d.Metadata), &meta) == nil {
+		record.Description = meta.Description
+		if meta.Tags != nil {
+			record.Tags = meta.Tags
+		}
+	}
+	return record
+}
+
+// CSVRow returns the record's cells in ExportColumns order. Lists are joined
+// with ExportListSeparator and times are RFC 3339.
+func (r ExportRecord) CSVRow() []string {
+	completedAt := ""
+	if r.CompletedAt != nil {
+		completedAt = r.CompletedAt.Format(time.RFC3339)
+	}
+	return []string{
+		r.ID, r.URL, string(r.Platform), string(r.Mode), string(r.Status), string(r.Source),
+		r.Title, r.Uploader, r.UploaderID, r.UploadDate, r.WebpageURL, r.Language,
+		r.Description, strings.Join(r.Tags, ExportListSeparator), r.FilePath,
+		strings.Join(r.Files, ExportListSeparator), strconv.Itoa(r.FileCount), strconv.FormatInt(r.FileSize, 10),
+		strconv.FormatFloat(r.MediaDuration, 'f', -1, 64), string(r.ErrorCode), r.ErrorMessage, r.CreatedAt.Format(time.RFC3339), completedAt,
+	}
+}
+
+// ExportWriter writes downloads to w in an export format
+type ExportWriter struct {
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+}
+
+// NewExportWriter returns a writer of format (empty = CSV). A CSV export
+// starts with the header row.
+func NewExportWriter(w io.Writer, format string) (*ExportWriter, error) {
+	if err := ValidateExportFormat(format); err != nil {
+		return nil, err
+	}
+	if format == ExportJSONL {
+		return &ExportWriter{format: format, json: json.NewEncoder(w)}, nil
+	}
+	writer := &ExportWriter{format: ExportCSV, csv: csv.NewWriter(w)}
+	if err := writer.csv.Write(ExportColumns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes one download
+func (w *ExportWriter) Write(d *Download) error {
+	record := NewExportRecord(d)
+	if w.json != nil {
+		return w.json.Encode(record)
+	}
+	return w.csv.Write(record.CSVRow())
+}
+
+// Flush writes any buffered rows
+func (w *ExportWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
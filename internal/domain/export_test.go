@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestDownload() *Download {
+	completedAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	d := NewDownload("https://x.com/alice/status/1", PlatformX, ModeDefault)
+	d.Status = StatusCompleted
+	d.CreatedAt = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	d.CompletedAt = &completedAt
+	d.Title = "A post, with a comma"
+	d.Uploader = "Alice"
+	d.UploadDate = "20240301"
+	d.FilePath = "/completed/a.mp4"
+	d.FileSize = 1024
+	d.MediaDuration = 12.5
+	d.Metadata = `{"description":"line one\nline two","tags":["cats","dogs"],"files":["/completed/a.mp4","/completed/b.jpg"]}`
+	return d
+}
+
+func TestNewExportRecord(t *testing.T) {
+	record := NewExportRecord(exportTestDownload())
+	assert.Equal(t, "A post, with a comma", record.Title)
+	assert.Equal(t, "line one\nline two", record.Description)
+	assert.Equal(t, []string{"cats", "dogs"}, record.Tags)
+	assert.Equal(t, []string{"/completed/a.mp4", "/completed/b.jpg"}, record.Files)
+	assert.Equal(t, 2, record.FileCount)
+
+	empty := NewExportRecord(NewDownload("https://t.me/news/1", PlatformTelegram, ModeDefault))
+	assert.Equal(t, []string{}, empty.Tags)
+	assert.Equal(t, []string{}, empty.Files)
+	assert.Len(t, empty.CSVRow(), len(ExportColumns))
+}
+
+func TestExportWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewExportWriter(&buf, "")
+	require.NoError(t, err)
+	require.NoError(t, w.Write(exportTestDownload()))
+	require.NoError(t, w.Flush())
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, ExportColumns, rows[0])
+	row := map[string]string{}
+	for i, column := range rows[0] {
+		row[column] = rows[1][i]
+	}
+	assert.Equal(t, "A post, with a comma", row["title"])
+	assert.Equal(t, "cats|dogs", row["tags"])
+	assert.Equal(t, "/completed/a.mp4|/completed/b.jpg", row["files"])
+	assert.Equal(t, "1024", row["file_size"])
+	assert.Equal(t, "12.5", row["media_duration"])
+	assert.Equal(t, "2024-03-02T10:00:00Z", row["completed_at"])
+}
+
+func TestExportWriter_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewExportWriter(&buf, ExportJSONL)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(exportTestDownload()))
+	require.NoError(t, w.Write(NewDownload("https://t.me/news/1", PlatformTelegram, ModeDefault)))
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "Alice", record["uploader"])
+	assert.Equal(t, []interface{}{"cats", "dogs"}, record["tags"])
+}
+
+func TestValidateExportFormat(t *testing.T) {
+	assert.NoError(t, ValidateExportFormat(""))
+	assert.NoError(t, ValidateExportFormat(ExportJSONL))
+	assert.Error(t, ValidateExportFormat("xlsx"))
+	_, err := NewExportWriter(&bytes.Buffer{}, "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// FailedFilter selects failed downloads to list or retry in bulk. Empty
+// fields match every failed download. The age of a failure is measured from
+// the download's last update, when it was marked failed.
+type FailedFilter struct {
+	Platform  Platform
+	ErrorCode ErrorCode
+	OlderThan time.Duration // Failed at least this long ago
+	NewerThan time.Duration // Failed at most this long ago
+}
+
+// Validate checks the platform and age range of the filter
+func (f FailedFilter) Validate() error {
+	if f.Platform != "" && !ValidatePlatform(f.Platform) {
+		return fmt.Errorf("invalid platform: %s", f.Platform)
+	}
+	if f.OlderThan > 0 && f.NewerThan > 0 && f.OlderThan >= f.NewerThan {
+		return fmt.Errorf("invalid age range: older_than must be less than newer_than")
+	}
+	return nil
+}
+
+// Filters returns the repository column filters of f (see
+// DownloadRepository.FindAll); the age range is checked with Matches
+func (f FailedFilter) Filters() map[string]interface{} {
+	filters := map[string]interface{}{"status": StatusFailed}
+	if f.Platform != "" {
+		filters["platform"] = f.Platform
+	}
+	if f.ErrorCode != "" {
+		filters["error_code"] = f.ErrorCode
+	}
+	return filters
+}
+
+// Matches reports whether download is a failed download selected by f at now
+func (f FailedFilter) Matches(download *Download, now time.Time) bool {
+	if download.Status != StatusFailed {
+		return false
+	}
+	if f.Platform != "" && download.Platform != f.Platform {
+		return false
+	}
+	if f.ErrorCode != "" && download.ErrorCode != f.ErrorCode {
+		return false
+	}
+	age := now.Sub(download.UpdatedAt)
+	if f.OlderThan > 0 && age < f.OlderThan {
+		return false
+	}
+	if f.NewerThan > 0 && age > f.NewerThan {
+		return false
+	}
+	return true
+}
+
+// FailedGroup is the failed downloads sharing an error code. Failures that
+// were not classified have an empty code.
+type FailedGroup struct {
+	ErrorCode ErrorCode   `json:"error_code"`
+	Retryable bool        `json:"retryable"` // Whether the download manager retries this kind of failure by itself
+	Count     int         `json:"count"`
+	Downloads []*Download `json:"downloads"`
+}
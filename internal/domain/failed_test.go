@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailedFilter_Validate(t *testing.T) {
+	assert.NoError(t, FailedFilter{}.Validate())
+	assert.NoError(t, FailedFilter{Platform: PlatformX, OlderThan: time.Hour, NewerThan: 24 * time.Hour}.Validate())
+	assert.Error(t, FailedFilter{Platform: "myspace"}.Validate())
+	assert.Error(t, FailedFilter{OlderThan: 24 * time.Hour, NewerThan: time.Hour}.Validate())
+}
+
+func TestFailedFilter_Matches(t *testing.T) {
+	now := time.Now()
+	download := NewDownload("https://t.me/c/1/2", PlatformTelegram, ModeDefault)
+	download.Status = StatusFailed
+	download.ErrorCode = ErrorNetwork
+	download.UpdatedAt = now.Add(-2 * time.Hour)
+
+	assert.True(t, FailedFilter{}.Matches(download, now))
+	assert.True(t, FailedFilter{Platform: PlatformTelegram, ErrorCode: ErrorNetwork}.Matches(download, now))
+	assert.False(t, FailedFilter{Platform: PlatformX}.Matches(download, now))
+	assert.False(t, FailedFilter{ErrorCode: ErrorRateLimited}.Matches(download, now))
+	assert.True(t, FailedFilter{OlderThan: time.Hour, NewerThan: 3 * time.Hour}.Matches(download, now))
+	assert.False(t, FailedFilter{OlderThan: 3 * time.Hour}.Matches(download, now))
+	assert.False(t, FailedFilter{NewerThan: time.Hour}.Matches(download, now))
+
+	download.Status = StatusCancelled
+	assert.False(t, FailedFilter{}.Matches(download, now), "only failed downloads")
+}
+
+func TestFailedFilter_Filters(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{"status": StatusFailed}, FailedFilter{}.Filters())
+	assert.Equal(t, map[string]interface{}{"status": StatusFailed, "platform": PlatformX, "error_code": ErrorRateLimited},
+		FailedFilter{Platform: PlatformX, ErrorCode: ErrorRateLimited, OlderThan: time.Hour}.Filters())
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FilenameTemplateTokens are the placeholders supported in filename templates
+var FilenameTemplateTokens = []string{
+	"{uploader}",    // Uploader display name
+	"{uploader_id}", // Uploader handle or channel ID
+	"{id}",          // Tweet or message ID
+	"{date}",        // Upload date (YYYYMMDD)
+	"{title}",       // Post title, truncated
+	"{platform}",    // Platform name
+	"{index}",       // 1-based position of the file within the download
+	"{original}",    // Name chosen by the download tool, without extension
+}
+
+// filenameTitleMaxRunes bounds {title} so long post texts don't produce
+// names beyond filesystem limits
+const filenameTitleMaxRunes = 80
+
+var filenameTemplateTokenRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+// ValidateFilenameTemplate checks that a template only uses known tokens.
+// An empty template is valid and keeps the tool's own file names.
+func ValidateFilenameTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if strings.ContainsAny(template, `/\`) {
+		return fmt.Errorf("filename template must not contain path separators: %s", template)
+	}
+	for _, token := range filenameTemplateTokenRe.FindAllString(template, -1) {
+		if !isFilenameTemplateToken(token) {
+			return fmt.Errorf("unknown filename template token %s (supported: %s)",
+				token, strings.Join(FilenameTemplateTokens, ", "))
+		}
+	}
+	return nil
+}
+
+func isFilenameTemplateToken(token string) bool {
+	for _, t := range FilenameTemplateTokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderFilenameTemplate expands template for one file of a download, without
+// extension. original is the tool-chosen name without extension; index is the
+// 1-based file position. Missing values render as "NA" (as yt-dlp does) and
+// path separators inside values are replaced so the result is a single name.
+func RenderFilenameTemplate(template string, meta *MediaMetadata, index int, original string) string {
+	values := map[string]string{
+		"{uploader}":    meta.Uploader,
+		"{uploader_id}": meta.UploaderID,
+		"{id}":          meta.ID,
+		"{date}":        meta.UploadDate,
+		"{title}":       truncateRunes(meta.Title, filenameTitleMaxRunes),
+		"{platform}":    meta.Platform,
+		"{index}":       fmt.Sprint(index),
+		"{original}":    original,
+	}
+	return filenameTemplateTokenRe.ReplaceAllStringFunc(template, func(token string) string {
+		value, ok := values[token]
+		if !ok {
+			return token
+		}
+		value = strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-", "\n", " ", "\r", " ").Replace(value))
+		if value == "" {
+			return "NA"
+		}
+		return value
+	})
+}
+
+// UsesFileIndex reports whether template distinguishes the files of a
+// multi-file download on its own (via {index} or {original})
+func UsesFileIndex(template string) bool {
+	return strings.Contains(template, "{index}") || strings.Contains(template, "{original}")
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFilenameTemplate(t *testing.T) {
+	assert.NoError(t, ValidateFilenameTemplate(""))
+	assert.NoError(t, ValidateFilenameTemplate("{uploader}_{date}_{id}"))
+	assert.NoError(t, ValidateFilenameTemplate("{original} - {title}"))
+	assert.Error(t, ValidateFilenameTemplate("{uploader}_{nope}"))
+	assert.Error(t, ValidateFilenameTemplate("{uploader}/{id}"))
+}
+
+func TestRenderFilenameTemplate(t *testing.T) {
+	meta := &MediaMetadata{
+		ID:         "123",
+		Uploader:   "Some / User",
+		UploaderID: "someuser",
+		UploadDate: "20240102",
+		Title:      strings.Repeat("a", 100),
+		Platform:   "x",
+	}
+
+	assert.Equal(t, "Some - User_20240102_123", RenderFilenameTemplate("{uploader}_{date}_{id}", meta, 1, "orig"))
+	assert.Equal(t, "x_orig_2", RenderFilenameTemplate("{platform}_{original}_{index}", meta, 2, "orig"))
+	assert.Len(t, RenderFilenameTemplate("{title}", meta, 1, ""), filenameTitleMaxRunes)
+	assert.Equal(t, "NA_123", RenderFilenameTemplate("{uploader}_{id}", &MediaMetadata{ID: "123"}, 1, ""))
+}
+
+func TestDownloadConfig_FilenameTemplateFor(t *testing.T) {
+	config := DownloadConfig{
+		FilenameTemplate:          "{id}",
+		FilenameTemplateOverrides: map[string]string{"telegram": "{original}"},
+	}
+	assert.Equal(t, "{id}", config.FilenameTemplateFor(PlatformX))
+	assert.Equal(t, "{original}", config.FilenameTemplateFor(PlatformTelegram))
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// KeepAliveStatus reports what keeps the server from auto-exiting on an empty
+// queue (GET/POST/DELETE /api/v1/server/keepalive)
+type KeepAliveStatus struct {
+	AutoExitOnEmpty  bool       `json:"auto_exit_on_empty"`
+	Held             bool       `json:"held"`                       // Kept alive until released
+	KeepAliveUntil   *time.Time `json:"keep_alive_until,omitempty"` // Kept alive until this time
+	Inhibited        bool       `json:"inhibited"`                  // Kept alive by enabled schedules
+	ClientsConnected bool       `json:"clients_connected"`          // A dashboard or API client is connected (queue.defer_exit_for_clients)
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minLanguageLetters is the fewest letters of a script DetectLanguage decides on
+const minLanguageLetters = 2
+
+// latinStopwords are common words telling apart languages written in the
+// Latin script
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "of", "to", "in", "for", "this", "that", "with", "on", "you", "it", "was", "have", "not", "be", "at", "my", "what", "new"},
+	"es": {"el", "la", "los", "las", "de", "que", "y", "en", "un", "una", "por", "con", "para", "es", "del", "se", "no", "lo", "más", "pero"},
+	"fr": {"le", "la", "les", "de", "des", "et", "est", "un", "une", "pour", "que", "dans", "en", "du", "pas", "sur", "qui", "avec", "ce", "je"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "mit", "auf", "für", "den", "von", "zu", "ich", "sie", "es", "auch", "dem", "sich"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "é", "por", "mais", "no", "na"},
+	"it": {"il", "la", "di", "che", "e", "è", "un", "una", "per", "non", "con", "del", "della", "sono", "ma", "le", "gli", "si", "anche", "questo"},
+}
+
+// latinStopwordIndex maps each stopword to the languages it belongs to
+var latinStopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range latinStopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// DetectLanguage guesses the ISO 639-1 code of the language of a post text
+// from its script, and for Latin-script text from common words: zh, ja, ko,
+// ru, uk, ar, fa, he, th, el, hi, en, es, fr, de, pt or it. Links, @mentions
+// and #hashtags are ignored. Returns "" when the text is too short or the
+// guess too close to call.
+func DetectLanguage(text string) string {
+	var words []string
+	counts := make(map[string]int)
+	for _, word := range strings.Fields(text) {
+		lower := strings.ToLower(word)
+		if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+			strings.HasPrefix(word, "@") || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, lower)
+		for _, r := range word {
+			if script := runeScript(r); script != "" {
+				counts[script]++
+			}
+			switch {
+			case strings.ContainsRune(ukrainianLetters, r):
+				counts["ukrainian"]++
+			case strings.ContainsRune(persianLetters, r):
+				counts["persian"]++
+			}
+		}
+	}
+
+	// Japanese mixes kana with kanji; Chinese never uses kana
+	if counts["kana"] > 0 && counts["kana"]*10 >= counts["kana"]+counts["han"] {
+		counts["kana"] += counts["han"]
+		counts["han"] = 0
+	}
+
+	script, best := "", 0
+	for _, s := range scripts {
+		if counts[s] > best {
+			script, best = s, counts[s]
+		}
+	}
+	if best < minLanguageLetters {
+		return ""
+	}
+	switch script {
+	case "han":
+		return "zh"
+	case "kana":
+		return "ja"
+	case "hangul":
+		return "ko"
+	case "cyrillic":
+		if counts["ukrainian"] > 0 {
+			return "uk"
+		}
+		return "ru"
+	case "arabic":
+		if counts["persian"] > 0 {
+			return "fa"
+		}
+		return "ar"
+	case "hebrew":
+		return "he"
+	case "thai":
+		return "th"
+	case "greek":
+		return "el"
+	case "devanagari":
+		return "hi"
+	case "latin":
+		return detectLatinLanguage(words)
+	}
+	return ""
+}
+
+// scripts are the scripts runeScript tells apart
+var scripts = []string{"han", "kana", "hangul", "cyrillic", "arabic", "hebrew", "thai", "greek", "devanagari", "latin"}
+
+// Letters telling Ukrainian from Russian and Persian from Arabic
+const (
+	ukrainianLetters = "іїєґІЇЄҐ"
+	persianLetters   = "پچژگ"
+)
+
+// runeScript returns the script of a letter, or "" for anything else
+func runeScript(r rune) string {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return "han"
+	case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+		return "kana"
+	case unicode.Is(unicode.Hangul, r):
+		return "hangul"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "cyrillic"
+	case unicode.Is(unicode.Arabic, r):
+		return "arabic"
+	case unicode.Is(unicode.Hebrew, r):
+		return "hebrew"
+	case unicode.Is(unicode.Thai, r):
+		return "thai"
+	case unicode.Is(unicode.Greek, r):
+		return "greek"
+	case unicode.Is(unicode.Devanagari, r):
+		return "devanagari"
+	case unicode.Is(unicode.Latin, r):
+		return "latin"
+	}
+	return ""
+}
+
+// detectLatinLanguage picks the language with the most stopwords among words,
+// needing at least two and a clear lead
+func detectLatinLanguage(words []string) string {
+	scores := make(map[string]int)
+	for _, word := range words {
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) })
+		for _, lang := range latinStopwordIndex[word] {
+			scores[lang]++
+		}
+	}
+	best, bestScore, runnerUp := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = lang, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < 2 || bestScore == runnerUp {
+		return ""
+	}
+	return best
+}
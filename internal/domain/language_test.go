@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"今天天气很好，我们去公园散步吧", "zh"},
+		{"今日はいい天気ですね。散歩に行きましょう", "ja"},
+		{"오늘 날씨가 정말 좋네요", "ko"},
+		{"Сегодня отличная погода для прогулки", "ru"},
+		{"Сьогодні чудова погода, ідемо гуляти", "uk"},
+		{"الطقس جميل اليوم", "ar"},
+		{"امروز هوا خیلی خوب است، بیا برویم پارک", "fa"},
+		{"Καλημέρα σε όλους", "el"},
+		{"The new video is out and it is the best one yet", "en"},
+		{"Hoy es un día perfecto para ir a la playa con los amigos", "es"},
+		{"Le nouveau clip est sorti et il est pour vous", "fr"},
+		{"Das neue Video ist da und es ist nicht schlecht", "de"},
+		{"Novo vídeo no ar, não percam", "pt"},
+		{"Il nuovo video è online, non perdetelo e condividete con gli amici", "it"},
+
+		// Links, mentions and hashtags do not count
+		{"https://t.me/channel 新视频 @someone #tag", "zh"},
+		{"@someone #nofilter https://x.com/a/status/1", ""},
+
+		// Too short or too close to call
+		{"", ""},
+		{"ok", ""},
+		{"🔥🔥🔥", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DetectLanguage(tt.text), tt.text)
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Heavy background jobs that maintenance.window holds until it opens
+const (
+	MaintenanceChannelExports = "channel_exports" // Scheduled channel and account syncs (scheduler)
+	MaintenanceTranscodes     = "transcodes"      // Post-processing steps of completed downloads (postprocess)
+	MaintenanceRemoteSync     = "remote_sync"     // Uploads of completed files to a remote storage.backend
+	MaintenanceVacuum         = "vacuum"          // VACUUM of the database, once per window
+)
+
+// MaintenanceJobs lists the jobs maintenance.jobs may hold
+var MaintenanceJobs = []string{MaintenanceChannelExports, MaintenanceTranscodes, MaintenanceRemoteSync, MaintenanceVacuum}
+
+// DailyWindow is a range of the time of day in local time, written
+// "HH:MM-HH:MM". It spans midnight when it ends before it starts
+// ("22:00-06:00").
+type DailyWindow struct {
+	start, end int // Minutes after midnight
+}
+
+// ParseDailyWindow parses a "HH:MM-HH:MM" window
+func ParseDailyWindow(s string) (*DailyWindow, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid window %q: expected HH:MM-HH:MM", s)
+	}
+	start, err := parseTimeOfDay(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	end, err := parseTimeOfDay(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("invalid window %q: start and end are the same", s)
+	}
+	return &DailyWindow{start: start, end: end}, nil
+}
+
+// parseTimeOfDay parses "HH:MM" into minutes after midnight
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", strings.TrimSpace(s))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// String returns the window as "HH:MM-HH:MM"
+func (w *DailyWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.start/60, w.start%60, w.end/60, w.end%60)
+}
+
+// Contains reports whether t is within the window
+func (w *DailyWindow) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return m >= w.start && m < w.end
+	}
+	return m >= w.start || m < w.end
+}
+
+// OpenedAt returns when the window containing t opened (zero when t is
+// outside the window)
+func (w *DailyWindow) OpenedAt(t time.Time) time.Time {
+	if !w.Contains(t) {
+		return time.Time{}
+	}
+	opened := w.startOn(t, 0)
+	if opened.After(t) {
+		// Past midnight in a window that opened the day before
+		opened = w.startOn(t, -1)
+	}
+	return opened
+}
+
+// NextOpen returns the next time after t the window opens
+func (w *DailyWindow) NextOpen(t time.Time) time.Time {
+	next := w.startOn(t, 0)
+	if !next.After(t) {
+		next = w.startOn(t, 1)
+	}
+	return next
+}
+
+// startOn returns the start of the window on the day days after t's
+func (w *DailyWindow) startOn(t time.Time, days int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, w.start/60, w.start%60, 0, 0, t.Location())
+}
+
+// MaintenanceStatus is the state of maintenance.window
+// (GET /api/v1/maintenance/window)
+type MaintenanceStatus struct {
+	Window       string     `json:"window"`                   // maintenance.window ("" = none: jobs run any time)
+	Open         bool       `json:"open"`                     // Whether the window is open now
+	Jobs         []string   `json:"jobs"`                     // Jobs held until the window opens
+	NextOpenAt   *time.Time `json:"next_open_at,omitempty"`   // When the window opens next, while it is closed
+	Deferred     int64      `json:"deferred"`                 // Completed downloads whose transcodes or uploads wait for the window
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`    // When held jobs of downloads last ran
+	LastVacuumAt *time.Time `json:"last_vacuum_at,omitempty"` // When the database was last vacuumed
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDailyWindow(t *testing.T) {
+	window, err := ParseDailyWindow(" 1:30-06:00 ")
+	require.NoError(t, err)
+	assert.Equal(t, "01:30-06:00", window.String())
+
+	for _, invalid := range []string{"", "01:00", "01:00-25:00", "1am-6am", "03:00-03:00"} {
+		_, err := ParseDailyWindow(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDailyWindow(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.Local)
+	}
+
+	night, err := ParseDailyWindow("01:00-06:00")
+	require.NoError(t, err)
+	assert.True(t, night.Contains(at(10, 1, 0)))
+	assert.True(t, night.Contains(at(10, 5, 59)))
+	assert.False(t, night.Contains(at(10, 6, 0)))
+	assert.False(t, night.Contains(at(10, 0, 59)))
+	assert.Equal(t, at(10, 1, 0), night.OpenedAt(at(10, 3, 0)))
+	assert.True(t, night.OpenedAt(at(10, 12, 0)).IsZero())
+	assert.Equal(t, at(11, 1, 0), night.NextOpen(at(10, 1, 0)))
+	assert.Equal(t, at(10, 1, 0), night.NextOpen(at(10, 0, 30)))
+
+	// Spanning midnight
+	late, err := ParseDailyWindow("22:00-06:00")
+	require.NoError(t, err)
+	assert.True(t, late.Contains(at(10, 23, 0)))
+	assert.True(t, late.Contains(at(10, 2, 0)))
+	assert.False(t, late.Contains(at(10, 12, 0)))
+	assert.Equal(t, at(9, 22, 0), late.OpenedAt(at(10, 2, 0)), "opened the day before")
+	assert.Equal(t, at(10, 22, 0), late.OpenedAt(at(10, 23, 0)))
+	assert.Equal(t, at(10, 22, 0), late.NextOpen(at(10, 2, 0)))
+}
+
+func TestMaintenanceConfig(t *testing.T) {
+	config := DefaultConfig().Maintenance
+	assert.NoError(t, config.Validate())
+	assert.Nil(t, config.DailyWindow())
+	assert.False(t, config.Holds(MaintenanceTranscodes), "nothing is held without a window")
+
+	config.Window = "01:00-06:00"
+	config.Jobs = []string{MaintenanceTranscodes}
+	assert.NoError(t, config.Validate())
+	assert.NotNil(t, config.DailyWindow())
+	assert.True(t, config.Holds(MaintenanceTranscodes))
+	assert.False(t, config.Holds(MaintenanceVacuum))
+
+	assert.Error(t, (&MaintenanceConfig{Window: "night"}).Validate())
+	assert.Error(t, (&MaintenanceConfig{Jobs: []string{"backups"}}).Validate())
+}
+
+func TestDownload_DeferJob(t *testing.T) {
+	d := NewDownload("https://x.com/alice/status/1", PlatformX, ModeDefault)
+	assert.Nil(t, d.DeferredJobs())
+
+	d.DeferJob(MaintenanceTranscodes)
+	d.DeferJob(MaintenanceRemoteSync)
+	d.DeferJob(MaintenanceTranscodes)
+	assert.Equal(t, "transcodes,remote_sync", d.Deferred)
+	assert.True(t, d.HasDeferred(MaintenanceRemoteSync))
+	assert.False(t, d.HasDeferred(MaintenanceVacuum))
+}
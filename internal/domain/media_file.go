@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Media types of downloaded files
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+	MediaTypeOther = "other"
+)
+
+var mediaTypesByExt = map[string]string{
+	".jpg":  MediaTypeImage,
+	".jpeg": MediaTypeImage,
+	".png":  MediaTypeImage,
+	".gif":  MediaTypeImage,
+	".webp": MediaTypeImage,
+	".mp4":  MediaTypeVideo,
+	".mkv":  MediaTypeVideo,
+	".avi":  MediaTypeVideo,
+	".mov":  MediaTypeVideo,
+	".webm": MediaTypeVideo,
+	".m4v":  MediaTypeVideo,
+	".ts":   MediaTypeVideo,
+}
+
+// MediaTypeOf returns the media type of a file by its extension
+func MediaTypeOf(path string) string {
+	if mediaType, ok := mediaTypesByExt[strings.ToLower(filepath.Ext(path))]; ok {
+		return mediaType
+	}
+	return MediaTypeOther
+}
+
+// DownloadFile is a file of a download as listed by
+// GET /api/v1/downloads/:id/files
+type DownloadFile struct {
+	Index     int    `json:"index"` // Position in Download.Files(), used in the content and thumbnail URLs
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	MediaType string `json:"media_type"` // image, video or other
+	Size      int64  `json:"size"`
+	Exists    bool   `json:"exists"`               // False when the file was moved or deleted
+	RemoteURL string `json:"remote_url,omitempty"` // Where the file was uploaded (storage.backend)
+}
+
+// Thumbnailer makes thumbnails of downloaded media files
+type Thumbnailer interface {
+	// Thumbnail returns the path of a JPEG thumbnail of the file at path,
+	// or an *UnsupportedThumbnailError when none can be made
+	Thumbnail(ctx context.Context, path string) (string, error)
+}
+
+// FileStore finds the completed files of downloads wherever storage.backend
+// keeps them
+type FileStore interface {
+	// LocalPath returns a local path with the content of file: file itself,
+	// or a cached copy fetched from the storage backend when the local copy
+	// was removed after upload. The error wraps os.ErrNotExist when the file
+	// is in neither place.
+	LocalPath(ctx context.Context, file string) (string, error)
+}
+
+// UnsupportedThumbnailError is returned when no thumbnail can be made of a
+// file: it is not an image or video, or making one needs a missing tool
+type UnsupportedThumbnailError struct {
+	Path   string
+	Reason string
+}
+
+// Error implements error
+func (e *UnsupportedThumbnailError) Error() string {
+	return fmt.Sprintf("no thumbnail for %s: %s", filepath.Base(e.Path), e.Reason)
+}
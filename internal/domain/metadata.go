@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"bytes"
+	"text/template"
+)
+
 // MediaMetadata is the unified metadata structure shared by all downloaders.
 // It captures common fields that map to Eagle App's API and yt-dlp's .info.json format.
 //
@@ -19,6 +24,7 @@ type MediaMetadata struct {
 	Uploader    string `json:"uploader"`
 	UploaderID  string `json:"uploader_id"`
 	UploaderURL string `json:"uploader_url"`
+	Channel     string `json:"channel,omitempty"` // Telegram channel name
 
 	// URLs
 	WebpageURL string `json:"webpage_url"`
@@ -33,10 +39,47 @@ type MediaMetadata struct {
 	Platform     string   `json:"platform"`
 	Extractor    string   `json:"extractor"`
 	ExtractorKey string   `json:"extractor_key"`
+	Language     string   `json:"language,omitempty"` // ISO 639-1 code of the post text, detected by ToMap when empty
+
+	// Post details (X); counts use yt-dlp's field names
+	AltTexts     []string `json:"alt_texts,omitempty"` // Image descriptions, in media order
+	LikeCount    int64    `json:"like_count,omitempty"`
+	RepostCount  int64    `json:"repost_count,omitempty"`
+	CommentCount int64    `json:"comment_count,omitempty"`
+	ViewCount    int64    `json:"view_count,omitempty"`
+	Poll         *Poll    `json:"poll,omitempty"`
 
 	// File info
-	Extension string   `json:"ext,omitempty"`
-	Files     []string `json:"files,omitempty"`
+	Extension    string         `json:"ext,omitempty"`
+	Files        []string       `json:"files,omitempty"`
+	RelativePath string         `json:"relative_path,omitempty"` // File path relative to completed/
+	Variants     []MediaVariant `json:"variants,omitempty"`      // Every rendition, when all variants were requested
+
+	// Extra holds user-configured fields (metadata.extra_fields) merged into ToMap output.
+	// Extra keys never override the core fields above.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Poll is a poll attached to a post
+type Poll struct {
+	Options []PollOption `json:"options"`
+	EndsAt  int64        `json:"ends_at,omitempty"` // Unix timestamp
+	Final   bool         `json:"final"`             // Voting has closed and counts are final
+}
+
+// PollOption is one choice of a Poll with its vote count
+type PollOption struct {
+	Label string `json:"label"`
+	Votes int64  `json:"votes"`
+}
+
+// MediaVariant labels one file of an all-variants download: which media item
+// of the post it is and which rendition (original image, video resolution or
+// format ID)
+type MediaVariant struct {
+	File  string `json:"file"`
+	Media int    `json:"media"` // 1-based position of the photo or video in the post
+	Label string `json:"label"` // e.g. "orig", "1280x720", "http-2176"
 }
 
 // EagleItem represents the metadata structure for importing into Eagle App.
@@ -86,22 +129,103 @@ func (m *MediaMetadata) ToMap() map[string]interface{} {
 		"platform": m.Platform,
 	}
 
+	if language := m.detectLanguage(); language != "" {
+		result["language"] = language
+	}
 	if m.Extension != "" {
 		result["ext"] = m.Extension
 	}
+	if m.Channel != "" {
+		result["channel"] = m.Channel
+	}
 	if len(m.Files) > 0 {
 		result["files"] = m.Files
 	}
+	if m.RelativePath != "" {
+		result["relative_path"] = m.RelativePath
+	}
+	if len(m.AltTexts) > 0 {
+		result["alt_texts"] = m.AltTexts
+	}
+	if m.Poll != nil {
+		result["poll"] = m.Poll
+	}
+	if len(m.Variants) > 0 {
+		result["variants"] = m.Variants
+	}
+	for key, count := range map[string]int64{
+		"like_count":    m.LikeCount,
+		"repost_count":  m.RepostCount,
+		"comment_count": m.CommentCount,
+		"view_count":    m.ViewCount,
+	} {
+		if count > 0 {
+			result[key] = count
+		}
+	}
+
+	for key, value := range m.Extra {
+		if _, exists := result[key]; !exists {
+			result[key] = value
+		}
+	}
 
 	return result
 }
 
+// detectLanguage returns Language, or the language of the description (the
+// post text), falling back to the title when there is no description
+func (m *MediaMetadata) detectLanguage() string {
+	if m.Language != "" {
+		return m.Language
+	}
+	if m.Description != "" {
+		return DetectLanguage(m.Description)
+	}
+	return DetectLanguage(m.Title)
+}
+
+// ApplyExtraFields renders user-configured extra fields into m.Extra.
+// Each value is a Go text/template evaluated against the MediaMetadata, so both
+// static values ("x-extract") and templated ones ("{{.Platform}}/{{.UploaderID}}")
+// are supported. Values whose template fails to parse or execute are kept verbatim.
+func (m *MediaMetadata) ApplyExtraFields(fields map[string]string) {
+	if len(fields) == 0 {
+		return
+	}
+	if m.Extra == nil {
+		m.Extra = make(map[string]interface{}, len(fields))
+	}
+	for key, value := range fields {
+		m.Extra[key] = renderExtraField(value, m)
+	}
+}
+
+// renderExtraField evaluates a single extra-field template against meta.
+func renderExtraField(value string, meta *MediaMetadata) string {
+	tmpl, err := template.New("extra").Option("missingkey=zero").Parse(value)
+	if err != nil {
+		return value
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, meta); err != nil {
+		return value
+	}
+	return buf.String()
+}
+
 // ToFileMap returns a map for per-file .info.json metadata.
 // It includes file-specific fields (ext, local_file, _type, epoch) alongside the common fields.
 func (m *MediaMetadata) ToFileMap(filePath, ext string) map[string]interface{} {
 	result := m.ToMap()
-	// Remove aggregate "files" from per-file metadata
+	// Remove aggregate "files" and "variants" from per-file metadata
 	delete(result, "files")
+	delete(result, "variants")
+	for _, variant := range m.Variants {
+		if variant.File == filePath {
+			result["variant"] = variant.Label
+		}
+	}
 	// Add per-file fields
 	result["ext"] = ext
 	result["local_file"] = filePath
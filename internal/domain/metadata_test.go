@@ -79,6 +79,21 @@ func TestMediaMetadata_ToMap_OmitsEmptyOptionals(t *testing.T) {
 	assert.False(t, hasFiles, "files should be omitted when Files is empty")
 }
 
+func TestMediaMetadata_ToMap_Language(t *testing.T) {
+	meta := &MediaMetadata{Title: "Channel - 新视频", Description: "今天的新视频已经上传了"}
+	assert.Equal(t, "zh", meta.ToMap()["language"])
+
+	// Without a description the title is used
+	meta = &MediaMetadata{Title: "Сегодня отличная погода"}
+	assert.Equal(t, "ru", meta.ToMap()["language"])
+
+	// A set language is kept; undetectable text has none
+	meta = &MediaMetadata{Description: "今日は", Language: "ja"}
+	assert.Equal(t, "ja", meta.ToMap()["language"])
+	_, hasLanguage := (&MediaMetadata{Description: "🔥"}).ToMap()["language"]
+	assert.False(t, hasLanguage)
+}
+
 func TestMediaMetadata_ToFileMap(t *testing.T) {
 	meta := newTestMetadata()
 	fm := meta.ToFileMap("/tmp/completed/file1.mp4", "mp4")
@@ -165,3 +180,54 @@ func TestMediaMetadata_ToMap_EmptyTags(t *testing.T) {
 	assert.Empty(t, tags)
 }
 
+
+func TestMediaMetadata_ApplyExtraFields(t *testing.T) {
+	meta := newTestMetadata()
+	meta.ApplyExtraFields(map[string]string{
+		"source":     "x-extract",
+		"collection": "{{.Platform}}-{{.UploaderID}}",
+		"broken":     "{{.Platform",
+		"title":      "should not override",
+	})
+
+	m := meta.ToMap()
+	assert.Equal(t, "x-extract", m["source"])
+	assert.Equal(t, "x-testuser", m["collection"])
+	assert.Equal(t, "{{.Platform", m["broken"])
+	assert.Equal(t, "Test Video Title", m["title"])
+
+	fileMap := meta.ToFileMap("/tmp/file.mp4", "mp4")
+	assert.Equal(t, "x-extract", fileMap["source"])
+}
+
+func TestMediaMetadata_ApplyExtraFields_Empty(t *testing.T) {
+	meta := newTestMetadata()
+	meta.ApplyExtraFields(nil)
+	assert.Nil(t, meta.Extra)
+}
+
+func TestMediaMetadata_ToMap_PostDetails(t *testing.T) {
+	meta := &MediaMetadata{ID: "1", AltTexts: []string{"A cat"}, LikeCount: 5, ViewCount: 100}
+	m := meta.ToMap()
+	assert.Equal(t, []string{"A cat"}, m["alt_texts"])
+	assert.Equal(t, int64(5), m["like_count"])
+	assert.Equal(t, int64(100), m["view_count"])
+	_, hasReposts := m["repost_count"]
+	assert.False(t, hasReposts, "zero counts should be omitted")
+}
+
+func TestMediaMetadata_ToFileMap_Variant(t *testing.T) {
+	meta := &MediaMetadata{
+		ID:    "6",
+		Files: []string{"/completed/a_orig.jpg", "/completed/b_1280x720.mp4"},
+		Variants: []MediaVariant{
+			{File: "/completed/a_orig.jpg", Media: 1, Label: "orig"},
+			{File: "/completed/b_1280x720.mp4", Media: 2, Label: "1280x720"},
+		},
+	}
+	assert.Len(t, meta.ToMap()["variants"], 2)
+
+	m := meta.ToFileMap("/completed/b_1280x720.mp4", "mp4")
+	assert.Equal(t, "1280x720", m["variant"])
+	assert.NotContains(t, m, "variants")
+}
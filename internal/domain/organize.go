@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Values for download.organize_by, which sorts completed files into
+// subdirectories of completed/
+const (
+	OrganizeFlat     = ""         // Everything directly in completed/
+	OrganizeUploader = "uploader" // completed/{uploader}/
+	OrganizeChannel  = "channel"  // completed/{channel_name}/ (X: the account handle)
+	OrganizeDate     = "date"     // completed/{YYYY-MM}/ by upload date
+	OrganizePlatform = "platform" // completed/{platform}/
+)
+
+// organizeUnknown names the subdirectory used when the organizing value is missing
+const organizeUnknown = "unknown"
+
+// ValidateOrganizeBy checks that organizeBy is a supported value
+func ValidateOrganizeBy(organizeBy string) error {
+	switch organizeBy {
+	case OrganizeFlat, OrganizeUploader, OrganizeChannel, OrganizeDate, OrganizePlatform:
+		return nil
+	}
+	return fmt.Errorf("invalid organize_by: %s (supported: uploader, channel, date, platform)", organizeBy)
+}
+
+// OrganizeSubdir returns the completed/ subdirectory for a download's files,
+// or "" for a flat layout. The result is a single path segment.
+func OrganizeSubdir(organizeBy string, meta *MediaMetadata) string {
+	var value string
+	switch organizeBy {
+	case OrganizeUploader:
+		value = meta.Uploader
+		if value == "" {
+			value = meta.UploaderID
+		}
+	case OrganizeChannel:
+		value = meta.Channel
+		if value == "" {
+			value = meta.UploaderID
+		}
+	case OrganizeDate:
+		if t, err := time.Parse("20060102", meta.UploadDate); err == nil {
+			value = t.Format("2006-01")
+		}
+	case OrganizePlatform:
+		value = meta.Platform
+	default:
+		return ""
+	}
+
+	value = strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-", "\n", " ", "\r", " ").Replace(value))
+	if value == "" || value == "." || value == ".." {
+		return organizeUnknown
+	}
+	return value
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOrganizeBy(t *testing.T) {
+	for _, value := range []string{"", "uploader", "channel", "date", "platform"} {
+		assert.NoError(t, ValidateOrganizeBy(value), value)
+	}
+	assert.Error(t, ValidateOrganizeBy("month"))
+}
+
+func TestOrganizeSubdir(t *testing.T) {
+	telegram := &MediaMetadata{Uploader: "News_Alice", UploaderID: "42", Channel: "News", UploadDate: "20240315", Platform: "telegram"}
+	x := &MediaMetadata{Uploader: "Some / User", UploaderID: "someuser", Platform: "x"}
+
+	assert.Equal(t, "", OrganizeSubdir(OrganizeFlat, telegram))
+	assert.Equal(t, "News_Alice", OrganizeSubdir(OrganizeUploader, telegram))
+	assert.Equal(t, "News", OrganizeSubdir(OrganizeChannel, telegram))
+	assert.Equal(t, "2024-03", OrganizeSubdir(OrganizeDate, telegram))
+	assert.Equal(t, "telegram", OrganizeSubdir(OrganizePlatform, telegram))
+
+	assert.Equal(t, "Some - User", OrganizeSubdir(OrganizeUploader, x), "path separators are replaced")
+	assert.Equal(t, "someuser", OrganizeSubdir(OrganizeChannel, x), "X falls back to the account handle")
+	assert.Equal(t, "unknown", OrganizeSubdir(OrganizeDate, x))
+}
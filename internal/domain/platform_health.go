@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+// Reasons a platform is degraded, in the order they are reported
+const (
+	DegradedCookieExpired    = "cookie_expired"    // Auth cookies expired or rejected
+	DegradedBinaryMissing    = "binary_missing"    // An external tool the platform needs is not installed
+	DegradedRepeatedFailures = "repeated_failures" // Downloads kept failing in the last hour
+)
+
+// DegradedReasons lists every degraded reason
+var DegradedReasons = []string{DegradedCookieExpired, DegradedBinaryMissing, DegradedRepeatedFailures}
+
+// RepeatedFailureWindow and RepeatedFailureThreshold define repeated failures:
+// at least the threshold of downloads of a platform failed for good within
+// the window
+const (
+	RepeatedFailureWindow    = time.Hour
+	RepeatedFailureThreshold = 3
+)
+
+// PlatformHealth tells whether downloads of a platform are likely to fail
+type PlatformHealth struct {
+	Platform        Platform    `json:"platform"`
+	Degraded        bool        `json:"degraded"`
+	Reasons         []string    `json:"reasons,omitempty"`          // See DegradedReasons
+	Cookie          CookieState `json:"cookie,omitempty"`           // Platforms with a cookie check only
+	MissingBinaries []string    `json:"missing_binaries,omitempty"` // Paths of the missing tools
+	RecentFailures  int         `json:"recent_failures"`            // Failed downloads within RepeatedFailureWindow
+}
+
+// NewPlatformHealth derives the degraded reasons of a platform from its
+// cookie state, missing tools and recent failures
+func NewPlatformHealth(platform Platform, cookie CookieState, missingBinaries []string, recentFailures int) PlatformHealth {
+	health := PlatformHealth{
+		Platform:        platform,
+		Cookie:          cookie,
+		MissingBinaries: missingBinaries,
+		RecentFailures:  recentFailures,
+	}
+	if cookie == CookieExpired || cookie == CookieInvalid {
+		health.Reasons = append(health.Reasons, DegradedCookieExpired)
+	}
+	if len(missingBinaries) > 0 {
+		health.Reasons = append(health.Reasons, DegradedBinaryMissing)
+	}
+	if recentFailures >= RepeatedFailureThreshold {
+		health.Reasons = append(health.Reasons, DegradedRepeatedFailures)
+	}
+	health.Degraded = len(health.Reasons) > 0
+	return health
+}
+
+// HasReason reports whether the platform is degraded for reason
+func (h PlatformHealth) HasReason(reason string) bool {
+	for _, r := range h.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
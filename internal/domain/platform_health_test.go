@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPlatformHealth(t *testing.T) {
+	healthy := NewPlatformHealth(PlatformX, CookieExpiring, nil, RepeatedFailureThreshold-1)
+	assert.False(t, healthy.Degraded)
+	assert.Empty(t, healthy.Reasons)
+
+	degraded := NewPlatformHealth(PlatformX, CookieInvalid, []string{"/usr/bin/yt-dlp"}, RepeatedFailureThreshold)
+	assert.True(t, degraded.Degraded)
+	assert.Equal(t, DegradedReasons, degraded.Reasons)
+	assert.True(t, degraded.HasReason(DegradedBinaryMissing))
+
+	assert.Equal(t, []string{DegradedCookieExpired}, NewPlatformHealth(PlatformX, CookieExpired, nil, 0).Reasons)
+}
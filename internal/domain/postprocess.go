@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Post-processing steps (postprocess.steps). Each applies only to the files
+// it fits; the others are left alone.
+const (
+	PostProcessRemuxMP4     = "remux_mp4"     // Repackage MKV, WebM, MOV and M4V videos as MP4 without re-encoding
+	PostProcessH264         = "h264"          // Re-encode HEVC videos to H.264 MP4 for players without HEVC support
+	PostProcessContactSheet = "contact_sheet" // Write a 4x4 grid of frames of each video as <name>.contact.jpg
+	PostProcessStripEXIF    = "strip_exif"    // Remove EXIF (including GPS) and text metadata from JPEG and PNG images
+)
+
+// PostProcessSteps lists the supported post-processing steps
+var PostProcessSteps = []string{PostProcessRemuxMP4, PostProcessH264, PostProcessContactSheet, PostProcessStripEXIF}
+
+// ValidatePostProcessSteps checks that every step is supported
+func ValidatePostProcessSteps(steps []string) error {
+	for _, step := range steps {
+		supported := false
+		for _, s := range PostProcessSteps {
+			if step == s {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("unknown step %q (supported: %s)", step, strings.Join(PostProcessSteps, ", "))
+		}
+	}
+	return nil
+}
+
+// PostProcessResult records one step applied to one file of a download. It
+// is saved in the download's metadata under "postprocess".
+type PostProcessResult struct {
+	Step   string `json:"step"`
+	File   string `json:"file"`             // The file the step ran on
+	Output string `json:"output,omitempty"` // The file it produced, when other than File
+	Error  string `json:"error,omitempty"`  // Why the step failed; the file is left as it was
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QueueReorder is a new order for queued downloads, given either as IDs
+// listed top first or as one download (ID) moved just before or just after
+// another
+type QueueReorder struct {
+	IDs    []string `json:"ids,omitempty"`
+	ID     string   `json:"id,omitempty"`
+	Before string   `json:"before,omitempty"`
+	After  string   `json:"after,omitempty"`
+}
+
+// Validate checks that the reorder uses exactly one of its two forms
+func (r QueueReorder) Validate() error {
+	move := r.ID != "" || r.Before != "" || r.After != ""
+	switch {
+	case len(r.IDs) > 0 && move:
+		return errors.New("give either ids or id with before/after, not both")
+	case len(r.IDs) > 0:
+		seen := make(map[string]bool, len(r.IDs))
+		for _, id := range r.IDs {
+			if seen[id] {
+				return fmt.Errorf("duplicate id: %s", id)
+			}
+			seen[id] = true
+		}
+		return nil
+	case r.ID == "":
+		return errors.New("ids or id is required")
+	case (r.Before == "") == (r.After == ""):
+		return errors.New("exactly one of before or after is required")
+	case r.ID == r.Before || r.ID == r.After:
+		return errors.New("cannot move a download relative to itself")
+	}
+	return nil
+}
+
+// Apply returns queue, the queued downloads top first as FindPending returns
+// them, in the new order. Listed IDs take the places they held between them,
+// so downloads that are not listed keep their places. Every ID must be in
+// queue.
+func (r QueueReorder) Apply(queue []*Download) ([]*Download, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(queue))
+	for i, download := range queue {
+		index[download.ID] = i
+	}
+	for _, id := range append([]string{r.ID, r.Before, r.After}, r.IDs...) {
+		if _, ok := index[id]; id != "" && !ok {
+			return nil, &NotQueuedError{ID: id}
+		}
+	}
+
+	ordered := make([]*Download, 0, len(queue))
+	if len(r.IDs) > 0 {
+		slots := make(map[int]bool, len(r.IDs))
+		for _, id := range r.IDs {
+			slots[index[id]] = true
+		}
+		next := 0
+		for i, download := range queue {
+			if slots[i] {
+				download = queue[index[r.IDs[next]]]
+				next++
+			}
+			ordered = append(ordered, download)
+		}
+		return ordered, nil
+	}
+
+	moved := queue[index[r.ID]]
+	for _, download := range queue {
+		switch download.ID {
+		case r.ID:
+			continue
+		case r.Before:
+			ordered = append(ordered, moved, download)
+		case r.After:
+			ordered = append(ordered, download, moved)
+		default:
+			ordered = append(ordered, download)
+		}
+	}
+	return ordered, nil
+}
+
+// QueuePriorities returns the priorities that put ordered, queued downloads
+// top first, in that order: len(ordered)-1 for the first down to 0 for the
+// last. Downloads queued later with the default priority 0 go to the bottom.
+func QueuePriorities(ordered []*Download) map[string]int {
+	priorities := make(map[string]int, len(ordered))
+	for i, download := range ordered {
+		priorities[download.ID] = len(ordered) - 1 - i
+	}
+	return priorities
+}
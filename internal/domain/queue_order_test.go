@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queueOf(ids ...string) []*Download {
+	queue := make([]*Download, len(ids))
+	for i, id := range ids {
+		queue[i] = &Download{ID: id, Status: StatusQueued}
+	}
+	return queue
+}
+
+func idsOf(downloads []*Download) []string {
+	ids := make([]string, len(downloads))
+	for i, download := range downloads {
+		ids[i] = download.ID
+	}
+	return ids
+}
+
+func TestQueueReorder_Validate(t *testing.T) {
+	assert.NoError(t, QueueReorder{IDs: []string{"a", "b"}}.Validate())
+	assert.NoError(t, QueueReorder{ID: "a", Before: "b"}.Validate())
+	assert.NoError(t, QueueReorder{ID: "a", After: "b"}.Validate())
+
+	assert.Error(t, QueueReorder{}.Validate())
+	assert.Error(t, QueueReorder{IDs: []string{"a", "a"}}.Validate())
+	assert.Error(t, QueueReorder{IDs: []string{"a"}, ID: "b", Before: "a"}.Validate())
+	assert.Error(t, QueueReorder{ID: "a"}.Validate())
+	assert.Error(t, QueueReorder{ID: "a", Before: "b", After: "c"}.Validate())
+	assert.Error(t, QueueReorder{ID: "a", Before: "a"}.Validate())
+}
+
+func TestQueueReorder_Apply(t *testing.T) {
+	queue := queueOf("a", "b", "c", "d", "e")
+
+	ordered, err := QueueReorder{IDs: []string{"e", "a", "b", "c", "d"}}.Apply(queue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e", "a", "b", "c", "d"}, idsOf(ordered))
+
+	// Unlisted downloads keep their places
+	ordered, err = QueueReorder{IDs: []string{"d", "b"}}.Apply(queue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "d", "c", "b", "e"}, idsOf(ordered))
+
+	ordered, err = QueueReorder{ID: "e", Before: "b"}.Apply(queue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "e", "b", "c", "d"}, idsOf(ordered))
+
+	ordered, err = QueueReorder{ID: "a", After: "e"}.Apply(queue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d", "e", "a"}, idsOf(ordered))
+
+	_, err = QueueReorder{ID: "a", After: "x"}.Apply(queue)
+	assert.Error(t, err)
+	_, err = QueueReorder{IDs: []string{"a", "x"}}.Apply(queue)
+	assert.Error(t, err)
+}
+
+func TestQueuePriorities(t *testing.T) {
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, "c": 0}, QueuePriorities(queueOf("a", "b", "c")))
+}
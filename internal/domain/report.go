@@ -0,0 +1,342 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"text/template"
+	"time"
+)
+
+// Archive report periods (report.period)
+const (
+	ReportDaily  = "daily"
+	ReportWeekly = "weekly"
+)
+
+// Archive report formats (report.format)
+const (
+	ReportFormatMarkdown = "markdown"
+	ReportFormatHTML     = "html"
+)
+
+// Limits of the lists in an archive report
+const (
+	reportTopSources  = 10
+	reportMaxFailures = 20
+)
+
+// ValidateReportPeriod checks a report.period value
+func ValidateReportPeriod(period string) error {
+	switch period {
+	case ReportDaily, ReportWeekly:
+		return nil
+	default:
+		return fmt.Errorf("invalid report period %q (supported: %s, %s)", period, ReportDaily, ReportWeekly)
+	}
+}
+
+// ValidateReportFormat checks a report.format value
+func ValidateReportFormat(format string) error {
+	switch format {
+	case ReportFormatMarkdown, ReportFormatHTML:
+		return nil
+	default:
+		return fmt.Errorf("invalid report format %q (supported: %s, %s)", format, ReportFormatMarkdown, ReportFormatHTML)
+	}
+}
+
+// ReportPeriodBounds returns the last full period before now, in now's time
+// zone: yesterday for daily reports, the previous Monday-to-Monday week for
+// weekly reports. from is inclusive, to exclusive.
+func ReportPeriodBounds(period string, now time.Time) (from, to time.Time) {
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if period == ReportWeekly {
+		daysSinceMonday := (int(to.Weekday()) + 6) % 7
+		to = to.AddDate(0, 0, -daysSinceMonday)
+		return to.AddDate(0, 0, -7), to
+	}
+	return to.AddDate(0, 0, -1), to
+}
+
+// ReportFileName returns the file name of a report, e.g.
+// "report_daily_2024-01-14.md" (dated by the first day of the period)
+func ReportFileName(period, format string, from time.Time) string {
+	ext := ".md"
+	if format == ReportFormatHTML {
+		ext = ".html"
+	}
+	return fmt.Sprintf("report_%s_%s%s", period, from.Format("2006-01-02"), ext)
+}
+
+// ArchiveReport summarizes what the archive gained over one period
+type ArchiveReport struct {
+	Period      string    `json:"period"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Completed    int              `json:"completed"`
+	Failed       int              `json:"failed"`
+	Cancelled    int              `json:"cancelled"`
+	Items        int              `json:"items"`       // Files of the completed downloads
+	Bytes        int64            `json:"bytes"`       // Storage growth: size of the downloads completed in the period
+	TotalBytes   int64            `json:"total_bytes"` // Size of all downloads completed by the end of the period
+	Platforms    map[Platform]int `json:"platforms"`   // Completed downloads per platform
+	TopSources   []ReportSource   `json:"top_sources"`
+	Failures     []ReportFailure  `json:"failures"` // Most recent first, at most 20
+	MoreFailures int              `json:"more_failures,omitempty"`
+}
+
+// ReportSource is an uploader or channel with its completed downloads in a report
+type ReportSource struct {
+	Platform  Platform `json:"platform"`
+	Key       string   `json:"key"`      // Uploader ID, or name when the ID is unknown
+	Uploader  string   `json:"uploader"` // Display name
+	Downloads int      `json:"downloads"`
+	Bytes     int64    `json:"bytes"`
+}
+
+// ReportFailure is a download that failed during a report's period
+type ReportFailure struct {
+	ID       string    `json:"id"`
+	URL      string    `json:"url"`
+	Platform Platform  `json:"platform"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// ReportRepository defines the queries behind archive reports
+type ReportRepository interface {
+	// FindFinishedBetween returns the downloads that completed, failed or were
+	// cancelled in [from, to)
+	FindFinishedBetween(from, to time.Time) ([]*Download, error)
+
+	// CompletedSizeBefore returns the total size of the downloads completed before t
+	CompletedSizeBefore(t time.Time) (int64, error)
+}
+
+// BuildArchiveReport summarizes the downloads finished in [from, to).
+// totalBytes is the size of the archive at the end of the period.
+func BuildArchiveReport(period string, from, to time.Time, downloads []*Download, totalBytes int64) *ArchiveReport {
+	report := &ArchiveReport{
+		Period:      period,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		TotalBytes:  totalBytes,
+		Platforms:   map[Platform]int{},
+		TopSources:  []ReportSource{},
+		Failures:    []ReportFailure{},
+	}
+
+	sources := map[string]*ReportSource{}
+	for _, d := range downloads {
+		switch d.Status {
+		case StatusCompleted:
+			report.Completed++
+			report.Bytes += d.FileSize
+			report.Platforms[d.Platform]++
+			if len(d.Items) > 0 {
+				for _, item := range d.Items {
+					if item.Status == StatusCompleted {
+						report.Items++
+					}
+				}
+			} else {
+				report.Items += len(d.Files())
+			}
+
+			key := firstNonEmptyString(d.UploaderID, d.Uploader)
+			if key == "" {
+				continue
+			}
+			mapKey := string(d.Platform) + "/" + key
+			source, ok := sources[mapKey]
+			if !ok {
+				source = &ReportSource{Platform: d.Platform, Key: key, Uploader: firstNonEmptyString(d.Uploader, key)}
+				sources[mapKey] = source
+			}
+			source.Downloads++
+			source.Bytes += d.FileSize
+		case StatusFailed:
+			report.Failed++
+			report.Failures = append(report.Failures, ReportFailure{
+				ID:       d.ID,
+				URL:      d.URL,
+				Platform: d.Platform,
+				Error:    d.ErrorMessage,
+				FailedAt: d.UpdatedAt,
+			})
+		case StatusCancelled:
+			report.Cancelled++
+		}
+	}
+
+	for _, source := range sources {
+		report.TopSources = append(report.TopSources, *source)
+	}
+	sort.Slice(report.TopSources, func(i, j int) bool {
+		a, b := report.TopSources[i], report.TopSources[j]
+		if a.Downloads != b.Downloads {
+			return a.Downloads > b.Downloads
+		}
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Key < b.Key
+	})
+	if len(report.TopSources) > reportTopSources {
+		report.TopSources = report.TopSources[:reportTopSources]
+	}
+
+	sort.Slice(report.Failures, func(i, j int) bool {
+		return report.Failures[i].FailedAt.After(report.Failures[j].FailedAt)
+	})
+	if len(report.Failures) > reportMaxFailures {
+		report.MoreFailures = len(report.Failures) - reportMaxFailures
+		report.Failures = report.Failures[:reportMaxFailures]
+	}
+	return report
+}
+
+// Title returns the report heading, e.g. "Daily archive report: 2024-01-14"
+// or "Weekly archive report: 2024-01-08 to 2024-01-14"
+func (r *ArchiveReport) Title() string {
+	last := r.To.AddDate(0, 0, -1)
+	if r.Period == ReportWeekly {
+		return fmt.Sprintf("Weekly archive report: %s to %s", r.From.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Daily archive report: %s", r.From.Format("2006-01-02"))
+}
+
+// Summary returns a one-line summary for notifications, e.g.
+// "12 downloads (1.5 GiB), 2 failed"
+func (r *ArchiveReport) Summary() string {
+	summary := fmt.Sprintf("%d downloads (%s)", r.Completed, FormatBytes(r.Bytes))
+	if r.Failed > 0 {
+		summary += fmt.Sprintf(", %d failed", r.Failed)
+	}
+	return summary
+}
+
+// Render renders the report as Markdown or HTML (see ReportFormat*)
+func (r *ArchiveReport) Render(format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case ReportFormatMarkdown:
+		err = reportMarkdownTemplate.Execute(&buf, r)
+	case ReportFormatHTML:
+		err = reportHTMLTemplate.Execute(&buf, r)
+	default:
+		return nil, ValidateReportFormat(format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// reportFuncs are shared by the Markdown and HTML report templates
+var reportFuncs = map[string]interface{}{
+	"bytes": FormatBytes,
+	"time":  func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}
+
+var reportMarkdownTemplate = template.Must(template.New("report.md").Funcs(reportFuncs).Parse(`# {{.Title}}
+
+Generated {{time .GeneratedAt}}
+
+## Summary
+
+| | |
+|---|---|
+| Completed | {{.Completed}} ({{.Items}} files) |
+| Failed | {{.Failed}} |
+| Cancelled | {{.Cancelled}} |
+| Storage growth | {{bytes .Bytes}} |
+| Archive size | {{bytes .TotalBytes}} |
+{{- range $platform, $count := .Platforms}}
+| {{$platform}} downloads | {{$count}} |
+{{- end}}
+
+## Top sources
+{{if .TopSources}}
+| Source | Platform | Downloads | Size |
+|---|---|---|---|
+{{- range .TopSources}}
+| {{.Uploader}} | {{.Platform}} | {{.Downloads}} | {{bytes .Bytes}} |
+{{- end}}
+{{else}}
+None.
+{{end}}
+## Failures
+{{if .Failures}}
+{{- range .Failures}}
+- {{time .FailedAt}} {{.URL}}: {{.Error}}
+{{- end}}
+{{- if .MoreFailures}}
+- ... and {{.MoreFailures}} more
+{{- end}}
+{{else}}
+None.
+{{end}}`))
+
+var reportHTMLTemplate = htmltemplate.Must(htmltemplate.New("report.html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 56em; margin: 2em auto; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{time .GeneratedAt}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Completed</th><td>{{.Completed}} ({{.Items}} files)</td></tr>
+<tr><th>Failed</th><td>{{.Failed}}</td></tr>
+<tr><th>Cancelled</th><td>{{.Cancelled}}</td></tr>
+<tr><th>Storage growth</th><td>{{bytes .Bytes}}</td></tr>
+<tr><th>Archive size</th><td>{{bytes .TotalBytes}}</td></tr>
+{{- range $platform, $count := .Platforms}}
+<tr><th>{{$platform}} downloads</th><td>{{$count}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Top sources</h2>
+{{if .TopSources}}<table>
+<tr><th>Source</th><th>Platform</th><th>Downloads</th><th>Size</th></tr>
+{{- range .TopSources}}
+<tr><td>{{.Uploader}}</td><td>{{.Platform}}</td><td>{{.Downloads}}</td><td>{{bytes .Bytes}}</td></tr>
+{{- end}}
+</table>{{else}}<p>None.</p>{{end}}
+
+<h2>Failures</h2>
+{{if .Failures}}<ul>
+{{- range .Failures}}
+<li>{{time .FailedAt}} <a href="{{.URL}}">{{.URL}}</a>: {{.Error}}</li>
+{{- end}}
+{{- if .MoreFailures}}
+<li>... and {{.MoreFailures}} more</li>
+{{- end}}
+</ul>{{else}}<p>None.</p>{{end}}
+</body>
+</html>
+`))
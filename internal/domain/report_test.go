@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPeriodBounds(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2024, 1, 17, 15, 30, 0, 0, time.UTC)
+
+	from, to := ReportPeriodBounds(ReportDaily, now)
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), to)
+
+	from, to = ReportPeriodBounds(ReportWeekly, now)
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), from, "previous Monday")
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), to)
+
+	// On a Monday the week that just ended is reported
+	from, _ = ReportPeriodBounds(ReportWeekly, time.Date(2024, 1, 15, 0, 5, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), from)
+}
+
+func TestReportFileName(t *testing.T) {
+	from := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "report_daily_2024-01-14.md", ReportFileName(ReportDaily, ReportFormatMarkdown, from))
+	assert.Equal(t, "report_weekly_2024-01-14.html", ReportFileName(ReportWeekly, ReportFormatHTML, from))
+}
+
+func TestValidateReportSettings(t *testing.T) {
+	assert.NoError(t, ValidateReportPeriod(ReportWeekly))
+	assert.Error(t, ValidateReportPeriod("monthly"))
+	assert.NoError(t, ValidateReportFormat(ReportFormatHTML))
+	assert.Error(t, ValidateReportFormat("pdf"))
+}
+
+func TestBuildArchiveReport(t *testing.T) {
+	from := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	downloads := []*Download{
+		{Status: StatusCompleted, Platform: PlatformX, UploaderID: "alice", Uploader: "Alice", FileSize: 100,
+			Items: []DownloadItem{{Status: StatusCompleted}, {Status: StatusCompleted}, {Status: StatusFailed}}},
+		{Status: StatusCompleted, Platform: PlatformX, UploaderID: "alice", Uploader: "Alice", FileSize: 50, FilePath: "/c/a.mp4"},
+		{Status: StatusCompleted, Platform: PlatformTelegram, Uploader: "News", FileSize: 500, FilePath: "/c/b.mp4"},
+		{Status: StatusFailed, URL: "https://x.com/bob/status/1", ErrorMessage: "gone", UpdatedAt: from.Add(time.Hour)},
+		{Status: StatusFailed, URL: "https://x.com/bob/status/2", ErrorMessage: "private", UpdatedAt: from.Add(2 * time.Hour)},
+		{Status: StatusCancelled},
+	}
+
+	report := BuildArchiveReport(ReportDaily, from, to, downloads, 10000)
+	assert.Equal(t, 3, report.Completed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Cancelled)
+	assert.Equal(t, 4, report.Items, "completed items, or the files of downloads without items")
+	assert.Equal(t, int64(650), report.Bytes)
+	assert.Equal(t, int64(10000), report.TotalBytes)
+	assert.Equal(t, map[Platform]int{PlatformX: 2, PlatformTelegram: 1}, report.Platforms)
+
+	require.Len(t, report.TopSources, 2)
+	assert.Equal(t, ReportSource{Platform: PlatformX, Key: "alice", Uploader: "Alice", Downloads: 2, Bytes: 150}, report.TopSources[0])
+	assert.Equal(t, "News", report.TopSources[1].Key, "name is the key when the ID is unknown")
+
+	require.Len(t, report.Failures, 2)
+	assert.Equal(t, "https://x.com/bob/status/2", report.Failures[0].URL, "most recent failure first")
+	assert.Equal(t, "3 downloads (650 B), 2 failed", report.Summary())
+}
+
+func TestArchiveReport_Render(t *testing.T) {
+	from := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	report := BuildArchiveReport(ReportWeekly, from, from.AddDate(0, 0, 7), []*Download{
+		{Status: StatusCompleted, Platform: PlatformX, UploaderID: "alice", Uploader: "<Alice>", FileSize: 2048, FilePath: "/c/a.mp4"},
+		{Status: StatusFailed, URL: "https://x.com/bob/status/1", ErrorMessage: "gone"},
+	}, 1<<30)
+
+	md, err := report.Render(ReportFormatMarkdown)
+	require.NoError(t, err)
+	assert.Contains(t, string(md), "# Weekly archive report: 2024-01-08 to 2024-01-14")
+	assert.Contains(t, string(md), "| Storage growth | 2.0 KiB |")
+	assert.Contains(t, string(md), "| Archive size | 1.0 GiB |")
+	assert.Contains(t, string(md), "| <Alice> | x | 1 | 2.0 KiB |")
+	assert.Contains(t, string(md), "https://x.com/bob/status/1: gone")
+
+	html, err := report.Render(ReportFormatHTML)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<td>&lt;Alice&gt;</td>", "HTML output is escaped")
+
+	_, err = report.Render("pdf")
+	assert.Error(t, err)
+}
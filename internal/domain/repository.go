@@ -1,17 +1,33 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+)
+
 // DownloadRepository defines the interface for download persistence
 type DownloadRepository interface {
 	// Create creates a new download
 	Create(download *Download) error
 
-	// Update updates an existing download
+	// Update updates an existing download. When Items is non-nil the
+	// download's item rows are replaced by it.
 	Update(download *Download) error
 
-	// Delete deletes a download by ID
+	// UpdateProgress saves only the live progress fields (progress, speed,
+	// eta, current_file, item_count) so it never overwrites a concurrent
+	// status change
+	UpdateProgress(download *Download) error
+
+	// UpdateQueuedPriorities sets the priority of downloads by ID, in one
+	// transaction, skipping those that are no longer queued. Only the priority
+	// column is written. Returns how many downloads were updated.
+	UpdateQueuedPriorities(priorities map[string]int) (int64, error)
+
+	// Delete deletes a download and its items by ID
 	Delete(id string) error
 
-	// FindByID finds a download by ID
+	// FindByID finds a download by ID, with its items
 	FindByID(id string) (*Download, error)
 
 	// FindByURL finds downloads by URL with specific statuses
@@ -24,8 +40,14 @@ type DownloadRepository interface {
 	// FindPending finds all pending downloads ordered by priority and creation time
 	FindPending() ([]*Download, error)
 
-	// FindAll finds all downloads with optional filters
-	FindAll(filters map[string]interface{}) ([]*Download, error)
+	// FindAll finds the downloads matching the optional column filters and
+	// the creation time range of opts, with their items, ordered and paged
+	// by opts
+	FindAll(filters map[string]interface{}, opts ListOptions) ([]*Download, error)
+
+	// CountAll returns the number of downloads FindAll would return without
+	// opts' limit and offset
+	CountAll(filters map[string]interface{}, opts ListOptions) (int64, error)
 
 	// Count returns the total number of downloads
 	Count() (int64, error)
@@ -33,15 +55,77 @@ type DownloadRepository interface {
 	// CountByStatus returns the number of downloads by status
 	CountByStatus(status DownloadStatus) (int64, error)
 
-	// CountActive returns the number of active downloads (queued + processing)
+	// CountActive returns the number of active downloads (queued + processing + recording)
 	CountActive() (int64, error)
 
-	// ResetOrphanedProcessing resets downloads that are stuck in processing state
-	// This handles cases where the server was killed during download
-	ResetOrphanedProcessing() (int64, error)
+	// ResetOrphanedProcessing recovers downloads that are stuck in processing or
+	// recording state because the server was killed during download (see
+	// Download.RecoverOrphaned) and returns them
+	ResetOrphanedProcessing(maxRetries int) ([]*Download, error)
 
 	// GetStats returns download statistics
 	GetStats() (*DownloadStats, error)
+
+	// GetStatsHistory returns the downloads added and completed per day, by
+	// platform, for days days through now's day. Imported downloads are not
+	// counted.
+	GetStatsHistory(days int, now time.Time) (*StatsHistory, error)
+}
+
+// Download list sort directions
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc" // Default
+)
+
+// ValidDownloadSorts lists the columns ListOptions.SortBy accepts
+var ValidDownloadSorts = map[string]bool{
+	"created_at":     true,
+	"updated_at":     true,
+	"completed_at":   true,
+	"priority":       true,
+	"file_size":      true,
+	"media_duration": true,
+	"title":          true,
+	"uploader":       true,
+	"status":         true,
+	"platform":       true,
+}
+
+// ListOptions pages and orders a download listing and bounds it by creation
+// time and size. The zero value lists every download, newest first.
+type ListOptions struct {
+	Limit         int        // At most this many downloads; 0 means no limit
+	Offset        int        // Skip this many downloads
+	SortBy        string     // One of ValidDownloadSorts; empty means created_at
+	SortDir       string     // SortAsc or SortDesc; empty means SortDesc
+	CreatedAfter  *time.Time // Downloads created at or after this time
+	CreatedBefore *time.Time // Downloads created before this time
+	MinSize       int64      // Downloads of at least this many bytes (0 = no minimum)
+	MaxSize       int64      // Downloads of at most this many bytes (0 = no maximum)
+}
+
+// Validate checks the paging, sort, time range and size range of the options
+func (o ListOptions) Validate() error {
+	if o.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d", o.Limit)
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("invalid offset: %d", o.Offset)
+	}
+	if o.SortBy != "" && !ValidDownloadSorts[o.SortBy] {
+		return fmt.Errorf("invalid sort_by: %s", o.SortBy)
+	}
+	if o.SortDir != "" && o.SortDir != SortAsc && o.SortDir != SortDesc {
+		return fmt.Errorf("invalid sort_dir: %s (asc or desc)", o.SortDir)
+	}
+	if o.CreatedAfter != nil && o.CreatedBefore != nil && !o.CreatedAfter.Before(*o.CreatedBefore) {
+		return fmt.Errorf("invalid time range: created_after must be before created_before")
+	}
+	if o.MinSize < 0 || o.MaxSize < 0 || (o.MaxSize > 0 && o.MinSize > o.MaxSize) {
+		return fmt.Errorf("invalid size range: min_size must not exceed max_size")
+	}
+	return nil
 }
 
 // DownloadStats represents download statistics
@@ -49,7 +133,39 @@ type DownloadStats struct {
 	Total      int64 `json:"total"`
 	Queued     int64 `json:"queued"`
 	Processing int64 `json:"processing"`
+	Recording  int64 `json:"recording"`
 	Completed  int64 `json:"completed"`
 	Failed     int64 `json:"failed"`
 	Cancelled  int64 `json:"cancelled"`
+	Expired    int64 `json:"expired"`
+
+	// Items counts files rather than downloads: the completed files of all
+	// downloads, and the files a tool reported as failed
+	Items       int64 `json:"items"`
+	FailedItems int64 `json:"failed_items"`
+
+	// Bytes and MediaDuration total the files of every download that
+	// completed, expired ones included and imported ones aside: their size
+	// and the play time in seconds of their videos. BytesToday counts those
+	// that completed since local midnight.
+	Bytes         int64   `json:"bytes"`
+	BytesToday    int64   `json:"bytes_today"`
+	MediaDuration float64 `json:"media_duration"`
+
+	// Tags and Collections break the completed downloads down by the "tags"
+	// and "collection" metadata fields (see metadata.extra_fields), largest first
+	Tags        []GroupStats `json:"tags"`
+	Collections []GroupStats `json:"collections"`
+
+	// Resources is the resource usage of the external tools, summed over the
+	// downloads' last attempts
+	Resources ResourceStats `json:"resources"`
+}
+
+// GroupStats counts the completed downloads sharing a tag or collection and
+// the bytes they take up
+type GroupStats struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	Bytes int64  `json:"bytes"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOptions_Validate(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	assert.NoError(t, ListOptions{}.Validate())
+	assert.NoError(t, ListOptions{Limit: 50, Offset: 100, SortBy: "file_size", SortDir: SortAsc,
+		CreatedAfter: &earlier, CreatedBefore: &now}.Validate())
+
+	assert.Error(t, ListOptions{Limit: -1}.Validate())
+	assert.Error(t, ListOptions{Offset: -1}.Validate())
+	assert.Error(t, ListOptions{SortBy: "metadata"}.Validate())
+	assert.Error(t, ListOptions{SortDir: "up"}.Validate())
+	assert.Error(t, ListOptions{CreatedAfter: &now, CreatedBefore: &earlier}.Validate())
+	assert.NoError(t, ListOptions{MinSize: 1 << 20, MaxSize: 1 << 20}.Validate())
+	assert.Error(t, ListOptions{MinSize: 2 << 20, MaxSize: 1 << 20}.Validate())
+}
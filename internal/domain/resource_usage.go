@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResourceUsage is what the external tools (yt-dlp, tdl, gallery-dl, ffmpeg)
+// run for a download attempt used
+type ResourceUsage struct {
+	Processes int           // Tools run
+	CPUTime   time.Duration // User plus system CPU time, of the tools and their children
+	PeakRSS   int64         // Largest resident set size in bytes of any tool
+	WallTime  time.Duration // Time the tools ran, summed
+}
+
+// Add adds the usage of another process
+func (u *ResourceUsage) Add(other ResourceUsage) {
+	u.Processes += other.Processes
+	u.CPUTime += other.CPUTime
+	u.WallTime += other.WallTime
+	if other.PeakRSS > u.PeakRSS {
+		u.PeakRSS = other.PeakRSS
+	}
+}
+
+// UsageMeter adds up the resource usage of the processes run for a download
+// attempt. The download manager passes it to the downloaders in the context.
+type UsageMeter struct {
+	mu    sync.Mutex
+	usage ResourceUsage
+}
+
+// Record adds the usage of a finished process
+func (m *UsageMeter) Record(usage ResourceUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Add(usage)
+}
+
+// Usage returns the usage recorded so far
+func (m *UsageMeter) Usage() ResourceUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter returns a context whose processes are recorded by meter
+func WithUsageMeter(ctx context.Context, meter *UsageMeter) context.Context {
+	return context.WithValue(ctx, usageMeterKey{}, meter)
+}
+
+// UsageMeterFrom returns the meter of ctx, or nil
+func UsageMeterFrom(ctx context.Context) *UsageMeter {
+	meter, _ := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	return meter
+}
+
+// ResourceStats sums the resource usage of the downloads' last attempts
+type ResourceStats struct {
+	CPUMs    int64           `json:"cpu_ms"`
+	WallMs   int64           `json:"wall_ms"`
+	PeakRSS  int64           `json:"peak_rss"` // Largest of any download, in bytes
+	Heaviest []DownloadUsage `json:"heaviest"` // The downloads that used the most CPU time, most first
+}
+
+// DownloadUsage is the resource usage of one download's last attempt
+type DownloadUsage struct {
+	ID        string         `json:"id"`
+	URL       string         `json:"url"`
+	Status    DownloadStatus `json:"status"`
+	Processes int            `json:"processes"`
+	CPUMs     int64          `json:"cpu_ms"`
+	WallMs    int64          `json:"wall_ms"`
+	PeakRSS   int64          `json:"peak_rss"`
+}
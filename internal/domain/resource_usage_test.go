@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageMeter(t *testing.T) {
+	assert.Nil(t, UsageMeterFrom(context.Background()))
+
+	meter := &UsageMeter{}
+	ctx := WithUsageMeter(context.Background(), meter)
+	assert.Same(t, meter, UsageMeterFrom(ctx))
+
+	UsageMeterFrom(ctx).Record(ResourceUsage{Processes: 1, CPUTime: time.Second, WallTime: 3 * time.Second, PeakRSS: 100})
+	UsageMeterFrom(ctx).Record(ResourceUsage{Processes: 1, CPUTime: 2 * time.Second, WallTime: time.Second, PeakRSS: 50})
+	assert.Equal(t, ResourceUsage{Processes: 2, CPUTime: 3 * time.Second, WallTime: 4 * time.Second, PeakRSS: 100}, meter.Usage())
+
+	var d Download
+	d.ApplyResourceUsage(meter.Usage())
+	assert.Equal(t, 2, d.ProcessCount)
+	assert.Equal(t, int64(3000), d.ProcessCPUMs)
+	assert.Equal(t, int64(4000), d.ProcessWallMs)
+	assert.Equal(t, int64(100), d.ProcessPeakRSS)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// SelectExpired returns the completed downloads the retention policy expires,
+// oldest first: those completed more than maxAge before now, then the oldest
+// of the rest until the remaining total FileSize is at most maxSize. A zero
+// maxAge or maxSize disables that limit. Archived downloads are kept and not
+// counted: their files are on the archive volume.
+func SelectExpired(downloads []*Download, maxAge time.Duration, maxSize int64, now time.Time) []*Download {
+	sorted := make([]*Download, 0, len(downloads))
+	var total int64
+	for _, d := range downloads {
+		if d.Status != StatusCompleted || d.ArchiveDir() != "" {
+			continue
+		}
+		sorted = append(sorted, d)
+		total += d.FileSize
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return completedTime(sorted[i]).Before(completedTime(sorted[j]))
+	})
+
+	var expired []*Download
+	for _, d := range sorted {
+		tooOld := maxAge > 0 && completedTime(d).Before(now.Add(-maxAge))
+		overSize := maxSize > 0 && total > maxSize
+		if !tooOld && !overSize {
+			// Newer downloads are neither older nor needed to free space
+			break
+		}
+		expired = append(expired, d)
+		total -= d.FileSize
+	}
+	return expired
+}
+
+// completedTime returns when the download completed, or its creation time for
+// records without completed_at
+func completedTime(d *Download) time.Time {
+	if d.CompletedAt != nil {
+		return *d.CompletedAt
+	}
+	return d.CreatedAt
+}
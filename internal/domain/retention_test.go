@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	completedAt := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+		return &at
+	}
+	old := &Download{ID: "old", Status: StatusCompleted, FileSize: 100, CompletedAt: completedAt(120)}
+	mid := &Download{ID: "mid", Status: StatusCompleted, FileSize: 300, CompletedAt: completedAt(60)}
+	recent := &Download{ID: "recent", Status: StatusCompleted, FileSize: 200, CompletedAt: completedAt(1)}
+	failed := &Download{ID: "failed", Status: StatusFailed, FileSize: 1000, CreatedAt: now.AddDate(-1, 0, 0)}
+	downloads := []*Download{recent, failed, mid, old}
+
+	ids := func(downloads []*Download) []string {
+		var ids []string
+		for _, d := range downloads {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"old"}, ids(SelectExpired(downloads, 90*24*time.Hour, 0, now)))
+	assert.Equal(t, []string{"old", "mid"}, ids(SelectExpired(downloads, 0, 250, now)), "oldest first until the rest fit")
+	assert.Equal(t, []string{"old"}, ids(SelectExpired(downloads, 0, 500, now)))
+	assert.Equal(t, []string{"old", "mid"}, ids(SelectExpired(downloads, 30*24*time.Hour, 1000, now)))
+	assert.Empty(t, SelectExpired(downloads, 0, 0, now), "no limits")
+}
+
+func TestDownload_MarkExpired(t *testing.T) {
+	download := &Download{
+		Status: StatusCompleted,
+		Items:  []DownloadItem{{Status: StatusCompleted}, {Status: StatusFailed}},
+	}
+	download.MarkExpired()
+
+	assert.Equal(t, StatusExpired, download.Status)
+	assert.Equal(t, StatusExpired, download.Items[0].Status)
+	assert.Equal(t, StatusFailed, download.Items[1].Status)
+	assert.True(t, download.IsTerminal())
+	assert.Equal(t, TimelineExpired, download.Timeline[len(download.Timeline)-1].Event)
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schedule is a recurring sync of a Telegram channel or X account. On every
+// cron tick the source is listed and items newer than Cursor are enqueued as
+// individual downloads.
+type Schedule struct {
+	ID           string     `json:"id" gorm:"primaryKey"`
+	Name         string     `json:"name"`
+	SourceURL    string     `json:"source_url" gorm:"not null"`         // Channel (https://t.me/c/123) or profile (https://x.com/user) URL
+	Platform     Platform   `json:"platform" gorm:"not null"`           // Source platform: telegram or x
+	CronExpr     string     `json:"cron_expr" gorm:"not null"`          // 5-field cron expression or @hourly/@daily/...
+	Enabled      bool       `json:"enabled"`                            // Disabled schedules are kept but never run
+	Cursor       string     `json:"cursor,omitempty"`                   // Newest item ID already enqueued (message ID / tweet ID)
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`              // When the last sync ran
+	NextRunAt    *time.Time `json:"next_run_at,omitempty" gorm:"index"` // When the next sync is due
+	LastError    string     `json:"last_error,omitempty"`               // Error from the last sync, empty on success
+	LastEnqueued int        `json:"last_enqueued"`                      // Number of downloads enqueued by the last sync
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (Schedule) TableName() string {
+	return "schedules"
+}
+
+// NewSchedule creates a new enabled schedule with NextRunAt computed from cronExpr
+func NewSchedule(name, sourceURL string, platform Platform, cronExpr string) (*Schedule, error) {
+	if platform != PlatformTelegram && platform != PlatformX {
+		return nil, fmt.Errorf("schedules support telegram and x sources, got: %s", platform)
+	}
+	s := &Schedule{
+		ID:        uuid.New().String()[:8],
+		Name:      name,
+		SourceURL: sourceURL,
+		Platform:  platform,
+		CronExpr:  cronExpr,
+		Enabled:   true,
+	}
+	if err := s.ScheduleNext(time.Now()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ScheduleNext sets NextRunAt to the first cron tick after t
+func (s *Schedule) ScheduleNext(t time.Time) error {
+	cron, err := ParseCron(s.CronExpr)
+	if err != nil {
+		return err
+	}
+	next := cron.Next(t)
+	if next.IsZero() {
+		return fmt.Errorf("cron expression %q never fires", s.CronExpr)
+	}
+	s.NextRunAt = &next
+	return nil
+}
+
+// IsDue checks if an enabled schedule should run at t
+func (s *Schedule) IsDue(t time.Time) bool {
+	return s.Enabled && s.NextRunAt != nil && !s.NextRunAt.After(t)
+}
+
+// ScheduleRepository defines the interface for schedule persistence
+type ScheduleRepository interface {
+	// CreateSchedule creates a new schedule
+	CreateSchedule(schedule *Schedule) error
+
+	// UpdateSchedule updates an existing schedule
+	UpdateSchedule(schedule *Schedule) error
+
+	// DeleteSchedule deletes a schedule by ID
+	DeleteSchedule(id string) error
+
+	// FindScheduleByID finds a schedule by ID
+	// Returns nil if not found
+	FindScheduleByID(id string) (*Schedule, error)
+
+	// FindAllSchedules returns all schedules ordered by creation time
+	FindAllSchedules() ([]*Schedule, error)
+}
+
+// SyncItem is a single new item discovered by a SourceSyncer
+type SyncItem struct {
+	ID       string       // Platform item ID (message ID / tweet ID), used as the schedule cursor
+	URL      string       // URL to enqueue as a download
+	Platform Platform     // Platform of the download to enqueue
+	Mode     DownloadMode // Download mode of the download to enqueue
+}
+
+// SourceSyncer lists items newly published by a channel or profile
+type SourceSyncer interface {
+	// ListNewItems returns up to limit items published after cursor, oldest
+	// first. An empty cursor lists from the beginning of the source.
+	ListNewItems(ctx context.Context, sourceURL, cursor string, limit int) ([]SyncItem, error)
+}
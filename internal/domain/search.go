@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DownloadFilter selects downloads by any combination of criteria. Empty
+// fields are ignored; all set fields must match.
+type DownloadFilter struct {
+	Status   DownloadStatus `json:"status,omitempty"`
+	Platform Platform       `json:"platform,omitempty"`
+	Tag      string         `json:"tag,omitempty"`      // Exact match against the metadata "tags" list
+	Uploader string         `json:"uploader,omitempty"` // Matches uploader name or uploader ID
+	Language string         `json:"language,omitempty"` // ISO 639-1 code of the post text, e.g. "zh"
+	From     *time.Time     `json:"from,omitempty"`     // Downloads created at or after From
+	To       *time.Time     `json:"to,omitempty"`       // Downloads created before To
+	Query    string         `json:"query,omitempty"`    // Case-insensitive substring of URL, title or uploader
+	Text     string         `json:"text,omitempty"`     // Full-text terms, see SearchTerms
+}
+
+// SearchTerms splits a full-text query into terms. Terms are separated by
+// whitespace; double quotes group a phrase into one term. Every term must
+// appear (case-insensitively) in the URL, title, description, uploader,
+// tags, image alt texts or poll options of a download for it to match.
+func SearchTerms(text string) []string {
+	var terms []string
+	for i, part := range strings.Split(text, `"`) {
+		if i%2 == 1 {
+			// Inside quotes
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// Validate checks that the filter's enumerated fields and date range are valid
+func (f DownloadFilter) Validate() error {
+	switch f.Status {
+	case "", StatusQueued, StatusProcessing, StatusRecording, StatusCompleted, StatusFailed, StatusCancelled, StatusExpired:
+	default:
+		return fmt.Errorf("invalid status: %s", f.Status)
+	}
+	if f.Platform != "" && !ValidatePlatform(f.Platform) {
+		return fmt.Errorf("invalid platform: %s", f.Platform)
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("invalid date range: from must be before to")
+	}
+	return nil
+}
+
+// SavedSearch is a named DownloadFilter that can be re-run as a one-click view
+type SavedSearch struct {
+	ID        string         `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"not null;uniqueIndex"`
+	Filter    DownloadFilter `json:"filter" gorm:"embedded;embeddedPrefix:filter_"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+// NewSavedSearch creates a new saved search after validating its filter
+func NewSavedSearch(name string, filter DownloadFilter) (*SavedSearch, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("saved search name is required")
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return &SavedSearch{
+		ID:     uuid.New().String()[:8],
+		Name:   name,
+		Filter: filter,
+	}, nil
+}
+
+// SavedSearchRepository defines the interface for saved search persistence
+// and filtered download lookups
+type SavedSearchRepository interface {
+	// CreateSavedSearch creates a new saved search
+	CreateSavedSearch(search *SavedSearch) error
+
+	// UpdateSavedSearch updates an existing saved search
+	UpdateSavedSearch(search *SavedSearch) error
+
+	// DeleteSavedSearch deletes a saved search by ID
+	DeleteSavedSearch(id string) error
+
+	// FindSavedSearch finds a saved search by ID or name
+	// Returns nil if not found
+	FindSavedSearch(idOrName string) (*SavedSearch, error)
+
+	// FindAllSavedSearches returns all saved searches ordered by name
+	FindAllSavedSearches() ([]*SavedSearch, error)
+
+	// SearchDownloads returns downloads matching filter, newest first.
+	// limit <= 0 means no limit.
+	SearchDownloads(filter DownloadFilter, limit int) ([]*Download, error)
+}
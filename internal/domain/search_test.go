@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadFilter_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	assert.NoError(t, DownloadFilter{}.Validate())
+	assert.NoError(t, DownloadFilter{Status: StatusFailed, Platform: PlatformX, From: &from, To: &to}.Validate())
+	assert.Error(t, DownloadFilter{Status: "bogus"}.Validate())
+	assert.Error(t, DownloadFilter{Platform: "myspace"}.Validate())
+	assert.Error(t, DownloadFilter{From: &to, To: &from}.Validate())
+}
+
+func TestNewSavedSearch(t *testing.T) {
+	search, err := NewSavedSearch("  failed x  ", DownloadFilter{Status: StatusFailed})
+	require.NoError(t, err)
+	assert.NotEmpty(t, search.ID)
+	assert.Equal(t, "failed x", search.Name)
+	assert.Equal(t, StatusFailed, search.Filter.Status)
+
+	_, err = NewSavedSearch(" ", DownloadFilter{})
+	assert.Error(t, err)
+
+	_, err = NewSavedSearch("bad", DownloadFilter{Status: "bogus"})
+	assert.Error(t, err)
+}
+
+func TestSearchTerms(t *testing.T) {
+	assert.Empty(t, SearchTerms("   "))
+	assert.Equal(t, []string{"cats", "dogs"}, SearchTerms(" cats  dogs "))
+	assert.Equal(t, []string{"cute cats", "alice"}, SearchTerms(`"cute cats" alice`))
+	// An unterminated quote still groups the rest as a phrase
+	assert.Equal(t, []string{"alice", "cute cats"}, SearchTerms(`alice "cute cats`))
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// Self-test stage outcomes
+const (
+	SelfTestPassed  = "passed"
+	SelfTestFailed  = "failed"
+	SelfTestSkipped = "skipped" // The stage does not apply or is not configured
+)
+
+// Self-test stages, in the order they run
+const (
+	SelfTestDatabase    = "database"    // The queue database answers
+	SelfTestDirectories = "directories" // incoming/ and completed/ are writable
+	SelfTestBinary      = "binary"      // The platform's tools are installed
+	SelfTestCookies     = "cookies"     // The platform's cookies are valid (X only)
+	SelfTestSimulate    = "simulate"    // The media of selftest.urls is listed
+)
+
+// SelfTestStage is the outcome of one stage of the self-test
+type SelfTestStage struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// SelfTestPlatform holds the stages run for one platform
+type SelfTestPlatform struct {
+	Platform Platform        `json:"platform"`
+	URL      string          `json:"url,omitempty"` // URL of the simulate stage
+	Passed   bool            `json:"passed"`
+	Stages   []SelfTestStage `json:"stages"`
+}
+
+// SelfTestResult is the result of POST /api/v1/admin/selftest. It passes
+// when no stage failed; skipped stages don't fail it.
+type SelfTestResult struct {
+	Passed     bool               `json:"passed"`
+	StartedAt  time.Time          `json:"started_at"`
+	DurationMs int64              `json:"duration_ms"`
+	Stages     []SelfTestStage    `json:"stages"` // Stages shared by every platform
+	Platforms  []SelfTestPlatform `json:"platforms"`
+}
+
+// StagesPassed reports whether none of stages failed
+func StagesPassed(stages []SelfTestStage) bool {
+	for _, stage := range stages {
+		if stage.Status == SelfTestFailed {
+			return false
+		}
+	}
+	return true
+}
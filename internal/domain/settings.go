@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RuntimeSettings are the settings that can be changed while the server is
+// running (GET/PATCH /api/v1/settings). Durations use Go syntax, e.g. "30s".
+type RuntimeSettings struct {
+	CheckInterval        string `json:"check_interval"`         // queue.check_interval
+	AutoExitOnEmpty      bool   `json:"auto_exit_on_empty"`     // queue.auto_exit_on_empty
+	EmptyWaitTime        string `json:"empty_wait_time"`        // queue.empty_wait_time
+	DeferExitForClients  bool   `json:"defer_exit_for_clients"` // queue.defer_exit_for_clients
+	MaxRetries           int    `json:"max_retries"`            // download.max_retries
+	RetryDelay           string `json:"retry_delay"`            // download.retry_delay
+	PlatformConcurrency  int    `json:"platform_concurrency"`   // download.platform_concurrency
+	NotificationsEnabled bool   `json:"notifications_enabled"`  // notification.enabled
+	NotificationSound    bool   `json:"notification_sound"`     // notification.sound
+
+	RateLimit          string            `json:"rate_limit"`           // download.rate_limit
+	RateLimitOverrides map[string]string `json:"rate_limit_overrides"` // download.rate_limit_overrides
+}
+
+// RuntimeSettingsFrom returns the runtime settings of config
+func RuntimeSettingsFrom(config *Config) RuntimeSettings {
+	return RuntimeSettings{
+		CheckInterval:        config.Queue.CheckInterval.String(),
+		AutoExitOnEmpty:      config.Queue.AutoExitOnEmpty,
+		EmptyWaitTime:        config.Queue.EmptyWaitTime.String(),
+		DeferExitForClients:  config.Queue.DeferExitForClients,
+		MaxRetries:           config.Download.MaxRetries,
+		RetryDelay:           config.Download.RetryDelay.String(),
+		PlatformConcurrency:  config.Download.PlatformConcurrency,
+		NotificationsEnabled: config.Notification.Enabled,
+		NotificationSound:    config.Notification.Sound,
+		RateLimit:            config.Download.RateLimit,
+		RateLimitOverrides:   config.Download.RateLimitOverrides,
+	}
+}
+
+// RuntimeSettingsPatch is a partial update of RuntimeSettings; nil fields are
+// left unchanged
+type RuntimeSettingsPatch struct {
+	CheckInterval        *string `json:"check_interval,omitempty"`
+	AutoExitOnEmpty      *bool   `json:"auto_exit_on_empty,omitempty"`
+	EmptyWaitTime        *string `json:"empty_wait_time,omitempty"`
+	DeferExitForClients  *bool   `json:"defer_exit_for_clients,omitempty"`
+	MaxRetries           *int    `json:"max_retries,omitempty"`
+	RetryDelay           *string `json:"retry_delay,omitempty"`
+	PlatformConcurrency  *int    `json:"platform_concurrency,omitempty"`
+	NotificationsEnabled *bool   `json:"notifications_enabled,omitempty"`
+	NotificationSound    *bool   `json:"notification_sound,omitempty"`
+
+	RateLimit          *string            `json:"rate_limit,omitempty"`
+	RateLimitOverrides *map[string]string `json:"rate_limit_overrides,omitempty"` // Replaces all overrides
+}
+
+// Patch returns the patch that sets every runtime setting to its value in s
+func (s RuntimeSettings) Patch() RuntimeSettingsPatch {
+	overrides := s.RateLimitOverrides
+	if overrides == nil {
+		overrides = map[string]string{}
+	}
+	return RuntimeSettingsPatch{
+		CheckInterval:        &s.CheckInterval,
+		AutoExitOnEmpty:      &s.AutoExitOnEmpty,
+		EmptyWaitTime:        &s.EmptyWaitTime,
+		DeferExitForClients:  &s.DeferExitForClients,
+		MaxRetries:           &s.MaxRetries,
+		RetryDelay:           &s.RetryDelay,
+		PlatformConcurrency:  &s.PlatformConcurrency,
+		NotificationsEnabled: &s.NotificationsEnabled,
+		NotificationSound:    &s.NotificationSound,
+		RateLimit:            &s.RateLimit,
+		RateLimitOverrides:   &overrides,
+	}
+}
+
+// ChangesFrom returns the patch that turns current into s: the fields where
+// they differ
+func (s RuntimeSettings) ChangesFrom(current RuntimeSettings) RuntimeSettingsPatch {
+	var patch RuntimeSettingsPatch
+	if s.CheckInterval != current.CheckInterval {
+		patch.CheckInterval = &s.CheckInterval
+	}
+	if s.AutoExitOnEmpty != current.AutoExitOnEmpty {
+		patch.AutoExitOnEmpty = &s.AutoExitOnEmpty
+	}
+	if s.EmptyWaitTime != current.EmptyWaitTime {
+		patch.EmptyWaitTime = &s.EmptyWaitTime
+	}
+	if s.DeferExitForClients != current.DeferExitForClients {
+		patch.DeferExitForClients = &s.DeferExitForClients
+	}
+	if s.MaxRetries != current.MaxRetries {
+		patch.MaxRetries = &s.MaxRetries
+	}
+	if s.RetryDelay != current.RetryDelay {
+		patch.RetryDelay = &s.RetryDelay
+	}
+	if s.PlatformConcurrency != current.PlatformConcurrency {
+		patch.PlatformConcurrency = &s.PlatformConcurrency
+	}
+	if s.NotificationsEnabled != current.NotificationsEnabled {
+		patch.NotificationsEnabled = &s.NotificationsEnabled
+	}
+	if s.NotificationSound != current.NotificationSound {
+		patch.NotificationSound = &s.NotificationSound
+	}
+	if s.RateLimit != current.RateLimit {
+		patch.RateLimit = &s.RateLimit
+	}
+	if !sameStringMaps(s.RateLimitOverrides, current.RateLimitOverrides) {
+		overrides := s.RateLimitOverrides
+		if overrides == nil {
+			overrides = map[string]string{}
+		}
+		patch.RateLimitOverrides = &overrides
+	}
+	return patch
+}
+
+// sameStringMaps reports whether a and b hold the same entries; nil and empty
+// maps are the same
+func sameStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// maxPlatformConcurrency bounds download.platform_concurrency; each download
+// runs its own yt-dlp, tdl or gallery-dl process
+const maxPlatformConcurrency = 16
+
+// ConfigValues validates the patch and returns the config keys it sets, e.g.
+// "queue.check_interval", with durations in Go syntax
+func (p RuntimeSettingsPatch) ConfigValues() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if p.CheckInterval != nil {
+		d, err := time.ParseDuration(*p.CheckInterval)
+		if err != nil || d < 100*time.Millisecond {
+			return nil, fmt.Errorf("invalid check_interval %q (at least 100ms)", *p.CheckInterval)
+		}
+		values["queue.check_interval"] = d.String()
+	}
+	if p.AutoExitOnEmpty != nil {
+		values["queue.auto_exit_on_empty"] = *p.AutoExitOnEmpty
+	}
+	if p.EmptyWaitTime != nil {
+		d, err := time.ParseDuration(*p.EmptyWaitTime)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid empty_wait_time %q", *p.EmptyWaitTime)
+		}
+		values["queue.empty_wait_time"] = d.String()
+	}
+	if p.DeferExitForClients != nil {
+		values["queue.defer_exit_for_clients"] = *p.DeferExitForClients
+	}
+	if p.MaxRetries != nil {
+		if *p.MaxRetries < 0 {
+			return nil, fmt.Errorf("invalid max_retries: %d", *p.MaxRetries)
+		}
+		values["download.max_retries"] = *p.MaxRetries
+	}
+	if p.RetryDelay != nil {
+		d, err := time.ParseDuration(*p.RetryDelay)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid retry_delay %q", *p.RetryDelay)
+		}
+		values["download.retry_delay"] = d.String()
+	}
+	if p.PlatformConcurrency != nil {
+		if *p.PlatformConcurrency < 1 || *p.PlatformConcurrency > maxPlatformConcurrency {
+			return nil, fmt.Errorf("invalid platform_concurrency: %d (1-%d)", *p.PlatformConcurrency, maxPlatformConcurrency)
+		}
+		values["download.platform_concurrency"] = *p.PlatformConcurrency
+	}
+	if p.RateLimit != nil || p.RateLimitOverrides != nil {
+		var rateLimit string
+		var overrides map[string]string
+		if p.RateLimit != nil {
+			rateLimit = *p.RateLimit
+		}
+		if p.RateLimitOverrides != nil {
+			overrides = *p.RateLimitOverrides
+		}
+		if err := ValidateRateLimits(rateLimit, overrides); err != nil {
+			return nil, err
+		}
+		if p.RateLimit != nil {
+			values["download.rate_limit"] = *p.RateLimit
+		}
+		if p.RateLimitOverrides != nil {
+			values["download.rate_limit_overrides"] = overrides
+		}
+	}
+	if p.NotificationsEnabled != nil {
+		values["notification.enabled"] = *p.NotificationsEnabled
+	}
+	if p.NotificationSound != nil {
+		values["notification.sound"] = *p.NotificationSound
+	}
+	return values, nil
+}
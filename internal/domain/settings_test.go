@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeSettingsPatch_ConfigValues(t *testing.T) {
+	interval, delay, retries, sound := "1m30s", "45s", 5, false
+	values, err := RuntimeSettingsPatch{
+		CheckInterval:     &interval,
+		RetryDelay:        &delay,
+		MaxRetries:        &retries,
+		NotificationSound: &sound,
+	}.ConfigValues()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"queue.check_interval": "1m30s",
+		"download.retry_delay": "45s",
+		"download.max_retries": 5,
+		"notification.sound":   false,
+	}, values)
+
+	values, err = RuntimeSettingsPatch{}.ConfigValues()
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	tooMany := 100
+	_, err = RuntimeSettingsPatch{PlatformConcurrency: &tooMany}.ConfigValues()
+	assert.Error(t, err)
+}
+
+func TestRuntimeSettingsFrom(t *testing.T) {
+	settings := RuntimeSettingsFrom(DefaultConfig())
+	assert.Equal(t, 3, settings.MaxRetries)
+	assert.Equal(t, "30s", settings.RetryDelay)
+	assert.Equal(t, 1, settings.PlatformConcurrency)
+	assert.True(t, settings.NotificationsEnabled)
+	assert.True(t, settings.DeferExitForClients)
+}
+
+func TestRuntimeSettings_ChangesFrom(t *testing.T) {
+	current := RuntimeSettingsFrom(DefaultConfig())
+	assert.Equal(t, RuntimeSettingsPatch{}, current.ChangesFrom(current))
+
+	changed := current
+	changed.MaxRetries = 7
+	changed.RateLimitOverrides = map[string]string{"telegram": "1M"}
+	patch := changed.ChangesFrom(current)
+	require.NotNil(t, patch.MaxRetries)
+	assert.Equal(t, 7, *patch.MaxRetries)
+	require.NotNil(t, patch.RateLimitOverrides)
+	assert.Equal(t, map[string]string{"telegram": "1M"}, *patch.RateLimitOverrides)
+	assert.Nil(t, patch.CheckInterval)
+
+	cleared := current
+	cleared.RateLimitOverrides = nil
+	patch = cleared.ChangesFrom(changed)
+	require.NotNil(t, patch.RateLimitOverrides)
+	assert.Empty(t, *patch.RateLimitOverrides)
+}
+
+func TestRuntimeSettings_Patch(t *testing.T) {
+	values, err := RuntimeSettingsFrom(DefaultConfig()).Patch().ConfigValues()
+	require.NoError(t, err)
+	assert.Len(t, values, 11, "every runtime setting")
+	assert.Equal(t, "30s", values["download.retry_delay"])
+	assert.Equal(t, map[string]string{}, values["download.rate_limit_overrides"])
+}
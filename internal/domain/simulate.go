@@ -0,0 +1,46 @@
+package domain
+
+import "context"
+
+// Simulator is implemented by downloaders that can show what a download would
+// fetch without downloading anything (x-extract test-url)
+type Simulator interface {
+	// Simulate runs the download's tool in a mode that only lists the media
+	// (yt-dlp --simulate, tdl chat export) and reports it with the command
+	// line and settings the download would use. A failed listing is reported
+	// in the simulation; the error is for downloads that cannot be simulated.
+	Simulate(ctx context.Context, download *Download) (*Simulation, error)
+}
+
+// Simulation is what a download would do
+type Simulation struct {
+	URL       string            `json:"url"`
+	Platform  Platform          `json:"platform"`
+	Mode      DownloadMode      `json:"mode"`
+	Tool      string            `json:"tool"`                 // External tool the download would run
+	Command   string            `json:"command"`              // Command line of the download
+	Probe     string            `json:"probe"`                // Command line run to list the media
+	Config    map[string]string `json:"config"`               // Settings applied to the download
+	Items     []SimulatedItem   `json:"items"`                // Media the download would fetch
+	Error     string            `json:"error,omitempty"`      // Why the media could not be listed
+	ErrorCode ErrorCode         `json:"error_code,omitempty"` // Classification of Error
+}
+
+// TotalSize returns the summed size of the items whose size is known
+func (s *Simulation) TotalSize() int64 {
+	var total int64
+	for _, item := range s.Items {
+		total += item.Size
+	}
+	return total
+}
+
+// SimulatedItem is a file a download would fetch, as far as the tool reports it
+type SimulatedItem struct {
+	ID       string `json:"id,omitempty"` // Tweet or message ID
+	URL      string `json:"url,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Uploader string `json:"uploader,omitempty"`
+	Filename string `json:"filename,omitempty"` // Name the tool would give the file, before filename_template
+	Size     int64  `json:"size,omitempty"`     // Size in bytes when known, possibly approximate
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Days of download history GET /api/v1/downloads/stats/history returns by
+// default and at most
+const (
+	DefaultStatsHistoryDays = 30
+	MaxStatsHistoryDays     = 366
+)
+
+// ValidateStatsHistoryDays checks the number of days of a stats history
+func ValidateStatsHistoryDays(days int) error {
+	if days < 1 || days > MaxStatsHistoryDays {
+		return fmt.Errorf("invalid days: %d (1-%d)", days, MaxStatsHistoryDays)
+	}
+	return nil
+}
+
+// DayCounts counts the downloads of a day
+type DayCounts struct {
+	Added     int64 `json:"added"`     // Downloads created on the day
+	Completed int64 `json:"completed"` // Downloads completed on the day
+	Bytes     int64 `json:"bytes"`     // Size of the files of the downloads completed on the day
+}
+
+// DailyStats is the download activity of one day in local time, in total
+// and by platform (platforms without activity that day are left out)
+type DailyStats struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	DayCounts
+	Platforms map[Platform]DayCounts `json:"platforms"`
+}
+
+// StatsHistory is the download activity per day, oldest first, through today
+type StatsHistory struct {
+	Days    int          `json:"days"`
+	History []DailyStats `json:"history"`
+}
+
+// DownloadActivity is when a download was added and completed, as bucketed
+// by BuildStatsHistory
+type DownloadActivity struct {
+	Platform    Platform
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	FileSize    int64
+}
+
+// StatsHistoryStart returns local midnight of the first day of a history of
+// days days ending on now's day
+func StatsHistoryStart(days int, now time.Time) time.Time {
+	now = now.Local()
+	return time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, now.Location())
+}
+
+// BuildStatsHistory counts the activities per day of a history of days days
+// ending on now's day. Every day is listed, days without activity with zeros.
+func BuildStatsHistory(activities []DownloadActivity, days int, now time.Time) *StatsHistory {
+	start := StatsHistoryStart(days, now)
+	history := &StatsHistory{Days: days, History: make([]DailyStats, days)}
+	index := make(map[string]int, days)
+	for i := range history.History {
+		date := time.Date(start.Year(), start.Month(), start.Day()+i, 0, 0, 0, 0, start.Location()).Format("2006-01-02")
+		history.History[i] = DailyStats{Date: date, Platforms: map[Platform]DayCounts{}}
+		index[date] = i
+	}
+
+	day := func(t time.Time) *DailyStats {
+		if i, ok := index[t.Local().Format("2006-01-02")]; ok {
+			return &history.History[i]
+		}
+		return nil
+	}
+	for _, a := range activities {
+		if d := day(a.CreatedAt); d != nil {
+			counts := d.Platforms[a.Platform]
+			counts.Added++
+			d.Platforms[a.Platform] = counts
+			d.Added++
+		}
+		if a.CompletedAt == nil {
+			continue
+		}
+		if d := day(*a.CompletedAt); d != nil {
+			counts := d.Platforms[a.Platform]
+			counts.Completed++
+			counts.Bytes += a.FileSize
+			d.Platforms[a.Platform] = counts
+			d.Completed++
+			d.Bytes += a.FileSize
+		}
+	}
+	return history
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildStatsHistory(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.Local) }
+	completed := func(d, hour int) *time.Time { t := day(d, hour); return &t }
+
+	history := BuildStatsHistory([]DownloadActivity{
+		{Platform: PlatformX, CreatedAt: day(9, 23), CompletedAt: completed(10, 1), FileSize: 100},
+		{Platform: PlatformX, CreatedAt: day(10, 8), CompletedAt: completed(10, 9), FileSize: 50},
+		{Platform: PlatformTelegram, CreatedAt: day(10, 9)},
+		{Platform: PlatformX, CreatedAt: day(1, 9), CompletedAt: completed(8, 12), FileSize: 7}, // Added before the history
+	}, 3, now)
+
+	assert.Equal(t, 3, history.Days)
+	require.Len(t, history.History, 3)
+	assert.Equal(t, DailyStats{Date: "2026-03-08", DayCounts: DayCounts{Completed: 1, Bytes: 7},
+		Platforms: map[Platform]DayCounts{PlatformX: {Completed: 1, Bytes: 7}}}, history.History[0])
+	assert.Equal(t, DailyStats{Date: "2026-03-09", DayCounts: DayCounts{Added: 1},
+		Platforms: map[Platform]DayCounts{PlatformX: {Added: 1}}}, history.History[1])
+	assert.Equal(t, DailyStats{Date: "2026-03-10", DayCounts: DayCounts{Added: 2, Completed: 2, Bytes: 150},
+		Platforms: map[Platform]DayCounts{
+			PlatformX:        {Added: 1, Completed: 2, Bytes: 150},
+			PlatformTelegram: {Added: 1},
+		}}, history.History[2])
+}
+
+func TestValidateStatsHistoryDays(t *testing.T) {
+	assert.NoError(t, ValidateStatsHistoryDays(1))
+	assert.NoError(t, ValidateStatsHistoryDays(MaxStatsHistoryDays))
+	assert.Error(t, ValidateStatsHistoryDays(0))
+	assert.Error(t, ValidateStatsHistoryDays(MaxStatsHistoryDays+1))
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MinSubscriptionInterval is the shortest interval a subscription may be
+// checked at
+const MinSubscriptionInterval = time.Minute
+
+// SubscriptionFromStart is the cursor of a subscription that enqueues every
+// post of its source, oldest first, instead of only those published after it
+// was created
+const SubscriptionFromStart = "0"
+
+// Subscription follows a Telegram channel or an X account: every Interval
+// the posts newer than Cursor are listed and those with media enqueued as
+// downloads. Unlike a Schedule it runs at a fixed interval and, unless
+// created with backfill, starts after the newest post already known.
+type Subscription struct {
+	ID            string     `json:"id" gorm:"primaryKey"`
+	Name          string     `json:"name,omitempty"`
+	SourceURL     string     `json:"source_url" gorm:"not null"`           // Channel URL (https://t.me/<name> or https://t.me/c/<id>) or account URL (https://x.com/<user>)
+	Platform      Platform   `json:"platform" gorm:"not null"`             // Source platform: telegram or x
+	Source        string     `json:"source" gorm:"not null;index"`         // Channel username or ID, as in message URLs; lowercase X username
+	Profile       string     `json:"profile,omitempty"`                    // Telegram: tdl profile to list and download with (empty = telegram.profile)
+	Interval      string     `json:"interval" gorm:"not null"`             // How often the source is checked, e.g. 15m
+	Enabled       bool       `json:"enabled"`                              // Disabled subscriptions are kept but never checked
+	Cursor        string     `json:"cursor,omitempty"`                     // Newest message/tweet ID already enqueued; empty until the first check
+	LastCheckAt   *time.Time `json:"last_check_at,omitempty"`              // When the source was last checked
+	NextCheckAt   *time.Time `json:"next_check_at,omitempty" gorm:"index"` // When the next check is due
+	LastError     string     `json:"last_error,omitempty"`                 // Error of the last check, empty on success
+	LastEnqueued  int        `json:"last_enqueued"`                        // Downloads enqueued by the last check
+	TotalEnqueued int        `json:"total_enqueued"`                       // Downloads enqueued since the subscription was created
+	Checks        int        `json:"checks"`                               // Checks since the subscription was created
+	FailedChecks  int        `json:"failed_checks"`                        // Checks that ended with an error
+	LastNewAt     *time.Time `json:"last_new_at,omitempty"`                // When a check last enqueued a new post
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// NewSubscription creates an enabled subscription to the Telegram channel of
+// sourceURL (a channel or message link) or the X account of sourceURL (a
+// profile link), due now. profile is the tdl profile of a Telegram channel.
+// With backfill every post of the source is enqueued; otherwise only those
+// published after the newest post already known.
+func NewSubscription(sourceURL, profile, interval string, backfill bool) (*Subscription, error) {
+	s := &Subscription{
+		ID:       uuid.New().String()[:8],
+		Platform: DetectPlatform(sourceURL),
+		Profile:  profile,
+		Interval: interval,
+		Enabled:  true,
+	}
+	switch s.Platform {
+	case PlatformTelegram:
+		channel := TelegramURLChannel(sourceURL)
+		if channel == "" || channel == "joinchat" || strings.HasPrefix(channel, "+") {
+			return nil, fmt.Errorf("URL names no Telegram channel: %s", sourceURL)
+		}
+		s.Source = channel
+		s.SourceURL = TelegramURLWithChannel("https://t.me/"+channel, channel)
+	case PlatformX:
+		username := XProfileUsername(sourceURL)
+		if username == "" {
+			return nil, fmt.Errorf("URL names no X account: %s", sourceURL)
+		}
+		if profile != "" {
+			return nil, fmt.Errorf("profile applies to Telegram subscriptions only")
+		}
+		s.Source = strings.ToLower(username)
+		s.SourceURL = "https://x.com/" + username
+	default:
+		return nil, fmt.Errorf("subscriptions support Telegram channels and X accounts, got: %s", sourceURL)
+	}
+	if _, err := ParseSubscriptionInterval(interval); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.NextCheckAt = &now
+	if backfill {
+		s.Cursor = SubscriptionFromStart
+	}
+	return s, nil
+}
+
+// ParseSubscriptionInterval parses the interval of a subscription, at least
+// MinSubscriptionInterval
+func ParseSubscriptionInterval(interval string) (time.Duration, error) {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", interval, err)
+	}
+	if d < MinSubscriptionInterval {
+		return 0, fmt.Errorf("interval %s is shorter than %s", interval, MinSubscriptionInterval)
+	}
+	return d, nil
+}
+
+// Checked records a check finished at t and schedules the next one an
+// interval later
+func (s *Subscription) Checked(t time.Time) {
+	s.LastCheckAt = &t
+	next := t.Add(MinSubscriptionInterval)
+	if d, err := ParseSubscriptionInterval(s.Interval); err == nil {
+		next = t.Add(d)
+	}
+	s.NextCheckAt = &next
+}
+
+// IsDue checks if an enabled subscription should be checked at t
+func (s *Subscription) IsDue(t time.Time) bool {
+	return s.Enabled && s.NextCheckAt != nil && !s.NextCheckAt.After(t)
+}
+
+// SameSource reports whether s follows the same source as other, with the
+// same tdl profile
+func (s *Subscription) SameSource(other *Subscription) bool {
+	return s.Platform == other.Platform && s.Source == other.Source && s.Profile == other.Profile
+}
+
+// DuplicateSubscriptionError is returned when subscribing to a source that
+// already has a subscription with the same tdl profile
+type DuplicateSubscriptionError struct {
+	Existing *Subscription
+}
+
+// Error implements error
+func (e *DuplicateSubscriptionError) Error() string {
+	return fmt.Sprintf("already subscribed to %s as %s", e.Existing.Source, e.Existing.ID)
+}
+
+// SubscriptionRepository defines the interface for subscription persistence
+type SubscriptionRepository interface {
+	// CreateSubscription creates a new subscription
+	CreateSubscription(subscription *Subscription) error
+
+	// UpdateSubscription updates an existing subscription
+	UpdateSubscription(subscription *Subscription) error
+
+	// DeleteSubscription deletes a subscription by ID
+	DeleteSubscription(id string) error
+
+	// FindSubscriptionByID finds a subscription by ID
+	// Returns nil if not found
+	FindSubscriptionByID(id string) (*Subscription, error)
+
+	// FindAllSubscriptions returns all subscriptions ordered by creation time
+	FindAllSubscriptions() ([]*Subscription, error)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSubscription(t *testing.T) {
+	s, err := NewSubscription("https://t.me/somechannel/123", "", "15m", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://t.me/somechannel", s.SourceURL)
+	assert.Equal(t, "somechannel", s.Source)
+	assert.Equal(t, PlatformTelegram, s.Platform)
+	assert.True(t, s.Enabled)
+	assert.Empty(t, s.Cursor)
+	assert.True(t, s.IsDue(time.Now()))
+
+	s, err = NewSubscription("https://t.me/c/1234567890/5/100", "work", "1h", true)
+	require.NoError(t, err)
+	assert.Equal(t, "https://t.me/c/1234567890", s.SourceURL)
+	assert.Equal(t, "1234567890", s.Source)
+	assert.Equal(t, "work", s.Profile)
+	assert.Equal(t, SubscriptionFromStart, s.Cursor)
+
+	s, err = NewSubscription("https://x.com/SomeUser/media", "", "1h", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://x.com/SomeUser", s.SourceURL)
+	assert.Equal(t, "someuser", s.Source)
+	assert.Equal(t, PlatformX, s.Platform)
+
+	_, err = NewSubscription("https://x.com/someuser/status/123", "", "15m", false)
+	assert.Error(t, err)
+	_, err = NewSubscription("https://x.com/someuser", "work", "15m", false)
+	assert.Error(t, err, "tdl profiles are Telegram only")
+	_, err = NewSubscription("https://www.instagram.com/someuser", "", "15m", false)
+	assert.Error(t, err)
+	_, err = NewSubscription("https://t.me/+AbCdEf", "", "15m", false)
+	assert.Error(t, err)
+	_, err = NewSubscription("https://t.me/somechannel", "", "30s", false)
+	assert.Error(t, err)
+	_, err = NewSubscription("https://t.me/somechannel", "", "often", false)
+	assert.Error(t, err)
+}
+
+func TestSubscription_Checked(t *testing.T) {
+	s := &Subscription{Interval: "30m", Enabled: true}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.Checked(now)
+
+	assert.Equal(t, now, *s.LastCheckAt)
+	assert.Equal(t, now.Add(30*time.Minute), *s.NextCheckAt)
+	assert.False(t, s.IsDue(now.Add(29*time.Minute)))
+	assert.True(t, s.IsDue(now.Add(30*time.Minute)))
+
+	s.Enabled = false
+	assert.False(t, s.IsDue(now.Add(time.Hour)))
+}
@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+// Files of a support bundle (x-extract-cli support-bundle), a zip of what is
+// needed to debug an installation. Secrets are redacted from all of them.
+const (
+	SupportBundleManifest    = "manifest.json"    // SupportBundleInfo
+	SupportBundleConfig      = "config.json"      // Every config key with its effective value and source
+	SupportBundleEnvironment = "environment.json" // SupportEnvironment
+	SupportBundleDatabase    = "database.json"    // SupportDatabase
+	SupportBundleTools       = "tools.json"       // []SupportTool
+	SupportBundleServer      = "server.json"      // GET /health of the running server, when it runs
+	SupportBundleLogsDir     = "logs/"            // Tails of the recent log files of all categories
+)
+
+// SupportBundleInfo describes a support bundle: when and by which build it
+// was made, its files, and the parts that could not be collected
+type SupportBundleInfo struct {
+	CreatedAt time.Time         `json:"created_at"`
+	Build     BuildInfo         `json:"build"`
+	LogDays   int               `json:"log_days"` // Days of logs included, today included
+	Files     []string          `json:"files"`
+	Errors    map[string]string `json:"errors,omitempty"` // Why a file is missing or incomplete, by file
+}
+
+// SupportEnvironment is the system the bundle was made on
+type SupportEnvironment struct {
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+	CPUs       int      `json:"cpus"`
+	Docker     bool     `json:"docker"`
+	Timezone   string   `json:"timezone"`
+	ConfigPath string   `json:"config_path"`
+	UserConfig string   `json:"user_config"` // base_dir/config/config.yaml
+	BaseDir    string   `json:"base_dir"`
+	FreeBytes  int64    `json:"free_bytes"`     // Free space on the disk of base_dir (-1 = unknown)
+	EnvVars    []string `json:"env_vars"`       // Names of the X-Extract environment variables set (values left out)
+	ServerURL  string   `json:"server_url"`     // Where the CLI looks for the server
+	ServerUp   bool     `json:"server_running"` // Whether the server answered
+}
+
+// SupportDatabase is the state of the queue database
+type SupportDatabase struct {
+	Path           string           `json:"path"`
+	SizeBytes      int64            `json:"size_bytes"`
+	WALBytes       int64            `json:"wal_bytes"` // Size of the write-ahead log not yet checkpointed
+	Stats          *DownloadStats   `json:"stats"`
+	History        *StatsHistory    `json:"history"`         // The last week
+	RecentFailures []SupportFailure `json:"recent_failures"` // Newest first
+}
+
+// SupportFailure is a failed download of a support bundle
+type SupportFailure struct {
+	ID           string    `json:"id"`
+	URL          string    `json:"url"`
+	Platform     Platform  `json:"platform"`
+	ErrorCode    ErrorCode `json:"error_code,omitempty"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	RetryCount   int       `json:"retry_count"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SupportTool is an external download tool and the version it reports
+type SupportTool struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Pin     string `json:"version_pin"` // download.<tool>_version
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"` // Why the tool was not found or did not run
+}
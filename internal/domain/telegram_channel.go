@@ -1,16 +1,23 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
 // TelegramChannel represents a Telegram channel with its ID and name mapping
 type TelegramChannel struct {
 	ChannelID     string    `json:"channel_id" gorm:"primaryKey"`
+	Profile       string    `json:"profile,omitempty" gorm:"primaryKey"` // tdl profile whose chat list has it (empty = telegram.profile)
 	ChannelName   string    `json:"channel_name" gorm:"not null"`
 	ChannelType   string    `json:"channel_type" gorm:"default:channel"` // channel, group, private
 	Username      string    `json:"username,omitempty"`                  // Public username if available
 	LastUpdatedAt time.Time `json:"last_updated_at" gorm:"autoUpdateTime"`
+
+	// Set when a sync of the channel list finds the channel renamed
+	PreviousName string     `json:"previous_name,omitempty"` // Name before the last rename
+	RenamedAt    *time.Time `json:"renamed_at,omitempty"`    // When the last rename was detected
 }
 
 // TableName specifies the table name for GORM
@@ -28,6 +35,9 @@ type TelegramChannelRepository interface {
 	// Returns nil if not found
 	GetChannel(channelID string) (*TelegramChannel, error)
 
+	// ListChannels returns all stored channels, ordered by name
+	ListChannels() ([]*TelegramChannel, error)
+
 	// UpdateChannelList updates or inserts multiple channels
 	// channels is a map of channelID -> TelegramChannel
 	UpdateChannelList(channels map[string]*TelegramChannel) error
@@ -43,3 +53,86 @@ type TelegramChannelRepository interface {
 
 // ChannelUpdateMaxAge is the default maximum age before channel list needs updating
 const ChannelUpdateMaxAge = 7 * 24 * time.Hour // 7 days
+
+// ChannelRename is a channel whose name or username changed between two syncs
+// of the channel list
+type ChannelRename struct {
+	ChannelID   string `json:"channel_id"`
+	OldName     string `json:"old_name"`
+	NewName     string `json:"new_name"`
+	OldUsername string `json:"old_username,omitempty"`
+	NewUsername string `json:"new_username,omitempty"`
+}
+
+// ChannelSyncResult summarizes a sync of the stored channel list with the
+// chats tdl lists
+type ChannelSyncResult struct {
+	Channels int             `json:"channels"` // Channels listed by tdl
+	Added    int             `json:"added"`    // Channels that were not stored before
+	Renamed  []ChannelRename `json:"renamed"`  // Channels whose name or username changed
+	SyncedAt time.Time       `json:"synced_at"`
+}
+
+// DiffChannelList compares a fetched channel list with the stored channels.
+// Fetched channels whose name changed get the stored name as PreviousName and
+// now as RenamedAt; the others keep those of their stored record.
+func DiffChannelList(stored []*TelegramChannel, fetched map[string]*TelegramChannel, now time.Time) *ChannelSyncResult {
+	byID := make(map[string]*TelegramChannel, len(stored))
+	for _, channel := range stored {
+		byID[channel.ChannelID] = channel
+	}
+
+	result := &ChannelSyncResult{Channels: len(fetched), Renamed: []ChannelRename{}, SyncedAt: now}
+	for id, channel := range fetched {
+		old, ok := byID[id]
+		if !ok {
+			result.Added++
+			continue
+		}
+		channel.PreviousName, channel.RenamedAt = old.PreviousName, old.RenamedAt
+		if old.ChannelName == channel.ChannelName && old.Username == channel.Username {
+			continue
+		}
+		if old.ChannelName != channel.ChannelName {
+			renamedAt := now
+			channel.PreviousName, channel.RenamedAt = old.ChannelName, &renamedAt
+		}
+		result.Renamed = append(result.Renamed, ChannelRename{
+			ChannelID:   id,
+			OldName:     old.ChannelName,
+			NewName:     channel.ChannelName,
+			OldUsername: old.Username,
+			NewUsername: channel.Username,
+		})
+	}
+	return result
+}
+
+// RenameChannelInMetadata replaces the channel name oldName with newName in
+// Telegram metadata (a download's Metadata or an .info.json sidecar): the
+// channel field, the uploader ("{channel}" or "{channel}_{sender}") and the
+// title ("{sender}_{channel}_{message_id}"), as a download made today would
+// name them. Reports whether anything changed.
+func RenameChannelInMetadata(meta map[string]interface{}, oldName, newName string) bool {
+	if oldName == newName || metadataString(meta, "channel") != oldName {
+		return false
+	}
+	meta["channel"] = newName
+
+	if uploader := metadataString(meta, "uploader"); uploader == oldName {
+		meta["uploader"] = newName
+	} else if sender, ok := strings.CutPrefix(uploader, oldName+"_"); ok {
+		meta["uploader"] = newName + "_" + sender
+	}
+
+	messageID := metadataString(meta, "id")
+	suffix := fmt.Sprintf("_%s_%s", oldName, messageID)
+	if title := metadataString(meta, "title"); messageID != "" && strings.HasSuffix(title, suffix) {
+		sender := strings.TrimSuffix(title, suffix)
+		if sender == oldName {
+			sender = newName
+		}
+		meta["title"] = fmt.Sprintf("%s_%s_%s", sender, newName, messageID)
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TelegramChannelAlias records that a channel continues as another one: a
+// group migrated to a supergroup (new ID), or a public channel that changed
+// its username. Downloads of both count as one source: uploader stats are
+// merged, organize_by folders use the successor, and a message URL of one is
+// a duplicate of the same message URL of the other.
+//
+// Channels are keyed as in their message URLs: the numeric ID of private
+// channels (t.me/c/<id>/...), the username of public ones (t.me/<name>/...).
+type TelegramChannelAlias struct {
+	ChannelID   string    `json:"channel_id" gorm:"primaryKey"`       // Former channel
+	SuccessorID string    `json:"successor_id" gorm:"not null;index"` // Channel it continues as, never itself an alias
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (TelegramChannelAlias) TableName() string {
+	return "telegram_channel_aliases"
+}
+
+// TelegramChannelAliasRepository defines the interface for channel alias persistence
+type TelegramChannelAliasRepository interface {
+	// SaveChannelAlias records that alias.ChannelID continues as
+	// alias.SuccessorID. The successor is resolved to the channel it is
+	// itself an alias of, and aliases of alias.ChannelID move along, so every
+	// stored successor is a channel without an alias.
+	SaveChannelAlias(alias *TelegramChannelAlias) error
+
+	// DeleteChannelAlias removes the alias of a channel. Returns false if it
+	// had none.
+	DeleteChannelAlias(channelID string) (bool, error)
+
+	// ListChannelAliases returns all aliases, ordered by successor
+	ListChannelAliases() ([]*TelegramChannelAlias, error)
+}
+
+// NormalizeTelegramChannelKey turns a channel as users write it (numeric ID,
+// username, @username or a t.me link) into the key used in message URLs
+func NormalizeTelegramChannelKey(channel string) string {
+	channel = strings.TrimSpace(channel)
+	if DetectPlatform(channel) == PlatformTelegram {
+		channel = TelegramURLChannel(channel)
+	}
+	return strings.TrimPrefix(channel, "@")
+}
+
+// ValidateTelegramChannelAlias checks that channelID can become an alias of
+// successorID given the stored aliases: both set, different, and not making
+// the successor an alias of its own alias
+func ValidateTelegramChannelAlias(aliases TelegramChannelAliases, channelID, successorID string) error {
+	if channelID == "" || successorID == "" {
+		return fmt.Errorf("channel and successor are required")
+	}
+	if channelID == successorID {
+		return fmt.Errorf("channel %s cannot be an alias of itself", channelID)
+	}
+	if aliases.Successor(successorID) == channelID {
+		return fmt.Errorf("channel %s is already an alias of %s", successorID, channelID)
+	}
+	return nil
+}
+
+// TelegramChannelAliases maps former channels to their successors
+type TelegramChannelAliases map[string]string
+
+// NewTelegramChannelAliases indexes stored aliases
+func NewTelegramChannelAliases(aliases []*TelegramChannelAlias) TelegramChannelAliases {
+	m := make(TelegramChannelAliases, len(aliases))
+	for _, alias := range aliases {
+		m[alias.ChannelID] = alias.SuccessorID
+	}
+	return m
+}
+
+// Successor returns the channel channelID continues as, or channelID itself
+// when it has no alias. Chains are followed, stopping at a cycle.
+func (m TelegramChannelAliases) Successor(channelID string) string {
+	seen := map[string]bool{channelID: true}
+	for {
+		next, ok := m[channelID]
+		if !ok || seen[next] {
+			return channelID
+		}
+		seen[next] = true
+		channelID = next
+	}
+}
+
+// Group returns every channel counted as the same source as channelID: its
+// successor first, then the successor's aliases in order
+func (m TelegramChannelAliases) Group(channelID string) []string {
+	successor := m.Successor(channelID)
+	var aliases []string
+	for alias := range m {
+		if alias != successor && m.Successor(alias) == successor {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return append([]string{successor}, aliases...)
+}
+
+// TelegramURLChannel returns the channel of a Telegram link as used in
+// aliases: the numeric ID of t.me/c/<id>/... links, else the username.
+// Returns "" when the link names no channel.
+func TelegramURLChannel(url string) string {
+	parts := telegramURLParts(url)
+	if len(parts) > 1 && parts[0] == "c" {
+		return parts[1]
+	}
+	if len(parts) > 0 && parts[0] != "c" {
+		return parts[0]
+	}
+	return ""
+}
+
+// TelegramURLWithChannel returns a Telegram link with its channel replaced
+// by channel, keeping the topic and message. Numeric channels get a private
+// t.me/c/ link. The query string is dropped. Returns "" when the link names
+// no channel.
+func TelegramURLWithChannel(url, channel string) string {
+	parts := telegramURLParts(url)
+	if len(parts) > 0 && parts[0] == "c" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	parts[0] = channel
+	if isNumeric(channel) {
+		parts = append([]string{"c"}, parts...)
+	}
+	return "https://t.me/" + strings.Join(parts, "/")
+}
+
+// telegramURLParts returns the path segments of a Telegram link
+func telegramURLParts(url string) []string {
+	if idx := strings.IndexAny(url, "?#"); idx >= 0 {
+		url = url[:idx]
+	}
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	if len(parts) < 2 {
+		return nil
+	}
+	return parts[1:] // Host
+}
+
+// isNumeric reports whether s is a non-empty string of digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
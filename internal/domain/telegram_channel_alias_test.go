@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelegramChannelAliases(t *testing.T) {
+	aliases := NewTelegramChannelAliases([]*TelegramChannelAlias{
+		{ChannelID: "100", SuccessorID: "300"},
+		{ChannelID: "oldnews", SuccessorID: "300"},
+	})
+
+	assert.Equal(t, "300", aliases.Successor("100"))
+	assert.Equal(t, "300", aliases.Successor("300"))
+	assert.Equal(t, "400", aliases.Successor("400"))
+	assert.Equal(t, []string{"300", "100", "oldnews"}, aliases.Group("oldnews"))
+	assert.Equal(t, []string{"400"}, aliases.Group("400"))
+
+	assert.NoError(t, ValidateTelegramChannelAlias(aliases, "400", "100"))
+	assert.Error(t, ValidateTelegramChannelAlias(aliases, "300", "100"))
+	assert.Error(t, ValidateTelegramChannelAlias(aliases, "300", "300"))
+	assert.Error(t, ValidateTelegramChannelAlias(aliases, "", "300"))
+}
+
+func TestTelegramURLChannel(t *testing.T) {
+	assert.Equal(t, "news", TelegramURLChannel("https://t.me/news/12?single"))
+	assert.Equal(t, "100", TelegramURLChannel("https://t.me/c/100/5/12"))
+	assert.Equal(t, "", TelegramURLChannel("https://t.me"))
+
+	assert.Equal(t, "https://t.me/c/300/12", TelegramURLWithChannel("https://t.me/news/12?single", "300"))
+	assert.Equal(t, "https://t.me/dailynews/5/12", TelegramURLWithChannel("https://t.me/c/100/5/12", "dailynews"))
+
+	assert.Equal(t, "news", NormalizeTelegramChannelKey(" @news "))
+	assert.Equal(t, "100", NormalizeTelegramChannelKey("https://t.me/c/100/12"))
+	assert.Equal(t, "100", NormalizeTelegramChannelKey("100"))
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffChannelList(t *testing.T) {
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	stored := []*TelegramChannel{
+		{ChannelID: "1", ChannelName: "News", Username: "-"},
+		{ChannelID: "2", ChannelName: "Art", Username: "-", PreviousName: "Drawings", RenamedAt: &earlier},
+		{ChannelID: "3", ChannelName: "Music", Username: "music"},
+	}
+	fetched := map[string]*TelegramChannel{
+		"1": {ChannelID: "1", ChannelName: "Daily News", Username: "-"},
+		"2": {ChannelID: "2", ChannelName: "Art", Username: "-"},
+		"3": {ChannelID: "3", ChannelName: "Music", Username: "tunes"},
+		"4": {ChannelID: "4", ChannelName: "Friends", Username: "-"},
+	}
+
+	result := DiffChannelList(stored, fetched, now)
+	assert.Equal(t, 4, result.Channels)
+	assert.Equal(t, 1, result.Added)
+	assert.Len(t, result.Renamed, 2)
+	assert.Contains(t, result.Renamed, ChannelRename{ChannelID: "1", OldName: "News", NewName: "Daily News", OldUsername: "-", NewUsername: "-"})
+	assert.Contains(t, result.Renamed, ChannelRename{ChannelID: "3", OldName: "Music", NewName: "Music", OldUsername: "music", NewUsername: "tunes"})
+
+	assert.Equal(t, "News", fetched["1"].PreviousName)
+	require.NotNil(t, fetched["1"].RenamedAt)
+	assert.Equal(t, now, *fetched["1"].RenamedAt)
+	assert.Equal(t, "Drawings", fetched["2"].PreviousName, "an earlier rename is kept")
+	assert.Empty(t, fetched["3"].PreviousName, "a new username alone is not a rename")
+	assert.Nil(t, fetched["4"].RenamedAt)
+}
+
+func TestRenameChannelInMetadata(t *testing.T) {
+	meta := map[string]interface{}{
+		"id":       "42",
+		"channel":  "News",
+		"uploader": "News_Alice",
+		"title":    "Alice_News_42",
+	}
+	assert.True(t, RenameChannelInMetadata(meta, "News", "Daily News"))
+	assert.Equal(t, "Daily News", meta["channel"])
+	assert.Equal(t, "Daily News_Alice", meta["uploader"])
+	assert.Equal(t, "Alice_Daily News_42", meta["title"])
+
+	// Posts by the channel itself name it as sender too
+	meta = map[string]interface{}{"id": "7", "channel": "News", "uploader": "News", "title": "News_News_7"}
+	assert.True(t, RenameChannelInMetadata(meta, "News", "Daily News"))
+	assert.Equal(t, "Daily News", meta["uploader"])
+	assert.Equal(t, "Daily News_Daily News_7", meta["title"])
+
+	assert.False(t, RenameChannelInMetadata(meta, "Other", "Daily News"), "channel does not match")
+	assert.False(t, RenameChannelInMetadata(meta, "Daily News", "Daily News"))
+}
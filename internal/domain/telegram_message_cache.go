@@ -6,9 +6,11 @@ import (
 
 // TelegramMessageCache represents cached Telegram message metadata
 // This avoids repeatedly calling tdl chat export for the same messages
+// Each tdl profile has its own cache
 type TelegramMessageCache struct {
 	ChannelID  string    `json:"channel_id" gorm:"primaryKey;index:idx_channel_message"` // channel identifier
 	MessageID  string    `json:"message_id" gorm:"primaryKey;index:idx_channel_message"` // message identifier (unique with channel_id)
+	Profile    string    `json:"profile,omitempty" gorm:"primaryKey"`                    // tdl profile that cached it (empty = telegram.profile)
 	Text       string    `json:"text" gorm:"type:text"`                                  // message text/description
 	Date       int64     `json:"date"`                                                   // message timestamp (for smart incremental export)
 	SenderID   string    `json:"sender_id,omitempty"`                                    // sender user ID
@@ -42,6 +44,10 @@ type TelegramMessageCacheRepository interface {
 	// Returns 0 if no messages are cached
 	GetMaxDate(channelID string) (int64, error)
 
+	// GetMaxMessageID gets the highest cached message ID of a channel
+	// Returns 0 if no messages are cached
+	GetMaxMessageID(channelID string) (int, error)
+
 	// GetCachedMessages returns a map of all cached message IDs for a channel
 	// This is used to filter out already-cached messages during export
 	GetCachedMessages(channelID string) (map[string]bool, error)
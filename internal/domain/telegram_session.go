@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+)
+
+// TelegramSession records the last verification of a tdl login: whether the
+// stored session of a profile could list chats
+type TelegramSession struct {
+	Profile    string    `json:"profile" gorm:"primaryKey"`
+	Verified   bool      `json:"verified"`
+	Chats      int       `json:"chats"`           // Chats listed by the probe
+	Error      string    `json:"error,omitempty"` // Why the probe failed
+	VerifiedAt time.Time `json:"verified_at"`     // When the probe ran
+}
+
+// TableName specifies the table name for GORM
+func (TelegramSession) TableName() string {
+	return "telegram_sessions"
+}
+
+// TelegramSessionRepository defines the interface for Telegram session persistence
+type TelegramSessionRepository interface {
+	// SaveTelegramSession inserts or replaces the session of its profile
+	SaveTelegramSession(session *TelegramSession) error
+
+	// GetTelegramSession returns the session of a profile
+	// Returns nil if the profile was never verified
+	GetTelegramSession(profile string) (*TelegramSession, error)
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Timeline events recorded on status transitions
+const (
+	TimelineQueued      = "queued"
+	TimelineStarted     = "started"
+	TimelineRecording   = "recording"
+	TimelineRetry       = "retry"
+	TimelineCompleted   = "completed"
+	TimelineFailed      = "failed"
+	TimelineCancelled   = "cancelled"
+	TimelineRequeued    = "requeued"
+	TimelineExpired     = "expired"
+	TimelineImported    = "imported"
+	TimelineInterrupted = "interrupted"
+)
+
+// maxTimelineEntries bounds the timeline of downloads that are retried many
+// times; the oldest entries after the first are dropped.
+const maxTimelineEntries = 50
+
+// TimelineEntry is a single status transition of a download
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Timeline is the ordered list of status transitions of a download.
+// It is stored as a JSON text column.
+type Timeline []TimelineEntry
+
+// Value implements driver.Valuer
+func (t Timeline) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (t *Timeline) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported timeline type: %T", value)
+	}
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// add appends an entry, keeping the first (queued) entry and the most recent
+// ones when the timeline exceeds maxTimelineEntries
+func (t *Timeline) add(event, message string) {
+	*t = append(*t, TimelineEntry{Time: time.Now(), Event: event, Message: message})
+	if len(*t) > maxTimelineEntries {
+		trimmed := append(Timeline{(*t)[0]}, (*t)[len(*t)-maxTimelineEntries+1:]...)
+		*t = trimmed
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+// UploaderSummary aggregates the downloads of a single uploader/channel.
+// Downloads are grouped by platform and uploader ID, falling back to the
+// uploader name when the ID is unknown.
+type UploaderSummary struct {
+	Key            string    `json:"key"` // Uploader ID, or uploader name when the ID is empty
+	Platform       Platform  `json:"platform"`
+	Uploader       string    `json:"uploader"`    // Name from the most recent download
+	UploaderID     string    `json:"uploader_id"` // Empty when only the name is known
+	DownloadCount  int64     `json:"download_count"`
+	TotalSize      int64     `json:"total_size"` // Sum of FileSize in bytes
+	LatestDownload *Download `json:"latest_download,omitempty"`
+}
+
+// Uploader list sort orders
+const (
+	UploaderSortLatest = "latest" // Most recent download first (default)
+	UploaderSortCount  = "count"  // Most downloads first
+	UploaderSortSize   = "size"   // Largest total size first
+	UploaderSortName   = "name"   // Uploader name A-Z
+)
+
+// ValidUploaderSorts lists the accepted UploaderFilter.Sort values
+var ValidUploaderSorts = map[string]bool{
+	UploaderSortLatest: true,
+	UploaderSortCount:  true,
+	UploaderSortSize:   true,
+	UploaderSortName:   true,
+}
+
+// UploaderFilter narrows and orders an uploader listing
+type UploaderFilter struct {
+	Platform Platform // Empty means all platforms
+	Sort     string   // One of the UploaderSort* constants; empty means latest
+}
+
+// UploaderRepository defines the interface for browsing downloads by uploader
+type UploaderRepository interface {
+	// ListUploaders returns one summary per distinct uploader
+	ListUploaders(filter UploaderFilter) ([]*UploaderSummary, error)
+
+	// FindByUploader returns the downloads of the uploader identified by key
+	// (uploader ID, or name for downloads without an ID), newest first.
+	// An empty platform matches all platforms.
+	FindByUploader(platform Platform, key string) ([]*Download, error)
+}
@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic_ReplacesWithoutLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.info.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"description": "old"}`), 0600))
+
+	require.NoError(t, WriteFileAtomic(path, []byte(`{"description": "new"}`), 0644))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"description": "new"}`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file is renamed into place")
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	assert.Error(t, WriteFileAtomic(filepath.Join(t.TempDir(), "missing", "a.json"), []byte("{}"), 0644))
+}
+
+func TestSyncFiles_SkipsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	media := filepath.Join(dir, "a.mp4")
+	require.NoError(t, os.WriteFile(media, []byte("video"), 0644))
+
+	assert.NoError(t, SyncFiles([]string{media, filepath.Join(dir, "a.info.json")}))
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"strconv"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// BandwidthLimit is embedded by downloaders that throttle their tool to the
+// configured download.rate_limit. The limit is looked up when each download
+// starts, so a limit changed at runtime applies from the next download on.
+type BandwidthLimit struct {
+	rateLimit func(platform domain.Platform) int64
+}
+
+// SetRateLimit sets the lookup of a platform's rate limit in bytes per second
+// (0 = unlimited), typically DownloadManager.RateLimitFor
+func (b *BandwidthLimit) SetRateLimit(rateLimit func(platform domain.Platform) int64) {
+	b.rateLimit = rateLimit
+}
+
+// rateLimitFor returns the rate limit of a platform (0 = unlimited)
+func (b *BandwidthLimit) rateLimitFor(platform domain.Platform) int64 {
+	if b.rateLimit == nil {
+		return 0
+	}
+	return b.rateLimit(platform)
+}
+
+// limitRateArgs returns the --limit-rate flag shared by yt-dlp and gallery-dl,
+// which both take bytes per second
+func limitRateArgs(bytesPerSecond int64) []string {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return []string{"--limit-rate", strconv.FormatInt(bytesPerSecond, 10)}
+}
+
+// tdlThrottleArgs returns the tdl flags used for a throttled download. tdl has
+// no bandwidth cap (its -l/--limit is the number of concurrent tasks), so a
+// rate limit runs the download as a single task on a single thread instead.
+func tdlThrottleArgs(bytesPerSecond int64) []string {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return []string{"--limit", "1", "--threads", "1"}
+}
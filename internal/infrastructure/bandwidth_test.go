@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestBandwidthLimit(t *testing.T) {
+	var b BandwidthLimit
+	assert.Zero(t, b.rateLimitFor(domain.PlatformX), "unlimited until a lookup is set")
+
+	b.SetRateLimit(func(platform domain.Platform) int64 {
+		if platform == domain.PlatformX {
+			return 2 << 20
+		}
+		return 0
+	})
+	assert.Equal(t, []string{"--limit-rate", "2097152"}, limitRateArgs(b.rateLimitFor(domain.PlatformX)))
+	assert.Empty(t, limitRateArgs(b.rateLimitFor(domain.PlatformGallery)))
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// callbackTimeout bounds each POST to a callback URL
+	callbackTimeout = 10 * time.Second
+	// callbackAttempts is how often a callback is tried before it is dropped
+	callbackAttempts = 3
+	// callbackRetryDelay is the wait before the second attempt; it doubles
+	// before each further attempt
+	callbackRetryDelay = 5 * time.Second
+)
+
+// DownloadCallback is the JSON body POSTed to a download's callback URL
+type DownloadCallback struct {
+	Event    string           `json:"event"` // download.completed, download.failed or download.cancelled
+	Download *domain.Download `json:"download"`
+}
+
+// CallbackSender POSTs a download to the callback URL it was added with once
+// it reaches a terminal state. Callbacks are sent in the background and retried
+// on errors and non-2xx responses; a callback that keeps failing is logged and
+// dropped.
+type CallbackSender struct {
+	client     *http.Client
+	logger     *zap.Logger
+	retryDelay time.Duration
+}
+
+// NewCallbackSender creates a callback sender
+func NewCallbackSender(logger *zap.Logger) *CallbackSender {
+	return &CallbackSender{
+		client:     &http.Client{Timeout: callbackTimeout},
+		logger:     logger,
+		retryDelay: callbackRetryDelay,
+	}
+}
+
+// Send posts the download to its callback URL in the background. Downloads
+// without a callback URL are ignored. The body is built before Send returns,
+// so the caller may keep changing download.
+func (s *CallbackSender) Send(download *domain.Download) {
+	if download.CallbackURL == "" {
+		return
+	}
+	payload := *download
+	payload.ProcessLog = "" // Tool output can be megabytes; GET the download for it
+	body, err := json.Marshal(DownloadCallback{Event: "download." + string(download.Status), Download: &payload})
+	if err != nil {
+		s.logger.Error("Failed to encode download callback", zap.String("id", download.ID), zap.Error(err))
+		return
+	}
+	go s.deliver(download.ID, download.CallbackURL, body)
+}
+
+// deliver posts body to callbackURL, retrying failed attempts
+func (s *CallbackSender) deliver(id, callbackURL string, body []byte) {
+	delay := s.retryDelay
+	var err error
+	for attempt := 1; attempt <= callbackAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		if err = s.post(callbackURL, body); err == nil {
+			s.logger.Info("Download callback sent", zap.String("id", id), zap.String("callback_url", callbackURL))
+			return
+		}
+		s.logger.Warn("Download callback failed",
+			zap.String("id", id),
+			zap.String("callback_url", callbackURL),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+	}
+	s.logger.Error("Giving up on download callback",
+		zap.String("id", id),
+		zap.String("callback_url", callbackURL),
+		zap.Error(err))
+}
+
+func (s *CallbackSender) post(callbackURL string, body []byte) error {
+	resp, err := s.client.Post(callbackURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
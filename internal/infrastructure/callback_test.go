@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestCallbackSender_RetriesUntilAccepted(t *testing.T) {
+	received := make(chan DownloadCallback, 1)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var callback DownloadCallback
+		json.NewDecoder(r.Body).Decode(&callback)
+		received <- callback
+	}))
+	defer server.Close()
+
+	sender := NewCallbackSender(zap.NewNop())
+	sender.retryDelay = time.Millisecond
+
+	download := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	download.CallbackURL = server.URL
+	download.ProcessLog = "[download] 100%"
+	download.MarkCompleted("/completed/file.mp4")
+	sender.Send(download)
+
+	select {
+	case callback := <-received:
+		assert.Equal(t, "download.completed", callback.Event)
+		require.NotNil(t, callback.Download)
+		assert.Equal(t, download.ID, callback.Download.ID)
+		assert.Equal(t, "/completed/file.mp4", callback.Download.FilePath)
+		assert.Empty(t, callback.Download.ProcessLog, "the process log is left out")
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not delivered")
+	}
+	assert.Equal(t, "[download] 100%", download.ProcessLog, "the download itself is not changed")
+}
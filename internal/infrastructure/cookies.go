@@ -0,0 +1,231 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// cookieProbeTimeout bounds the yt-dlp --simulate request used to validate cookies
+const cookieProbeTimeout = time.Minute
+
+// xAuthCookieNames are the cookies X requires for authenticated requests
+var xAuthCookieNames = []string{"auth_token", "ct0"}
+
+// NetscapeCookie is a single entry of a Netscape/Mozilla cookies.txt file
+type NetscapeCookie struct {
+	Domain  string
+	Path    string
+	Secure  bool
+	Expires time.Time // Zero for session cookies
+	Name    string
+	Value   string
+}
+
+// ParseNetscapeCookies reads a cookies.txt file as exported by browser
+// extensions and used by yt-dlp and gallery-dl. Malformed lines are skipped.
+func ParseNetscapeCookies(path string) ([]NetscapeCookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cookies []NetscapeCookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// "#HttpOnly_" prefixed lines are cookies, other "#" lines are comments
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			continue
+		}
+		cookie := NetscapeCookie{
+			Domain: fields[0],
+			Path:   fields[2],
+			Secure: strings.EqualFold(fields[3], "TRUE"),
+			Name:   fields[5],
+			Value:  fields[6],
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, scanner.Err()
+}
+
+// isXCookieDomain reports whether a cookie domain belongs to X/Twitter
+func isXCookieDomain(domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	return domain == "x.com" || domain == "twitter.com" ||
+		strings.HasSuffix(domain, ".x.com") || strings.HasSuffix(domain, ".twitter.com")
+}
+
+// checkXCookieFile inspects an X cookie file without network access: the
+// auth cookies must be present and not expired. The status is CookieOK when
+// nothing is wrong.
+func checkXCookieFile(cookieFile string, warnWithin time.Duration, now time.Time) domain.CookieStatus {
+	status := domain.CookieStatus{
+		Platform:   domain.PlatformX,
+		CookieFile: cookieFile,
+		CheckedAt:  now,
+	}
+	if cookieFile == "" {
+		status.State = domain.CookieMissing
+		status.Message = "no cookie file configured"
+		return status
+	}
+
+	info, err := os.Stat(cookieFile)
+	if err != nil {
+		status.State = domain.CookieMissing
+		status.Message = fmt.Sprintf("cookie file not found: %s", cookieFile)
+		return status
+	}
+	modified := info.ModTime()
+	status.LastModified = &modified
+
+	cookies, err := ParseNetscapeCookies(cookieFile)
+	if err != nil {
+		status.State = domain.CookieInvalid
+		status.Message = fmt.Sprintf("failed to read cookie file: %v", err)
+		return status
+	}
+
+	found := make(map[string]bool)
+	for _, cookie := range cookies {
+		if !isXCookieDomain(cookie.Domain) || !isXAuthCookie(cookie.Name) {
+			continue
+		}
+		found[cookie.Name] = true
+		if cookie.Expires.IsZero() {
+			continue
+		}
+		expires := cookie.Expires
+		if status.ExpiresAt == nil || expires.Before(*status.ExpiresAt) {
+			status.ExpiresAt = &expires
+		}
+		if cookie.Name == "auth_token" {
+			status.AuthTokenExpiresAt = &expires
+		}
+	}
+	for _, name := range xAuthCookieNames {
+		if !found[name] {
+			status.State = domain.CookieInvalid
+			status.Message = fmt.Sprintf("cookie file has no %s cookie for x.com", name)
+			return status
+		}
+	}
+
+	switch {
+	case status.ExpiresAt != nil && !status.ExpiresAt.After(now):
+		status.State = domain.CookieExpired
+		status.Message = fmt.Sprintf("auth cookies expired at %s", status.ExpiresAt.Format(time.RFC3339))
+	case status.ExpiresAt != nil && status.ExpiresAt.Sub(now) <= warnWithin:
+		status.State = domain.CookieExpiring
+		status.Message = fmt.Sprintf("auth cookies expire at %s", status.ExpiresAt.Format(time.RFC3339))
+	default:
+		status.State = domain.CookieOK
+	}
+	return status
+}
+
+func isXAuthCookie(name string) bool {
+	for _, authName := range xAuthCookieNames {
+		if name == authName {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCookies implements domain.CookieChecker for the X cookie file. Beyond
+// the expiry check, if twitter.cookie_check_url is set it runs
+// "yt-dlp --simulate" on that tweet with the cookies and reports a failure as
+// CookieInvalid.
+func (d *TwitterDownloader) CheckCookies(ctx context.Context, warnWithin time.Duration) domain.CookieStatus {
+	status := checkXCookieFile(d.config.CookieFile, warnWithin, time.Now())
+	if status.State == domain.CookieMissing || status.State == domain.CookieInvalid || status.State == domain.CookieExpired {
+		return status
+	}
+	if d.config.CookieCheckURL == "" {
+		return status
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, cookieProbeTimeout)
+	defer cancel()
+	args := append([]string{"--simulate", "--no-warnings", "--no-playlist", "--cookies", d.config.CookieFile},
+		ytdlpClientArgs(d.config.Impersonate, d.config.UserAgent)...)
+	cmd := d.ytdlp(probeCtx, append(args, d.config.CookieCheckURL)...)
+	output, err := cmd.CombinedOutput()
+	status.Validated = true
+	if err != nil {
+		status.State = domain.CookieInvalid
+		status.Message = fmt.Sprintf("X rejected the cookies: %s", lastOutputLine(string(output), err))
+	}
+	return status
+}
+
+// ImportCookies implements domain.CookieImporter for the X cookie file
+// (twitter.cookie_file)
+func (d *TwitterDownloader) ImportCookies(data []byte, warnWithin time.Duration) (domain.CookieStatus, error) {
+	return installXCookieFile(data, d.config.CookieFile, warnWithin, time.Now())
+}
+
+// installXCookieFile checks data like checkXCookieFile and, unless the
+// cookies are invalid or expired, atomically replaces cookieFile with it
+// (mode 0600). Rejected cookies leave cookieFile unchanged.
+func installXCookieFile(data []byte, cookieFile string, warnWithin time.Duration, now time.Time) (domain.CookieStatus, error) {
+	if cookieFile == "" {
+		return domain.CookieStatus{}, fmt.Errorf("no cookie file configured (twitter.cookie_file)")
+	}
+	dir := filepath.Dir(cookieFile)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return domain.CookieStatus{}, fmt.Errorf("failed to create cookie directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".import-*.cookie")
+	if err != nil {
+		return domain.CookieStatus{}, fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return domain.CookieStatus{}, fmt.Errorf("failed to write cookie file: %w", err)
+	}
+
+	status := checkXCookieFile(tmp.Name(), warnWithin, now)
+	status.CookieFile = cookieFile
+	if status.State == domain.CookieInvalid || status.State == domain.CookieExpired {
+		return status, &domain.InvalidCookieError{Status: status}
+	}
+	if err := os.Rename(tmp.Name(), cookieFile); err != nil {
+		return domain.CookieStatus{}, fmt.Errorf("failed to replace cookie file: %w", err)
+	}
+	return status, nil
+}
+
+// lastOutputLine returns the last non-empty line of tool output, or err's
+// message if there is none
+func lastOutputLine(output string, err error) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return err.Error()
+}
@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func writeCookieFile(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	content := "# Netscape HTTP Cookie File\n"
+	for _, line := range lines {
+		content += line + "\n"
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func xCookieLine(name string, expires time.Time, httpOnly bool) string {
+	prefix := ""
+	if httpOnly {
+		prefix = "#HttpOnly_"
+	}
+	return fmt.Sprintf("%s.x.com\tTRUE\t/\tTRUE\t%d\t%s\tvalue", prefix, expires.Unix(), name)
+}
+
+func TestParseNetscapeCookies(t *testing.T) {
+	expires := time.Unix(1900000000, 0)
+	path := writeCookieFile(t,
+		xCookieLine("auth_token", expires, true),
+		".x.com\tTRUE\t/\tFALSE\t0\tguest_id\tv1",
+		"malformed line",
+	)
+
+	cookies, err := ParseNetscapeCookies(path)
+	require.NoError(t, err)
+	require.Len(t, cookies, 2)
+	assert.Equal(t, ".x.com", cookies[0].Domain)
+	assert.Equal(t, "auth_token", cookies[0].Name)
+	assert.True(t, cookies[0].Secure)
+	assert.True(t, expires.Equal(cookies[0].Expires))
+	assert.True(t, cookies[1].Expires.IsZero())
+}
+
+func TestCheckXCookieFile(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	warn := 72 * time.Hour
+
+	status := checkXCookieFile(filepath.Join(t.TempDir(), "missing.txt"), warn, now)
+	assert.Equal(t, domain.CookieMissing, status.State)
+
+	status = checkXCookieFile(writeCookieFile(t, xCookieLine("ct0", now.AddDate(1, 0, 0), false)), warn, now)
+	assert.Equal(t, domain.CookieInvalid, status.State)
+	assert.Contains(t, status.Message, "auth_token")
+
+	status = checkXCookieFile(writeCookieFile(t,
+		xCookieLine("auth_token", now.AddDate(1, 0, 0), true),
+		xCookieLine("ct0", now.AddDate(0, 6, 0), false),
+	), warn, now)
+	assert.Equal(t, domain.CookieOK, status.State)
+	require.NotNil(t, status.ExpiresAt)
+	assert.True(t, now.AddDate(0, 6, 0).Equal(*status.ExpiresAt))
+	require.NotNil(t, status.AuthTokenExpiresAt)
+	assert.True(t, now.AddDate(1, 0, 0).Equal(*status.AuthTokenExpiresAt))
+	assert.NotNil(t, status.LastModified)
+
+	status = checkXCookieFile(writeCookieFile(t,
+		xCookieLine("auth_token", now.Add(24*time.Hour), true),
+		xCookieLine("ct0", now.AddDate(1, 0, 0), false),
+	), warn, now)
+	assert.Equal(t, domain.CookieExpiring, status.State)
+
+	status = checkXCookieFile(writeCookieFile(t,
+		xCookieLine("auth_token", now.Add(-time.Hour), true),
+		xCookieLine("ct0", now.AddDate(1, 0, 0), false),
+	), warn, now)
+	assert.Equal(t, domain.CookieExpired, status.State)
+	assert.True(t, status.NeedsAttention())
+}
+
+func TestInstallXCookieFile(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	warn := 72 * time.Hour
+	cookieFile := filepath.Join(t.TempDir(), "x.com", "default.cookie")
+	cookies := func(lines ...string) []byte {
+		data, err := os.ReadFile(writeCookieFile(t, lines...))
+		require.NoError(t, err)
+		return data
+	}
+
+	valid := cookies(
+		xCookieLine("auth_token", now.AddDate(1, 0, 0), true),
+		xCookieLine("ct0", now.AddDate(1, 0, 0), false),
+	)
+	status, err := installXCookieFile(valid, cookieFile, warn, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CookieOK, status.State)
+	assert.Equal(t, cookieFile, status.CookieFile)
+	installed, err := os.ReadFile(cookieFile)
+	require.NoError(t, err)
+	assert.Equal(t, valid, installed)
+	info, err := os.Stat(cookieFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Expired cookies are rejected and the installed file is kept
+	expired := cookies(
+		xCookieLine("auth_token", now.Add(-time.Hour), true),
+		xCookieLine("ct0", now.AddDate(1, 0, 0), false),
+	)
+	status, err = installXCookieFile(expired, cookieFile, warn, now)
+	var invalid *domain.InvalidCookieError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, domain.CookieExpired, status.State)
+	installed, err = os.ReadFile(cookieFile)
+	require.NoError(t, err)
+	assert.Equal(t, valid, installed)
+
+	_, err = installXCookieFile([]byte("not a cookie file"), cookieFile, warn, now)
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, domain.CookieInvalid, invalid.Status.State)
+
+	entries, err := os.ReadDir(filepath.Dir(cookieFile))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"encoding/hex"
+	"os"
+)
+
+// HashFile returns the hex SHA-256 of a file's content
+func HashFile(path string) (string, error) {
+	sum, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// LinkDuplicate replaces duplicate with a hard link to original, which has
+// the same content, freeing the space of the second copy. The link is made
+// next to duplicate and renamed over it, so duplicate is never missing;
+// when linking fails (e.g. the files are on different volumes) it is kept.
+func LinkDuplicate(original, duplicate string) error {
+	originalInfo, err := os.Stat(original)
+	if err != nil {
+		return err
+	}
+	duplicateInfo, err := os.Stat(duplicate)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(originalInfo, duplicateInfo) {
+		return nil
+	}
+
+	tmp := duplicate + ".dedupe"
+	_ = os.Remove(tmp)
+	if err := os.Link(original, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, duplicate); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
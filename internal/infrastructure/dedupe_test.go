@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(file, []byte("hello"), 0644))
+
+	hash, err := HashFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hash)
+
+	_, err = HashFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestLinkDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.mp4")
+	duplicate := filepath.Join(dir, "duplicate.mp4")
+	require.NoError(t, os.WriteFile(original, []byte("video"), 0644))
+	require.NoError(t, os.WriteFile(duplicate, []byte("video"), 0644))
+
+	require.NoError(t, LinkDuplicate(original, duplicate))
+	originalInfo, err := os.Stat(original)
+	require.NoError(t, err)
+	duplicateInfo, err := os.Stat(duplicate)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(originalInfo, duplicateInfo), "the duplicate is a link to the original")
+	assert.NoFileExists(t, duplicate+".dedupe")
+
+	// Linking again is a no-op; removing the original keeps the duplicate
+	require.NoError(t, LinkDuplicate(original, duplicate))
+	require.NoError(t, os.Remove(original))
+	data, err := os.ReadFile(duplicate)
+	require.NoError(t, err)
+	assert.Equal(t, "video", string(data))
+
+	assert.Error(t, LinkDuplicate(original, duplicate), "missing original")
+	assert.FileExists(t, duplicate)
+}
@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// DiskGuard checks that there is room for a download before it starts: free
+// space on the completed directory's volume (and on the incoming directory's,
+// when that is another disk) must stay above a minimum, and base_dir must stay
+// below its quota.
+type DiskGuard struct {
+	completedDir string
+	incomingDir  string
+	baseDir      string
+	minFree      int64 // 0 = no free space check
+	quota        int64 // 0 = no quota
+}
+
+// NewDiskGuard creates a disk guard. A zero minFree or quota disables that check.
+func NewDiskGuard(completedDir, incomingDir, baseDir string, minFree, quota int64) *DiskGuard {
+	return &DiskGuard{
+		completedDir: completedDir,
+		incomingDir:  incomingDir,
+		baseDir:      baseDir,
+		minFree:      minFree,
+		quota:        quota,
+	}
+}
+
+// Check returns a *domain.DiskSpaceError when a download has no room
+func (g *DiskGuard) Check() error {
+	if g.minFree > 0 {
+		dirs := []string{g.completedDir}
+		if g.incomingDir != "" && !SameFileSystem(g.completedDir, g.incomingDir) {
+			dirs = append(dirs, g.incomingDir)
+		}
+		for _, dir := range dirs {
+			free, err := FreeSpace(dir)
+			if err != nil {
+				return fmt.Errorf("failed to check free space: %w", err)
+			}
+			if free < g.minFree {
+				return &domain.DiskSpaceError{Reason: fmt.Sprintf("%s free on the volume of %s, min_free_space is %s",
+					domain.FormatBytes(free), dir, domain.FormatBytes(g.minFree))}
+			}
+		}
+	}
+	if g.quota > 0 {
+		used := DirSize(g.baseDir)
+		// completed_dir and incoming_dir may be placed outside base_dir
+		for _, dir := range []string{g.completedDir, g.incomingDir} {
+			if dir != "" && !isWithin(dir, g.baseDir) {
+				used += DirSize(dir)
+			}
+		}
+		if used >= g.quota {
+			return &domain.DiskSpaceError{Reason: fmt.Sprintf("%s uses %s, quota is %s",
+				g.baseDir, domain.FormatBytes(used), domain.FormatBytes(g.quota))}
+		}
+	}
+	return nil
+}
+
+// isWithin reports whether path is dir or below it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// DirSize returns the total size of the regular files under dir. Unreadable
+// entries are skipped.
+func DirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,63 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestDiskGuard_MinFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	free, err := FreeSpace(dir)
+	require.NoError(t, err)
+	require.Greater(t, free, int64(0))
+
+	assert.NoError(t, NewDiskGuard(dir, dir, dir, 1, 0).Check())
+
+	err = NewDiskGuard(dir, dir, dir, free+(1<<40), 0).Check()
+	var diskErr *domain.DiskSpaceError
+	require.ErrorAs(t, err, &diskErr)
+	assert.Contains(t, diskErr.Reason, "min_free_space")
+}
+
+func TestDiskGuard_Quota(t *testing.T) {
+	baseDir := t.TempDir()
+	completedDir := filepath.Join(baseDir, "completed", "alice")
+	require.NoError(t, os.MkdirAll(completedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(completedDir, "a.mp4"), make([]byte, 600), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "queue.db"), make([]byte, 400), 0644))
+
+	assert.Equal(t, int64(1000), DirSize(baseDir))
+	assert.NoError(t, NewDiskGuard(completedDir, baseDir, baseDir, 0, 1001).Check())
+
+	err := NewDiskGuard(completedDir, baseDir, baseDir, 0, 1000).Check()
+	var diskErr *domain.DiskSpaceError
+	require.ErrorAs(t, err, &diskErr)
+	assert.Contains(t, diskErr.Reason, "quota is 1000 B")
+}
+
+func TestDiskGuard_QuotaCountsDirsOutsideBaseDir(t *testing.T) {
+	baseDir := t.TempDir()
+	incomingDir := t.TempDir()
+	completedDir := filepath.Join(baseDir, "completed")
+	require.NoError(t, os.MkdirAll(completedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(completedDir, "a.mp4"), make([]byte, 600), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(incomingDir, "b.mp4.part"), make([]byte, 400), 0644))
+
+	assert.NoError(t, NewDiskGuard(completedDir, incomingDir, baseDir, 0, 1001).Check())
+	err := NewDiskGuard(completedDir, incomingDir, baseDir, 0, 1000).Check()
+	var diskErr *domain.DiskSpaceError
+	require.ErrorAs(t, err, &diskErr)
+}
+
+func TestSameFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "incoming")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	assert.True(t, SameFileSystem(dir, sub))
+	assert.True(t, SameFileSystem(dir, filepath.Join(dir, "missing")), "unknown paths count as the same")
+}
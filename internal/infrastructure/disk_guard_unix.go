@@ -0,0 +1,25 @@
+//go:build !windows
+
+package infrastructure
+
+import "syscall"
+
+// SameFileSystem reports whether a and b are on the same file system, so a
+// file moves between them by rename instead of a copy. Paths that cannot be
+// checked count as the same.
+func SameFileSystem(a, b string) bool {
+	var statA, statB syscall.Stat_t
+	if syscall.Stat(a, &statA) != nil || syscall.Stat(b, &statB) != nil {
+		return true
+	}
+	return statA.Dev == statB.Dev
+}
+
+// FreeSpace returns the bytes available to this user on the volume of path
+func FreeSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
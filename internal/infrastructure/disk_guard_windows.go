@@ -0,0 +1,39 @@
+//go:build windows
+
+package infrastructure
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// SameFileSystem reports whether a and b are on the same volume (drive letter
+// or UNC share), so a file moves between them by rename instead of a copy.
+// Volumes mounted in a folder are not told apart. Paths that cannot be
+// checked count as the same.
+func SameFileSystem(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return true
+	}
+	return strings.EqualFold(filepath.VolumeName(absA), filepath.VolumeName(absB))
+}
+
+// FreeSpace returns the bytes available to this user on the volume of path
+func FreeSpace(path string) (int64, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	ok, _, err := procGetDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(&available)), 0, 0)
+	if ok == 0 {
+		return 0, err
+	}
+	return int64(available), nil
+}
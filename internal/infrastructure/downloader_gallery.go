@@ -1,12 +1,13 @@
 package infrastructure
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -19,12 +20,14 @@ import (
 
 // GalleryDownloader implements Downloader for gallery-dl (catch-all for 100+ sites)
 type GalleryDownloader struct {
-	DownloadLogger // Embedded shared log file operations
-	config         *domain.GalleryDLConfig
-	incomingDir    string
-	completedDir   string
-	cookiesDir     string
-	eventLogger    *logger.MultiLogger
+	DownloadLogger     // Embedded shared log file operations
+	MetadataExtensions // Embedded user-configured extra metadata fields
+	BandwidthLimit     // Embedded download.rate_limit lookup
+	config             *domain.GalleryDLConfig
+	incomingDir        string
+	completedDir       string
+	cookiesDir         string
+	eventLogger        *logger.MultiLogger
 }
 
 // parseGalleryDLFilters reads key=value filter pairs from the Download.Metadata
@@ -109,15 +112,15 @@ func (d *GalleryDownloader) Validate(url string) error {
 }
 
 // Download downloads media using gallery-dl
-func (d *GalleryDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) error {
+func (d *GalleryDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
 	if err := d.Validate(download.URL); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Create a per-download temp directory inside incoming to isolate files
 	downloadDir := filepath.Join(d.incomingDir, "gallery-dl-"+download.ID)
 	if err := os.MkdirAll(downloadDir, 0755); err != nil {
-		return fmt.Errorf("failed to create download directory: %w", err)
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
 	}
 	defer os.RemoveAll(downloadDir) // Clean up temp dir after move
 
@@ -139,6 +142,8 @@ func (d *GalleryDownloader) Download(ctx context.Context, download *domain.Downl
 		args = append(args, "--cookies", cookieFile)
 	}
 
+	args = append(args, limitRateArgs(d.rateLimitFor(download.Platform))...)
+
 	// Add extra params if configured
 	if d.config.ExtraParams != "" {
 		for _, param := range strings.Fields(d.config.ExtraParams) {
@@ -184,13 +189,13 @@ func (d *GalleryDownloader) Download(ctx context.Context, download *domain.Downl
 
 	// Create default callback if nil
 	if progressCallback == nil {
-		progressCallback = func(output string, percent float64) {}
+		progressCallback = func(progress domain.DownloadProgress) {}
 	}
 
 	// Open per-download log file so parallel downloads don't interleave.
 	downloadLog, err := d.OpenDownloadLogFile(download.ID)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
 	defer downloadLog.Close()
 
@@ -198,53 +203,158 @@ func (d *GalleryDownloader) Download(ctx context.Context, download *domain.Downl
 	cmdLine := ShellEscapeCommand(d.config.GalleryDLBinary, args...)
 	d.WriteLogHeader(downloadLog, download.ID, cmdLine)
 
-	// Execute gallery-dl. CommandContext ensures the process is killed if ctx is cancelled.
-	cmd := exec.CommandContext(ctx, d.config.GalleryDLBinary, args...)
-	cmd.Stdout = downloadLog
-	cmd.Stderr = downloadLog
+	// Execute gallery-dl. CommandWithCancel terminates the process group if ctx is cancelled.
+	cmd := CommandWithCancel(ctx, d.config.GalleryDLBinary, args...)
+	output := newOutputTail()
+	sink := io.MultiWriter(downloadLog, output, newProgressWriter(progressCallback))
+	cmd.Stdout = sink
+	cmd.Stderr = sink
 
+	started := time.Now()
 	err = cmd.Run()
+	recordProcessUsage(ctx, cmd, started)
 	if err != nil {
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("gallery-dl failed: %v", err))
-		progressCallback("", -1)
-		return fmt.Errorf("gallery-dl failed: %w", err)
+		progressCallback(domain.DownloadProgress{Percent: -1})
+		return nil, toolError("gallery-dl", err, output.String())
 	}
 
 	// Find downloaded files in the download directory
 	files, err := d.findDownloadedFiles(downloadDir)
 	if err != nil {
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to find files: %v", err))
-		return err
+		return nil, err
 	}
 
 	if len(files) == 0 {
 		d.WriteLogFooter(downloadLog, false, "No files downloaded")
-		return fmt.Errorf("no files downloaded")
+		return nil, fmt.Errorf("no files downloaded")
 	}
 
 	// Move files from download dir to completed directory
-	completedFiles, err := d.moveToCompleted(files, downloadDir)
+	completedFiles, err := d.moveToCompleted(files, downloadDir, progressCallback)
 	if err != nil {
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to move files: %v", err))
-		return fmt.Errorf("failed to move files to completed: %w", err)
+		return nil, fmt.Errorf("failed to move files to completed: %w", err)
 	}
 
-	// Store metadata
+	result := &domain.DownloadResult{Files: completedFiles}
 	if d.config.WriteMetadata {
-		if err := d.storeMetadata(download, completedFiles, downloadDir); err != nil {
-			if d.eventLogger != nil {
-				d.eventLogger.LogAppError("Failed to store gallery-dl metadata", zap.Error(err))
-			}
-		}
+		result.Metadata = d.writeMetadata(download.URL, completedFiles).ToMap()
 	}
 
-	// Update download with file path (use first file if multiple)
-	download.FilePath = completedFiles[0]
+	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded: %s", completedFiles[0]))
+	progressCallback(domain.DownloadProgress{Percent: 100})
 
-	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded: %s", download.FilePath))
-	progressCallback("", 100)
+	return result, nil
+}
 
-	return nil
+// gallerySyncScanDepth is how many of the newest timeline entries gallery-dl
+// inspects per sync. Tweets older than this that were never enqueued are skipped.
+const gallerySyncScanDepth = 200
+
+// ListNewItems implements domain.SourceSyncer for X account timelines. It asks
+// gallery-dl for the newest timeline entries (-j, nothing is downloaded) and
+// returns one item per tweet newer than cursor, oldest first. Each tweet is
+// enqueued as a regular X download so yt-dlp (with gallery-dl fallback) handles it.
+func (d *GalleryDownloader) ListNewItems(ctx context.Context, sourceURL, cursor string, limit int) ([]domain.SyncItem, error) {
+	if domain.DetectXURLType(sourceURL) != domain.XURLTypeTimeline {
+		return nil, fmt.Errorf("not an X account URL: %s", sourceURL)
+	}
+
+	args := []string{"-j", "--range", fmt.Sprintf("1-%d", gallerySyncScanDepth)}
+	if cookieFile := d.resolveCookieFile(sourceURL); cookieFile != "" {
+		args = append(args, "--cookies", cookieFile)
+	}
+	args = append(args, sourceURL)
+
+	var stdout, stderr bytes.Buffer
+	cmd := CommandWithCancel(ctx, d.config.GalleryDLBinary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, toolError("gallery-dl", fmt.Errorf("%w, output: %s", err, stderr.String()), stderr.String())
+	}
+
+	tweets, err := parseGalleryDLTweets(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var items []domain.SyncItem
+	for _, tweet := range tweets {
+		if !tweetIDAfter(tweet.id, cursor) {
+			continue
+		}
+		items = append(items, domain.SyncItem{
+			ID:       tweet.id,
+			URL:      fmt.Sprintf("https://x.com/%s/status/%s", tweet.author, tweet.id),
+			Platform: domain.PlatformX,
+			Mode:     domain.ModeDefault,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return tweetIDAfter(items[j].ID, items[i].ID) })
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// galleryDLTweet is a tweet referenced by gallery-dl -j output
+type galleryDLTweet struct {
+	id     string
+	author string
+}
+
+// parseGalleryDLTweets extracts unique tweets from gallery-dl -j output.
+// The output is a JSON array of messages; URL messages have the form
+// [3, "<media url>", {kwdict}] where kwdict carries tweet_id and author.name.
+func parseGalleryDLTweets(data []byte) ([]galleryDLTweet, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // tweet IDs exceed float64 precision
+	var messages []json.RawMessage
+	if err := dec.Decode(&messages); err != nil {
+		return nil, fmt.Errorf("failed to parse gallery-dl output: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var tweets []galleryDLTweet
+	for _, raw := range messages {
+		var msg []interface{}
+		msgDec := json.NewDecoder(bytes.NewReader(raw))
+		msgDec.UseNumber()
+		if err := msgDec.Decode(&msg); err != nil || len(msg) < 3 {
+			continue
+		}
+		if msgType, _ := msg[0].(json.Number); msgType.String() != "3" {
+			continue
+		}
+		kwdict, ok := msg[2].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := fmt.Sprint(kwdict["tweet_id"])
+		author, _ := kwdict["author"].(map[string]interface{})
+		name, _ := author["name"].(string)
+		if id == "" || id == "<nil>" || name == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		tweets = append(tweets, galleryDLTweet{id: id, author: name})
+	}
+	return tweets, nil
+}
+
+// tweetIDAfter reports whether tweet ID a is newer than b. An empty b means
+// no cursor, so every ID is newer. IDs are compared numerically as strings.
+func tweetIDAfter(a, b string) bool {
+	if b == "" {
+		return true
+	}
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
 }
 
 // findDownloadedFiles finds all media files in the download directory (recursive)
@@ -266,7 +376,8 @@ func (d *GalleryDownloader) findDownloadedFiles(downloadDir string) ([]string, e
 
 // moveToCompleted moves media files from download dir to completed directory.
 // Also moves corresponding .json metadata files created by gallery-dl.
-func (d *GalleryDownloader) moveToCompleted(files []string, downloadDir string) ([]string, error) {
+// Large copies across file systems report their progress to progress.
+func (d *GalleryDownloader) moveToCompleted(files []string, downloadDir string, progress domain.DownloadProgressCallback) ([]string, error) {
 	var completedFiles []string
 
 	if err := os.MkdirAll(d.completedDir, 0755); err != nil {
@@ -277,7 +388,7 @@ func (d *GalleryDownloader) moveToCompleted(files []string, downloadDir string)
 		filename := filepath.Base(file)
 		destPath := filepath.Join(d.completedDir, filename)
 
-		if err := MoveFile(file, destPath); err != nil {
+		if err := MoveFileWithProgress(file, destPath, progress); err != nil {
 			return nil, err
 		}
 
@@ -294,12 +405,12 @@ func (d *GalleryDownloader) moveToCompleted(files []string, downloadDir string)
 	return completedFiles, nil
 }
 
-// storeMetadata reads gallery-dl's native metadata .json files, converts them
+// writeMetadata reads gallery-dl's native metadata .json files, converts them
 // to the unified MediaMetadata format, writes a single .info.json per media
 // file, and deletes gallery-dl's native .json so only one metadata sidecar
 // remains on disk — matching the convention used by the yt-dlp and Telegram
-// downloaders.
-func (d *GalleryDownloader) storeMetadata(download *domain.Download, completedFiles []string, downloadDir string) error {
+// downloaders. It returns the metadata of the download.
+func (d *GalleryDownloader) writeMetadata(url string, completedFiles []string) *domain.MediaMetadata {
 	var meta *domain.MediaMetadata
 
 	// Try to read gallery-dl's metadata .json file (one per media file,
@@ -309,8 +420,14 @@ func (d *GalleryDownloader) storeMetadata(download *domain.Download, completedFi
 		metaPath := file + ".json"
 		if data, err := os.ReadFile(metaPath); err == nil {
 			var infoData map[string]interface{}
-			if json.Unmarshal(data, &infoData) == nil {
-				meta = d.buildRichMetadata(infoData, download.URL, completedFiles)
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber() // tweet IDs exceed float64 precision
+			if dec.Decode(&infoData) == nil {
+				if isGalleryDLTweet(infoData) {
+					meta = buildTweetMetadata(infoData, url, completedFiles)
+				} else {
+					meta = d.buildRichMetadata(infoData, url, completedFiles)
+				}
 				break
 			}
 		}
@@ -318,15 +435,9 @@ func (d *GalleryDownloader) storeMetadata(download *domain.Download, completedFi
 
 	// If no metadata file found, build minimal metadata
 	if meta == nil {
-		meta = d.buildMinimalMetadata(download.URL, completedFiles)
+		meta = d.buildMinimalMetadata(url, completedFiles)
 	}
-
-	data, err := json.Marshal(meta.ToMap())
-	if err != nil {
-		return err
-	}
-
-	download.Metadata = string(data)
+	d.ApplyExtensions(meta)
 
 	// Write the unified .info.json sidecar and remove gallery-dl's native
 	// .json (it has been consumed). Best-effort deletion — leftover files
@@ -334,9 +445,12 @@ func (d *GalleryDownloader) storeMetadata(download *domain.Download, completedFi
 	for _, file := range completedFiles {
 		WriteInfoJSON(file, meta)
 		_ = os.Remove(file + ".json")
+		if err := d.WriteCompanionFiles(file, meta); err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("Failed to write description file", zap.String("file", file), zap.Error(err))
+		}
 	}
 
-	return nil
+	return meta
 }
 
 // buildRichMetadata extracts metadata from gallery-dl's .json metadata
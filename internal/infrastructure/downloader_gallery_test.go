@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGalleryDLTweets(t *testing.T) {
+	output := `[
+  [2, {"tweet_id": 1790000000000000002, "author": {"name": "someone"}}],
+  [3, "https://pbs.twimg.com/media/a.jpg", {"tweet_id": 1790000000000000002, "author": {"name": "someone"}}],
+  [3, "https://pbs.twimg.com/media/b.jpg", {"tweet_id": 1790000000000000002, "author": {"name": "someone"}}],
+  [3, "https://video.twimg.com/c.mp4", {"tweet_id": 1790000000000000001, "author": {"name": "other"}}],
+  [3, "https://pbs.twimg.com/media/d.jpg", {"author": {"name": "someone"}}]
+]`
+
+	tweets, err := parseGalleryDLTweets([]byte(output))
+	require.NoError(t, err)
+	assert.Equal(t, []galleryDLTweet{
+		{id: "1790000000000000002", author: "someone"},
+		{id: "1790000000000000001", author: "other"},
+	}, tweets)
+
+	_, err = parseGalleryDLTweets([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestTweetIDAfter(t *testing.T) {
+	assert.True(t, tweetIDAfter("1", ""))
+	assert.True(t, tweetIDAfter("1790000000000000002", "1790000000000000001"))
+	assert.True(t, tweetIDAfter("10000000000000000000", "9999999999999999999"))
+	assert.False(t, tweetIDAfter("999", "1000"))
+	assert.False(t, tweetIDAfter("5", "5"))
+}
+
+func TestBuildTweetMetadata(t *testing.T) {
+	data := `{
+  "category": "twitter",
+  "subcategory": "tweet",
+  "tweet_id": 1790000000000000002,
+  "content": "Sunset over the bay\nshot on film #photography",
+  "date": "2024-05-13 18:30:00",
+  "hashtags": ["photography"],
+  "author": {"id": 42, "name": "someone", "nick": "Some One"},
+  "favorite_count": 120,
+  "retweet_count": 7,
+  "extension": "jpg"
+}`
+	var infoData map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&infoData))
+	require.True(t, isGalleryDLTweet(infoData))
+
+	files := []string{"/completed/a.jpg"}
+	meta := buildTweetMetadata(infoData, "https://twitter.com/someone/status/1790000000000000002", files)
+	assert.Equal(t, "1790000000000000002", meta.ID)
+	assert.Equal(t, "Some One - Sunset over the bay shot on film #photography", meta.Title)
+	assert.Equal(t, "Sunset over the bay\nshot on film #photography", meta.Description)
+	assert.Equal(t, "Some One", meta.Uploader)
+	assert.Equal(t, "someone", meta.UploaderID)
+	assert.Equal(t, "https://x.com/someone", meta.UploaderURL)
+	assert.Equal(t, "https://x.com/someone/status/1790000000000000002", meta.WebpageURL)
+	assert.Equal(t, "20240513", meta.UploadDate)
+	assert.Equal(t, []string{"photography", "x", "twitter"}, meta.Tags)
+	assert.Equal(t, "x", meta.Platform)
+	assert.Equal(t, "Twitter", meta.ExtractorKey)
+	assert.Equal(t, int64(120), meta.LikeCount)
+	assert.Equal(t, int64(7), meta.RepostCount)
+	assert.Equal(t, files, meta.Files)
+}
@@ -0,0 +1,107 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// galleryDLTweetTitleMaxRunes matches the length yt-dlp truncates tweet
+// text to when building its "<uploader> - <text>" title
+const galleryDLTweetTitleMaxRunes = 72
+
+// galleryDLDateLayout is how gallery-dl serializes datetimes in --write-metadata output
+const galleryDLDateLayout = "2006-01-02 15:04:05"
+
+// isGalleryDLTweet reports whether gallery-dl metadata came from its twitter extractor
+func isGalleryDLTweet(infoData map[string]interface{}) bool {
+	return GetStringFromMap(infoData, "category") == "twitter"
+}
+
+// buildTweetMetadata normalizes gallery-dl's twitter metadata (tweet_id,
+// content, author{name,nick}, hashtags, date) into the same MediaMetadata that
+// TwitterDownloader builds from yt-dlp's .info.json, so X downloads look the
+// same whichever backend fetched them.
+func buildTweetMetadata(infoData map[string]interface{}, url string, files []string) *domain.MediaMetadata {
+	tweetID := jsonValueString(infoData["tweet_id"])
+	author, _ := infoData["author"].(map[string]interface{})
+	if author == nil {
+		author, _ = infoData["user"].(map[string]interface{})
+	}
+	uploaderID := GetStringFromMap(author, "name")
+	uploader := GetFirstStringFromMap(author, "nick", "name")
+	content := GetStringFromMap(infoData, "content")
+
+	title := strings.Join(strings.Fields(content), " ")
+	if runes := []rune(title); len(runes) > galleryDLTweetTitleMaxRunes {
+		title = string(runes[:galleryDLTweetTitleMaxRunes]) + "..."
+	}
+	if uploader != "" {
+		title = uploader + " - " + title
+	}
+
+	timestamp := time.Now().Unix()
+	uploadDate := time.Now().Format("20060102")
+	if t, err := time.Parse(galleryDLDateLayout, GetStringFromMap(infoData, "date")); err == nil {
+		timestamp = t.Unix()
+		uploadDate = t.Format("20060102")
+	}
+
+	var tags []string
+	if hashtags, ok := infoData["hashtags"].([]interface{}); ok {
+		for _, tag := range hashtags {
+			if tagStr, ok := tag.(string); ok {
+				tags = append(tags, tagStr)
+			}
+		}
+	}
+	tags = append(tags, "x", "twitter")
+
+	uploaderURL := ""
+	webpageURL := url
+	if uploaderID != "" {
+		uploaderURL = "https://x.com/" + uploaderID
+		if tweetID != "" {
+			webpageURL = fmt.Sprintf("https://x.com/%s/status/%s", uploaderID, tweetID)
+		}
+	}
+
+	return &domain.MediaMetadata{
+		ID:           tweetID,
+		Title:        title,
+		Description:  content,
+		Uploader:     uploader,
+		UploaderID:   uploaderID,
+		UploaderURL:  uploaderURL,
+		WebpageURL:   webpageURL,
+		URL:          url,
+		Timestamp:    timestamp,
+		UploadDate:   uploadDate,
+		Tags:         tags,
+		Platform:     "x",
+		Extractor:    "twitter",
+		ExtractorKey: "Twitter",
+		LikeCount:    GetInt64FromMap(infoData, "favorite_count"),
+		RepostCount:  GetInt64FromMap(infoData, "retweet_count"),
+		CommentCount: GetInt64FromMap(infoData, "reply_count"),
+		ViewCount:    GetInt64FromMap(infoData, "view_count"),
+		Extension:    GetStringFromMap(infoData, "extension"),
+		Files:        files,
+	}
+}
+
+// jsonValueString formats a decoded JSON scalar as a string. Numbers must be
+// decoded with UseNumber so tweet IDs keep their precision.
+func jsonValueString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return ""
+	}
+}
@@ -2,13 +2,17 @@ package infrastructure
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -58,13 +62,16 @@ type TelegramPeerInfo struct {
 
 // TelegramDownloader implements Downloader for Telegram
 type TelegramDownloader struct {
-	DownloadLogger   // Embedded shared log file operations
-	config           *domain.TelegramConfig
-	incomingDir      string
-	completedDir     string
-	eventLogger      *logger.MultiLogger // For structured events only (LogQueueEvent, LogAppError)
-	channelRepo      domain.TelegramChannelRepository
-	messageCacheRepo domain.TelegramMessageCacheRepository
+	DownloadLogger     // Embedded shared log file operations
+	MetadataExtensions // Embedded user-configured extra metadata fields
+	BandwidthLimit     // Embedded download.rate_limit lookup
+	config             *domain.TelegramConfig
+	incomingDir        string
+	completedDir       string
+	eventLogger        *logger.MultiLogger // For structured events only (LogQueueEvent, LogAppError)
+	channelRepo        domain.TelegramChannelRepository
+	messageCacheRepo   domain.TelegramMessageCacheRepository
+	channelAliasRepo   domain.TelegramChannelAliasRepository
 }
 
 // NewTelegramDownloader creates a new Telegram downloader
@@ -88,6 +95,37 @@ func (d *TelegramDownloader) SetMessageCacheRepository(repo domain.TelegramMessa
 	d.messageCacheRepo = repo
 }
 
+// SetChannelAliasRepository sets the channel aliases; files of a channel with
+// an alias are organized into the folder of its successor
+func (d *TelegramDownloader) SetChannelAliasRepository(repo domain.TelegramChannelAliasRepository) {
+	d.channelAliasRepo = repo
+}
+
+// telegramProfileRepository is implemented by repositories keeping a
+// separate channel list and message cache per tdl profile
+type telegramProfileRepository interface {
+	ForTelegramProfile(profile string) *SQLiteDownloadRepository
+}
+
+// ForProfile returns a downloader using a tdl profile of telegram.profiles:
+// its -n and --storage arguments, channel list and message cache. The default
+// profile (or an empty name) returns d.
+func (d *TelegramDownloader) ForProfile(name string) *TelegramDownloader {
+	if name == "" || name == d.config.Profile {
+		return d
+	}
+	config := d.config.ForProfile(name)
+	profile := *d
+	profile.config = &config
+	if repo, ok := d.channelRepo.(telegramProfileRepository); ok {
+		profile.channelRepo = repo.ForTelegramProfile(name)
+	}
+	if repo, ok := d.messageCacheRepo.(telegramProfileRepository); ok {
+		profile.messageCacheRepo = repo.ForTelegramProfile(name)
+	}
+	return &profile
+}
+
 // Platform returns the platform this downloader handles
 func (d *TelegramDownloader) Platform() domain.Platform {
 	return domain.PlatformTelegram
@@ -102,15 +140,21 @@ func (d *TelegramDownloader) Validate(url string) error {
 }
 
 // Download downloads media from Telegram
-func (d *TelegramDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) error {
+func (d *TelegramDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
 	// Validate URL
 	if err := d.Validate(download.URL); err != nil {
-		return err
+		return nil, err
+	}
+	if download.TDLProfile != "" {
+		if !d.config.HasProfile(download.TDLProfile) {
+			return nil, fmt.Errorf("unknown Telegram profile: %s", download.TDLProfile)
+		}
+		d = d.ForProfile(download.TDLProfile)
 	}
 
 	// Update channel list if needed (for channel name lookups in metadata)
 	// This runs once every 7 days and won't block downloads if it fails
-	d.UpdateChannelListIfNeeded()
+	d.UpdateChannelListIfNeeded(ctx)
 
 	// Check if this is a re-download of a previously completed download
 	// If files were deleted by user, we should not re-download them
@@ -121,161 +165,374 @@ func (d *TelegramDownloader) Download(ctx context.Context, download *domain.Down
 		allExist, missingFiles := d.checkFilesExist(existingFiles)
 		if allExist {
 			// All files exist, nothing to download
-			download.FilePath = existingFiles[0]
-			return nil
+			return &domain.DownloadResult{Files: existingFiles}, nil
 		}
 		// Some files are missing (user deleted them intentionally)
 		// Update metadata to reflect the current state and skip download
 		_ = missingFiles // Log if needed
-		download.FilePath = existingFiles[0]
-		d.updateMetadataAfterPartialDeletion(download, existingFiles)
-		return nil
+		return d.partialDeletionResult(download, existingFiles), nil
 	}
 
 	// Create temp directory for this download in incoming directory
 	downloadTempDir := filepath.Join(d.incomingDir, "temp_"+download.ID)
 	if err := os.MkdirAll(downloadTempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer os.RemoveAll(downloadTempDir)
 
 	// Ensure incoming directory exists
 	if err := os.MkdirAll(d.incomingDir, 0755); err != nil {
-		return fmt.Errorf("failed to create incoming directory: %w", err)
+		return nil, fmt.Errorf("failed to create incoming directory: %w", err)
+	}
+
+	// A range inside a forum topic spans the messages of every topic; only
+	// the topic's messages are downloaded
+	urls := download.MessageURLs()
+	var rangeMessages map[string]*TelegramMessageData
+	if topicID := extractTelegramTopic(download.URL); download.IsRange() && topicID > 0 {
+		var err error
+		rangeMessages, err = d.exportMessageRange(ctx, extractTelegramChannel(download.URL), topicID, download.RangeStart, download.RangeEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list messages of topic %d: %w", topicID, err)
+		}
+		if urls = topicMessageURLs(download, rangeMessages); len(urls) == 0 {
+			return nil, fmt.Errorf("no messages of topic %d between %d and %d", topicID, download.RangeStart, download.RangeEnd)
+		}
 	}
 
 	// Build tdl command
-	args := d.buildTDLCommand(download, downloadTempDir)
+	args := d.buildTDLCommand(download, urls, downloadTempDir)
+	clientProfile := d.config.ClientProfile()
+	if download.TDLProfile != "" {
+		profile := "profile=" + download.TDLProfile
+		if clientProfile != "" {
+			profile = clientProfile + " | " + profile
+		}
+		clientProfile = profile
+	}
 
 	// Create default callback if nil
 	if progressCallback == nil {
-		progressCallback = func(output string, percent float64) {}
+		progressCallback = func(progress domain.DownloadProgress) {}
 	}
 
 	// Open per-download log file so parallel downloads don't interleave.
 	downloadLog, err := d.OpenDownloadLogFile(download.ID)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return nil, domain.WithClientProfile(fmt.Errorf("failed to open log file: %w", err), clientProfile)
 	}
 	defer downloadLog.Close()
 
 	// Write command header to download log (with proper shell escaping for display)
-	cmdLine := ShellEscapeCommand(d.config.TDLBinary, args...)
+	cmdLine := d.tdlCommandLine(args...)
 	d.WriteLogHeader(downloadLog, download.ID, cmdLine)
 
-	// Execute tdl with direct file redirect.
-	// CommandContext ensures the process is killed if ctx is cancelled.
-	cmd := exec.CommandContext(ctx, d.config.TDLBinary, args...)
-	cmd.Stdout = downloadLog
-	cmd.Stderr = downloadLog
+	// Execute tdl; its output goes to the download log and its progress bars
+	// are parsed into progressCallback.
+	// CommandWithCancel terminates the process group if ctx is cancelled.
+	cmd := d.tdl(ctx, args...)
+	output := newOutputTail()
+	sink := io.MultiWriter(downloadLog, output, newTDLProgressWriter(progressCallback))
+	cmd.Stdout = sink
+	cmd.Stderr = sink
 
 	// Run command and check exit code
+	started := time.Now()
 	err = cmd.Run()
+	recordProcessUsage(ctx, cmd, started)
 
 	// Write completion marker and handle result
 	if err != nil {
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("tdl failed: %v", err))
-		progressCallback("", -1) // Signal failure
-		return fmt.Errorf("tdl failed: %w", err)
+		progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+		return nil, domain.WithClientProfile(toolError("tdl", err, output.String()), clientProfile)
 	}
 
 	// Move files from temp to completed directory
 	// Returns file paths and the actual message ID from the filename
-	files, actualMsgID, err := d.moveDownloadedFiles(downloadTempDir)
+	files, actualMsgID, err := d.moveDownloadedFiles(downloadTempDir, progressCallback)
 	if err != nil {
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to move files: %v", err))
-		return err
+		return nil, domain.WithClientProfile(err, clientProfile)
 	}
 
 	if len(files) == 0 {
 		d.WriteLogFooter(downloadLog, false, "No files downloaded")
-		return fmt.Errorf("no files downloaded")
+		return nil, domain.WithClientProfile(fmt.Errorf("no files downloaded"), clientProfile)
 	}
 
-	// Use the actual message ID from the filename if available (more accurate than URL)
-	// This handles cases where tdl downloads a different message than expected
-	messageURL := download.URL
-	if actualMsgID != "" {
-		channelID := extractTelegramChannel(download.URL)
-		messageURL = fmt.Sprintf("https://t.me/c/%s/%s", channelID, actualMsgID)
-		if d.eventLogger != nil {
-			d.eventLogger.LogQueueEvent("telegram_actual_message_id",
-				zap.String("download_id", download.ID),
-				zap.String("url_message_id", extractTelegramID(download.URL)),
-				zap.String("actual_message_id", actualMsgID))
-		}
-	}
-
-	// Extract message metadata.
-	// Single-mode: cache hit (zero network) → narrow-range export (≤6 messages) → fallback.
-	// Group/default: full cache-warm path amortised across many messages.
-	channel := extractTelegramChannel(messageURL)
-	msgID := extractTelegramID(messageURL)
-
+	// Range downloads get per-message metadata; the first message's data is
+	// used for the download record
 	var messageData *TelegramMessageData
-	if download.Mode == domain.ModeSingle {
-		messageData = d.fetchSingleMessageData(ctx, channel, msgID)
+	var items []domain.DownloadItem
+	if download.IsRange() {
+		files, items, messageData = d.finishRangeFiles(ctx, download, files, rangeMessages)
 	} else {
-		messageData, err = d.extractMessageContent(ctx, messageURL)
-		if err != nil {
+		// Use the actual message ID from the filename if available (more accurate than URL)
+		// This handles cases where tdl downloads a different message than expected
+		messageURL := download.URL
+		if actualMsgID != "" {
+			messageURL = parseTelegramURL(download.URL).messageURL(actualMsgID)
 			if d.eventLogger != nil {
-				d.eventLogger.LogAppError("Failed to extract message content",
-					zap.String("url", messageURL),
-					zap.Error(err))
+				d.eventLogger.LogQueueEvent("telegram_actual_message_id",
+					zap.String("download_id", download.ID),
+					zap.String("url_message_id", extractTelegramID(download.URL)),
+					zap.String("actual_message_id", actualMsgID))
 			}
 		}
+
+		// Extract message metadata.
+		// Single-mode: cache hit (zero network) → narrow-range export (≤6 messages) → fallback.
+		// Group/default: full cache-warm path amortised across many messages.
+		channel := extractTelegramChannel(messageURL)
+		msgID := extractTelegramID(messageURL)
+
+		if download.Mode == domain.ModeSingle {
+			messageData = d.fetchSingleMessageData(ctx, channel, msgID)
+		} else {
+			messageData, err = d.extractMessageContent(ctx, messageURL)
+			if err != nil {
+				if d.eventLogger != nil {
+					d.eventLogger.LogAppError("Failed to extract message content",
+						zap.String("url", messageURL),
+						zap.Error(err))
+				}
+			}
+		}
+
+		files = d.finishMessageFiles(download.URL, files, messageData)
+	}
+
+	// Build full metadata for the download record (includes title, description, uploader)
+	meta := d.buildTelegramMetadata(download.URL, messageData, files)
+	meta.RelativePath = RelativeToCompleted(d.completedDir, files[0])
+
+	// Log successful completion
+	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded: %s", files[0]))
+	progressCallback(domain.DownloadProgress{Percent: 100}) // Signal success
+
+	return &domain.DownloadResult{Files: files, Metadata: meta.ToMap(), Items: items, ClientProfile: clientProfile}, nil
+}
+
+// finishMessageFiles applies the configured filename template and subdirectory
+// layout to the files of one message, then writes their metadata sidecars.
+// It returns the final file paths.
+func (d *TelegramDownloader) finishMessageFiles(url string, files []string, messageData *TelegramMessageData) []string {
+	var err error
+	if d.FilenameTemplate != "" || d.OrganizeBy != "" {
+		meta := d.buildTelegramMetadata(url, messageData, files)
+		files, err = d.RenameToTemplate(files, meta)
+		if err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("Failed to apply filename template", zap.Error(err))
+		}
+		files, err = d.OrganizeFiles(files, d.completedDir, d.successorMetadata(url, meta))
+		if err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("Failed to organize completed files", zap.Error(err))
+		}
 	}
 
 	// Create metadata for each file using shared message data
 	for _, file := range files {
-		if err := d.createMetadataFile(download.URL, file, messageData); err != nil {
+		if err := d.createMetadataFile(url, file, messageData); err != nil {
 			if d.eventLogger != nil {
 				d.eventLogger.LogAppError("Failed to create metadata file", zap.String("file", file), zap.Error(err))
 			}
 		}
 	}
+	return files
+}
+
+// finishRangeFiles finishes the files of a range download message by message:
+// files are grouped by the message ID in their tdl filename so each message
+// keeps its own text, date and sender. One bounded export fetches the text of
+// the whole range, unless messages were already exported. Each file is
+// recorded as an item of its message. It returns the final file paths and the
+// data of the first message, which describes the download record.
+func (d *TelegramDownloader) finishRangeFiles(ctx context.Context, download *domain.Download, files []string, messages map[string]*TelegramMessageData) ([]string, []domain.DownloadItem, *TelegramMessageData) {
+	if messages == nil {
+		channel := extractTelegramChannel(download.URL)
+		var err error
+		messages, err = d.exportMessageRange(ctx, channel, 0, download.RangeStart, download.RangeEnd)
+		if err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("telegram range export failed",
+				zap.String("channel", channel),
+				zap.Int("range_start", download.RangeStart),
+				zap.Int("range_end", download.RangeEnd),
+				zap.Error(err))
+		}
+	}
+
+	var order []string
+	byMessage := make(map[string][]string)
+	for _, file := range files {
+		msgID := extractMessageIDFromFilename(filepath.Base(file))
+		if _, seen := byMessage[msgID]; !seen {
+			order = append(order, msgID)
+		}
+		byMessage[msgID] = append(byMessage[msgID], file)
+	}
+
+	var finished []string
+	var items []domain.DownloadItem
+	for _, msgID := range order {
+		messageURL := download.URL
+		if id, err := strconv.Atoi(msgID); err == nil {
+			messageURL = domain.TelegramMessageURL(download.URL, id)
+		}
+		messageFiles := d.finishMessageFiles(messageURL, byMessage[msgID], messages[msgID])
+		for _, file := range messageFiles {
+			item := domain.DownloadItem{Status: domain.StatusCompleted, FilePath: file, SourceID: msgID, SourceURL: messageURL}
+			if msg := messages[msgID]; msg != nil {
+				item.Title = msg.Text
+				if msg.Date > 0 {
+					item.UploadDate = time.Unix(msg.Date, 0).Format("20060102")
+				}
+			}
+			items = append(items, item)
+		}
+		finished = append(finished, messageFiles...)
+	}
+	return finished, items, messages[order[0]]
+}
 
-	// Update download with file path (use first file if multiple)
-	download.FilePath = files[0]
+// exportMessageRange exports messages [startID, endID] of a channel, or of
+// one of its forum topics when topicID is above 0, in one tdl run and caches
+// them. It returns the messages keyed by message ID.
+func (d *TelegramDownloader) exportMessageRange(ctx context.Context, channel string, topicID, startID, endID int) (map[string]*TelegramMessageData, error) {
+	exported, err := d.exportMessages(ctx, channel, topicID, startID, endID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Build full metadata for the download record (includes title, description, uploader)
-	meta := d.buildTelegramMetadata(download.URL, messageData, files)
-	data, _ := json.Marshal(meta.ToMap())
-	download.Metadata = string(data)
+	messages := make(map[string]*TelegramMessageData, len(exported))
+	caches := make([]domain.TelegramMessageCache, 0, len(exported))
+	for i := range exported {
+		msg := &exported[i]
+		msgID := strconv.Itoa(msg.ID)
+		messages[msgID] = msg
+		caches = append(caches, domain.TelegramMessageCache{
+			ChannelID: channel,
+			MessageID: msgID,
+			Text:      msg.Text,
+			Date:      msg.Date,
+			SenderID:  formatSenderID(msg.Raw),
+			GroupedID: formatGroupedID(msg.Raw),
+		})
+	}
+	if d.messageCacheRepo != nil && len(caches) > 0 {
+		if err := d.messageCacheRepo.SaveMessages(caches); err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("failed to cache range messages",
+				zap.String("channel", channel),
+				zap.Error(err))
+		}
+	}
+	return messages, nil
+}
 
-	// Log successful completion
-	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded: %s", download.FilePath))
-	progressCallback("", 100) // Signal success
+// topicMessageURLs returns the URLs of the exported messages of a topic range
+// download, in message ID order
+func topicMessageURLs(download *domain.Download, messages map[string]*TelegramMessageData) []string {
+	var urls []string
+	for id := download.RangeStart; id <= download.RangeEnd; id++ {
+		if messages[strconv.Itoa(id)] != nil {
+			urls = append(urls, domain.TelegramMessageURL(download.URL, id))
+		}
+	}
+	return urls
+}
 
-	return nil
+// tdl builds a tdl command run under telegram.wrapper
+func (d *TelegramDownloader) tdl(ctx context.Context, args ...string) *exec.Cmd {
+	return CommandWrapper(d.config.Wrapper).Command(ctx, d.config.TDLBinary, args...)
 }
 
-// tdlBaseArgs returns the common authentication and storage arguments for all tdl commands.
+// tdlCommandLine returns the command line of a tdl run, for download logs
+func (d *TelegramDownloader) tdlCommandLine(args ...string) string {
+	return CommandWrapper(d.config.Wrapper).CommandLine(d.config.TDLBinary, args...)
+}
+
+// tdlBaseArgs returns the common authentication, storage and connection arguments for all tdl commands.
 func (d *TelegramDownloader) tdlBaseArgs() []string {
-	return []string{
+	args := []string{
 		"-n", d.config.Profile,
 		"--storage", fmt.Sprintf("type=%s,path=%s", d.config.StorageType, d.config.StoragePath),
 	}
+	if d.config.Proxy != "" {
+		args = append(args, "--proxy", d.config.Proxy)
+	}
+	if d.config.NTP != "" {
+		args = append(args, "--ntp", d.config.NTP)
+	}
+	return args
 }
 
-// buildTDLCommand builds the tdl command with appropriate flags
-func (d *TelegramDownloader) buildTDLCommand(download *domain.Download, tempDir string) []string {
-	args := append(d.tdlBaseArgs(),
-		"dl",
-		"-u", download.URL,
-		"-d", tempDir,
-	)
+// telegramSessionProbeTimeout bounds the `tdl chat ls` probe of VerifySession
+const telegramSessionProbeTimeout = time.Minute
 
-	// Determine if we should use --group flag
-	useGroup := d.config.UseGroup
+// LoginCommand returns the interactive `tdl login` command for the configured
+// profile and storage. loginType is tdl's --type (desktop, code or qr), or
+// empty for tdl's default. The caller connects it to the terminal; it is not
+// started in its own process group so terminal input and Ctrl-C reach tdl.
+func (d *TelegramDownloader) LoginCommand(loginType string) *exec.Cmd {
+	args := append(d.tdlBaseArgs(), "login")
+	if loginType != "" {
+		args = append(args, "--type", loginType)
+	}
+	program, wrapped := CommandWrapper(d.config.Wrapper).Wrap(d.config.TDLBinary, args...)
+	return exec.Command(program, wrapped...)
+}
+
+// VerifySession checks the stored tdl session with a `tdl chat ls` probe.
+// The session is verified when the chats can be listed.
+func (d *TelegramDownloader) VerifySession(ctx context.Context) *domain.TelegramSession {
+	session := &domain.TelegramSession{Profile: d.config.Profile, VerifiedAt: time.Now()}
+
+	probeCtx, cancel := context.WithTimeout(ctx, telegramSessionProbeTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := d.tdl(probeCtx, append(d.tdlBaseArgs(), "chat", "ls")...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		session.Error = lastOutputLine(stderr.String(), err)
+		return session
+	}
+
+	channels, err := parseTDLChatList(string(output))
+	if err != nil {
+		session.Error = err.Error()
+		return session
+	}
+	session.Verified = true
+	session.Chats = len(channels)
+	return session
+}
+
+// usesGroup reports whether a download fetches the whole album of its
+// message (tdl --group): always in group mode, never in single mode, else
+// as telegram.use_group says
+func (d *TelegramDownloader) usesGroup(download *domain.Download) bool {
 	switch download.Mode {
 	case domain.ModeSingle:
-		useGroup = false
+		return false
 	case domain.ModeGroup:
-		useGroup = true
+		return true
+	}
+	return d.config.UseGroup
+}
+
+// buildTDLCommand builds the tdl command with appropriate flags to download
+// the message urls (download.MessageURLs, or a topic's share of a range)
+func (d *TelegramDownloader) buildTDLCommand(download *domain.Download, urls []string, tempDir string) []string {
+	args := append(d.tdlBaseArgs(), "dl")
+
+	// A range download passes one -u per message so tdl fetches them all in one run
+	for _, url := range urls {
+		args = append(args, "-u", url)
 	}
+	args = append(args, "-d", tempDir)
 
-	if useGroup {
+	if d.usesGroup(download) {
 		args = append(args, "--group")
 	}
 
@@ -301,6 +558,10 @@ func (d *TelegramDownloader) buildTDLCommand(download *domain.Download, tempDir
 		}
 	}
 
+	// tdl cannot cap bandwidth; a rate-limited download runs as one task on
+	// one thread. Extra parameters below can still override both.
+	args = append(args, tdlThrottleArgs(d.rateLimitFor(download.Platform))...)
+
 	// Add extra parameters if configured
 	if d.config.ExtraParams != "" {
 		extraArgs := strings.Fields(d.config.ExtraParams)
@@ -311,8 +572,9 @@ func (d *TelegramDownloader) buildTDLCommand(download *domain.Download, tempDir
 }
 
 // moveDownloadedFiles moves files from temp directory to completed directory
-// Returns both the file paths and the extracted message ID from the filename (if found)
-func (d *TelegramDownloader) moveDownloadedFiles(tempDir string) ([]string, string, error) {
+// Returns both the file paths and the extracted message ID from the filename (if found).
+// Large copies across file systems report their progress to progress.
+func (d *TelegramDownloader) moveDownloadedFiles(tempDir string, progress domain.DownloadProgressCallback) ([]string, string, error) {
 	var movedFiles []string
 
 	// Ensure completed directory exists
@@ -330,12 +592,8 @@ func (d *TelegramDownloader) moveDownloadedFiles(tempDir string) ([]string, stri
 			destPath := filepath.Join(d.completedDir, filename)
 
 			// Move file
-			if err := os.Rename(path, destPath); err != nil {
-				// If rename fails, try copy and delete
-				if err := CopyFile(path, destPath); err != nil {
-					return fmt.Errorf("failed to move file: %w", err)
-				}
-				os.Remove(path)
+			if err := MoveFileWithProgress(path, destPath, progress); err != nil {
+				return err
 			}
 
 			movedFiles = append(movedFiles, destPath)
@@ -376,9 +634,9 @@ func extractMessageIDFromFilename(filename string) string {
 // This is the single source of truth for all Telegram metadata — used by both
 // per-file .info.json generation and the download record metadata.
 func (d *TelegramDownloader) buildTelegramMetadata(url string, messageData *TelegramMessageData, files []string) *domain.MediaMetadata {
+	parsed := parseTelegramURL(url)
 	messageID := extractTelegramID(url)
 	channelID := extractTelegramChannel(url)
-	isPrivateChannel := isPrivateChannelURL(url)
 
 	// Look up channel name from repository (falls back to channelID if not found)
 	channelName := d.GetChannelName(channelID)
@@ -415,23 +673,22 @@ func (d *TelegramDownloader) buildTelegramMetadata(url string, messageData *Tele
 		uploader = fmt.Sprintf("%s_%s", channelName, uploaderName)
 	}
 
-	// Build URLs based on channel type (public vs private)
-	var uploaderURL, webpageURL string
-	if isPrivateChannel {
+	// Build URLs based on channel type (public vs private); the message link
+	// keeps its forum topic
+	uploaderURL := fmt.Sprintf("https://t.me/%s", channelID)
+	if parsed.Private {
 		uploaderURL = fmt.Sprintf("https://t.me/c/%s", channelID)
-		webpageURL = fmt.Sprintf("https://t.me/c/%s/%s", channelID, messageID)
-	} else {
-		uploaderURL = fmt.Sprintf("https://t.me/%s", channelID)
-		webpageURL = fmt.Sprintf("https://t.me/%s/%s", channelID, messageID)
 	}
+	webpageURL := parsed.messageURL(messageID)
 
-	return &domain.MediaMetadata{
+	meta := &domain.MediaMetadata{
 		ID:           messageID,
 		Title:        title,
 		Description:  description,
 		Uploader:     uploader,
 		UploaderID:   uploaderID,
 		UploaderURL:  uploaderURL,
+		Channel:      channelName,
 		WebpageURL:   webpageURL,
 		URL:          url,
 		Timestamp:    timestamp,
@@ -442,12 +699,52 @@ func (d *TelegramDownloader) buildTelegramMetadata(url string, messageData *Tele
 		ExtractorKey: "Telegram",
 		Files:        files,
 	}
+	d.ApplyExtensions(meta)
+	return meta
 }
 
-// createMetadataFile creates a per-file .info.json metadata file using WriteInfoJSON.
+// successorMetadata returns meta as the successor of the URL's channel would
+// have it, so organize_by folders of a channel with an alias are those of its
+// successor: the channel name, and the uploader ID and name when they are the
+// channel's. Returns meta itself when the channel has no alias.
+func (d *TelegramDownloader) successorMetadata(url string, meta *domain.MediaMetadata) *domain.MediaMetadata {
+	if d.channelAliasRepo == nil {
+		return meta
+	}
+	stored, err := d.channelAliasRepo.ListChannelAliases()
+	if err != nil {
+		if d.eventLogger != nil {
+			d.eventLogger.LogAppError("failed to list channel aliases", zap.Error(err))
+		}
+		return meta
+	}
+	channelID := extractTelegramChannel(url)
+	successor := domain.NewTelegramChannelAliases(stored).Successor(channelID)
+	if successor == channelID {
+		return meta
+	}
+
+	successorName := d.GetChannelName(successor)
+	organized := *meta
+	organized.Channel = successorName
+	if meta.UploaderID == channelID {
+		organized.UploaderID = successor
+	}
+	if strings.HasPrefix(meta.Uploader, meta.Channel) {
+		organized.Uploader = successorName + strings.TrimPrefix(meta.Uploader, meta.Channel)
+	}
+	return &organized
+}
+
+// createMetadataFile creates a per-file .info.json metadata file using WriteInfoJSON,
+// plus the optional .description.txt companion.
 func (d *TelegramDownloader) createMetadataFile(url, filePath string, messageData *TelegramMessageData) error {
 	meta := d.buildTelegramMetadata(url, messageData, nil)
-	return WriteInfoJSON(filePath, meta)
+	meta.RelativePath = RelativeToCompleted(d.completedDir, filePath)
+	if err := WriteInfoJSON(filePath, meta); err != nil {
+		return err
+	}
+	return d.WriteCompanionFiles(filePath, meta)
 }
 
 // extractHashtags extracts hashtags from message text
@@ -469,11 +766,68 @@ func extractHashtags(text string) []string {
 	return tags
 }
 
-// extractTelegramID extracts ID from Telegram URL
+// telegramURL is a parsed Telegram message link
+type telegramURL struct {
+	Channel   string // Channel username, or numeric ID for private channels
+	Private   bool   // https://t.me/c/<id>/... link
+	TopicID   int    // Forum topic (thread) the message belongs to, 0 if none
+	MessageID string // Empty for channel links
+}
+
+// parseTelegramURL parses a Telegram link. Handles public and private channel
+// links, with or without a forum topic:
+// - https://t.me/channelname/messageid
+// - https://t.me/channelname/topicid/messageid
+// - https://t.me/c/1234567890/messageid
+// - https://t.me/c/1234567890/topicid/messageid
+// The query string (?single, ?comment=...) is ignored. Channel is empty when
+// the link names no channel.
+func parseTelegramURL(url string) telegramURL {
+	if idx := strings.IndexAny(url, "?#"); idx >= 0 {
+		url = url[:idx]
+	}
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	if len(parts) < 2 {
+		return telegramURL{}
+	}
+	parts = parts[1:] // Host
+
+	var parsed telegramURL
+	if parts[0] == "c" {
+		if len(parts) < 2 {
+			return telegramURL{}
+		}
+		parsed.Private = true
+		parts = parts[1:]
+	}
+	parsed.Channel = parts[0]
+	switch rest := parts[1:]; len(rest) {
+	case 1:
+		parsed.MessageID = rest[0]
+	case 2:
+		parsed.TopicID, _ = strconv.Atoi(rest[0])
+		parsed.MessageID = rest[1]
+	}
+	return parsed
+}
+
+// messageURL returns the link to message msgID in the same channel and topic
+func (u telegramURL) messageURL(msgID string) string {
+	base := "https://t.me/" + u.Channel
+	if u.Private {
+		base = "https://t.me/c/" + u.Channel
+	}
+	if u.TopicID > 0 {
+		base += "/" + strconv.Itoa(u.TopicID)
+	}
+	return base + "/" + msgID
+}
+
+// extractTelegramID extracts the message ID from a Telegram URL
 func extractTelegramID(url string) string {
-	parts := strings.Split(url, "/")
-	if len(parts) > 0 {
-		return parts[len(parts)-1]
+	if id := parseTelegramURL(url).MessageID; id != "" {
+		return id
 	}
 	return "unknown"
 }
@@ -482,27 +836,24 @@ func extractTelegramID(url string) string {
 // Handles both public and private channel URLs:
 // - Public: https://t.me/channelname/messageid -> returns "channelname"
 // - Private: https://t.me/c/1234567890/messageid -> returns "1234567890"
+// A forum topic between channel and message does not change the result.
 func extractTelegramChannel(url string) string {
-	// URL format: https://t.me/channelname/messageid
-	// or: https://t.me/c/channelid/messageid (private channels)
-	parts := strings.Split(url, "/")
-	if len(parts) >= 4 {
-		// Check if this is a private channel URL (has /c/ prefix)
-		if parts[3] == "c" && len(parts) >= 5 {
-			// Private channel: https://t.me/c/1234567890/messageid
-			return parts[4]
-		}
-		// Public channel: https://t.me/channelname/messageid
-		return parts[3]
+	if channel := parseTelegramURL(url).Channel; channel != "" {
+		return channel
 	}
 	return "unknown"
 }
 
+// extractTelegramTopic returns the forum topic of a Telegram message URL
+// (https://t.me/c/1234567890/topicid/messageid), or 0 if it has none
+func extractTelegramTopic(url string) int {
+	return parseTelegramURL(url).TopicID
+}
+
 // isPrivateChannelURL checks if a Telegram URL is for a private channel
 // Private channel URLs have format: https://t.me/c/channelid/messageid
 func isPrivateChannelURL(url string) bool {
-	parts := strings.Split(url, "/")
-	return len(parts) >= 5 && parts[3] == "c"
+	return parseTelegramURL(url).Private
 }
 
 // extractSenderInfo extracts sender/uploader information from raw message data
@@ -696,7 +1047,7 @@ func (d *TelegramDownloader) exportAndSaveNewMessages(ctx context.Context, chann
 	)
 
 	// Execute tdl chat export
-	cmd := exec.CommandContext(ctx, d.config.TDLBinary, args...)
+	cmd := d.tdl(ctx, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to export channel: %w, output: %s", err, string(output))
@@ -776,7 +1127,7 @@ func (d *TelegramDownloader) exportAndCacheAllMessages(ctx context.Context, chan
 	)
 
 	// Execute tdl chat export
-	cmd := exec.CommandContext(ctx, d.config.TDLBinary, args...)
+	cmd := d.tdl(ctx, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to export channel: %w, output: %s", err, string(output))
@@ -925,23 +1276,52 @@ func (d *TelegramDownloader) fetchSingleMessageData(ctx context.Context, channel
 // ctx is forwarded so the subprocess is killed if the download is cancelled.
 func (d *TelegramDownloader) exportMessageFromTelegram(ctx context.Context, channel, messageID string, endID int) (*TelegramMessageData, error) {
 	msgIDInt, _ := strconv.Atoi(messageID)
-	rangeArg := fmt.Sprintf("%d,%d", msgIDInt, endID)
+	messages, err := d.exportMessages(ctx, channel, 0, msgIDInt, endID)
+	if err != nil {
+		return nil, err
+	}
 
-	tempFile := filepath.Join(d.incomingDir, fmt.Sprintf("export_%s_%s.json", channel, messageID))
-	defer os.Remove(tempFile)
+	for _, msg := range messages {
+		if fmt.Sprintf("%d", msg.ID) == messageID {
+			return &msg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("message %s not found in export range [%d,%d]", messageID, msgIDInt, endID)
+}
+
+// exportFile returns the temporary file exportMessages writes an export to
+func (d *TelegramDownloader) exportFile(channel string, startID, endID int) string {
+	return filepath.Join(d.incomingDir, fmt.Sprintf("export_%s_%d_%d.json", channel, startID, endID))
+}
 
+// tdlExportArgs returns the tdl chat export arguments of exportMessages
+func (d *TelegramDownloader) tdlExportArgs(channel string, topicID, startID, endID int, output string) []string {
 	args := append(d.tdlBaseArgs(),
 		"chat", "export",
 		"-c", channel,
 		"-T", "id",
-		"-i", rangeArg,
-		"--with-content",
-		"--raw",
-		"-o", tempFile,
+		"-i", fmt.Sprintf("%d,%d", startID, endID),
 	)
+	if topicID > 0 {
+		args = append(args, "--topic", strconv.Itoa(topicID))
+	}
+	return append(args, "--with-content", "--raw", "-o", output)
+}
+
+// exportMessages runs tdl chat export for the message IDs [startID, endID]
+// with content and raw sender data. A topicID above 0 restricts the export to
+// that forum topic.
+func (d *TelegramDownloader) exportMessages(ctx context.Context, channel string, topicID, startID, endID int) ([]TelegramMessageData, error) {
+	rangeArg := fmt.Sprintf("%d,%d", startID, endID)
+
+	tempFile := d.exportFile(channel, startID, endID)
+	defer os.Remove(tempFile)
 
-	cmd := exec.CommandContext(ctx, d.config.TDLBinary, args...)
+	cmd := d.tdl(ctx, d.tdlExportArgs(channel, topicID, startID, endID, tempFile)...)
+	started := time.Now()
 	output, err := cmd.CombinedOutput()
+	recordProcessUsage(ctx, cmd, started)
 	if err != nil {
 		return nil, fmt.Errorf("tdl export [%s]: %w — %s", rangeArg, err, string(output))
 	}
@@ -955,14 +1335,116 @@ func (d *TelegramDownloader) exportMessageFromTelegram(ctx context.Context, chan
 	if err := json.Unmarshal(data, &exportData); err != nil {
 		return nil, fmt.Errorf("parse export data: %w", err)
 	}
+	return exportData.Messages, nil
+}
 
-	for _, msg := range exportData.Messages {
-		if fmt.Sprintf("%d", msg.ID) == messageID {
-			return &msg, nil
+// ListNewItems implements domain.SourceSyncer for Telegram channels. It exports
+// the channel's media messages newer than cursor and returns one single-mode
+// item per message, oldest first. Exported messages are also written to the
+// message cache so the downloads that follow resolve their text without
+// another export.
+func (d *TelegramDownloader) ListNewItems(ctx context.Context, sourceURL, cursor string, limit int) ([]domain.SyncItem, error) {
+	if err := d.Validate(sourceURL); err != nil {
+		return nil, err
+	}
+	channel := extractTelegramChannel(sourceURL)
+	if channel == "unknown" {
+		return nil, fmt.Errorf("invalid Telegram channel URL: %s", sourceURL)
+	}
+
+	if err := os.MkdirAll(d.incomingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create incoming directory: %w", err)
+	}
+	tempFile := filepath.Join(d.incomingDir, fmt.Sprintf("export_sync_%s.json", channel))
+	defer os.Remove(tempFile)
+
+	// Without --all, tdl only exports messages that carry media.
+	args := append(d.tdlBaseArgs(), "chat", "export", "-c", channel)
+	cursorID := parseMessageID(cursor)
+	if cursorID > 0 {
+		args = append(args, "-T", "id", "-i", fmt.Sprintf("%d,%d", cursorID+1, math.MaxInt32))
+	}
+	args = append(args, "--with-content", "--raw", "-o", tempFile)
+
+	cmd := d.tdl(ctx, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export channel: %w, output: %s", err, string(output))
+	}
+
+	data, err := os.ReadFile(tempFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+	var exportData TelegramExportData
+	if err := json.Unmarshal(data, &exportData); err != nil {
+		return nil, fmt.Errorf("failed to parse export data: %w", err)
+	}
+
+	messages := newTelegramMessages(exportData.Messages, cursorID, limit)
+
+	if d.messageCacheRepo != nil && len(messages) > 0 {
+		caches := make([]domain.TelegramMessageCache, 0, len(messages))
+		for _, msg := range messages {
+			caches = append(caches, domain.TelegramMessageCache{
+				ChannelID: channel,
+				MessageID: fmt.Sprintf("%d", msg.ID),
+				Text:      msg.Text,
+				Date:      msg.Date,
+				SenderID:  formatSenderID(msg.Raw),
+				GroupedID: formatGroupedID(msg.Raw),
+			})
+		}
+		if err := d.messageCacheRepo.SaveMessages(caches); err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("Failed to cache synced messages", zap.String("channel", channel), zap.Error(err))
 		}
 	}
 
-	return nil, fmt.Errorf("message %s not found in export range [%s]", messageID, rangeArg)
+	baseURL := "https://t.me/" + channel
+	if isPrivateChannelURL(sourceURL) {
+		baseURL = "https://t.me/c/" + channel
+	}
+	items := make([]domain.SyncItem, 0, len(messages))
+	for _, msg := range messages {
+		id := fmt.Sprintf("%d", msg.ID)
+		items = append(items, domain.SyncItem{
+			ID:       id,
+			URL:      baseURL + "/" + id,
+			Platform: domain.PlatformTelegram,
+			Mode:     domain.ModeSingle,
+		})
+	}
+	return items, nil
+}
+
+// NewestCachedID returns the highest message ID of the channel of sourceURL
+// in the message cache, the newest message seen by an export of the channel,
+// or "" when none is cached
+func (d *TelegramDownloader) NewestCachedID(sourceURL string) (string, error) {
+	if d.messageCacheRepo == nil {
+		return "", nil
+	}
+	id, err := d.messageCacheRepo.GetMaxMessageID(extractTelegramChannel(sourceURL))
+	if err != nil || id == 0 {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+// newTelegramMessages returns the exported messages with IDs above cursorID,
+// sorted oldest first and truncated to limit (limit <= 0 means no limit).
+func newTelegramMessages(messages []TelegramMessageData, cursorID, limit int) []TelegramMessageData {
+	var result []TelegramMessageData
+	for _, msg := range messages {
+		if msg.ID > cursorID {
+			result = append(result, msg)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
 }
 
 // parseTDLProgress parses tdl output to extract progress percentage
@@ -978,11 +1460,11 @@ func parseTDLProgress(line string) float64 {
 
 // FetchChannelList executes `tdl chat ls` command and parses the output
 // to extract channel ID and name mappings
-func (d *TelegramDownloader) FetchChannelList() (map[string]*domain.TelegramChannel, error) {
+func (d *TelegramDownloader) FetchChannelList(ctx context.Context) (map[string]*domain.TelegramChannel, error) {
 	// Build tdl chat ls command
 	args := append(d.tdlBaseArgs(), "chat", "ls")
 
-	cmd := exec.Command(d.config.TDLBinary, args...)
+	cmd := d.tdl(ctx, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute tdl chat ls: %w", err)
@@ -1135,8 +1617,9 @@ func parseTDLChatLine(line string) *domain.TelegramChannel {
 }
 
 // UpdateChannelListIfNeeded checks if the channel list needs updating and updates it if necessary
-// This should be called before processing Telegram downloads
-func (d *TelegramDownloader) UpdateChannelListIfNeeded() error {
+// This should be called before processing Telegram downloads; cancelling ctx
+// stops the `tdl chat ls` run along with the download
+func (d *TelegramDownloader) UpdateChannelListIfNeeded(ctx context.Context) error {
 	if d.channelRepo == nil {
 		return nil // No repository configured, skip
 	}
@@ -1158,27 +1641,58 @@ func (d *TelegramDownloader) UpdateChannelListIfNeeded() error {
 			zap.String("reason", "channel list needs updating"))
 	}
 
-	channels, err := d.FetchChannelList()
+	// Errors are logged by SyncChannelList; don't block downloads on them
+	d.SyncChannelList(ctx)
+	return nil
+}
+
+// SyncChannelList refreshes the stored channel list from `tdl chat ls`,
+// detecting channels whose name or username changed since the last sync.
+// Renamed channels keep their previous name in the repository, so downloads
+// made under it can be re-rendered.
+func (d *TelegramDownloader) SyncChannelList(ctx context.Context) (*domain.ChannelSyncResult, error) {
+	if d.channelRepo == nil {
+		return nil, fmt.Errorf("no channel repository configured")
+	}
+
+	channels, err := d.FetchChannelList(ctx)
 	if err != nil {
 		if d.eventLogger != nil {
 			d.eventLogger.LogAppError("failed to fetch channel list", zap.Error(err))
 		}
-		return nil // Don't block downloads on this error
+		return nil, err
+	}
+	stored, err := d.channelRepo.ListChannels()
+	if err != nil {
+		if d.eventLogger != nil {
+			d.eventLogger.LogAppError("failed to read stored channels", zap.Error(err))
+		}
+		return nil, err
 	}
+	result := domain.DiffChannelList(stored, channels, time.Now())
 
 	if err := d.channelRepo.UpdateChannelList(channels); err != nil {
 		if d.eventLogger != nil {
 			d.eventLogger.LogAppError("failed to update channel list in database", zap.Error(err))
 		}
-		return nil // Don't block downloads on this error
+		return nil, err
 	}
 
 	if d.eventLogger != nil {
+		for _, rename := range result.Renamed {
+			d.eventLogger.LogQueueEvent("telegram_channel_renamed",
+				zap.String("channel_id", rename.ChannelID),
+				zap.String("old_name", rename.OldName),
+				zap.String("new_name", rename.NewName),
+				zap.String("old_username", rename.OldUsername),
+				zap.String("new_username", rename.NewUsername))
+		}
 		d.eventLogger.LogQueueEvent("telegram_channel_update_complete",
-			zap.Int("channels_count", len(channels)))
+			zap.Int("channels_count", len(channels)),
+			zap.Int("added", result.Added),
+			zap.Int("renamed", len(result.Renamed)))
 	}
-
-	return nil
+	return result, nil
 }
 
 // GetChannelName retrieves the channel name for a given channel ID from the repository
@@ -1265,8 +1779,9 @@ func (d *TelegramDownloader) checkFilesExist(files []string) (allExist bool, mis
 	return allExist, missingFiles
 }
 
-// updateMetadataAfterPartialDeletion updates the download metadata to remove deleted files
-func (d *TelegramDownloader) updateMetadataAfterPartialDeletion(download *domain.Download, remainingFiles []string) {
+// partialDeletionResult returns the result of a download whose files were
+// partly deleted by the user: metadata and items of the remaining files only
+func (d *TelegramDownloader) partialDeletionResult(download *domain.Download, remainingFiles []string) *domain.DownloadResult {
 	metadata := map[string]interface{}{
 		"url":      download.URL,
 		"platform": download.Platform,
@@ -1274,8 +1789,24 @@ func (d *TelegramDownloader) updateMetadataAfterPartialDeletion(download *domain
 		"files":    remainingFiles,
 		"note":     "Some files were deleted by user after download",
 	}
-	data, _ := json.Marshal(metadata)
-	download.Metadata = string(data)
+
+	// Drop the items of deleted files, or make one per remaining file if none are loaded
+	remaining := make(map[string]bool, len(remainingFiles))
+	for _, file := range remainingFiles {
+		remaining[file] = true
+	}
+	var items []domain.DownloadItem
+	for _, item := range download.Items {
+		if item.Status == domain.StatusCompleted && remaining[item.FilePath] {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		for _, file := range remainingFiles {
+			items = append(items, domain.DownloadItem{Status: domain.StatusCompleted, FilePath: file})
+		}
+	}
+	return &domain.DownloadResult{Files: remainingFiles, Metadata: metadata, Items: items}
 }
 
 // metadataKeys returns the keys of a metadata map for diagnostic logging
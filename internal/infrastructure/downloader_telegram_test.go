@@ -1,12 +1,16 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/yourusername/x-extract-go/internal/domain"
 )
 
@@ -30,6 +34,15 @@ func (m *mockMessageCacheRepo) SaveMessages(caches []domain.TelegramMessageCache
 }
 func (m *mockMessageCacheRepo) HasChannelCache(channelID string) (bool, error) { return false, nil }
 func (m *mockMessageCacheRepo) GetMaxDate(channelID string) (int64, error)     { return 0, nil }
+func (m *mockMessageCacheRepo) GetMaxMessageID(channelID string) (int, error) {
+	max := 0
+	for _, msg := range m.messages {
+		if id := parseMessageID(msg.MessageID); msg.ChannelID == channelID && id > max {
+			max = id
+		}
+	}
+	return max, nil
+}
 func (m *mockMessageCacheRepo) GetCachedMessages(channelID string) (map[string]bool, error) {
 	return nil, nil
 }
@@ -74,7 +87,7 @@ func TestBuildTDLCommand_IncludesSkipSame(t *testing.T) {
 	downloader := newTestTelegramDownloader(config)
 
 	dl := domain.NewDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault)
-	args := downloader.buildTDLCommand(dl, "/tmp/download")
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/download")
 
 	assert.Contains(t, args, "--skip-same", "tdl command should include --skip-same flag")
 	assert.Contains(t, args, "--continue", "tdl command should include --continue flag to avoid interactive prompt")
@@ -90,7 +103,7 @@ func TestBuildTDLCommand_BasicArgs(t *testing.T) {
 	downloader := newTestTelegramDownloader(config)
 
 	dl := domain.NewDownload("https://t.me/channel/456", domain.PlatformTelegram, domain.ModeDefault)
-	args := downloader.buildTDLCommand(dl, "/tmp/tempdir")
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir")
 
 	assert.Contains(t, args, "-n")
 	assert.Contains(t, args, "myprofile")
@@ -101,6 +114,24 @@ func TestBuildTDLCommand_BasicArgs(t *testing.T) {
 	assert.Contains(t, args, "/tmp/tempdir")
 }
 
+func TestBuildTDLCommand_ConnectionSettings(t *testing.T) {
+	config := &domain.TelegramConfig{
+		Profile:     "default",
+		StorageType: "bolt",
+		StoragePath: "/data/storage",
+		Proxy:       "socks5://127.0.0.1:1080",
+		NTP:         "pool.ntp.org",
+	}
+	downloader := newTestTelegramDownloader(config)
+
+	dl := domain.NewDownload("https://t.me/channel/456", domain.PlatformTelegram, domain.ModeDefault)
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir")
+
+	assert.Equal(t, []string{"--proxy", "socks5://127.0.0.1:1080", "--ntp", "pool.ntp.org"}, args[4:8],
+		"connection settings are global tdl flags, before the subcommand")
+	assert.Equal(t, "dl", args[8])
+}
+
 func TestBuildTDLCommand_GroupMode(t *testing.T) {
 	config := &domain.TelegramConfig{
 		Profile:     "test",
@@ -112,13 +143,13 @@ func TestBuildTDLCommand_GroupMode(t *testing.T) {
 
 	// ModeGroup should force --group
 	dl := domain.NewDownload("https://t.me/channel/789", domain.PlatformTelegram, domain.ModeGroup)
-	args := downloader.buildTDLCommand(dl, "/tmp/tempdir")
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir")
 	assert.Contains(t, args, "--group", "ModeGroup should add --group flag")
 
 	// ModeSingle should NOT have --group even if config says UseGroup=true
 	config.UseGroup = true
 	dl2 := domain.NewDownload("https://t.me/channel/789", domain.PlatformTelegram, domain.ModeSingle)
-	args2 := downloader.buildTDLCommand(dl2, "/tmp/tempdir")
+	args2 := downloader.buildTDLCommand(dl2, dl2.MessageURLs(), "/tmp/tempdir")
 	assert.NotContains(t, args2, "--group", "ModeSingle should not have --group flag")
 }
 
@@ -132,7 +163,7 @@ func TestBuildTDLCommand_RewriteExt(t *testing.T) {
 	downloader := newTestTelegramDownloader(config)
 
 	dl := domain.NewDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault)
-	args := downloader.buildTDLCommand(dl, "/tmp/tempdir")
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir")
 	assert.Contains(t, args, "--rewrite-ext")
 }
 
@@ -146,13 +177,193 @@ func TestBuildTDLCommand_ExtraParams(t *testing.T) {
 	downloader := newTestTelegramDownloader(config)
 
 	dl := domain.NewDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault)
-	args := downloader.buildTDLCommand(dl, "/tmp/tempdir")
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir")
 	assert.Contains(t, args, "--threads")
 	assert.Contains(t, args, "8")
 	assert.Contains(t, args, "--limit")
 	assert.Contains(t, args, "4")
 }
 
+func TestBuildTDLCommand_RateLimit(t *testing.T) {
+	downloader := newTestTelegramDownloader(&domain.TelegramConfig{Profile: "test"})
+	dl := domain.NewDownload("https://t.me/channel/123", domain.PlatformTelegram, domain.ModeDefault)
+	assert.NotContains(t, downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir"), "--limit")
+
+	downloader.SetRateLimit(func(domain.Platform) int64 { return 1 << 20 })
+	args := strings.Join(downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir"), " ")
+	assert.Contains(t, args, "--limit 1 --threads 1", "tdl has no bandwidth cap; throttle to one task and thread")
+}
+
+func TestTelegramForProfile(t *testing.T) {
+	config := &domain.TelegramConfig{
+		Profile:     "default",
+		StorageType: "bolt",
+		StoragePath: "/data/storage",
+		Profiles:    map[string]string{"work": "/data/work"},
+	}
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	downloader := newTestTelegramDownloader(config)
+	downloader.SetChannelRepository(repo)
+	downloader.SetMessageCacheRepository(repo)
+
+	assert.Same(t, downloader, downloader.ForProfile(""))
+	assert.Same(t, downloader, downloader.ForProfile("default"))
+
+	work := downloader.ForProfile("work")
+	assert.Equal(t, []string{"-n", "work", "--storage", "type=bolt,path=/data/work"}, work.tdlBaseArgs())
+	assert.Equal(t, []string{"-n", "default", "--storage", "type=bolt,path=/data/storage"}, downloader.tdlBaseArgs())
+
+	// The profile has its own channel list and message cache
+	require.NoError(t, work.channelRepo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "Work Channel"},
+	}))
+	require.NoError(t, work.messageCacheRepo.SaveMessages([]domain.TelegramMessageCache{
+		{ChannelID: "100", MessageID: "1", Text: "work"},
+	}))
+	assert.Equal(t, "Work Channel", work.GetChannelName("100"))
+	name, err := repo.GetChannelName("100")
+	require.NoError(t, err)
+	assert.Empty(t, name)
+	cached, err := repo.GetMessage("100", "1")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+}
+
+func TestBuildTDLCommand_MessageRange(t *testing.T) {
+	downloader := newTestTelegramDownloader(&domain.TelegramConfig{Profile: "test"})
+
+	dl := domain.NewDownload("https://t.me/c/12345/100", domain.PlatformTelegram, domain.ModeDefault)
+	dl.RangeStart, dl.RangeEnd = 100, 102
+	args := downloader.buildTDLCommand(dl, dl.MessageURLs(), "/tmp/tempdir")
+
+	var urls []string
+	for i, arg := range args {
+		if arg == "-u" {
+			urls = append(urls, args[i+1])
+		}
+	}
+	assert.Equal(t, []string{
+		"https://t.me/c/12345/100",
+		"https://t.me/c/12345/101",
+		"https://t.me/c/12345/102",
+	}, urls)
+}
+
+func TestParseTelegramURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want telegramURL
+	}{
+		{"https://t.me/channel/789", telegramURL{Channel: "channel", MessageID: "789"}},
+		{"https://t.me/channel/55/789", telegramURL{Channel: "channel", TopicID: 55, MessageID: "789"}},
+		{"https://t.me/c/1234567890/789", telegramURL{Channel: "1234567890", Private: true, MessageID: "789"}},
+		{"https://t.me/c/1234567890/55/789", telegramURL{Channel: "1234567890", Private: true, TopicID: 55, MessageID: "789"}},
+		{"https://t.me/c/1234567890/55/789?single", telegramURL{Channel: "1234567890", Private: true, TopicID: 55, MessageID: "789"}},
+		{"https://t.me/channel", telegramURL{Channel: "channel"}},
+		{"https://t.me/c/", telegramURL{}},
+		{"https://t.me", telegramURL{}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseTelegramURL(tt.url), tt.url)
+	}
+
+	url := "https://t.me/c/1234567890/55/789"
+	assert.Equal(t, "1234567890", extractTelegramChannel(url))
+	assert.Equal(t, "789", extractTelegramID(url))
+	assert.Equal(t, 55, extractTelegramTopic(url))
+	assert.True(t, isPrivateChannelURL(url))
+	assert.Equal(t, "https://t.me/c/1234567890/55/790", parseTelegramURL(url).messageURL("790"))
+	assert.Equal(t, "https://t.me/channel/790", parseTelegramURL("https://t.me/channel/789").messageURL("790"))
+	assert.Equal(t, "unknown", extractTelegramID("https://t.me/channel"))
+}
+
+func TestBuildTelegramMetadata_KeepsTopic(t *testing.T) {
+	downloader := newTestTelegramDownloader(&domain.TelegramConfig{})
+
+	meta := downloader.buildTelegramMetadata("https://t.me/c/1234567890/55/789", nil, nil)
+	assert.Equal(t, "789", meta.ID)
+	assert.Equal(t, "https://t.me/c/1234567890/55/789", meta.WebpageURL)
+	assert.Equal(t, "https://t.me/c/1234567890", meta.UploaderURL)
+}
+
+func TestTopicMessageURLs(t *testing.T) {
+	dl := domain.NewDownload("https://t.me/c/12345/55/100", domain.PlatformTelegram, domain.ModeDefault)
+	dl.RangeStart, dl.RangeEnd = 100, 104
+	messages := map[string]*TelegramMessageData{
+		"101": {ID: 101},
+		"104": {ID: 104},
+	}
+
+	downloader := newTestTelegramDownloader(&domain.TelegramConfig{Profile: "test"})
+	args := strings.Join(downloader.buildTDLCommand(dl, topicMessageURLs(dl, messages), "/tmp/tempdir"), " ")
+	assert.Contains(t, args, "-u https://t.me/c/12345/55/101 -u https://t.me/c/12345/55/104 -d")
+	assert.NotContains(t, args, "/100")
+}
+
+func TestFinishRangeFiles_WritesPerMessageMetadata(t *testing.T) {
+	completedDir := t.TempDir()
+	downloader := NewTelegramDownloader(&domain.TelegramConfig{TDLBinary: filepath.Join(completedDir, "missing-tdl")},
+		t.TempDir(), completedDir, t.TempDir(), nil)
+
+	var files []string
+	for _, name := range []string{"12345_100_1.jpg", "12345_100_2.jpg", "12345_101_3.mp4"} {
+		path := filepath.Join(completedDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+		files = append(files, path)
+	}
+
+	dl := domain.NewDownload("https://t.me/c/12345/100", domain.PlatformTelegram, domain.ModeDefault)
+	dl.RangeStart, dl.RangeEnd = 100, 101
+
+	// The export fails without tdl; files still get fallback metadata for their own message
+	finished, items, first := downloader.finishRangeFiles(context.Background(), dl, files, nil)
+	assert.Equal(t, files, finished)
+	assert.Nil(t, first)
+	require.Len(t, items, 3)
+	assert.Equal(t, "100", items[0].SourceID)
+	assert.Equal(t, "https://t.me/c/12345/101", items[2].SourceURL)
+
+	for file, webpageURL := range map[string]string{
+		files[1]: "https://t.me/c/12345/100",
+		files[2]: "https://t.me/c/12345/101",
+	} {
+		data, err := os.ReadFile(strings.TrimSuffix(file, filepath.Ext(file)) + ".info.json")
+		require.NoError(t, err)
+		var meta map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &meta))
+		assert.Equal(t, webpageURL, meta["webpage_url"])
+	}
+}
+
+func TestFinishMessageFiles_OrganizesAliasIntoSuccessor(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	require.NoError(t, repo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "Old Group", ChannelType: "group", Username: "-"},
+		"200": {ChannelID: "200", ChannelName: "New Group", ChannelType: "channel", Username: "-"},
+	}))
+	require.NoError(t, repo.SaveChannelAlias(&domain.TelegramChannelAlias{ChannelID: "100", SuccessorID: "200"}))
+
+	completedDir := t.TempDir()
+	downloader := NewTelegramDownloader(&domain.TelegramConfig{}, t.TempDir(), completedDir, t.TempDir(), nil)
+	downloader.SetChannelRepository(repo)
+	downloader.SetChannelAliasRepository(repo)
+	downloader.SetOrganizeBy(domain.OrganizeChannel)
+
+	file := filepath.Join(completedDir, "100_7_1.jpg")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0644))
+	finished := downloader.finishMessageFiles("https://t.me/c/100/7", []string{file}, nil)
+	assert.Equal(t, []string{filepath.Join(completedDir, "New Group", "100_7_1.jpg")}, finished)
+
+	// The metadata keeps the channel the message was posted in
+	data, err := os.ReadFile(filepath.Join(completedDir, "New Group", "100_7_1.info.json"))
+	require.NoError(t, err)
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &meta))
+	assert.Equal(t, "Old Group", meta["channel"])
+}
+
 func TestGetExistingDownloadedFiles_WithMetadata(t *testing.T) {
 	config := &domain.TelegramConfig{}
 	downloader := newTestTelegramDownloader(config)
@@ -237,7 +448,7 @@ func TestCheckFilesExist_SomeMissing(t *testing.T) {
 	assert.Contains(t, missing[0], "/nonexistent/path/file.mp4")
 }
 
-func TestUpdateMetadataAfterPartialDeletion(t *testing.T) {
+func TestPartialDeletionResult(t *testing.T) {
 	config := &domain.TelegramConfig{}
 	downloader := newTestTelegramDownloader(config)
 
@@ -248,8 +459,14 @@ func TestUpdateMetadataAfterPartialDeletion(t *testing.T) {
 		Metadata: `{"files": ["/tmp/completed/file1.mp4", "/tmp/completed/file2.jpg", "/tmp/completed/file3.jpg"]}`,
 	}
 
+	download.Items = []domain.DownloadItem{
+		{Status: domain.StatusCompleted, FilePath: "/tmp/completed/file1.mp4"},
+		{Status: domain.StatusCompleted, FilePath: "/tmp/completed/file2.mp4"},
+	}
 	remainingFiles := []string{"/tmp/completed/file1.mp4", "/tmp/completed/file3.jpg"}
-	downloader.updateMetadataAfterPartialDeletion(download, remainingFiles)
+	download.ApplyResult(downloader.partialDeletionResult(download, remainingFiles))
+	assert.Equal(t, "/tmp/completed/file1.mp4", download.FilePath)
+	require.Len(t, download.Items, 1, "items of deleted files are dropped")
 
 	// Verify metadata was updated
 	var metadata map[string]interface{}
@@ -421,3 +638,138 @@ func TestCachedToMessageData_RealWorldScenario(t *testing.T) {
 	assert.Equal(t, 1906, result.ID)
 	assert.Equal(t, "Kengo系列六期。本期共3个批次，第2批次。#DJ0005 🔺会员专享🔻", result.Text)
 }
+
+func TestTelegramDownloader_NewestCachedID(t *testing.T) {
+	id, err := newTestTelegramDownloader(&domain.TelegramConfig{}).NewestCachedID("https://t.me/somechannel")
+	require.NoError(t, err)
+	assert.Empty(t, id, "no message cache")
+
+	d := newTestTelegramDownloaderWithMockRepo(&mockMessageCacheRepo{messages: []domain.TelegramMessageCache{
+		{ChannelID: "somechannel", MessageID: "41"},
+		{ChannelID: "somechannel", MessageID: "120"},
+		{ChannelID: "1234567890", MessageID: "7"},
+	}})
+	id, err = d.NewestCachedID("https://t.me/somechannel")
+	require.NoError(t, err)
+	assert.Equal(t, "120", id)
+
+	id, err = d.NewestCachedID("https://t.me/c/1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, "7", id)
+
+	id, err = d.NewestCachedID("https://t.me/otherchannel")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestNewTelegramMessages(t *testing.T) {
+	messages := []TelegramMessageData{{ID: 7}, {ID: 3}, {ID: 9}, {ID: 5}, {ID: 8}}
+
+	result := newTelegramMessages(messages, 5, 2)
+	require.Len(t, result, 2)
+	assert.Equal(t, 7, result[0].ID)
+	assert.Equal(t, 8, result[1].ID)
+
+	assert.Len(t, newTelegramMessages(messages, 0, 0), 5)
+	assert.Empty(t, newTelegramMessages(messages, 9, 10))
+}
+
+func TestTelegramVerifySession(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tdl")
+	config := &domain.TelegramConfig{TDLBinary: binary, Profile: "work", StorageType: "bolt", StoragePath: dir}
+	d := NewTelegramDownloader(config, dir, dir, dir, nil)
+
+	t.Run("chats listed", func(t *testing.T) {
+		script := "#!/bin/sh\n" +
+			"echo 'ID         Type     VisibleName          Username             Topics'\n" +
+			"echo '1454687932 group    Friends              -                    -'\n" +
+			"echo '3464638440 channel  News                 news                 -'\n"
+		require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+
+		session := d.VerifySession(context.Background())
+		assert.Equal(t, "work", session.Profile)
+		assert.True(t, session.Verified)
+		assert.Equal(t, 2, session.Chats)
+		assert.Empty(t, session.Error)
+		assert.False(t, session.VerifiedAt.IsZero())
+	})
+
+	t.Run("not logged in", func(t *testing.T) {
+		script := "#!/bin/sh\necho 'Error: not authorized. please login first' >&2\nexit 1\n"
+		require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+
+		session := d.VerifySession(context.Background())
+		assert.False(t, session.Verified)
+		assert.Contains(t, session.Error, "not authorized")
+	})
+}
+
+func TestTelegramSyncChannelList(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	require.NoError(t, repo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"3464638440": {ChannelID: "3464638440", ChannelName: "Old News", ChannelType: "channel", Username: "-"},
+	}))
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tdl")
+	script := "#!/bin/sh\n" +
+		"echo 'ID         Type     VisibleName          Username             Topics'\n" +
+		"echo '1454687932 group    Friends              -                    -'\n" +
+		"echo '3464638440 channel  News                 news                 -'\n"
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+	d := NewTelegramDownloader(&domain.TelegramConfig{TDLBinary: binary}, dir, dir, dir, nil)
+	d.SetChannelRepository(repo)
+
+	result, err := d.SyncChannelList(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Channels)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, []domain.ChannelRename{{
+		ChannelID: "3464638440", OldName: "Old News", NewName: "News", OldUsername: "-", NewUsername: "news",
+	}}, result.Renamed)
+
+	channel, err := repo.GetChannel("3464638440")
+	require.NoError(t, err)
+	assert.Equal(t, "News", channel.ChannelName)
+	assert.Equal(t, "Old News", channel.PreviousName)
+
+	// Nothing changed since
+	result, err = d.SyncChannelList(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.Added)
+	assert.Empty(t, result.Renamed)
+	channel, err = repo.GetChannel("3464638440")
+	require.NoError(t, err)
+	assert.Equal(t, "Old News", channel.PreviousName, "the last rename is kept")
+}
+
+func TestTelegramUpdateChannelListIfNeeded_StopsWithContext(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tdl")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\nsleep 30\n"), 0755))
+	d := NewTelegramDownloader(&domain.TelegramConfig{TDLBinary: binary}, dir, dir, dir, nil)
+	d.SetChannelRepository(repo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.NoError(t, d.UpdateChannelListIfNeeded(ctx), "a failed refresh does not block the download")
+	assert.Less(t, time.Since(start), SubprocessKillGrace, "tdl is stopped when the download is cancelled")
+}
+
+func TestTelegramLoginCommand(t *testing.T) {
+	config := &domain.TelegramConfig{TDLBinary: "tdl", Profile: "work", StorageType: "bolt", StoragePath: "/data/tdl"}
+	d := NewTelegramDownloader(config, "", "", "", nil)
+
+	cmd := d.LoginCommand("qr")
+	args := strings.Join(cmd.Args[1:], " ")
+	assert.True(t, strings.HasPrefix(args, "-n work --storage type=bolt,path=/data/tdl"), args)
+	assert.True(t, strings.HasSuffix(args, "login --type qr"), args)
+
+	assert.NotContains(t, strings.Join(d.LoginCommand("").Args, " "), "--type")
+}
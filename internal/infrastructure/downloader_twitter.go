@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,16 +25,30 @@ const ytDLPNoVideoMarker = "No video could be found in this tweet"
 
 // TwitterDownloader implements Downloader for X/Twitter
 type TwitterDownloader struct {
-	DownloadLogger // Embedded shared log file operations
-	config         *domain.TwitterConfig
-	incomingDir    string
-	completedDir   string
-	eventLogger    *logger.MultiLogger // For structured events only (LogQueueEvent, LogAppError)
-	fallback       domain.Downloader   // Optional fallback for photo-only tweets (gallery-dl)
+	DownloadLogger     // Embedded shared log file operations
+	MetadataExtensions // Embedded user-configured extra metadata fields
+	BandwidthLimit     // Embedded download.rate_limit lookup
+	config             *domain.TwitterConfig
+	incomingDir        string
+	completedDir       string
+	eventLogger        *logger.MultiLogger // For structured events only (LogQueueEvent, LogAppError)
+	fallback           domain.Downloader   // Optional fallback for photo-only tweets (gallery-dl)
+	native             *XAPIClient         // Optional native X API extractor (metadata and photos)
+	accounts           *xAccountPool       // Cookie accounts of yt-dlp runs
+}
+
+// SetNativeExtractor enables the native X API extractor. Each tweet is then
+// fetched with the cookie session first: photo-only tweets are downloaded
+// directly, and its text, alt text and counts fill in whatever yt-dlp's
+// .info.json lacks. Videos are still downloaded by yt-dlp, except for
+// all-variants downloads, which save every listed rendition directly.
+func (d *TwitterDownloader) SetNativeExtractor(client *XAPIClient) {
+	d.native = client
 }
 
 // SetFallback sets the downloader to use when yt-dlp reports no video in the
 // tweet (photo-only posts). Typically wired to the gallery-dl downloader.
+// With twitter.backend set to gallery-dl it handles every tweet instead.
 func (d *TwitterDownloader) SetFallback(fallback domain.Downloader) {
 	d.fallback = fallback
 }
@@ -45,6 +61,7 @@ func NewTwitterDownloader(config *domain.TwitterConfig, incomingDir, completedDi
 		incomingDir:    incomingDir,
 		completedDir:   completedDir,
 		eventLogger:    eventLogger,
+		accounts:       newXAccountPool(config),
 	}
 }
 
@@ -62,115 +79,427 @@ func (d *TwitterDownloader) Validate(url string) error {
 }
 
 // Download downloads media from Twitter/X
-func (d *TwitterDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) error {
+func (d *TwitterDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	return d.download(ctx, download, d.config.Backend, d.fallback, progressCallback)
+}
+
+// Backend returns a downloader of X posts that only uses backend (ytdlp or
+// x-api), one link of a fallback chain (download.fallback_chains). Its yt-dlp
+// runs do not fall back to gallery-dl for photo-only tweets; x-api downloads
+// the media the native extractor lists, without yt-dlp.
+func (d *TwitterDownloader) Backend(backend string) domain.Downloader {
+	return &twitterBackend{TwitterDownloader: d, backend: backend}
+}
+
+// twitterBackend is a TwitterDownloader limited to one backend
+type twitterBackend struct {
+	*TwitterDownloader
+	backend string
+}
+
+// Download downloads media from Twitter/X with the backend alone
+func (b *twitterBackend) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	return b.download(ctx, download, b.backend, nil, progressCallback)
+}
+
+// download downloads media from Twitter/X with backend (twitter.backend or a
+// backend of a fallback chain). fallback is the downloader photo-only tweets
+// are left to when yt-dlp finds no video (nil = none).
+func (d *TwitterDownloader) download(ctx context.Context, download *domain.Download, backend string, fallback domain.Downloader, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
 	// Validate URL
 	if err := d.Validate(download.URL); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Ensure incoming directory exists
-	if err := os.MkdirAll(d.incomingDir, 0755); err != nil {
-		return fmt.Errorf("failed to create incoming directory: %w", err)
+	// twitter.backend: gallery-dl downloads the tweet and normalizes its
+	// metadata to the yt-dlp .info.json shape
+	if backend == domain.TwitterBackendGalleryDL {
+		if fallback == nil {
+			return nil, fmt.Errorf("twitter backend %s is not available", domain.TwitterBackendGalleryDL)
+		}
+		return fallback.Download(ctx, download, progressCallback)
 	}
-
-	// Build yt-dlp command - download to incoming directory
-	// Note: exec.Command passes args directly to process, no shell quoting needed
-	args := []string{
-		"--write-info-json",
-		"--write-playlist-metafiles",
-		"--restrict-filenames",
-		"-o", "%(uploader_id)s_%(id)s.%(ext)s",
-		"-P", d.incomingDir,
+	if backend == domain.BackendXAPI {
+		if d.native == nil {
+			return nil, &domain.ToolError{Tool: domain.BackendXAPI, Err: fmt.Errorf("needs twitter.native_extractor"), Code: domain.ErrorToolMissing}
+		}
+		if download.Mode == domain.ModeProfile {
+			return nil, &domain.ToolError{Tool: domain.BackendXAPI, Err: fmt.Errorf("profiles are downloaded with yt-dlp"), Code: domain.ErrorParse}
+		}
 	}
 
-	// Add cookie file if configured
-	if d.config.CookieFile != "" && FileExists(d.config.CookieFile) {
-		args = append(args, "--cookies", d.config.CookieFile)
+	if download.Account != "" && !d.config.HasAccount(download.Account) {
+		return nil, fmt.Errorf("unknown X account: %s", download.Account)
 	}
 
-	args = append(args, download.URL)
+	// Ensure incoming directory exists
+	if err := os.MkdirAll(d.incomingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create incoming directory: %w", err)
+	}
 
 	// Create default callback if nil
 	if progressCallback == nil {
-		progressCallback = func(output string, percent float64) {}
+		progressCallback = func(progress domain.DownloadProgress) {}
 	}
 
 	// Open per-download log file so parallel downloads don't interleave.
 	downloadLog, err := d.OpenDownloadLogFile(download.ID)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
 	defer downloadLog.Close()
 
-	// Write command header to download log (with proper shell escaping for display)
-	cmdLine := ShellEscapeCommand(d.config.YTDLPBinary, args...)
-	d.WriteLogHeader(downloadLog, download.ID, cmdLine)
+	// Profile backup: yt-dlp walks the whole profile as a playlist
+	if download.Mode == domain.ModeProfile {
+		return d.downloadProfile(ctx, download, progressCallback, downloadLog)
+	}
 
-	// Execute yt-dlp. Tee output to a buffer so we can detect the photo-only
-	// "No video could be found" error without re-reading the log file.
-	// CommandContext ensures the process is killed if ctx is cancelled.
-	var outputBuf bytes.Buffer
-	sink := io.MultiWriter(downloadLog, &outputBuf)
-	cmd := exec.CommandContext(ctx, d.config.YTDLPBinary, args...)
-	cmd.Stdout = sink
-	cmd.Stderr = sink
+	// Native extractor: fetch the tweet first. Failures only cost the extra
+	// metadata; yt-dlp still runs.
+	var tweet *XTweet
+	if backend == domain.BackendXAPI {
+		if tweet, err = d.fetchNativeOnly(ctx, download.URL, downloadLog); err != nil {
+			progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+			return nil, err
+		}
+	} else if d.native != nil {
+		tweet = d.fetchNativeTweet(ctx, download.URL, downloadLog)
+	}
 
-	// Run command and check exit code
-	err = cmd.Run()
+	var files []string
+	var variants []domain.MediaVariant
+	var clientProfile string // Of the yt-dlp run; the native extractor has none
+	if tweet != nil && download.AllVariants && tweet.HasAllVariants() {
+		d.WriteLogHeader(downloadLog, download.ID, fmt.Sprintf("x-api all variants %s", download.URL))
+		files, variants, err = d.downloadAllVariants(ctx, tweet)
+		if err != nil {
+			d.removePartialFiles(download.URL)
+			if ctx.Err() != nil {
+				d.WriteLogFooter(downloadLog, false, "Cancelled")
+				return nil, fmt.Errorf("variant download cancelled: %w", ctx.Err())
+			}
+			d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Variant download failed: %v", err))
+			progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+			return nil, fmt.Errorf("variant download failed: %w", err)
+		}
+	} else if tweet != nil && (tweet.IsPhotoOnly() || backend == domain.BackendXAPI) {
+		d.WriteLogHeader(downloadLog, download.ID, fmt.Sprintf("x-api media %s", download.URL))
+		files, err = d.downloadMedia(ctx, tweet)
+		if err != nil {
+			d.removePartialFiles(download.URL)
+			if ctx.Err() != nil {
+				d.WriteLogFooter(downloadLog, false, "Cancelled")
+				return nil, fmt.Errorf("media download cancelled: %w", ctx.Err())
+			}
+			d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Media download failed: %v", err))
+			progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+			return nil, toolError(domain.BackendXAPI, err, err.Error())
+		}
+	} else {
+		var fallbackResult *domain.DownloadResult
+		files, fallbackResult, clientProfile, err = d.runYTDLP(ctx, download, fallback, progressCallback, downloadLog)
+		if err != nil {
+			return nil, domain.WithClientProfile(err, clientProfile)
+		}
+		if fallbackResult != nil {
+			fallbackResult.ClientProfile = clientProfile
+			return fallbackResult, nil
+		}
+		if download.AllVariants {
+			variants = ytdlpVariants(files, tweetIDFromURL(download.URL))
+		}
+	}
 
-	// Write completion marker
+	if len(files) == 0 {
+		d.WriteLogFooter(downloadLog, false, "No files downloaded")
+		return nil, domain.WithClientProfile(fmt.Errorf("no files downloaded"), clientProfile)
+	}
+
+	// Move files from incoming to completed directory
+	completedFiles, err := d.moveToCompleted(files, progressCallback)
 	if err != nil {
+		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to move files: %v", err))
+		return nil, domain.WithClientProfile(fmt.Errorf("failed to move files to completed: %w", err), clientProfile)
+	}
+
+	// Apply the configured filename template and subdirectory layout (sidecars follow)
+	completedFiles = d.applyLayout(download.URL, completedFiles, tweet)
+
+	// Variant labels follow their files through the move, rename and organize
+	for i := range variants {
+		variants[i].File = completedFiles[i]
+	}
+
+	result := &domain.DownloadResult{Files: completedFiles, ClientProfile: clientProfile}
+	if d.config.WriteMetadata {
+		result.Metadata = d.writeMetadata(download.URL, completedFiles, tweet, variants).ToMap()
+	}
+
+	// Log successful completion
+	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded: %s", completedFiles[0]))
+	progressCallback(domain.DownloadProgress{Percent: 100}) // Signal success
+
+	return result, nil
+}
+
+// ytdlp builds a yt-dlp command run under twitter.wrapper
+func (d *TwitterDownloader) ytdlp(ctx context.Context, args ...string) *exec.Cmd {
+	return CommandWrapper(d.config.Wrapper).Command(ctx, d.config.YTDLPBinary, args...)
+}
+
+// ytdlpCommandLine returns the command line of a yt-dlp run, for download logs
+func (d *TwitterDownloader) ytdlpCommandLine(args ...string) string {
+	return CommandWrapper(d.config.Wrapper).CommandLine(d.config.YTDLPBinary, args...)
+}
+
+// ytdlpTweetArgs returns the yt-dlp arguments of a tweet download, without
+// the account's cookies and the URL
+func (d *TwitterDownloader) ytdlpTweetArgs(download *domain.Download) []string {
+	// Intermediate name used to find this tweet's files in incoming;
+	// download.filename_template is applied after the move to completed.
+	// All variants keep every format yt-dlp lists, each under its format ID.
+	outputTemplate := "%(uploader_id)s_%(id)s.%(ext)s"
+	if download.AllVariants {
+		outputTemplate = "%(uploader_id)s_%(id)s_%(format_id)s.%(ext)s"
+	}
+
+	// Build yt-dlp command - download to incoming directory
+	// Note: exec.Command passes args directly to process, no shell quoting needed
+	args := []string{
+		"--write-info-json",
+		"--write-playlist-metafiles",
+		"--restrict-filenames",
+		"-o", outputTemplate,
+		"-P", d.incomingDir,
+	}
+	if download.AllVariants {
+		args = append(args, "-f", "all")
+	}
+	args = append(args, ytdlpClientArgs(d.config.Impersonate, d.config.UserAgent)...)
+	return append(args, limitRateArgs(d.rateLimitFor(download.Platform))...)
+}
+
+// runYTDLP downloads the tweet with yt-dlp into the incoming directory and
+// returns the media files. fallbackResult is the result of fallback (the
+// gallery-dl downloader, or nil for none) when yt-dlp found no video and it
+// completed the download instead. Unless the download is pinned to an
+// account, a run X rate limits or rejects is repeated with the next account;
+// clientProfile describes the client settings of the last run.
+func (d *TwitterDownloader) runYTDLP(ctx context.Context, download *domain.Download, fallback domain.Downloader, progressCallback domain.DownloadProgressCallback, downloadLog *os.File) (files []string, fallbackResult *domain.DownloadResult, clientProfile string, err error) {
+	baseArgs := d.ytdlpTweetArgs(download)
+
+	accounts := d.accounts.candidates(download.Account)
+	if len(accounts) == 0 {
+		accounts = []string{""}
+	}
+	for i, account := range accounts {
+		// Add the account's cookie file if configured
+		args := append(append([]string{}, baseArgs...), d.accounts.cookieArgs(account)...)
+		args = append(args, download.URL)
+
+		clientProfile = d.accounts.clientProfile(account)
+
+		// Write command header to download log (with proper shell escaping for display)
+		cmdLine := d.ytdlpCommandLine(args...)
+		d.WriteLogHeader(downloadLog, download.ID, cmdLine)
+
+		// Execute yt-dlp. Tee output to a buffer so we can detect the photo-only
+		// "No video could be found" error without re-reading the log file.
+		// CommandWithCancel terminates the process group if ctx is cancelled.
+		var outputBuf bytes.Buffer
+		sink := io.MultiWriter(downloadLog, &outputBuf, newProgressWriter(progressCallback))
+		cmd := d.ytdlp(ctx, args...)
+		cmd.Stdout = sink
+		cmd.Stderr = sink
+
+		// Run command and check exit code
+		started := time.Now()
+		err = cmd.Run()
+		recordProcessUsage(ctx, cmd, started)
+		if err == nil {
+			break
+		}
+
+		// Cancelled: yt-dlp writes straight into the shared incoming dir, so
+		// remove its partial files for this tweet before returning.
+		if ctx.Err() != nil {
+			d.removePartialFiles(download.URL)
+			d.WriteLogFooter(downloadLog, false, "Cancelled")
+			return nil, nil, clientProfile, fmt.Errorf("yt-dlp cancelled: %w", ctx.Err())
+		}
 		// Photo-only tweets: yt-dlp has nothing to grab. Fall back to gallery-dl.
-		if d.fallback != nil && strings.Contains(outputBuf.String(), ytDLPNoVideoMarker) {
+		if fallback != nil && strings.Contains(outputBuf.String(), ytDLPNoVideoMarker) {
 			fmt.Fprintf(downloadLog, "\n[twitter] no video in tweet — falling back to gallery-dl\n")
-			if fbErr := d.fallback.Download(ctx, download, progressCallback); fbErr != nil {
+			result, fbErr := fallback.Download(ctx, download, progressCallback)
+			if fbErr != nil {
 				d.WriteLogFooter(downloadLog, false, fmt.Sprintf("gallery-dl fallback failed: %v", fbErr))
-				return fmt.Errorf("gallery-dl fallback failed: %w", fbErr)
+				return nil, nil, clientProfile, fmt.Errorf("gallery-dl fallback failed: %w", fbErr)
+			}
+			d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded via gallery-dl: %s", result.Files[0]))
+			return nil, result, clientProfile, nil
+		}
+
+		retryAfter, limited := detectRateLimit(outputBuf.String())
+		if limited {
+			d.accounts.rateLimited(account, retryAfter)
+		}
+		if (limited || xAuthErrorRe.MatchString(outputBuf.String())) && i < len(accounts)-1 {
+			d.removePartialFiles(download.URL)
+			fmt.Fprintf(downloadLog, "\n[twitter] account %s failed — retrying with account %s\n", account, accounts[i+1])
+			if d.eventLogger != nil {
+				d.eventLogger.LogQueueEvent("x_account_failed_over",
+					zap.String("download_id", download.ID),
+					zap.String("account", account),
+					zap.String("next_account", accounts[i+1]),
+					zap.Bool("rate_limited", limited))
 			}
-			d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded via gallery-dl: %s", download.FilePath))
-			return nil
+			continue
 		}
+
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("yt-dlp failed: %v", err))
-		progressCallback("", -1) // Signal failure
-		return fmt.Errorf("yt-dlp failed: %w", err)
+		progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+		// HTTP 429: let the download manager wait for Retry-After instead of
+		// retrying on the fixed delay.
+		if limited {
+			return nil, nil, clientProfile, &domain.RateLimitError{RetryAfter: retryAfter, Err: toolError("yt-dlp", err, outputBuf.String())}
+		}
+		return nil, nil, clientProfile, toolError("yt-dlp", err, outputBuf.String())
 	}
 
 	// Find downloaded files in incoming directory
-	files, err := d.findDownloadedFiles(download.URL)
+	files, err = d.findDownloadedFiles(download.URL)
 	if err != nil {
 		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to find files: %v", err))
-		return err
+		return nil, nil, clientProfile, err
 	}
+	return files, nil, clientProfile, nil
 
-	if len(files) == 0 {
-		d.WriteLogFooter(downloadLog, false, "No files downloaded")
-		return fmt.Errorf("no files downloaded")
+}
+
+// fetchNativeTweet fetches the tweet with the native X API extractor, logging
+// and returning nil on failure
+func (d *TwitterDownloader) fetchNativeTweet(ctx context.Context, url string, downloadLog io.Writer) *XTweet {
+	tweetID := tweetIDFromURL(url)
+	if tweetID == "" {
+		return nil
 	}
+	tweet, err := d.native.FetchTweet(ctx, tweetID)
+	if err != nil {
+		fmt.Fprintf(downloadLog, "[x-api] failed to fetch tweet %s: %v\n", tweetID, err)
+		return nil
+	}
+	fmt.Fprintf(downloadLog, "[x-api] fetched tweet %s by @%s (%d media)\n", tweet.ID, tweet.ScreenName, len(tweet.Media))
+	return tweet
+}
 
-	// Move files from incoming to completed directory
-	completedFiles, err := d.moveToCompleted(files)
+// fetchNativeOnly fetches the tweet for the x-api backend, which has nothing
+// to download without it. Failures are returned as x-api tool errors, rate
+// limits as they are.
+func (d *TwitterDownloader) fetchNativeOnly(ctx context.Context, url string, downloadLog io.Writer) (*XTweet, error) {
+	tweetID := tweetIDFromURL(url)
+	if tweetID == "" {
+		return nil, &domain.ToolError{Tool: domain.BackendXAPI, Err: fmt.Errorf("no tweet ID in %s", url), Code: domain.ErrorParse}
+	}
+	tweet, err := d.native.FetchTweet(ctx, tweetID)
 	if err != nil {
-		d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to move files: %v", err))
-		return fmt.Errorf("failed to move files to completed: %w", err)
+		fmt.Fprintf(downloadLog, "[x-api] failed to fetch tweet %s: %v\n", tweetID, err)
+		var rateLimit *domain.RateLimitError
+		if errors.As(err, &rateLimit) {
+			return nil, err
+		}
+		return nil, toolError(domain.BackendXAPI, err, err.Error())
+	}
+	if len(tweet.Media) == 0 {
+		fmt.Fprintf(downloadLog, "[x-api] no media in tweet %s\n", tweetID)
+		return nil, &domain.ToolError{Tool: domain.BackendXAPI, Err: fmt.Errorf("no media in tweet %s", tweetID), Code: domain.ErrorParse}
 	}
+	fmt.Fprintf(downloadLog, "[x-api] fetched tweet %s by @%s (%d media)\n", tweet.ID, tweet.ScreenName, len(tweet.Media))
+	return tweet, nil
+}
 
-	// Store metadata
-	if d.config.WriteMetadata {
-		if err := d.storeMetadata(download, completedFiles); err != nil {
-			if d.eventLogger != nil {
-				d.eventLogger.LogAppError("Failed to store metadata", zap.Error(err))
+// downloadMedia saves the photos of a tweet at original resolution and the
+// best MP4 rendition of each video or GIF into the incoming directory, using
+// the yt-dlp intermediate naming ({uploader_id}_{id}_{n})
+func (d *TwitterDownloader) downloadMedia(ctx context.Context, tweet *XTweet) ([]string, error) {
+	var files []string
+	for i, media := range tweet.Media {
+		mediaURL, ext := media.URL, photoExtension(media.URL)
+		if media.Type != "photo" {
+			variants := media.MP4Variants()
+			if len(variants) == 0 {
+				return nil, fmt.Errorf("unable to extract an MP4 rendition of media %d", i+1)
 			}
+			mediaURL, ext = variants[0].URL, ".mp4"
+		}
+		name := fmt.Sprintf("%s_%s_%d%s", tweet.ScreenName, tweet.ID, i+1, ext)
+		dest := filepath.Join(d.incomingDir, SanitizeFilename(name))
+		if err := d.native.DownloadMedia(ctx, mediaURL, dest); err != nil {
+			return nil, err
+		}
+		files = append(files, dest)
+	}
+	return files, nil
+}
+
+// downloadAllVariants saves every photo at original resolution and every MP4
+// rendition of each video into the incoming directory, named
+// {uploader_id}_{id}_{n}_{label}, and returns the files with their labels
+func (d *TwitterDownloader) downloadAllVariants(ctx context.Context, tweet *XTweet) ([]string, []domain.MediaVariant, error) {
+	var files []string
+	var variants []domain.MediaVariant
+	save := func(index int, label, mediaURL, ext string) error {
+		name := fmt.Sprintf("%s_%s_%d_%s%s", tweet.ScreenName, tweet.ID, index, label, ext)
+		dest := filepath.Join(d.incomingDir, SanitizeFilename(name))
+		if err := d.native.DownloadMedia(ctx, mediaURL, dest); err != nil {
+			return err
 		}
+		files = append(files, dest)
+		variants = append(variants, domain.MediaVariant{File: dest, Media: index, Label: label})
+		return nil
 	}
 
-	// Update download with file path (use first file if multiple)
-	download.FilePath = completedFiles[0]
+	for i, media := range tweet.Media {
+		if media.Type == "photo" {
+			if err := save(i+1, "orig", media.URL, photoExtension(media.URL)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		for _, variant := range media.MP4Variants() {
+			if err := save(i+1, variant.Label(), variant.URL, ".mp4"); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return files, variants, nil
+}
 
-	// Log successful completion
-	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded: %s", download.FilePath))
-	progressCallback("", 100) // Signal success
+// ytdlpClientArgs returns the yt-dlp flags setting its client fingerprint:
+// --impersonate (needs yt-dlp's curl_cffi extra) and --user-agent
+func ytdlpClientArgs(impersonate, userAgent string) []string {
+	var args []string
+	if impersonate != "" {
+		args = append(args, "--impersonate", impersonate)
+	}
+	if userAgent != "" {
+		args = append(args, "--user-agent", userAgent)
+	}
+	return args
+}
 
-	return nil
+// ytdlpVariants labels the files of an all-variants yt-dlp run with the
+// format ID in their name ({uploader_id}_{id}_{format_id}.{ext})
+func ytdlpVariants(files []string, tweetID string) []domain.MediaVariant {
+	variants := make([]domain.MediaVariant, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		label := name
+		if idx := strings.Index(name, "_"+tweetID+"_"); idx >= 0 {
+			label = name[idx+len(tweetID)+2:]
+		}
+		variants = append(variants, domain.MediaVariant{File: file, Media: 1, Label: label})
+	}
+	return variants
 }
 
 // findDownloadedFiles finds files downloaded for a specific URL in incoming directory
@@ -214,8 +543,49 @@ func (d *TwitterDownloader) findDownloadedFiles(url string) ([]string, error) {
 	return files, err
 }
 
-// moveToCompleted moves files from incoming to completed directory
-func (d *TwitterDownloader) moveToCompleted(files []string) ([]string, error) {
+// removePartialFiles deletes files left in the incoming directory by an
+// interrupted yt-dlp run for the given tweet URL (.part, .ytdl, .info.json, ...).
+// Filename format: {uploader_id}_{tweet_id}[...].{ext}
+func (d *TwitterDownloader) removePartialFiles(url string) {
+	tweetID := tweetIDFromURL(url)
+	if tweetID == "" {
+		return
+	}
+	entries, err := os.ReadDir(d.incomingDir)
+	if err != nil {
+		return
+	}
+	marker := "_" + tweetID
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.Contains(entry.Name(), marker) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(d.incomingDir, entry.Name()))
+	}
+}
+
+// tweetIDFromURL returns the numeric status ID from a tweet URL
+// (https://x.com/{username}/status/{tweet_id}[/...]), or "" if there is none.
+func tweetIDFromURL(url string) string {
+	if idx := strings.IndexAny(url, "?#"); idx >= 0 {
+		url = url[:idx]
+	}
+	parts := strings.Split(url, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] != "status" {
+			continue
+		}
+		id := parts[i+1]
+		if _, err := strconv.ParseUint(id, 10, 64); err == nil {
+			return id
+		}
+	}
+	return ""
+}
+
+// moveToCompleted moves files from incoming to completed directory.
+// Large copies across file systems report their progress to progress.
+func (d *TwitterDownloader) moveToCompleted(files []string, progress domain.DownloadProgressCallback) ([]string, error) {
 	var completedFiles []string
 
 	// Ensure completed directory exists
@@ -228,12 +598,8 @@ func (d *TwitterDownloader) moveToCompleted(files []string) ([]string, error) {
 		destPath := filepath.Join(d.completedDir, filename)
 
 		// Move file
-		if err := os.Rename(file, destPath); err != nil {
-			// If rename fails, try copy and delete
-			if err := CopyFile(file, destPath); err != nil {
-				return nil, fmt.Errorf("failed to move file %s: %w", file, err)
-			}
-			os.Remove(file)
+		if err := MoveFileWithProgress(file, destPath, progress); err != nil {
+			return nil, err
 		}
 
 		completedFiles = append(completedFiles, destPath)
@@ -242,7 +608,7 @@ func (d *TwitterDownloader) moveToCompleted(files []string) ([]string, error) {
 		infoJSONPath := strings.TrimSuffix(file, filepath.Ext(file)) + ".info.json"
 		if infoData, err := os.ReadFile(infoJSONPath); err == nil {
 			infoJSONDest := filepath.Join(d.completedDir, filepath.Base(infoJSONPath))
-			if err := os.WriteFile(infoJSONDest, infoData, 0644); err == nil {
+			if err := WriteFileAtomic(infoJSONDest, infoData, 0644); err == nil {
 				os.Remove(infoJSONPath)
 			}
 		}
@@ -251,35 +617,96 @@ func (d *TwitterDownloader) moveToCompleted(files []string) ([]string, error) {
 	return completedFiles, nil
 }
 
-// storeMetadata stores download metadata by reading yt-dlp's .info.json files
-func (d *TwitterDownloader) storeMetadata(download *domain.Download, files []string) error {
-	// Try to read yt-dlp's .info.json file to extract rich metadata
-	var meta *domain.MediaMetadata
+// applyLayout applies the configured filename template and subdirectory
+// layout to a tweet's completed files (sidecars follow) and returns their new
+// paths. Failures are logged and leave the files where they are.
+func (d *TwitterDownloader) applyLayout(url string, files []string, tweet *XTweet) []string {
+	if d.FilenameTemplate == "" && d.OrganizeBy == "" {
+		return files
+	}
+	meta := d.readMetadata(url, files, tweet)
+	files, err := d.RenameToTemplate(files, meta)
+	if err != nil && d.eventLogger != nil {
+		d.eventLogger.LogAppError("Failed to apply filename template", zap.Error(err))
+	}
+	files, err = d.OrganizeFiles(files, d.completedDir, meta)
+	if err != nil && d.eventLogger != nil {
+		d.eventLogger.LogAppError("Failed to organize completed files", zap.Error(err))
+	}
+	return files
+}
 
-	// Look for .info.json files in the completed directory (files have been moved there)
+// readMetadata builds metadata from yt-dlp's .info.json next to the first
+// file that has one, completed by the natively fetched tweet if any. Without
+// an .info.json it uses the tweet, or minimal metadata from the URL.
+func (d *TwitterDownloader) readMetadata(url string, files []string, tweet *XTweet) *domain.MediaMetadata {
 	for _, file := range files {
 		infoJSONPath := strings.TrimSuffix(file, filepath.Ext(file)) + ".info.json"
 		if data, err := os.ReadFile(infoJSONPath); err == nil {
 			var infoData map[string]interface{}
 			if json.Unmarshal(data, &infoData) == nil {
-				meta = d.buildRichMetadata(infoData, download.URL, files)
-				break
+				meta := d.buildRichMetadata(infoData, url, files)
+				if tweet != nil {
+					tweet.FillMissing(meta)
+				}
+				return meta
 			}
 		}
 	}
-
-	// If no .info.json found, build minimal metadata
-	if meta == nil {
-		meta = d.buildMinimalMetadata(download.URL, files)
+	if tweet != nil {
+		return tweet.ToMediaMetadata(url, files)
 	}
+	return d.buildMinimalMetadata(url, files)
+}
 
-	data, err := json.Marshal(meta.ToMap())
-	if err != nil {
-		return err
+// writeMetadata builds the metadata of a tweet's completed files and writes
+// it to their sidecars (.info.json and companion files)
+func (d *TwitterDownloader) writeMetadata(url string, files []string, tweet *XTweet, variants []domain.MediaVariant) *domain.MediaMetadata {
+	meta := d.readMetadata(url, files, tweet)
+	meta.RelativePath = RelativeToCompleted(d.completedDir, files[0])
+	meta.Variants = variants
+	d.ApplyExtensions(meta)
+
+	// yt-dlp writes its own .info.json sidecars, so inject the relative path,
+	// extra fields and natively fetched details there too
+	metaMap := meta.ToMap()
+	for _, file := range files {
+		fields := map[string]interface{}{"relative_path": RelativeToCompleted(d.completedDir, file)}
+		for _, variant := range variants {
+			if variant.File == file {
+				fields["variant"] = variant.Label
+			}
+		}
+		for key, value := range meta.Extra {
+			fields[key] = value
+		}
+		for _, key := range []string{"alt_texts", "like_count", "repost_count", "comment_count", "view_count", "poll"} {
+			if value, ok := metaMap[key]; ok {
+				fields[key] = value
+			}
+		}
+		infoJSONPath := strings.TrimSuffix(file, filepath.Ext(file)) + ".info.json"
+		if tweet != nil && !FileExists(infoJSONPath) {
+			// Downloaded natively: there is no yt-dlp sidecar to extend
+			fileMeta := *meta
+			fileMeta.RelativePath = RelativeToCompleted(d.completedDir, file)
+			if err := WriteInfoJSON(file, &fileMeta); err != nil && d.eventLogger != nil {
+				d.eventLogger.LogAppError("Failed to write info.json", zap.String("file", file), zap.Error(err))
+			}
+			continue
+		}
+		if err := mergeInfoJSONFields(infoJSONPath, fields); err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("Failed to add extra metadata fields",
+				zap.String("file", infoJSONPath), zap.Error(err))
+		}
 	}
 
-	download.Metadata = string(data)
-	return nil
+	for _, file := range files {
+		if err := d.WriteCompanionFiles(file, meta); err != nil && d.eventLogger != nil {
+			d.eventLogger.LogAppError("Failed to write description file", zap.String("file", file), zap.Error(err))
+		}
+	}
+	return meta
 }
 
 // buildRichMetadata extracts and formats rich metadata from yt-dlp's .info.json
@@ -309,6 +736,16 @@ func (d *TwitterDownloader) buildRichMetadata(infoData map[string]interface{}, u
 		webpageURL = url
 	}
 
+	// Alt text and polls are not extracted by yt-dlp; they come from the
+	// native extractor (or a previous run) when present
+	var altTexts []string
+	if altRaw, ok := infoData["alt_texts"].([]interface{}); ok {
+		for _, alt := range altRaw {
+			altStr, _ := alt.(string)
+			altTexts = append(altTexts, altStr)
+		}
+	}
+
 	return &domain.MediaMetadata{
 		ID:           GetStringFromMap(infoData, "id"),
 		Title:        GetStringFromMap(infoData, "title"),
@@ -324,11 +761,33 @@ func (d *TwitterDownloader) buildRichMetadata(infoData map[string]interface{}, u
 		Platform:     "x",
 		Extractor:    GetStringFromMap(infoData, "extractor"),
 		ExtractorKey: GetStringFromMap(infoData, "extractor_key"),
+		AltTexts:     altTexts,
+		LikeCount:    GetInt64FromMap(infoData, "like_count"),
+		RepostCount:  GetInt64FromMap(infoData, "repost_count"),
+		CommentCount: GetInt64FromMap(infoData, "comment_count"),
+		ViewCount:    GetInt64FromMap(infoData, "view_count"),
+		Poll:         pollFromMap(infoData["poll"]),
 		Extension:    GetStringFromMap(infoData, "ext"),
 		Files:        files,
 	}
 }
 
+// pollFromMap decodes a "poll" value previously written to an .info.json
+func pollFromMap(value interface{}) *domain.Poll {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var poll domain.Poll
+	if json.Unmarshal(data, &poll) != nil || len(poll.Options) == 0 {
+		return nil
+	}
+	return &poll
+}
+
 // buildMinimalMetadata creates basic metadata when .info.json is not available
 func (d *TwitterDownloader) buildMinimalMetadata(url string, files []string) *domain.MediaMetadata {
 	// Extract username and tweet ID from URL
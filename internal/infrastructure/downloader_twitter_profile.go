@@ -0,0 +1,353 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// profileTweetIDRe extracts the tweet ID from a profile file name
+// ({uploader_id}_{tweet_id}[_{n}]) when its .info.json is missing
+var profileTweetIDRe = regexp.MustCompile(`_(\d+)(?:[_-]\d+)?$`)
+
+// profileErrorRe matches the yt-dlp error for a tweet it could not fetch:
+// "ERROR: [twitter] 1234567890: <reason>"
+var profileErrorRe = regexp.MustCompile(`(?m)^ERROR: \[twitter\] (\d+): (.+)$`)
+
+// profileTweet is the files of one tweet downloaded by a profile backup
+type profileTweet struct {
+	id    string
+	files []string
+}
+
+// ytdlpProfileArgs returns the yt-dlp arguments of a profile backup into
+// workDir, without the account's cookies and the URL
+func (d *TwitterDownloader) ytdlpProfileArgs(download *domain.Download, workDir string) []string {
+	args := []string{
+		"--yes-playlist",
+		"--ignore-errors",
+		"--write-info-json",
+		"--restrict-filenames",
+		"-o", "%(uploader_id)s_%(id)s.%(ext)s",
+		"-P", workDir,
+	}
+	args = append(args, ytdlpClientArgs(d.config.Impersonate, d.config.UserAgent)...)
+	return append(args, limitRateArgs(d.rateLimitFor(download.Platform))...)
+}
+
+// firstAccount returns the account a download would start with: the one it
+// is pinned to, else the first available ("" for no cookies)
+func (d *TwitterDownloader) firstAccount(download *domain.Download) string {
+	if accounts := d.accounts.candidates(download.Account); len(accounts) > 0 {
+		return accounts[0]
+	}
+	return ""
+}
+
+// downloadProfile backs up every tweet with media on a profile. yt-dlp walks
+// the profile as a playlist into a directory of its own; each tweet's files
+// are then finished like a single tweet and returned as items of the
+// download. Tweets yt-dlp fails on are returned as failed items rather than
+// failing the backup.
+func (d *TwitterDownloader) downloadProfile(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback, downloadLog *os.File) (*domain.DownloadResult, error) {
+	username := domain.XProfileUsername(download.URL)
+	if username == "" {
+		return nil, fmt.Errorf("not an X profile URL: %s", download.URL)
+	}
+
+	workDir := filepath.Join(d.incomingDir, "profile_"+download.ID)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	args := d.ytdlpProfileArgs(download, workDir)
+	// A backup runs with one account, the first available
+	account := d.firstAccount(download)
+	args = append(args, d.accounts.cookieArgs(account)...)
+	args = append(args, download.URL)
+	clientProfile := d.accounts.clientProfile(account)
+	d.WriteLogHeader(downloadLog, download.ID, d.ytdlpCommandLine(args...))
+
+	var outputBuf bytes.Buffer
+	sink := io.MultiWriter(downloadLog, &outputBuf, newProgressWriter(progressCallback))
+	cmd := d.ytdlp(ctx, args...)
+	cmd.Stdout = sink
+	cmd.Stderr = sink
+	started := time.Now()
+	err := cmd.Run()
+	recordProcessUsage(ctx, cmd, started)
+
+	if ctx.Err() != nil {
+		d.WriteLogFooter(downloadLog, false, "Cancelled")
+		return nil, domain.WithClientProfile(fmt.Errorf("yt-dlp cancelled: %w", ctx.Err()), clientProfile)
+	}
+	tweets := groupProfileFiles(workDir)
+	if err != nil {
+		if len(tweets) == 0 {
+			d.WriteLogFooter(downloadLog, false, fmt.Sprintf("yt-dlp failed: %v", err))
+			progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+			if retryAfter, limited := detectRateLimit(outputBuf.String()); limited {
+				return nil, domain.WithClientProfile(&domain.RateLimitError{RetryAfter: retryAfter, Err: toolError("yt-dlp", err, outputBuf.String())}, clientProfile)
+			}
+			return nil, domain.WithClientProfile(toolError("yt-dlp", err, outputBuf.String()), clientProfile)
+		}
+		// --ignore-errors: some tweets failed, keep the rest
+		fmt.Fprintf(downloadLog, "\n[twitter] yt-dlp reported errors (%v); keeping %d downloaded tweets\n", err, len(tweets))
+	}
+	if len(tweets) == 0 {
+		d.WriteLogFooter(downloadLog, false, "No files downloaded")
+		return nil, domain.WithClientProfile(fmt.Errorf("no files downloaded"), clientProfile)
+	}
+
+	// A retry downloads the whole profile again; its items replace the old ones
+	var allFiles []string
+	var items []domain.DownloadItem
+	var uploader string
+	for _, t := range tweets {
+		tweetURL := "https://x.com/" + username + "/status/" + t.id
+		completedFiles, err := d.moveToCompleted(t.files, progressCallback)
+		if err != nil {
+			d.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to move files: %v", err))
+			return nil, domain.WithClientProfile(fmt.Errorf("failed to move files to completed: %w", err), clientProfile)
+		}
+		completedFiles = d.applyLayout(tweetURL, completedFiles, nil)
+
+		var meta *domain.MediaMetadata
+		if d.config.WriteMetadata {
+			meta = d.writeMetadata(tweetURL, completedFiles, nil, nil)
+		} else {
+			meta = d.readMetadata(tweetURL, completedFiles, nil)
+		}
+		if uploader == "" {
+			uploader = meta.Uploader
+		}
+
+		for _, file := range completedFiles {
+			items = append(items, domain.DownloadItem{
+				Status:     domain.StatusCompleted,
+				FilePath:   file,
+				SourceID:   t.id,
+				SourceURL:  tweetURL,
+				Title:      meta.Title,
+				UploadDate: meta.UploadDate,
+			})
+		}
+		allFiles = append(allFiles, completedFiles...)
+	}
+	for _, m := range profileErrorRe.FindAllStringSubmatch(outputBuf.String(), -1) {
+		items = append(items, domain.DownloadItem{
+			Status:       domain.StatusFailed,
+			SourceID:     m[1],
+			SourceURL:    "https://x.com/" + username + "/status/" + m[1],
+			ErrorMessage: strings.TrimSpace(m[2]),
+		})
+	}
+	// The profile's own metadata lists every file so the download's size and
+	// file list cover the whole backup; per-tweet details live in the items
+	now := time.Now()
+	meta := &domain.MediaMetadata{
+		ID:           username,
+		Title:        fmt.Sprintf("@%s media", username),
+		Uploader:     firstNonEmpty(uploader, username),
+		UploaderID:   username,
+		UploaderURL:  "https://x.com/" + username,
+		WebpageURL:   download.URL,
+		URL:          download.URL,
+		Timestamp:    now.Unix(),
+		UploadDate:   now.Format("20060102"),
+		Tags:         []string{"x", "twitter", "profile"},
+		Platform:     "x",
+		Extractor:    "x",
+		ExtractorKey: "X",
+		Files:        allFiles,
+	}
+
+	d.WriteLogFooter(downloadLog, true, fmt.Sprintf("Downloaded %d tweets (%d files)", len(tweets), len(allFiles)))
+	progressCallback(domain.DownloadProgress{Percent: 100}) // Signal success
+	return &domain.DownloadResult{Files: allFiles, Metadata: meta.ToMap(), Items: items, ItemCount: len(tweets), ClientProfile: clientProfile}, nil
+}
+
+// groupProfileFiles groups the media files yt-dlp left in dir by tweet,
+// oldest tweet first. The tweet ID comes from each file's .info.json
+// (display_id is the tweet of a multi-video entry), else from its name.
+func groupProfileFiles(dir string) []profileTweet {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	byID := map[string]*profileTweet{}
+	var ids []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !IsMediaFile(path) {
+			continue
+		}
+		id := profileTweetID(path)
+		if id == "" {
+			continue
+		}
+		t, ok := byID[id]
+		if !ok {
+			t = &profileTweet{id: id}
+			byID[id] = t
+			ids = append(ids, id)
+		}
+		t.files = append(t.files, path)
+	}
+
+	// Tweet IDs grow over time: order numerically, shorter IDs first
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	tweets := make([]profileTweet, 0, len(ids))
+	for _, id := range ids {
+		sort.Strings(byID[id].files)
+		tweets = append(tweets, *byID[id])
+	}
+	return tweets
+}
+
+// profileTweetID returns the tweet a profile file belongs to
+func profileTweetID(path string) string {
+	stem := strings.TrimSuffix(path, filepath.Ext(path))
+	if data, err := os.ReadFile(stem + ".info.json"); err == nil {
+		var info map[string]interface{}
+		if json.Unmarshal(data, &info) == nil {
+			id := firstNonEmpty(GetStringFromMap(info, "display_id"), GetStringFromMap(info, "id"))
+			// Multi-video entries may carry an index suffix ({tweet_id}_{n})
+			if idx := strings.IndexFunc(id, func(r rune) bool { return r < '0' || r > '9' }); idx >= 0 {
+				id = id[:idx]
+			}
+			if id != "" {
+				return id
+			}
+		}
+	}
+	if m := profileTweetIDRe.FindStringSubmatch(filepath.Base(stem)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// ytdlpSyncScanDepth is how many of the newest tweets of an account yt-dlp
+// inspects per subscription check. Tweets older than this that were never
+// enqueued are skipped.
+const ytdlpSyncScanDepth = 100
+
+// twitterEpochMs is the Unix time in milliseconds tweet IDs count from
+const twitterEpochMs = 1288834974657
+
+// ListNewItems implements domain.SourceSyncer for X accounts with yt-dlp: it
+// walks the account's timeline as a playlist without downloading anything
+// (--skip-download --dump-json), at most ytdlpSyncScanDepth tweets, and
+// returns one item per tweet with media posted after cursor, oldest first.
+// The cursor's tweet ID also dates it, so yt-dlp drops older tweets with a
+// --match-filters date cutoff (tweets without a timestamp pass); the ID
+// comparison then skips the rest (a pinned tweet, several tweets in the
+// cursor's second).
+func (d *TwitterDownloader) ListNewItems(ctx context.Context, sourceURL, cursor string, limit int) ([]domain.SyncItem, error) {
+	username := domain.XProfileUsername(sourceURL)
+	if username == "" {
+		return nil, fmt.Errorf("not an X account URL: %s", sourceURL)
+	}
+
+	args := []string{
+		"--yes-playlist",
+		"--ignore-errors",
+		"--skip-download",
+		"--dump-json",
+		"--playlist-end", strconv.Itoa(ytdlpSyncScanDepth),
+	}
+	if since, ok := tweetIDTime(cursor); ok {
+		args = append(args, "--match-filters", fmt.Sprintf("timestamp>=?%d", since.Unix()))
+	}
+	args = append(args, ytdlpClientArgs(d.config.Impersonate, d.config.UserAgent)...)
+	account := ""
+	if accounts := d.accounts.candidates(""); len(accounts) > 0 {
+		account = accounts[0]
+	}
+	args = append(args, d.accounts.cookieArgs(account)...)
+	args = append(args, sourceURL)
+
+	var stdout, stderr bytes.Buffer
+	cmd := d.ytdlp(ctx, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	tweets := parseYTDLPTweets(stdout.Bytes())
+	if err != nil && len(tweets) == 0 {
+		// --ignore-errors: failed tweets alone do not fail the listing
+		if retryAfter, limited := detectRateLimit(stderr.String()); limited {
+			d.accounts.rateLimited(account, retryAfter)
+			return nil, &domain.RateLimitError{RetryAfter: retryAfter, Err: toolError("yt-dlp", err, stderr.String())}
+		}
+		return nil, toolError("yt-dlp", fmt.Errorf("%w, output: %s", err, stderr.String()), stderr.String())
+	}
+
+	var items []domain.SyncItem
+	for _, id := range tweets {
+		if !tweetIDAfter(id, cursor) {
+			continue
+		}
+		items = append(items, domain.SyncItem{
+			ID:       id,
+			URL:      fmt.Sprintf("https://x.com/%s/status/%s", username, id),
+			Platform: domain.PlatformX,
+			Mode:     domain.ModeDefault,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return tweetIDAfter(items[j].ID, items[i].ID) })
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// parseYTDLPTweets returns the unique tweet IDs of yt-dlp --dump-json output,
+// one JSON object per line. A tweet with several videos has an entry per
+// video, all with the tweet's display_id.
+func parseYTDLPTweets(data []byte) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var info map[string]interface{}
+		if json.Unmarshal(bytes.TrimSpace(line), &info) != nil {
+			continue
+		}
+		id := firstNonEmpty(GetStringFromMap(info, "display_id"), GetStringFromMap(info, "id"))
+		if idx := strings.IndexFunc(id, func(r rune) bool { return r < '0' || r > '9' }); idx >= 0 {
+			id = id[:idx]
+		}
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// tweetIDTime returns when the tweet with a (snowflake) ID was posted, to
+// the millisecond; ok is false for IDs that are not snowflakes
+func tweetIDTime(id string) (time.Time, bool) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n>>22 == 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(n>>22) + twitterEpochMs), true
+}
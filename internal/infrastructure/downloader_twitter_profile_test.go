@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// fakeProfileYTDLP writes two tweets (one with two videos) into the -P
+// directory and exits 1, as yt-dlp --ignore-errors does after a failed item
+const fakeProfileYTDLP = `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-P" ]; then dir="$2"; fi
+  shift
+done
+echo "[download] Downloading item 1 of 3"
+printf v > "$dir/alice_200_1.mp4"
+printf v > "$dir/alice_200_2.mp4"
+echo "[download] Downloading item 2 of 3"
+printf v > "$dir/alice_100.mp4"
+printf '{"id":"100","title":"first post","uploader":"Alice","uploader_id":"alice","timestamp":1699963200}' > "$dir/alice_100.info.json"
+echo "ERROR: [twitter] 300: This tweet is unavailable"
+exit 1
+`
+
+func TestTwitterDownloadProfile(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte(fakeProfileYTDLP), 0755))
+	incoming, completed := t.TempDir(), t.TempDir()
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary, WriteMetadata: true},
+		incoming, completed, t.TempDir(), nil)
+
+	download := domain.NewDownload("https://x.com/alice/media", domain.PlatformX, domain.ModeProfile)
+	result, err := d.Download(context.Background(), download, download.ApplyProgress)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ItemCount, "tweets downloaded")
+	download.ApplyResult(result)
+
+	assert.Equal(t, 3, download.ItemCount, "item count comes from yt-dlp's playlist output")
+	require.Len(t, download.Items, 4, "one item per file, plus the failed tweet")
+	assert.Equal(t, "100", download.Items[0].SourceID, "oldest tweet first")
+	assert.Equal(t, "https://x.com/alice/status/100", download.Items[0].SourceURL)
+	assert.Equal(t, "first post", download.Items[0].Title)
+	assert.Equal(t, "20231114", download.Items[0].UploadDate)
+	assert.Equal(t, "200", download.Items[1].SourceID)
+	assert.Equal(t, "200", download.Items[2].SourceID)
+	assert.Equal(t, domain.StatusFailed, download.Items[3].Status)
+	assert.Equal(t, "300", download.Items[3].SourceID)
+	assert.Equal(t, "This tweet is unavailable", download.Items[3].ErrorMessage)
+
+	assert.Len(t, download.Files(), 3)
+	for _, file := range download.Files() {
+		assert.Equal(t, completed, filepath.Dir(file))
+		assert.True(t, FileExists(file))
+	}
+	assert.NoDirExists(t, filepath.Join(incoming, "profile_"+download.ID), "work directory is removed")
+}
+
+func TestTwitterDownloadProfile_RejectsTweetURL(t *testing.T) {
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: "/nonexistent/yt-dlp"},
+		t.TempDir(), t.TempDir(), t.TempDir(), nil)
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeProfile)
+	_, err := d.Download(context.Background(), download, nil)
+	assert.Error(t, err)
+}
+
+func TestGroupProfileFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"bob_99.jpg", "bob_1000_1.mp4", "bob_1000_2.mp4", "bob_1000_1.info.json", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644))
+	}
+	// display_id names the tweet of a multi-video entry
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bob_1000_1.info.json"),
+		[]byte(`{"id":"555","display_id":"1000"}`), 0644))
+
+	tweets := groupProfileFiles(dir)
+	require.Len(t, tweets, 2)
+	assert.Equal(t, "99", tweets[0].id)
+	assert.Equal(t, "1000", tweets[1].id)
+	assert.Len(t, tweets[1].files, 2)
+}
+
+func TestParseYTDLPTweets(t *testing.T) {
+	output := `{"id": "1800000000000000001", "display_id": "1800000000000000001", "timestamp": 1717000000}
+{"id": "1800000000000000002_1", "display_id": "1800000000000000002"}
+{"id": "1800000000000000002_2", "display_id": "1800000000000000002"}
+WARNING: not json
+`
+	assert.Equal(t, []string{"1800000000000000001", "1800000000000000002"}, parseYTDLPTweets([]byte(output)))
+}
+
+func TestTweetIDTime(t *testing.T) {
+	// The ID's top 42 bits are milliseconds since the X epoch
+	posted, ok := tweetIDTime("1212092628029698048")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2019, 12, 31, 19, 26, 16, 771000000, time.UTC), posted.UTC())
+
+	_, ok = tweetIDTime("0")
+	assert.False(t, ok)
+	_, ok = tweetIDTime("abc")
+	assert.False(t, ok)
+}
+
+func TestTwitterListNewItems(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "yt-dlp")
+	argsFile := filepath.Join(dir, "args")
+	script := `#!/bin/sh
+echo "$@" > ` + argsFile + `
+echo '{"id": "1800000000000000003", "display_id": "1800000000000000003"}'
+echo '{"id": "1800000000000000001", "display_id": "1800000000000000001"}'
+echo '{"id": "1800000000000000002", "display_id": "1800000000000000002"}'
+`
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary}, t.TempDir(), t.TempDir(), t.TempDir(), nil)
+
+	items, err := d.ListNewItems(context.Background(), "https://x.com/alice", "1800000000000000001", 0)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "1800000000000000002", items[0].ID, "oldest first")
+	assert.Equal(t, "https://x.com/alice/status/1800000000000000003", items[1].URL)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "--skip-download")
+	assert.Contains(t, string(args), "--match-filters timestamp>=?1717")
+
+	_, err = d.ListNewItems(context.Background(), "https://x.com/alice/status/1", "", 0)
+	assert.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package infrastructure
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -287,6 +290,65 @@ func TestTwitterDownloader_Platform(t *testing.T) {
 	assert.Equal(t, domain.PlatformX, downloader.Platform())
 }
 
+// recordingDownloader records the downloads it is asked to perform
+type recordingDownloader struct {
+	downloads []*domain.Download
+}
+
+func (r *recordingDownloader) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	r.downloads = append(r.downloads, download)
+	return &domain.DownloadResult{Files: []string{"/completed/someone_123.jpg"}}, nil
+}
+
+func (r *recordingDownloader) Platform() domain.Platform { return domain.PlatformGallery }
+
+func (r *recordingDownloader) Validate(url string) error { return nil }
+
+func TestTwitterDownloader_GalleryDLBackend(t *testing.T) {
+	downloader := newTestTwitterDownloader(&domain.TwitterConfig{
+		YTDLPBinary: "/nonexistent/yt-dlp",
+		Backend:     domain.TwitterBackendGalleryDL,
+	})
+	download := domain.NewDownload("https://x.com/someone/status/123", domain.PlatformX, domain.ModeDefault)
+
+	// No gallery-dl downloader wired
+	_, err := downloader.Download(context.Background(), download, nil)
+	assert.Error(t, err)
+
+	gallery := &recordingDownloader{}
+	downloader.SetFallback(gallery)
+	result, err := downloader.Download(context.Background(), download, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.Download{download}, gallery.downloads)
+	assert.Equal(t, []string{"/completed/someone_123.jpg"}, result.Files, "the gallery-dl result is returned")
+}
+
+func TestTwitterBuildRichMetadata_PostDetails(t *testing.T) {
+	downloader := newTestTwitterDownloader(&domain.TwitterConfig{})
+	infoData := map[string]interface{}{
+		"id":            "1",
+		"like_count":    float64(42),
+		"repost_count":  float64(7),
+		"comment_count": float64(3),
+		"alt_texts":     []interface{}{"A cat", ""},
+		"poll": map[string]interface{}{
+			"options": []interface{}{map[string]interface{}{"label": "Yes", "votes": float64(2)}},
+			"final":   true,
+		},
+	}
+
+	meta := downloader.buildRichMetadata(infoData, "https://x.com/a/status/1", nil)
+	assert.Equal(t, int64(42), meta.LikeCount)
+	assert.Equal(t, int64(7), meta.RepostCount)
+	assert.Equal(t, int64(3), meta.CommentCount)
+	assert.Equal(t, int64(0), meta.ViewCount)
+	assert.Equal(t, []string{"A cat", ""}, meta.AltTexts)
+	require.NotNil(t, meta.Poll)
+	assert.Equal(t, []domain.PollOption{{Label: "Yes", Votes: 2}}, meta.Poll.Options)
+
+	assert.Nil(t, downloader.buildRichMetadata(map[string]interface{}{}, "https://x.com/a/status/1", nil).Poll)
+}
+
 func TestFindDownloadedFiles_UsernameExtraction(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -323,3 +385,110 @@ func TestFindDownloadedFiles_UsernameExtraction(t *testing.T) {
 		})
 	}
 }
+
+func TestTwitterRemovePartialFiles(t *testing.T) {
+	dir := t.TempDir()
+	d := &TwitterDownloader{incomingDir: dir}
+
+	partial := []string{"user_1234567890.mp4.part", "user_1234567890.info.json", "user_1234567890.f137.mp4.ytdl"}
+	other := "other_9999999999.mp4"
+	for _, name := range append(partial, other) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644))
+	}
+
+	d.removePartialFiles("https://x.com/user/status/1234567890/video/1")
+
+	for _, name := range partial {
+		assert.NoFileExists(t, filepath.Join(dir, name))
+	}
+	assert.FileExists(t, filepath.Join(dir, other))
+}
+
+func TestTwitterDownloadAllVariants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := &TwitterDownloader{incomingDir: dir, native: NewXAPIClient("", "")}
+	tweet := &XTweet{ID: "6", ScreenName: "someone", Media: []XTweetMedia{
+		{Type: "photo", URL: server.URL + "/media/A1.jpg"},
+		{Type: "video", Variants: []XVideoVariant{
+			{URL: server.URL + "/vid/avc1/640x360/a.mp4", ContentType: "video/mp4", Bitrate: 832000},
+			{URL: server.URL + "/vid/avc1/1280x720/b.mp4", ContentType: "video/mp4", Bitrate: 2176000},
+		}},
+	}}
+
+	files, variants, err := d.downloadAllVariants(context.Background(), tweet)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "someone_6_1_orig.jpg"),
+		filepath.Join(dir, "someone_6_2_1280x720.mp4"),
+		filepath.Join(dir, "someone_6_2_640x360.mp4"),
+	}, files)
+	assert.Equal(t, []domain.MediaVariant{
+		{File: files[0], Media: 1, Label: "orig"},
+		{File: files[1], Media: 2, Label: "1280x720"},
+		{File: files[2], Media: 2, Label: "640x360"},
+	}, variants)
+
+	assert.Equal(t, []domain.MediaVariant{
+		{File: "/in/someone_6_http-2176.mp4", Media: 1, Label: "http-2176"},
+		{File: "/in/someone_6_hls-832.mp4", Media: 1, Label: "hls-832"},
+	}, ytdlpVariants([]string{"/in/someone_6_http-2176.mp4", "/in/someone_6_hls-832.mp4"}, "6"))
+}
+
+func TestTwitterDownloadMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := &TwitterDownloader{incomingDir: dir, native: NewXAPIClient("", "")}
+	tweet := &XTweet{ID: "7", ScreenName: "someone", Media: []XTweetMedia{
+		{Type: "photo", URL: server.URL + "/media/A1.jpg"},
+		{Type: "video", Variants: []XVideoVariant{
+			{URL: server.URL + "/vid/avc1/640x360/a.mp4", ContentType: "video/mp4", Bitrate: 832000},
+			{URL: server.URL + "/vid/avc1/1280x720/b.mp4", ContentType: "video/mp4", Bitrate: 2176000},
+		}},
+	}}
+
+	files, err := d.downloadMedia(context.Background(), tweet)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "someone_7_1.jpg"), filepath.Join(dir, "someone_7_2.mp4")}, files)
+	data, err := os.ReadFile(files[1])
+	require.NoError(t, err)
+	assert.Equal(t, "/vid/avc1/1280x720/b.mp4", string(data), "the best rendition")
+
+	tweet.Media = []XTweetMedia{{Type: "video", Variants: []XVideoVariant{{URL: server.URL + "/a.m3u8", ContentType: "application/x-mpegURL"}}}}
+	_, err = d.downloadMedia(context.Background(), tweet)
+	assert.Error(t, err, "HLS only")
+}
+
+func TestTwitterDownloader_Backend(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\necho 'ERROR: [twitter] 123: No video could be found in this tweet'\nexit 1\n"), 0755))
+	downloader := newTestTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary})
+	gallery := &recordingDownloader{}
+	downloader.SetFallback(gallery)
+	download := domain.NewDownload("https://x.com/someone/status/123", domain.PlatformX, domain.ModeDefault)
+
+	_, err := downloader.Backend(domain.BackendYTDLP).Download(context.Background(), download, nil)
+	assert.Empty(t, gallery.downloads, "no gallery-dl fallback inside a fallback chain")
+	assert.True(t, domain.IsExtractorError(err))
+
+	_, err = downloader.Backend(domain.BackendXAPI).Download(context.Background(), download, nil)
+	assert.True(t, domain.IsExtractorError(err), "x-api needs the native extractor")
+
+	_, err = downloader.Download(context.Background(), download, nil)
+	require.NoError(t, err)
+	assert.Len(t, gallery.downloads, 1)
+}
+
+func TestYTDLPClientArgs(t *testing.T) {
+	assert.Empty(t, ytdlpClientArgs("", ""))
+	assert.Equal(t, []string{"--impersonate", "chrome-124:macos-14", "--user-agent", "Mozilla/5.0"},
+		ytdlpClientArgs("chrome-124:macos-14", "Mozilla/5.0"))
+}
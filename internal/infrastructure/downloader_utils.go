@@ -49,26 +49,27 @@ func FileExists(path string) bool {
 	return err == nil
 }
 
-// CopyFile copies a file from src to dst.
-func CopyFile(src, dst string) error {
-	data, err := os.ReadFile(src)
-	if err != nil {
-		return err
+// TotalFileSize returns the combined size in bytes of the given files.
+// Missing or unreadable files are skipped.
+func TotalFileSize(paths []string) int64 {
+	var total int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			total += info.Size()
+		}
 	}
-	return os.WriteFile(dst, data, 0644)
+	return total
+}
+
+// CopyFile copies a file from src to dst (see CopyFileWithProgress).
+func CopyFile(src, dst string) error {
+	return CopyFileWithProgress(src, dst, nil)
 }
 
 // MoveFile moves a file from src to dst.
 // Tries os.Rename first; if that fails (e.g., cross-device), falls back to copy+delete.
 func MoveFile(src, dst string) error {
-	if err := os.Rename(src, dst); err != nil {
-		// Rename failed (possibly cross-device), try copy and delete
-		if err := CopyFile(src, dst); err != nil {
-			return fmt.Errorf("failed to move file %s to %s: %w", src, dst, err)
-		}
-		os.Remove(src)
-	}
-	return nil
+	return MoveFileWithProgress(src, dst, nil)
 }
 
 // GetStringFromMap safely extracts a string value from a map[string]interface{}.
@@ -80,6 +81,19 @@ func GetStringFromMap(data map[string]interface{}, key string) string {
 	return ""
 }
 
+// GetInt64FromMap safely extracts an integer from a decoded JSON map, whether
+// it was decoded as float64 or json.Number. Returns 0 if absent.
+func GetInt64FromMap(data map[string]interface{}, key string) int64 {
+	switch val := data[key].(type) {
+	case float64:
+		return int64(val)
+	case json.Number:
+		n, _ := val.Int64()
+		return n
+	}
+	return 0
+}
+
 // GetFirstStringFromMap returns the first non-empty string value found in data
 // for the given keys, in order. Returns "" if none match.
 func GetFirstStringFromMap(data map[string]interface{}, keys ...string) string {
@@ -98,6 +112,188 @@ type DownloadLogger struct {
 	LogsDir string
 }
 
+// MetadataExtensions holds user-configured extra metadata fields, companion files
+// and the layout of completed files (filename template and subdirectories).
+// Embed this in downloader structs so every metadata builder applies them uniformly.
+type MetadataExtensions struct {
+	ExtraFields          map[string]string
+	WriteDescriptionFile bool
+	FilenameTemplate     string
+	OrganizeBy           string
+}
+
+// SetExtraMetadataFields sets the extra fields injected into generated metadata.
+func (me *MetadataExtensions) SetExtraMetadataFields(fields map[string]string) {
+	me.ExtraFields = fields
+}
+
+// SetWriteDescriptionFile enables writing a .description.txt next to each media file.
+func (me *MetadataExtensions) SetWriteDescriptionFile(enabled bool) {
+	me.WriteDescriptionFile = enabled
+}
+
+// SetFilenameTemplate sets the template completed files are renamed to ("" keeps tool names).
+func (me *MetadataExtensions) SetFilenameTemplate(template string) {
+	me.FilenameTemplate = template
+}
+
+// SetOrganizeBy sets how completed files are sorted into subdirectories ("" keeps them flat).
+func (me *MetadataExtensions) SetOrganizeBy(organizeBy string) {
+	me.OrganizeBy = organizeBy
+}
+
+// ApplyExtensions renders the configured extra fields into meta.
+func (me *MetadataExtensions) ApplyExtensions(meta *domain.MediaMetadata) {
+	meta.ApplyExtraFields(me.ExtraFields)
+}
+
+// WriteCompanionFiles writes the optional companion files for a media file.
+// Currently only the .description.txt; a no-op when disabled or the description is empty.
+func (me *MetadataExtensions) WriteCompanionFiles(filePath string, meta *domain.MediaMetadata) error {
+	if !me.WriteDescriptionFile || meta.Description == "" {
+		return nil
+	}
+	return WriteDescriptionTxt(filePath, meta.Description)
+}
+
+// renameSidecarSuffixes are the files that follow a media file when it is moved.
+var renameSidecarSuffixes = []string{".info.json", ".description.txt"}
+
+// RenameToTemplate renames completed media files (and their .info.json and
+// .description.txt sidecars) according to the filename template, returning the
+// new paths. Multi-file downloads get an "_<n>" suffix unless the template uses
+// {index} or {original}. Existing files are never overwritten. Files that fail
+// to rename keep their path; the first error is returned.
+func (me *MetadataExtensions) RenameToTemplate(files []string, meta *domain.MediaMetadata) ([]string, error) {
+	if me.FilenameTemplate == "" {
+		return files, nil
+	}
+
+	renamed := make([]string, len(files))
+	var firstErr error
+	for i, file := range files {
+		renamed[i] = file
+		ext := filepath.Ext(file)
+		original := strings.TrimSuffix(filepath.Base(file), ext)
+		name := domain.RenderFilenameTemplate(me.FilenameTemplate, meta, i+1, original)
+		if len(files) > 1 && !domain.UsesFileIndex(me.FilenameTemplate) {
+			name = fmt.Sprintf("%s_%d", name, i+1)
+		}
+		dest, err := moveWithSidecars(file, filepath.Join(filepath.Dir(file), SanitizeFilename(name+ext)))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		renamed[i] = dest
+	}
+	return renamed, firstErr
+}
+
+// OrganizeFiles moves completed media files (and their sidecars) from
+// completedDir into the subdirectory selected by OrganizeBy, returning the new
+// paths. A no-op for the flat layout. Files that fail to move keep their path;
+// the first error is returned.
+func (me *MetadataExtensions) OrganizeFiles(files []string, completedDir string, meta *domain.MediaMetadata) ([]string, error) {
+	subdir := domain.OrganizeSubdir(me.OrganizeBy, meta)
+	if subdir == "" {
+		return files, nil
+	}
+
+	destDir := filepath.Join(completedDir, SanitizeFilename(subdir))
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return files, fmt.Errorf("failed to create directory %s: %w", destDir, err)
+	}
+
+	organized := make([]string, len(files))
+	var firstErr error
+	for i, file := range files {
+		organized[i] = file
+		dest, err := moveWithSidecars(file, filepath.Join(destDir, filepath.Base(file)))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		organized[i] = dest
+	}
+	return organized, firstErr
+}
+
+// RelativeToCompleted returns file's path relative to completedDir, or the
+// base name if file is outside it.
+func RelativeToCompleted(completedDir, file string) string {
+	rel, err := filepath.Rel(completedDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// moveWithSidecars renames a media file to dest (or a free variant of it, see
+// uniqueFilePath) and moves its .info.json and .description.txt along.
+// Returns the final path.
+func moveWithSidecars(file, dest string) (string, error) {
+	dest = uniqueFilePath(dest, file)
+	if dest == file {
+		return file, nil
+	}
+	if err := os.Rename(file, dest); err != nil {
+		return file, fmt.Errorf("failed to move %s: %w", file, err)
+	}
+
+	srcStem := strings.TrimSuffix(file, filepath.Ext(file))
+	destStem := strings.TrimSuffix(dest, filepath.Ext(dest))
+	for _, suffix := range renameSidecarSuffixes {
+		if FileExists(srcStem + suffix) {
+			_ = os.Rename(srcStem+suffix, destStem+suffix)
+		}
+	}
+	return dest, nil
+}
+
+// RenameWithSidecars renames a completed media file to dest (or a free variant
+// of it) with its .info.json and .description.txt. Returns the final path.
+func RenameWithSidecars(file, dest string) (string, error) {
+	return moveWithSidecars(file, dest)
+}
+
+// RemoveWithSidecars deletes a media file and, when sidecars is set, its
+// .info.json and .description.txt. Files that are already gone are not an error.
+func RemoveWithSidecars(file string, sidecars bool) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", file, err)
+	}
+	if !sidecars {
+		return nil
+	}
+	stem := strings.TrimSuffix(file, filepath.Ext(file))
+	for _, suffix := range renameSidecarSuffixes {
+		if err := os.Remove(stem + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", stem+suffix, err)
+		}
+	}
+	return nil
+}
+
+// uniqueFilePath returns path, or path with a "_2", "_3", ... suffix before the
+// extension if another file (other than self) already exists there.
+func uniqueFilePath(path, self string) string {
+	if path == self || !FileExists(path) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s_%d%s", stem, n, ext)
+		if candidate == self || !FileExists(candidate) {
+			return candidate
+		}
+	}
+}
+
 // ImportLogger writes human-readable Eagle import logs to the logs directory.
 type ImportLogger struct {
 	LogsDir string
@@ -224,7 +420,47 @@ func WriteInfoJSON(filePath string, meta *domain.MediaMetadata) error {
 	}
 
 	metadataPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".info.json"
-	return os.WriteFile(metadataPath, data, 0644)
+	return WriteFileAtomic(metadataPath, data, 0644)
+}
+
+// DescriptionTxtPath returns the .description.txt path for a media file.
+func DescriptionTxtPath(filePath string) string {
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".description.txt"
+}
+
+// WriteDescriptionTxt writes the post text as a plain .description.txt file next to the media file.
+func WriteDescriptionTxt(filePath, description string) error {
+	data := description
+	if !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	return WriteFileAtomic(DescriptionTxtPath(filePath), []byte(data), 0644)
+}
+
+// mergeInfoJSONFields adds fields to an existing .info.json file without
+// overriding keys that are already present. Missing files are ignored.
+func mergeInfoJSONFields(infoJSONPath string, fields map[string]interface{}) error {
+	data, err := os.ReadFile(infoJSONPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var info map[string]interface{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return fmt.Errorf("parse info.json: %w", err)
+	}
+	for key, value := range fields {
+		if _, exists := info[key]; !exists {
+			info[key] = value
+		}
+	}
+	data, err = json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("marshal info.json: %w", err)
+	}
+	return WriteFileAtomic(infoJSONPath, data, 0644)
 }
 
 // illegalFilenameChars contains characters that are problematic for filesystems.
@@ -321,5 +557,5 @@ func WriteEagleMetadata(filePath string, meta *domain.MediaMetadata) error {
 	}
 
 	eaglePath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".eagle.json"
-	return os.WriteFile(eaglePath, data, 0644)
+	return WriteFileAtomic(eaglePath, data, 0644)
 }
@@ -246,3 +246,144 @@ func TestIsMediaFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeInfoJSONFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	infoPath := filepath.Join(tmpDir, "user_1.info.json")
+	require.NoError(t, os.WriteFile(infoPath, []byte(`{"id":"1","title":"orig"}`), 0644))
+
+	err := mergeInfoJSONFields(infoPath, map[string]interface{}{"source": "x-extract", "title": "new"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(infoPath)
+	require.NoError(t, err)
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, "x-extract", info["source"])
+	assert.Equal(t, "orig", info["title"])
+
+	// Written atomically, without leaving the temporary file behind
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Missing files are ignored
+	assert.NoError(t, mergeInfoJSONFields(filepath.Join(tmpDir, "missing.info.json"), map[string]interface{}{"a": "b"}))
+}
+
+func TestMetadataExtensions_WriteCompanionFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	mediaPath := filepath.Join(tmpDir, "user_1.mp4")
+	meta := &domain.MediaMetadata{Description: "hello world"}
+
+	// Disabled by default
+	var ext MetadataExtensions
+	require.NoError(t, ext.WriteCompanionFiles(mediaPath, meta))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "user_1.description.txt"))
+
+	ext.SetWriteDescriptionFile(true)
+	require.NoError(t, ext.WriteCompanionFiles(mediaPath, meta))
+	data, err := os.ReadFile(filepath.Join(tmpDir, "user_1.description.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+
+	// Empty descriptions produce no file
+	otherPath := filepath.Join(tmpDir, "user_2.jpg")
+	require.NoError(t, ext.WriteCompanionFiles(otherPath, &domain.MediaMetadata{}))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "user_2.description.txt"))
+}
+
+func TestTotalFileSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.jpg")
+	b := filepath.Join(tmpDir, "b.jpg")
+	require.NoError(t, os.WriteFile(a, make([]byte, 10), 0644))
+	require.NoError(t, os.WriteFile(b, make([]byte, 5), 0644))
+
+	assert.Equal(t, int64(15), TotalFileSize([]string{a, b, filepath.Join(tmpDir, "missing.jpg"), tmpDir}))
+	assert.Equal(t, int64(0), TotalFileSize(nil))
+}
+
+func TestMetadataExtensions_RenameToTemplate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		return path
+	}
+	first := write("user_1.mp4")
+	write("user_1.info.json")
+	second := write("user_2.jpg")
+	write("taken_1.jpg") // existing file must not be overwritten
+
+	meta := &domain.MediaMetadata{ID: "1", UploaderID: "user", UploadDate: "20240102"}
+	ext := MetadataExtensions{FilenameTemplate: "{uploader_id}_{date}_{id}"}
+	renamed, err := ext.RenameToTemplate([]string{first, second}, meta)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "user_20240102_1_1.mp4"),
+		filepath.Join(dir, "user_20240102_1_2.jpg"),
+	}, renamed)
+	assert.True(t, FileExists(filepath.Join(dir, "user_20240102_1_1.info.json")))
+	assert.False(t, FileExists(first))
+
+	// Collisions get a numeric suffix instead of overwriting
+	ext.FilenameTemplate = "taken_{index}"
+	third := write("other.jpg")
+	renamed, err = ext.RenameToTemplate([]string{third}, meta)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "taken_1_2.jpg")}, renamed)
+
+	// No template keeps the tool's names
+	renamed, err = (&MetadataExtensions{}).RenameToTemplate(renamed, meta)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "taken_1_2.jpg")}, renamed)
+}
+
+func TestMetadataExtensions_OrganizeFiles(t *testing.T) {
+	completedDir := t.TempDir()
+	file := filepath.Join(completedDir, "user_1.mp4")
+	require.NoError(t, os.WriteFile(file, []byte("video"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(completedDir, "user_1.info.json"), []byte("{}"), 0644))
+
+	meta := &domain.MediaMetadata{Uploader: "Some User", UploaderID: "user"}
+
+	flat, err := (&MetadataExtensions{}).OrganizeFiles([]string{file}, completedDir, meta)
+	require.NoError(t, err)
+	assert.Equal(t, []string{file}, flat)
+
+	ext := &MetadataExtensions{OrganizeBy: domain.OrganizeUploader}
+	organized, err := ext.OrganizeFiles([]string{file}, completedDir, meta)
+	require.NoError(t, err)
+	expected := filepath.Join(completedDir, "Some User", "user_1.mp4")
+	assert.Equal(t, []string{expected}, organized)
+	assert.True(t, FileExists(filepath.Join(completedDir, "Some User", "user_1.info.json")))
+	assert.False(t, FileExists(file))
+
+	assert.Equal(t, "Some User/user_1.mp4", RelativeToCompleted(completedDir, expected))
+	assert.Equal(t, "other.mp4", RelativeToCompleted(completedDir, "/elsewhere/other.mp4"))
+}
+
+func TestRemoveWithSidecars(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		return path
+	}
+	first := write("a.mp4")
+	write("a.info.json")
+	write("a.description.txt")
+	second := write("b.mp4")
+	write("b.info.json")
+
+	require.NoError(t, RemoveWithSidecars(first, true))
+	assert.False(t, FileExists(first))
+	assert.False(t, FileExists(filepath.Join(dir, "a.info.json")))
+	assert.False(t, FileExists(filepath.Join(dir, "a.description.txt")))
+
+	require.NoError(t, RemoveWithSidecars(second, false))
+	assert.True(t, FileExists(filepath.Join(dir, "b.info.json")), "sidecars are kept when disabled")
+
+	assert.NoError(t, RemoveWithSidecars(first, true), "already removed")
+}
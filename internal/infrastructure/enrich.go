@@ -0,0 +1,298 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// MetadataEnrichment runs metadata enrichers on the files of completed
+// downloads. The fields of each file are written to its .info.json and saved
+// in the download's metadata under "enrichments" (keyed by file name); those
+// of the main file (FilePath) also fill in the download's metadata where it
+// has no value, so promoted columns such as language pick them up.
+type MetadataEnrichment struct {
+	enrichers []domain.MetadataEnricher
+	timeout   time.Duration
+}
+
+// NewMetadataEnrichment creates a runner of enrichers, each bounded to timeout
+// per file (0 = no limit)
+func NewMetadataEnrichment(enrichers []domain.MetadataEnricher, timeout time.Duration) *MetadataEnrichment {
+	return &MetadataEnrichment{enrichers: enrichers, timeout: timeout}
+}
+
+// ConfiguredEnrichers returns the enrichers of metadata.enrichers in order,
+// then those of metadata.exec_enrichers by name. ffprobeBinary is used by the
+// ffprobe enricher.
+func ConfiguredEnrichers(config *domain.MetadataConfig, ffprobeBinary string) []domain.MetadataEnricher {
+	var enrichers []domain.MetadataEnricher
+	for _, name := range config.Enrichers {
+		switch name {
+		case domain.EnricherFFprobe:
+			enrichers = append(enrichers, ffprobeEnricher{binary: ffprobeBinary})
+		case domain.EnricherLanguage:
+			enrichers = append(enrichers, languageEnricher{})
+		case domain.EnricherSHA256:
+			enrichers = append(enrichers, sha256Enricher{})
+		}
+	}
+	names := make([]string, 0, len(config.ExecEnrichers))
+	for name := range config.ExecEnrichers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		enrichers = append(enrichers, execEnricher{name: name, command: config.ExecEnrichers[name]})
+	}
+	return enrichers
+}
+
+// Enrich runs every enricher on every file of a completed download and
+// records their fields. An enricher failing on a file is skipped for that
+// file; the failures are returned and saved under "enrich_errors".
+func (e *MetadataEnrichment) Enrich(ctx context.Context, download *domain.Download) []domain.EnrichmentError {
+	downloadMeta := map[string]interface{}{}
+	if download.Metadata != "" {
+		_ = json.Unmarshal([]byte(download.Metadata), &downloadMeta)
+	}
+
+	enrichments := make(map[string]map[string]interface{})
+	var failures []domain.EnrichmentError
+	for _, file := range download.Files() {
+		infoJSONPath := strings.TrimSuffix(file, filepath.Ext(file)) + ".info.json"
+		fileMeta := readInfoJSON(infoJSONPath)
+		if fileMeta == nil {
+			fileMeta = downloadMeta
+		}
+
+		fields := make(map[string]interface{})
+		for _, enricher := range e.enrichers {
+			result, err := e.run(ctx, enricher, file, fileMeta)
+			if err != nil {
+				failures = append(failures, domain.EnrichmentError{Enricher: enricher.Name(), File: file, Error: err.Error()})
+				continue
+			}
+			for key, value := range result {
+				fields[key] = value
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if err := setInfoJSONFields(infoJSONPath, fields); err != nil {
+			failures = append(failures, domain.EnrichmentError{Enricher: "info.json", File: file, Error: err.Error()})
+		}
+		enrichments[filepath.Base(file)] = fields
+		if file == download.FilePath {
+			for key, value := range fields {
+				if current, ok := downloadMeta[key]; !ok || current == nil || current == "" {
+					download.SetMetadataField(key, value)
+				}
+			}
+		}
+	}
+
+	if len(enrichments) > 0 {
+		download.SetMetadataField("enrichments", enrichments)
+	}
+	if len(failures) > 0 {
+		download.SetMetadataField("enrich_errors", failures)
+	}
+	return failures
+}
+
+// run runs one enricher on one file within the timeout
+func (e *MetadataEnrichment) run(ctx context.Context, enricher domain.MetadataEnricher, file string, metadata map[string]interface{}) (map[string]interface{}, error) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	return enricher.Enrich(ctx, file, metadata)
+}
+
+// readInfoJSON parses a .info.json file. Returns nil if it is missing or invalid.
+func readInfoJSON(path string) map[string]interface{} {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var info map[string]interface{}
+	if json.Unmarshal(data, &info) != nil {
+		return nil
+	}
+	return info
+}
+
+// setInfoJSONFields sets fields in an existing .info.json file, replacing
+// their values. Missing files are ignored.
+func setInfoJSONFields(path string, fields map[string]interface{}) error {
+	if !FileExists(path) {
+		return nil
+	}
+	info := readInfoJSON(path)
+	if info == nil {
+		return fmt.Errorf("parse info.json: %s", filepath.Base(path))
+	}
+	for key, value := range fields {
+		info[key] = value
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal info.json: %w", err)
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
+
+// ffprobeEnricher records the duration, resolution, codecs and bit rate of videos
+type ffprobeEnricher struct {
+	binary string
+}
+
+// Name implements domain.MetadataEnricher
+func (ffprobeEnricher) Name() string {
+	return domain.EnricherFFprobe
+}
+
+// ffprobeOutput is the part of ffprobe's JSON output the enricher reads
+type ffprobeOutput struct {
+	Format struct {
+		Duration string `json:"duration"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+}
+
+// Enrich implements domain.MetadataEnricher
+func (e ffprobeEnricher) Enrich(ctx context.Context, file string, _ map[string]interface{}) (map[string]interface{}, error) {
+	if domain.MediaTypeOf(file) != domain.MediaTypeVideo {
+		return nil, nil
+	}
+	output, err := CommandWithCancel(ctx, e.binary, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", file).Output()
+	if err != nil {
+		return nil, &domain.ToolError{Tool: "ffprobe", Err: err}
+	}
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+
+	fields := make(map[string]interface{})
+	if duration, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		fields["duration"] = duration
+	}
+	if bitRate, err := strconv.ParseInt(probe.Format.BitRate, 10, 64); err == nil {
+		fields["bit_rate"] = bitRate
+	}
+	for _, stream := range probe.Streams {
+		switch {
+		case stream.CodecType == "video" && fields["video_codec"] == nil:
+			fields["video_codec"] = stream.CodecName
+			fields["width"] = stream.Width
+			fields["height"] = stream.Height
+		case stream.CodecType == "audio" && fields["audio_codec"] == nil:
+			fields["audio_codec"] = stream.CodecName
+		}
+	}
+	return fields, nil
+}
+
+// languageEnricher detects the language of the post text when the metadata
+// has none (see domain.DetectLanguage)
+type languageEnricher struct{}
+
+// Name implements domain.MetadataEnricher
+func (languageEnricher) Name() string {
+	return domain.EnricherLanguage
+}
+
+// Enrich implements domain.MetadataEnricher
+func (languageEnricher) Enrich(_ context.Context, _ string, metadata map[string]interface{}) (map[string]interface{}, error) {
+	if language, _ := metadata["language"].(string); language != "" {
+		return nil, nil
+	}
+	text, _ := metadata["description"].(string)
+	if text == "" {
+		text, _ = metadata["title"].(string)
+	}
+	language := domain.DetectLanguage(text)
+	if language == "" {
+		return nil, nil
+	}
+	return map[string]interface{}{"language": language}, nil
+}
+
+// sha256Enricher records the SHA-256 checksum of each file
+type sha256Enricher struct{}
+
+// Name implements domain.MetadataEnricher
+func (sha256Enricher) Name() string {
+	return domain.EnricherSHA256
+}
+
+// Enrich implements domain.MetadataEnricher
+func (sha256Enricher) Enrich(_ context.Context, file string, _ map[string]interface{}) (map[string]interface{}, error) {
+	sum, err := hashFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"sha256": hex.EncodeToString(sum)}, nil
+}
+
+// execEnricher runs a metadata.exec_enrichers command with the file path
+// appended and takes the JSON object it prints as the fields
+type execEnricher struct {
+	name    string
+	command string
+}
+
+// Name implements domain.MetadataEnricher
+func (e execEnricher) Name() string {
+	return e.name
+}
+
+// Enrich implements domain.MetadataEnricher
+func (e execEnricher) Enrich(ctx context.Context, file string, _ map[string]interface{}) (map[string]interface{}, error) {
+	args := append(strings.Fields(e.command), file)
+	var stderr bytes.Buffer
+	cmd := CommandWithCancel(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("command failed: %s", lastOutputLine(stderr.String(), err))
+	}
+
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, nil
+	}
+	var fields map[string]interface{}
+	if output[0] == '[' {
+		// exiftool -json and similar print an array of one object per file
+		var objects []map[string]interface{}
+		if err := json.Unmarshal(output, &objects); err != nil || len(objects) != 1 {
+			return nil, fmt.Errorf("command did not print a JSON object")
+		}
+		fields = objects[0]
+	} else if err := json.Unmarshal(output, &fields); err != nil {
+		return nil, fmt.Errorf("command did not print a JSON object: %w", err)
+	}
+	return fields, nil
+}
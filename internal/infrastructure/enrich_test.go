@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// fakeFFprobe prints the probe of a 1280x720 H.264 video with AAC audio
+const fakeFFprobe = `#!/bin/sh
+cat <<'JSON'
+{"streams": [{"codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720},
+             {"codec_type": "audio", "codec_name": "aac"}],
+ "format": {"duration": "12.500000", "bit_rate": "800000"}}
+JSON
+`
+
+// fakeExifTool prints an exiftool -json style array, and fails on .png files
+const fakeExifTool = `#!/bin/sh
+case "$2" in
+  *.png) echo "Error: unsupported file" >&2; exit 1 ;;
+esac
+echo '[{"Make": "Canon", "Flag": "'$1'"}]'
+`
+
+func TestMetadataEnrichment_Enrich(t *testing.T) {
+	dir := t.TempDir()
+	ffprobe := filepath.Join(dir, "ffprobe")
+	require.NoError(t, os.WriteFile(ffprobe, []byte(fakeFFprobe), 0755))
+	exifTool := filepath.Join(dir, "exiftool")
+	require.NoError(t, os.WriteFile(exifTool, []byte(fakeExifTool), 0755))
+
+	video := filepath.Join(dir, "clip.mp4")
+	photo := filepath.Join(dir, "photo.png")
+	require.NoError(t, os.WriteFile(video, []byte("video"), 0644))
+	require.NoError(t, os.WriteFile(photo, []byte("photo"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "clip.info.json"),
+		[]byte(`{"title": "clip", "description": "Das ist nicht die neue Version und sie ist auch nicht gut"}`), 0644))
+
+	enrichers := ConfiguredEnrichers(&domain.MetadataConfig{
+		Enrichers:     []string{domain.EnricherFFprobe, domain.EnricherLanguage, domain.EnricherSHA256},
+		ExecEnrichers: map[string]string{"exif": exifTool + " -json"},
+	}, ffprobe)
+	require.Len(t, enrichers, 4)
+	enrichment := NewMetadataEnrichment(enrichers, time.Minute)
+
+	download := domain.NewDownload("https://t.me/channel/1", domain.PlatformTelegram, domain.ModeDefault)
+	download.Metadata = `{"title": "clip", "files": ["` + video + `", "` + photo + `"]}`
+	download.FilePath = video
+
+	failures := enrichment.Enrich(context.Background(), download)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "exif", failures[0].Enricher)
+	assert.Equal(t, photo, failures[0].File)
+	assert.Contains(t, failures[0].Error, "unsupported file")
+
+	// The main file's fields fill in the download's metadata
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(download.Metadata), &meta))
+	assert.Equal(t, "clip", meta["title"])
+	assert.Equal(t, "de", meta["language"])
+	assert.Equal(t, 12.5, meta["duration"])
+	assert.Equal(t, "h264", meta["video_codec"])
+	assert.Equal(t, "Canon", meta["Make"])
+	enrichments := meta["enrichments"].(map[string]interface{})
+	assert.Len(t, enrichments, 2)
+	photoFields := enrichments["photo.png"].(map[string]interface{})
+	assert.Equal(t, "55c64d0fcd6f9d5f7c828093857e3fdfda68478bb4e9bd24d481ef391c7804e8", photoFields["sha256"])
+	assert.NotContains(t, photoFields, "video_codec", "ffprobe only probes videos")
+	assert.Len(t, meta["enrich_errors"], 1)
+
+	// and are written to its .info.json
+	info := readInfoJSON(filepath.Join(dir, "clip.info.json"))
+	assert.Equal(t, float64(1280), info["width"])
+	assert.Equal(t, "aac", info["audio_codec"])
+	assert.Equal(t, "de", info["language"])
+	assert.Equal(t, "-json", info["Flag"])
+}
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+	"regexp"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// Tool output classified by classifyToolFailure, checked in this order after
+// rate limits (rateLimitRe)
+var (
+	// tdl: "FLOOD_WAIT (30)"
+	floodWaitRe = regexp.MustCompile(`FLOOD_WAIT|FLOOD_PREMIUM_WAIT`)
+	diskFullRe  = regexp.MustCompile(`(?i)No space left on device|disk quota exceeded`)
+	// tdl: revoked or unknown sessions and channels the account may not read
+	tdlAuthErrorRe = regexp.MustCompile(`AUTH_KEY_UNREGISTERED|AUTH_KEY_INVALID|SESSION_REVOKED|SESSION_EXPIRED|USER_DEACTIVATED|CHANNEL_PRIVATE|(?i)not authorized|login required`)
+	notFoundRe     = regexp.MustCompile(`(?i)HTTP Error 404|404 Not Found|does not exist|no longer (available|exists)|(post|tweet|video|page|media) (is )?unavailable|has been (deleted|removed)|CHANNEL_INVALID|MESSAGE_ID_INVALID|USERNAME_NOT_OCCUPIED|USERNAME_INVALID|PEER_ID_INVALID`)
+	parseErrorRe   = regexp.MustCompile(`(?i)Unable to extract|Unsupported URL|No video could be found|No suitable extractor|JSONDecodeError|failed to parse|unable to parse|unable to decode`)
+	networkErrorRe = regexp.MustCompile(`(?i)connection (reset|refused|aborted|timed out)|timed out|i/o timeout|Temporary failure in name resolution|Name or service not known|no such host|network is unreachable|TLS handshake|SSL: |unexpected EOF|Remote end closed connection|Unable to download webpage`)
+)
+
+// classifyToolFailure returns the error code of a failed tool run from its
+// error and output ("" when not recognised)
+func classifyToolFailure(err error, output string) domain.ErrorCode {
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return domain.ErrorToolMissing
+	case rateLimitRe.MatchString(output), floodWaitRe.MatchString(output):
+		return domain.ErrorRateLimited
+	case diskFullRe.MatchString(output):
+		return domain.ErrorDiskFull
+	case xAuthErrorRe.MatchString(output), tdlAuthErrorRe.MatchString(output):
+		return domain.ErrorAuthExpired
+	case notFoundRe.MatchString(output):
+		return domain.ErrorNotFound
+	case parseErrorRe.MatchString(output):
+		return domain.ErrorParse
+	case networkErrorRe.MatchString(output):
+		return domain.ErrorNetwork
+	}
+	return ""
+}
+
+// toolError returns the error of a failed tool run, classified from its output
+func toolError(tool string, err error, output string) *domain.ToolError {
+	return &domain.ToolError{Tool: tool, Err: err, Code: classifyToolFailure(err, output)}
+}
+
+// outputTail keeps the last bytes of a tool's output, enough to classify a
+// failure without holding all the progress output of a long download
+type outputTail struct {
+	max int
+	buf []byte
+}
+
+func newOutputTail() *outputTail {
+	return &outputTail{max: 64 * 1024}
+}
+
+// Write implements io.Writer
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the output kept
+func (t *outputTail) String() string {
+	return string(t.buf)
+}
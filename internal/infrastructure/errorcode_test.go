@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestClassifyToolFailure(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		output string
+		want   domain.ErrorCode
+	}{
+		{"ERROR: [twitter] 123: HTTP Error 429: Too Many Requests", domain.ErrorRateLimited},
+		{"rpc error code 420: FLOOD_WAIT (30)", domain.ErrorRateLimited},
+		{"ERROR: unable to write data: [Errno 28] No space left on device", domain.ErrorDiskFull},
+		{"ERROR: [twitter] 123: HTTP Error 401: Unauthorized", domain.ErrorAuthExpired},
+		{"Error: rpc error code 401: AUTH_KEY_UNREGISTERED", domain.ErrorAuthExpired},
+		{"ERROR: [twitter] 123: HTTP Error 404: Not Found", domain.ErrorNotFound},
+		{"Error: rpc error code 400: MESSAGE_ID_INVALID", domain.ErrorNotFound},
+		{"ERROR: [generic] Unable to extract title; please report this issue", domain.ErrorParse},
+		{"ERROR: Unsupported URL: https://example.com/", domain.ErrorParse},
+		{"ERROR: [twitter] 123: Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>", domain.ErrorNetwork},
+		{"Error: read tcp 10.0.0.2:5000: i/o timeout", domain.ErrorNetwork},
+		{"ERROR: something went wrong", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyToolFailure(exitErr, tt.output), tt.output)
+	}
+
+	// The tool could not be started
+	_, err := exec.Command(filepath.Join(t.TempDir(), "yt-dlp")).Output()
+	assert.Equal(t, domain.ErrorToolMissing, classifyToolFailure(err, ""))
+	_, err = exec.Command("x-extract-no-such-tool").Output()
+	assert.Equal(t, domain.ErrorToolMissing, classifyToolFailure(err, ""))
+
+	toolErr := toolError("yt-dlp", exitErr, "HTTP Error 404: Not Found")
+	assert.Equal(t, "yt-dlp", toolErr.Tool)
+	assert.Equal(t, domain.ErrorNotFound, toolErr.Code)
+}
+
+func TestOutputTail(t *testing.T) {
+	tail := &outputTail{max: 8}
+	n, err := tail.Write([]byte("progress 1%\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+	tail.Write([]byte("ERR 404"))
+	assert.Equal(t, "\nERR 404", tail.String())
+
+	tail = newOutputTail()
+	tail.Write([]byte(strings.Repeat("x", 100*1024) + "HTTP Error 404"))
+	assert.Len(t, tail.String(), 64*1024)
+	assert.True(t, strings.HasSuffix(tail.String(), "HTTP Error 404"))
+}
@@ -0,0 +1,174 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// largeCopySize is the size from which a copy reports its progress
+const largeCopySize = 64 << 20
+
+// copyProgressInterval is the minimum time between progress reports of a copy
+const copyProgressInterval = time.Second
+
+// CopyFileWithProgress copies src to dst without holding the file in memory.
+// The data is streamed into a temporary file next to dst, synced, checked
+// against the size and SHA-256 of what was read from src, and only then
+// renamed over dst. Copies of files of at least largeCopySize report their
+// percent and speed to progress, which may be nil.
+func CopyFileWithProgress(src, dst string, progress domain.DownloadProgressCallback) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(dst)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	srcHash := sha256.New()
+	var out io.Writer = tmp
+	if progress != nil && info.Size() >= largeCopySize {
+		out = &copyProgressWriter{
+			w:        tmp,
+			total:    info.Size(),
+			file:     filepath.Base(dst),
+			callback: progress,
+			start:    time.Now(),
+		}
+	}
+	written, err := io.Copy(io.MultiWriter(out, srcHash), in)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if written != info.Size() {
+		tmp.Close()
+		return fmt.Errorf("copied %d of %d bytes of %s", written, info.Size(), src)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	dstHash, err := hashFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(dstHash, srcHash.Sum(nil)) {
+		return fmt.Errorf("copy of %s does not match the original", src)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// MoveFileWithProgress moves src to dst like MoveFile, reporting the
+// progress of a large copy across file systems to progress (may be nil)
+func MoveFileWithProgress(src, dst string, progress domain.DownloadProgressCallback) error {
+	if err := os.Rename(src, dst); err != nil {
+		// Rename failed (possibly cross-device), try copy and delete
+		if err := CopyFileWithProgress(src, dst, progress); err != nil {
+			return fmt.Errorf("failed to move file %s to %s: %w", src, dst, err)
+		}
+		os.Remove(src)
+	}
+	return nil
+}
+
+// MoveWithSidecarsAcross moves a media file with its .info.json and
+// .description.txt to dest (or a free variant of it, see uniqueFilePath),
+// creating dest's directory. Unlike RenameWithSidecars, dest may be on
+// another file system: files are then copied, verified and removed. Returns
+// the final path.
+func MoveWithSidecarsAcross(file, dest string, progress domain.DownloadProgressCallback) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return file, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dest), err)
+	}
+	dest = uniqueFilePath(dest, file)
+	if dest == file {
+		return file, nil
+	}
+	if err := MoveFileWithProgress(file, dest, progress); err != nil {
+		return file, err
+	}
+
+	srcStem := strings.TrimSuffix(file, filepath.Ext(file))
+	destStem := strings.TrimSuffix(dest, filepath.Ext(dest))
+	for _, suffix := range renameSidecarSuffixes {
+		if FileExists(srcStem + suffix) {
+			if err := MoveFile(srcStem+suffix, destStem+suffix); err != nil {
+				return dest, err
+			}
+		}
+	}
+	return dest, nil
+}
+
+// hashFile returns the SHA-256 of a file's content
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// copyProgressWriter passes writes through to w and reports the share of
+// total written so far, at most once per copyProgressInterval
+type copyProgressWriter struct {
+	w          io.Writer
+	total      int64
+	written    int64
+	file       string
+	callback   domain.DownloadProgressCallback
+	start      time.Time
+	lastReport time.Time
+}
+
+// Write implements io.Writer
+func (p *copyProgressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if now := time.Now(); now.Sub(p.lastReport) >= copyProgressInterval || p.written == p.total {
+		p.lastReport = now
+		progress := domain.DownloadProgress{
+			Percent:     float64(p.written) * 100 / float64(p.total),
+			CurrentFile: p.file,
+		}
+		if elapsed := now.Sub(p.start).Seconds(); elapsed > 0 {
+			progress.Speed = domain.FormatBytes(int64(float64(p.written)/elapsed)) + "/s"
+		}
+		p.callback(progress)
+	}
+	return n, err
+}
@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestCopyFileWithProgress(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "video.mp4")
+	data := bytes.Repeat([]byte("0123456789"), 100000)
+	require.NoError(t, os.WriteFile(src, data, 0600))
+
+	dst := filepath.Join(dir, "completed.mp4")
+	var reports int
+	require.NoError(t, CopyFileWithProgress(src, dst, func(domain.DownloadProgress) { reports++ }))
+
+	got, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Zero(t, reports, "small copies report no progress")
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary file is left behind")
+}
+
+func TestCopyFileWithProgress_MissingSource(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "dst.mp4")
+	assert.Error(t, CopyFileWithProgress(filepath.Join(dir, "missing.mp4"), dst, nil))
+	assert.False(t, FileExists(dst))
+}
+
+func TestMoveFileWithProgress(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.mp4")
+	require.NoError(t, os.WriteFile(src, []byte("video"), 0644))
+
+	dst := filepath.Join(dir, "dst.mp4")
+	require.NoError(t, MoveFileWithProgress(src, dst, nil))
+	assert.False(t, FileExists(src))
+	got, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "video", string(got))
+}
+
+func TestCopyProgressWriter(t *testing.T) {
+	var reports []domain.DownloadProgress
+	var buf bytes.Buffer
+	w := &copyProgressWriter{
+		w:        &buf,
+		total:    100,
+		file:     "video.mp4",
+		callback: func(p domain.DownloadProgress) { reports = append(reports, p) },
+		start:    time.Now().Add(-time.Second),
+	}
+
+	w.Write(make([]byte, 40))
+	w.Write(make([]byte, 40)) // Within copyProgressInterval of the first report
+	w.Write(make([]byte, 20)) // The last write always reports
+
+	assert.Equal(t, 100, buf.Len())
+	require.Len(t, reports, 2)
+	assert.Equal(t, 40.0, reports[0].Percent)
+	assert.Equal(t, 100.0, reports[1].Percent)
+	assert.Equal(t, "video.mp4", reports[1].CurrentFile)
+	assert.Contains(t, reports[1].Speed, "/s")
+}
@@ -0,0 +1,109 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// FilePermissions sets the mode and owner of completed files and of the
+// directories they were sorted into below the completed directory
+// (download.file_mode, dir_mode and file_owner)
+type FilePermissions struct {
+	completedDir string
+	fileMode     os.FileMode // 0 = unchanged
+	dirMode      os.FileMode // 0 = unchanged
+	uid, gid     int         // -1 = unchanged
+}
+
+// NewFilePermissions creates the permission settings applied to files
+// completed under completedDir. owner is "user", "user:group", ":group" or
+// numeric "uid:gid"; names are looked up now, so a missing user fails at
+// startup rather than on every download. Returns nil when nothing is set.
+func NewFilePermissions(completedDir, fileMode, dirMode, owner string) (*FilePermissions, error) {
+	if fileMode == "" && dirMode == "" && owner == "" {
+		return nil, nil
+	}
+	p := &FilePermissions{completedDir: completedDir, uid: -1, gid: -1}
+	var err error
+	if p.fileMode, err = domain.ParseFileMode(fileMode); err != nil {
+		return nil, fmt.Errorf("invalid file_mode: %w", err)
+	}
+	if p.dirMode, err = domain.ParseFileMode(dirMode); err != nil {
+		return nil, fmt.Errorf("invalid dir_mode: %w", err)
+	}
+
+	userName, groupName, _ := strings.Cut(owner, ":")
+	if userName != "" {
+		if p.uid, err = strconv.Atoi(userName); err != nil {
+			u, err := user.Lookup(userName)
+			if err != nil {
+				return nil, fmt.Errorf("invalid file_owner: %w", err)
+			}
+			p.uid, _ = strconv.Atoi(u.Uid)
+		}
+	}
+	if groupName != "" {
+		if p.gid, err = strconv.Atoi(groupName); err != nil {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return nil, fmt.Errorf("invalid file_owner: %w", err)
+			}
+			p.gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+	return p, nil
+}
+
+// Apply sets the configured mode and owner on files, their .info.json and
+// .description.txt sidecars and their parent directories up to, but not
+// including, the completed directory. A file dedupe hard-linked to another
+// is left alone: the mode and owner belong to the inode, so changing them
+// would change the archived original too. Every file is tried; the errors
+// are joined.
+func (p *FilePermissions) Apply(files []string) error {
+	var errs []error
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		if !hardLinked(file) {
+			errs = append(errs, p.apply(file, p.fileMode))
+		}
+		stem := strings.TrimSuffix(file, filepath.Ext(file))
+		for _, suffix := range renameSidecarSuffixes {
+			if sidecar := stem + suffix; FileExists(sidecar) && !hardLinked(sidecar) {
+				errs = append(errs, p.apply(sidecar, p.fileMode))
+			}
+		}
+		for dir := filepath.Dir(file); p.below(dir) && !dirs[dir]; dir = filepath.Dir(dir) {
+			dirs[dir] = true
+			errs = append(errs, p.apply(dir, p.dirMode))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// below reports whether path is inside the completed directory
+func (p *FilePermissions) below(path string) bool {
+	rel, err := filepath.Rel(p.completedDir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (p *FilePermissions) apply(path string, mode os.FileMode) error {
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+	}
+	if p.uid != -1 || p.gid != -1 {
+		if err := os.Lchown(path, p.uid, p.gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFilePermissions_NothingSet(t *testing.T) {
+	p, err := NewFilePermissions(t.TempDir(), "", "", "")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestNewFilePermissions_Owner(t *testing.T) {
+	uid, gid := os.Getuid(), os.Getgid()
+	p, err := NewFilePermissions(t.TempDir(), "", "", strconv.Itoa(uid)+":"+strconv.Itoa(gid))
+	require.NoError(t, err)
+	assert.Equal(t, uid, p.uid)
+	assert.Equal(t, gid, p.gid)
+
+	p, err = NewFilePermissions(t.TempDir(), "", "", ":"+strconv.Itoa(gid))
+	require.NoError(t, err)
+	assert.Equal(t, -1, p.uid)
+
+	_, err = NewFilePermissions(t.TempDir(), "", "", "no-such-user-x-extract")
+	assert.Error(t, err)
+}
+
+func TestFilePermissions_Apply(t *testing.T) {
+	completedDir := t.TempDir()
+	require.NoError(t, os.Chmod(completedDir, 0700))
+	subdir := filepath.Join(completedDir, "alice", "2026-01")
+	require.NoError(t, os.MkdirAll(subdir, 0700))
+	file := filepath.Join(subdir, "a.mp4")
+	require.NoError(t, os.WriteFile(file, []byte("video"), 0600))
+
+	p, err := NewFilePermissions(completedDir, "0644", "0755",
+		strconv.Itoa(os.Getuid())+":"+strconv.Itoa(os.Getgid()))
+	require.NoError(t, err)
+	require.NoError(t, p.Apply([]string{file}))
+
+	mode := func(path string) os.FileMode {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info.Mode().Perm()
+	}
+	assert.Equal(t, os.FileMode(0644), mode(file))
+	assert.Equal(t, os.FileMode(0755), mode(subdir))
+	assert.Equal(t, os.FileMode(0755), mode(filepath.Join(completedDir, "alice")))
+	assert.Equal(t, os.FileMode(0700), mode(completedDir), "the completed dir itself is left alone")
+}
+
+func TestFilePermissions_ApplySidecarsAndSkipsLinks(t *testing.T) {
+	completedDir := t.TempDir()
+	file := filepath.Join(completedDir, "a.mp4")
+	sidecar := filepath.Join(completedDir, "a.info.json")
+	description := filepath.Join(completedDir, "a.description.txt")
+	original := filepath.Join(completedDir, "original.mp4")
+	linked := filepath.Join(completedDir, "b.mp4")
+	for _, path := range []string{file, sidecar, description, original} {
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0600))
+	}
+	require.NoError(t, os.Link(original, linked))
+
+	p, err := NewFilePermissions(completedDir, "0644", "", "")
+	require.NoError(t, err)
+	require.NoError(t, p.Apply([]string{file, linked}))
+
+	mode := func(path string) os.FileMode {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info.Mode().Perm()
+	}
+	assert.Equal(t, os.FileMode(0644), mode(file))
+	assert.Equal(t, os.FileMode(0644), mode(sidecar))
+	assert.Equal(t, os.FileMode(0644), mode(description))
+	assert.Equal(t, os.FileMode(0600), mode(original), "the hard-linked original keeps its mode")
+}
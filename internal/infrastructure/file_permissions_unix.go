@@ -0,0 +1,15 @@
+//go:build !windows
+
+package infrastructure
+
+import "syscall"
+
+// hardLinked reports whether path has more than one link, e.g. a duplicate
+// dedupe replaced with a link to the original
+func hardLinked(path string) bool {
+	var stat syscall.Stat_t
+	if syscall.Lstat(path, &stat) != nil {
+		return false
+	}
+	return stat.Nlink > 1
+}
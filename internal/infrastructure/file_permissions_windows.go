@@ -0,0 +1,26 @@
+//go:build windows
+
+package infrastructure
+
+import "syscall"
+
+// hardLinked reports whether path has more than one link, e.g. a duplicate
+// dedupe replaced with a link to the original
+func hardLinked(path string) bool {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	handle, err := syscall.CreateFile(name, 0,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_OPEN_REPARSE_POINT|syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+	var info syscall.ByHandleFileInformation
+	if syscall.GetFileInformationByHandle(handle, &info) != nil {
+		return false
+	}
+	return info.NumberOfLinks > 1
+}
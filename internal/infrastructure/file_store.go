@@ -0,0 +1,187 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileStore gives access to the files of completed downloads wherever
+// storage.backend keeps them. Files are addressed by the path recorded on
+// their download; their storage key is that path relative to completed/. A
+// file whose local copy was removed after upload (storage.delete_local) is
+// fetched back from the backend on demand: into a cache under cacheDir for
+// reading, evicted least recently used first above maxCacheSize, or to its
+// own path when it is moved.
+type FileStore struct {
+	storage      Storage
+	completedDir string
+	cacheDir     string
+	maxCacheSize int64                // 0 = no limit
+	lastUsed     map[string]time.Time // Cached files read since the start; others by fetch time
+	mu           sync.Mutex           // Serializes fetches and cache eviction
+}
+
+// NewFileStore creates a file store over a storage backend, caching fetched
+// files under cacheDir (typically base_dir/storage-cache)
+func NewFileStore(storage Storage, completedDir, cacheDir string, maxCacheSize int64) *FileStore {
+	return &FileStore{
+		storage:      storage,
+		completedDir: completedDir,
+		cacheDir:     cacheDir,
+		maxCacheSize: maxCacheSize,
+		lastUsed:     make(map[string]time.Time),
+	}
+}
+
+// Store uploads a completed file under key (see Storage) and drops any cached
+// copy of a file previously stored there
+func (s *FileStore) Store(ctx context.Context, file, key string) (string, error) {
+	remoteURL, err := s.storage.Store(ctx, file, key)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	os.Remove(s.cachePath(key))
+	s.mu.Unlock()
+	return remoteURL, nil
+}
+
+// LocalPath returns a local path with the content of file: file itself, or a
+// cached copy of its stored copy when the local one was removed. The error
+// wraps os.ErrNotExist when the file is in neither place.
+func (s *FileStore) LocalPath(ctx context.Context, file string) (string, error) {
+	if FileExists(file) {
+		return file, nil
+	}
+	key := RelativeToCompleted(s.completedDir, file)
+	cached := s.cachePath(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if FileExists(cached) {
+		// Not touching the file: thumbnails are keyed by its modification time
+		s.lastUsed[cached] = time.Now()
+		return cached, nil
+	}
+	if err := s.fetch(ctx, key, cached); err != nil {
+		return "", err
+	}
+	s.lastUsed[cached] = time.Now()
+	s.evict(cached)
+	return cached, nil
+}
+
+// Restore fetches the stored copy of file back to its own path when its local
+// copy was removed, so it can be moved like any other completed file
+func (s *FileStore) Restore(ctx context.Context, file string) error {
+	if FileExists(file) {
+		return nil
+	}
+	key := RelativeToCompleted(s.completedDir, file)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached := s.cachePath(key); FileExists(cached) && os.MkdirAll(filepath.Dir(file), 0755) == nil {
+		if err := MoveFile(cached, file); err == nil {
+			return nil
+		}
+	}
+	return s.fetch(ctx, key, file)
+}
+
+// Delete removes the stored and cached copies of file. Its local copy is left
+// to the caller.
+func (s *FileStore) Delete(ctx context.Context, file string) error {
+	key := RelativeToCompleted(s.completedDir, file)
+	s.mu.Lock()
+	os.Remove(s.cachePath(key))
+	s.mu.Unlock()
+	return s.storage.Delete(ctx, key)
+}
+
+// cachePath returns where the cached copy of key is kept
+func (s *FileStore) cachePath(key string) string {
+	return filepath.Join(s.cacheDir, filepath.FromSlash(key))
+}
+
+// fetch writes the file stored under key to dest, through a temporary file
+// so a failed fetch leaves nothing behind
+func (s *FileStore) fetch(ctx context.Context, key, dest string) error {
+	body, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// evict removes the least recently used cached files until the cache fits in
+// maxCacheSize, keeping keep (the file just fetched) even when it alone is
+// larger
+func (s *FileStore) evict(keep string) {
+	if s.maxCacheSize <= 0 {
+		return
+	}
+	type cachedFile struct {
+		path     string
+		size     int64
+		lastUsed time.Time
+	}
+	var files []cachedFile
+	var total int64
+	_ = filepath.WalkDir(s.cacheDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		lastUsed := info.ModTime()
+		if used, ok := s.lastUsed[path]; ok {
+			lastUsed = used
+		}
+		files = append(files, cachedFile{path: path, size: info.Size(), lastUsed: lastUsed})
+		total += info.Size()
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].lastUsed.Before(files[j].lastUsed) })
+	for _, file := range files {
+		if total <= s.maxCacheSize {
+			return
+		}
+		if file.path == keep {
+			continue
+		}
+		if err := os.Remove(file.path); err == nil {
+			total -= file.size
+			delete(s.lastUsed, file.path)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage keeps stored files in memory and counts fetches
+type memoryStorage struct {
+	files   map[string]string
+	fetches int
+}
+
+func (m *memoryStorage) Store(ctx context.Context, file, key string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	m.files[key] = string(data)
+	return "https://bucket.example.com/" + key, nil
+}
+
+func (m *memoryStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m.files[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, os.ErrNotExist)
+	}
+	m.fetches++
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+func TestFileStore_LocalPath(t *testing.T) {
+	base := t.TempDir()
+	completedDir := filepath.Join(base, "completed")
+	cacheDir := filepath.Join(base, "storage-cache")
+	require.NoError(t, os.MkdirAll(filepath.Join(completedDir, "alice"), 0755))
+	local := filepath.Join(completedDir, "alice", "a.jpg")
+	require.NoError(t, os.WriteFile(local, []byte("local"), 0644))
+
+	storage := &memoryStorage{files: map[string]string{"alice/b.jpg": "stored"}}
+	store := NewFileStore(storage, completedDir, cacheDir, 0)
+
+	// A local copy is used as is
+	path, err := store.LocalPath(context.Background(), local)
+	require.NoError(t, err)
+	assert.Equal(t, local, path)
+
+	// A removed one is fetched into the cache once
+	removed := filepath.Join(completedDir, "alice", "b.jpg")
+	for i := 0; i < 2; i++ {
+		path, err = store.LocalPath(context.Background(), removed)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(cacheDir, "alice", "b.jpg"), path)
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "stored", string(data))
+	assert.Equal(t, 1, storage.fetches)
+
+	_, err = store.LocalPath(context.Background(), filepath.Join(completedDir, "alice", "missing.jpg"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestFileStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	base := t.TempDir()
+	completedDir := filepath.Join(base, "completed")
+	storage := &memoryStorage{files: map[string]string{
+		"a.jpg": "aaaa",
+		"b.jpg": "bbbb",
+		"c.jpg": "cccc",
+	}}
+	store := NewFileStore(storage, completedDir, filepath.Join(base, "storage-cache"), 10)
+
+	var cached []string
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		path, err := store.LocalPath(context.Background(), filepath.Join(completedDir, name))
+		require.NoError(t, err)
+		cached = append(cached, path)
+	}
+	// Reading a makes b the least recently used
+	_, err := store.LocalPath(context.Background(), filepath.Join(completedDir, "a.jpg"))
+	require.NoError(t, err)
+	_, err = store.LocalPath(context.Background(), filepath.Join(completedDir, "c.jpg"))
+	require.NoError(t, err)
+
+	assert.FileExists(t, cached[0])
+	assert.NoFileExists(t, cached[1])
+}
+
+func TestFileStore_RestoreAndDelete(t *testing.T) {
+	base := t.TempDir()
+	completedDir := filepath.Join(base, "completed")
+	storage := &memoryStorage{files: map[string]string{"alice/a.jpg": "stored"}}
+	store := NewFileStore(storage, completedDir, filepath.Join(base, "storage-cache"), 0)
+
+	file := filepath.Join(completedDir, "alice", "a.jpg")
+	require.NoError(t, store.Restore(context.Background(), file))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "stored", string(data))
+
+	require.NoError(t, store.Delete(context.Background(), file))
+	assert.Empty(t, storage.files)
+	assert.FileExists(t, file, "the local copy is left to the caller")
+}
@@ -0,0 +1,296 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// liveWaitRetry is yt-dlp's --wait-for-video interval (seconds between checks)
+// while a scheduled broadcast has not gone live yet
+const liveWaitRetry = "30"
+
+// LiveRecorder implements Downloader for live broadcasts (X broadcasts and
+// Telegram live streams, see domain.IsLiveURL). It records with yt-dlp from
+// the start of the stream, waiting for scheduled broadcasts to go live.
+// Cancelling ctx stops the recording and keeps what was recorded.
+type LiveRecorder struct {
+	DownloadLogger     // Embedded shared log file operations
+	MetadataExtensions // Embedded user-configured extra metadata fields
+	ytdlpBinary        string
+	cookieFile         string // X cookie file, passed for X broadcasts only
+	impersonate        string // yt-dlp --impersonate target (empty = none)
+	userAgent          string // yt-dlp --user-agent (empty = default)
+	incomingDir        string
+	completedDir       string
+	eventLogger        *logger.MultiLogger
+	wrapper            CommandWrapper // twitter.wrapper yt-dlp runs under
+}
+
+// NewLiveRecorder creates a new live broadcast recorder
+func NewLiveRecorder(ytdlpBinary, cookieFile, incomingDir, completedDir, logsDir string, eventLogger *logger.MultiLogger) *LiveRecorder {
+	return &LiveRecorder{
+		DownloadLogger: DownloadLogger{LogsDir: logsDir},
+		ytdlpBinary:    ytdlpBinary,
+		cookieFile:     cookieFile,
+		incomingDir:    incomingDir,
+		completedDir:   completedDir,
+		eventLogger:    eventLogger,
+	}
+}
+
+// SetClientOptions sets the yt-dlp client fingerprint (twitter.impersonate and
+// twitter.user_agent) used for recordings
+func (r *LiveRecorder) SetClientOptions(impersonate, userAgent string) {
+	r.impersonate = impersonate
+	r.userAgent = userAgent
+}
+
+// SetWrapper sets the command recordings run yt-dlp under (twitter.wrapper)
+func (r *LiveRecorder) SetWrapper(wrapper []string) {
+	r.wrapper = wrapper
+}
+
+// Platform returns the platform this downloader handles. Live broadcasts keep
+// the platform of their URL; the download manager routes them here by URL.
+func (r *LiveRecorder) Platform() domain.Platform {
+	return ""
+}
+
+// Validate validates if the recorder can handle the given URL
+func (r *LiveRecorder) Validate(url string) error {
+	if !domain.IsLiveURL(url) {
+		return fmt.Errorf("not a live broadcast URL: %s", url)
+	}
+	return nil
+}
+
+// Download records the broadcast until it ends or ctx is cancelled
+func (r *LiveRecorder) Download(ctx context.Context, download *domain.Download, progressCallback domain.DownloadProgressCallback) (*domain.DownloadResult, error) {
+	if err := r.Validate(download.URL); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.incomingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create incoming directory: %w", err)
+	}
+	if progressCallback == nil {
+		progressCallback = func(progress domain.DownloadProgress) {}
+	}
+
+	downloadLog, err := r.OpenDownloadLogFile(download.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer downloadLog.Close()
+
+	args := r.buildArgs(download)
+	clientProfile := domain.YTDLPClientProfile(r.impersonate, r.userAgent)
+	r.WriteLogHeader(downloadLog, download.ID, r.wrapper.CommandLine(r.ytdlpBinary, args...))
+
+	// SIGINT on cancel: yt-dlp stops recording and finalizes the file
+	program, wrapped := r.wrapper.Wrap(r.ytdlpBinary, args...)
+	cmd := CommandWithInterrupt(ctx, program, wrapped...)
+	output := newOutputTail()
+	sink := io.MultiWriter(downloadLog, output, newProgressWriter(progressCallback))
+	cmd.Stdout = sink
+	cmd.Stderr = sink
+	started := time.Now()
+	err = cmd.Run()
+	recordProcessUsage(ctx, cmd, started)
+
+	stopped := ctx.Err() != nil
+	if err != nil && !stopped {
+		r.removeRecordingFiles(download.ID)
+		r.WriteLogFooter(downloadLog, false, fmt.Sprintf("yt-dlp failed: %v", err))
+		progressCallback(domain.DownloadProgress{Percent: -1}) // Signal failure
+		return nil, domain.WithClientProfile(toolError("yt-dlp", err, output.String()), clientProfile)
+	}
+
+	files := r.findRecordingFiles(download.ID, stopped)
+	if len(files) == 0 {
+		r.removeRecordingFiles(download.ID)
+		if stopped {
+			r.WriteLogFooter(downloadLog, false, "Stopped before anything was recorded")
+			return nil, domain.WithClientProfile(fmt.Errorf("recording stopped before anything was recorded: %w", ctx.Err()), clientProfile)
+		}
+		r.WriteLogFooter(downloadLog, false, "No files recorded")
+		return nil, domain.WithClientProfile(fmt.Errorf("no files recorded"), clientProfile)
+	}
+
+	completedFiles, meta, err := r.finishFiles(download, files)
+	if err != nil {
+		r.WriteLogFooter(downloadLog, false, fmt.Sprintf("Failed to move files: %v", err))
+		return nil, domain.WithClientProfile(err, clientProfile)
+	}
+
+	if stopped {
+		fmt.Fprintf(downloadLog, "\n[live] recording stopped by request\n")
+		if r.eventLogger != nil {
+			r.eventLogger.LogQueueEvent("live_recording_stopped",
+				zap.String("download_id", download.ID),
+				zap.String("url", download.URL))
+		}
+	}
+	r.WriteLogFooter(downloadLog, true, fmt.Sprintf("Recorded: %s", completedFiles[0]))
+	progressCallback(domain.DownloadProgress{Percent: 100}) // Signal success
+	return &domain.DownloadResult{Files: completedFiles, Metadata: meta.ToMap(), ClientProfile: clientProfile}, nil
+}
+
+// buildArgs builds the yt-dlp command. Files are named live_{download id}_{id}
+// so a recording's files are found without knowing the broadcast's metadata.
+func (r *LiveRecorder) buildArgs(download *domain.Download) []string {
+	args := []string{
+		"--live-from-start",
+		"--wait-for-video", liveWaitRetry,
+		"--write-info-json",
+		"--restrict-filenames",
+		"-o", "live_" + download.ID + "_%(id)s.%(ext)s",
+		"-P", r.incomingDir,
+	}
+	args = append(args, ytdlpClientArgs(r.impersonate, r.userAgent)...)
+	if download.Platform == domain.PlatformX && r.cookieFile != "" && FileExists(r.cookieFile) {
+		args = append(args, "--cookies", r.cookieFile)
+	}
+	return append(args, download.URL)
+}
+
+// findRecordingFiles returns the recorded media files of a download. After a
+// stop, a .part file that yt-dlp could not finalize is kept under its final
+// name: a partial recording is still worth having.
+func (r *LiveRecorder) findRecordingFiles(downloadID string, stopped bool) []string {
+	entries, err := os.ReadDir(r.incomingDir)
+	if err != nil {
+		return nil
+	}
+	prefix := "live_" + downloadID + "_"
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		path := filepath.Join(r.incomingDir, name)
+		if stopped && strings.HasSuffix(name, ".part") {
+			final := strings.TrimSuffix(path, ".part")
+			if IsMediaFile(final) && !FileExists(final) && os.Rename(path, final) == nil {
+				files = append(files, final)
+			}
+			continue
+		}
+		if IsMediaFile(path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// removeRecordingFiles deletes whatever a failed recording left in the
+// incoming directory (.part fragments, .info.json, ...)
+func (r *LiveRecorder) removeRecordingFiles(downloadID string) {
+	entries, err := os.ReadDir(r.incomingDir)
+	if err != nil {
+		return
+	}
+	prefix := "live_" + downloadID + "_"
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			_ = os.Remove(filepath.Join(r.incomingDir, entry.Name()))
+		}
+	}
+}
+
+// finishFiles moves the recording to the completed directory, applies the
+// filename template and layout, and writes its metadata sidecars
+func (r *LiveRecorder) finishFiles(download *domain.Download, files []string) ([]string, *domain.MediaMetadata, error) {
+	if err := os.MkdirAll(r.completedDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create completed directory: %w", err)
+	}
+	completedFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		dest, err := moveWithSidecars(file, filepath.Join(r.completedDir, filepath.Base(file)))
+		if err != nil {
+			return nil, nil, err
+		}
+		completedFiles = append(completedFiles, dest)
+	}
+
+	meta := r.buildMetadata(download, completedFiles)
+	var err error
+	if r.FilenameTemplate != "" || r.OrganizeBy != "" {
+		completedFiles, err = r.RenameToTemplate(completedFiles, meta)
+		if err != nil && r.eventLogger != nil {
+			r.eventLogger.LogAppError("Failed to apply filename template", zap.Error(err))
+		}
+		completedFiles, err = r.OrganizeFiles(completedFiles, r.completedDir, meta)
+		if err != nil && r.eventLogger != nil {
+			r.eventLogger.LogAppError("Failed to organize completed files", zap.Error(err))
+		}
+	}
+	meta.Files = completedFiles
+	meta.RelativePath = RelativeToCompleted(r.completedDir, completedFiles[0])
+
+	for _, file := range completedFiles {
+		fileMeta := *meta
+		fileMeta.RelativePath = RelativeToCompleted(r.completedDir, file)
+		if err := WriteInfoJSON(file, &fileMeta); err != nil && r.eventLogger != nil {
+			r.eventLogger.LogAppError("Failed to write info.json", zap.String("file", file), zap.Error(err))
+		}
+		if err := r.WriteCompanionFiles(file, meta); err != nil && r.eventLogger != nil {
+			r.eventLogger.LogAppError("Failed to write description file", zap.String("file", file), zap.Error(err))
+		}
+	}
+	return completedFiles, meta, nil
+}
+
+// buildMetadata builds the recording's metadata from yt-dlp's .info.json next
+// to the first file that has one, falling back to the URL
+func (r *LiveRecorder) buildMetadata(download *domain.Download, files []string) *domain.MediaMetadata {
+	info := map[string]interface{}{}
+	for _, file := range files {
+		data, err := os.ReadFile(strings.TrimSuffix(file, filepath.Ext(file)) + ".info.json")
+		if err == nil && json.Unmarshal(data, &info) == nil {
+			break
+		}
+	}
+
+	timestamp := GetInt64FromMap(info, "release_timestamp")
+	if timestamp == 0 {
+		timestamp = GetInt64FromMap(info, "timestamp")
+	}
+	if timestamp == 0 && download.StartedAt != nil {
+		timestamp = download.StartedAt.Unix()
+	}
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+
+	platform := string(download.Platform)
+	meta := &domain.MediaMetadata{
+		ID:           firstNonEmpty(GetStringFromMap(info, "id"), download.ID),
+		Title:        firstNonEmpty(GetStringFromMap(info, "title"), "Live broadcast "+download.ID),
+		Description:  GetStringFromMap(info, "description"),
+		Uploader:     GetStringFromMap(info, "uploader"),
+		UploaderID:   GetStringFromMap(info, "uploader_id"),
+		UploaderURL:  GetStringFromMap(info, "uploader_url"),
+		WebpageURL:   firstNonEmpty(GetStringFromMap(info, "webpage_url"), download.URL),
+		URL:          download.URL,
+		Timestamp:    timestamp,
+		UploadDate:   time.Unix(timestamp, 0).Format("20060102"),
+		Tags:         []string{platform, "live"},
+		Platform:     platform,
+		Extractor:    firstNonEmpty(GetStringFromMap(info, "extractor"), "live"),
+		ExtractorKey: firstNonEmpty(GetStringFromMap(info, "extractor_key"), "Live"),
+		Files:        files,
+	}
+	r.ApplyExtensions(meta)
+	return meta
+}
@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestLiveRecorder_Validate(t *testing.T) {
+	r := NewLiveRecorder("yt-dlp", "", t.TempDir(), t.TempDir(), t.TempDir(), nil)
+	assert.NoError(t, r.Validate("https://x.com/i/broadcasts/1YqKDqWqdPdJV"))
+	assert.NoError(t, r.Validate("https://t.me/channel?livestream"))
+	assert.Error(t, r.Validate("https://x.com/user/status/123"))
+}
+
+func TestLiveRecorder_BuildArgs(t *testing.T) {
+	incoming := t.TempDir()
+	cookies := filepath.Join(t.TempDir(), "cookies.txt")
+	require.NoError(t, os.WriteFile(cookies, []byte("# cookies"), 0644))
+	r := NewLiveRecorder("yt-dlp", cookies, incoming, t.TempDir(), t.TempDir(), nil)
+
+	x := domain.NewDownload("https://x.com/i/broadcasts/1YqKDqWqdPdJV", domain.PlatformX, domain.ModeDefault)
+	args := r.buildArgs(x)
+	assert.Contains(t, args, "--live-from-start")
+	assert.Contains(t, args, "--cookies")
+	assert.Equal(t, x.URL, args[len(args)-1])
+	assert.Contains(t, args, "live_"+x.ID+"_%(id)s.%(ext)s")
+
+	r.SetClientOptions("chrome", "")
+	args = r.buildArgs(x)
+	assert.Contains(t, args, "--impersonate")
+	assert.NotContains(t, args, "--user-agent")
+
+	tg := domain.NewDownload("https://t.me/channel?livestream", domain.PlatformTelegram, domain.ModeDefault)
+	assert.NotContains(t, r.buildArgs(tg), "--cookies", "X cookies must not be sent to Telegram")
+}
+
+func TestLiveRecorder_FindRecordingFiles_KeepsPartialAfterStop(t *testing.T) {
+	incoming := t.TempDir()
+	r := NewLiveRecorder("yt-dlp", "", incoming, t.TempDir(), t.TempDir(), nil)
+	part := filepath.Join(incoming, "live_abc_1YqK.mp4.part")
+	require.NoError(t, os.WriteFile(part, []byte("data"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(incoming, "live_other_1YqK.mp4"), []byte("data"), 0644))
+
+	assert.Empty(t, r.findRecordingFiles("abc", false), "unfinished fragments are not a recording")
+
+	files := r.findRecordingFiles("abc", true)
+	require.Len(t, files, 1)
+	assert.Equal(t, filepath.Join(incoming, "live_abc_1YqK.mp4"), files[0])
+	assert.False(t, FileExists(part))
+}
+
+func TestLiveRecorder_FinishFiles(t *testing.T) {
+	incoming := t.TempDir()
+	completed := t.TempDir()
+	r := NewLiveRecorder("yt-dlp", "", incoming, completed, t.TempDir(), nil)
+	download := domain.NewDownload("https://x.com/i/broadcasts/1YqKDqWqdPdJV", domain.PlatformX, domain.ModeDefault)
+
+	file := filepath.Join(incoming, "live_"+download.ID+"_1YqK.mp4")
+	require.NoError(t, os.WriteFile(file, []byte("video"), 0644))
+	info := `{"id":"1YqK","title":"Launch stream","uploader_id":"spacex","release_timestamp":1699963200}`
+	require.NoError(t, os.WriteFile(filepath.Join(incoming, "live_"+download.ID+"_1YqK.info.json"), []byte(info), 0644))
+
+	files, meta, err := r.finishFiles(download, r.findRecordingFiles(download.ID, false))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, completed, filepath.Dir(files[0]))
+	assert.Equal(t, "1YqK", meta.ID)
+	assert.Equal(t, "Launch stream", meta.Title)
+	assert.Equal(t, "spacex", meta.UploaderID)
+	assert.Equal(t, "20231114", meta.UploadDate)
+	assert.Contains(t, meta.Tags, "live")
+	assert.True(t, FileExists(files[0]))
+}
@@ -3,6 +3,10 @@ package infrastructure
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/yourusername/x-extract-go/internal/domain"
 	"go.uber.org/zap"
@@ -12,6 +16,7 @@ import (
 type NotificationService struct {
 	config *domain.NotificationConfig
 	logger *zap.Logger
+	mu     sync.RWMutex // Guards config.Enabled and config.Sound, which change at runtime
 }
 
 // NewNotificationService creates a new notification service
@@ -22,9 +27,20 @@ func NewNotificationService(config *domain.NotificationConfig, logger *zap.Logge
 	}
 }
 
+// SetEnabled turns notifications and their sound on or off
+func (n *NotificationService) SetEnabled(enabled, sound bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.config.Enabled = enabled
+	n.config.Sound = sound
+}
+
 // Send sends a notification
 func (n *NotificationService) Send(title, message string) error {
-	if !n.config.Enabled {
+	n.mu.RLock()
+	enabled := n.config.Enabled
+	n.mu.RUnlock()
+	if !enabled {
 		n.logger.Debug("Notifications disabled, skipping",
 			zap.String("title", title),
 			zap.String("message", message))
@@ -107,6 +123,18 @@ func (n *NotificationService) NotifyDownloadFailed(url string, platform domain.P
 	n.Send(title, message)
 }
 
+// NotifyDownloadProgress sends the percent complete and ETA of a download
+// that has been running for a while
+func (n *NotificationService) NotifyDownloadProgress(url string, platform domain.Platform, percent float64, eta string, elapsed time.Duration) {
+	title := "Download In Progress"
+	status := fmt.Sprintf("%.0f%% after %s", percent, strings.TrimSuffix(elapsed.Round(time.Minute).String(), "0s"))
+	if eta != "" {
+		status += ", ETA " + eta
+	}
+	message := fmt.Sprintf("%s: %s (%s)", status, truncateString(url, 30), platform)
+	n.Send(title, message)
+}
+
 // NotifyQueueEmpty sends notification when queue is empty
 func (n *NotificationService) NotifyQueueEmpty() {
 	title := "Queue Empty"
@@ -114,6 +142,29 @@ func (n *NotificationService) NotifyQueueEmpty() {
 	n.Send(title, message)
 }
 
+// NotifyCookieProblem sends notification when a platform's cookies are
+// expiring, expired or rejected
+func (n *NotificationService) NotifyCookieProblem(platform domain.Platform, status domain.CookieStatus) {
+	title := fmt.Sprintf("%s Cookies Need Attention", platform)
+	message := fmt.Sprintf("Cookies %s: %s", status.State, truncateString(status.Message, 80))
+	n.Send(title, message)
+}
+
+// NotifyDiskSpaceLow sends notification when downloads are held for disk space
+func (n *NotificationService) NotifyDiskSpaceLow(err error) {
+	title := "Downloads Held"
+	message := truncateString(err.Error(), 120)
+	n.Send(title, message)
+}
+
+// NotifyArchiveReport sends the summary of an archive report as a digest
+// notification
+func (n *NotificationService) NotifyArchiveReport(report *domain.ArchiveReport, path string) {
+	title := report.Title()
+	message := fmt.Sprintf("%s. Saved to %s", report.Summary(), filepath.Base(path))
+	n.Send(title, message)
+}
+
 // truncateString truncates a string to the specified length
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// AlreadyRunningError is returned by AcquirePIDFile when another server
+// holds the PID file
+type AlreadyRunningError struct {
+	PID  int // 0 when the holder's PID could not be read
+	Path string
+}
+
+func (e *AlreadyRunningError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("x-extract-server is already running (PID %d, PID file %s)", e.PID, e.Path)
+	}
+	return fmt.Sprintf("x-extract-server is already running (PID file %s)", e.Path)
+}
+
+// PIDFile is a PID file held by this process. It keeps a second server from
+// running against the same base_dir: the file is locked while held.
+type PIDFile struct {
+	path string
+	file *os.File
+}
+
+// AcquirePIDFile takes the PID file at path and writes this process's PID to
+// it. It returns an *AlreadyRunningError when another process holds it. A PID
+// file left behind by a server that was killed does not hold.
+func AcquirePIDFile(path string) (*PIDFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create PID file directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PID file: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		pid := readPID(file)
+		file.Close()
+		return nil, &AlreadyRunningError{PID: pid, Path: path}
+	}
+
+	if err := file.Truncate(0); err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return &PIDFile{path: path, file: file}, nil
+}
+
+// Release empties and unlocks the PID file. The file is not removed: a
+// process that opened it just before would go on to lock a file that no
+// longer has the path, next to a new one.
+func (p *PIDFile) Release() error {
+	err := p.file.Truncate(0)
+	unlockFile(p.file)
+	if closeErr := p.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// RunningPID returns the PID of the server holding the PID file at path, or 0
+// when no server holds it
+func RunningPID(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	if err := lockFile(file); err == nil {
+		unlockFile(file)
+		return 0
+	}
+	return readPID(file)
+}
+
+// readPID reads the PID written to a PID file, 0 if there is none
+func readPID(file *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquirePIDFile_SingleHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "server.pid")
+	assert.Zero(t, RunningPID(path))
+
+	pidFile, err := AcquirePIDFile(path)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+	assert.Equal(t, os.Getpid(), RunningPID(path))
+
+	_, err = AcquirePIDFile(path)
+	var running *AlreadyRunningError
+	require.True(t, errors.As(err, &running))
+	assert.Equal(t, os.Getpid(), running.PID)
+	assert.Contains(t, err.Error(), "already running")
+
+	require.NoError(t, pidFile.Release())
+	assert.Zero(t, RunningPID(path))
+
+	pidFile, err = AcquirePIDFile(path)
+	require.NoError(t, err)
+	require.NoError(t, pidFile.Release())
+}
+
+func TestAcquirePIDFile_StaleFile(t *testing.T) {
+	// A server that was killed leaves its PID behind, unlocked
+	path := filepath.Join(t.TempDir(), "server.pid")
+	require.NoError(t, os.WriteFile(path, []byte("999999\n"), 0644))
+	assert.Zero(t, RunningPID(path))
+
+	pidFile, err := AcquirePIDFile(path)
+	require.NoError(t, err)
+	require.NoError(t, pidFile.Release())
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package infrastructure
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on file without waiting. The lock goes
+// with the process, so a killed server leaves none.
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// unlockFile releases the lock of lockFile
+func unlockFile(file *os.File) {
+	_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package infrastructure
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is where the byte locked by lockFile lies. Windows locks are
+// mandatory, so it is put far past the PID for readPID to still read it.
+const lockOffset = 1 << 30
+
+// lockFile takes an exclusive lock on file without waiting. Windows drops
+// the lock when the process exits, so a killed server leaves none, and a
+// PID reused by another process can't hold the file.
+func lockFile(file *os.File) error {
+	overlapped := windows.Overlapped{Offset: lockOffset}
+	return windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+}
+
+// unlockFile releases the lock of lockFile
+func unlockFile(file *os.File) {
+	overlapped := windows.Overlapped{Offset: lockOffset}
+	_ = windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &overlapped)
+}
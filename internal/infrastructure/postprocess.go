@@ -0,0 +1,297 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// errStepSkipped is returned by a post-processing step for a file it does not apply to
+var errStepSkipped = errors.New("step does not apply")
+
+// remuxExtensions are the video containers remux_mp4 repackages as MP4
+var remuxExtensions = map[string]bool{".mkv": true, ".webm": true, ".mov": true}
+
+// PostProcessor runs the configured post-processing steps (postprocess.steps)
+// on the files of completed downloads. remux_mp4, h264 and contact_sheet need
+// ffmpeg (and ffprobe); strip_exif runs in-process.
+type PostProcessor struct {
+	config *domain.PostProcessConfig
+}
+
+// NewPostProcessor creates a post-processor. Returns nil when no platform has
+// any steps.
+func NewPostProcessor(config *domain.PostProcessConfig) *PostProcessor {
+	hasSteps := len(config.Steps) > 0
+	for _, steps := range config.PlatformSteps {
+		hasSteps = hasSteps || len(steps) > 0
+	}
+	if !hasSteps {
+		return nil
+	}
+	return &PostProcessor{config: config}
+}
+
+// Process runs the steps of platform on files, step by step in the configured
+// order. A step that fails leaves its file as it was and the next steps carry
+// on. It returns the files that were replaced by a file of another name (old
+// path -> new path) and a result per step applied to a file.
+func (p *PostProcessor) Process(ctx context.Context, platform domain.Platform, files []string) (map[string]string, []domain.PostProcessResult) {
+	renamed := make(map[string]string)
+	current := append([]string(nil), files...)
+	var results []domain.PostProcessResult
+	for _, step := range p.config.StepsFor(platform) {
+		for i, file := range current {
+			output, err := p.runStep(ctx, step, file)
+			if errors.Is(err, errStepSkipped) {
+				continue
+			}
+			result := domain.PostProcessResult{Step: step, File: file}
+			if err != nil {
+				result.Error = err.Error()
+			} else if output != file {
+				result.Output = output
+			}
+			results = append(results, result)
+
+			// Every step but contact_sheet replaces the file it ran on
+			if err == nil && output != file && step != domain.PostProcessContactSheet {
+				renamed[files[i]] = output
+				current[i] = output
+			}
+		}
+	}
+	return renamed, results
+}
+
+// runStep applies one step to a file and returns the file it produced:
+// the file itself when changed in place, a replacement, or a new file next
+// to it. errStepSkipped means the step does not apply to the file.
+func (p *PostProcessor) runStep(ctx context.Context, step, file string) (string, error) {
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+	isVideo := domain.MediaTypeOf(file) == domain.MediaTypeVideo
+	switch step {
+	case domain.PostProcessRemuxMP4:
+		if !remuxExtensions[strings.ToLower(filepath.Ext(file))] {
+			return "", errStepSkipped
+		}
+		return p.transcodeToMP4(ctx, file, "-c", "copy")
+	case domain.PostProcessH264:
+		if !isVideo {
+			return "", errStepSkipped
+		}
+		codec, err := p.probe(ctx, file, "-select_streams", "v:0", "-show_entries", "stream=codec_name")
+		if err != nil {
+			return "", err
+		}
+		if codec != "hevc" {
+			return "", errStepSkipped
+		}
+		return p.transcodeToMP4(ctx, file, "-c:v", "libx264", "-crf", "20", "-preset", "medium", "-pix_fmt", "yuv420p", "-c:a", "copy")
+	case domain.PostProcessContactSheet:
+		if !isVideo {
+			return "", errStepSkipped
+		}
+		return p.contactSheet(ctx, file)
+	case domain.PostProcessStripEXIF:
+		return stripImageMetadata(file)
+	}
+	return "", errStepSkipped
+}
+
+// transcodeToMP4 runs ffmpeg with codecArgs to write file as MP4, then
+// replaces file with it, keeping the name apart from the extension. Its
+// .info.json and .description.txt follow if the name has to change.
+func (p *PostProcessor) transcodeToMP4(ctx context.Context, file string, codecArgs ...string) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*.mp4")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	args := append([]string{"-y", "-v", "error", "-i", file}, codecArgs...)
+	args = append(args, "-movflags", "+faststart", tmp.Name())
+	if err := p.ffmpeg(ctx, args...); err != nil {
+		return "", err
+	}
+
+	dest := uniqueFilePath(strings.TrimSuffix(file, filepath.Ext(file))+".mp4", file)
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", fmt.Errorf("failed to replace %s: %w", filepath.Base(file), err)
+	}
+	if dest == file {
+		return file, nil
+	}
+	os.Remove(file)
+	srcStem := strings.TrimSuffix(file, filepath.Ext(file))
+	destStem := strings.TrimSuffix(dest, filepath.Ext(dest))
+	if srcStem != destStem {
+		for _, suffix := range renameSidecarSuffixes {
+			if FileExists(srcStem + suffix) {
+				_ = os.Rename(srcStem+suffix, destStem+suffix)
+			}
+		}
+	}
+	return dest, nil
+}
+
+// contactSheetSuffix names the contact sheet of a video: <name>.contact.jpg
+const contactSheetSuffix = ".contact.jpg"
+
+// contactSheet writes a 4x4 grid of frames spread over the video
+func (p *PostProcessor) contactSheet(ctx context.Context, file string) (string, error) {
+	out, err := p.probe(ctx, file, "-show_entries", "format=duration")
+	if err != nil {
+		return "", err
+	}
+	duration, err := strconv.ParseFloat(out, 64)
+	if err != nil || duration <= 0 {
+		return "", fmt.Errorf("unknown duration of %s", filepath.Base(file))
+	}
+
+	sheet := strings.TrimSuffix(file, filepath.Ext(file)) + contactSheetSuffix
+	filter := fmt.Sprintf("fps=%f,scale=320:-2,tile=4x4", 16/duration)
+	if err := p.ffmpeg(ctx, "-y", "-v", "error", "-i", file, "-vf", filter, "-frames:v", "1", sheet); err != nil {
+		return "", err
+	}
+	return sheet, nil
+}
+
+// ffmpeg runs the configured ffmpeg
+func (p *PostProcessor) ffmpeg(ctx context.Context, args ...string) error {
+	if _, err := exec.LookPath(p.config.FFmpegBinary); err != nil {
+		return fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	cmd := CommandWithCancel(ctx, p.config.FFmpegBinary, args...)
+	started := time.Now()
+	output, err := cmd.CombinedOutput()
+	recordProcessUsage(ctx, cmd, started)
+	if err != nil {
+		return &domain.ToolError{Tool: "ffmpeg", Err: fmt.Errorf("%s", lastOutputLine(string(output), err))}
+	}
+	return nil
+}
+
+// probe runs ffprobe with entryArgs on file and returns the value it prints
+func (p *PostProcessor) probe(ctx context.Context, file string, entryArgs ...string) (string, error) {
+	if _, err := exec.LookPath(p.config.FFprobeBinary); err != nil {
+		return "", fmt.Errorf("ffprobe not found: %w", err)
+	}
+	args := append([]string{"-v", "error"}, entryArgs...)
+	args = append(args, "-of", "default=noprint_wrappers=1:nokey=1", file)
+	output, err := CommandWithCancel(ctx, p.config.FFprobeBinary, args...).Output()
+	if err != nil {
+		return "", &domain.ToolError{Tool: "ffprobe", Err: err}
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// stripImageMetadata removes EXIF (with GPS), XMP, IPTC and comments from a
+// JPEG, and EXIF, text and time chunks from a PNG, without re-encoding. The
+// file is rewritten only when there was something to remove.
+func stripImageMetadata(file string) (string, error) {
+	var strip func([]byte) ([]byte, error)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".jpg", ".jpeg":
+		strip = stripJPEGMetadata
+	case ".png":
+		strip = stripPNGMetadata
+	default:
+		return "", errStepSkipped
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	stripped, err := strip(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	if len(stripped) == len(data) {
+		return "", errStepSkipped
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteFileAtomic(file, stripped, info.Mode().Perm()); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// stripJPEGMetadata drops the APP1 (EXIF, XMP), APP13 (IPTC) and COM segments
+// before the image data. The orientation stored in EXIF is lost with it.
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errors.New("not a JPEG file")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, errors.New("corrupt JPEG segment")
+		}
+		marker := data[pos+1]
+		if marker == 0xDA { // Start of scan: the image data follows
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errors.New("corrupt JPEG segment")
+		}
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), nil
+}
+
+// pngMetadataChunks are the PNG chunks stripImageMetadata removes
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// stripPNGMetadata drops the metadata chunks of a PNG
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, errors.New("not a PNG file")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.WriteString(signature)
+	pos := len(signature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errors.New("corrupt PNG chunk")
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errors.New("corrupt PNG chunk")
+		}
+		if !pngMetadataChunks[string(data[pos+4:pos+8])] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+	return out.Bytes(), nil
+}
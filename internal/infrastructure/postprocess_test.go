@@ -0,0 +1,124 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// jpegWithEXIF encodes a small JPEG with an EXIF APP1 segment and a comment
+// inserted after SOI
+func jpegWithEXIF(t *testing.T) (withEXIF, plain []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil))
+	plain = buf.Bytes()
+
+	exif := append([]byte("Exif\x00\x00"), []byte("GPS 52.52N 13.40E")...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(exif)+2))
+	comment := []byte{0xFF, 0xFE, 0, 7, 'h', 'e', 'l', 'l', 'o'}
+
+	withEXIF = append([]byte{}, plain[:2]...)
+	withEXIF = append(withEXIF, app1...)
+	withEXIF = append(withEXIF, exif...)
+	withEXIF = append(withEXIF, comment...)
+	withEXIF = append(withEXIF, plain[2:]...)
+	return withEXIF, plain
+}
+
+// pngChunk encodes one PNG chunk
+func pngChunk(typ string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], typ)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	withEXIF, plain := jpegWithEXIF(t)
+
+	stripped, err := stripJPEGMetadata(withEXIF)
+	require.NoError(t, err)
+	assert.Equal(t, plain, stripped)
+	_, err = jpeg.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+
+	_, err = stripJPEGMetadata([]byte("not a jpeg"))
+	assert.Error(t, err)
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))))
+	plain := buf.Bytes()
+
+	// Insert a text chunk after IHDR (8 byte signature + 25 byte chunk)
+	withText := append([]byte{}, plain[:33]...)
+	withText = append(withText, pngChunk("tEXt", []byte("Comment\x00secret"))...)
+	withText = append(withText, plain[33:]...)
+	_, err := png.Decode(bytes.NewReader(withText))
+	require.NoError(t, err)
+
+	stripped, err := stripPNGMetadata(withText)
+	require.NoError(t, err)
+	assert.Equal(t, plain, stripped)
+}
+
+func TestPostProcessor_Process(t *testing.T) {
+	dir := t.TempDir()
+	withEXIF, plain := jpegWithEXIF(t)
+	photo := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(photo, withEXIF, 0644))
+	video := filepath.Join(dir, "clip.mkv")
+	require.NoError(t, os.WriteFile(video, []byte("video"), 0644))
+
+	processor := NewPostProcessor(&domain.PostProcessConfig{
+		PlatformSteps: map[string][]string{
+			"telegram": {domain.PostProcessRemuxMP4, domain.PostProcessStripEXIF},
+		},
+		FFmpegBinary: filepath.Join(dir, "no-ffmpeg"),
+	})
+	require.NotNil(t, processor)
+
+	// Platforms without steps are left alone
+	renamed, results := processor.Process(context.Background(), domain.PlatformX, []string{photo, video})
+	assert.Empty(t, renamed)
+	assert.Empty(t, results)
+
+	renamed, results = processor.Process(context.Background(), domain.PlatformTelegram, []string{photo, video})
+	assert.Empty(t, renamed)
+	require.Len(t, results, 2)
+	assert.Equal(t, domain.PostProcessRemuxMP4, results[0].Step)
+	assert.Equal(t, video, results[0].File)
+	assert.Contains(t, results[0].Error, "ffmpeg not found")
+	assert.Equal(t, domain.PostProcessResult{Step: domain.PostProcessStripEXIF, File: photo}, results[1])
+
+	data, err := os.ReadFile(photo)
+	require.NoError(t, err)
+	assert.Equal(t, plain, data)
+	data, err = os.ReadFile(video)
+	require.NoError(t, err)
+	assert.Equal(t, "video", string(data), "a failed step leaves the file as it was")
+
+	// Nothing left to strip
+	_, results = processor.Process(context.Background(), domain.PlatformTelegram, []string{photo})
+	assert.Empty(t, results)
+}
+
+func TestNewPostProcessor_NoSteps(t *testing.T) {
+	assert.Nil(t, NewPostProcessor(&domain.PostProcessConfig{}))
+	assert.Nil(t, NewPostProcessor(&domain.PostProcessConfig{PlatformSteps: map[string][]string{"x": {}}}))
+}
@@ -0,0 +1,35 @@
+package infrastructure
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// SubprocessKillGrace is how long a cancelled subprocess is given to exit after
+// SIGTERM before it is forcibly killed with SIGKILL.
+const SubprocessKillGrace = 5 * time.Second
+
+// LiveStopGrace is how long a stopped live recording is given to finalize its
+// file after SIGINT before it is killed.
+const LiveStopGrace = 2 * time.Minute
+
+// CommandWithCancel builds an exec.Cmd for an external download tool (yt-dlp,
+// tdl, gallery-dl) that is stopped when ctx is cancelled.
+//
+// Unlike plain exec.CommandContext (which sends SIGKILL to the tool only), the
+// tool runs in its own process group and cancellation first sends SIGTERM to
+// the whole group so helpers like ffmpeg exit too and partial files get
+// flushed. If the group is still alive after SubprocessKillGrace it is killed.
+// Windows has no process group signals; see process_windows.go.
+func CommandWithCancel(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return commandWithStopSignal(ctx, syscall.SIGTERM, SubprocessKillGrace, name, args...)
+}
+
+// CommandWithInterrupt is CommandWithCancel for live recordings: cancellation
+// sends SIGINT, which yt-dlp treats like Ctrl+C — it stops recording and
+// finalizes what it has — and the group gets LiveStopGrace to finish.
+func CommandWithInterrupt(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return commandWithStopSignal(ctx, syscall.SIGINT, LiveStopGrace, name, args...)
+}
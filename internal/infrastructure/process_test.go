@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandWithCancel_TerminatesProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// The child sleep runs in the same process group as sh and must be
+	// terminated too, otherwise Run would block until it exits.
+	cmd := CommandWithCancel(ctx, "sh", "-c", "sleep 30 & wait")
+	require.NoError(t, cmd.Start())
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := cmd.Wait()
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), SubprocessKillGrace, "process group should exit on SIGTERM")
+}
+
+func TestCommandWithInterrupt_SendsSIGINT(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// The script exits cleanly on SIGINT, like yt-dlp finalizing a recording
+	cmd := CommandWithInterrupt(ctx, "sh", "-c", "trap 'exit 0' INT; while true; do sleep 0.05; done")
+	require.NoError(t, cmd.Start())
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_ = cmd.Wait() // reports ctx.Err() even on a clean exit
+	assert.True(t, cmd.ProcessState.Success(), "process should exit cleanly on SIGINT")
+	assert.Less(t, time.Since(start), LiveStopGrace)
+}
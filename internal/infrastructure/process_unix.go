@@ -0,0 +1,50 @@
+//go:build !windows
+
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// commandWithStopSignal builds a process-group command that receives sig when
+// ctx is cancelled and SIGKILL once grace has passed
+func commandWithStopSignal(ctx context.Context, sig syscall.Signal, grace time.Duration, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		if !leaderUnreaped(cmd.Process) {
+			return os.ErrProcessDone
+		}
+		if err := syscall.Kill(-pgid, sig); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				return os.ErrProcessDone
+			}
+			return err
+		}
+		time.AfterFunc(grace, func() {
+			// Once Wait has reaped the leader, the group is gone and its
+			// ID may belong to another process group
+			if leaderUnreaped(cmd.Process) {
+				_ = syscall.Kill(-pgid, syscall.SIGKILL)
+			}
+		})
+		return nil
+	}
+	// Safety net: if the leader ignores the signal, Wait kills it after the grace period.
+	cmd.WaitDelay = grace
+	return cmd
+}
+
+// leaderUnreaped reports whether Wait has not yet reaped the group leader.
+// Until it is reaped its PID, and so the process group ID, can't be reused.
+// os.Process marks itself done before reaping, so a signal sent through it
+// never reaches a process that reused the PID.
+func leaderUnreaped(process *os.Process) bool {
+	return !errors.Is(process.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package infrastructure
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderUnreaped_FalseOnceWaited(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	assert.True(t, leaderUnreaped(cmd.Process))
+
+	require.NoError(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+	// The SIGKILL timer must not signal a process group that reused the ID
+	assert.False(t, leaderUnreaped(cmd.Process))
+}
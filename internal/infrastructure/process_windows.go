@@ -0,0 +1,63 @@
+//go:build windows
+
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+var procGenerateConsoleCtrlEvent = syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+
+// ctrlBreakEvent is CTRL_BREAK_EVENT, the console event a process group can
+// be sent
+const ctrlBreakEvent = 1
+
+// commandWithStopSignal builds a command started in its own process group.
+// Windows has no signals to send it: cancelling ctx sends the group a
+// Ctrl+Break instead, which ffmpeg takes to finalize its output, and kills
+// the tool's process tree once grace has passed. A server without a console
+// (started as a daemon) cannot send Ctrl+Break, so the tree is killed at once.
+func commandWithStopSignal(ctx context.Context, sig syscall.Signal, grace time.Duration, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+	cmd.Cancel = func() error {
+		pid := cmd.Process.Pid
+		// Holding a handle keeps Windows from giving the PID to another
+		// process, so taskkill can't stop an unrelated tree after the tool
+		// has exited. It is only ours while Wait hasn't released the
+		// handle os.Process holds, which is checked after opening it.
+		handle, err := syscall.OpenProcess(syscall.SYNCHRONIZE, false, uint32(pid))
+		if err != nil {
+			return os.ErrProcessDone
+		}
+		if errors.Is(cmd.Process.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+			syscall.CloseHandle(handle)
+			return os.ErrProcessDone
+		}
+		killTree := func() error {
+			defer syscall.CloseHandle(handle)
+			if event, _ := syscall.WaitForSingleObject(handle, 0); event == syscall.WAIT_OBJECT_0 {
+				return nil // the tool has exited
+			}
+			// taskkill /T also stops the children (ffmpeg) the tool started
+			return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run()
+		}
+		if ok, _, _ := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(pid)); ok == 0 {
+			if err := killTree(); err != nil {
+				return cmd.Process.Kill()
+			}
+			return nil
+		}
+		time.AfterFunc(grace, func() { _ = killTree() })
+		return nil
+	}
+	// Safety net: if the tool ignores Ctrl+Break, Wait kills it after the grace period.
+	cmd.WaitDelay = grace
+	return cmd
+}
@@ -0,0 +1,193 @@
+package infrastructure
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+	"github.com/yourusername/x-extract-go/pkg/logger"
+)
+
+var (
+	// yt-dlp: "[download]  45.3% of ~12.34MiB at 1.23MiB/s ETA 00:05"
+	// tdl:    "... 31.7% [196.00 MB in 39s] 5.00 MB/s ~ETA: 1m27s"
+	outputPercentRe = regexp.MustCompile(`(\d+(?:\.\d+)?)%`)
+	outputSpeedRe   = regexp.MustCompile(`(\d+(?:\.\d+)?\s*[KMGT]?i?B/s)`)
+	outputETARe     = regexp.MustCompile(`~?ETA:?\s+([\w:]+)`)
+	// yt-dlp: "[download] Destination: /path/to/file.mp4"
+	outputDestinationRe = regexp.MustCompile(`^\[download\] Destination:\s*(.+)$`)
+	// yt-dlp playlists: "[download] Downloading item 3 of 120"
+	outputItemRe = regexp.MustCompile(`^\[download\] Downloading (?:item|video) \d+ of (\d+)`)
+	// tdl draws one tracker per file, named "<chat>(<id>):<message> -> <path>":
+	// "Chan(123):45 -> /tmp/123_45_a.mp4 ... 31.7% [###] [196.00 MB in 39s] 5.00 MB/s ~ETA: 1m27s"
+	// "Chan(123):45 -> /tmp/123_45_a.mp4 ... done! [196.00 MB in 40s] 4.90 MB/s"
+	tdlTrackerRe = regexp.MustCompile(`^.+? -> (.+?) \.\.\. (.*)$`)
+)
+
+// progressWriter is an io.Writer that parses external tool output line by
+// line and reports progress through a callback. Carriage returns (used by
+// progress bars to redraw in place) are treated as line breaks.
+type progressWriter struct {
+	callback domain.DownloadProgressCallback
+	parse    func(line string, state *domain.DownloadProgress) bool
+	mu       sync.Mutex
+	partial  []byte
+	state    domain.DownloadProgress
+}
+
+// newProgressWriter creates a progressWriter reporting to callback
+func newProgressWriter(callback domain.DownloadProgressCallback) *progressWriter {
+	return &progressWriter{callback: callback, parse: parseProgressOutput}
+}
+
+// newTDLProgressWriter creates a progressWriter for tdl output, which reports
+// the combined progress of the files tdl downloads in parallel
+func newTDLProgressWriter(callback domain.DownloadProgressCallback) *progressWriter {
+	return &progressWriter{callback: callback, parse: newTDLProgress().parse}
+}
+
+// Write implements io.Writer
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		idx := strings.IndexAny(string(w.partial), "\r\n")
+		if idx < 0 {
+			break
+		}
+		line := string(w.partial[:idx])
+		w.partial = w.partial[idx+1:]
+		if w.parse(strings.TrimSpace(logger.StripANSI(line)), &w.state) {
+			w.callback(w.state)
+		}
+	}
+	return len(p), nil
+}
+
+// parseProgressOutput updates state from a single line of tool output and
+// reports whether anything changed. Percent, speed and ETA come from progress
+// lines; the current file from yt-dlp "Destination:" lines or from the
+// absolute media paths gallery-dl prints after each file; the item count from
+// yt-dlp playlist lines.
+func parseProgressOutput(line string, state *domain.DownloadProgress) bool {
+	if line == "" {
+		return false
+	}
+
+	if m := outputDestinationRe.FindStringSubmatch(line); m != nil {
+		state.CurrentFile = filepath.Base(m[1])
+		state.Percent = 0
+		state.Speed = ""
+		state.ETA = ""
+		return true
+	}
+	if m := outputItemRe.FindStringSubmatch(line); m != nil {
+		count, err := strconv.Atoi(m[1])
+		if err != nil || count == state.ItemCount {
+			return false
+		}
+		state.ItemCount = count
+		return true
+	}
+	if filepath.IsAbs(line) && IsMediaFile(line) {
+		state.CurrentFile = filepath.Base(line)
+		return true
+	}
+
+	m := outputPercentRe.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	percent, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || percent > 100 {
+		return false
+	}
+	state.Percent = percent
+	if m := outputSpeedRe.FindStringSubmatch(line); m != nil {
+		state.Speed = strings.ReplaceAll(m[1], " ", "")
+	}
+	if m := outputETARe.FindStringSubmatch(line); m != nil {
+		state.ETA = m[1]
+	}
+	return true
+}
+
+// tdlFileProgress is the last reported state of one tdl tracker
+type tdlFileProgress struct {
+	percent float64
+	speed   int64 // Bytes per second, 0 once done
+	eta     time.Duration
+}
+
+// tdlProgress combines the trackers tdl draws for the files it downloads in
+// parallel into one progress: the mean percent of the files seen so far, the
+// sum of their speeds and the longest ETA
+type tdlProgress struct {
+	files map[string]*tdlFileProgress
+}
+
+func newTDLProgress() *tdlProgress {
+	return &tdlProgress{files: make(map[string]*tdlFileProgress)}
+}
+
+// parse updates state from a line of tdl output and reports whether anything
+// changed. Lines other than tracker lines are parsed like any tool output.
+func (p *tdlProgress) parse(line string, state *domain.DownloadProgress) bool {
+	m := tdlTrackerRe.FindStringSubmatch(line)
+	if m == nil {
+		return parseProgressOutput(line, state)
+	}
+	name, status := filepath.Base(m[1]), m[2]
+
+	file := p.files[name]
+	if file == nil {
+		file = &tdlFileProgress{}
+	}
+	if strings.HasPrefix(status, "done!") {
+		*file = tdlFileProgress{percent: 100}
+	} else {
+		pm := outputPercentRe.FindStringSubmatch(status)
+		if pm == nil {
+			return false
+		}
+		percent, err := strconv.ParseFloat(pm[1], 64)
+		if err != nil || percent > 100 {
+			return false
+		}
+		file.percent = percent
+		file.speed = 0
+		if sm := outputSpeedRe.FindStringSubmatch(status); sm != nil {
+			file.speed, _ = domain.ParseByteSize(strings.TrimSuffix(strings.ReplaceAll(sm[1], " ", ""), "/s"))
+		}
+		file.eta = 0
+		if em := outputETARe.FindStringSubmatch(status); em != nil {
+			file.eta, _ = time.ParseDuration(em[1])
+		}
+	}
+	p.files[name] = file
+
+	var percent float64
+	var speed int64
+	var eta time.Duration
+	for _, f := range p.files {
+		percent += f.percent
+		speed += f.speed
+		eta = max(eta, f.eta)
+	}
+	state.CurrentFile = name
+	state.Percent = percent / float64(len(p.files))
+	state.Speed, state.ETA = "", ""
+	if speed > 0 {
+		state.Speed = strings.ReplaceAll(domain.FormatBytes(speed), " ", "") + "/s"
+	}
+	if eta > 0 {
+		state.ETA = eta.String()
+	}
+	return true
+}
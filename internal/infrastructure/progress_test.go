@@ -0,0 +1,82 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestParseProgressOutput(t *testing.T) {
+	var state domain.DownloadProgress
+
+	// yt-dlp
+	assert.True(t, parseProgressOutput("[download] Destination: /incoming/user_123.mp4", &state))
+	assert.Equal(t, "user_123.mp4", state.CurrentFile)
+	assert.True(t, parseProgressOutput("[download]  45.3% of ~  12.34MiB at    1.23MiB/s ETA 00:05 (frag 3/10)", &state))
+	assert.Equal(t, domain.DownloadProgress{Percent: 45.3, Speed: "1.23MiB/s", ETA: "00:05", CurrentFile: "user_123.mp4"}, state)
+
+	// tdl
+	state = domain.DownloadProgress{}
+	assert.True(t, parseProgressOutput("channel-1-2.mp4 ... 31.7% [196.00 MB in 39s] 5.00 MB/s ~ETA: 1m27s", &state))
+	assert.Equal(t, 31.7, state.Percent)
+	assert.Equal(t, "5.00MB/s", state.Speed)
+	assert.Equal(t, "1m27s", state.ETA)
+
+	// gallery-dl prints the path of each downloaded file
+	assert.True(t, parseProgressOutput("/incoming/gallery/abc/photo_1.jpg", &state))
+	assert.Equal(t, "photo_1.jpg", state.CurrentFile)
+
+	// yt-dlp playlist position: only the item count is kept
+	state = domain.DownloadProgress{}
+	assert.True(t, parseProgressOutput("[download] Downloading item 3 of 120", &state))
+	assert.Equal(t, 120, state.ItemCount)
+	assert.False(t, parseProgressOutput("[download] Downloading item 4 of 120", &state))
+
+	// Unrelated output
+	assert.False(t, parseProgressOutput("[info] Writing video metadata as JSON", &state))
+	assert.False(t, parseProgressOutput("", &state))
+}
+
+func TestProgressWriter_SplitsCarriageReturns(t *testing.T) {
+	var updates []domain.DownloadProgress
+	w := newProgressWriter(func(progress domain.DownloadProgress) {
+		updates = append(updates, progress)
+	})
+
+	// Progress bars redraw with \r; chunks may split lines arbitrarily
+	w.Write([]byte("[download]  10.0% of 1.00MiB at 1.00MiB/s ETA 00:01\r[download]  5"))
+	w.Write([]byte("0.0% of 1.00MiB at 2.00MiB/s ETA 00:00\r\x1b[K[download] 100.0% of 1.00MiB\n"))
+
+	if assert.Len(t, updates, 3) {
+		assert.Equal(t, 10.0, updates[0].Percent)
+		assert.Equal(t, 50.0, updates[1].Percent)
+		assert.Equal(t, "2.00MiB/s", updates[1].Speed)
+		assert.Equal(t, 100.0, updates[2].Percent)
+	}
+}
+
+func TestTDLProgressWriter_CombinesParallelFiles(t *testing.T) {
+	var updates []domain.DownloadProgress
+	w := newTDLProgressWriter(func(progress domain.DownloadProgress) {
+		updates = append(updates, progress)
+	})
+
+	// tdl redraws its trackers with cursor movement; each file has its own line
+	w.Write([]byte("Chan(123):45 -> /tmp/dl/123_45_a.mp4 ... 40.0% [####] [40.00 MB in 8s] 5.00 MB/s ~ETA: 12s\n"))
+	w.Write([]byte("\x1b[1AChan(123):46 -> /tmp/dl/123_46_b.mp4 ... 20.0% [##] [10.00 MB in 8s] 1.00 MB/s ~ETA: 40s\n"))
+	w.Write([]byte("Chan(123):45 -> /tmp/dl/123_45_a.mp4 ... done! [100.00 MB in 20s] 5.00 MB/s\n"))
+	w.Write([]byte("Chan(123):46 -> /tmp/dl/123_46_b.mp4 ... fail! [10.00 MB in 8s]\n"))
+
+	if assert.Len(t, updates, 3) {
+		assert.Equal(t, domain.DownloadProgress{Percent: 40, Speed: "4.8MiB/s", ETA: "12s", CurrentFile: "123_45_a.mp4"}, updates[0])
+		assert.Equal(t, 30.0, updates[1].Percent)
+		assert.Equal(t, "5.7MiB/s", updates[1].Speed)
+		assert.Equal(t, "40s", updates[1].ETA)
+		assert.Equal(t, "123_46_b.mp4", updates[1].CurrentFile)
+		// The finished file no longer adds speed
+		assert.Equal(t, 60.0, updates[2].Percent)
+		assert.Equal(t, "976.6KiB/s", updates[2].Speed)
+		assert.Equal(t, "123_45_a.mp4", updates[2].CurrentFile)
+	}
+}
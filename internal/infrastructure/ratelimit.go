@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// yt-dlp: "HTTP Error 429: Too Many Requests", "Rate limit exceeded"
+	rateLimitRe = regexp.MustCompile(`(?i)HTTP Error 429|Too Many Requests|rate[- ]limit(ed| exceeded)`)
+	// "Retry-After: 120" or "Retry-After: Wed, 21 Oct 2015 07:28:00 GMT"
+	retryAfterHeaderRe = regexp.MustCompile(`(?i)Retry-After:\s*([^\r\n]+)`)
+	// "retry after 120 seconds", "try again in 15 minutes"
+	retryAfterTextRe = regexp.MustCompile(`(?i)(?:retry after|try again in)\s+(\d+)\s*(s|sec|seconds?|m|min|minutes?|h|hours?)?\b`)
+)
+
+// detectRateLimit reports whether tool output contains a rate-limit error and
+// the Retry-After wait it mentions, if any (zero when absent).
+func detectRateLimit(output string) (time.Duration, bool) {
+	if !rateLimitRe.MatchString(output) {
+		return 0, false
+	}
+	return parseRetryAfter(output, time.Now()), true
+}
+
+// parseRetryAfter extracts a Retry-After wait from output: a header value in
+// seconds or as an HTTP date, or a "retry after N seconds" style message.
+func parseRetryAfter(output string, now time.Time) time.Duration {
+	if m := retryAfterHeaderRe.FindStringSubmatch(output); m != nil {
+		value := strings.TrimSpace(m[1])
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil && at.After(now) {
+			return at.Sub(now)
+		}
+	}
+	if m := retryAfterTextRe.FindStringSubmatch(output); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := time.Second
+		switch strings.ToLower(m[2]) {
+		case "m", "min", "minute", "minutes":
+			unit = time.Minute
+		case "h", "hour", "hours":
+			unit = time.Hour
+		}
+		return time.Duration(n) * unit
+	}
+	return 0
+}
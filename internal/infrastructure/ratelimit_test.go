@@ -0,0 +1,29 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectRateLimit(t *testing.T) {
+	_, limited := detectRateLimit("ERROR: [twitter] 123: Unable to download JSON metadata: HTTP Error 404: Not Found")
+	assert.False(t, limited)
+
+	retryAfter, limited := detectRateLimit("ERROR: [twitter] 123: Unable to download JSON metadata: HTTP Error 429: Too Many Requests")
+	assert.True(t, limited)
+	assert.Equal(t, time.Duration(0), retryAfter)
+
+	retryAfter, limited = detectRateLimit("ERROR: Rate limit exceeded. Retry-After: 120")
+	assert.True(t, limited)
+	assert.Equal(t, 120*time.Second, retryAfter)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 20, 0, 0, time.UTC)
+	assert.Equal(t, 8*time.Minute, parseRetryAfter("Retry-After: Wed, 21 Oct 2015 07:28:00 GMT", now))
+	assert.Equal(t, 15*time.Minute, parseRetryAfter("Too Many Requests, try again in 15 minutes", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("rate limited, retry after 30 seconds", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("HTTP Error 429: Too Many Requests", now))
+}
@@ -2,7 +2,9 @@ package infrastructure
 
 import (
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/yourusername/x-extract-go/internal/domain"
@@ -15,33 +17,237 @@ import (
 // SQLiteDownloadRepository implements DownloadRepository and TelegramChannelRepository using SQLite
 type SQLiteDownloadRepository struct {
 	db *gorm.DB
+
+	// tdl profile whose channel list and message cache are used (empty =
+	// telegram.profile), see ForTelegramProfile
+	telegramProfile string
 }
 
 // NewSQLiteDownloadRepository creates a new SQLite repository
 func NewSQLiteDownloadRepository(dbPath string) (*SQLiteDownloadRepository, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(dbPath)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Auto-migrate the schema for Download and TelegramChannel
-	if err := db.AutoMigrate(&domain.Download{}, &domain.TelegramChannel{}); err != nil {
+	// Parallel downloads write progress and status concurrently; keep a small
+	// pool so readers don't queue behind writers (see sqliteDSN)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(sqliteMaxOpenConns)
+	sqlDB.SetMaxIdleConns(sqliteMaxOpenConns)
+
+	// Databases created before the metadata columns existed need their rows
+	// backfilled from the metadata JSON once the columns are added.
+	needsMetadataColumns := db.Migrator().HasTable(&domain.Download{}) &&
+		!db.Migrator().HasColumn(&domain.Download{}, "Uploader")
+	needsLanguage := db.Migrator().HasTable(&domain.Download{}) &&
+		!db.Migrator().HasColumn(&domain.Download{}, "Language")
+	needsFileSize := db.Migrator().HasTable(&domain.Download{}) &&
+		!db.Migrator().HasColumn(&domain.Download{}, "FileSize")
+	needsItems := db.Migrator().HasTable(&domain.Download{}) &&
+		!db.Migrator().HasTable(&domain.DownloadItem{})
+
+	// Channel lists and message caches created before they were kept per tdl
+	// profile get the profile in their primary key
+	for _, model := range []interface{}{&domain.TelegramChannel{}, &domain.TelegramMessageCache{}} {
+		if err := addTelegramProfileKey(db, model); err != nil {
+			return nil, fmt.Errorf("failed to migrate telegram caches: %w", err)
+		}
+	}
+
+	// Auto-migrate the schema for Download, its items and TelegramChannel
+	if err := db.AutoMigrate(&domain.Download{}, &domain.DownloadItem{}, &domain.TelegramChannel{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if needsMetadataColumns || needsLanguage {
+		if err := migrateMetadataColumns(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate metadata columns: %w", err)
+		}
+	}
+	if needsFileSize {
+		if err := migrateFileSizes(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate file sizes: %w", err)
+		}
+	}
+	if needsItems {
+		if err := migrateItems(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate download items: %w", err)
+		}
+	}
+
 	// Auto-migrate the message cache table
 	if err := db.AutoMigrate(&domain.TelegramMessageCache{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate message cache: %w", err)
 	}
 
+	// Auto-migrate the schedules table
+	if err := db.AutoMigrate(&domain.Schedule{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate schedules: %w", err)
+	}
+
+	// Auto-migrate the subscriptions table
+	if err := db.AutoMigrate(&domain.Subscription{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate subscriptions: %w", err)
+	}
+
+	// Auto-migrate the saved searches table
+	if err := db.AutoMigrate(&domain.SavedSearch{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate saved searches: %w", err)
+	}
+
+	// Auto-migrate the telegram sessions table
+	if err := db.AutoMigrate(&domain.TelegramSession{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate telegram sessions: %w", err)
+	}
+
+	// Auto-migrate the telegram channel aliases table
+	if err := db.AutoMigrate(&domain.TelegramChannelAlias{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate telegram channel aliases: %w", err)
+	}
+
 	return &SQLiteDownloadRepository{db: db}, nil
 }
 
+// NewReadOnlySQLiteDownloadRepository opens an existing database read-only,
+// without migrating it, so the CLI can read downloads and stats while the
+// server is not running. Writes through the repository fail.
+func NewReadOnlySQLiteDownloadRepository(dbPath string) (*SQLiteDownloadRepository, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db, err := gorm.Open(sqlite.Open(sqliteReadOnlyDSN(dbPath)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return &SQLiteDownloadRepository{db: db}, nil
+}
+
+// addTelegramProfileKey rebuilds a Telegram cache table without the profile
+// column, adding it to the primary key. Existing rows belong to
+// telegram.profile (""). SQLite cannot change the primary key of a table, so
+// the rows are copied into a new one.
+func addTelegramProfileKey(db *gorm.DB, model interface{}) error {
+	if !db.Migrator().HasTable(model) || db.Migrator().HasColumn(model, "Profile") {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+	legacy := table + "_legacy"
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		columnTypes, err := tx.Migrator().ColumnTypes(model)
+		if err != nil {
+			return err
+		}
+		columns := make([]string, 0, len(columnTypes))
+		for _, column := range columnTypes {
+			columns = append(columns, column.Name())
+		}
+		list := strings.Join(columns, ", ")
+
+		// Dropping the table drops its indexes too, so the new ones can be created
+		if err := tx.Exec(fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s", legacy, table)).Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropTable(table); err != nil {
+			return err
+		}
+		if err := tx.AutoMigrate(model); err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s, profile) SELECT %s, '' FROM %s", table, list, list, legacy)).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable(legacy)
+	})
+}
+
+// migrateMetadataColumns populates the title/uploader/uploader_id/upload_date/
+// webpage_url/language columns of existing rows from their metadata JSON.
+func migrateMetadataColumns(db *gorm.DB) error {
+	var downloads []*domain.Download
+	return db.Select("id", "metadata").
+		Where("metadata IS NOT NULL AND metadata != ''").
+		FindInBatches(&downloads, 500, func(tx *gorm.DB, batch int) error {
+			for _, download := range downloads {
+				download.SyncMetadataColumns()
+				// UpdateColumns leaves updated_at untouched
+				if err := tx.Model(download).UpdateColumns(map[string]interface{}{
+					"title":       download.Title,
+					"uploader":    download.Uploader,
+					"uploader_id": download.UploaderID,
+					"upload_date": download.UploadDate,
+					"webpage_url": download.WebpageURL,
+					"language":    download.Language,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// migrateFileSizes populates the file_size column of existing completed
+// downloads from the files still present on disk.
+func migrateFileSizes(db *gorm.DB) error {
+	var downloads []*domain.Download
+	return db.Select("id", "file_path", "metadata").
+		Where("status = ?", domain.StatusCompleted).
+		FindInBatches(&downloads, 500, func(tx *gorm.DB, batch int) error {
+			for _, download := range downloads {
+				size := TotalFileSize(download.Files())
+				if size == 0 {
+					continue
+				}
+				if err := tx.Model(download).UpdateColumn("file_size", size).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// migrateItems creates the items of existing completed downloads from the
+// file list in their metadata. Files no longer on disk keep a zero size.
+func migrateItems(db *gorm.DB) error {
+	var downloads []*domain.Download
+	return db.Select("id", "file_path", "metadata").
+		Where("status = ?", domain.StatusCompleted).
+		FindInBatches(&downloads, 500, func(tx *gorm.DB, batch int) error {
+			for _, download := range downloads {
+				download.Items = nil
+				download.EnsureItems()
+				if len(download.Items) == 0 {
+					continue
+				}
+				for i := range download.Items {
+					download.Items[i].FileSize = TotalFileSize([]string{download.Items[i].FilePath})
+				}
+				if err := tx.Create(&download.Items).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
 // Create creates a new download
 func (r *SQLiteDownloadRepository) Create(download *domain.Download) error {
-	return r.db.Create(download).Error
+	download.SyncMetadataColumns()
+	return withBusyRetry(func() error {
+		return r.db.Create(download).Error
+	})
 }
 
 // FindByURL finds the most recent download matching the URL with any of the given statuses.
@@ -60,31 +266,137 @@ func (r *SQLiteDownloadRepository) FindByURL(url string, statuses []domain.Downl
 	return &download, nil
 }
 
-// Update updates an existing download
+// Update updates an existing download, replacing its items when Items is non-nil
 func (r *SQLiteDownloadRepository) Update(download *domain.Download) error {
+	download.SyncMetadataColumns()
 	// Use Update with explicit columns to ensure all fields are saved
-	return r.db.Model(download).Updates(map[string]interface{}{
-		"status":        download.Status,
-		"file_path":     download.FilePath,
-		"metadata":      download.Metadata,
-		"process_log":   download.ProcessLog,
-		"error_message": download.ErrorMessage,
-		"retry_count":   download.RetryCount,
-		"started_at":    download.StartedAt,
-		"completed_at":  download.CompletedAt,
-		"updated_at":    time.Now(),
+	return withBusyRetry(func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			if err := r.updateColumns(tx, download); err != nil {
+				return err
+			}
+			if download.Items == nil {
+				return nil
+			}
+			return replaceItems(tx, download)
+		})
+	})
+}
+
+// updateColumns saves every mutable column of a download
+func (r *SQLiteDownloadRepository) updateColumns(tx *gorm.DB, download *domain.Download) error {
+	// Items are saved by replaceItems, never as an association
+	return tx.Model(download).Omit(clause.Associations).Updates(map[string]interface{}{
+		"status":           download.Status,
+		"file_path":        download.FilePath,
+		"file_size":        download.FileSize,
+		"media_duration":   download.MediaDuration,
+		"remote_url":       download.RemoteURL,
+		"metadata":         download.Metadata,
+		"title":            download.Title,
+		"uploader":         download.Uploader,
+		"uploader_id":      download.UploaderID,
+		"upload_date":      download.UploadDate,
+		"webpage_url":      download.WebpageURL,
+		"language":         download.Language,
+		"process_log":      download.ProcessLog,
+		"timeline":         download.Timeline,
+		"progress":         download.Progress,
+		"speed":            download.Speed,
+		"eta":              download.ETA,
+		"current_file":     download.CurrentFile,
+		"process_count":    download.ProcessCount,
+		"process_cpu_ms":   download.ProcessCPUMs,
+		"process_wall_ms":  download.ProcessWallMs,
+		"process_peak_rss": download.ProcessPeakRSS,
+		"estimated_size":   download.EstimatedSize,
+		"item_count":       download.ItemCount,
+		"client_profile":   download.ClientProfile,
+		"deferred":         download.Deferred,
+		"error_message":    download.ErrorMessage,
+		"error_code":       download.ErrorCode,
+		"retry_count":      download.RetryCount,
+		"priority":         download.Priority,
+		"started_at":       download.StartedAt,
+		"completed_at":     download.CompletedAt,
+		"updated_at":       time.Now(),
 	}).Error
 }
 
+// replaceItems replaces the item rows of a download with download.Items
+func replaceItems(tx *gorm.DB, download *domain.Download) error {
+	if err := tx.Where("download_id = ?", download.ID).Delete(&domain.DownloadItem{}).Error; err != nil {
+		return err
+	}
+	if len(download.Items) == 0 {
+		return nil
+	}
+	for i := range download.Items {
+		download.Items[i].ID = 0
+		download.Items[i].DownloadID = download.ID
+	}
+	return tx.Create(&download.Items).Error
+}
+
+// preloadItems loads the items of the queried downloads in position order
+func preloadItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	})
+}
+
+// UpdateProgress saves only the live progress fields of a download
+func (r *SQLiteDownloadRepository) UpdateProgress(download *domain.Download) error {
+	// UpdateColumns leaves updated_at untouched; progress is not a state change
+	return withBusyRetry(func() error {
+		return r.db.Model(download).UpdateColumns(map[string]interface{}{
+			"progress":     download.Progress,
+			"speed":        download.Speed,
+			"eta":          download.ETA,
+			"current_file": download.CurrentFile,
+			"item_count":   download.ItemCount,
+		}).Error
+	})
+}
+
+// UpdateQueuedPriorities sets the priority of downloads that are still queued
+// and returns how many were updated
+func (r *SQLiteDownloadRepository) UpdateQueuedPriorities(priorities map[string]int) (int64, error) {
+	var updated int64
+	err := withBusyRetry(func() error {
+		updated = 0
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			for id, priority := range priorities {
+				result := tx.Model(&domain.Download{}).
+					Where("id = ? AND status = ?", id, domain.StatusQueued).
+					UpdateColumn("priority", priority)
+				if result.Error != nil {
+					return result.Error
+				}
+				updated += result.RowsAffected
+			}
+			return nil
+		})
+	})
+	return updated, err
+}
+
 // Delete deletes a download by ID
 func (r *SQLiteDownloadRepository) Delete(id string) error {
-	return r.db.Delete(&domain.Download{}, "id = ?", id).Error
+	return withBusyRetry(func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("download_id = ?", id).Delete(&domain.DownloadItem{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&domain.Download{}, "id = ?", id).Error
+		})
+	})
 }
 
 // FindByID finds a download by ID
 func (r *SQLiteDownloadRepository) FindByID(id string) (*domain.Download, error) {
 	var download domain.Download
-	err := r.db.First(&download, "id = ?", id).Error
+	err := preloadItems(r.db).First(&download, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -98,14 +410,32 @@ func (r *SQLiteDownloadRepository) FindByStatus(status domain.DownloadStatus) ([
 	return downloads, err
 }
 
-// ResetOrphanedProcessing resets downloads that are stuck in processing state
-// This handles cases where the server was killed during download
-// Returns the number of downloads that were reset
-func (r *SQLiteDownloadRepository) ResetOrphanedProcessing() (int64, error) {
-	result := r.db.Model(&domain.Download{}).
-		Where("status = ?", domain.StatusProcessing).
-		Update("status", domain.StatusQueued)
-	return result.RowsAffected, result.Error
+// ResetOrphanedProcessing recovers downloads that are stuck in processing or
+// recording state. This handles cases where the server was killed during download.
+// Downloads with retries left are queued again, the others fail. Returns the
+// recovered downloads.
+func (r *SQLiteDownloadRepository) ResetOrphanedProcessing(maxRetries int) ([]*domain.Download, error) {
+	var orphans []*domain.Download
+	err := withBusyRetry(func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			orphans = nil
+			if err := tx.Where("status IN ?", []domain.DownloadStatus{domain.StatusProcessing, domain.StatusRecording}).
+				Find(&orphans).Error; err != nil {
+				return err
+			}
+			for _, download := range orphans {
+				download.RecoverOrphaned(maxRetries)
+				if err := r.updateColumns(tx, download); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orphans, nil
 }
 
 // FindPending finds all pending downloads ordered by priority and creation time
@@ -118,18 +448,58 @@ func (r *SQLiteDownloadRepository) FindPending() ([]*domain.Download, error) {
 }
 
 // FindAll finds all downloads with optional filters
-func (r *SQLiteDownloadRepository) FindAll(filters map[string]interface{}) ([]*domain.Download, error) {
+func (r *SQLiteDownloadRepository) FindAll(filters map[string]interface{}, opts domain.ListOptions) ([]*domain.Download, error) {
 	var downloads []*domain.Download
-	query := r.db
+	query := filterDownloads(preloadItems(r.db), filters, opts)
 
-	for key, value := range filters {
-		query = query.Where(fmt.Sprintf("%s = ?", key), value)
+	sortBy, sortDir := "created_at", "DESC"
+	if domain.ValidDownloadSorts[opts.SortBy] {
+		sortBy = opts.SortBy
+	}
+	if opts.SortDir == domain.SortAsc {
+		sortDir = "ASC"
+	}
+	// id breaks ties so pages do not overlap
+	query = query.Order(sortBy + " " + sortDir).Order("id " + sortDir)
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		query = query.Offset(opts.Offset)
 	}
 
-	err := query.Order("created_at DESC").Find(&downloads).Error
+	err := query.Find(&downloads).Error
 	return downloads, err
 }
 
+// CountAll returns the number of downloads FindAll would return without paging
+func (r *SQLiteDownloadRepository) CountAll(filters map[string]interface{}, opts domain.ListOptions) (int64, error) {
+	var count int64
+	err := filterDownloads(r.db.Model(&domain.Download{}), filters, opts).Count(&count).Error
+	return count, err
+}
+
+// filterDownloads applies FindAll's column filters, creation time range and
+// size range
+func filterDownloads(query *gorm.DB, filters map[string]interface{}, opts domain.ListOptions) *gorm.DB {
+	for key, value := range filters {
+		query = query.Where(fmt.Sprintf("%s = ?", key), value)
+	}
+	if opts.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *opts.CreatedAfter)
+	}
+	if opts.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *opts.CreatedBefore)
+	}
+	if opts.MinSize > 0 {
+		query = query.Where("file_size >= ?", opts.MinSize)
+	}
+	if opts.MaxSize > 0 {
+		query = query.Where("file_size <= ?", opts.MaxSize)
+	}
+	return query
+}
+
 // Count returns the total number of downloads
 func (r *SQLiteDownloadRepository) Count() (int64, error) {
 	var count int64
@@ -144,18 +514,18 @@ func (r *SQLiteDownloadRepository) CountByStatus(status domain.DownloadStatus) (
 	return count, err
 }
 
-// CountActive returns the number of active downloads (queued + processing)
+// CountActive returns the number of active downloads (queued + processing + recording)
 func (r *SQLiteDownloadRepository) CountActive() (int64, error) {
 	var count int64
 	err := r.db.Model(&domain.Download{}).
-		Where("status IN ?", []domain.DownloadStatus{domain.StatusQueued, domain.StatusProcessing}).
+		Where("status IN ?", []domain.DownloadStatus{domain.StatusQueued, domain.StatusProcessing, domain.StatusRecording}).
 		Count(&count).Error
 	return count, err
 }
 
 // GetStats returns download statistics
 func (r *SQLiteDownloadRepository) GetStats() (*domain.DownloadStats, error) {
-	stats := &domain.DownloadStats{}
+	stats := &domain.DownloadStats{Tags: []domain.GroupStats{}, Collections: []domain.GroupStats{}}
 
 	// Get total count
 	if err := r.db.Model(&domain.Download{}).Count(&stats.Total).Error; err != nil {
@@ -181,18 +551,134 @@ func (r *SQLiteDownloadRepository) GetStats() (*domain.DownloadStats, error) {
 			stats.Queued = sc.Count
 		case domain.StatusProcessing:
 			stats.Processing = sc.Count
+		case domain.StatusRecording:
+			stats.Recording = sc.Count
 		case domain.StatusCompleted:
 			stats.Completed = sc.Count
 		case domain.StatusFailed:
 			stats.Failed = sc.Count
 		case domain.StatusCancelled:
 			stats.Cancelled = sc.Count
+		case domain.StatusExpired:
+			stats.Expired = sc.Count
 		}
 	}
 
+	itemCounts := []struct {
+		Status domain.DownloadStatus
+		Count  int64
+	}{}
+	if err := r.db.Model(&domain.DownloadItem{}).
+		Select("status, count(*) as count").
+		Group("status").
+		Scan(&itemCounts).Error; err != nil {
+		return nil, err
+	}
+	for _, ic := range itemCounts {
+		switch ic.Status {
+		case domain.StatusCompleted:
+			stats.Items = ic.Count
+		case domain.StatusFailed:
+			stats.FailedItems = ic.Count
+		}
+	}
+
+	if err := r.downloadedTotals(stats); err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Raw(tagStatsQuery, domain.StatusCompleted).Scan(&stats.Tags).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Raw(collectionStatsQuery, domain.StatusCompleted).Scan(&stats.Collections).Error; err != nil {
+		return nil, err
+	}
+	if err := r.resourceStats(&stats.Resources); err != nil {
+		return nil, err
+	}
+
 	return stats, nil
 }
 
+// downloadedTotals sums the size and media duration of the downloads that
+// completed, imported ones aside, and the size of those completed today
+func (r *SQLiteDownloadRepository) downloadedTotals(stats *domain.DownloadStats) error {
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var totals struct {
+		Bytes         int64
+		BytesToday    int64
+		MediaDuration float64
+	}
+	if err := r.db.Model(&domain.Download{}).
+		Select("COALESCE(SUM(file_size), 0) AS bytes, "+
+			"COALESCE(SUM(CASE WHEN completed_at >= ? THEN file_size ELSE 0 END), 0) AS bytes_today, "+
+			"COALESCE(SUM(media_duration), 0) AS media_duration", midnight).
+		Where("completed_at IS NOT NULL AND COALESCE(source, '') <> ?", domain.SourceImport).
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+	stats.Bytes, stats.BytesToday, stats.MediaDuration = totals.Bytes, totals.BytesToday, totals.MediaDuration
+	return nil
+}
+
+// GetStatsHistory returns the downloads added and completed per day, by
+// platform, for days days through now's day. The days are bucketed in local
+// time, so the rows are loaded and counted by domain.BuildStatsHistory.
+func (r *SQLiteDownloadRepository) GetStatsHistory(days int, now time.Time) (*domain.StatsHistory, error) {
+	start := domain.StatsHistoryStart(days, now)
+	var activities []domain.DownloadActivity
+	if err := r.db.Model(&domain.Download{}).
+		Select("platform, created_at, completed_at, file_size").
+		Where("(created_at >= ? OR completed_at >= ?) AND COALESCE(source, '') <> ?", start, start, domain.SourceImport).
+		Scan(&activities).Error; err != nil {
+		return nil, err
+	}
+	return domain.BuildStatsHistory(activities, days, now), nil
+}
+
+// heaviestDownloads is how many downloads the resource stats list
+const heaviestDownloads = 5
+
+// resourceStats sums the resource usage of the downloads' last attempts and
+// lists the downloads that used the most CPU time
+func (r *SQLiteDownloadRepository) resourceStats(stats *domain.ResourceStats) error {
+	var totals struct {
+		CPUMs   int64
+		WallMs  int64
+		PeakRSS int64
+	}
+	if err := r.db.Model(&domain.Download{}).
+		Select("COALESCE(SUM(process_cpu_ms), 0) AS cpu_ms, COALESCE(SUM(process_wall_ms), 0) AS wall_ms, COALESCE(MAX(process_peak_rss), 0) AS peak_rss").
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+	stats.CPUMs, stats.WallMs, stats.PeakRSS = totals.CPUMs, totals.WallMs, totals.PeakRSS
+	stats.Heaviest = []domain.DownloadUsage{}
+	return r.db.Model(&domain.Download{}).
+		Select("id, url, status, process_count AS processes, process_cpu_ms AS cpu_ms, process_wall_ms AS wall_ms, process_peak_rss AS peak_rss").
+		Where("process_cpu_ms > 0").
+		Order("process_cpu_ms DESC").
+		Limit(heaviestDownloads).
+		Scan(&stats.Heaviest).Error
+}
+
+// tagStatsQuery groups the downloads with the given status by each entry of
+// their metadata "tags". The CASE guards json_each against rows whose
+// metadata is not valid JSON.
+const tagStatsQuery = "SELECT tag.value AS name, COUNT(*) AS count, COALESCE(SUM(downloads.file_size), 0) AS bytes " +
+	"FROM downloads, json_each(CASE WHEN json_valid(downloads.metadata) THEN downloads.metadata ELSE '{}' END, '$.tags') AS tag " +
+	"WHERE downloads.status = ? AND tag.value <> '' " +
+	"GROUP BY tag.value ORDER BY bytes DESC, name"
+
+// collectionStatsQuery groups the downloads with the given status by their
+// metadata "collection"
+const collectionStatsQuery = "SELECT name, COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS bytes FROM (" +
+	"SELECT CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.collection') END AS name, file_size " +
+	"FROM downloads WHERE status = ?) " +
+	"WHERE name IS NOT NULL AND name <> '' " +
+	"GROUP BY name ORDER BY bytes DESC, name"
+
 // Close closes the database connection
 func (r *SQLiteDownloadRepository) Close() error {
 	sqlDB, err := r.db.DB()
@@ -206,11 +692,25 @@ func (r *SQLiteDownloadRepository) Close() error {
 // TelegramChannelRepository implementation
 // ============================================================================
 
+// ForTelegramProfile returns a view of the repository whose channel list and
+// message cache are those of a tdl profile of telegram.profiles (empty =
+// telegram.profile). It shares the database; close the repository, not the
+// view.
+func (r *SQLiteDownloadRepository) ForTelegramProfile(profile string) *SQLiteDownloadRepository {
+	return &SQLiteDownloadRepository{db: r.db, telegramProfile: profile}
+}
+
+// telegramCache scopes a query of the channel list or message cache to the
+// repository's tdl profile
+func (r *SQLiteDownloadRepository) telegramCache() *gorm.DB {
+	return r.db.Where("profile = ?", r.telegramProfile)
+}
+
 // GetChannelName retrieves the channel name for a given channel ID
 // Returns empty string if not found
 func (r *SQLiteDownloadRepository) GetChannelName(channelID string) (string, error) {
 	var channel domain.TelegramChannel
-	err := r.db.Select("channel_name").Where("channel_id = ?", channelID).First(&channel).Error
+	err := r.telegramCache().Select("channel_name").Where("channel_id = ?", channelID).First(&channel).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return "", nil
@@ -224,7 +724,7 @@ func (r *SQLiteDownloadRepository) GetChannelName(channelID string) (string, err
 // Returns nil if not found
 func (r *SQLiteDownloadRepository) GetChannel(channelID string) (*domain.TelegramChannel, error) {
 	var channel domain.TelegramChannel
-	err := r.db.Where("channel_id = ?", channelID).First(&channel).Error
+	err := r.telegramCache().Where("channel_id = ?", channelID).First(&channel).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -234,6 +734,15 @@ func (r *SQLiteDownloadRepository) GetChannel(channelID string) (*domain.Telegra
 	return &channel, nil
 }
 
+// ListChannels returns all stored channels, ordered by name
+func (r *SQLiteDownloadRepository) ListChannels() ([]*domain.TelegramChannel, error) {
+	var channels []*domain.TelegramChannel
+	if err := r.telegramCache().Order("channel_name").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
 // UpdateChannelList updates or inserts multiple channels
 // channels is a map of channelID -> TelegramChannel
 func (r *SQLiteDownloadRepository) UpdateChannelList(channels map[string]*domain.TelegramChannel) error {
@@ -245,22 +754,25 @@ func (r *SQLiteDownloadRepository) UpdateChannelList(channels map[string]*domain
 	channelList := make([]*domain.TelegramChannel, 0, len(channels))
 	now := time.Now()
 	for _, ch := range channels {
+		ch.Profile = r.telegramProfile
 		ch.LastUpdatedAt = now
 		channelList = append(channelList, ch)
 	}
 
 	// Upsert all channels (insert or update on conflict)
-	return r.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "channel_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"channel_name", "channel_type", "username", "last_updated_at"}),
-	}).Create(&channelList).Error
+	return withBusyRetry(func() error {
+		return r.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "channel_id"}, {Name: "profile"}},
+			DoUpdates: clause.AssignmentColumns([]string{"channel_name", "channel_type", "username", "last_updated_at", "previous_name", "renamed_at"}),
+		}).Create(&channelList).Error
+	})
 }
 
 // ShouldUpdateChannelList checks if the channel list needs updating
 // Returns true if the list is empty or the newest record is older than maxAge
 func (r *SQLiteDownloadRepository) ShouldUpdateChannelList(maxAge time.Duration) (bool, error) {
 	var count int64
-	if err := r.db.Model(&domain.TelegramChannel{}).Count(&count).Error; err != nil {
+	if err := r.telegramCache().Model(&domain.TelegramChannel{}).Count(&count).Error; err != nil {
 		return true, err
 	}
 
@@ -283,7 +795,7 @@ func (r *SQLiteDownloadRepository) ShouldUpdateChannelList(maxAge time.Duration)
 // Returns zero time if no records exist
 func (r *SQLiteDownloadRepository) GetLastUpdateTime() (time.Time, error) {
 	var channel domain.TelegramChannel
-	err := r.db.Order("last_updated_at DESC").First(&channel).Error
+	err := r.telegramCache().Order("last_updated_at DESC").First(&channel).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return time.Time{}, nil
@@ -293,6 +805,76 @@ func (r *SQLiteDownloadRepository) GetLastUpdateTime() (time.Time, error) {
 	return channel.LastUpdatedAt, nil
 }
 
+// ============================================================================
+// TelegramChannelAliasRepository implementation
+// ============================================================================
+
+// SaveChannelAlias records that alias.ChannelID continues as alias.SuccessorID,
+// keeping every stored successor a channel without an alias
+func (r *SQLiteDownloadRepository) SaveChannelAlias(alias *domain.TelegramChannelAlias) error {
+	return withBusyRetry(func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			var stored []*domain.TelegramChannelAlias
+			if err := tx.Find(&stored).Error; err != nil {
+				return err
+			}
+			aliases := domain.NewTelegramChannelAliases(stored)
+			if err := domain.ValidateTelegramChannelAlias(aliases, alias.ChannelID, alias.SuccessorID); err != nil {
+				return err
+			}
+			alias.SuccessorID = aliases.Successor(alias.SuccessorID)
+
+			if err := tx.Model(&domain.TelegramChannelAlias{}).
+				Where("successor_id = ?", alias.ChannelID).
+				Update("successor_id", alias.SuccessorID).Error; err != nil {
+				return err
+			}
+			return tx.Save(alias).Error
+		})
+	})
+}
+
+// DeleteChannelAlias removes the alias of a channel
+// Returns false if it had none
+func (r *SQLiteDownloadRepository) DeleteChannelAlias(channelID string) (bool, error) {
+	result := r.db.Delete(&domain.TelegramChannelAlias{}, "channel_id = ?", channelID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListChannelAliases returns all aliases, ordered by successor
+func (r *SQLiteDownloadRepository) ListChannelAliases() ([]*domain.TelegramChannelAlias, error) {
+	var aliases []*domain.TelegramChannelAlias
+	if err := r.db.Order("successor_id, channel_id").Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// ============================================================================
+// TelegramSessionRepository implementation
+// ============================================================================
+
+// SaveTelegramSession inserts or replaces the session of its profile
+func (r *SQLiteDownloadRepository) SaveTelegramSession(session *domain.TelegramSession) error {
+	return withBusyRetry(func() error {
+		return r.db.Save(session).Error
+	})
+}
+
+// GetTelegramSession returns the session of a profile
+// Returns nil if the profile was never verified
+func (r *SQLiteDownloadRepository) GetTelegramSession(profile string) (*domain.TelegramSession, error) {
+	var session domain.TelegramSession
+	err := r.db.Where("profile = ?", profile).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
 // ============================================================================
 // TelegramMessageCacheRepository implementation
 // ============================================================================
@@ -301,7 +883,7 @@ func (r *SQLiteDownloadRepository) GetLastUpdateTime() (time.Time, error) {
 // Returns nil if not found
 func (r *SQLiteDownloadRepository) GetMessage(channelID, messageID string) (*domain.TelegramMessageCache, error) {
 	var cache domain.TelegramMessageCache
-	err := r.db.Where("channel_id = ? AND message_id = ?", channelID, messageID).First(&cache).Error
+	err := r.telegramCache().Where("channel_id = ? AND message_id = ?", channelID, messageID).First(&cache).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -313,7 +895,10 @@ func (r *SQLiteDownloadRepository) GetMessage(channelID, messageID string) (*dom
 
 // SaveMessage saves a single message to cache
 func (r *SQLiteDownloadRepository) SaveMessage(cache *domain.TelegramMessageCache) error {
-	return r.db.Save(cache).Error
+	cache.Profile = r.telegramProfile
+	return withBusyRetry(func() error {
+		return r.db.Save(cache).Error
+	})
 }
 
 // SaveMessages saves multiple messages in batch (more efficient)
@@ -322,16 +907,21 @@ func (r *SQLiteDownloadRepository) SaveMessages(caches []domain.TelegramMessageC
 	if len(caches) == 0 {
 		return nil
 	}
-	return r.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "channel_id"}, {Name: "message_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"text", "date", "sender_id", "sender_name", "media_type", "grouped_id", "cached_at"}),
-	}).Create(&caches).Error
+	for i := range caches {
+		caches[i].Profile = r.telegramProfile
+	}
+	return withBusyRetry(func() error {
+		return r.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "channel_id"}, {Name: "message_id"}, {Name: "profile"}},
+			DoUpdates: clause.AssignmentColumns([]string{"text", "date", "sender_id", "sender_name", "media_type", "grouped_id", "cached_at"}),
+		}).Create(&caches).Error
+	})
 }
 
 // GetCachedMessages returns a map of messageID -> true for all cached messages in a channel
 func (r *SQLiteDownloadRepository) GetCachedMessages(channelID string) (map[string]bool, error) {
 	var caches []domain.TelegramMessageCache
-	err := r.db.Select("message_id").Where("channel_id = ?", channelID).Find(&caches).Error
+	err := r.telegramCache().Select("message_id").Where("channel_id = ?", channelID).Find(&caches).Error
 	if err != nil {
 		return nil, err
 	}
@@ -345,7 +935,7 @@ func (r *SQLiteDownloadRepository) GetCachedMessages(channelID string) (map[stri
 // HasChannelCache checks if a channel has any cached messages
 func (r *SQLiteDownloadRepository) HasChannelCache(channelID string) (bool, error) {
 	var count int64
-	err := r.db.Model(&domain.TelegramMessageCache{}).Where("channel_id = ?", channelID).Count(&count).Error
+	err := r.telegramCache().Model(&domain.TelegramMessageCache{}).Where("channel_id = ?", channelID).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
@@ -358,7 +948,7 @@ func (r *SQLiteDownloadRepository) GetMaxDate(channelID string) (int64, error) {
 	var result struct {
 		MaxDate int64
 	}
-	err := r.db.Model(&domain.TelegramMessageCache{}).
+	err := r.telegramCache().Model(&domain.TelegramMessageCache{}).
 		Select("MAX(date) as max_date").
 		Where("channel_id = ?", channelID).
 		Scan(&result).Error
@@ -368,11 +958,27 @@ func (r *SQLiteDownloadRepository) GetMaxDate(channelID string) (int64, error) {
 	return result.MaxDate, nil
 }
 
+// GetMaxMessageID gets the highest cached message ID of a channel
+// Returns 0 if no messages are cached
+func (r *SQLiteDownloadRepository) GetMaxMessageID(channelID string) (int, error) {
+	var result struct {
+		MaxID int
+	}
+	err := r.telegramCache().Model(&domain.TelegramMessageCache{}).
+		Select("COALESCE(MAX(CAST(message_id AS INTEGER)), 0) as max_id").
+		Where("channel_id = ?", channelID).
+		Scan(&result).Error
+	if err != nil {
+		return 0, err
+	}
+	return result.MaxID, nil
+}
+
 // GetMessagesByGroupedID retrieves all cached messages with the same grouped ID in a channel
 // Used to find text from other messages in a media group/album
 func (r *SQLiteDownloadRepository) GetMessagesByGroupedID(channelID, groupedID string) ([]domain.TelegramMessageCache, error) {
 	var caches []domain.TelegramMessageCache
-	err := r.db.Where("channel_id = ? AND grouped_id = ?", channelID, groupedID).Find(&caches).Error
+	err := r.telegramCache().Where("channel_id = ? AND grouped_id = ?", channelID, groupedID).Find(&caches).Error
 	if err != nil {
 		return nil, err
 	}
@@ -391,10 +997,431 @@ func (r *SQLiteDownloadRepository) GetNearbyMessages(channelID, messageID string
 	maxID := msgID + msgRange
 
 	var caches []domain.TelegramMessageCache
-	err = r.db.Where("channel_id = ? AND CAST(message_id AS INTEGER) BETWEEN ? AND ? AND message_id != ?",
+	err = r.telegramCache().Where("channel_id = ? AND CAST(message_id AS INTEGER) BETWEEN ? AND ? AND message_id != ?",
 		channelID, minID, maxID, messageID).Find(&caches).Error
 	if err != nil {
 		return nil, err
 	}
 	return caches, nil
 }
+
+// ============================================================================
+// ScheduleRepository implementation
+// ============================================================================
+
+// CreateSchedule creates a new schedule
+func (r *SQLiteDownloadRepository) CreateSchedule(schedule *domain.Schedule) error {
+	return withBusyRetry(func() error {
+		return r.db.Create(schedule).Error
+	})
+}
+
+// UpdateSchedule updates an existing schedule.
+// Uses an explicit column map so zero values (Enabled=false, empty LastError) are persisted.
+func (r *SQLiteDownloadRepository) UpdateSchedule(schedule *domain.Schedule) error {
+	return withBusyRetry(func() error {
+		return r.db.Model(schedule).Updates(map[string]interface{}{
+			"name":          schedule.Name,
+			"source_url":    schedule.SourceURL,
+			"platform":      schedule.Platform,
+			"cron_expr":     schedule.CronExpr,
+			"enabled":       schedule.Enabled,
+			"cursor":        schedule.Cursor,
+			"last_run_at":   schedule.LastRunAt,
+			"next_run_at":   schedule.NextRunAt,
+			"last_error":    schedule.LastError,
+			"last_enqueued": schedule.LastEnqueued,
+			"updated_at":    time.Now(),
+		}).Error
+	})
+}
+
+// DeleteSchedule deletes a schedule by ID
+func (r *SQLiteDownloadRepository) DeleteSchedule(id string) error {
+	return r.db.Delete(&domain.Schedule{}, "id = ?", id).Error
+}
+
+// FindScheduleByID finds a schedule by ID
+// Returns nil if not found
+func (r *SQLiteDownloadRepository) FindScheduleByID(id string) (*domain.Schedule, error) {
+	var schedule domain.Schedule
+	err := r.db.First(&schedule, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindAllSchedules returns all schedules ordered by creation time
+func (r *SQLiteDownloadRepository) FindAllSchedules() ([]*domain.Schedule, error) {
+	var schedules []*domain.Schedule
+	err := r.db.Order("created_at ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// ============================================================================
+// SubscriptionRepository implementation
+// ============================================================================
+
+// CreateSubscription creates a new subscription
+func (r *SQLiteDownloadRepository) CreateSubscription(subscription *domain.Subscription) error {
+	return withBusyRetry(func() error {
+		return r.db.Create(subscription).Error
+	})
+}
+
+// UpdateSubscription updates an existing subscription.
+// Uses an explicit column map so zero values (Enabled=false, empty LastError) are persisted.
+func (r *SQLiteDownloadRepository) UpdateSubscription(subscription *domain.Subscription) error {
+	return withBusyRetry(func() error {
+		return r.db.Model(subscription).Updates(map[string]interface{}{
+			"name":           subscription.Name,
+			"interval":       subscription.Interval,
+			"enabled":        subscription.Enabled,
+			"cursor":         subscription.Cursor,
+			"last_check_at":  subscription.LastCheckAt,
+			"next_check_at":  subscription.NextCheckAt,
+			"last_error":     subscription.LastError,
+			"last_enqueued":  subscription.LastEnqueued,
+			"total_enqueued": subscription.TotalEnqueued,
+			"checks":         subscription.Checks,
+			"failed_checks":  subscription.FailedChecks,
+			"last_new_at":    subscription.LastNewAt,
+			"updated_at":     time.Now(),
+		}).Error
+	})
+}
+
+// DeleteSubscription deletes a subscription by ID
+func (r *SQLiteDownloadRepository) DeleteSubscription(id string) error {
+	return r.db.Delete(&domain.Subscription{}, "id = ?", id).Error
+}
+
+// FindSubscriptionByID finds a subscription by ID
+// Returns nil if not found
+func (r *SQLiteDownloadRepository) FindSubscriptionByID(id string) (*domain.Subscription, error) {
+	var subscription domain.Subscription
+	err := r.db.First(&subscription, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// FindAllSubscriptions returns all subscriptions ordered by creation time
+func (r *SQLiteDownloadRepository) FindAllSubscriptions() ([]*domain.Subscription, error) {
+	var subscriptions []*domain.Subscription
+	err := r.db.Order("created_at ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ============================================================================
+// UploaderRepository implementation
+// ============================================================================
+
+// uploaderKeyExpr groups downloads by uploader ID, falling back to the name.
+// Telegram channels with an alias are counted as their successor.
+const uploaderKeyExpr = "COALESCE(" +
+	"(SELECT successor_id FROM telegram_channel_aliases WHERE downloads.platform = 'telegram' AND " +
+	"telegram_channel_aliases.channel_id = COALESCE(NULLIF(downloads.uploader_id, ''), downloads.uploader)), " +
+	"NULLIF(uploader_id, ''), uploader)"
+
+// uploaderSortOrders maps UploaderFilter.Sort to ORDER BY clauses
+var uploaderSortOrders = map[string]string{
+	domain.UploaderSortLatest: "latest_at DESC",
+	domain.UploaderSortCount:  "download_count DESC, latest_at DESC",
+	domain.UploaderSortSize:   "total_size DESC, latest_at DESC",
+	domain.UploaderSortName:   "uploader COLLATE NOCASE ASC",
+}
+
+// ListUploaders returns one summary per distinct uploader
+func (r *SQLiteDownloadRepository) ListUploaders(filter domain.UploaderFilter) ([]*domain.UploaderSummary, error) {
+	order, ok := uploaderSortOrders[filter.Sort]
+	if !ok {
+		order = uploaderSortOrders[domain.UploaderSortLatest]
+	}
+
+	// With a single MAX() aggregate, SQLite takes the bare columns (id,
+	// uploader, uploader_id) from the row holding the maximum, i.e. the
+	// most recent download of each uploader.
+	rows := []struct {
+		Platform      domain.Platform
+		UploaderKey   string
+		Uploader      string
+		UploaderID    string
+		LatestID      string
+		DownloadCount int64
+		TotalSize     int64
+	}{}
+	query := r.db.Model(&domain.Download{}).
+		Select("platform, " + uploaderKeyExpr + " AS uploader_key, " +
+			"COALESCE(uploader, '') AS uploader, COALESCE(uploader_id, '') AS uploader_id, " +
+			"id AS latest_id, MAX(created_at) AS latest_at, " +
+			"COUNT(*) AS download_count, COALESCE(SUM(file_size), 0) AS total_size").
+		Where("uploader != '' OR uploader_id != ''")
+	if filter.Platform != "" {
+		query = query.Where("platform = ?", filter.Platform)
+	}
+	if err := query.Group("platform, uploader_key").Order(order).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	latestIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		latestIDs = append(latestIDs, row.LatestID)
+	}
+	latest := make(map[string]*domain.Download, len(rows))
+	if len(latestIDs) > 0 {
+		var downloads []*domain.Download
+		if err := r.db.Where("id IN ?", latestIDs).Find(&downloads).Error; err != nil {
+			return nil, err
+		}
+		for _, d := range downloads {
+			latest[d.ID] = d
+		}
+	}
+
+	summaries := make([]*domain.UploaderSummary, 0, len(rows))
+	for _, row := range rows {
+		summaries = append(summaries, &domain.UploaderSummary{
+			Key:            row.UploaderKey,
+			Platform:       row.Platform,
+			Uploader:       row.Uploader,
+			UploaderID:     row.UploaderID,
+			DownloadCount:  row.DownloadCount,
+			TotalSize:      row.TotalSize,
+			LatestDownload: latest[row.LatestID],
+		})
+	}
+	return summaries, nil
+}
+
+// FindByUploader returns the downloads of the uploader identified by key, newest first
+func (r *SQLiteDownloadRepository) FindByUploader(platform domain.Platform, key string) ([]*domain.Download, error) {
+	var downloads []*domain.Download
+	query := r.db.Where(uploaderKeyExpr+" = ?", key)
+	if platform != "" {
+		query = query.Where("platform = ?", platform)
+	}
+	err := query.Order("created_at DESC").Find(&downloads).Error
+	return downloads, err
+}
+
+// ============================================================================
+// SavedSearchRepository implementation
+// ============================================================================
+
+// CreateSavedSearch creates a new saved search
+func (r *SQLiteDownloadRepository) CreateSavedSearch(search *domain.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+// UpdateSavedSearch updates an existing saved search
+func (r *SQLiteDownloadRepository) UpdateSavedSearch(search *domain.SavedSearch) error {
+	// Explicit columns so cleared filter fields are saved
+	return r.db.Model(search).Updates(map[string]interface{}{
+		"name":            search.Name,
+		"filter_status":   search.Filter.Status,
+		"filter_platform": search.Filter.Platform,
+		"filter_tag":      search.Filter.Tag,
+		"filter_uploader": search.Filter.Uploader,
+		"filter_language": search.Filter.Language,
+		"filter_from":     search.Filter.From,
+		"filter_to":       search.Filter.To,
+		"filter_query":    search.Filter.Query,
+		"filter_text":     search.Filter.Text,
+		"updated_at":      time.Now(),
+	}).Error
+}
+
+// DeleteSavedSearch deletes a saved search by ID
+func (r *SQLiteDownloadRepository) DeleteSavedSearch(id string) error {
+	return r.db.Delete(&domain.SavedSearch{}, "id = ?", id).Error
+}
+
+// FindSavedSearch finds a saved search by ID or name
+// Returns nil if not found
+func (r *SQLiteDownloadRepository) FindSavedSearch(idOrName string) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	err := r.db.Where("id = ? OR name = ?", idOrName, idOrName).First(&search).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &search, nil
+}
+
+// FindAllSavedSearches returns all saved searches ordered by name
+func (r *SQLiteDownloadRepository) FindAllSavedSearches() ([]*domain.SavedSearch, error) {
+	var searches []*domain.SavedSearch
+	err := r.db.Order("name COLLATE NOCASE ASC").Find(&searches).Error
+	return searches, err
+}
+
+// SearchDownloads returns downloads matching filter, newest first
+func (r *SQLiteDownloadRepository) SearchDownloads(filter domain.DownloadFilter, limit int) ([]*domain.Download, error) {
+	query := r.db.Model(&domain.Download{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Platform != "" {
+		query = query.Where("platform = ?", filter.Platform)
+	}
+	if filter.Uploader != "" {
+		query = query.Where("uploader = ? OR uploader_id = ?", filter.Uploader, filter.Uploader)
+	}
+	if filter.Language != "" {
+		query = query.Where("language = ?", filter.Language)
+	}
+	if filter.Tag != "" {
+		// CASE guards json_each against rows whose metadata is not valid JSON
+		query = query.Where("CASE WHEN json_valid(metadata) THEN "+
+			"EXISTS (SELECT 1 FROM json_each(metadata, '$.tags') WHERE value = ?) ELSE 0 END", filter.Tag)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("url LIKE ? ESCAPE '\\' OR title LIKE ? ESCAPE '\\' OR uploader LIKE ? ESCAPE '\\'",
+			pattern, pattern, pattern)
+	}
+	for _, term := range domain.SearchTerms(filter.Text) {
+		args := make([]interface{}, fullTextTermArgs)
+		for i := range args {
+			args[i] = "%" + escapeLike(term) + "%"
+		}
+		query = query.Where(fullTextTermClause, args...)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var downloads []*domain.Download
+	err := query.Order("created_at DESC").Find(&downloads).Error
+	return downloads, err
+}
+
+// fullTextTermClause matches one full-text search term against the URL,
+// title and uploader columns and the description, tags, image alt texts and
+// poll options in the metadata JSON. It takes the LIKE pattern
+// fullTextTermArgs times.
+const fullTextTermClause = "url LIKE ? ESCAPE '\\' OR title LIKE ? ESCAPE '\\' OR " +
+	"uploader LIKE ? ESCAPE '\\' OR uploader_id LIKE ? ESCAPE '\\' OR " +
+	"CASE WHEN json_valid(metadata) THEN " +
+	"json_extract(metadata, '$.description') LIKE ? ESCAPE '\\' OR " +
+	"EXISTS (SELECT 1 FROM json_each(metadata, '$.tags') WHERE value LIKE ? ESCAPE '\\') OR " +
+	"EXISTS (SELECT 1 FROM json_each(metadata, '$.alt_texts') WHERE value LIKE ? ESCAPE '\\') OR " +
+	"EXISTS (SELECT 1 FROM json_each(metadata, '$.poll.options') WHERE json_extract(value, '$.label') LIKE ? ESCAPE '\\') " +
+	"ELSE 0 END"
+
+const fullTextTermArgs = 8
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// ============================================================================
+// ReportRepository implementation
+// ============================================================================
+
+// FindFinishedBetween returns the downloads that completed, failed or were
+// cancelled in [from, to), with their items. Completed downloads are matched
+// by completed_at, failed and cancelled ones by their last update.
+func (r *SQLiteDownloadRepository) FindFinishedBetween(from, to time.Time) ([]*domain.Download, error) {
+	var downloads []*domain.Download
+	err := preloadItems(r.db).
+		Where("(status = ? AND completed_at >= ? AND completed_at < ?) OR (status IN ? AND updated_at >= ? AND updated_at < ?)",
+			domain.StatusCompleted, from, to,
+			[]domain.DownloadStatus{domain.StatusFailed, domain.StatusCancelled}, from, to).
+		Order("created_at ASC").
+		Find(&downloads).Error
+	return downloads, err
+}
+
+// CompletedSizeBefore returns the total size of the downloads completed before t
+func (r *SQLiteDownloadRepository) CompletedSizeBefore(t time.Time) (int64, error) {
+	var total int64
+	err := r.db.Model(&domain.Download{}).
+		Select("COALESCE(SUM(file_size), 0)").
+		Where("status = ? AND completed_at < ?", domain.StatusCompleted, t).
+		Scan(&total).Error
+	return total, err
+}
+
+// ============================================================================
+// Content dedupe (download.dedupe)
+// ============================================================================
+
+// FindItemsByContentHash returns the completed items whose file has the given
+// SHA-256, original files (not linked duplicates) first, oldest first
+func (r *SQLiteDownloadRepository) FindItemsByContentHash(hash string) ([]domain.DownloadItem, error) {
+	var items []domain.DownloadItem
+	err := r.db.Where("content_hash = ? AND status = ? AND file_path <> ''", hash, domain.StatusCompleted).
+		Order("duplicate_of <> '' ASC, id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// FindDuplicateItems returns the completed items whose content hash another
+// completed item shares
+func (r *SQLiteDownloadRepository) FindDuplicateItems() ([]domain.DownloadItem, error) {
+	var items []domain.DownloadItem
+	shared := r.db.Model(&domain.DownloadItem{}).
+		Select("content_hash").
+		Where("content_hash <> '' AND status = ?", domain.StatusCompleted).
+		Group("content_hash").
+		Having("COUNT(*) > 1")
+	err := r.db.Where("content_hash IN (?) AND status = ?", shared, domain.StatusCompleted).
+		Order("id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// ============================================================================
+// Maintenance window (maintenance.window)
+// ============================================================================
+
+// FindDeferred returns up to limit completed downloads, with their items,
+// whose transcodes or uploads wait for the maintenance window, oldest
+// completed first
+func (r *SQLiteDownloadRepository) FindDeferred(limit int) ([]*domain.Download, error) {
+	var downloads []*domain.Download
+	err := preloadItems(r.db).
+		Where("deferred <> '' AND status = ?", domain.StatusCompleted).
+		Order("completed_at ASC").
+		Limit(limit).
+		Find(&downloads).Error
+	return downloads, err
+}
+
+// CountDeferred returns the number of completed downloads whose transcodes or
+// uploads wait for the maintenance window
+func (r *SQLiteDownloadRepository) CountDeferred() (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Download{}).
+		Where("deferred <> '' AND status = ?", domain.StatusCompleted).
+		Count(&count).Error
+	return count, err
+}
+
+// Vacuum rebuilds the database file, returning the space of deleted rows to
+// the file system
+func (r *SQLiteDownloadRepository) Vacuum() error {
+	return withBusyRetry(func() error {
+		return r.db.Exec("VACUUM").Error
+	})
+}
@@ -1,9 +1,13 @@
 package infrastructure
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -121,6 +125,285 @@ func TestFindByURL_MultipleStatuses(t *testing.T) {
 	assert.Equal(t, dl.ID, found.ID)
 }
 
+// ============================================================================
+// Download: Update tests
+// ============================================================================
+
+func TestUpdate_PersistsPriorityForFindPending(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	first := domain.NewDownload("https://t.me/channel/1", domain.PlatformTelegram, domain.ModeDefault)
+	require.NoError(t, repo.Create(first))
+	second := domain.NewDownload("https://t.me/channel/2", domain.PlatformTelegram, domain.ModeDefault)
+	require.NoError(t, repo.Create(second))
+
+	// Bump the later download ahead of the earlier one
+	second.Priority = 5
+	require.NoError(t, repo.Update(second))
+
+	pending, err := repo.FindPending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, second.ID, pending[0].ID)
+	assert.Equal(t, 5, pending[0].Priority)
+	assert.Equal(t, first.ID, pending[1].ID)
+}
+
+func TestUpdateQueuedPriorities_SkipsStartedDownloads(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	queued := domain.NewDownload("https://t.me/channel/1", domain.PlatformTelegram, domain.ModeDefault)
+	require.NoError(t, repo.Create(queued))
+	started := domain.NewDownload("https://t.me/channel/2", domain.PlatformTelegram, domain.ModeDefault)
+	require.NoError(t, repo.Create(started))
+	started.MarkProcessing()
+	require.NoError(t, repo.Update(started))
+
+	updated, err := repo.UpdateQueuedPriorities(map[string]int{queued.ID: 3, started.ID: 4})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	got, err := repo.FindByID(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.Priority)
+	got, err = repo.FindByID(started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.Priority)
+	assert.Equal(t, domain.StatusProcessing, got.Status)
+}
+
+func TestUpdate_PersistsTimeline(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	download := domain.NewDownload("https://t.me/channel/1", domain.PlatformTelegram, domain.ModeDefault)
+	require.NoError(t, repo.Create(download))
+	download.MarkProcessing()
+	download.MarkFailed(errors.New("exit status 1"))
+	require.NoError(t, repo.Update(download))
+
+	found, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	require.Len(t, found.Timeline, 3)
+	assert.Equal(t, domain.TimelineQueued, found.Timeline[0].Event)
+	assert.Equal(t, domain.TimelineStarted, found.Timeline[1].Event)
+	assert.Equal(t, domain.TimelineFailed, found.Timeline[2].Event)
+	assert.Equal(t, "exit status 1", found.Timeline[2].Message)
+}
+
+func TestUpdate_PersistsItems(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	download := domain.NewDownload("https://x.com/alice/media", domain.PlatformX, domain.ModeProfile)
+	require.NoError(t, repo.Create(download))
+	download.ItemCount = 2
+	download.Items = []domain.DownloadItem{
+		{Status: domain.StatusCompleted, FilePath: "/c/a.mp4", FileSize: 10, SourceID: "100"},
+		{Status: domain.StatusFailed, SourceID: "200", ErrorMessage: "unavailable"},
+	}
+	download.MarkCompleted("/c/a.mp4")
+	download.EnsureItems()
+	require.NoError(t, repo.Update(download))
+
+	found, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.ItemCount)
+	require.Len(t, found.Items, 2)
+	assert.Equal(t, "100", found.Items[0].SourceID, "items load in position order")
+	assert.Equal(t, domain.StatusFailed, found.Items[1].Status)
+
+	// A later update replaces the items; nil Items leaves them alone
+	download.Items = []domain.DownloadItem{{Status: domain.StatusCompleted, FilePath: "/c/b.mp4"}}
+	require.NoError(t, repo.Update(download))
+	download.Items = nil
+	require.NoError(t, repo.Update(download))
+	found, err = repo.FindByID(download.ID)
+	require.NoError(t, err)
+	require.Len(t, found.Items, 1)
+	assert.Equal(t, "/c/b.mp4", found.Items[0].FilePath)
+
+	stats, err := repo.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Items)
+	assert.Equal(t, int64(0), stats.FailedItems)
+
+	require.NoError(t, repo.Delete(download.ID))
+	var count int64
+	require.NoError(t, repo.db.Model(&domain.DownloadItem{}).Count(&count).Error)
+	assert.Zero(t, count, "deleting a download deletes its items")
+}
+
+func TestFindItemsByContentHash_AndDuplicates(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	save := func(url string, items ...domain.DownloadItem) *domain.Download {
+		download := domain.NewDownload(url, domain.PlatformX, domain.ModeDefault)
+		require.NoError(t, repo.Create(download))
+		download.Items = items
+		download.MarkCompleted(items[0].FilePath)
+		download.EnsureItems()
+		require.NoError(t, repo.Update(download))
+		return download
+	}
+	first := save("https://x.com/a/status/1", domain.DownloadItem{Status: domain.StatusCompleted, FilePath: "/c/1.mp4", ContentHash: "video"})
+	second := save("https://x.com/b/status/2",
+		domain.DownloadItem{Status: domain.StatusCompleted, FilePath: "/c/2.mp4", ContentHash: "video", DuplicateOf: first.ID},
+		domain.DownloadItem{Status: domain.StatusCompleted, FilePath: "/c/2.jpg", ContentHash: "photo"})
+	// Saved again later: its items get new IDs, but it stays the original
+	require.NoError(t, repo.Update(first))
+
+	items, err := repo.FindItemsByContentHash("video")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, first.ID, items[0].DownloadID, "originals first")
+	assert.Equal(t, second.ID, items[1].DownloadID)
+	assert.Equal(t, first.ID, items[1].DuplicateOf)
+
+	items, err = repo.FindItemsByContentHash("none")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	duplicates, err := repo.FindDuplicateItems()
+	require.NoError(t, err)
+	require.Len(t, duplicates, 2, "the unshared photo is left out")
+	for _, item := range duplicates {
+		assert.Equal(t, "video", item.ContentHash)
+	}
+}
+
+func TestUpdate_PersistsClientProfile(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(download))
+	download.ClientProfile = "impersonate=chrome"
+	download.MarkProcessing()
+	require.NoError(t, repo.Update(download))
+
+	found, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "impersonate=chrome", found.ClientProfile)
+}
+
+func TestUpdate_PersistsDeferredJobs(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(download))
+	download.MarkCompleted("/completed/a.mp4")
+	download.DeferJob(domain.MaintenanceTranscodes)
+	require.NoError(t, repo.Update(download))
+
+	found, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceTranscodes, found.Deferred)
+	deferred, err := repo.FindDeferred(10)
+	require.NoError(t, err)
+	require.Len(t, deferred, 1)
+	assert.Equal(t, download.ID, deferred[0].ID)
+
+	// Running the job clears it
+	download.Deferred = ""
+	require.NoError(t, repo.Update(download))
+	count, err := repo.CountDeferred()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestUpdate_PersistsEstimatedSize(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(download))
+	download.EstimatedSize = 500 << 20
+	download.MarkProcessing()
+	require.NoError(t, repo.Update(download))
+
+	// A retry reuses the estimate instead of simulating again
+	found, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500<<20), found.EstimatedSize)
+}
+
+// downloadCreateOnlyFields are the Download fields set when a download is
+// added, which Update leaves alone. Items are saved by Update, but only when
+// set (see TestUpdate_PersistsItems).
+var downloadCreateOnlyFields = map[string]bool{
+	"ID": true, "URL": true, "Platform": true, "Mode": true, "RangeStart": true, "RangeEnd": true,
+	"AllVariants": true, "CallbackURL": true, "Source": true, "Account": true, "TDLProfile": true,
+	"CreatedAt": true, "UpdatedAt": true, "Items": true,
+}
+
+func TestUpdate_PersistsEveryMutableField(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(download))
+
+	// Give every other field a value, so a field added to Download but not
+	// to Update fails here
+	when := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	want := reflect.ValueOf(download).Elem()
+	var mutable []string
+	for i := 0; i < want.NumField(); i++ {
+		name := want.Type().Field(i).Name
+		if downloadCreateOnlyFields[name] {
+			continue
+		}
+		mutable = append(mutable, name)
+		field := want.Field(i)
+		switch field.Interface().(type) {
+		case *time.Time:
+			field.Set(reflect.ValueOf(&when))
+		case domain.Timeline:
+			field.Set(reflect.ValueOf(domain.Timeline{{Time: when, Event: domain.TimelineFailed, Message: "exit status 1"}}))
+		default:
+			switch field.Kind() {
+			case reflect.String:
+				field.SetString(name)
+			case reflect.Int, reflect.Int64:
+				field.SetInt(int64(i + 1))
+			case reflect.Float64:
+				field.SetFloat(float64(i) + 0.5)
+			case reflect.Bool:
+				field.SetBool(true)
+			default:
+				t.Fatalf("no test value for Download.%s", name)
+			}
+		}
+	}
+	require.NoError(t, repo.Update(download))
+
+	found, err := repo.FindByID(download.ID)
+	require.NoError(t, err)
+	got := reflect.ValueOf(found).Elem()
+	for _, name := range mutable {
+		wantValue, gotValue := want.FieldByName(name).Interface(), got.FieldByName(name).Interface()
+		switch wantTime := wantValue.(type) {
+		case *time.Time:
+			if assert.NotNil(t, gotValue.(*time.Time), name) {
+				assert.True(t, wantTime.Equal(*gotValue.(*time.Time)), name)
+			}
+		case domain.Timeline:
+			gotTimeline := gotValue.(domain.Timeline)
+			if assert.Len(t, gotTimeline, 1, name) {
+				assert.Equal(t, wantTime[0].Message, gotTimeline[0].Message)
+				assert.True(t, wantTime[0].Time.Equal(gotTimeline[0].Time), name)
+			}
+		default:
+			assert.Equal(t, wantValue, gotValue, name)
+		}
+	}
+}
+
 // ============================================================================
 // TelegramMessageCache: GetMessagesByGroupedID tests
 // ============================================================================
@@ -272,3 +555,846 @@ func TestGetNearbyMessages_DifferentChannels(t *testing.T) {
 	assert.Equal(t, "chan1", results[0].ChannelID)
 	assert.Equal(t, "Chan1 nearby", results[0].Text)
 }
+
+func TestGetMaxMessageID(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	id, err := repo.GetMaxMessageID("chan1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, id)
+
+	// Compared as numbers, not strings
+	require.NoError(t, repo.SaveMessages([]domain.TelegramMessageCache{
+		{ChannelID: "chan1", MessageID: "99"},
+		{ChannelID: "chan1", MessageID: "1000"},
+		{ChannelID: "chan2", MessageID: "5000"},
+	}))
+	id, err = repo.GetMaxMessageID("chan1")
+	require.NoError(t, err)
+	assert.Equal(t, 1000, id)
+}
+
+func TestSubscription_CRUDPersistsDisabled(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	subscription, err := domain.NewSubscription("https://t.me/somechannel", "", "15m", false)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateSubscription(subscription))
+
+	subscription.Enabled = false
+	subscription.Cursor = "42"
+	subscription.TotalEnqueued = 3
+	require.NoError(t, repo.UpdateSubscription(subscription))
+
+	found, err := repo.FindSubscriptionByID(subscription.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.False(t, found.Enabled)
+	assert.Equal(t, "42", found.Cursor)
+	assert.Equal(t, 3, found.TotalEnqueued)
+	assert.Equal(t, "somechannel", found.Source)
+
+	all, err := repo.FindAllSubscriptions()
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	require.NoError(t, repo.DeleteSubscription(subscription.ID))
+	found, err = repo.FindSubscriptionByID(subscription.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestSchedule_CRUDPersistsDisabled(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	schedule, err := domain.NewSchedule("chan", "https://t.me/c/123", domain.PlatformTelegram, "@hourly")
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateSchedule(schedule))
+
+	schedule.Enabled = false
+	schedule.Cursor = "42"
+	schedule.LastError = ""
+	require.NoError(t, repo.UpdateSchedule(schedule))
+
+	found, err := repo.FindScheduleByID(schedule.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.False(t, found.Enabled)
+	assert.Equal(t, "42", found.Cursor)
+	require.NotNil(t, found.NextRunAt)
+
+	all, err := repo.FindAllSchedules()
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	require.NoError(t, repo.DeleteSchedule(schedule.ID))
+	found, err = repo.FindScheduleByID(schedule.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestUpdate_PromotesMetadataColumns(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	dl := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(dl))
+
+	dl.Metadata = `{"title":"T","uploader":"User","uploader_id":"user","upload_date":"20240101"}`
+	require.NoError(t, repo.Update(dl))
+
+	found, err := repo.FindAll(map[string]interface{}{"uploader": "User"}, domain.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "T", found[0].Title)
+	assert.Equal(t, "user", found[0].UploaderID)
+	assert.Equal(t, "20240101", found[0].UploadDate)
+}
+
+func TestFindAll_PagesSortsAndFiltersByTime(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		dl := domain.NewDownload(fmt.Sprintf("https://x.com/user/status/%d", i), domain.PlatformX, domain.ModeDefault)
+		dl.ID = fmt.Sprintf("dl%d", i)
+		dl.CreatedAt = base.AddDate(0, 0, i)
+		dl.FileSize = int64(100 - i*10)
+		require.NoError(t, repo.Create(dl))
+	}
+	ids := func(downloads []*domain.Download) []string {
+		var result []string
+		for _, d := range downloads {
+			result = append(result, d.ID)
+		}
+		return result
+	}
+
+	// Newest first by default
+	found, err := repo.FindAll(nil, domain.ListOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl4", "dl3"}, ids(found))
+	found, err = repo.FindAll(nil, domain.ListOptions{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl2", "dl1"}, ids(found))
+	found, err = repo.FindAll(nil, domain.ListOptions{Offset: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl1", "dl0"}, ids(found))
+
+	found, err = repo.FindAll(nil, domain.ListOptions{SortBy: "file_size", SortDir: domain.SortAsc, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl4", "dl3"}, ids(found))
+	found, err = repo.FindAll(nil, domain.ListOptions{SortBy: "created_at", SortDir: domain.SortAsc, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl0"}, ids(found))
+
+	after, before := base.AddDate(0, 0, 1), base.AddDate(0, 0, 3)
+	opts := domain.ListOptions{CreatedAfter: &after, CreatedBefore: &before, Limit: 1}
+	found, err = repo.FindAll(map[string]interface{}{"platform": domain.PlatformX}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl2"}, ids(found))
+	total, err := repo.CountAll(map[string]interface{}{"platform": domain.PlatformX}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total, "the count ignores paging")
+
+	// Sizes are 100, 90, 80, 70 and 60
+	opts = domain.ListOptions{MinSize: 70, MaxSize: 90, SortDir: domain.SortAsc}
+	found, err = repo.FindAll(nil, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dl1", "dl2", "dl3"}, ids(found))
+	total, err = repo.CountAll(nil, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+}
+
+func TestNewSQLiteDownloadRepository_MigratesMetadataColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	repo, err := NewSQLiteDownloadRepository(dbPath)
+	require.NoError(t, err)
+	dl := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	dl.Metadata = `{"title":"Old","uploader":"Legacy","uploader_id":"legacy"}`
+	require.NoError(t, repo.Create(dl))
+
+	// Simulate a database from before the metadata columns existed
+	for _, column := range []string{"Title", "Uploader", "UploaderID", "UploadDate", "WebpageURL"} {
+		require.NoError(t, repo.db.Migrator().DropColumn(&domain.Download{}, column))
+	}
+	require.NoError(t, repo.Close())
+
+	repo, err = NewSQLiteDownloadRepository(dbPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	found, err := repo.FindByID(dl.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Old", found.Title)
+	assert.Equal(t, "Legacy", found.Uploader)
+	assert.Equal(t, "legacy", found.UploaderID)
+}
+
+func TestListUploaders_GroupsAndAggregates(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	create := func(platform domain.Platform, metadata string, size int64, createdAt time.Time) *domain.Download {
+		dl := domain.NewDownload("https://example.com/"+createdAt.String(), platform, domain.ModeDefault)
+		dl.Metadata = metadata
+		dl.FileSize = size
+		dl.CreatedAt = createdAt
+		require.NoError(t, repo.Create(dl))
+		return dl
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	create(domain.PlatformX, `{"uploader":"Old Name","uploader_id":"alice"}`, 100, base)
+	latestAlice := create(domain.PlatformX, `{"uploader":"Alice","uploader_id":"alice"}`, 50, base.Add(2*time.Hour))
+	create(domain.PlatformTelegram, `{"uploader":"News Channel"}`, 10, base.Add(time.Hour))
+	create(domain.PlatformX, `{"title":"no uploader"}`, 10, base.Add(3*time.Hour))
+
+	uploaders, err := repo.ListUploaders(domain.UploaderFilter{})
+	require.NoError(t, err)
+	require.Len(t, uploaders, 2)
+
+	alice := uploaders[0]
+	assert.Equal(t, "alice", alice.Key)
+	assert.Equal(t, "Alice", alice.Uploader)
+	assert.Equal(t, int64(2), alice.DownloadCount)
+	assert.Equal(t, int64(150), alice.TotalSize)
+	require.NotNil(t, alice.LatestDownload)
+	assert.Equal(t, latestAlice.ID, alice.LatestDownload.ID)
+
+	assert.Equal(t, "News Channel", uploaders[1].Key)
+	assert.Empty(t, uploaders[1].UploaderID)
+
+	byName, err := repo.ListUploaders(domain.UploaderFilter{Sort: domain.UploaderSortName})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", byName[0].Uploader)
+
+	telegramOnly, err := repo.ListUploaders(domain.UploaderFilter{Platform: domain.PlatformTelegram})
+	require.NoError(t, err)
+	assert.Len(t, telegramOnly, 1)
+
+	downloads, err := repo.FindByUploader(domain.PlatformX, "alice")
+	require.NoError(t, err)
+	require.Len(t, downloads, 2)
+	assert.Equal(t, latestAlice.ID, downloads[0].ID)
+
+	downloads, err = repo.FindByUploader("", "News Channel")
+	require.NoError(t, err)
+	assert.Len(t, downloads, 1)
+}
+
+func TestSearchDownloads_Filters(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	create := func(url string, platform domain.Platform, status domain.DownloadStatus, metadata string, createdAt time.Time) *domain.Download {
+		dl := domain.NewDownload(url, platform, domain.ModeDefault)
+		dl.Status = status
+		dl.Metadata = metadata
+		dl.CreatedAt = createdAt
+		require.NoError(t, repo.Create(dl))
+		return dl
+	}
+
+	cats := create("https://x.com/alice/status/1", domain.PlatformX, domain.StatusCompleted,
+		`{"title":"Cute cats","uploader":"Alice","uploader_id":"alice","tags":["cats","pets"]}`, base)
+	create("https://x.com/bob/status/2", domain.PlatformX, domain.StatusFailed,
+		`{"title":"100% dogs","uploader":"Bob","tags":["dogs"]}`, base.Add(48*time.Hour))
+	create("https://t.me/news/3", domain.PlatformTelegram, domain.StatusCompleted, "not json", base.Add(-48*time.Hour))
+
+	search := func(filter domain.DownloadFilter) []*domain.Download {
+		downloads, err := repo.SearchDownloads(filter, 0)
+		require.NoError(t, err)
+		return downloads
+	}
+
+	assert.Len(t, search(domain.DownloadFilter{}), 3)
+	assert.Len(t, search(domain.DownloadFilter{Platform: domain.PlatformX}), 2)
+	assert.Len(t, search(domain.DownloadFilter{Status: domain.StatusFailed}), 1)
+
+	byTag := search(domain.DownloadFilter{Tag: "pets"})
+	require.Len(t, byTag, 1)
+	assert.Equal(t, cats.ID, byTag[0].ID)
+
+	assert.Len(t, search(domain.DownloadFilter{Uploader: "alice"}), 1)
+	assert.Len(t, search(domain.DownloadFilter{Uploader: "Alice"}), 1)
+	assert.Len(t, search(domain.DownloadFilter{Query: "CATS"}), 1)
+	assert.Len(t, search(domain.DownloadFilter{Query: "100%"}), 1)
+	assert.Len(t, search(domain.DownloadFilter{Query: "0%"}), 1)
+	assert.Empty(t, search(domain.DownloadFilter{Query: "_"}))
+
+	from := base.Add(-time.Hour)
+	to := base.Add(time.Hour)
+	inRange := search(domain.DownloadFilter{From: &from, To: &to})
+	require.Len(t, inRange, 1)
+	assert.Equal(t, cats.ID, inRange[0].ID)
+
+	// Combined criteria: OR clauses must not leak past other conditions
+	assert.Empty(t, search(domain.DownloadFilter{Query: "cats", Status: domain.StatusFailed}))
+
+	limited, err := repo.SearchDownloads(domain.DownloadFilter{}, 2)
+	require.NoError(t, err)
+	assert.Len(t, limited, 2)
+}
+
+func TestSearchDownloads_Language(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	chinese := domain.NewDownload("https://t.me/news/1", domain.PlatformTelegram, domain.ModeDefault)
+	chinese.Metadata = `{"title":"news","description":"今天的新闻已经发布了"}`
+	require.NoError(t, repo.Create(chinese))
+	english := domain.NewDownload("https://t.me/news/2", domain.PlatformTelegram, domain.ModeDefault)
+	english.Metadata = `{"title":"news","description":"The news of the day is out","language":"en"}`
+	require.NoError(t, repo.Create(english))
+
+	downloads, err := repo.SearchDownloads(domain.DownloadFilter{Language: "zh"}, 0)
+	require.NoError(t, err)
+	require.Len(t, downloads, 1)
+	assert.Equal(t, chinese.ID, downloads[0].ID)
+
+	downloads, err = repo.FindAll(map[string]interface{}{"language": "en"}, domain.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, downloads, 1)
+	assert.Equal(t, english.ID, downloads[0].ID)
+}
+
+func TestSearchDownloads_FullText(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	create := func(url, metadata string) *domain.Download {
+		dl := domain.NewDownload(url, domain.PlatformX, domain.ModeDefault)
+		dl.Metadata = metadata
+		require.NoError(t, repo.Create(dl))
+		return dl
+	}
+
+	cats := create("https://x.com/alice/status/1",
+		`{"title":"Weekend","description":"Cute cats playing in the garden","uploader":"Alice","uploader_id":"alice","tags":["pets","Caturday"]}`)
+	create("https://x.com/bob/status/2", `{"title":"Dogs","description":"A dog park","uploader":"Bob","tags":["dogs"]}`)
+	create("https://t.me/news/3", "not json")
+
+	search := func(text string) []*domain.Download {
+		downloads, err := repo.SearchDownloads(domain.DownloadFilter{Text: text}, 0)
+		require.NoError(t, err)
+		return downloads
+	}
+
+	byDescription := search("garden")
+	require.Len(t, byDescription, 1)
+	assert.Equal(t, cats.ID, byDescription[0].ID)
+
+	assert.Len(t, search("caturday"), 1, "tags match case-insensitively")
+	assert.Len(t, search("t.me"), 1, "URL matches even when metadata is not JSON")
+	assert.Len(t, search("x.com"), 2)
+	assert.Len(t, search("alice garden"), 1, "every term must match")
+	assert.Empty(t, search("alice dog"))
+	assert.Len(t, search(`"cute cats"`), 1)
+	assert.Empty(t, search(`"cats cute"`))
+
+	poll := create("https://x.com/carol/status/4",
+		`{"title":"Vote","alt_texts":["A tabby on a windowsill"],"poll":{"options":[{"label":"Tabby","votes":3},{"label":"Siamese","votes":5}],"final":true}}`)
+	byAlt := search("windowsill")
+	require.Len(t, byAlt, 1)
+	assert.Equal(t, poll.ID, byAlt[0].ID)
+	assert.Len(t, search("siamese"), 1, "poll options are searchable")
+}
+
+func TestSavedSearch_CRUD(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	search, err := domain.NewSavedSearch("failed", domain.DownloadFilter{Status: domain.StatusFailed, Tag: "cats"})
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateSavedSearch(search))
+
+	found, err := repo.FindSavedSearch("failed")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, search.ID, found.ID)
+	assert.Equal(t, "cats", found.Filter.Tag)
+
+	// Clearing a filter field persists
+	found.Filter.Tag = ""
+	require.NoError(t, repo.UpdateSavedSearch(found))
+	found, err = repo.FindSavedSearch(search.ID)
+	require.NoError(t, err)
+	assert.Empty(t, found.Filter.Tag)
+	assert.Equal(t, domain.StatusFailed, found.Filter.Status)
+
+	all, err := repo.FindAllSavedSearches()
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	require.NoError(t, repo.DeleteSavedSearch(search.ID))
+	found, err = repo.FindSavedSearch(search.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestTelegramSession_SaveAndGet(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	session, err := repo.GetTelegramSession("default")
+	require.NoError(t, err)
+	assert.Nil(t, session, "unverified profile")
+
+	require.NoError(t, repo.SaveTelegramSession(&domain.TelegramSession{
+		Profile: "default", Error: "not authorized", VerifiedAt: time.Now(),
+	}))
+	require.NoError(t, repo.SaveTelegramSession(&domain.TelegramSession{
+		Profile: "default", Verified: true, Chats: 12, VerifiedAt: time.Now(),
+	}))
+
+	session, err = repo.GetTelegramSession("default")
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	assert.True(t, session.Verified, "saving replaces the profile's session")
+	assert.Equal(t, 12, session.Chats)
+	assert.Empty(t, session.Error)
+}
+
+func TestUpdateProgress_DoesNotOverwriteStatus(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	dl := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	dl.MarkProcessing()
+	require.NoError(t, repo.Create(dl))
+
+	// A concurrent cancel changes the stored status
+	cancelled, err := repo.FindByID(dl.ID)
+	require.NoError(t, err)
+	cancelled.MarkCancelled()
+	require.NoError(t, repo.Update(cancelled))
+
+	dl.ApplyProgress(domain.DownloadProgress{Percent: 55, Speed: "1MiB/s", CurrentFile: "a.mp4"})
+	require.NoError(t, repo.UpdateProgress(dl))
+
+	found, err := repo.FindByID(dl.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, found.Status)
+	assert.Equal(t, 55.0, found.Progress)
+	assert.Equal(t, "1MiB/s", found.Speed)
+	assert.Equal(t, "a.mp4", found.CurrentFile)
+}
+
+func TestResetOrphanedProcessing_RequeuesWithinRetries(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	fresh := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	fresh.MarkProcessing()
+	exhausted := domain.NewDownload("https://x.com/user/status/2", domain.PlatformX, domain.ModeDefault)
+	exhausted.RetryCount = 3
+	exhausted.MarkProcessing()
+	queued := domain.NewDownload("https://x.com/user/status/3", domain.PlatformX, domain.ModeDefault)
+	for _, dl := range []*domain.Download{fresh, exhausted, queued} {
+		require.NoError(t, repo.Create(dl))
+	}
+
+	orphans, err := repo.ResetOrphanedProcessing(3)
+	require.NoError(t, err)
+	assert.Len(t, orphans, 2)
+
+	found, err := repo.FindByID(fresh.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusQueued, found.Status)
+	assert.Equal(t, 1, found.RetryCount)
+	assert.Nil(t, found.StartedAt)
+	assert.Equal(t, domain.TimelineInterrupted, found.Timeline[len(found.Timeline)-1].Event)
+
+	found, err = repo.FindByID(exhausted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusFailed, found.Status)
+	assert.Equal(t, 3, found.RetryCount)
+	assert.Contains(t, found.ErrorMessage, "no retries left")
+
+	found, err = repo.FindByID(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusQueued, found.Status)
+	assert.Zero(t, found.RetryCount)
+}
+
+func TestFindFinishedBetween_AndCompletedSizeBefore(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	from := time.Now().Add(-24 * time.Hour)
+	to := time.Now().Add(time.Hour)
+
+	old := domain.NewDownload("https://x.com/a/status/1", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(old))
+	old.MarkCompleted("/c/old.mp4")
+	earlier := from.Add(-time.Hour)
+	old.CompletedAt = &earlier
+	old.FileSize = 1000
+	require.NoError(t, repo.Update(old))
+
+	done := domain.NewDownload("https://x.com/a/status/2", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(done))
+	done.MarkCompleted("/c/new.mp4")
+	done.FileSize = 200
+	require.NoError(t, repo.Update(done))
+
+	failed := domain.NewDownload("https://x.com/a/status/3", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(failed))
+	failed.MarkFailed(assert.AnError)
+	require.NoError(t, repo.Update(failed))
+
+	queued := domain.NewDownload("https://x.com/a/status/4", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(queued))
+
+	downloads, err := repo.FindFinishedBetween(from, to)
+	require.NoError(t, err)
+	var ids []string
+	for _, d := range downloads {
+		ids = append(ids, d.ID)
+	}
+	assert.ElementsMatch(t, []string{done.ID, failed.ID}, ids)
+
+	total, err := repo.CompletedSizeBefore(to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1200), total)
+	total, err = repo.CompletedSizeBefore(from)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), total)
+}
+
+func TestGetStats_GroupsByTagAndCollection(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	create := func(status domain.DownloadStatus, size int64, metadata string) {
+		dl := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+		dl.Status = status
+		dl.FileSize = size
+		dl.Metadata = metadata
+		require.NoError(t, repo.Create(dl))
+	}
+	create(domain.StatusCompleted, 100, `{"tags":["memes","x"],"collection":"fun"}`)
+	create(domain.StatusCompleted, 300, `{"tags":["reference"],"collection":"work"}`)
+	create(domain.StatusCompleted, 50, `{"tags":["memes"]}`)
+	create(domain.StatusFailed, 1000, `{"tags":["memes"],"collection":"fun"}`)
+	create(domain.StatusCompleted, 10, "not json")
+
+	stats, err := repo.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, []domain.GroupStats{
+		{Name: "reference", Count: 1, Bytes: 300},
+		{Name: "memes", Count: 2, Bytes: 150},
+		{Name: "x", Count: 1, Bytes: 100},
+	}, stats.Tags, "completed downloads only, largest first")
+	assert.Equal(t, []domain.GroupStats{
+		{Name: "work", Count: 1, Bytes: 300},
+		{Name: "fun", Count: 1, Bytes: 100},
+	}, stats.Collections)
+}
+
+func TestGetStats_DownloadedBytes(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	create := func(completedAt *time.Time, size int64, duration float64, source domain.DownloadSource) {
+		dl := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+		dl.Source = source
+		require.NoError(t, repo.Create(dl))
+		// Size and play time are recorded at completion, by Update
+		dl.Status = domain.StatusCompleted
+		dl.CompletedAt = completedAt
+		dl.FileSize = size
+		dl.MediaDuration = duration
+		require.NoError(t, repo.Update(dl))
+	}
+	create(&now, 100, 12.5, domain.SourceCLI)
+	create(&yesterday, 300, 60, domain.SourceAPI)
+	create(&now, 5000, 600, domain.SourceImport)
+	create(nil, 70, 0, domain.SourceAPI)
+
+	stats, err := repo.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(400), stats.Bytes, "imported and never completed downloads are not counted")
+	assert.Equal(t, int64(100), stats.BytesToday)
+	assert.Equal(t, 72.5, stats.MediaDuration)
+}
+
+func TestGetStatsHistory(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	create := func(platform domain.Platform, createdAt time.Time, completedAt *time.Time, size int64, source domain.DownloadSource) {
+		dl := domain.NewDownload("https://x.com/alice/status/1", platform, domain.ModeDefault)
+		dl.CreatedAt = createdAt
+		dl.CompletedAt = completedAt
+		dl.FileSize = size
+		dl.Source = source
+		require.NoError(t, repo.Create(dl))
+	}
+	create(domain.PlatformX, yesterday, &now, 100, domain.SourceCLI)
+	create(domain.PlatformTelegram, now, nil, 0, domain.SourceAPI)
+	create(domain.PlatformX, now, &now, 5000, domain.SourceImport)
+	create(domain.PlatformX, now.AddDate(0, 0, -10), nil, 0, domain.SourceAPI)
+
+	history, err := repo.GetStatsHistory(2, now)
+	require.NoError(t, err)
+	require.Len(t, history.History, 2)
+	assert.Equal(t, yesterday.Format("2006-01-02"), history.History[0].Date)
+	assert.Equal(t, int64(1), history.History[0].Added)
+	today := history.History[1]
+	assert.Equal(t, int64(1), today.Added, "imported downloads are not counted")
+	assert.Equal(t, int64(1), today.Completed)
+	assert.Equal(t, int64(100), today.Bytes)
+	assert.Equal(t, domain.DayCounts{Completed: 1, Bytes: 100}, today.Platforms[domain.PlatformX])
+	assert.Equal(t, domain.DayCounts{Added: 1}, today.Platforms[domain.PlatformTelegram])
+}
+
+func TestFindDeferred(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	create := func(status domain.DownloadStatus, completedAt time.Time, deferred string) *domain.Download {
+		dl := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+		dl.Status = status
+		dl.CompletedAt = &completedAt
+		dl.Deferred = deferred
+		dl.Items = []domain.DownloadItem{{Status: domain.StatusCompleted, FilePath: "/completed/a.mp4"}}
+		require.NoError(t, repo.Create(dl))
+		return dl
+	}
+	now := time.Now()
+	newer := create(domain.StatusCompleted, now, "remote_sync")
+	older := create(domain.StatusCompleted, now.Add(-time.Hour), "transcodes,remote_sync")
+	create(domain.StatusCompleted, now, "")
+	create(domain.StatusExpired, now, "transcodes")
+
+	found, err := repo.FindDeferred(10)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, older.ID, found[0].ID, "oldest completed first")
+	assert.Equal(t, newer.ID, found[1].ID)
+	assert.Len(t, found[0].Items, 1, "with their items")
+	found, err = repo.FindDeferred(1)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	count, err := repo.CountDeferred()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, repo.Vacuum())
+}
+
+func TestGetStats_ResourceUsage(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	stats, err := repo.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, domain.ResourceStats{Heaviest: []domain.DownloadUsage{}}, stats.Resources)
+
+	// The usage is recorded when an attempt ends, by Update
+	create := func(cpu time.Duration, rss int64) *domain.Download {
+		dl := domain.NewDownload("https://x.com/alice/status/1", domain.PlatformX, domain.ModeDefault)
+		require.NoError(t, repo.Create(dl))
+		dl.MarkProcessing()
+		dl.ApplyResourceUsage(domain.ResourceUsage{Processes: 1, CPUTime: cpu, WallTime: 2 * cpu, PeakRSS: rss})
+		dl.MarkCompleted("/completed/a.mp4")
+		require.NoError(t, repo.Update(dl))
+		return dl
+	}
+	light := create(time.Second, 50<<20)
+	heavy := create(time.Minute, 20<<20)
+	create(0, 0)
+
+	stats, err = repo.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(61000), stats.Resources.CPUMs)
+	assert.Equal(t, int64(122000), stats.Resources.WallMs)
+	assert.Equal(t, int64(50<<20), stats.Resources.PeakRSS)
+	require.Len(t, stats.Resources.Heaviest, 2, "downloads without usage are not listed")
+	assert.Equal(t, heavy.ID, stats.Resources.Heaviest[0].ID)
+	assert.Equal(t, int64(60000), stats.Resources.Heaviest[0].CPUMs)
+	assert.Equal(t, 1, stats.Resources.Heaviest[0].Processes)
+	assert.Equal(t, light.ID, stats.Resources.Heaviest[1].ID)
+	assert.Equal(t, int64(50<<20), stats.Resources.Heaviest[1].PeakRSS)
+}
+
+func TestNewReadOnlySQLiteDownloadRepository(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	_, err := NewReadOnlySQLiteDownloadRepository(dbPath)
+	assert.Error(t, err, "a missing database is not created")
+	_, statErr := os.Stat(dbPath)
+	assert.True(t, os.IsNotExist(statErr))
+
+	repo, err := NewSQLiteDownloadRepository(dbPath)
+	require.NoError(t, err)
+	dl := domain.NewDownload("https://x.com/user/status/1", domain.PlatformX, domain.ModeDefault)
+	require.NoError(t, repo.Create(dl))
+
+	// Readable while the writer is still open
+	readOnly, err := NewReadOnlySQLiteDownloadRepository(dbPath)
+	require.NoError(t, err)
+	defer readOnly.Close()
+	require.NoError(t, repo.Close())
+
+	downloads, err := readOnly.FindAll(nil, domain.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, downloads, 1)
+	assert.Equal(t, dl.ID, downloads[0].ID)
+
+	stats, err := readOnly.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+
+	other := domain.NewDownload("https://x.com/user/status/2", domain.PlatformX, domain.ModeDefault)
+	assert.Error(t, readOnly.Create(other))
+}
+
+func TestUpdateChannelList_StoresRenames(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	require.NoError(t, repo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "Old News", ChannelType: "channel", Username: "-"},
+		"200": {ChannelID: "200", ChannelName: "Art", ChannelType: "group", Username: "-"},
+	}))
+
+	renamedAt := time.Now()
+	require.NoError(t, repo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "Daily News", ChannelType: "channel", Username: "dailynews",
+			PreviousName: "Old News", RenamedAt: &renamedAt},
+	}))
+
+	channels, err := repo.ListChannels()
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+	assert.Equal(t, "Art", channels[0].ChannelName)
+	assert.Equal(t, "Daily News", channels[1].ChannelName)
+	assert.Equal(t, "Old News", channels[1].PreviousName)
+	require.NotNil(t, channels[1].RenamedAt)
+	assert.Equal(t, "dailynews", channels[1].Username)
+}
+
+func TestTelegramCaches_PerProfile(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	work := repo.ForTelegramProfile("work")
+
+	require.NoError(t, repo.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "News"},
+	}))
+	require.NoError(t, work.UpdateChannelList(map[string]*domain.TelegramChannel{
+		"100": {ChannelID: "100", ChannelName: "News (work)"},
+		"200": {ChannelID: "200", ChannelName: "Team"},
+	}))
+	channels, err := repo.ListChannels()
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "News", channels[0].ChannelName)
+	channels, err = work.ListChannels()
+	require.NoError(t, err)
+	assert.Len(t, channels, 2)
+
+	require.NoError(t, work.SaveMessages([]domain.TelegramMessageCache{{ChannelID: "200", MessageID: "5", Text: "hi", Date: 42}}))
+	has, err := repo.HasChannelCache("200")
+	require.NoError(t, err)
+	assert.False(t, has)
+	maxDate, err := work.GetMaxDate("200")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), maxDate)
+}
+
+func TestTelegramCaches_MigrateProfileKey(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	repo, err := NewSQLiteDownloadRepository(dbPath)
+	require.NoError(t, err)
+	// A database from before the caches were kept per profile
+	for _, stmt := range []string{
+		"DROP TABLE telegram_channels",
+		"DROP TABLE telegram_message_cache",
+		"CREATE TABLE telegram_channels (channel_id text PRIMARY KEY, channel_name text NOT NULL, channel_type text DEFAULT 'channel', username text, last_updated_at datetime, previous_name text, renamed_at datetime)",
+		"CREATE TABLE telegram_message_cache (channel_id text, message_id text, text text, date integer, sender_id text, sender_name text, media_type text, grouped_id text, cached_at datetime, PRIMARY KEY (channel_id, message_id))",
+		"CREATE INDEX idx_channel_message ON telegram_message_cache(channel_id, message_id)",
+		"INSERT INTO telegram_channels (channel_id, channel_name, last_updated_at) VALUES ('100', 'News', CURRENT_TIMESTAMP)",
+		"INSERT INTO telegram_message_cache (channel_id, message_id, text, date) VALUES ('100', '7', 'hello', 1)",
+	} {
+		require.NoError(t, repo.db.Exec(stmt).Error)
+	}
+	require.NoError(t, repo.Close())
+
+	repo, err = NewSQLiteDownloadRepository(dbPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	name, err := repo.GetChannelName("100")
+	require.NoError(t, err)
+	assert.Equal(t, "News", name)
+	cached, err := repo.GetMessage("100", "7")
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.Equal(t, "hello", cached.Text)
+
+	// The same channel can now be cached by another profile
+	work := repo.ForTelegramProfile("work")
+	require.NoError(t, work.SaveMessages([]domain.TelegramMessageCache{{ChannelID: "100", MessageID: "7", Text: "hello"}}))
+	require.NoError(t, work.UpdateChannelList(map[string]*domain.TelegramChannel{"100": {ChannelID: "100", ChannelName: "News"}}))
+}
+
+func TestChannelAliases_MergeUploaders(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	for i, uploaderID := range []string{"100", "200", "300"} {
+		dl := domain.NewDownload(fmt.Sprintf("https://t.me/c/%s/%d", uploaderID, i+1), domain.PlatformTelegram, domain.ModeDefault)
+		dl.Metadata = fmt.Sprintf(`{"uploader":"Channel %s","uploader_id":%q}`, uploaderID, uploaderID)
+		dl.FileSize = 10
+		require.NoError(t, repo.Create(dl))
+	}
+
+	// 100 continues as 200, which then continues as 300
+	require.NoError(t, repo.SaveChannelAlias(&domain.TelegramChannelAlias{ChannelID: "100", SuccessorID: "200"}))
+	require.NoError(t, repo.SaveChannelAlias(&domain.TelegramChannelAlias{ChannelID: "200", SuccessorID: "300"}))
+	assert.Error(t, repo.SaveChannelAlias(&domain.TelegramChannelAlias{ChannelID: "300", SuccessorID: "100"}))
+
+	aliases, err := repo.ListChannelAliases()
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	assert.Equal(t, "300", aliases[0].SuccessorID)
+	assert.Equal(t, "300", aliases[1].SuccessorID)
+
+	uploaders, err := repo.ListUploaders(domain.UploaderFilter{})
+	require.NoError(t, err)
+	require.Len(t, uploaders, 1)
+	assert.Equal(t, "300", uploaders[0].Key)
+	assert.Equal(t, int64(3), uploaders[0].DownloadCount)
+
+	downloads, err := repo.FindByUploader(domain.PlatformTelegram, "300")
+	require.NoError(t, err)
+	assert.Len(t, downloads, 3)
+
+	deleted, err := repo.DeleteChannelAlias("100")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.DeleteChannelAlias("100")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	uploaders, err = repo.ListUploaders(domain.UploaderFilter{})
+	require.NoError(t, err)
+	assert.Len(t, uploaders, 2)
+}
@@ -0,0 +1,183 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// ResolveMetadata implements domain.MetadataResolver: yt-dlp dumps the tweet's
+// JSON without downloading it. Photo-only tweets, and every tweet with
+// twitter.backend gallery-dl, are resolved by the fallback.
+func (d *TwitterDownloader) ResolveMetadata(ctx context.Context, download *domain.Download, files []string) (*domain.MediaMetadata, error) {
+	if err := d.Validate(download.URL); err != nil {
+		return nil, err
+	}
+	if download.Mode == domain.ModeProfile {
+		return nil, fmt.Errorf("metadata of profile downloads cannot be resolved")
+	}
+	fallback, _ := d.fallback.(domain.MetadataResolver)
+	if d.config.Backend == domain.TwitterBackendGalleryDL && fallback != nil {
+		return fallback.ResolveMetadata(ctx, download, files)
+	}
+
+	args := []string{"--dump-json", "--no-warnings", "--no-playlist"}
+	args = append(args, d.accounts.cookieArgs(d.firstAccount(download))...)
+	args = append(args, download.URL)
+
+	var stdout bytes.Buffer
+	stderr := newOutputTail()
+	cmd := d.ytdlp(ctx, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if fallback != nil && strings.Contains(stderr.String(), ytDLPNoVideoMarker) {
+			return fallback.ResolveMetadata(ctx, download, files)
+		}
+		return nil, toolError("yt-dlp", fmt.Errorf("%w, output: %s", err, stderr.String()), stderr.String())
+	}
+
+	infoData := firstJSONObject(stdout.Bytes())
+	if infoData == nil {
+		return nil, fmt.Errorf("yt-dlp dumped no metadata for %s", download.URL)
+	}
+	meta := d.buildRichMetadata(infoData, download.URL, files)
+	d.ApplyExtensions(meta)
+	return meta, nil
+}
+
+// firstJSONObject returns the first line of yt-dlp --dump-json output that is
+// a JSON object, or nil when there is none
+func firstJSONObject(output []byte) map[string]interface{} {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var infoData map[string]interface{}
+		if json.Unmarshal(line, &infoData) == nil {
+			return infoData
+		}
+	}
+	return nil
+}
+
+// ResolveMetadata implements domain.MetadataResolver: gallery-dl dumps the
+// post's JSON (-j) without downloading it, and the first file's metadata is
+// converted like the .json files of a download.
+func (d *GalleryDownloader) ResolveMetadata(ctx context.Context, download *domain.Download, files []string) (*domain.MediaMetadata, error) {
+	args := []string{"-j", "--range", "1"}
+	if cookieFile := d.resolveCookieFile(download.URL); cookieFile != "" {
+		args = append(args, "--cookies", cookieFile)
+	}
+	args = append(args, download.URL)
+
+	var stdout, stderr bytes.Buffer
+	cmd := CommandWithCancel(ctx, d.config.GalleryDLBinary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, toolError("gallery-dl", fmt.Errorf("%w, output: %s", err, stderr.String()), stderr.String())
+	}
+
+	infoData, err := parseGalleryDLMetadata(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	var meta *domain.MediaMetadata
+	if isGalleryDLTweet(infoData) {
+		meta = buildTweetMetadata(infoData, download.URL, files)
+	} else {
+		meta = d.buildRichMetadata(infoData, download.URL, files)
+	}
+	d.ApplyExtensions(meta)
+	return meta, nil
+}
+
+// parseGalleryDLMetadata returns the metadata of the first file in gallery-dl
+// -j output ([3, "<url>", {kwdict}]), or of the first directory ([2, {kwdict}])
+// when it lists no file
+func parseGalleryDLMetadata(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // tweet IDs exceed float64 precision
+	var messages [][]interface{}
+	if err := dec.Decode(&messages); err != nil {
+		return nil, fmt.Errorf("failed to parse gallery-dl output: %w", err)
+	}
+
+	var directory map[string]interface{}
+	for _, msg := range messages {
+		if len(msg) < 2 {
+			continue
+		}
+		msgType, _ := msg[0].(json.Number)
+		switch msgType.String() {
+		case "3":
+			if len(msg) >= 3 {
+				if kwdict, ok := msg[2].(map[string]interface{}); ok {
+					return kwdict, nil
+				}
+			}
+		case "2":
+			if kwdict, ok := msg[1].(map[string]interface{}); ok && directory == nil {
+				directory = kwdict
+			}
+		}
+	}
+	if directory == nil {
+		return nil, fmt.Errorf("gallery-dl listed no metadata")
+	}
+	return directory, nil
+}
+
+// ResolveMetadata implements domain.MetadataResolver: tdl exports the message
+// with the rest of its album, whose text the message takes when it has none
+// of its own.
+func (d *TelegramDownloader) ResolveMetadata(ctx context.Context, download *domain.Download, files []string) (*domain.MediaMetadata, error) {
+	if err := d.Validate(download.URL); err != nil {
+		return nil, err
+	}
+	if download.TDLProfile != "" && d.config.HasProfile(download.TDLProfile) {
+		d = d.ForProfile(download.TDLProfile)
+	}
+	channel := extractTelegramChannel(download.URL)
+	msgID, err := strconv.Atoi(extractTelegramID(download.URL))
+	if channel == "unknown" || err != nil {
+		return nil, fmt.Errorf("not a Telegram message URL: %s", download.URL)
+	}
+	if err := os.MkdirAll(d.incomingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create incoming directory: %w", err)
+	}
+
+	messages, err := d.exportMessages(ctx, channel, extractTelegramTopic(download.URL),
+		max(msgID-telegramAlbumWindow, 1), msgID+telegramAlbumWindow)
+	if err != nil {
+		return nil, toolError("tdl", err, err.Error())
+	}
+
+	var message *TelegramMessageData
+	album := simulatedMessages(messages, msgID, true)
+	for i := range album {
+		if album[i].ID == msgID {
+			message = &album[i]
+		}
+	}
+	if message == nil {
+		return nil, fmt.Errorf("message %d not found in the export of %s", msgID, channel)
+	}
+	for _, msg := range album {
+		if message.Text == "" && msg.Text != "" {
+			message.Text = msg.Text
+		}
+	}
+	return d.buildTelegramMetadata(download.URL, message, files), nil
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestTwitterResolveMetadata(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	script := `#!/bin/sh
+case "$*" in *--dump-json*) ;; *) exit 1 ;; esac
+echo '{"id":"100","title":"A post","description":"Hello","uploader":"Alice","uploader_id":"alice"}'
+`
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary}, t.TempDir(), t.TempDir(), t.TempDir(), nil)
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	meta, err := d.ResolveMetadata(context.Background(), download, []string{"/done/alice_100.mp4"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", meta.Description)
+	assert.Equal(t, "Alice", meta.Uploader)
+	assert.Equal(t, "alice", meta.UploaderID)
+	assert.Equal(t, []string{"/done/alice_100.mp4"}, meta.Files)
+}
+
+func TestTelegramResolveMetadata_TakesAlbumText(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tdl")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then out="$2"; fi
+  shift
+done
+cat > "$out" <<'EOF'
+{"id":1,"messages":[
+{"id":101,"type":"message","file":"b.jpg","text":"caption","raw":{"GroupedID":9}},
+{"id":102,"type":"message","file":"c.mp4","text":"","raw":{"GroupedID":9}}
+]}
+EOF
+`
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+	config := &domain.TelegramConfig{TDLBinary: binary, Profile: "default", StorageType: "bolt", StoragePath: dir}
+	d := NewTelegramDownloader(config, dir, dir, dir, nil)
+
+	download := domain.NewDownload("https://t.me/news/102", domain.PlatformTelegram, domain.ModeSingle)
+	meta, err := d.ResolveMetadata(context.Background(), download, []string{filepath.Join(dir, "c.mp4")})
+	require.NoError(t, err)
+	assert.Equal(t, "caption", meta.Description)
+	assert.Equal(t, "102", meta.ID)
+}
+
+func TestParseGalleryDLMetadata(t *testing.T) {
+	meta, err := parseGalleryDLMetadata([]byte(`[
+  [2, {"category": "site", "description": "directory"}],
+  [3, "https://example.com/a.jpg", {"category": "site", "description": "file", "author": "someone"}]
+]`))
+	require.NoError(t, err)
+	assert.Equal(t, "file", meta["description"])
+
+	meta, err = parseGalleryDLMetadata([]byte(`[[2, {"description": "directory"}]]`))
+	require.NoError(t, err)
+	assert.Equal(t, "directory", meta["description"])
+
+	_, err = parseGalleryDLMetadata([]byte(`[]`))
+	assert.Error(t, err)
+	_, err = parseGalleryDLMetadata([]byte("not json"))
+	assert.Error(t, err)
+}
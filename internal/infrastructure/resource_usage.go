@@ -0,0 +1,27 @@
+package infrastructure
+
+import (
+	"context"
+	"os/exec"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// recordProcessUsage adds the resource usage of cmd, which has exited, to the
+// usage meter of ctx (see domain.WithUsageMeter). started is when cmd was
+// started. The CPU time and peak RSS come from the process state, so they
+// cover the children the tool waited for (ffmpeg and the like) too.
+func recordProcessUsage(ctx context.Context, cmd *exec.Cmd, started time.Time) {
+	meter := domain.UsageMeterFrom(ctx)
+	if meter == nil || cmd.ProcessState == nil {
+		return
+	}
+	meter.Record(processUsage(cmd.ProcessState.UserTime()+cmd.ProcessState.SystemTime(),
+		cmd.ProcessState.SysUsage(), time.Since(started)))
+}
+
+// processUsage builds the usage of one process from its rusage
+func processUsage(cpu time.Duration, sysUsage interface{}, wall time.Duration) domain.ResourceUsage {
+	return domain.ResourceUsage{Processes: 1, CPUTime: cpu, WallTime: wall, PeakRSS: peakRSS(sysUsage)}
+}
@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestRecordProcessUsage(t *testing.T) {
+	meter := &domain.UsageMeter{}
+	ctx := domain.WithUsageMeter(context.Background(), meter)
+
+	for i := 0; i < 2; i++ {
+		cmd := CommandWithCancel(ctx, "sh", "-c", "i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done")
+		started := time.Now()
+		require.NoError(t, cmd.Run())
+		recordProcessUsage(ctx, cmd, started)
+	}
+
+	usage := meter.Usage()
+	assert.Equal(t, 2, usage.Processes)
+	assert.Greater(t, usage.CPUTime, time.Duration(0))
+	assert.GreaterOrEqual(t, usage.WallTime, usage.CPUTime/2)
+	assert.Greater(t, usage.PeakRSS, int64(0))
+}
+
+func TestRecordProcessUsage_WithoutMeter(t *testing.T) {
+	cmd := CommandWithCancel(context.Background(), "true")
+	require.NoError(t, cmd.Run())
+	recordProcessUsage(context.Background(), cmd, time.Now())
+
+	notStarted := CommandWithCancel(context.Background(), "true")
+	meter := &domain.UsageMeter{}
+	recordProcessUsage(domain.WithUsageMeter(context.Background(), meter), notStarted, time.Now())
+	assert.Zero(t, meter.Usage().Processes)
+}
+
+func TestProcessUsage(t *testing.T) {
+	usage := processUsage(time.Second, nil, time.Minute)
+	assert.Equal(t, domain.ResourceUsage{Processes: 1, CPUTime: time.Second, WallTime: time.Minute}, usage)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package infrastructure
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// peakRSS returns the peak resident set size in bytes of a process from its
+// rusage, 0 when unknown
+func peakRSS(sysUsage interface{}) int64 {
+	rusage, ok := sysUsage.(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// ru_maxrss is in kilobytes on Linux and in bytes on macOS
+	if runtime.GOOS == "darwin" {
+		return int64(rusage.Maxrss)
+	}
+	return int64(rusage.Maxrss) * 1024
+}
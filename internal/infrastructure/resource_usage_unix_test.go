@@ -0,0 +1,20 @@
+//go:build !windows
+
+package infrastructure
+
+import (
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeakRSS(t *testing.T) {
+	want := int64(2048 * 1024)
+	if runtime.GOOS == "darwin" {
+		want = 2048
+	}
+	assert.Equal(t, want, peakRSS(&syscall.Rusage{Maxrss: 2048}))
+	assert.Zero(t, peakRSS(nil))
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package infrastructure
+
+// peakRSS returns 0: the process state on Windows has CPU times but no memory
+// usage
+func peakRSS(sysUsage interface{}) int64 {
+	return 0
+}
@@ -0,0 +1,290 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// telegramAlbumWindow is how many message IDs around a message a simulated
+// group download exports to find the rest of its album (albums hold at most
+// 10 media)
+const telegramAlbumWindow = 9
+
+// Simulate implements domain.Simulator: yt-dlp lists the tweet, or the tweets
+// of a profile, with --simulate --dump-json and the arguments of the download.
+// Profiles are listed flat, without fetching each tweet.
+func (d *TwitterDownloader) Simulate(ctx context.Context, download *domain.Download) (*domain.Simulation, error) {
+	if err := d.Validate(download.URL); err != nil {
+		return nil, err
+	}
+	if d.config.Backend == domain.TwitterBackendGalleryDL {
+		return nil, fmt.Errorf("simulation is not supported with twitter.backend %s", domain.TwitterBackendGalleryDL)
+	}
+	if download.Account != "" && !d.config.HasAccount(download.Account) {
+		return nil, fmt.Errorf("unknown X account: %s", download.Account)
+	}
+
+	var args, probeArgs []string
+	if download.Mode == domain.ModeProfile {
+		if domain.XProfileUsername(download.URL) == "" {
+			return nil, fmt.Errorf("not an X profile URL: %s", download.URL)
+		}
+		args = d.ytdlpProfileArgs(download, filepath.Join(d.incomingDir, "profile_"+download.ID))
+		probeArgs = []string{"--flat-playlist"}
+	} else {
+		args = d.ytdlpTweetArgs(download)
+	}
+	account := d.firstAccount(download)
+	args = append(args, d.accounts.cookieArgs(account)...)
+	args = append(args, download.URL)
+	probeArgs = append(append(probeArgs, "--simulate", "--dump-json"), args...)
+
+	sim := &domain.Simulation{
+		URL:      download.URL,
+		Platform: domain.PlatformX,
+		Mode:     download.Mode,
+		Tool:     "yt-dlp",
+		Command:  d.ytdlpCommandLine(args...),
+		Probe:    d.ytdlpCommandLine(probeArgs...),
+		Config:   d.simulationConfig(download, account),
+		Items:    []domain.SimulatedItem{},
+	}
+
+	var stdout bytes.Buffer
+	stderr := newOutputTail()
+	cmd := d.ytdlp(ctx, probeArgs...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	sim.Items = parseYTDLPSimulation(stdout.Bytes())
+	if err != nil {
+		sim.Error = lastOutputLine(stderr.String(), err)
+		sim.ErrorCode = classifyToolFailure(err, stderr.String())
+		if d.fallback != nil && strings.Contains(stderr.String(), ytDLPNoVideoMarker) {
+			sim.Error += " (the download would fall back to gallery-dl)"
+		}
+	}
+	return sim, nil
+}
+
+// simulationConfig returns the settings a download of the tweet would use
+func (d *TwitterDownloader) simulationConfig(download *domain.Download, account string) map[string]string {
+	config := map[string]string{
+		"backend":        domain.TwitterBackendYTDLP,
+		"incoming_dir":   d.incomingDir,
+		"completed_dir":  d.completedDir,
+		"write_metadata": strconv.FormatBool(d.config.WriteMetadata),
+	}
+	if account != "" {
+		config["account"] = account
+	}
+	if args := d.accounts.cookieArgs(account); len(args) == 2 {
+		config["cookie_file"] = args[1]
+	}
+	setIfNotEmpty(config, "client", d.accounts.clientProfile(account))
+	if download.AllVariants {
+		config["all_variants"] = "true"
+	}
+	if d.native != nil {
+		config["native_extractor"] = "enabled"
+	}
+	if d.fallback != nil {
+		config["fallback"] = "gallery-dl"
+	}
+	layoutConfig(config, download.Platform, &d.MetadataExtensions, &d.BandwidthLimit)
+	return config
+}
+
+// Simulate implements domain.Simulator: tdl exports the messages the download
+// covers, with their file names, instead of downloading their media. A group
+// download exports the messages around its message and keeps its album.
+func (d *TelegramDownloader) Simulate(ctx context.Context, download *domain.Download) (*domain.Simulation, error) {
+	if err := d.Validate(download.URL); err != nil {
+		return nil, err
+	}
+	if download.TDLProfile != "" {
+		if !d.config.HasProfile(download.TDLProfile) {
+			return nil, fmt.Errorf("unknown Telegram profile: %s", download.TDLProfile)
+		}
+		d = d.ForProfile(download.TDLProfile)
+	}
+	channel := extractTelegramChannel(download.URL)
+	msgID, err := strconv.Atoi(extractTelegramID(download.URL))
+	if channel == "unknown" || err != nil {
+		return nil, fmt.Errorf("not a Telegram message URL: %s", download.URL)
+	}
+	if err := os.MkdirAll(d.incomingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create incoming directory: %w", err)
+	}
+
+	start, end, topicID := msgID, msgID, 0
+	group := d.usesGroup(download)
+	switch {
+	case download.IsRange():
+		start, end, topicID = download.RangeStart, download.RangeEnd, extractTelegramTopic(download.URL)
+	case group:
+		start, end = max(msgID-telegramAlbumWindow, 1), msgID+telegramAlbumWindow
+	}
+
+	args := d.buildTDLCommand(download, download.MessageURLs(), filepath.Join(d.incomingDir, "temp_"+download.ID))
+	sim := &domain.Simulation{
+		URL:      download.URL,
+		Platform: domain.PlatformTelegram,
+		Mode:     download.Mode,
+		Tool:     "tdl",
+		Command:  d.tdlCommandLine(args...),
+		Probe:    d.tdlCommandLine(d.tdlExportArgs(channel, topicID, start, end, d.exportFile(channel, start, end))...),
+		Config:   d.simulationConfig(download, group),
+		Items:    []domain.SimulatedItem{},
+	}
+
+	messages, err := d.exportMessages(ctx, channel, topicID, start, end)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		sim.Error = err.Error()
+		sim.ErrorCode = classifyToolFailure(err, err.Error())
+		return sim, nil
+	}
+	if !download.IsRange() {
+		messages = simulatedMessages(messages, msgID, group)
+	}
+	for _, msg := range messages {
+		if msg.File == "" {
+			continue
+		}
+		title, _, _ := strings.Cut(strings.TrimSpace(msg.Text), "\n")
+		sim.Items = append(sim.Items, domain.SimulatedItem{
+			ID:       strconv.Itoa(msg.ID),
+			URL:      domain.TelegramMessageURL(download.URL, msg.ID),
+			Title:    title,
+			Filename: msg.File,
+		})
+	}
+	if len(sim.Items) == 0 {
+		sim.Error = "no media in the exported messages"
+	}
+	return sim, nil
+}
+
+// simulatedMessages returns the message msgID of an export, with the rest of
+// its album when the download fetches whole albums
+func simulatedMessages(messages []TelegramMessageData, msgID int, group bool) []TelegramMessageData {
+	var groupedID string
+	for _, msg := range messages {
+		if msg.ID == msgID {
+			groupedID = formatGroupedID(msg.Raw)
+		}
+	}
+	var selected []TelegramMessageData
+	for _, msg := range messages {
+		if msg.ID == msgID || (group && groupedID != "" && formatGroupedID(msg.Raw) == groupedID) {
+			selected = append(selected, msg)
+		}
+	}
+	return selected
+}
+
+// simulationConfig returns the settings a download of the message would use
+func (d *TelegramDownloader) simulationConfig(download *domain.Download, group bool) map[string]string {
+	config := map[string]string{
+		"profile":       d.config.Profile,
+		"storage":       fmt.Sprintf("type=%s,path=%s", d.config.StorageType, d.config.StoragePath),
+		"group":         strconv.FormatBool(group),
+		"incoming_dir":  d.incomingDir,
+		"completed_dir": d.completedDir,
+	}
+	setIfNotEmpty(config, "client", d.config.ClientProfile())
+	setIfNotEmpty(config, "extra_params", d.config.ExtraParams)
+	if d.config.Takeout {
+		config["takeout"] = "true"
+	}
+	if d.config.RewriteExt {
+		config["rewrite_ext"] = "true"
+	}
+	if download.IsRange() {
+		config["range"] = fmt.Sprintf("%d-%d", download.RangeStart, download.RangeEnd)
+	}
+	layoutConfig(config, download.Platform, &d.MetadataExtensions, &d.BandwidthLimit)
+	return config
+}
+
+// layoutConfig adds the rate limit, filename template and organize_by
+// settings of a downloader to a simulation's settings
+func layoutConfig(config map[string]string, platform domain.Platform, extensions *MetadataExtensions, bandwidth *BandwidthLimit) {
+	if limit := bandwidth.rateLimitFor(platform); limit > 0 {
+		config["rate_limit"] = domain.FormatBytes(limit) + "/s"
+	}
+	setIfNotEmpty(config, "filename_template", extensions.FilenameTemplate)
+	setIfNotEmpty(config, "organize_by", extensions.OrganizeBy)
+}
+
+// setIfNotEmpty sets config[key] to a non-empty value
+func setIfNotEmpty(config map[string]string, key, value string) {
+	if value != "" {
+		config[key] = value
+	}
+}
+
+// ytdlpSimulatedEntry is the part of a yt-dlp --dump-json line a simulation
+// reports
+type ytdlpSimulatedEntry struct {
+	ID             string  `json:"id"`
+	Title          string  `json:"title"`
+	Uploader       string  `json:"uploader"`
+	WebpageURL     string  `json:"webpage_url"`
+	URL            string  `json:"url"`
+	Filename       string  `json:"filename"`
+	Filesize       float64 `json:"filesize"`
+	FilesizeApprox float64 `json:"filesize_approx"`
+}
+
+// parseYTDLPSimulation parses the JSON lines of yt-dlp --dump-json; lines
+// that are not JSON objects are skipped
+func parseYTDLPSimulation(output []byte) []domain.SimulatedItem {
+	items := []domain.SimulatedItem{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var entry ytdlpSimulatedEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		item := domain.SimulatedItem{
+			ID:       entry.ID,
+			URL:      entry.WebpageURL,
+			Title:    entry.Title,
+			Uploader: entry.Uploader,
+			Filename: filepath.Base(entry.Filename),
+			Size:     int64(entry.Filesize),
+		}
+		if item.URL == "" {
+			item.URL = entry.URL
+		}
+		if entry.Filename == "" {
+			item.Filename = ""
+		}
+		if item.Size == 0 {
+			item.Size = int64(entry.FilesizeApprox)
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,133 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// fakeSimulateYTDLP prints one --dump-json line, with the arguments it was
+// run with on stderr
+const fakeSimulateYTDLP = `#!/bin/sh
+echo "$@" >&2
+echo '{"id":"100","title":"A post","uploader":"Alice","webpage_url":"https://x.com/alice/status/100","filename":"/in/alice_100.mp4","filesize_approx":2048}'
+`
+
+func TestTwitterSimulate(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte(fakeSimulateYTDLP), 0755))
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary, WriteMetadata: true},
+		t.TempDir(), t.TempDir(), t.TempDir(), nil)
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	sim, err := d.Simulate(context.Background(), download)
+	require.NoError(t, err)
+	assert.Equal(t, "yt-dlp", sim.Tool)
+	assert.Contains(t, sim.Probe, "--simulate --dump-json")
+	assert.NotContains(t, sim.Command, "--simulate")
+	assert.Contains(t, sim.Command, "https://x.com/alice/status/100")
+	assert.Equal(t, "true", sim.Config["write_metadata"])
+	assert.Empty(t, sim.Error)
+	assert.Equal(t, []domain.SimulatedItem{{
+		ID: "100", URL: "https://x.com/alice/status/100", Title: "A post", Uploader: "Alice",
+		Filename: "alice_100.mp4", Size: 2048,
+	}}, sim.Items)
+}
+
+func TestTwitterSimulate_ReportsToolFailure(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	script := "#!/bin/sh\necho 'ERROR: [twitter] 100: This tweet is unavailable' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary}, t.TempDir(), t.TempDir(), t.TempDir(), nil)
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	sim, err := d.Simulate(context.Background(), download)
+	require.NoError(t, err, "a failed listing is reported in the simulation")
+	assert.Empty(t, sim.Items)
+	assert.Contains(t, sim.Error, "This tweet is unavailable")
+	assert.NotEmpty(t, sim.ErrorCode)
+}
+
+func TestTwitterSimulate_ProfileNeedsProfileURL(t *testing.T) {
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: "/nonexistent/yt-dlp"},
+		t.TempDir(), t.TempDir(), t.TempDir(), nil)
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeProfile)
+	_, err := d.Simulate(context.Background(), download)
+	assert.Error(t, err)
+}
+
+func TestTelegramSimulate(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tdl")
+	// Writes an export of an album (101, 102), a text message and another
+	// album to the -o file
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then out="$2"; fi
+  shift
+done
+cat > "$out" <<'EOF'
+{"id":1,"messages":[
+{"id":100,"type":"message","file":"a.jpg","text":"other album","raw":{"GroupedID":7}},
+{"id":101,"type":"message","file":"b.jpg","text":"caption\nmore","raw":{"GroupedID":9}},
+{"id":102,"type":"message","file":"c.mp4","text":"","raw":{"GroupedID":9}},
+{"id":103,"type":"message","file":"","text":"just text"}
+]}
+EOF
+`
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0755))
+	config := &domain.TelegramConfig{TDLBinary: binary, Profile: "default", StorageType: "bolt", StoragePath: dir}
+
+	t.Run("group", func(t *testing.T) {
+		d := NewTelegramDownloader(config, dir, dir, dir, nil)
+		download := domain.NewDownload("https://t.me/news/101", domain.PlatformTelegram, domain.ModeGroup)
+		sim, err := d.Simulate(context.Background(), download)
+		require.NoError(t, err)
+		assert.Equal(t, "tdl", sim.Tool)
+		assert.Contains(t, sim.Command, "https://t.me/news/101")
+		assert.Contains(t, sim.Probe, "chat export")
+		assert.Contains(t, sim.Probe, "92,110", "the album window around the message")
+		assert.Equal(t, "true", sim.Config["group"])
+		require.Len(t, sim.Items, 2, "the message and the rest of its album")
+		assert.Equal(t, domain.SimulatedItem{ID: "101", URL: "https://t.me/news/101", Title: "caption", Filename: "b.jpg"}, sim.Items[0])
+		assert.Equal(t, "102", sim.Items[1].ID)
+		assert.Empty(t, sim.Error)
+	})
+
+	t.Run("single", func(t *testing.T) {
+		d := NewTelegramDownloader(config, dir, dir, dir, nil)
+		download := domain.NewDownload("https://t.me/news/101", domain.PlatformTelegram, domain.ModeSingle)
+		sim, err := d.Simulate(context.Background(), download)
+		require.NoError(t, err)
+		assert.Equal(t, "false", sim.Config["group"])
+		require.Len(t, sim.Items, 1)
+		assert.Equal(t, "101", sim.Items[0].ID)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		d := NewTelegramDownloader(config, dir, dir, dir, nil)
+		download := domain.NewDownload("https://t.me/news/100", domain.PlatformTelegram, domain.ModeDefault)
+		download.RangeStart, download.RangeEnd = 100, 103
+		sim, err := d.Simulate(context.Background(), download)
+		require.NoError(t, err)
+		assert.Equal(t, "100-103", sim.Config["range"])
+		assert.Len(t, sim.Items, 3, "every message with media in the range")
+	})
+}
+
+func TestParseYTDLPSimulation(t *testing.T) {
+	output := "WARNING: something\n" +
+		`{"id":"1","url":"https://video.twimg.com/1.mp4","filesize":10}` + "\n" +
+		"{not json\n" +
+		`{"id":"2","_type":"url","url":"https://x.com/a/status/2"}` + "\n"
+	items := parseYTDLPSimulation([]byte(output))
+	require.Len(t, items, 2)
+	assert.Equal(t, domain.SimulatedItem{ID: "1", URL: "https://video.twimg.com/1.mp4", Size: 10}, items[0])
+	assert.Equal(t, "https://x.com/a/status/2", items[1].URL, "flat playlist entries carry a url")
+	assert.Empty(t, items[1].Filename)
+}
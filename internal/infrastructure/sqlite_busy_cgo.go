@@ -0,0 +1,19 @@
+//go:build cgo
+
+package infrastructure
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// isSQLiteBusy reports whether err is a transient SQLITE_BUSY or
+// SQLITE_LOCKED ("database is locked") error
+func isSQLiteBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+//go:build cgo
+
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+// sqliteBusyError is a SQLITE_BUSY error as the driver returns it
+var sqliteBusyError error = sqlite3.Error{Code: sqlite3.ErrBusy}
+
+func TestIsSQLiteBusy(t *testing.T) {
+	assert.True(t, isSQLiteBusy(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	assert.True(t, isSQLiteBusy(sqlite3.Error{Code: sqlite3.ErrLocked}))
+	assert.False(t, isSQLiteBusy(sqlite3.Error{Code: sqlite3.ErrConstraint}))
+	assert.False(t, isSQLiteBusy(nil))
+}
@@ -0,0 +1,19 @@
+//go:build !cgo
+
+package infrastructure
+
+import "strings"
+
+// isSQLiteBusy reports whether err is a transient SQLITE_BUSY or
+// SQLITE_LOCKED error. Without cgo, go-sqlite3 has no error codes to compare,
+// so the message SQLite gives them is matched instead.
+func isSQLiteBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "database is locked") ||
+		strings.Contains(message, "database table is locked") ||
+		strings.Contains(message, "SQLITE_BUSY") ||
+		strings.Contains(message, "SQLITE_LOCKED")
+}
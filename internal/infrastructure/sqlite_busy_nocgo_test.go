@@ -0,0 +1,21 @@
+//go:build !cgo
+
+package infrastructure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sqliteBusyError is a SQLITE_BUSY error as the driver reports it
+var sqliteBusyError = errors.New("database is locked")
+
+func TestIsSQLiteBusy(t *testing.T) {
+	assert.True(t, isSQLiteBusy(errors.New("database is locked")))
+	assert.True(t, isSQLiteBusy(errors.New("database table is locked: downloads")))
+	assert.True(t, isSQLiteBusy(errors.New("SQLITE_BUSY: cannot commit")))
+	assert.False(t, isSQLiteBusy(errors.New("UNIQUE constraint failed")))
+	assert.False(t, isSQLiteBusy(nil))
+}
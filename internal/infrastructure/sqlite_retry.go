@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sqliteBusyTimeout is how long SQLite itself waits for a lock before
+	// returning SQLITE_BUSY (the _busy_timeout DSN option)
+	sqliteBusyTimeout = 5 * time.Second
+
+	// sqliteBusyRetries is how many more times withBusyRetry runs a write
+	// that still failed with SQLITE_BUSY/SQLITE_LOCKED after the busy timeout
+	sqliteBusyRetries = 3
+
+	// sqliteBusyBackoff is the delay before the first retry, doubled each time
+	sqliteBusyBackoff = 100 * time.Millisecond
+
+	// sqliteMaxOpenConns bounds the connection pool. WAL lets readers run
+	// alongside the single writer; more connections only add lock contention.
+	sqliteMaxOpenConns = 4
+)
+
+// sqliteDSN adds the connection options used for every repository
+// connection: WAL journaling so readers don't block the writer, a busy
+// timeout so concurrent writers wait instead of failing, and IMMEDIATE
+// transactions so a transaction that reads before writing can't fail on the
+// lock upgrade (which SQLite reports as SQLITE_BUSY without waiting).
+func sqliteDSN(dbPath string) string {
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	return dbPath + sep + "_journal_mode=WAL" +
+		"&_busy_timeout=" + strconv.FormatInt(sqliteBusyTimeout.Milliseconds(), 10) +
+		"&_txlock=immediate"
+}
+
+// sqliteReadOnlyDSN opens dbPath read-only as a file: URI. The busy timeout
+// lets reads wait out a checkpoint by a running server.
+func sqliteReadOnlyDSN(dbPath string) string {
+	return "file:" + dbPath + "?mode=ro" +
+		"&_busy_timeout=" + strconv.FormatInt(sqliteBusyTimeout.Milliseconds(), 10)
+}
+
+// withBusyRetry runs a database write, retrying with exponential backoff while
+// it fails with a transient SQLITE_BUSY/SQLITE_LOCKED error (see isSQLiteBusy)
+func withBusyRetry(write func() error) error {
+	delay := sqliteBusyBackoff
+	err := write()
+	for attempt := 0; attempt < sqliteBusyRetries && isSQLiteBusy(err); attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+		err = write()
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "/tmp/q.db?_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate", sqliteDSN("/tmp/q.db"))
+	assert.Equal(t, "file:q.db?cache=shared&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate", sqliteDSN("file:q.db?cache=shared"))
+}
+
+func TestWithBusyRetry(t *testing.T) {
+	busy := sqliteBusyError
+	assert.True(t, isSQLiteBusy(fmt.Errorf("update: %w", busy)))
+	assert.False(t, isSQLiteBusy(errors.New("database is corrupt")))
+
+	calls := 0
+	err := withBusyRetry(func() error {
+		calls++
+		if calls < 3 {
+			return busy
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// Other errors are returned at once
+	calls = 0
+	err = withBusyRetry(func() error {
+		calls++
+		return errors.New("constraint failed")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	// Gives up after sqliteBusyRetries retries
+	calls = 0
+	err = withBusyRetry(func() error {
+		calls++
+		return busy
+	})
+	assert.True(t, isSQLiteBusy(err))
+	assert.Equal(t, sqliteBusyRetries+1, calls)
+}
+
+func TestSQLiteRepository_ConcurrentWrites(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	var journalMode string
+	require.NoError(t, repo.db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	assert.Equal(t, "wal", journalMode)
+
+	downloads := make([]*domain.Download, 8)
+	for i := range downloads {
+		downloads[i] = domain.NewDownload(fmt.Sprintf("https://x.com/u/status/%d", i), domain.PlatformX, domain.ModeDefault)
+		require.NoError(t, repo.Create(downloads[i]))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(downloads)*40)
+	for _, dl := range downloads {
+		wg.Add(1)
+		go func(dl *domain.Download) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				copied := *dl
+				copied.Progress = float64(i * 5)
+				errs <- repo.UpdateProgress(&copied)
+				errs <- repo.Update(&copied)
+			}
+		}(dl)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,373 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// Storage keeps the files of completed downloads (storage.backend). Files
+// are addressed by key, their path relative to completed/ with forward
+// slashes. Store returns the URL a file was uploaded to, or "" when the file
+// is kept in completed/ only. Open returns an error wrapping os.ErrNotExist
+// when nothing is stored under key; deleting a missing key is no error.
+type Storage interface {
+	Store(ctx context.Context, file, key string) (string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// NewStorage creates the storage backend of config
+func NewStorage(config *domain.StorageConfig) (Storage, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	switch config.Backend {
+	case domain.StorageS3:
+		return NewS3Storage(&config.S3), nil
+	case domain.StorageWebDAV:
+		return NewWebDAVStorage(&config.WebDAV), nil
+	}
+	return LocalStorage{}, nil
+}
+
+// LocalStorage is the default backend: files stay in completed/
+type LocalStorage struct{}
+
+// Store implements Storage
+func (LocalStorage) Store(ctx context.Context, file, key string) (string, error) {
+	return "", nil
+}
+
+// Open implements Storage: nothing is stored besides completed/
+func (LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("%s: %w", key, os.ErrNotExist)
+}
+
+// Delete implements Storage
+func (LocalStorage) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+// S3Storage uploads files to an S3-compatible bucket (AWS S3, Backblaze B2,
+// MinIO, ...) with a single signed PUT per file, so objects are limited to the
+// 5GB of a single PUT.
+type S3Storage struct {
+	config *domain.S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Storage creates an S3 storage backend
+func NewS3Storage(config *domain.S3Config) *S3Storage {
+	return &S3Storage{config: config, client: &http.Client{}, now: time.Now}
+}
+
+// Store implements Storage
+func (s *S3Storage) Store(ctx context.Context, file, key string) (string, error) {
+	payloadHash, err := hashFile(file)
+	if err != nil {
+		return "", err
+	}
+	objectURL := s.objectURL(key)
+	req, err := newUploadRequest(ctx, file, objectURL)
+	if err != nil {
+		return "", err
+	}
+	s.sign(req, hex.EncodeToString(payloadHash))
+	if err := doUpload(s.client, req); err != nil {
+		return "", err
+	}
+	if s.config.PublicURL != "" {
+		return strings.TrimRight(s.config.PublicURL, "/") + "/" + escapeKey(s.objectKey(key)), nil
+	}
+	return objectURL, nil
+}
+
+// Open implements Storage
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, emptyPayloadHash)
+	return doFetch(s.client, req)
+}
+
+// Delete implements Storage
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, emptyPayloadHash)
+	return doDelete(s.client, req)
+}
+
+// objectKey returns the object key of a file: storage.s3.prefix + key
+func (s *S3Storage) objectKey(key string) string {
+	return s.config.Prefix + key
+}
+
+// objectURL returns the URL of a file's object, addressing the bucket by
+// virtual host (bucket.endpoint) or by path (endpoint/bucket)
+func (s *S3Storage) objectURL(key string) string {
+	endpoint, _ := url.Parse(strings.TrimRight(s.config.Endpoint, "/"))
+	escaped := escapeKey(s.objectKey(key))
+	if s.config.PathStyle {
+		return fmt.Sprintf("%s://%s/%s/%s", endpoint.Scheme, endpoint.Host, escapeKey(s.config.Bucket), escaped)
+	}
+	return fmt.Sprintf("%s://%s.%s/%s", endpoint.Scheme, s.config.Bucket, endpoint.Host, escaped)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req, signing
+// the host and every header set on req. req must have no query string.
+func (s *S3Storage) sign(req *http.Request, payloadHash string) {
+	amzDate := s.now().UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery,
+		canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	region := s.config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// WebDAVStorage uploads files to a WebDAV server, creating the collections
+// (directories) of their keys as needed
+type WebDAVStorage struct {
+	config  *domain.WebDAVConfig
+	client  *http.Client
+	created sync.Map // Collections known to exist
+}
+
+// NewWebDAVStorage creates a WebDAV storage backend
+func NewWebDAVStorage(config *domain.WebDAVConfig) *WebDAVStorage {
+	return &WebDAVStorage{config: config, client: &http.Client{}}
+}
+
+// Store implements Storage
+func (w *WebDAVStorage) Store(ctx context.Context, file, key string) (string, error) {
+	base := strings.TrimRight(w.config.URL, "/")
+	if err := w.makeCollections(ctx, base, path.Dir(key)); err != nil {
+		return "", err
+	}
+	fileURL := w.fileURL(key)
+	req, err := newUploadRequest(ctx, file, fileURL)
+	if err != nil {
+		return "", err
+	}
+	w.authorize(req)
+	if err := doUpload(w.client, req); err != nil {
+		return "", err
+	}
+	return fileURL, nil
+}
+
+// Open implements Storage
+func (w *WebDAVStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.fileURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	w.authorize(req)
+	return doFetch(w.client, req)
+}
+
+// Delete implements Storage
+func (w *WebDAVStorage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, w.fileURL(key), nil)
+	if err != nil {
+		return err
+	}
+	w.authorize(req)
+	return doDelete(w.client, req)
+}
+
+// fileURL returns the URL of the file stored under key
+func (w *WebDAVStorage) fileURL(key string) string {
+	return strings.TrimRight(w.config.URL, "/") + "/" + escapeKey(key)
+}
+
+// makeCollections creates each collection of dir under base, parents first
+func (w *WebDAVStorage) makeCollections(ctx context.Context, base, dir string) error {
+	if dir == "." || dir == "/" {
+		return nil
+	}
+	collection := ""
+	for _, part := range strings.Split(dir, "/") {
+		collection += "/" + escapeKey(part)
+		if _, ok := w.created.Load(collection); ok {
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, "MKCOL", base+collection+"/", nil)
+		if err != nil {
+			return err
+		}
+		w.authorize(req)
+		resp, err := w.client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 Method Not Allowed: the collection exists already
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("MKCOL %s: %s", collection, resp.Status)
+		}
+		w.created.Store(collection, true)
+	}
+	return nil
+}
+
+func (w *WebDAVStorage) authorize(req *http.Request) {
+	if w.config.Username != "" {
+		req.SetBasicAuth(w.config.Username, w.config.Password)
+	}
+}
+
+// newUploadRequest creates a PUT of file to fileURL
+func newUploadRequest(ctx context.Context, file, fileURL string) (*http.Request, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fileURL, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	req.ContentLength = info.Size()
+	contentType := mime.TypeByExtension(filepath.Ext(file))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// doUpload sends an upload request. Responses other than 2xx are errors
+// carrying the start of the response body, where S3 and WebDAV servers
+// explain what went wrong.
+func doUpload(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload to %s failed: %s %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// doFetch sends a download request and returns the response body. 404 is
+// reported as os.ErrNotExist.
+func doFetch(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", req.URL.Redacted(), os.ErrNotExist)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("download from %s failed: %s %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp.Body, nil
+}
+
+// doDelete sends a delete request. 404 means the file is gone already.
+func doDelete(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("delete of %s failed: %s %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// escapeKey percent-encodes every byte of key outside A-Z, a-z, 0-9, "-._~"
+// and "/", as S3 signatures require
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Register GIF decoding (first frame)
+	"image/jpeg"
+	_ "image/png" // Register PNG decoding
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// thumbnailTimeout bounds the ffmpeg run that grabs a video frame
+const thumbnailTimeout = 30 * time.Second
+
+// Thumbnailer makes JPEG thumbnails of downloaded media on request and caches
+// them under dir. JPEG, PNG and GIF images are scaled in-process; videos and
+// WebP images need ffmpeg. A cached thumbnail is keyed by the file's path,
+// size and modification time, so a replaced file gets a new thumbnail.
+type Thumbnailer struct {
+	dir     string
+	maxSize int
+	ffmpeg  string
+}
+
+// NewThumbnailer creates a thumbnailer caching under dir (typically
+// base_dir/thumbnails) whose thumbnails fit in maxSize x maxSize pixels
+func NewThumbnailer(dir string, maxSize int, ffmpegBinary string) *Thumbnailer {
+	return &Thumbnailer{dir: dir, maxSize: maxSize, ffmpeg: ffmpegBinary}
+}
+
+// Thumbnail returns the path of the thumbnail of a media file, making it on
+// the first request. Files it cannot thumbnail return a
+// *domain.UnsupportedThumbnailError.
+func (t *Thumbnailer) Thumbnail(ctx context.Context, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d|%d", path, info.Size(), info.ModTime().UnixNano(), t.maxSize)))
+	key := hex.EncodeToString(sum[:])
+	thumbPath := filepath.Join(t.dir, key[:2], key+".jpg")
+	if FileExists(thumbPath) {
+		return thumbPath, nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	useFFmpeg := domain.MediaTypeOf(path) == domain.MediaTypeVideo || ext == ".webp"
+	if domain.MediaTypeOf(path) != domain.MediaTypeImage && !useFFmpeg {
+		return "", &domain.UnsupportedThumbnailError{Path: path, Reason: "not an image or video"}
+	}
+	if useFFmpeg {
+		if _, err := exec.LookPath(t.ffmpeg); err != nil {
+			return "", &domain.UnsupportedThumbnailError{Path: path, Reason: "ffmpeg not found"}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(thumbPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+	// Write next to the final path and rename, so concurrent requests for
+	// the same file never serve a partial thumbnail
+	tmp, err := os.CreateTemp(filepath.Dir(thumbPath), "thumb-*.jpg")
+	if err != nil {
+		return "", fmt.Errorf("failed to create thumbnail: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if useFFmpeg {
+		err = t.ffmpegThumbnail(ctx, path, tmp.Name())
+	} else {
+		err = t.imageThumbnail(path, tmp.Name())
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), thumbPath); err != nil {
+		return "", fmt.Errorf("failed to save thumbnail: %w", err)
+	}
+	return thumbPath, nil
+}
+
+// imageThumbnail scales a JPEG, PNG or GIF image down to fit maxSize and
+// writes it as JPEG
+func (t *Thumbnailer) imageThumbnail(path, out string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+
+	dst, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	if err := jpeg.Encode(dst, scaleDown(img, t.maxSize), &jpeg.Options{Quality: 85}); err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return dst.Close()
+}
+
+// ffmpegThumbnail writes a representative frame of a video (or a WebP image)
+// scaled to fit maxSize
+func (t *Thumbnailer) ffmpegThumbnail(ctx context.Context, path, out string) error {
+	ctx, cancel := context.WithTimeout(ctx, thumbnailTimeout)
+	defer cancel()
+	size := strconv.Itoa(t.maxSize)
+	cmd := CommandWithCancel(ctx, t.ffmpeg,
+		"-y", "-v", "error",
+		"-i", path,
+		"-vf", "thumbnail,scale="+size+":"+size+":force_original_aspect_ratio=decrease",
+		"-frames:v", "1",
+		out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return &domain.ToolError{Tool: "ffmpeg", Err: fmt.Errorf("%s", lastOutputLine(string(output), err))}
+	}
+	return nil
+}
+
+// scaleDown returns img scaled to fit maxSize x maxSize, averaging the source
+// pixels under each thumbnail pixel. Smaller images are returned as they are.
+func scaleDown(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxSize && h <= maxSize {
+		return img
+	}
+	tw, th := maxSize, h*maxSize/w
+	if h > w {
+		tw, th = w*maxSize/h, maxSize
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := y*h/th, (y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := x*w/tw, (x+1)*w/tw
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r, g, b, a = r+int(p[0]), g+int(p[1]), b+int(p[2]), a+int(p[3])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,84 @@
+package infrastructure
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func writeTestPNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, png.Encode(f, img))
+}
+
+func TestThumbnailer_ScalesImageAndCaches(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "photo.png")
+	writeTestPNG(t, src, 400, 200)
+
+	thumbnailer := NewThumbnailer(filepath.Join(dir, "thumbnails"), 100, "ffmpeg")
+	thumbPath, err := thumbnailer.Thumbnail(context.Background(), src)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(thumbPath, filepath.Join(dir, "thumbnails")))
+
+	f, err := os.Open(thumbPath)
+	require.NoError(t, err)
+	defer f.Close()
+	thumb, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, 100, thumb.Bounds().Dx())
+	assert.Equal(t, 50, thumb.Bounds().Dy())
+	r, g, b, _ := thumb.At(10, 10).RGBA()
+	assert.InDelta(t, 200, r>>8, 8)
+	assert.InDelta(t, 100, g>>8, 8)
+	assert.InDelta(t, 50, b>>8, 8)
+
+	// The second request is served from the cache
+	again, err := thumbnailer.Thumbnail(context.Background(), src)
+	require.NoError(t, err)
+	assert.Equal(t, thumbPath, again)
+
+	// Replacing the file makes a new thumbnail
+	writeTestPNG(t, src, 50, 80)
+	replaced, err := thumbnailer.Thumbnail(context.Background(), src)
+	require.NoError(t, err)
+	assert.NotEqual(t, thumbPath, replaced)
+}
+
+func TestThumbnailer_Unsupported(t *testing.T) {
+	dir := t.TempDir()
+	thumbnailer := NewThumbnailer(filepath.Join(dir, "thumbnails"), 100, filepath.Join(dir, "no-ffmpeg"))
+
+	text := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(text, []byte("hello"), 0644))
+	_, err := thumbnailer.Thumbnail(context.Background(), text)
+	var unsupported *domain.UnsupportedThumbnailError
+	assert.ErrorAs(t, err, &unsupported)
+
+	// Videos need ffmpeg
+	video := filepath.Join(dir, "clip.mp4")
+	require.NoError(t, os.WriteFile(video, []byte("not really a video"), 0644))
+	_, err = thumbnailer.Thumbnail(context.Background(), video)
+	require.ErrorAs(t, err, &unsupported)
+	assert.Contains(t, unsupported.Reason, "ffmpeg")
+}
@@ -0,0 +1,123 @@
+package infrastructure
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Files of the self-signed certificate in its directory
+const (
+	SelfSignedCertFile = "cert.pem"
+	SelfSignedKeyFile  = "key.pem"
+)
+
+// selfSignedValidity is how long a generated certificate is valid, and
+// selfSignedRenewBefore how long before it expires it is replaced
+const (
+	selfSignedValidity    = 365 * 24 * time.Hour
+	selfSignedRenewBefore = 30 * 24 * time.Hour
+)
+
+// EnsureSelfSignedCert returns the certificate and key files of the
+// self-signed certificate in dir, generating them when they are missing,
+// expire within 30 days or don't cover every one of hosts (names or IPs)
+func EnsureSelfSignedCert(dir string, hosts []string) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, SelfSignedCertFile)
+	keyFile = filepath.Join(dir, SelfSignedKeyFile)
+	if selfSignedCertValid(certFile, keyFile, hosts, time.Now()) {
+		return certFile, keyFile, nil
+	}
+
+	certPEM, keyPEM, err := generateSelfSignedCert(hosts, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := WriteFileAtomic(keyFile, keyPEM, 0600); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", keyFile, err)
+	}
+	if err := WriteFileAtomic(certFile, certPEM, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", certFile, err)
+	}
+	return certFile, keyFile, nil
+}
+
+// selfSignedCertValid reports whether the certificate of certFile can be kept:
+// its key is there, it is valid for another 30 days and covers hosts
+func selfSignedCertValid(certFile, keyFile string, hosts []string, now time.Time) bool {
+	if _, err := os.Stat(keyFile); err != nil {
+		return false
+	}
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || now.Add(selfSignedRenewBefore).After(cert.NotAfter) {
+		return false
+	}
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateSelfSignedCert generates an ECDSA P-256 certificate for hosts and
+// returns it and its key PEM-encoded
+func generateSelfSignedCert(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "x-extract self-signed", Organization: []string{"X-Extract"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureSelfSignedCert(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+
+	certFile, keyFile, err := EnsureSelfSignedCert(dir, hosts)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, SelfSignedCertFile), certFile)
+	_, err = tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	stat, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	assert.True(t, selfSignedCertValid(certFile, keyFile, hosts, time.Now()))
+
+	// Kept while it covers the hosts and is not about to expire
+	first, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	_, _, err = EnsureSelfSignedCert(dir, hosts)
+	require.NoError(t, err)
+	again, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+	assert.False(t, selfSignedCertValid(certFile, keyFile, hosts, time.Now().Add(340*24*time.Hour)), "renewed 30 days before it expires")
+
+	// Replaced for a new host
+	_, _, err = EnsureSelfSignedCert(dir, append(hosts, "nas.example.ts.net"))
+	require.NoError(t, err)
+	renewed, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, renewed)
+	assert.True(t, selfSignedCertValid(certFile, keyFile, []string{"nas.example.ts.net", "127.0.0.1"}, time.Now()))
+}
@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"context"
+	"os/exec"
+)
+
+// CommandWrapper is a command an external tool runs under to contain it
+// (twitter.wrapper, telegram.wrapper), e.g. ["nice", "-n", "10"],
+// ["systemd-run", "--user", "--scope", "-p", "MemoryMax=2G"],
+// ["firejail", "--quiet"] or ["docker", "run", "--rm", "-v", "/data:/data", "image"].
+// The tool's command line is appended to it, so the wrapper must run the
+// command given after its own arguments. An empty wrapper runs the tool
+// directly.
+type CommandWrapper []string
+
+// Wrap returns the program and arguments that run name with args under w
+func (w CommandWrapper) Wrap(name string, args ...string) (string, []string) {
+	if len(w) == 0 {
+		return name, args
+	}
+	wrapped := make([]string, 0, len(w)+len(args))
+	wrapped = append(wrapped, w[1:]...)
+	wrapped = append(wrapped, name)
+	return w[0], append(wrapped, args...)
+}
+
+// Command is CommandWithCancel for name with args run under w
+func (w CommandWrapper) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	program, wrapped := w.Wrap(name, args...)
+	return CommandWithCancel(ctx, program, wrapped...)
+}
+
+// CommandLine is the shell-escaped command line of name with args run under
+// w, for download logs
+func (w CommandWrapper) CommandLine(name string, args ...string) string {
+	program, wrapped := w.Wrap(name, args...)
+	return ShellEscapeCommand(program, wrapped...)
+}
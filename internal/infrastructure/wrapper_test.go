@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestCommandWrapper_Wrap(t *testing.T) {
+	program, args := CommandWrapper(nil).Wrap("yt-dlp", "-o", "out")
+	assert.Equal(t, "yt-dlp", program)
+	assert.Equal(t, []string{"-o", "out"}, args)
+
+	program, args = CommandWrapper{"nice", "-n", "10"}.Wrap("yt-dlp", "-o", "out")
+	assert.Equal(t, "nice", program)
+	assert.Equal(t, []string{"-n", "10", "yt-dlp", "-o", "out"}, args)
+
+	assert.Equal(t, "systemd-run --scope -p MemoryMax=2G tdl dl -u 'a b'",
+		CommandWrapper{"systemd-run", "--scope", "-p", "MemoryMax=2G"}.CommandLine("tdl", "dl", "-u", "a b"))
+}
+
+func TestCommandWrapper_Command(t *testing.T) {
+	output, err := CommandWrapper{"env", "WRAPPED=yes"}.Command(context.Background(), "sh", "-c", "echo $WRAPPED").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "yes", strings.TrimSpace(string(output)))
+}
+
+func TestTwitterDownloader_RunsUnderWrapper(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\necho \"{\\\"id\\\":\\\"$WRAPPED\\\"}\"\n"), 0755))
+	d := NewTwitterDownloader(&domain.TwitterConfig{YTDLPBinary: binary, Wrapper: []string{"env", "WRAPPED=1"}},
+		dir, dir, dir, nil)
+
+	download := domain.NewDownload("https://x.com/alice/status/100", domain.PlatformX, domain.ModeDefault)
+	sim, err := d.Simulate(context.Background(), download)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sim.Command, "env WRAPPED=1 "+binary+" "), sim.Command)
+	require.Len(t, sim.Items, 1)
+	assert.Equal(t, "1", sim.Items[0].ID, "yt-dlp ran under the wrapper")
+}
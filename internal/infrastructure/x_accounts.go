@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+// xAuthErrorRe matches yt-dlp errors for a tweet the account may not see:
+// rejected or expired cookies, or a protected account it does not follow
+var xAuthErrorRe = regexp.MustCompile(`(?i)HTTP Error 40[13]|logged[- ]in|log ?in required|authenticated users|requires authentication|could not authenticate|account (is )?(suspended|locked)`)
+
+// defaultAccountCooldown is how long a rate-limited account is skipped when
+// neither X nor twitter.account_cooldown says
+const defaultAccountCooldown = 15 * time.Minute
+
+// xAccountPool chooses the cookie accounts of X downloads (twitter.cookie_file
+// and twitter.accounts) and skips accounts X has rate limited until their
+// cooldown ends
+type xAccountPool struct {
+	config   *domain.TwitterConfig
+	mu       sync.Mutex
+	cooldown map[string]time.Time // Account -> end of its cooldown
+	now      func() time.Time
+}
+
+func newXAccountPool(config *domain.TwitterConfig) *xAccountPool {
+	return &xAccountPool{config: config, cooldown: make(map[string]time.Time), now: time.Now}
+}
+
+// candidates returns the accounts to try for a download, in order. A pinned
+// account is used alone. Otherwise every account not cooling down is tried,
+// default first; when all are cooling down, the one available soonest. An
+// empty list means no account is configured (yt-dlp runs without cookies).
+func (p *xAccountPool) candidates(pinned string) []string {
+	if pinned != "" {
+		return []string{pinned}
+	}
+	names := p.config.AccountNames()
+	if len(names) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var available []string
+	for _, name := range names {
+		if !p.cooldown[name].After(now) {
+			available = append(available, name)
+		}
+	}
+	if len(available) > 0 {
+		return available
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return p.cooldown[names[i]].Before(p.cooldown[names[j]])
+	})
+	return names[:1]
+}
+
+// rateLimited skips an account for retryAfter, or twitter.account_cooldown
+// when X did not say
+func (p *xAccountPool) rateLimited(name string, retryAfter time.Duration) {
+	if name == "" {
+		return
+	}
+	if retryAfter <= 0 {
+		retryAfter = p.config.AccountCooldown
+	}
+	if retryAfter <= 0 {
+		retryAfter = defaultAccountCooldown
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cooldown[name] = p.now().Add(retryAfter)
+}
+
+// cookieArgs returns the yt-dlp --cookies arguments of an account, or none
+// when it has no cookie file on disk
+func (p *xAccountPool) cookieArgs(name string) []string {
+	file, ok := p.config.AccountCookieFile(name)
+	if !ok || !FileExists(file) {
+		return nil
+	}
+	return []string{"--cookies", file}
+}
+
+// clientProfile describes the client settings of a run with an account: the
+// yt-dlp fingerprint, plus the account when it is not the default one
+func (p *xAccountPool) clientProfile(name string) string {
+	profile := p.config.ClientProfile()
+	if name == "" || name == domain.DefaultXAccount {
+		return profile
+	}
+	if profile == "" {
+		return "account=" + name
+	}
+	return profile + " | account=" + name
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+func TestXAccountPool_Candidates(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pool := newXAccountPool(&domain.TwitterConfig{
+		CookieFile:      "/cookies/main.cookie",
+		Accounts:        map[string]string{"alt": "/cookies/alt.cookie", "protected": "/cookies/protected.cookie"},
+		AccountCooldown: 10 * time.Minute,
+	})
+	pool.now = func() time.Time { return now }
+
+	assert.Equal(t, []string{domain.DefaultXAccount, "alt", "protected"}, pool.candidates(""))
+	assert.Equal(t, []string{"protected"}, pool.candidates("protected"))
+
+	pool.rateLimited(domain.DefaultXAccount, 0)
+	pool.rateLimited("alt", time.Hour)
+	assert.Equal(t, []string{"protected"}, pool.candidates(""))
+
+	// All cooling down: the one available soonest
+	pool.rateLimited("protected", 30*time.Minute)
+	assert.Equal(t, []string{domain.DefaultXAccount}, pool.candidates(""))
+
+	now = now.Add(11 * time.Minute)
+	assert.Equal(t, []string{domain.DefaultXAccount}, pool.candidates(""))
+	now = now.Add(time.Hour)
+	assert.Len(t, pool.candidates(""), 3)
+
+	assert.Empty(t, newXAccountPool(&domain.TwitterConfig{}).candidates(""))
+}
+
+func TestXAccountPool_ClientProfile(t *testing.T) {
+	pool := newXAccountPool(&domain.TwitterConfig{Impersonate: "chrome"})
+	assert.Equal(t, "impersonate=chrome", pool.clientProfile(domain.DefaultXAccount))
+	assert.Equal(t, "impersonate=chrome | account=alt", pool.clientProfile("alt"))
+	assert.Equal(t, "account=alt", newXAccountPool(&domain.TwitterConfig{}).clientProfile("alt"))
+}
+
+// fakeAccountYTDLP is rate limited with limited.cookie, and otherwise writes
+// the tweet into the -P directory
+const fakeAccountYTDLP = `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -P) dir="$2" ;;
+    --cookies) cookies="$2" ;;
+  esac
+  shift
+done
+case "$cookies" in
+  *limited.cookie)
+    echo "ERROR: [twitter] 123: HTTP Error 429: Too Many Requests"
+    exit 1 ;;
+esac
+printf v > "$dir/alice_123.mp4"
+`
+
+func TestTwitterRunYTDLP_FailsOverToNextAccount(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte(fakeAccountYTDLP), 0755))
+	limited := filepath.Join(dir, "limited.cookie")
+	alt := filepath.Join(dir, "alt.cookie")
+	for _, file := range []string{limited, alt} {
+		require.NoError(t, os.WriteFile(file, []byte("# Netscape HTTP Cookie File\n"), 0644))
+	}
+	config := &domain.TwitterConfig{
+		YTDLPBinary: binary,
+		CookieFile:  limited,
+		Accounts:    map[string]string{"alt": alt},
+	}
+	incoming := t.TempDir()
+	d := NewTwitterDownloader(config, incoming, t.TempDir(), t.TempDir(), nil)
+	downloadLog, err := os.Create(filepath.Join(dir, "download.log"))
+	require.NoError(t, err)
+	defer downloadLog.Close()
+
+	download := domain.NewDownload("https://x.com/alice/status/123", domain.PlatformX, domain.ModeDefault)
+	files, _, clientProfile, err := d.runYTDLP(context.Background(), download, nil, func(domain.DownloadProgress) {}, downloadLog)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(incoming, "alice_123.mp4")}, files)
+	assert.Equal(t, "account=alt", clientProfile)
+	assert.Equal(t, []string{"alt"}, d.accounts.candidates(""), "the rate-limited account cools down")
+
+	// A download pinned to an account does not fail over
+	download = domain.NewDownload("https://x.com/alice/status/123", domain.PlatformX, domain.ModeDefault)
+	download.Account = domain.DefaultXAccount
+	_, _, _, err = d.runYTDLP(context.Background(), download, nil, func(domain.DownloadProgress) {}, downloadLog)
+	var rateLimit *domain.RateLimitError
+	assert.ErrorAs(t, err, &rateLimit)
+}
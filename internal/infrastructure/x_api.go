@@ -0,0 +1,615 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+const (
+	// xAPIBearerToken is the public bearer token of the x.com web client. The
+	// session itself is authenticated by the auth_token/ct0 cookies.
+	xAPIBearerToken = "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
+
+	// DefaultXTweetQueryID is the GraphQL query ID of TweetResultByRestId. X
+	// rotates these; override with twitter.native_query_id when it changes.
+	DefaultXTweetQueryID = "2ICDjqPd81tulZcYrtpTuQ"
+
+	xAPIBaseURL    = "https://x.com/i/api/graphql/"
+	xAPITimeout    = 30 * time.Second
+	xTweetTimeForm = "Mon Jan 02 15:04:05 -0700 2006"
+)
+
+// xTweetFeatures are the GraphQL feature switches sent with TweetResultByRestId.
+// X rejects requests that omit required switches.
+var xTweetFeatures = map[string]bool{
+	"creator_subscriptions_tweet_preview_api_enabled":                         true,
+	"communities_web_enable_tweet_community_results_fetch":                    true,
+	"c9s_tweet_anatomy_moderator_badge_enabled":                               true,
+	"articles_preview_enabled":                                                true,
+	"tweetypie_unmention_optimization_enabled":                                true,
+	"responsive_web_edit_tweet_api_enabled":                                   true,
+	"graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+	"view_counts_everywhere_api_enabled":                                      true,
+	"longform_notetweets_consumption_enabled":                                 true,
+	"responsive_web_twitter_article_tweet_consumption_enabled":                true,
+	"tweet_awards_web_tipping_enabled":                                        false,
+	"creator_subscriptions_quote_tweet_preview_enabled":                       false,
+	"freedom_of_speech_not_reach_fetch_enabled":                               true,
+	"standardized_nudges_misinfo":                                             true,
+	"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+	"rweb_video_timestamps_enabled":                                           true,
+	"longform_notetweets_rich_text_read_enabled":                              true,
+	"longform_notetweets_inline_media_enabled":                                true,
+	"rweb_tipjar_consumption_enabled":                                         true,
+	"responsive_web_graphql_exclude_directive_enabled":                        true,
+	"verified_phone_label_enabled":                                            false,
+	"responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+	"responsive_web_graphql_timeline_navigation_enabled":                      true,
+	"responsive_web_enhance_cards_enabled":                                    false,
+}
+
+// XTweet is a tweet as returned by X's web GraphQL API
+type XTweet struct {
+	ID         string
+	Text       string // Full text without trailing media links
+	ScreenName string // Author handle
+	Name       string // Author display name
+	CreatedAt  time.Time
+	Hashtags   []string
+	Media      []XTweetMedia
+	Poll       *domain.Poll // nil when the tweet has no poll
+
+	LikeCount     int64
+	RepostCount   int64
+	ReplyCount    int64
+	QuoteCount    int64
+	BookmarkCount int64
+	ViewCount     int64
+}
+
+// XTweetMedia is a photo, video or GIF attached to a tweet
+type XTweetMedia struct {
+	Type     string // photo, video or animated_gif
+	URL      string // Image URL, or the poster image for videos
+	AltText  string
+	Variants []XVideoVariant // Video/GIF renditions
+}
+
+// XVideoVariant is one rendition of a tweet video or GIF
+type XVideoVariant struct {
+	URL         string
+	ContentType string // video/mp4 or application/x-mpegURL (HLS playlist)
+	Bitrate     int64  // bits per second; 0 for HLS playlists
+}
+
+// xVideoResolution matches the WxH segment of video.twimg.com rendition URLs
+// (.../vid/avc1/1280x720/name.mp4)
+var xVideoResolution = regexp.MustCompile(`/(\d+x\d+)/`)
+
+// Label names the rendition after its resolution, falling back to its bitrate
+func (v XVideoVariant) Label() string {
+	if match := xVideoResolution.FindStringSubmatch(v.URL); match != nil {
+		return match[1]
+	}
+	return fmt.Sprintf("%dk", v.Bitrate/1000)
+}
+
+// MP4Variants returns the directly downloadable renditions, best first
+func (m XTweetMedia) MP4Variants() []XVideoVariant {
+	var variants []XVideoVariant
+	for _, variant := range m.Variants {
+		if variant.ContentType == "video/mp4" {
+			variants = append(variants, variant)
+		}
+	}
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].Bitrate > variants[j].Bitrate })
+	return variants
+}
+
+// HasVideo reports whether the tweet has video or GIF media, which are left
+// to yt-dlp
+func (t *XTweet) HasVideo() bool {
+	for _, media := range t.Media {
+		if media.Type != "photo" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPhotoOnly reports whether the tweet has media and all of it is photos
+func (t *XTweet) IsPhotoOnly() bool {
+	return len(t.Media) > 0 && !t.HasVideo()
+}
+
+// HasAllVariants reports whether every video or GIF of the tweet lists an MP4
+// rendition, so an all-variants download needs no yt-dlp
+func (t *XTweet) HasAllVariants() bool {
+	for _, media := range t.Media {
+		if media.Type != "photo" && len(media.MP4Variants()) == 0 {
+			return false
+		}
+	}
+	return len(t.Media) > 0
+}
+
+// AltTexts returns the alt text of each photo in media order ("" when unset),
+// or nil if no photo has alt text
+func (t *XTweet) AltTexts() []string {
+	var texts []string
+	found := false
+	for _, media := range t.Media {
+		if media.Type != "photo" {
+			continue
+		}
+		texts = append(texts, media.AltText)
+		found = found || media.AltText != ""
+	}
+	if !found {
+		return nil
+	}
+	return texts
+}
+
+// ToMediaMetadata converts the tweet to the metadata TwitterDownloader builds
+// from yt-dlp's .info.json
+func (t *XTweet) ToMediaMetadata(url string, files []string) *domain.MediaMetadata {
+	title := strings.Join(strings.Fields(t.Text), " ")
+	if runes := []rune(title); len(runes) > galleryDLTweetTitleMaxRunes {
+		title = string(runes[:galleryDLTweetTitleMaxRunes]) + "..."
+	}
+	if t.Name != "" {
+		title = t.Name + " - " + title
+	}
+
+	timestamp := time.Now().Unix()
+	uploadDate := time.Now().Format("20060102")
+	if !t.CreatedAt.IsZero() {
+		timestamp = t.CreatedAt.Unix()
+		uploadDate = t.CreatedAt.Format("20060102")
+	}
+
+	meta := &domain.MediaMetadata{
+		ID:           t.ID,
+		Title:        title,
+		Description:  t.Text,
+		Uploader:     t.Name,
+		UploaderID:   t.ScreenName,
+		WebpageURL:   url,
+		URL:          url,
+		Timestamp:    timestamp,
+		UploadDate:   uploadDate,
+		Tags:         append(append([]string{}, t.Hashtags...), "x", "twitter"),
+		Platform:     "x",
+		Extractor:    "twitter",
+		ExtractorKey: "Twitter",
+		Files:        files,
+	}
+	if t.ScreenName != "" {
+		meta.UploaderURL = "https://x.com/" + t.ScreenName
+		meta.WebpageURL = fmt.Sprintf("https://x.com/%s/status/%s", t.ScreenName, t.ID)
+	}
+	t.fillStats(meta)
+	return meta
+}
+
+// FillMissing copies the tweet's text, alt text and counts into meta where
+// meta has none, e.g. when yt-dlp's extractor returned an empty description
+func (t *XTweet) FillMissing(meta *domain.MediaMetadata) {
+	if meta.Description == "" {
+		meta.Description = t.Text
+	}
+	if meta.Uploader == "" {
+		meta.Uploader = t.Name
+	}
+	if meta.UploaderID == "" {
+		meta.UploaderID = t.ScreenName
+	}
+	t.fillStats(meta)
+}
+
+func (t *XTweet) fillStats(meta *domain.MediaMetadata) {
+	if len(meta.AltTexts) == 0 {
+		meta.AltTexts = t.AltTexts()
+	}
+	if meta.LikeCount == 0 {
+		meta.LikeCount = t.LikeCount
+	}
+	if meta.RepostCount == 0 {
+		meta.RepostCount = t.RepostCount
+	}
+	if meta.CommentCount == 0 {
+		meta.CommentCount = t.ReplyCount
+	}
+	if meta.ViewCount == 0 {
+		meta.ViewCount = t.ViewCount
+	}
+	if meta.Poll == nil {
+		meta.Poll = t.Poll
+	}
+}
+
+// XAPIClient fetches tweets from X's web GraphQL API with the session in a
+// Netscape cookie file. It is a lightweight alternative to yt-dlp's extractor
+// for metadata and photo tweets.
+type XAPIClient struct {
+	cookieFile string
+	queryID    string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewXAPIClient creates a client authenticated by cookieFile. An empty
+// queryID uses DefaultXTweetQueryID.
+func NewXAPIClient(cookieFile, queryID string) *XAPIClient {
+	if queryID == "" {
+		queryID = DefaultXTweetQueryID
+	}
+	return &XAPIClient{
+		cookieFile: cookieFile,
+		queryID:    queryID,
+		baseURL:    xAPIBaseURL,
+		httpClient: &http.Client{Timeout: xAPITimeout},
+	}
+}
+
+// FetchTweet fetches a tweet by ID. HTTP 429 is returned as a
+// *domain.RateLimitError.
+func (c *XAPIClient) FetchTweet(ctx context.Context, tweetID string) (*XTweet, error) {
+	cookieHeader, csrfToken, err := c.sessionCookies()
+	if err != nil {
+		return nil, err
+	}
+
+	variables, _ := json.Marshal(map[string]interface{}{
+		"tweetId":                tweetID,
+		"withCommunity":          false,
+		"includePromotedContent": false,
+		"withVoice":              false,
+	})
+	features, _ := json.Marshal(xTweetFeatures)
+	query := url.Values{}
+	query.Set("variables", string(variables))
+	query.Set("features", string(features))
+	endpoint := c.baseURL + c.queryID + "/TweetResultByRestId?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+xAPIBearerToken)
+	req.Header.Set("Cookie", cookieHeader)
+	req.Header.Set("X-Csrf-Token", csrfToken)
+	req.Header.Set("X-Twitter-Auth-Type", "OAuth2Session")
+	req.Header.Set("X-Twitter-Active-User", "yes")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("X API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read X API response: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &domain.RateLimitError{
+			RetryAfter: xRateLimitReset(resp.Header, time.Now()),
+			Err:        fmt.Errorf("X API returned HTTP 429"),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("X API returned HTTP %d", resp.StatusCode)
+	}
+	return parseTweetResult(body)
+}
+
+// DownloadMedia saves a tweet photo at its original resolution, or a video
+// rendition as is, to destPath
+func (c *XAPIClient) DownloadMedia(ctx context.Context, mediaURL, destPath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, originalPhotoURL(mediaURL), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP GET %s: %w", mediaURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, mediaURL)
+	}
+	return writeFileAtomic(resp.Body, destPath)
+}
+
+// writeFileAtomic writes r to destPath via a .part file so an interrupted
+// download never leaves a truncated file under the final name
+func writeFileAtomic(r io.Reader, destPath string) error {
+	partPath := destPath + ".part"
+	out, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		os.Remove(partPath)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(partPath)
+		return err
+	}
+	return os.Rename(partPath, destPath)
+}
+
+// sessionCookies builds the Cookie header for x.com from the cookie file and
+// returns it with the ct0 CSRF token
+func (c *XAPIClient) sessionCookies() (string, string, error) {
+	cookies, err := ParseNetscapeCookies(c.cookieFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read X cookie file: %w", err)
+	}
+	var pairs []string
+	csrfToken := ""
+	for _, cookie := range cookies {
+		if !isXCookieDomain(cookie.Domain) {
+			continue
+		}
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+		if cookie.Name == "ct0" {
+			csrfToken = cookie.Value
+		}
+	}
+	if csrfToken == "" {
+		return "", "", fmt.Errorf("X cookie file has no ct0 cookie: %s", c.cookieFile)
+	}
+	return strings.Join(pairs, "; "), csrfToken, nil
+}
+
+// xRateLimitReset returns how long to wait after a 429 from the X API, from
+// the x-rate-limit-reset epoch header or a standard Retry-After
+func xRateLimitReset(header http.Header, now time.Time) time.Duration {
+	if reset, err := strconv.ParseInt(header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		if wait := time.Unix(reset, 0).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		return parseRetryAfter("Retry-After: "+retryAfter, now)
+	}
+	return 0
+}
+
+// originalPhotoURL requests the original resolution of a pbs.twimg.com image
+func originalPhotoURL(mediaURL string) string {
+	u, err := url.Parse(mediaURL)
+	if err != nil || u.Host != "pbs.twimg.com" {
+		return mediaURL
+	}
+	query := u.Query()
+	query.Set("name", "orig")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// photoExtension returns the file extension of a tweet photo URL
+func photoExtension(mediaURL string) string {
+	if u, err := url.Parse(mediaURL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" {
+			return ext
+		}
+		if format := u.Query().Get("format"); format != "" {
+			return "." + format
+		}
+	}
+	return ".jpg"
+}
+
+// GraphQL response shapes (only the fields we read)
+type (
+	xTweetResultResponse struct {
+		Data struct {
+			TweetResult struct {
+				Result *xTweetResult `json:"result"`
+			} `json:"tweetResult"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	xTweetResult struct {
+		TypeName string        `json:"__typename"`
+		RestID   string        `json:"rest_id"`
+		Tweet    *xTweetResult `json:"tweet"` // TweetWithVisibilityResults wrapper
+		Reason   string        `json:"reason"`
+		Core     struct {
+			UserResults struct {
+				Result struct {
+					Legacy xUserNames `json:"legacy"`
+					Core   xUserNames `json:"core"`
+				} `json:"result"`
+			} `json:"user_results"`
+		} `json:"core"`
+		Views struct {
+			Count string `json:"count"`
+		} `json:"views"`
+		Card struct {
+			Legacy xCardLegacy `json:"legacy"`
+		} `json:"card"`
+		NoteTweet struct {
+			NoteTweetResults struct {
+				Result struct {
+					Text string `json:"text"`
+				} `json:"result"`
+			} `json:"note_tweet_results"`
+		} `json:"note_tweet"`
+		Legacy struct {
+			FullText      string `json:"full_text"`
+			CreatedAt     string `json:"created_at"`
+			FavoriteCount int64  `json:"favorite_count"`
+			RetweetCount  int64  `json:"retweet_count"`
+			ReplyCount    int64  `json:"reply_count"`
+			QuoteCount    int64  `json:"quote_count"`
+			BookmarkCount int64  `json:"bookmark_count"`
+			Entities      struct {
+				Hashtags []struct {
+					Text string `json:"text"`
+				} `json:"hashtags"`
+			} `json:"entities"`
+			ExtendedEntities struct {
+				Media []struct {
+					Type          string `json:"type"`
+					URL           string `json:"url"` // t.co link appended to full_text
+					MediaURLHTTPS string `json:"media_url_https"`
+					ExtAltText    string `json:"ext_alt_text"`
+					VideoInfo     struct {
+						Variants []struct {
+							Bitrate     int64  `json:"bitrate"`
+							ContentType string `json:"content_type"`
+							URL         string `json:"url"`
+						} `json:"variants"`
+					} `json:"video_info"`
+				} `json:"media"`
+			} `json:"extended_entities"`
+		} `json:"legacy"`
+	}
+
+	xCardLegacy struct {
+		Name          string `json:"name"` // e.g. poll2choice_text_only
+		BindingValues []struct {
+			Key   string `json:"key"`
+			Value struct {
+				StringValue  string `json:"string_value"`
+				BooleanValue bool   `json:"boolean_value"`
+			} `json:"value"`
+		} `json:"binding_values"`
+	}
+
+	xUserNames struct {
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+	}
+)
+
+// parseTweetResult converts a TweetResultByRestId response into an XTweet
+func parseTweetResult(data []byte) (*XTweet, error) {
+	var resp xTweetResultResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse X API response: %w", err)
+	}
+	result := resp.Data.TweetResult.Result
+	if result == nil {
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("X API error: %s", resp.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("tweet not found")
+	}
+	if result.Tweet != nil {
+		result = result.Tweet
+	}
+	if result.TypeName != "" && result.TypeName != "Tweet" {
+		return nil, fmt.Errorf("tweet unavailable (%s %s)", result.TypeName, result.Reason)
+	}
+
+	user := result.Core.UserResults.Result
+	tweet := &XTweet{
+		ID:            result.RestID,
+		Name:          firstNonEmpty(user.Core.Name, user.Legacy.Name),
+		ScreenName:    firstNonEmpty(user.Core.ScreenName, user.Legacy.ScreenName),
+		LikeCount:     result.Legacy.FavoriteCount,
+		RepostCount:   result.Legacy.RetweetCount,
+		ReplyCount:    result.Legacy.ReplyCount,
+		QuoteCount:    result.Legacy.QuoteCount,
+		BookmarkCount: result.Legacy.BookmarkCount,
+	}
+	tweet.ViewCount, _ = strconv.ParseInt(result.Views.Count, 10, 64)
+	if createdAt, err := time.Parse(xTweetTimeForm, result.Legacy.CreatedAt); err == nil {
+		tweet.CreatedAt = createdAt
+	}
+	for _, hashtag := range result.Legacy.Entities.Hashtags {
+		tweet.Hashtags = append(tweet.Hashtags, hashtag.Text)
+	}
+
+	// Long tweets carry their full text in note_tweet; legacy.full_text is truncated
+	text := firstNonEmpty(result.NoteTweet.NoteTweetResults.Result.Text, result.Legacy.FullText)
+	for _, media := range result.Legacy.ExtendedEntities.Media {
+		tweetMedia := XTweetMedia{
+			Type:    media.Type,
+			URL:     media.MediaURLHTTPS,
+			AltText: media.ExtAltText,
+		}
+		for _, variant := range media.VideoInfo.Variants {
+			tweetMedia.Variants = append(tweetMedia.Variants, XVideoVariant{
+				URL:         variant.URL,
+				ContentType: variant.ContentType,
+				Bitrate:     variant.Bitrate,
+			})
+		}
+		tweet.Media = append(tweet.Media, tweetMedia)
+		if media.URL != "" {
+			text = strings.TrimSpace(strings.ReplaceAll(text, media.URL, ""))
+		}
+	}
+	tweet.Text = text
+	tweet.Poll = parsePollCard(result.Card.Legacy)
+	return tweet, nil
+}
+
+// xPollMaxChoices is the most options an X poll can have
+const xPollMaxChoices = 4
+
+// parsePollCard reads a poll from a tweet card ("poll<N>choice_*" cards keep
+// choice<i>_label / choice<i>_count binding values). Returns nil for other cards.
+func parsePollCard(card xCardLegacy) *domain.Poll {
+	if !strings.HasPrefix(card.Name, "poll") {
+		return nil
+	}
+	values := make(map[string]string)
+	poll := &domain.Poll{}
+	for _, binding := range card.BindingValues {
+		values[binding.Key] = binding.Value.StringValue
+		if binding.Key == "counts_are_final" {
+			poll.Final = binding.Value.BooleanValue
+		}
+	}
+	for i := 1; i <= xPollMaxChoices; i++ {
+		label, ok := values[fmt.Sprintf("choice%d_label", i)]
+		if !ok {
+			break
+		}
+		votes, _ := strconv.ParseInt(values[fmt.Sprintf("choice%d_count", i)], 10, 64)
+		poll.Options = append(poll.Options, domain.PollOption{Label: label, Votes: votes})
+	}
+	if len(poll.Options) == 0 {
+		return nil
+	}
+	if endsAt, err := time.Parse(time.RFC3339, values["end_datetime_utc"]); err == nil {
+		poll.EndsAt = endsAt.Unix()
+	}
+	return poll
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
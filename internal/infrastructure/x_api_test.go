@@ -0,0 +1,209 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/x-extract-go/internal/domain"
+)
+
+const testTweetResponse = `{
+  "data": {
+    "tweetResult": {
+      "result": {
+        "__typename": "TweetWithVisibilityResults",
+        "tweet": {
+          "rest_id": "1790000000000000002",
+          "core": {"user_results": {"result": {"legacy": {"name": "Some One", "screen_name": "someone"}}}},
+          "views": {"count": "12345"},
+          "legacy": {
+            "full_text": "Sunset over the bay #photography https://t.co/abc",
+            "created_at": "Mon May 13 18:30:00 +0000 2024",
+            "favorite_count": 120,
+            "retweet_count": 7,
+            "reply_count": 3,
+            "quote_count": 1,
+            "entities": {"hashtags": [{"text": "photography"}]},
+            "extended_entities": {"media": [
+              {"type": "photo", "url": "https://t.co/abc", "media_url_https": "https://pbs.twimg.com/media/A1.jpg", "ext_alt_text": "Orange sky over water"},
+              {"type": "photo", "url": "https://t.co/abc", "media_url_https": "https://pbs.twimg.com/media/B2.png"}
+            ]}
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestParseTweetResult(t *testing.T) {
+	tweet, err := parseTweetResult([]byte(testTweetResponse))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1790000000000000002", tweet.ID)
+	assert.Equal(t, "Sunset over the bay #photography", tweet.Text)
+	assert.Equal(t, "someone", tweet.ScreenName)
+	assert.Equal(t, "Some One", tweet.Name)
+	assert.Equal(t, time.Date(2024, 5, 13, 18, 30, 0, 0, time.UTC), tweet.CreatedAt.UTC())
+	assert.Equal(t, []string{"photography"}, tweet.Hashtags)
+	assert.Equal(t, int64(120), tweet.LikeCount)
+	assert.Equal(t, int64(12345), tweet.ViewCount)
+	require.Len(t, tweet.Media, 2)
+	assert.True(t, tweet.IsPhotoOnly())
+	assert.Equal(t, []string{"Orange sky over water", ""}, tweet.AltTexts())
+
+	_, err = parseTweetResult([]byte(`{"data":{"tweetResult":{"result":{"__typename":"TweetTombstone"}}}}`))
+	assert.Error(t, err)
+	_, err = parseTweetResult([]byte(`{"errors":[{"message":"Bad guest token"}]}`))
+	assert.ErrorContains(t, err, "Bad guest token")
+}
+
+func TestXTweet_ToMediaMetadata(t *testing.T) {
+	tweet, err := parseTweetResult([]byte(testTweetResponse))
+	require.NoError(t, err)
+
+	meta := tweet.ToMediaMetadata("https://twitter.com/someone/status/1790000000000000002", []string{"/completed/a.jpg"})
+	assert.Equal(t, "Some One - Sunset over the bay #photography", meta.Title)
+	assert.Equal(t, "Sunset over the bay #photography", meta.Description)
+	assert.Equal(t, "someone", meta.UploaderID)
+	assert.Equal(t, "https://x.com/someone/status/1790000000000000002", meta.WebpageURL)
+	assert.Equal(t, "20240513", meta.UploadDate)
+	assert.Equal(t, []string{"photography", "x", "twitter"}, meta.Tags)
+	assert.Equal(t, int64(7), meta.RepostCount)
+	assert.Equal(t, int64(3), meta.CommentCount)
+
+	// Fill gaps in yt-dlp metadata without overwriting what it has
+	ytdlp := &domain.MediaMetadata{Description: "", Uploader: "From yt-dlp", LikeCount: 99}
+	tweet.FillMissing(ytdlp)
+	assert.Equal(t, "Sunset over the bay #photography", ytdlp.Description)
+	assert.Equal(t, "From yt-dlp", ytdlp.Uploader)
+	assert.Equal(t, int64(99), ytdlp.LikeCount)
+	assert.Equal(t, int64(12345), ytdlp.ViewCount)
+	assert.Equal(t, []string{"Orange sky over water", ""}, ytdlp.AltTexts)
+}
+
+func TestXAPIClient_FetchTweet(t *testing.T) {
+	var gotCSRF, gotCookie, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCSRF = r.Header.Get("X-Csrf-Token")
+		gotCookie = r.Header.Get("Cookie")
+		gotPath = r.URL.Path
+		if strings.Contains(r.URL.Query().Get("variables"), "429") {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(testTweetResponse))
+	}))
+	defer server.Close()
+
+	expires := time.Now().AddDate(1, 0, 0)
+	client := NewXAPIClient(writeCookieFile(t,
+		xCookieLine("auth_token", expires, true),
+		xCookieLine("ct0", expires, false),
+	), "")
+	client.baseURL = server.URL + "/graphql/"
+
+	tweet, err := client.FetchTweet(context.Background(), "1790000000000000002")
+	require.NoError(t, err)
+	assert.Equal(t, "someone", tweet.ScreenName)
+	assert.Equal(t, "value", gotCSRF)
+	assert.Equal(t, "auth_token=value; ct0=value", gotCookie)
+	assert.Equal(t, "/graphql/"+DefaultXTweetQueryID+"/TweetResultByRestId", gotPath)
+
+	_, err = client.FetchTweet(context.Background(), "429")
+	var rateLimitErr *domain.RateLimitError
+	require.True(t, errors.As(err, &rateLimitErr))
+	assert.Equal(t, time.Minute, rateLimitErr.RetryAfter)
+
+	// No ct0 cookie: the session can't be used
+	client.cookieFile = writeCookieFile(t, xCookieLine("auth_token", expires, true))
+	_, err = client.FetchTweet(context.Background(), "1")
+	assert.ErrorContains(t, err, "ct0")
+}
+
+func TestXAPIClient_DownloadMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("image-bytes"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "someone_1_1.jpg")
+	require.NoError(t, NewXAPIClient("", "").DownloadMedia(context.Background(), server.URL+"/media/A1.jpg", dest))
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "image-bytes", string(data))
+	assert.False(t, FileExists(dest+".part"))
+}
+
+func TestPhotoURLHelpers(t *testing.T) {
+	assert.Equal(t, "https://pbs.twimg.com/media/A1.jpg?name=orig", originalPhotoURL("https://pbs.twimg.com/media/A1.jpg"))
+	assert.Equal(t, "https://example.com/a.jpg", originalPhotoURL("https://example.com/a.jpg"))
+	assert.Equal(t, ".png", photoExtension("https://pbs.twimg.com/media/B2.png"))
+	assert.Equal(t, ".webp", photoExtension("https://pbs.twimg.com/media/C3?format=webp"))
+	assert.Equal(t, ".jpg", photoExtension("https://pbs.twimg.com/media/C3"))
+}
+
+func TestParseTweetResult_Poll(t *testing.T) {
+	response := `{"data":{"tweetResult":{"result":{
+  "__typename": "Tweet",
+  "rest_id": "5",
+  "legacy": {"full_text": "Best cat?"},
+  "card": {"legacy": {"name": "poll2choice_text_only", "binding_values": [
+    {"key": "choice1_label", "value": {"type": "STRING", "string_value": "Tabby"}},
+    {"key": "choice1_count", "value": {"type": "STRING", "string_value": "3"}},
+    {"key": "choice2_label", "value": {"type": "STRING", "string_value": "Siamese"}},
+    {"key": "choice2_count", "value": {"type": "STRING", "string_value": "5"}},
+    {"key": "end_datetime_utc", "value": {"type": "STRING", "string_value": "2024-05-14T18:30:00Z"}},
+    {"key": "counts_are_final", "value": {"type": "BOOLEAN", "boolean_value": true}}
+  ]}}
+}}}}`
+	tweet, err := parseTweetResult([]byte(response))
+	require.NoError(t, err)
+	require.NotNil(t, tweet.Poll)
+	assert.Equal(t, []domain.PollOption{{Label: "Tabby", Votes: 3}, {Label: "Siamese", Votes: 5}}, tweet.Poll.Options)
+	assert.Equal(t, time.Date(2024, 5, 14, 18, 30, 0, 0, time.UTC).Unix(), tweet.Poll.EndsAt)
+	assert.True(t, tweet.Poll.Final)
+	assert.Same(t, tweet.Poll, tweet.ToMediaMetadata("https://x.com/a/status/5", nil).Poll)
+
+	assert.Nil(t, parsePollCard(xCardLegacy{Name: "summary_large_image"}))
+}
+
+func TestParseTweetResult_VideoVariants(t *testing.T) {
+	response := `{"data":{"tweetResult":{"result":{
+  "__typename": "Tweet",
+  "rest_id": "6",
+  "legacy": {"full_text": "Clip", "extended_entities": {"media": [
+    {"type": "photo", "media_url_https": "https://pbs.twimg.com/media/A1.jpg"},
+    {"type": "video", "media_url_https": "https://pbs.twimg.com/ext_tw_video_thumb/6/pu/img/P.jpg", "video_info": {"variants": [
+      {"content_type": "application/x-mpegURL", "url": "https://video.twimg.com/ext_tw_video/6/pu/pl/list.m3u8"},
+      {"bitrate": 832000, "content_type": "video/mp4", "url": "https://video.twimg.com/ext_tw_video/6/pu/vid/avc1/640x360/a.mp4?tag=12"},
+      {"bitrate": 2176000, "content_type": "video/mp4", "url": "https://video.twimg.com/ext_tw_video/6/pu/vid/avc1/1280x720/b.mp4?tag=12"}
+    ]}}
+  ]}}
+}}}}`
+	tweet, err := parseTweetResult([]byte(response))
+	require.NoError(t, err)
+	require.Len(t, tweet.Media, 2)
+	assert.True(t, tweet.HasVideo())
+	assert.True(t, tweet.HasAllVariants())
+
+	variants := tweet.Media[1].MP4Variants()
+	require.Len(t, variants, 2)
+	assert.Equal(t, "1280x720", variants[0].Label())
+	assert.Equal(t, "640x360", variants[1].Label())
+	assert.Equal(t, "832k", XVideoVariant{URL: "https://video.twimg.com/tweet_video/G.mp4", Bitrate: 832000}.Label())
+
+	// A video listing only an HLS playlist is left to yt-dlp
+	tweet.Media[1].Variants = tweet.Media[1].Variants[:1]
+	assert.False(t, tweet.HasAllVariants())
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// dailyFiles are the date-stamped log files (<category>-YYYYMMDD.log) of a
+// MultiLogger. On the first write after midnight all categories move to the
+// new day's files together, so no file of a day receives entries of the next.
+// Writes are serialized, so concurrent loggers never interleave entries or
+// write to a file being closed by the rotation.
+type dailyFiles struct {
+	dir   string
+	now   func() time.Time
+	mu    sync.Mutex
+	date  string // YYYYMMDD of the open files
+	files map[LogCategory]*os.File
+
+	failedDate string // Date whose files could not be opened, reported once
+	closed     bool   // Entries logged after close are dropped
+}
+
+func newDailyFiles(dir string) *dailyFiles {
+	return &dailyFiles{
+		dir:   dir,
+		now:   time.Now,
+		date:  time.Now().Format("20060102"),
+		files: make(map[LogCategory]*os.File),
+	}
+}
+
+// path returns the log file of a category for a date
+func (d *dailyFiles) path(category LogCategory, date string) string {
+	return filepath.Join(d.dir, fmt.Sprintf("%s-%s.log", category, date))
+}
+
+// writer opens today's file of a category and returns a WriteSyncer for it
+func (d *dailyFiles) writer(category LogCategory) (zapcore.WriteSyncer, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.files[category]; !ok {
+		file, err := openLogFile(d.path(category, d.date))
+		if err != nil {
+			return nil, err
+		}
+		d.files[category] = file
+	}
+	return &categoryWriter{files: d, category: category}, nil
+}
+
+func (d *dailyFiles) write(category LogCategory, p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return len(p), nil
+	}
+	d.rotate()
+	return d.files[category].Write(p)
+}
+
+func (d *dailyFiles) sync(category LogCategory) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+	return d.files[category].Sync()
+}
+
+// rotate switches every category to the current date's file when the date
+// has changed. If a new file cannot be opened, logging stays on the previous
+// day's files and the rotation is retried on the next write.
+func (d *dailyFiles) rotate() {
+	date := d.now().Format("20060102")
+	if date == d.date {
+		return
+	}
+
+	opened := make(map[LogCategory]*os.File, len(d.files))
+	for category := range d.files {
+		file, err := openLogFile(d.path(category, date))
+		if err != nil {
+			for _, f := range opened {
+				f.Close()
+			}
+			if d.failedDate != date {
+				d.failedDate = date
+				fmt.Fprintf(os.Stderr, "Failed to rotate logs to %s: %v\n", date, err)
+			}
+			return
+		}
+		opened[category] = file
+	}
+
+	for category, file := range d.files {
+		file.Close()
+		d.files[category] = opened[category]
+	}
+	d.date = date
+}
+
+// close closes the open files
+func (d *dailyFiles) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	var lastErr error
+	for _, file := range d.files {
+		if err := file.Close(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+}
+
+// categoryWriter writes the entries of one category to its current file
+type categoryWriter struct {
+	files    *dailyFiles
+	category LogCategory
+}
+
+// Write implements zapcore.WriteSyncer
+func (w *categoryWriter) Write(p []byte) (int, error) {
+	return w.files.write(w.category, p)
+}
+
+// Sync implements zapcore.WriteSyncer
+func (w *categoryWriter) Sync() error {
+	return w.files.sync(w.category)
+}
@@ -3,9 +3,7 @@ package logger
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
-	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -23,10 +21,12 @@ const (
 // Note: Raw download output (stdout/stderr from yt-dlp/tdl) is handled directly
 // by the downloaders using file redirects, not through this logger.
 type MultiLogger struct {
-	loggers     map[LogCategory]*zap.Logger
-	config      MultiLoggerConfig
-	mu          sync.RWMutex
-	currentDate string // Track current date for log rotation
+	loggers map[LogCategory]*zap.Logger
+	config  MultiLoggerConfig
+	mu      sync.RWMutex
+	files   *dailyFiles // Rotates all categories to the new day's files together
+
+	level zap.AtomicLevel // Level of the queue log, changed by SetLevel
 }
 
 // MultiLoggerConfig contains configuration for multi-output logging
@@ -47,9 +47,10 @@ func NewMultiLogger(config MultiLoggerConfig) (*MultiLogger, error) {
 	}
 
 	ml := &MultiLogger{
-		loggers:     make(map[LogCategory]*zap.Logger),
-		config:      config,
-		currentDate: time.Now().Format("20060102"),
+		loggers: make(map[LogCategory]*zap.Logger),
+		config:  config,
+		files:   newDailyFiles(config.LogsDir),
+		level:   zap.NewAtomicLevel(),
 	}
 
 	// Parse log level
@@ -57,9 +58,10 @@ func NewMultiLogger(config MultiLoggerConfig) (*MultiLogger, error) {
 	if err != nil {
 		level = zapcore.InfoLevel
 	}
+	ml.level.SetLevel(level)
 
 	// Create structured logger for queue (JSON format)
-	queueLogger, err := ml.createStructuredLogger(CategoryQueue, level)
+	queueLogger, err := ml.createStructuredLogger(CategoryQueue, ml.level)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create queue logger: %w", err)
 	}
@@ -76,7 +78,7 @@ func NewMultiLogger(config MultiLoggerConfig) (*MultiLogger, error) {
 }
 
 // createStructuredLogger creates a JSON-formatted logger for a category
-func (ml *MultiLogger) createStructuredLogger(category LogCategory, level zapcore.Level) (*zap.Logger, error) {
+func (ml *MultiLogger) createStructuredLogger(category LogCategory, level zapcore.LevelEnabler) (*zap.Logger, error) {
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.TimeKey = "ts"
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -86,23 +88,24 @@ func (ml *MultiLogger) createStructuredLogger(category LogCategory, level zapcor
 
 	encoder := zapcore.NewJSONEncoder(encoderConfig)
 
-	logPath := ml.getCategoryLogPath(category)
-	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	writer, err := ml.files.writer(category)
 	if err != nil {
 		return nil, err
 	}
-
-	writer := zapcore.AddSync(file)
 	core := zapcore.NewCore(encoder, writer, level)
 
 	return zap.New(core), nil
 }
 
-// getCategoryLogPath generates a log file path for a category with current date
-func (ml *MultiLogger) getCategoryLogPath(category LogCategory) string {
-	dateStr := time.Now().Format("20060102")
-	filename := fmt.Sprintf("%s-%s.log", category, dateStr)
-	return filepath.Join(ml.config.LogsDir, filename)
+// SetLevel changes the level of the queue log while the server runs; the
+// error log always logs errors only
+func (ml *MultiLogger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	ml.level.SetLevel(parsed)
+	return nil
 }
 
 // GetLogsDir returns the logs directory path
@@ -159,7 +162,7 @@ func (ml *MultiLogger) Sync() error {
 	return lastErr
 }
 
-// Close closes all loggers
+// Close flushes all loggers and closes their files
 func (ml *MultiLogger) Close() error {
 	ml.mu.Lock()
 	defer ml.mu.Unlock()
@@ -171,6 +174,9 @@ func (ml *MultiLogger) Close() error {
 			lastErr = err
 		}
 	}
+	if err := ml.files.close(); err != nil {
+		lastErr = err
+	}
 
 	return lastErr
 }
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestMultiLogger returns a multi-logger whose clock is read from now
+func newTestMultiLogger(t *testing.T, now *time.Time, mu *sync.Mutex) (*MultiLogger, string) {
+	t.Helper()
+	dir := t.TempDir()
+	ml, err := NewMultiLogger(MultiLoggerConfig{Level: "info", LogsDir: dir})
+	require.NoError(t, err)
+	t.Cleanup(func() { ml.Close() })
+
+	ml.files.mu.Lock()
+	ml.files.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return *now
+	}
+	ml.files.mu.Unlock()
+	return ml, dir
+}
+
+func readLogLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestMultiLogger_RotatesAllCategoriesAtMidnight(t *testing.T) {
+	today := time.Now()
+	now, mu := today, &sync.Mutex{}
+	ml, dir := newTestMultiLogger(t, &now, mu)
+	day1 := today.Format("20060102")
+	day2 := today.AddDate(0, 0, 1).Format("20060102")
+
+	ml.LogQueueEvent("before_midnight")
+	ml.LogAppError("error before midnight")
+
+	mu.Lock()
+	now = today.AddDate(0, 0, 1)
+	mu.Unlock()
+
+	// The first write after midnight moves every category, so the error
+	// logged next lands in the new day's file too
+	ml.LogQueueEvent("after_midnight")
+	ml.LogAppError("error after midnight")
+
+	queue1 := readLogLines(t, filepath.Join(dir, "queue-"+day1+".log"))
+	queue2 := readLogLines(t, filepath.Join(dir, "queue-"+day2+".log"))
+	require.Len(t, queue1, 1)
+	require.Len(t, queue2, 1)
+	assert.Contains(t, queue1[0], "before_midnight")
+	assert.Contains(t, queue2[0], "after_midnight")
+
+	error1 := readLogLines(t, filepath.Join(dir, "error-"+day1+".log"))
+	error2 := readLogLines(t, filepath.Join(dir, "error-"+day2+".log"))
+	require.Len(t, error1, 1)
+	require.Len(t, error2, 1)
+	assert.Contains(t, error2[0], "error after midnight")
+
+	// Loggers handed out before the rotation follow it
+	ml.Error().Error("from a held logger")
+	assert.Len(t, readLogLines(t, filepath.Join(dir, "error-"+day2+".log")), 2)
+}
+
+func TestMultiLogger_ConcurrentWritersAcrossRotation(t *testing.T) {
+	today := time.Now()
+	now, mu := today, &sync.Mutex{}
+	ml, dir := newTestMultiLogger(t, &now, mu)
+
+	const writers, entries = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				if w == 0 && i == entries/2 {
+					mu.Lock()
+					now = today.AddDate(0, 0, 1)
+					mu.Unlock()
+				}
+				ml.LogQueueEvent("tick", zap.Int("writer", w), zap.Int("i", i))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var lines []string
+	lines = append(lines, readLogLines(t, filepath.Join(dir, "queue-"+today.Format("20060102")+".log"))...)
+	lines = append(lines, readLogLines(t, filepath.Join(dir, "queue-"+today.AddDate(0, 0, 1).Format("20060102")+".log"))...)
+	require.Len(t, lines, writers*entries)
+	for _, line := range lines {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), "interleaved entry: %s", line)
+	}
+}
+
+func TestMultiLogger_DropsEntriesAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	ml, err := NewMultiLogger(MultiLoggerConfig{Level: "info", LogsDir: dir})
+	require.NoError(t, err)
+	queue := ml.Queue()
+	require.NoError(t, ml.Close())
+
+	queue.Info("late event")
+	data, err := os.ReadFile(filepath.Join(dir, "queue-"+time.Now().Format("20060102")+".log"))
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+func TestMultiLogger_SetLevel(t *testing.T) {
+	now, mu := time.Now(), &sync.Mutex{}
+	ml, dir := newTestMultiLogger(t, &now, mu)
+	path := filepath.Join(dir, "queue-"+now.Format("20060102")+".log")
+
+	ml.Queue().Debug("hidden")
+	require.NoError(t, ml.SetLevel("debug"))
+	ml.Queue().Debug("shown")
+
+	lines := readLogLines(t, path)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "shown")
+
+	assert.Error(t, ml.SetLevel("loud"))
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// queueLogTimeLayout is the layout of the ts field written by the zap
+// ISO8601 time encoder
+const queueLogTimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+// maxQueueEventDays bounds how many daily queue logs ReadQueueEvents reads
+const maxQueueEventDays = 90
+
+// QueueEvent is a queue lifecycle event read back from the queue logs
+type QueueEvent struct {
+	Time       time.Time              `json:"time"`
+	Event      string                 `json:"event"`                 // e.g. download_added, download_completed
+	DownloadID string                 `json:"download_id,omitempty"` // Set on events about a download
+	Fields     map[string]interface{} `json:"fields,omitempty"`      // The other logged fields
+}
+
+// ReadQueueEvents returns the queue events logged after since, oldest first.
+// At most limit events are returned (0 = no limit); more reports that later
+// events were left out. A page never ends between two events with the same
+// time, so the next page can be read with since set to the last event's time.
+// Logs older than maxQueueEventDays are not read.
+func (lr *LogReader) ReadQueueEvents(since time.Time, limit int) (events []QueueEvent, more bool, err error) {
+	now := time.Now()
+	if oldest := now.AddDate(0, 0, -maxQueueEventDays); since.Before(oldest) {
+		since = oldest
+	}
+
+	events = []QueueEvent{}
+	// Daily logs are named by local date; start at the day since falls on
+	day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.Local)
+	for ; !day.After(now); day = day.AddDate(0, 0, 1) {
+		dayEvents, err := lr.readQueueEventsFile(lr.GetLogPath(CategoryQueue, day), since)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, event := range dayEvents {
+			if limit > 0 && len(events) >= limit && !event.Time.Equal(events[len(events)-1].Time) {
+				return events, true, nil
+			}
+			events = append(events, event)
+		}
+	}
+	return events, false, nil
+}
+
+// readQueueEventsFile parses the events of one queue log logged after since.
+// Lines that are not queue events are skipped; a missing file has none.
+func (lr *LogReader) readQueueEventsFile(path string, since time.Time) ([]QueueEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []QueueEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			continue
+		}
+		event, _ := fields["msg"].(string)
+		ts, _ := fields["ts"].(string)
+		t, err := time.Parse(queueLogTimeLayout, ts)
+		if event == "" || err != nil || !t.After(since) {
+			continue
+		}
+		delete(fields, "msg")
+		delete(fields, "ts")
+		delete(fields, "level")
+		// Download events log the download as id or download_id
+		downloadID, _ := fields["id"].(string)
+		if downloadID == "" {
+			downloadID, _ = fields["download_id"].(string)
+		}
+		delete(fields, "id")
+		delete(fields, "download_id")
+		if len(fields) == 0 {
+			fields = nil
+		}
+		events = append(events, QueueEvent{Time: t, Event: event, DownloadID: downloadID, Fields: fields})
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReadQueueEvents_ParsesLoggedEvents(t *testing.T) {
+	dir := t.TempDir()
+	ml, err := NewMultiLogger(MultiLoggerConfig{Level: "info", LogsDir: dir})
+	require.NoError(t, err)
+	defer ml.Close()
+
+	start := time.Now().Add(-time.Second)
+	ml.LogQueueEvent("download_added", zap.String("id", "dl-1"), zap.String("platform", "x"))
+	ml.LogQueueEvent("download_expired", zap.String("download_id", "dl-2"))
+	ml.LogQueueEvent("queue_empty")
+	require.NoError(t, ml.Sync())
+
+	events, more, err := NewLogReader(dir).ReadQueueEvents(start, 0)
+	require.NoError(t, err)
+	assert.False(t, more)
+	require.Len(t, events, 3)
+	assert.Equal(t, "download_added", events[0].Event)
+	assert.Equal(t, "dl-1", events[0].DownloadID)
+	assert.Equal(t, map[string]interface{}{"platform": "x"}, events[0].Fields)
+	assert.Equal(t, "dl-2", events[1].DownloadID)
+	assert.Nil(t, events[1].Fields)
+	assert.Equal(t, "queue_empty", events[2].Event)
+	assert.WithinDuration(t, time.Now(), events[2].Time, time.Minute)
+}
+
+func TestReadQueueEvents_SpansDaysAndPages(t *testing.T) {
+	dir := t.TempDir()
+	lr := NewLogReader(dir)
+	yesterday := time.Now().AddDate(0, 0, -1)
+	t0 := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 23, 0, 0, 0, time.Local)
+	t1 := time.Now().Truncate(time.Millisecond)
+
+	line := func(ts time.Time, msg string) string {
+		return `{"level":"info","ts":"` + ts.Format(queueLogTimeLayout) + `","msg":"` + msg + `"}` + "\n"
+	}
+	require.NoError(t, os.WriteFile(lr.GetLogPath(CategoryQueue, t0),
+		[]byte(line(t0.Add(-time.Hour), "too_old")+line(t0, "a")+"not json\n"), 0644))
+	require.NoError(t, os.WriteFile(lr.GetLogPath(CategoryQueue, t1),
+		[]byte(line(t1, "b")+line(t1, "c")+line(t1.Add(time.Millisecond), "d")), 0644))
+
+	// Events sharing a time are never split across pages
+	events, more, err := lr.ReadQueueEvents(t0.Add(-time.Minute), 2)
+	require.NoError(t, err)
+	assert.True(t, more)
+	require.Len(t, events, 3)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{events[0].Event, events[1].Event, events[2].Event})
+
+	events, more, err = lr.ReadQueueEvents(events[2].Time, 2)
+	require.NoError(t, err)
+	assert.False(t, more)
+	require.Len(t, events, 1)
+	assert.Equal(t, "d", events[0].Event)
+}
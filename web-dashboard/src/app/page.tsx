@@ -3,6 +3,7 @@
 import { useEffect, useState, useCallback, useMemo } from "react";
 import { DownloadsTable } from "@/components/downloads-table";
 import { DownloadsFilters } from "@/components/downloads-filters";
+import { CookieStatus } from "@/components/cookie-status";
 import { api } from "@/lib/api";
 import type { Download, DownloadStatus, Platform } from "@/lib/types";
 import { useRefresh } from "./client-layout";
@@ -151,6 +152,9 @@ export default function HomePage() {
         <StatPill k="completed"  value={stats.completed}  onClick={() => handleStatClick("completed")}  active={statusFilter === "completed"} />
         <StatPill k="failed"     value={stats.failed}     onClick={() => handleStatClick("failed")}     active={statusFilter === "failed"} />
         <StatPill k="cancelled"  value={stats.cancelled}  onClick={() => handleStatClick("cancelled")}  active={statusFilter === "cancelled"} />
+        <div className="ml-auto">
+          <CookieStatus />
+        </div>
       </div>
 
       {/* Filters row */}
@@ -239,8 +239,20 @@ export function AddDownloadDialog({ open, onOpenChange, onSuccess }: AddDownload
 
     setLoading(true);
     try {
-      await api.createDownload({ url: trimmedUrl, platform, mode: resolveMode(), filters: buildFilters() });
-      addToast({ type: "success", title: "Queued", description: "Download added to queue." });
+      const result = await api.createDownload({
+        url: trimmedUrl,
+        platform,
+        mode: resolveMode(),
+        filters: buildFilters(),
+        source: "dashboard",
+      });
+      if (!result.duplicate) {
+        addToast({ type: "success", title: "Queued", description: "Download added to queue." });
+      } else if (result.download.status === "completed") {
+        addToast({ type: "info", title: "Already downloaded", description: result.download.file_path || result.download.id });
+      } else {
+        addToast({ type: "info", title: "Already queued", description: `This URL is already ${result.download.status}.` });
+      }
       reset();
       onOpenChange(false);
       onSuccess();
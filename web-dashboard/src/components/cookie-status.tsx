@@ -0,0 +1,93 @@
+"use client";
+
+import { useCallback, useEffect, useRef, useState } from "react";
+import { Button } from "@/components/ui/button";
+import { useToast } from "@/components/ui/toast";
+import { api } from "@/lib/api";
+import type { CookieStatus as CookieStatusType } from "@/lib/types";
+import { Cookie, Upload } from "lucide-react";
+
+const stateColor: Record<CookieStatusType["state"], string> = {
+  unknown:  "text-muted-foreground",
+  ok:       "text-green-500",
+  expiring: "text-yellow-500",
+  expired:  "text-red-500",
+  invalid:  "text-red-500",
+  missing:  "text-red-500",
+};
+
+// Cookie health of each platform, with an upload button to replace an
+// expired or rejected cookie file
+export function CookieStatus() {
+  const [cookies, setCookies] = useState<CookieStatusType[]>([]);
+  const [uploading, setUploading] = useState(false);
+  const fileInput = useRef<HTMLInputElement>(null);
+  const uploadPlatform = useRef<CookieStatusType["platform"]>("x");
+  const { addToast } = useToast();
+
+  const fetchCookies = useCallback(async () => {
+    try {
+      setCookies(await api.getCookies());
+    } catch (err) {
+      console.debug("Failed to fetch cookie status:", err);
+    }
+  }, []);
+
+  useEffect(() => {
+    fetchCookies();
+    const interval = setInterval(fetchCookies, 60000);
+    return () => clearInterval(interval);
+  }, [fetchCookies]);
+
+  const handleFile = async (event: React.ChangeEvent<HTMLInputElement>) => {
+    const file = event.target.files?.[0];
+    event.target.value = "";
+    if (!file) return;
+
+    setUploading(true);
+    try {
+      const status = await api.importCookies(uploadPlatform.current, file);
+      addToast({ type: "success", title: "Cookies imported", description: status.message || `State: ${status.state}` });
+      fetchCookies();
+    } catch (err) {
+      addToast({ type: "error", title: "Cookie import failed", description: err instanceof Error ? err.message : String(err) });
+    } finally {
+      setUploading(false);
+    }
+  };
+
+  if (cookies.length === 0) return null;
+
+  return (
+    <div className="flex items-center gap-2">
+      <input ref={fileInput} type="file" accept=".txt,.cookie,text/plain" className="hidden" onChange={handleFile} />
+      {cookies.map((status) => {
+        const expires = status.auth_token_expires_at ?? status.expires_at;
+        return (
+          <div key={status.platform} className="flex items-center gap-1.5 rounded-md bg-muted px-3 py-1 text-sm">
+            <Cookie className={`h-3.5 w-3.5 ${stateColor[status.state]}`} />
+            <span
+              className="font-medium"
+              title={[status.message, expires && `auth_token expires ${new Date(expires).toLocaleString()}`].filter(Boolean).join("\n")}
+            >
+              {status.platform} cookies: {status.state}
+            </span>
+            <Button
+              variant="ghost"
+              size="sm"
+              className="h-6 px-2"
+              disabled={uploading}
+              title="Upload a refreshed cookies.txt"
+              onClick={() => {
+                uploadPlatform.current = status.platform;
+                fileInput.current?.click();
+              }}
+            >
+              <Upload />
+            </Button>
+          </div>
+        );
+      })}
+    </div>
+  );
+}
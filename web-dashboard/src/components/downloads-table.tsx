@@ -460,7 +460,7 @@ export function DownloadsTable({ downloads, loading, onRefresh }: DownloadsTable
                               <ChevronRightIcon className="h-4 w-4" />
                             )}
                           </Button>
-                          {(download.status === "failed" || download.status === "cancelled") && (
+                          {(download.status === "failed" || download.status === "cancelled" || download.status === "expired") && (
                             <Button variant="ghost" size="icon" className="h-8 w-8" title="Restart" onClick={() => handleRetry(download.id)}>
                               <RefreshCw className="h-4 w-4" />
                             </Button>
@@ -492,10 +492,32 @@ export function DownloadsTable({ downloads, loading, onRefresh }: DownloadsTable
                               <div className="pl-6 space-y-1">
                                 {files.map((filePath, index) => (
                                   <div key={index} className="flex items-center gap-2 text-sm">
-                                    <FileText className="h-3 w-3 text-muted-foreground" />
-                                    <span className="font-mono text-xs truncate max-w-[600px]" title={filePath}>
-                                      {getFileName(filePath)}
-                                    </span>
+                                    {download.status === "completed" ? (
+                                      <img
+                                        src={api.fileThumbnailUrl(download.id, index)}
+                                        alt=""
+                                        loading="lazy"
+                                        className="h-10 w-10 rounded object-cover"
+                                        onError={(e) => (e.currentTarget.style.display = "none")}
+                                      />
+                                    ) : (
+                                      <FileText className="h-3 w-3 text-muted-foreground" />
+                                    )}
+                                    {download.status === "completed" ? (
+                                      <a
+                                        href={api.fileContentUrl(download.id, index)}
+                                        target="_blank"
+                                        rel="noreferrer"
+                                        className="font-mono text-xs truncate max-w-[600px] hover:underline"
+                                        title={filePath}
+                                      >
+                                        {getFileName(filePath)}
+                                      </a>
+                                    ) : (
+                                      <span className="font-mono text-xs truncate max-w-[600px]" title={filePath}>
+                                        {getFileName(filePath)}
+                                      </span>
+                                    )}
                                   </div>
                                 ))}
                               </div>
@@ -4,7 +4,8 @@ import { Button } from "@/components/ui/button";
 import { cn } from "@/lib/utils";
 import { useEffect, useState } from "react";
 import { useServerHealth } from "@/hooks/use-server-health";
-import { Download, Moon, Sun, Plus, AlertCircle, CheckCircle } from "lucide-react";
+import { api } from "@/lib/api";
+import { Download, Moon, Sun, Plus, AlertCircle, CheckCircle, Pin, PinOff } from "lucide-react";
 
 interface HeaderProps {
   onAddDownload: () => void;
@@ -13,6 +14,20 @@ interface HeaderProps {
 export function Header({ onAddDownload }: HeaderProps) {
   const [isDark, setIsDark] = useState(false);
   const serverOnline = useServerHealth();
+  const [keepRunning, setKeepRunning] = useState(false);
+
+  // A server started after the page loaded has no keepalive held
+  useEffect(() => {
+    if (!serverOnline) return;
+    api.getKeepAlive().then((status) => setKeepRunning(status.held)).catch(() => {});
+  }, [serverOnline]);
+
+  const toggleKeepRunning = async () => {
+    try {
+      const status = keepRunning ? await api.releaseKeepAlive() : await api.keepAlive({ hold: true });
+      setKeepRunning(status.held);
+    } catch {}
+  };
 
   // Sync with whatever the anti-flash script already applied
   useEffect(() => {
@@ -65,6 +80,16 @@ export function Header({ onAddDownload }: HeaderProps) {
             Add Download
           </Button>
 
+          <Button
+            variant="ghost"
+            size="icon"
+            onClick={toggleKeepRunning}
+            disabled={!serverOnline}
+            title={keepRunning ? "Keeping the server running; click to allow auto-exit" : "Keep the server running (suppress auto-exit)"}
+          >
+            {keepRunning ? <Pin className="h-4 w-4" /> : <PinOff className="h-4 w-4" />}
+          </Button>
+
           <Button variant="ghost" size="icon" onClick={toggleTheme} title="Toggle theme">
             {isDark ? <Sun className="h-4 w-4" /> : <Moon className="h-4 w-4" />}
           </Button>
@@ -1,11 +1,33 @@
 import type {
   Download,
   DownloadProgress,
+  DownloadFile,
   DownloadStats,
+  StatsHistory,
   CreateDownloadRequest,
   DownloadFilters,
+  ExportFormat,
+  FailedFilters,
+  FailedDownloads,
+  RetryAllResult,
+  DedupeReport,
+  SimulateRequest,
+  QueueReorderRequest,
+  Simulation,
   ApiError,
   ApiMessage,
+  RuntimeSettings,
+  EffectiveConfig,
+  ConfigReload,
+  UpdateConfigRequest,
+  SelfTestRequest,
+  SelfTestResult,
+  BuildInfo,
+  CookieStatus,
+  KeepAliveStatus,
+  KeepAliveRequest,
+  CreateDownloadResult,
+  Platform,
 } from "./types";
 
 const API_BASE = "/api/v1";
@@ -39,19 +61,70 @@ class ApiClient {
     const params = new URLSearchParams();
     if (filters?.status) params.append("status", filters.status);
     if (filters?.platform) params.append("platform", filters.platform);
+    if (filters?.source) params.append("source", filters.source);
+    if (filters?.language) params.append("language", filters.language);
+    if (filters?.error_code) params.append("error_code", filters.error_code);
+    if (filters?.limit) params.append("limit", String(filters.limit));
+    if (filters?.offset) params.append("offset", String(filters.offset));
+    if (filters?.sort_by) params.append("sort_by", filters.sort_by);
+    if (filters?.sort_dir) params.append("sort_dir", filters.sort_dir);
+    if (filters?.created_after) params.append("created_after", filters.created_after);
+    if (filters?.created_before) params.append("created_before", filters.created_before);
+    if (filters?.min_size) params.append("min_size", filters.min_size);
+    if (filters?.max_size) params.append("max_size", filters.max_size);
     const query = params.toString();
     return this.request<Download[]>(`/downloads${query ? `?${query}` : ""}`);
   }
 
+  // Download link of the export of the downloads matching filters
+  exportUrl(format: ExportFormat, filters?: DownloadFilters): string {
+    const params = new URLSearchParams({ format });
+    if (filters?.status) params.append("status", filters.status);
+    if (filters?.platform) params.append("platform", filters.platform);
+    if (filters?.source) params.append("source", filters.source);
+    if (filters?.language) params.append("language", filters.language);
+    if (filters?.error_code) params.append("error_code", filters.error_code);
+    if (filters?.created_after) params.append("created_after", filters.created_after);
+    if (filters?.created_before) params.append("created_before", filters.created_before);
+    if (filters?.min_size) params.append("min_size", filters.min_size);
+    if (filters?.max_size) params.append("max_size", filters.max_size);
+    return `${API_BASE}/downloads/export?${params.toString()}`;
+  }
+
   async getDownload(id: string): Promise<Download> {
     return this.request<Download>(`/downloads/${id}`);
   }
 
-  async createDownload(data: CreateDownloadRequest): Promise<Download> {
-    return this.request<Download>("/downloads", {
+  async getDownloadFiles(id: string): Promise<DownloadFile[]> {
+    const data = await this.request<{ files: DownloadFile[] }>(`/downloads/${id}/files`);
+    return data.files;
+  }
+
+  // URLs for <img>/<video> elements; the content URL supports range requests
+  fileContentUrl(id: string, index: number): string {
+    return `${API_BASE}/downloads/${id}/files/${index}/content`;
+  }
+
+  fileThumbnailUrl(id: string, index: number): string {
+    return `${API_BASE}/downloads/${id}/files/${index}/thumbnail`;
+  }
+
+  async createDownload(data: CreateDownloadRequest): Promise<CreateDownloadResult> {
+    const response = await fetch(`${API_BASE}/downloads`, {
       method: "POST",
+      headers: { "Content-Type": "application/json" },
       body: JSON.stringify(data),
     });
+    const body = await response.json().catch(() => ({
+      error: `HTTP error ${response.status}`,
+    }));
+    if (response.status === 409 && body.duplicate) {
+      return { duplicate: true, download: body.download };
+    }
+    if (!response.ok) {
+      throw new Error((body as ApiError).error);
+    }
+    return { duplicate: false, download: body };
   }
 
   async retryDownload(id: string): Promise<ApiMessage> {
@@ -60,6 +133,44 @@ class ApiClient {
     });
   }
 
+  async getFailedDownloads(filters?: FailedFilters): Promise<FailedDownloads> {
+    const params = new URLSearchParams();
+    if (filters?.platform) params.append("platform", filters.platform);
+    if (filters?.error_code) params.append("error_code", filters.error_code);
+    if (filters?.older_than) params.append("older_than", filters.older_than);
+    if (filters?.newer_than) params.append("newer_than", filters.newer_than);
+    const query = params.toString();
+    return this.request<FailedDownloads>(`/downloads/failed${query ? `?${query}` : ""}`);
+  }
+
+  async retryAll(filters?: FailedFilters): Promise<RetryAllResult> {
+    return this.request<RetryAllResult>("/downloads/retry-all", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(filters ?? {}),
+    });
+  }
+
+  async getDuplicates(): Promise<DedupeReport> {
+    return this.request<DedupeReport>("/downloads/duplicates");
+  }
+
+  async simulateDownload(request: SimulateRequest): Promise<Simulation> {
+    return this.request<Simulation>("/downloads/simulate", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(request),
+    });
+  }
+
+  // Returns the queued downloads in their new order
+  async reorderQueue(request: QueueReorderRequest): Promise<Download[]> {
+    return this.request<Download[]>("/queue/reorder", {
+      method: "POST",
+      body: JSON.stringify(request),
+    });
+  }
+
   async cancelDownload(id: string): Promise<ApiMessage> {
     return this.request<ApiMessage>(`/downloads/${id}/cancel`, {
       method: "POST",
@@ -88,6 +199,75 @@ class ApiClient {
     return this.request<DownloadStats>("/downloads/stats");
   }
 
+  async getStatsHistory(days = 30): Promise<StatsHistory> {
+    return this.request<StatsHistory>(`/downloads/stats/history?days=${days}`);
+  }
+
+  // Settings
+  async getSettings(): Promise<RuntimeSettings> {
+    return this.request<RuntimeSettings>("/settings");
+  }
+
+  async updateSettings(settings: Partial<RuntimeSettings>): Promise<RuntimeSettings> {
+    return this.request<RuntimeSettings>("/settings", {
+      method: "PATCH",
+      body: JSON.stringify(settings),
+    });
+  }
+
+  async getEffectiveConfig(): Promise<EffectiveConfig> {
+    return this.request<EffectiveConfig>("/admin/config");
+  }
+
+  async getConfig(): Promise<EffectiveConfig> {
+    return this.request<EffectiveConfig>("/config");
+  }
+
+  async updateConfig(request: UpdateConfigRequest): Promise<ConfigReload> {
+    return this.request<ConfigReload>("/config", {
+      method: "PUT",
+      body: JSON.stringify(request),
+    });
+  }
+
+  async reloadConfig(): Promise<ConfigReload> {
+    return this.request<ConfigReload>("/config/reload", {
+      method: "POST",
+    });
+  }
+
+  async runSelfTest(request: SelfTestRequest = {}): Promise<SelfTestResult> {
+    return this.request<SelfTestResult>("/admin/selftest", {
+      method: "POST",
+      body: JSON.stringify(request),
+    });
+  }
+
+  async getVersion(): Promise<BuildInfo> {
+    return this.request<BuildInfo>("/version");
+  }
+
+  // Cookies
+  async getCookies(refresh = false): Promise<CookieStatus[]> {
+    const data = await this.request<{ cookies: CookieStatus[] }>(
+      `/cookies${refresh ? "?refresh=true" : ""}`
+    );
+    return data.cookies;
+  }
+
+  // Replaces the platform's cookie file; rejected unless its auth cookies are valid
+  async importCookies(platform: Platform, file: File): Promise<CookieStatus> {
+    const data = await this.request<{ platform: Platform; cookie: CookieStatus }>(
+      `/cookies/${platform}`,
+      {
+        method: "PUT",
+        headers: { "Content-Type": "text/plain" },
+        body: await file.text(),
+      }
+    );
+    return data.cookie;
+  }
+
   // Health check - check if the backend server is responding
   async checkHealth(): Promise<boolean> {
     try {
@@ -113,6 +293,21 @@ class ApiClient {
       return false;
     }
   }
+
+  async getKeepAlive(): Promise<KeepAliveStatus> {
+    return this.request<KeepAliveStatus>("/server/keepalive");
+  }
+
+  async keepAlive(data: KeepAliveRequest = {}): Promise<KeepAliveStatus> {
+    return this.request<KeepAliveStatus>("/server/keepalive", {
+      method: "POST",
+      body: JSON.stringify(data),
+    });
+  }
+
+  async releaseKeepAlive(): Promise<KeepAliveStatus> {
+    return this.request<KeepAliveStatus>("/server/keepalive", { method: "DELETE" });
+  }
 }
 
 export const api = new ApiClient();
@@ -8,13 +8,13 @@ export interface DownloadProgress {
 }
 
 // Download status types
-export type DownloadStatus = "queued" | "processing" | "completed" | "failed" | "cancelled";
+export type DownloadStatus = "queued" | "processing" | "recording" | "completed" | "failed" | "cancelled" | "expired";
 
 // Platform types
 export type Platform = "x" | "telegram" | "instagram" | "gallery";
 
 // Download mode types
-export type DownloadMode = "default" | "single" | "group";
+export type DownloadMode = "default" | "single" | "group" | "profile";
 
 // Parsed download metadata
 export interface DownloadMetadata {
@@ -31,12 +31,62 @@ export interface DownloadMetadata {
   timestamp?: number;
   upload_date?: string;
   tags?: string[];
+  /** ISO 639-1 code of the post text, e.g. "zh" */
+  language?: string;
   extractor?: string;
   extractor_key?: string;
+  alt_texts?: string[];
+  like_count?: number;
+  repost_count?: number;
+  comment_count?: number;
+  view_count?: number;
+  poll?: Poll;
   note?: string;
 }
 
+// Poll attached to an X post
+export interface Poll {
+  options: { label: string; votes: number }[];
+  ends_at?: number;
+  final: boolean;
+}
+
+// Status transition recorded on a download
+export interface TimelineEntry {
+  time: string;
+  event: 'queued' | 'started' | 'recording' | 'retry' | 'failed' | 'cancelled' | 'requeued' | 'completed' | 'expired' | 'imported' | 'interrupted';
+  message?: string;
+}
+
+// File of a download, with its own status and size
+export interface DownloadItem {
+  id: number;
+  download_id: string;
+  position: number;
+  status: 'completed' | 'failed';
+  file_path?: string;
+  file_size?: number;
+  source_id?: string;
+  source_url?: string;
+  title?: string;
+  upload_date?: string;
+  error_message?: string;
+  /** Where the file was uploaded by a remote storage backend */
+  remote_url?: string;
+  created_at: string;
+  /** SHA-256 of the file, recorded by download.dedupe */
+  content_hash?: string;
+  /** Download whose file this one is a hard link to, having the same content */
+  duplicate_of?: string;
+}
+
+// Why a download failed
+export type ErrorCode = "auth_expired" | "not_found" | "rate_limited" | "network" | "disk_full" | "tool_missing" | "parse_error";
+
 // Download entity from API
+// How a download was added
+export type DownloadSource = "api" | "cli" | "dashboard" | "monitor" | "telegram-bot" | "watch-folder" | "import";
+
 export interface Download {
   id: string;
   url: string;
@@ -46,13 +96,113 @@ export interface Download {
   priority: number;
   retry_count: number;
   error_message?: string;
+  /** Why the download failed, when recognised */
+  error_code?: ErrorCode;
   file_path?: string;
+  file_size?: number;
+  /** Total play time of the downloaded videos in seconds */
+  media_duration?: number;
+  /** Where file_path was uploaded by a remote storage backend */
+  remote_url?: string;
   metadata?: string;
+  title?: string;
+  uploader?: string;
+  uploader_id?: string;
+  upload_date?: string;
+  webpage_url?: string;
+  /** Language of the post text (ISO 639-1), detected when the metadata was written */
+  language?: string;
   process_log?: string;
+  progress: number;
+  speed?: string;
+  eta?: string;
+  current_file?: string;
+  range_start?: number;
+  range_end?: number;
+  all_variants?: boolean;
+  item_count?: number;
+  items?: DownloadItem[];
+  client_profile?: string;
+  /** POSTed when the download reaches a terminal state */
+  callback_url?: string;
+  /** How the download was added; missing for downloads added before it was recorded */
+  source?: DownloadSource;
+  /** X only: twitter.accounts account the download is pinned to */
+  account?: string;
+  /** Telegram only: telegram.profiles tdl profile the download uses */
+  tdl_profile?: string;
+  timeline?: TimelineEntry[];
   created_at: string;
   updated_at: string;
   started_at?: string;
   completed_at?: string;
+  /** Resource usage of the external tools of the last attempt */
+  process_count?: number;
+  process_cpu_ms?: number;
+  process_wall_ms?: number;
+  /** Largest resident set size of any tool, in bytes */
+  process_peak_rss?: number;
+  /** Size estimated before the download started, with download.large_size set */
+  estimated_size?: number;
+  /** Maintenance jobs waiting for maintenance.window, comma-separated: transcodes, remote_sync */
+  deferred?: string;
+}
+
+// A downloaded file, as listed by GET /downloads/:id/files
+export interface DownloadFile {
+  index: number;
+  name: string;
+  path: string;
+  media_type: "image" | "video" | "other";
+  size: number;
+  exists: boolean;
+  remote_url?: string;
+}
+
+// Uploader summary from GET /api/v1/uploaders
+export interface UploaderSummary {
+  key: string;
+  platform: Platform;
+  uploader: string;
+  uploader_id: string;
+  download_count: number;
+  total_size: number;
+  latest_download?: Download;
+}
+
+// Download filter used by saved searches
+export interface DownloadFilter {
+  status?: DownloadStatus;
+  platform?: Platform;
+  tag?: string;
+  uploader?: string;
+  language?: string;
+  from?: string;
+  to?: string;
+  query?: string;
+  text?: string;
+}
+
+// Saved search from GET /api/v1/searches
+export interface SavedSearch {
+  id: string;
+  name: string;
+  filter: DownloadFilter;
+  created_at: string;
+  updated_at: string;
+}
+
+// Cookie health from GET /api/v1/cookies and /api/v1/platforms/:platform/status
+export interface CookieStatus {
+  platform: Platform;
+  cookie_file: string;
+  state: "unknown" | "ok" | "expiring" | "expired" | "invalid" | "missing";
+  expires_at?: string;
+  auth_token_expires_at?: string;
+  last_modified?: string;
+  validated: boolean;
+  message?: string;
+  checked_at: string;
 }
 
 // Statistics from API
@@ -60,9 +210,160 @@ export interface DownloadStats {
   total: number;
   queued: number;
   processing: number;
+  recording: number;
   completed: number;
   failed: number;
   cancelled: number;
+  expired: number;
+  items: number;
+  failed_items: number;
+  /** Size of every completed download (imports aside), and of those completed today */
+  bytes: number;
+  bytes_today: number;
+  /** Play time of their videos in seconds */
+  media_duration: number;
+  tags: GroupStats[];
+  collections: GroupStats[];
+  resources: ResourceStats;
+}
+
+// Resource usage of the external tools over the downloads' last attempts
+export interface ResourceStats {
+  cpu_ms: number;
+  wall_ms: number;
+  peak_rss: number;
+  /** The downloads that used the most CPU time, most first */
+  heaviest: DownloadUsage[];
+}
+
+export interface DownloadUsage {
+  id: string;
+  url: string;
+  status: DownloadStatus;
+  processes: number;
+  cpu_ms: number;
+  wall_ms: number;
+  peak_rss: number;
+}
+
+// Completed downloads sharing a tag or collection
+export interface GroupStats {
+  name: string;
+  count: number;
+  bytes: number;
+}
+
+// Downloads of a day (GET /downloads/stats/history)
+export interface DayCounts {
+  /** Downloads created on the day */
+  added: number;
+  /** Downloads completed on the day */
+  completed: number;
+  /** Size of the files of the downloads completed on the day */
+  bytes: number;
+}
+
+export interface DailyStats extends DayCounts {
+  /** YYYY-MM-DD in the server's local time */
+  date: string;
+  /** Platforms with activity that day */
+  platforms: Partial<Record<Platform, DayCounts>>;
+}
+
+// Download activity per day, oldest first, through today
+export interface StatsHistory {
+  days: number;
+  history: DailyStats[];
+}
+
+// Runtime settings (GET/PATCH /settings); durations use Go syntax, e.g. "30s"
+export interface RuntimeSettings {
+  check_interval: string;
+  auto_exit_on_empty: boolean;
+  empty_wait_time: string;
+  defer_exit_for_clients: boolean;
+  max_retries: number;
+  retry_delay: string;
+  platform_concurrency: number;
+  notifications_enabled: boolean;
+  notification_sound: boolean;
+  /** Bytes per second per download, e.g. "2MiB"; "" = unlimited */
+  rate_limit: string;
+  /** Per-platform limits replacing rate_limit; "0" = unlimited */
+  rate_limit_overrides: Record<string, string> | null;
+}
+
+// Where the effective value of a config key came from
+export type ConfigSource = "default" | "system config" | "user override" | "environment";
+
+// A config key with its default and effective value (GET /admin/config)
+export interface ConfigOption {
+  key: string;
+  type: string;
+  default: string;
+  value: string;
+  source: ConfigSource;
+  /** Applied by a config reload or update without a restart */
+  runtime: boolean;
+}
+
+// Merged effective configuration of the server, secrets redacted
+export interface EffectiveConfig {
+  system_config: string;
+  user_config: string;
+  options: ConfigOption[];
+}
+
+// Config keys to change (PUT /config), e.g. {"queue.check_interval": "5s"}
+export interface UpdateConfigRequest {
+  values: Record<string, unknown>;
+}
+
+// Result of reloading the config files or updating config keys
+export interface ConfigReload {
+  applied: string[];
+  restart_required: string[];
+}
+
+// Self-test (POST /admin/selftest); nothing is downloaded
+export interface SelfTestRequest {
+  platforms?: Platform[];
+  /** URLs to list instead of selftest.urls, by platform */
+  urls?: Partial<Record<Platform, string>>;
+}
+
+export interface SelfTestStage {
+  name: "database" | "directories" | "binary" | "cookies" | "simulate";
+  status: "passed" | "failed" | "skipped";
+  message?: string;
+  duration_ms: number;
+}
+
+export interface SelfTestPlatform {
+  platform: Platform;
+  url?: string;
+  passed: boolean;
+  stages: SelfTestStage[];
+}
+
+export interface SelfTestResult {
+  passed: boolean;
+  started_at: string;
+  duration_ms: number;
+  stages: SelfTestStage[];
+  platforms: SelfTestPlatform[];
+}
+
+// Build of the server (GET /api/v1/version)
+export interface BuildInfo {
+  version: string;
+  commit?: string;
+  build_date?: string;
+  /** Built from a tree with uncommitted changes */
+  modified?: boolean;
+  go_version: string;
+  os: string;
+  arch: string;
 }
 
 // Request to create a download
@@ -72,8 +373,24 @@ export interface CreateDownloadRequest {
   mode?: DownloadMode;
   /** Pipe-delimited gallery-dl -o options, e.g. "replies=false|retweets=false" */
   filters?: string;
+  /** Download again even if the URL is already downloaded */
+  force?: boolean;
+  /** URL POSTed the download when it completes, fails or is cancelled */
+  callback_url?: string;
+  /** Defaults to "api" */
+  source?: DownloadSource;
+  /** X only: download with this twitter.accounts account */
+  account?: string;
+  /** Telegram only: download with this telegram.profiles tdl profile */
+  tdl_profile?: string;
 }
 
+// New order of queued downloads: ids top first, or id moved before/after another
+export type QueueReorderRequest =
+  | { ids: string[] }
+  | { id: string; before: string }
+  | { id: string; after: string };
+
 // Instagram URL type
 export type InstagramURLType = "post" | "account";
 
@@ -84,9 +401,108 @@ export type XURLType = "single" | "timeline";
 export interface DownloadFilters {
   status?: DownloadStatus;
   platform?: Platform;
+  source?: DownloadSource;
+  language?: string;
+  error_code?: ErrorCode;
   search?: string;
   page?: number;
   limit?: number;
+  offset?: number;
+  sort_by?: "created_at" | "updated_at" | "completed_at" | "priority" | "file_size" | "media_duration" | "title" | "uploader" | "status" | "platform";
+  sort_dir?: "asc" | "desc";
+  /** RFC 3339 timestamp or YYYY-MM-DD */
+  created_after?: string;
+  created_before?: string;
+  /** Size bounds, in bytes or with a unit (e.g. 100MB) */
+  min_size?: string;
+  max_size?: string;
+}
+
+// Format of GET /downloads/export
+export type ExportFormat = "csv" | "jsonl";
+
+// Selects failed downloads to list or retry; empty fields match every failed download
+export interface FailedFilters {
+  platform?: Platform;
+  error_code?: ErrorCode;
+  /** Age such as 30d, 2w or 36h: only downloads that failed longer ago */
+  older_than?: string;
+  /** Only downloads that failed more recently */
+  newer_than?: string;
+}
+
+// Failed downloads sharing an error code ("" for unclassified failures)
+export interface FailedGroup {
+  error_code: ErrorCode | "";
+  /** Whether the download manager retries this kind of failure by itself */
+  retryable: boolean;
+  count: number;
+  downloads: Download[];
+}
+
+export interface FailedDownloads {
+  total: number;
+  groups: FailedGroup[];
+}
+
+export interface RetryAllResult {
+  retried: number;
+  ids: string[];
+}
+
+// Completed files sharing their content, the original first
+export interface DuplicateGroup {
+  content_hash: string;
+  file_size: number;
+  files: DownloadItem[];
+}
+
+// Files downloaded more than once under different URLs (download.dedupe)
+export interface DedupeReport {
+  groups: DuplicateGroup[];
+  duplicates: number;
+  /** Duplicates replaced by a hard link to the earlier copy */
+  linked: number;
+  saved_bytes: number;
+}
+
+// Request of POST /downloads/simulate (x-extract test-url)
+export interface SimulateRequest {
+  url: string;
+  platform?: Platform;
+  mode?: DownloadMode;
+  range_end?: number;
+  all_variants?: boolean;
+  account?: string;
+  tdl_profile?: string;
+}
+
+// A file a download would fetch, as far as the tool reports it
+export interface SimulatedItem {
+  id?: string;
+  url?: string;
+  title?: string;
+  uploader?: string;
+  filename?: string;
+  /** Size in bytes when known, possibly approximate */
+  size?: number;
+}
+
+// What a download would do, without downloading anything
+export interface Simulation {
+  url: string;
+  platform: Platform;
+  mode: DownloadMode;
+  tool: string;
+  /** Command line of the download */
+  command: string;
+  /** Command line run to list the media */
+  probe: string;
+  /** Settings applied to the download */
+  config: Record<string, string>;
+  items: SimulatedItem[];
+  error?: string;
+  error_code?: ErrorCode;
 }
 
 // API error response
@@ -94,6 +510,13 @@ export interface ApiError {
   error: string;
 }
 
+// Result of adding a download: the new download, or the existing download of
+// the URL when it is already queued, in progress or downloaded (409)
+export interface CreateDownloadResult {
+  duplicate: boolean;
+  download: Download;
+}
+
 // API success message response
 export interface ApiMessage {
   message: string;
@@ -103,18 +526,22 @@ export interface ApiMessage {
 export const STATUS_COLORS: Record<DownloadStatus, string> = {
   queued: "bg-yellow-100 text-yellow-800 dark:bg-yellow-900 dark:text-yellow-300",
   processing: "bg-blue-100 text-blue-800 dark:bg-blue-900 dark:text-blue-300",
+  recording: "bg-purple-100 text-purple-800 dark:bg-purple-900 dark:text-purple-300",
   completed: "bg-green-100 text-green-800 dark:bg-green-900 dark:text-green-300",
   failed: "bg-red-100 text-red-800 dark:bg-red-900 dark:text-red-300",
   cancelled: "bg-gray-100 text-gray-800 dark:bg-gray-700 dark:text-gray-300",
+  expired: "bg-orange-100 text-orange-800 dark:bg-orange-900 dark:text-orange-300",
 };
 
 // Human-readable status labels
 export const STATUS_LABELS: Record<DownloadStatus, string> = {
   queued: "Pending",
   processing: "Downloading",
+  recording: "Recording",
   completed: "Completed",
   failed: "Failed",
   cancelled: "Cancelled",
+  expired: "Expired",
 };
 
 // Platform icons/labels
@@ -125,3 +552,16 @@ export const PLATFORM_LABELS: Record<Platform, string> = {
   gallery: "Gallery",
 };
 
+// What keeps the server from auto-exiting (GET/POST/DELETE /server/keepalive)
+export interface KeepAliveStatus {
+  auto_exit_on_empty: boolean;
+  held: boolean;
+  keep_alive_until?: string;
+  inhibited: boolean;
+  clients_connected: boolean;
+}
+
+export interface KeepAliveRequest {
+  ttl?: string; // e.g. "30m" (default: queue.keepalive_ttl)
+  hold?: boolean; // Keep alive until released
+}